@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"time"
+
+	"mbankingcore/config"
+	"mbankingcore/handlers"
+
+	"github.com/joho/godotenv"
+)
+
+// Daily AML transaction monitoring job, intended to run from cron after the business day closes
+func main() {
+	date := flag.String("date", time.Now().AddDate(0, 0, -1).Format("2006-01-02"), "business date to monitor (YYYY-MM-DD)")
+	flag.Parse()
+
+	log.Println("MBankingCore - AML Monitoring")
+	log.Println("=============================")
+
+	businessDate, err := time.ParseInLocation("2006-01-02", *date, time.Local)
+	if err != nil {
+		log.Fatalf("Invalid date %q: %v", *date, err)
+	}
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found or error loading .env file")
+	}
+
+	// Connect to database
+	config.ConnectDatabase()
+
+	result, err := handlers.NewAMLHandler(config.DB).RunMonitoring(businessDate)
+	if err != nil {
+		log.Fatalf("AML monitoring failed: %v", err)
+	}
+
+	for _, alert := range result.Alerts {
+		log.Printf("%s user=%d type=%s risk=%d transactions=%d total=%d %s",
+			alert.AlertNumber, alert.UserID, alert.AlertType, alert.RiskScore,
+			alert.TransactionCount, alert.TotalAmount, alert.Detail)
+	}
+	log.Printf("AML monitoring for %s finished: %d raised, %d already raised, %d failed",
+		result.BusinessDate, result.Raised, result.Existing, result.Failed)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"mbankingcore/config"
+	"mbankingcore/handlers"
+	"mbankingcore/utils"
+
+	"github.com/joho/godotenv"
+)
+
+// Audit archive job. Intended to run daily from cron on the host that keeps the archive directory:
+// it archives every monthly audit partition older than audit_archive_after_days to a compressed file
+// with a signed manifest and drops the partition, applying the retention of each entity type.
+func main() {
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found or error loading .env file")
+	}
+
+	dir := flag.String("dir", utils.GetAuditArchiveDir(), "directory the archives are written to")
+	dryRun := flag.Bool("dry-run", false, "list the partitions that would be archived without changing anything")
+	flag.Parse()
+
+	log.Println("MBankingCore - Audit Archive")
+	log.Println("============================")
+
+	// Connect to database
+	config.ConnectDatabase()
+
+	auditHandler := handlers.NewAuditHandler()
+	archives, err := auditHandler.ArchiveAuditPartitions(*dir, "system", *dryRun)
+	for _, archive := range archives {
+		if *dryRun {
+			log.Printf("%s: would archive %d records (%s to %s)", archive.Partition, archive.RecordCount,
+				archive.PeriodStart.Format("2006-01-02"), archive.PeriodEnd.Format("2006-01-02"))
+			continue
+		}
+		log.Printf("%s: %d records (%d pruned, seq %d-%d) archived to %s",
+			archive.Partition, archive.RecordCount, archive.PrunedCount, archive.FirstSeq, archive.LastSeq, archive.FileName)
+	}
+	if err != nil {
+		log.Fatalf("Audit archive failed: %v", err)
+	}
+
+	log.Printf("Audit archive completed: %d partitions archived", len(archives))
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"mbankingcore/config"
+	"mbankingcore/handlers"
+
+	"github.com/joho/godotenv"
+)
+
+// Audit restore command for investigations. Restores the records of an archived audit partition
+// from its manifest, after checking the manifest signature, the archive checksum and the hash of
+// every record. The partition is dropped again by the audit archive job audit_restore_hold_days
+// after the restore.
+func main() {
+	manifest := flag.String("manifest", "", "path to the archive's .manifest.json file")
+	verifyOnly := flag.Bool("verify-only", false, "check the archive without restoring it")
+	flag.Parse()
+
+	log.Println("MBankingCore - Audit Restore")
+	log.Println("============================")
+
+	if *manifest == "" {
+		log.Fatal("-manifest is required")
+	}
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found or error loading .env file")
+	}
+
+	// The archive is checked without a database connection
+	if *verifyOnly {
+		archive, err := handlers.LoadAuditArchiveManifest(*manifest)
+		if err != nil {
+			log.Fatalf("Archive verification failed: %v", err)
+		}
+		result, err := (&handlers.AuditHandler{}).RestoreAuditArchive(*manifest, true)
+		if err != nil {
+			log.Fatalf("Archive verification failed: %v", err)
+		}
+		log.Printf("%s: %d records (%d pruned, seq %d-%d) verified", archive.Partition, result.Records,
+			result.Pruned, archive.FirstSeq, archive.LastSeq)
+		return
+	}
+
+	// Connect to database
+	config.ConnectDatabase()
+
+	result, err := handlers.NewAuditHandler().RestoreAuditArchive(*manifest, false)
+	if err != nil {
+		log.Fatalf("Audit restore failed: %v", err)
+	}
+
+	log.Printf("%s: %d records in archive, %d restored, %d already present, %d pruned and not restorable",
+		result.Partition, result.Records, result.Restored, result.AlreadyPresent, result.Pruned)
+	log.Println("Audit restore completed")
+}
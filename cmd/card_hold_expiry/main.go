@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"time"
+
+	"mbankingcore/config"
+	"mbankingcore/handlers"
+
+	"github.com/joho/godotenv"
+)
+
+// Daily release of card authorization holds that the network never cleared
+func main() {
+	date := flag.String("date", time.Now().Format("2006-01-02"), "release holds expiring on or before this date (YYYY-MM-DD)")
+	flag.Parse()
+
+	log.Println("MBankingCore - Card Hold Expiry")
+	log.Println("===============================")
+
+	asOf, err := time.ParseInLocation("2006-01-02", *date, time.Local)
+	if err != nil {
+		log.Fatalf("Invalid date %q: %v", *date, err)
+	}
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found or error loading .env file")
+	}
+
+	// Connect to database
+	config.ConnectDatabase()
+
+	result, err := handlers.NewCardHandler(config.DB).RunHoldExpiry(asOf)
+	if err != nil {
+		log.Fatalf("Card hold expiry failed: %v", err)
+	}
+
+	log.Printf("Hold expiry for %s finished: %d released (%d), %d failed",
+		result.Date, result.Released, result.Amount, result.Failed)
+}
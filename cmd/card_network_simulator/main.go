@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"mbankingcore/models"
+	"mbankingcore/utils"
+
+	"github.com/joho/godotenv"
+)
+
+// Local card network simulator. Sends signed authorization, reversal and clearing messages
+// to the card network endpoints, e.g.:
+//
+//	go run ./cmd/card_network_simulator -pan 5221840000000001 -expiry 3010 -cvv 123 -amount 150000
+//	go run ./cmd/card_network_simulator -action reverse -pan 5221840000000001 -expiry 3010 -amount 150000 -rrn 000000123456
+//	go run ./cmd/card_network_simulator -action clear -rrn 000000123456 -auth-code 654321 -amount 145000
+func main() {
+	baseURL := flag.String("url", "http://localhost:8080/api", "API base URL")
+	action := flag.String("action", "authorize", "authorize, reverse or clear")
+	pan := flag.String("pan", "", "card number")
+	expiry := flag.String("expiry", "", "card expiry (YYMM)")
+	cvv := flag.String("cvv", "", "CVV2, omitted for card-present purchases")
+	amount := flag.Int64("amount", 0, "amount in IDR")
+	merchant := flag.String("merchant", "SIMULATOR STORE JAKARTA", "merchant name and location")
+	mcc := flag.String("mcc", "5411", "merchant category code")
+	rrn := flag.String("rrn", "", "retrieval reference number (generated for authorizations when empty)")
+	authCode := flag.String("auth-code", "", "approval code to clear")
+	flag.Parse()
+
+	log.Println("MBankingCore - Card Network Simulator")
+	log.Println("=====================================")
+
+	// Load environment variables (CARD_NETWORK_SECRET)
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found or error loading .env file")
+	}
+
+	now := time.Now()
+	if *rrn == "" {
+		*rrn = fmt.Sprintf("%s%06d", now.Format("060102"), rand.Intn(1000000))
+	}
+	stan := fmt.Sprintf("%06d", rand.Intn(1000000))
+
+	var path string
+	var message interface{}
+	switch *action {
+	case "authorize", "reverse":
+		mti := models.CARD_MTI_AUTH_REQUEST
+		if *action == "reverse" {
+			mti = models.CARD_MTI_REVERSAL_REQUEST
+		}
+		posEntryMode := "051" // chip
+		if *cvv != "" {
+			posEntryMode = "812" // e-commerce
+		}
+		path = "/card-network/authorize"
+		message = models.CardNetworkMessage{
+			MTI:                  mti,
+			PAN:                  *pan,
+			ProcessingCode:       "000000",
+			Amount:               *amount,
+			TransmissionDateTime: now.UTC().Format("0102150405"),
+			STAN:                 stan,
+			Expiry:               *expiry,
+			MerchantCategoryCode: *mcc,
+			POSEntryMode:         posEntryMode,
+			RRN:                  *rrn,
+			TerminalID:           "SIMTRM01",
+			MerchantID:           "SIMMERCHANT0001",
+			MerchantName:         *merchant,
+			Currency:             models.BASE_CURRENCY,
+			CVV:                  *cvv,
+		}
+	case "clear":
+		path = "/card-network/clearing"
+		message = models.CardClearingRequest{
+			BatchReference: utils.GenerateReference("CLR"),
+			Records: []models.CardClearingRecord{
+				{RRN: *rrn, AuthCode: *authCode, Amount: *amount},
+			},
+		}
+	default:
+		log.Fatalf("Unknown action %q", *action)
+	}
+
+	payload, err := json.Marshal(message)
+	if err != nil {
+		log.Fatalf("Failed to encode message: %v", err)
+	}
+
+	request, err := http.NewRequest(http.MethodPost, *baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		log.Fatalf("Failed to build request: %v", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Signature", utils.SignCardNetworkMessage(payload))
+
+	log.Printf("Sending %s for RRN %s: %s", *action, *rrn, payload)
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	response, err := client.Do(request)
+	if err != nil {
+		log.Fatalf("Request failed: %v", err)
+	}
+	defer response.Body.Close()
+
+	body, _ := io.ReadAll(response.Body)
+	log.Printf("HTTP %d: %s", response.StatusCode, body)
+}
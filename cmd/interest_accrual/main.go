@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"time"
+
+	"mbankingcore/config"
+	"mbankingcore/handlers"
+
+	"github.com/joho/godotenv"
+)
+
+// Daily savings interest accrual job, intended to run from cron after midnight
+func main() {
+	date := flag.String("date", time.Now().AddDate(0, 0, -1).Format("2006-01-02"), "date to accrue (YYYY-MM-DD)")
+	flag.Parse()
+
+	log.Println("MBankingCore - Interest Accrual")
+	log.Println("===============================")
+
+	accrualDate, err := time.ParseInLocation("2006-01-02", *date, time.Local)
+	if err != nil {
+		log.Fatalf("Invalid date %q: %v", *date, err)
+	}
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found or error loading .env file")
+	}
+
+	// Connect to database
+	config.ConnectDatabase()
+
+	result, err := handlers.NewInterestHandler(config.DB).RunDailyAccrual(accrualDate)
+	if err != nil {
+		log.Fatalf("Interest accrual failed: %v", err)
+	}
+
+	log.Printf("Accrual for %s finished: %d accrued, %d skipped, %d failed",
+		result.AccrualDate, result.Accrued, result.Skipped, result.Failed)
+}
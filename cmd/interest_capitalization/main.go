@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"time"
+
+	"mbankingcore/config"
+	"mbankingcore/handlers"
+
+	"github.com/joho/godotenv"
+)
+
+// Monthly savings interest capitalization job, intended to run from cron on the first day of the month
+func main() {
+	now := time.Now()
+	lastMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local).AddDate(0, -1, 0)
+	periodFlag := flag.String("period", lastMonth.Format("2006-01"), "month to capitalize (YYYY-MM)")
+	flag.Parse()
+
+	log.Println("MBankingCore - Interest Capitalization")
+	log.Println("======================================")
+
+	period, err := time.ParseInLocation("2006-01", *periodFlag, time.Local)
+	if err != nil {
+		log.Fatalf("Invalid period %q: %v", *periodFlag, err)
+	}
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found or error loading .env file")
+	}
+
+	// Connect to database
+	config.ConnectDatabase()
+
+	result, err := handlers.NewInterestHandler(config.DB).RunMonthlyCapitalization(period)
+	if err != nil {
+		log.Fatalf("Interest capitalization failed: %v", err)
+	}
+
+	for _, capitalization := range result.Capitalizations {
+		log.Printf("user=%d accruals=%d gross=%d tax=%d net=%d status=%s %s",
+			capitalization.UserID, capitalization.AccrualCount, capitalization.GrossInterest,
+			capitalization.TaxAmount, capitalization.NetInterest, capitalization.Status, capitalization.FailureReason)
+	}
+	log.Printf("Capitalization for %s finished: %d capitalized, %d failed", result.Period, result.Capitalized, result.Failed)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"time"
+
+	"mbankingcore/config"
+	"mbankingcore/handlers"
+
+	"github.com/joho/godotenv"
+)
+
+// Daily loan installment auto-debit, intended to run from cron after the maturity and interest jobs
+func main() {
+	date := flag.String("date", time.Now().Format("2006-01-02"), "collect installments due on or before this date (YYYY-MM-DD)")
+	flag.Parse()
+
+	log.Println("MBankingCore - Loan Collection")
+	log.Println("==============================")
+
+	asOf, err := time.ParseInLocation("2006-01-02", *date, time.Local)
+	if err != nil {
+		log.Fatalf("Invalid date %q: %v", *date, err)
+	}
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found or error loading .env file")
+	}
+
+	// Connect to database
+	config.ConnectDatabase()
+
+	result, err := handlers.NewLoanHandler(config.DB).RunCollection(asOf)
+	if err != nil {
+		log.Fatalf("Loan collection failed: %v", err)
+	}
+
+	log.Printf("Collection for %s finished: %d loans processed, %d collected, %d paid off, %d overdue, %d failed",
+		result.Date, result.Processed, result.Collected, result.PaidOff, result.Overdue, result.Failed)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"time"
+
+	"mbankingcore/config"
+	"mbankingcore/handlers"
+
+	"github.com/joho/godotenv"
+)
+
+// Daily merchant settlement job, intended to run from cron after the business day closes
+func main() {
+	date := flag.String("date", time.Now().AddDate(0, 0, -1).Format("2006-01-02"), "business date to settle (YYYY-MM-DD)")
+	flag.Parse()
+
+	log.Println("MBankingCore - Merchant Settlement")
+	log.Println("==================================")
+
+	businessDate, err := time.ParseInLocation("2006-01-02", *date, time.Local)
+	if err != nil {
+		log.Fatalf("Invalid date %q: %v", *date, err)
+	}
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found or error loading .env file")
+	}
+
+	// Connect to database
+	config.ConnectDatabase()
+
+	result, err := handlers.NewMerchantSettlementHandler(config.DB).RunDailySettlement(businessDate)
+	if err != nil {
+		log.Fatalf("Merchant settlement failed: %v", err)
+	}
+
+	for _, settlement := range result.Settlements {
+		log.Printf("%s merchant=%d payments=%d gross=%d mdr=%d net=%d status=%s %s",
+			settlement.Reference, settlement.MerchantID, settlement.PaymentCount, settlement.GrossAmount,
+			settlement.MDRAmount, settlement.NetAmount, settlement.Status, settlement.FailureReason)
+	}
+	log.Printf("Settlement for %s finished: %d settled, %d failed", result.BusinessDate, result.Settled, result.Failed)
+}
@@ -0,0 +1,403 @@
+package main
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"mbankingcore/models"
+	"mbankingcore/utils"
+)
+
+const siemSDID = "mbc@32473"
+
+// Local SIEM receiver for testing audit export. It accepts RFC 5424 syslog over UDP, TCP or TLS, or
+// reads a JSON-lines export file, and checks that every event is correctly formatted, carries all
+// required fields and that each chain arrives without gaps and with unbroken hash links.
+func main() {
+	network := flag.String("network", "tcp", "transport to listen on: tcp, udp or tls")
+	addr := flag.String("addr", "127.0.0.1:6514", "address to listen on")
+	format := flag.String("format", "cef", "expected message body: cef or json")
+	certFile := flag.String("cert", "", "TLS certificate file (tls only)")
+	keyFile := flag.String("key", "", "TLS private key file (tls only)")
+	file := flag.String("file", "", "validate a JSON-lines export file instead of listening")
+	verbose := flag.Bool("v", false, "log every valid event")
+	flag.Parse()
+
+	log.Println("MBankingCore - SIEM Test Listener")
+	log.Println("=================================")
+
+	tracker := newChainTracker(*verbose)
+
+	if *file != "" {
+		if err := validateFile(*file, tracker); err != nil {
+			log.Fatalf("Failed to read %s: %v", *file, err)
+		}
+		if !tracker.summary() {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if !utils.IsValidSIEMFormat(*format) {
+		log.Fatalf("Invalid format %q, use cef or json", *format)
+	}
+	handle := func(raw string) {
+		event, err := parseSyslogEvent(raw, *format)
+		tracker.record(event, err, raw)
+	}
+
+	switch *network {
+	case "udp":
+		conn, err := net.ListenPacket("udp", *addr)
+		if err != nil {
+			log.Fatalf("Failed to listen: %v", err)
+		}
+		go serveUDP(conn, handle)
+	case "tcp", "tls":
+		listener, err := net.Listen("tcp", *addr)
+		if err != nil {
+			log.Fatalf("Failed to listen: %v", err)
+		}
+		if *network == "tls" {
+			cert, err := tls.LoadX509KeyPair(*certFile, *keyFile)
+			if err != nil {
+				log.Fatalf("Failed to load TLS certificate: %v", err)
+			}
+			listener = tls.NewListener(listener, &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12})
+		}
+		go serveStream(listener, handle)
+	default:
+		log.Fatalf("Invalid network %q, use tcp, udp or tls", *network)
+	}
+	log.Printf("Listening on %s/%s for %s messages, press Ctrl+C for the summary", *network, *addr, *format)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			tracker.progress()
+		case <-stop:
+			if !tracker.summary() {
+				os.Exit(1)
+			}
+			return
+		}
+	}
+}
+
+func serveUDP(conn net.PacketConn, handle func(string)) {
+	buf := make([]byte, 65536)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			log.Printf("UDP read failed: %v", err)
+			return
+		}
+		handle(string(buf[:n]))
+	}
+}
+
+func serveStream(listener net.Listener, handle func(string)) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			log.Printf("Accept failed: %v", err)
+			return
+		}
+		log.Printf("Connection from %s", conn.RemoteAddr())
+		go func() {
+			defer conn.Close()
+			reader := bufio.NewReader(conn)
+			for {
+				message, err := readOctetCounted(reader)
+				if err == io.EOF {
+					return
+				}
+				if err != nil {
+					log.Printf("Framing error from %s, closing connection: %v", conn.RemoteAddr(), err)
+					return
+				}
+				handle(message)
+			}
+		}()
+	}
+}
+
+// readOctetCounted reads one "MSG-LEN SP SYSLOG-MSG" frame (RFC 5425/6587)
+func readOctetCounted(reader *bufio.Reader) (string, error) {
+	prefix, err := reader.ReadString(' ')
+	if err != nil {
+		if err == io.EOF && prefix == "" {
+			return "", io.EOF
+		}
+		return "", fmt.Errorf("truncated frame length: %v", err)
+	}
+	length, err := strconv.Atoi(strings.TrimSuffix(prefix, " "))
+	if err != nil || length <= 0 || strings.HasPrefix(prefix, "0") {
+		return "", fmt.Errorf("invalid frame length %q", strings.TrimSpace(prefix))
+	}
+	message := make([]byte, length)
+	if _, err := io.ReadFull(reader, message); err != nil {
+		return "", fmt.Errorf("truncated frame: %v", err)
+	}
+	return string(message), nil
+}
+
+func validateFile(path string, tracker *chainTracker) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		event, err := parseJSONEvent(line)
+		tracker.record(event, err, line)
+	}
+	return scanner.Err()
+}
+
+// parseSyslogEvent validates a syslog message and its body, and checks the body against the chain
+// position carried in the structured data
+func parseSyslogEvent(raw, format string) (*models.AuditEvent, error) {
+	msg, err := utils.ParseSyslogMessage(raw)
+	if err != nil {
+		return nil, fmt.Errorf("syslog: %v", err)
+	}
+	if msg.Facility != 13 {
+		return nil, fmt.Errorf("syslog: facility %d, expected 13 (log audit)", msg.Facility)
+	}
+	if msg.Timestamp.IsZero() {
+		return nil, errors.New("syslog: missing timestamp")
+	}
+	if msg.MsgID != "AUDIT" && msg.MsgID != "LOGIN" {
+		return nil, fmt.Errorf("syslog: unexpected MSGID %q", msg.MsgID)
+	}
+	sd, ok := msg.StructuredData[siemSDID]
+	if !ok {
+		return nil, fmt.Errorf("syslog: missing structured data element %s", siemSDID)
+	}
+
+	var event *models.AuditEvent
+	if format == utils.SIEM_FORMAT_CEF {
+		event, err = parseCEFEvent(msg.Message)
+	} else {
+		event, err = parseJSONEvent(msg.Message)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if sd["chain"] != event.Chain || sd["seq"] != strconv.FormatUint(event.ChainSeq, 10) ||
+		sd["id"] != strconv.FormatUint(uint64(event.RecordID), 10) || sd["hash"] != event.Hash {
+		return event, errors.New("structured data does not match the message body")
+	}
+	if !msg.Timestamp.Equal(event.Time.Truncate(time.Microsecond)) && msg.Timestamp.UnixMilli() != event.Time.UnixMilli() {
+		return event, errors.New("syslog timestamp does not match the event time")
+	}
+	if want := strings.ToUpper(event.Category); msg.MsgID != want {
+		return event, fmt.Errorf("MSGID %q does not match category %q", msg.MsgID, event.Category)
+	}
+	return event, nil
+}
+
+func parseJSONEvent(body string) (*models.AuditEvent, error) {
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.DisallowUnknownFields()
+	var event models.AuditEvent
+	if err := decoder.Decode(&event); err != nil {
+		return nil, fmt.Errorf("json: %v", err)
+	}
+	return &event, checkEventFields(&event)
+}
+
+func parseCEFEvent(body string) (*models.AuditEvent, error) {
+	record, err := utils.ParseCEF(body)
+	if err != nil {
+		return nil, fmt.Errorf("cef: %v", err)
+	}
+	if record.Vendor != "MBankingCore" || record.Product != "mbankingcore" {
+		return nil, fmt.Errorf("cef: unexpected vendor/product %q/%q", record.Vendor, record.Product)
+	}
+
+	ext := record.Extension
+	for _, key := range []string{"rt", "act", "outcome", "cat", "externalId", "cs1", "cn1", "cs2"} {
+		if ext[key] == "" {
+			return nil, fmt.Errorf("cef: missing extension %s", key)
+		}
+	}
+	for label, want := range map[string]string{"cs1Label": "chain", "cn1Label": "chainSeq", "cs2Label": "hash", "cs3Label": "prevHash"} {
+		if ext[label] != want {
+			return nil, fmt.Errorf("cef: %s is %q, expected %q", label, ext[label], want)
+		}
+	}
+
+	rt, err := strconv.ParseInt(ext["rt"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("cef: invalid rt %q", ext["rt"])
+	}
+	seq, err := strconv.ParseUint(ext["cn1"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("cef: invalid cn1 %q", ext["cn1"])
+	}
+	id, err := strconv.ParseUint(ext["externalId"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("cef: invalid externalId %q", ext["externalId"])
+	}
+
+	event := &models.AuditEvent{
+		Chain:    ext["cs1"],
+		ChainSeq: seq,
+		RecordID: uint(id),
+		Time:     time.UnixMilli(rt),
+		Category: ext["cat"],
+		Action:   ext["act"],
+		Outcome:  ext["outcome"],
+		Hash:     ext["cs2"],
+		PrevHash: ext["cs3"],
+	}
+	return event, checkEventFields(event)
+}
+
+// checkEventFields checks the fields every exported event must carry
+func checkEventFields(event *models.AuditEvent) error {
+	switch {
+	case !models.IsValidAuditChain(event.Chain):
+		return fmt.Errorf("invalid chain %q", event.Chain)
+	case event.ChainSeq == 0:
+		return errors.New("missing chain_seq")
+	case event.RecordID == 0:
+		return errors.New("missing record_id")
+	case event.Time.IsZero():
+		return errors.New("missing time")
+	case event.Action == "":
+		return errors.New("missing action")
+	case event.Category != models.AUDIT_EVENT_CATEGORY_AUDIT && event.Category != models.AUDIT_EVENT_CATEGORY_LOGIN:
+		return fmt.Errorf("invalid category %q", event.Category)
+	case event.Outcome != models.AUDIT_EVENT_OUTCOME_SUCCESS && event.Outcome != models.AUDIT_EVENT_OUTCOME_FAILURE:
+		return fmt.Errorf("invalid outcome %q", event.Outcome)
+	case len(event.Hash) != 64:
+		return errors.New("missing or malformed hash")
+	case event.ChainSeq > 1 && len(event.PrevHash) != 64:
+		return errors.New("missing or malformed prev_hash")
+	}
+	return nil
+}
+
+// chainTracker checks that each chain is received in order, without gaps and with every event
+// linking to the hash of the one before it. Duplicates, which retried deliveries can cause, are
+// counted but accepted when identical.
+type chainTracker struct {
+	mu         sync.Mutex
+	verbose    bool
+	received   int
+	invalid    int
+	duplicates int
+	problems   int
+	chains     map[string]*chainState
+}
+
+type chainState struct {
+	firstSeq uint64
+	lastSeq  uint64
+	hashes   map[uint64]string
+}
+
+func newChainTracker(verbose bool) *chainTracker {
+	return &chainTracker{verbose: verbose, chains: map[string]*chainState{}}
+}
+
+func (t *chainTracker) record(event *models.AuditEvent, err error, raw string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.received++
+	if err != nil {
+		t.invalid++
+		if len(raw) > 300 {
+			raw = raw[:300] + "..."
+		}
+		log.Printf("INVALID: %v: %s", err, raw)
+		return
+	}
+
+	state, ok := t.chains[event.Chain]
+	if !ok {
+		// The listener may start partway through a chain
+		state = &chainState{firstSeq: event.ChainSeq, lastSeq: event.ChainSeq - 1, hashes: map[uint64]string{}}
+		t.chains[event.Chain] = state
+	}
+
+	if hash, seen := state.hashes[event.ChainSeq]; seen {
+		t.duplicates++
+		if hash != event.Hash {
+			t.problems++
+			log.Printf("CONFLICT: %s seq %d received again with a different hash", event.Chain, event.ChainSeq)
+		}
+		return
+	}
+
+	switch {
+	case event.ChainSeq > state.lastSeq+1:
+		t.problems++
+		log.Printf("GAP: %s seq %d-%d missing", event.Chain, state.lastSeq+1, event.ChainSeq-1)
+	case event.ChainSeq <= state.lastSeq:
+		t.problems++
+		log.Printf("OUT OF ORDER: %s seq %d received after %d", event.Chain, event.ChainSeq, state.lastSeq)
+	}
+	if prev, ok := state.hashes[event.ChainSeq-1]; ok && prev != event.PrevHash {
+		t.problems++
+		log.Printf("BROKEN LINK: %s seq %d prev_hash does not match seq %d", event.Chain, event.ChainSeq, event.ChainSeq-1)
+	}
+
+	state.hashes[event.ChainSeq] = event.Hash
+	if event.ChainSeq > state.lastSeq {
+		state.lastSeq = event.ChainSeq
+	}
+	if t.verbose {
+		log.Printf("OK: %s seq %d %s %s %s", event.Chain, event.ChainSeq, event.Category, event.Action, event.Outcome)
+	}
+}
+
+func (t *chainTracker) progress() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	log.Printf("%d received, %d invalid, %d duplicates, %d problems", t.received, t.invalid, t.duplicates, t.problems)
+}
+
+// summary logs the result and reports whether everything received was valid and complete
+func (t *chainTracker) summary() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for name, state := range t.chains {
+		log.Printf("%s: seq %d-%d, %d distinct events", name, state.firstSeq, state.lastSeq, len(state.hashes))
+	}
+	log.Printf("%d received, %d invalid, %d duplicates, %d problems", t.received, t.invalid, t.duplicates, t.problems)
+	if t.invalid > 0 || t.problems > 0 {
+		log.Println("Audit export check failed")
+		return false
+	}
+	log.Println("Audit export check passed")
+	return true
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"time"
+
+	"mbankingcore/config"
+	"mbankingcore/handlers"
+
+	"github.com/joho/godotenv"
+)
+
+// Daily deposito maturity job, intended to run from cron at the start of the business day
+func main() {
+	date := flag.String("date", time.Now().Format("2006-01-02"), "process deposits maturing on or before this date (YYYY-MM-DD)")
+	flag.Parse()
+
+	log.Println("MBankingCore - Time Deposit Maturity")
+	log.Println("====================================")
+
+	asOf, err := time.ParseInLocation("2006-01-02", *date, time.Local)
+	if err != nil {
+		log.Fatalf("Invalid date %q: %v", *date, err)
+	}
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found or error loading .env file")
+	}
+
+	// Connect to database
+	config.ConnectDatabase()
+
+	result, err := handlers.NewTimeDepositHandler(config.DB).RunMaturity(asOf)
+	if err != nil {
+		log.Fatalf("Time deposit maturity failed: %v", err)
+	}
+
+	log.Printf("Maturity for %s finished: %d rolled over, %d paid out, %d failed",
+		result.Date, result.RolledOver, result.PaidOut, result.Failed)
+}
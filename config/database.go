@@ -5,6 +5,9 @@ import (
 	"log"
 	"os"
 
+	"mbankingcore/models"
+	"mbankingcore/utils"
+
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -46,8 +49,9 @@ func ConnectDatabase() {
 	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		host, port, user, password, dbname, sslmode)
 
+	// SQL is logged with PINs, tokens and other sensitive values redacted
 	database, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger: utils.NewRedactingSQLLogger(logger.Default.LogMode(logger.Info)),
 	})
 
 	if err != nil {
@@ -63,6 +67,11 @@ func ConnectDatabase() {
 	if err := SetupDatabase(); err != nil {
 		log.Fatal("Failed to run migrations:", err)
 	}
+
+	// Capture field-level changes of audited models from here on, redacting audit payloads
+	if err := models.RegisterAuditCallbacks(DB, utils.RedactJSON); err != nil {
+		log.Fatal("Failed to register audit callbacks:", err)
+	}
 }
 
 func GetDB() *gorm.DB {
@@ -3,14 +3,21 @@ package config
 import (
 	"log"
 	"mbankingcore/models"
+	"mbankingcore/utils"
 
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 // SetupDatabase initializes database with migrations and seeds initial data
 func SetupDatabase() error {
 	log.Println("Setting up database...")
 
+	if err := renameDeviceVerificationCode(); err != nil {
+		log.Printf("Failed to rename device verification code column: %v", err)
+		return err
+	}
+
 	// Run Auto Migration
 	err := DB.AutoMigrate(
 		&models.User{},
@@ -26,6 +33,56 @@ func SetupDatabase() error {
 		&models.PendingTransaction{},
 		&models.PendingUserStatusChange{},
 		&models.ApprovalThreshold{},
+		&models.DisbursementBatch{},
+		&models.DisbursementItem{},
+		&models.SystemAccount{},
+		&models.SystemAccountEntry{},
+		&models.InterbankTransfer{},
+		&models.ISO20022Message{},
+		&models.Merchant{},
+		&models.QRPayment{},
+		&models.MerchantAPIKey{},
+		&models.MerchantSettlement{},
+		&models.Biller{},
+		&models.BillPayment{},
+		&models.VirtualAccount{},
+		&models.VirtualAccountPayment{},
+		&models.Currency{},
+		&models.CurrencyBalance{},
+		&models.FXRate{},
+		&models.FXQuote{},
+		&models.InterestProduct{},
+		&models.InterestTier{},
+		&models.InterestAccrual{},
+		&models.InterestCapitalization{},
+		&models.TimeDepositProduct{},
+		&models.TimeDeposit{},
+		&models.Pocket{},
+		&models.PocketMovement{},
+		&models.LoanProduct{},
+		&models.Loan{},
+		&models.LoanInstallment{},
+		&models.Card{},
+		&models.CardAuthorization{},
+		&models.Dispute{},
+		&models.DisputeAttachment{},
+		&models.DisputeNote{},
+		&models.DisputeStatusHistory{},
+		&models.FraudRule{},
+		&models.FraudDecision{},
+		&models.FraudRuleHit{},
+		&models.AMLAlert{},
+		&models.SuspiciousTransactionReport{},
+		&models.WatchlistEntry{},
+		&models.ScreeningMatch{},
+		&models.TrustedDevice{},
+		&models.DeviceVerification{},
+		&models.DeviceRequestNonce{},
+		&models.LoginThrottle{},
+		&models.AuditCheckpoint{},
+		&models.AuditExportCursor{},
+		&models.AuditRetentionPolicy{},
+		&models.AuditArchive{},
 	)
 	if err != nil {
 		log.Printf("Failed to auto-migrate models: %v", err)
@@ -33,6 +90,16 @@ func SetupDatabase() error {
 	}
 	log.Println("✅ Database tables created successfully")
 
+	if err := models.PartitionAuditTables(DB); err != nil {
+		log.Printf("Failed to partition audit tables: %v", err)
+		return err
+	}
+
+	if err := backfillReversedTransactions(); err != nil {
+		log.Printf("Failed to backfill reversed transactions: %v", err)
+		return err
+	}
+
 	// Seed initial data
 	if err := seedInitialData(); err != nil {
 		log.Printf("Failed to seed initial data: %v", err)
@@ -43,6 +110,35 @@ func SetupDatabase() error {
 	return nil
 }
 
+// renameDeviceVerificationCode renames device_verifications.code to verification_code, a column
+// name the SQL log redaction recognises
+func renameDeviceVerificationCode() error {
+	migrator := DB.Migrator()
+	if !migrator.HasTable(&models.DeviceVerification{}) || !migrator.HasColumn(&models.DeviceVerification{}, "code") ||
+		migrator.HasColumn(&models.DeviceVerification{}, "verification_code") {
+		return nil
+	}
+	return migrator.RenameColumn(&models.DeviceVerification{}, "code", "verification_code")
+}
+
+// backfillReversedTransactions moves transactions reversed before the status state machine existed,
+// which only carry is_reversed, to the reversed status
+func backfillReversedTransactions() error {
+	result := DB.Model(&models.Transaction{}).
+		Where("is_reversed = ? AND status = ?", true, models.TRANSACTION_STATUS_COMPLETED).
+		Updates(map[string]interface{}{
+			"status":          models.TRANSACTION_STATUS_REVERSED,
+			"reversed_amount": gorm.Expr("amount"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("✅ Backfilled %d reversed transactions", result.RowsAffected)
+	}
+	return nil
+}
+
 // seedInitialData creates essential initial data for new project
 func seedInitialData() error {
 	log.Println("Seeding initial data...")
@@ -72,6 +168,46 @@ func seedInitialData() error {
 		return err
 	}
 
+	// Seed internal system accounts
+	if err := seedSystemAccounts(); err != nil {
+		return err
+	}
+
+	// Seed supported currencies
+	if err := seedCurrencies(); err != nil {
+		return err
+	}
+
+	// Seed default savings interest product
+	if err := seedInterestProducts(); err != nil {
+		return err
+	}
+
+	// Seed deposito products
+	if err := seedTimeDepositProducts(); err != nil {
+		return err
+	}
+
+	// Seed loan products
+	if err := seedLoanProducts(); err != nil {
+		return err
+	}
+
+	// Seed default fraud rules
+	if err := seedFraudRules(); err != nil {
+		return err
+	}
+
+	// Seed bill payment catalog
+	if err := seedBillers(); err != nil {
+		return err
+	}
+
+	// Seed audit retention policies
+	if err := seedAuditRetentionPolicies(); err != nil {
+		return err
+	}
+
 	log.Println("✅ Initial data seeding completed")
 	return nil
 }
@@ -233,6 +369,44 @@ func seedInitialConfigs() error {
 		{Key: "contact_phone", Value: "+62-21-12345678"},
 		{Key: "maintenance_mode", Value: "false"},
 		{Key: "max_sessions_per_user", Value: "5"},
+		{Key: "interbank_transfer_fee", Value: "2500"},
+		{Key: "qris_default_city", Value: "JAKARTA"},
+		{Key: "max_pockets_per_user", Value: "10"},
+		{Key: "max_cards_per_user", Value: "3"},
+		{Key: "card_hold_expiry_days", Value: "7"},
+		{Key: "dispute_filing_window_days", Value: "60"},
+		{Key: "dispute_response_sla_hours", Value: "24"},
+		{Key: "dispute_resolution_sla_days", Value: "14"},
+		{Key: "fx_quote_validity_seconds", Value: "60"},
+		{Key: "reversal_counterparty_policy", Value: models.REVERSAL_POLICY_REJECT},
+		{Key: "fraud_challenge_validity_minutes", Value: "10"},
+		{Key: "fraud_approval_validity_hours", Value: "24"},
+		{Key: "fraud_challenge_max_attempts", Value: "3"},
+		{Key: "aml_reporting_threshold", Value: "500000000"},
+		{Key: "aml_structuring_lower_percent", Value: "80"},
+		{Key: "aml_structuring_min_count", Value: "3"},
+		{Key: "aml_structuring_window_days", Value: "7"},
+		{Key: "aml_rapid_movement_min_amount", Value: "100000000"},
+		{Key: "aml_rapid_movement_percent", Value: "80"},
+		{Key: "aml_dormant_days", Value: "180"},
+		{Key: "aml_dormant_min_amount", Value: "10000000"},
+		{Key: "watchlist_match_threshold", Value: "90"},
+		{Key: "device_cooling_off_hours", Value: "24"},
+		{Key: "device_cooling_off_max_amount", Value: "5000000"},
+		{Key: "device_max_bound", Value: "3"},
+		{Key: "device_signature_max_skew_seconds", Value: "300"},
+		{Key: "login_failure_window_minutes", Value: "15"},
+		{Key: "login_free_attempts", Value: "3"},
+		{Key: "login_ip_free_attempts", Value: "10"},
+		{Key: "login_delay_base_seconds", Value: "2"},
+		{Key: "login_delay_max_seconds", Value: "900"},
+		{Key: "login_user_lockout_threshold", Value: "5"},
+		{Key: "login_admin_lockout_threshold", Value: "5"},
+		{Key: "audit_chain_seal_interval_seconds", Value: "10"},
+		{Key: "audit_export_interval_seconds", Value: "5"},
+		{Key: "audit_archive_after_days", Value: "90"},
+		{Key: "audit_retention_default_days", Value: "2555"},
+		{Key: "audit_restore_hold_days", Value: "30"},
 	}
 
 	for _, config := range initialConfigs {
@@ -351,6 +525,14 @@ func seedInitialApprovalThresholds() error {
 			AutoExpireHours:       48,
 			IsActive:              true,
 		},
+		{
+			TransactionType:       "bulk_disbursement",
+			AmountThreshold:       0, // every batch requires approval
+			RequiresDualApproval:  false,
+			DualApprovalThreshold: 0,
+			AutoExpireHours:       24,
+			IsActive:              true,
+		},
 	}
 
 	for _, threshold := range initialThresholds {
@@ -367,6 +549,285 @@ func seedInitialApprovalThresholds() error {
 	log.Println("   - Transfer: 10M IDR (dual: 100M IDR, expires: 24h)")
 	log.Println("   - Balance Adjustment: 1M IDR (dual: 10M IDR, expires: 48h)")
 	log.Println("   - Balance Set: 5M IDR (dual: 50M IDR, expires: 48h)")
+	log.Println("   - Bulk Disbursement: every batch (expires: 24h)")
+	return nil
+}
+
+// seedSystemAccounts creates the internal suspense, settlement and income accounts.
+// Each account is created only if its code does not exist yet, so new accounts
+// can be added in later releases without reseeding.
+func seedSystemAccounts() error {
+	log.Println("Seeding system accounts...")
+
+	systemAccounts := []models.SystemAccount{
+		{Code: models.SYSTEM_ACCOUNT_INTERBANK_SUSPENSE, Name: "Interbank Transfer Suspense", AccountType: "suspense"},
+		{Code: models.SYSTEM_ACCOUNT_INTERBANK_SETTLEMENT, Name: "Interbank Settlement", AccountType: "settlement"},
+		{Code: models.SYSTEM_ACCOUNT_FEE_INCOME, Name: "Fee Income", AccountType: "income"},
+		{Code: models.SYSTEM_ACCOUNT_MERCHANT_PAYABLE, Name: "Merchant Settlement Payable", AccountType: "suspense"},
+		{Code: models.SYSTEM_ACCOUNT_BILLER_SUSPENSE, Name: "Bill Payment Suspense", AccountType: "suspense"},
+		{Code: models.SYSTEM_ACCOUNT_BILLER_SETTLEMENT, Name: "Biller Settlement", AccountType: "settlement"},
+		{Code: models.SYSTEM_ACCOUNT_VA_SUSPENSE, Name: "Virtual Account Suspense", AccountType: "suspense"},
+		{Code: models.SYSTEM_ACCOUNT_INTEREST_EXPENSE, Name: "Interest Expense", AccountType: "expense"},
+		{Code: models.SYSTEM_ACCOUNT_TAX_PAYABLE, Name: "Withholding Tax Payable", AccountType: "liability"},
+		{Code: models.SYSTEM_ACCOUNT_TIME_DEPOSIT, Name: "Time Deposits", AccountType: "liability"},
+		{Code: models.SYSTEM_ACCOUNT_LOAN_RECEIVABLE, Name: "Loan Receivable", AccountType: "asset"},
+		{Code: models.SYSTEM_ACCOUNT_INTEREST_INCOME, Name: "Loan Interest Income", AccountType: "income"},
+		{Code: models.SYSTEM_ACCOUNT_CARD_HOLD, Name: "Card Authorization Holds", AccountType: "suspense"},
+		{Code: models.SYSTEM_ACCOUNT_CARD_SETTLEMENT, Name: "Card Network Settlement", AccountType: "settlement"},
+		{Code: models.SYSTEM_ACCOUNT_DISPUTE_SUSPENSE, Name: "Dispute Provisional Credit", AccountType: "suspense"},
+		{Code: models.SYSTEM_ACCOUNT_DISPUTE_EXPENSE, Name: "Dispute Adjustment Expense", AccountType: "expense"},
+		{Code: models.SYSTEM_ACCOUNT_REVERSAL_RECEIVABLE, Name: "Reversal Shortfall Receivable", AccountType: "asset"},
+		{Code: models.SYSTEM_ACCOUNT_UNCLAIMED_REFUNDS, Name: "Unclaimed Customer Refunds", AccountType: "liability"},
+		{Code: models.SYSTEM_ACCOUNT_DISBURSEMENT_FUNDING, Name: "Bulk Disbursement Funding", AccountType: "liability"},
+	}
+
+	for _, account := range systemAccounts {
+		if err := DB.Where("code = ?", account.Code).FirstOrCreate(&account).Error; err != nil {
+			log.Printf("Failed to create system account %s: %v", account.Code, err)
+			return err
+		}
+	}
+
+	log.Printf("✅ %d system accounts available", len(systemAccounts))
+	return nil
+}
+
+// seedCurrencies creates the supported currencies. IDR amounts have always been whole rupiah,
+// so the base currency keeps 0 minor units.
+func seedCurrencies() error {
+	log.Println("Seeding currencies...")
+
+	currencies := []models.Currency{
+		{Code: models.BASE_CURRENCY, Name: "Indonesian Rupiah", MinorUnits: 0, IsActive: true},
+		{Code: "USD", Name: "US Dollar", MinorUnits: 2, IsActive: true},
+		{Code: "SGD", Name: "Singapore Dollar", MinorUnits: 2, IsActive: true},
+		{Code: "EUR", Name: "Euro", MinorUnits: 2, IsActive: true},
+		{Code: "JPY", Name: "Japanese Yen", MinorUnits: 0, IsActive: true},
+	}
+
+	for _, currency := range currencies {
+		if err := DB.Where("code = ?", currency.Code).FirstOrCreate(&currency).Error; err != nil {
+			log.Printf("Failed to create currency %s: %v", currency.Code, err)
+			return err
+		}
+	}
+
+	log.Printf("✅ %d currencies available", len(currencies))
+	return nil
+}
+
+// seedInterestProducts creates the default tiered savings product
+func seedInterestProducts() error {
+	log.Println("Seeding interest products...")
+
+	var count int64
+	DB.Model(&models.InterestProduct{}).Count(&count)
+	if count > 0 {
+		log.Printf("Interest products already exist (%d products), skipping seeding", count)
+		return nil
+	}
+
+	product := models.InterestProduct{
+		Code:               "TABUNGAN_REGULER",
+		Name:               "Tabungan Reguler",
+		DayCountBasis:      models.DAY_COUNT_ACT_365,
+		TaxRateBasisPoints: 2000,
+		IsDefault:          true,
+		IsActive:           true,
+		Tiers: []models.InterestTier{
+			{MinBalance: 0, RateBasisPoints: 0},
+			{MinBalance: 1000000, RateBasisPoints: 50},
+			{MinBalance: 50000000, RateBasisPoints: 100},
+			{MinBalance: 500000000, RateBasisPoints: 200},
+		},
+	}
+
+	if err := DB.Create(&product).Error; err != nil {
+		log.Printf("Failed to create interest product %s: %v", product.Code, err)
+		return err
+	}
+
+	log.Printf("✅ Interest product %s created", product.Code)
+	return nil
+}
+
+// seedTimeDepositProducts creates the standard deposito tenors
+func seedTimeDepositProducts() error {
+	log.Println("Seeding time deposit products...")
+
+	var count int64
+	DB.Model(&models.TimeDepositProduct{}).Count(&count)
+	if count > 0 {
+		log.Printf("Time deposit products already exist (%d products), skipping seeding", count)
+		return nil
+	}
+
+	products := []models.TimeDepositProduct{
+		{Code: "DEPOSITO_1M", Name: "Deposito 1 Bulan", TenorMonths: 1, RateBasisPoints: 350},
+		{Code: "DEPOSITO_3M", Name: "Deposito 3 Bulan", TenorMonths: 3, RateBasisPoints: 375},
+		{Code: "DEPOSITO_6M", Name: "Deposito 6 Bulan", TenorMonths: 6, RateBasisPoints: 400},
+		{Code: "DEPOSITO_12M", Name: "Deposito 12 Bulan", TenorMonths: 12, RateBasisPoints: 425},
+	}
+
+	for _, product := range products {
+		product.MinAmount = 10000000
+		product.PenaltyBasisPoints = 50
+		product.TaxRateBasisPoints = 2000
+		product.IsActive = true
+		if err := DB.Create(&product).Error; err != nil {
+			log.Printf("Failed to create time deposit product %s: %v", product.Code, err)
+			return err
+		}
+	}
+
+	log.Printf("✅ %d time deposit products created", len(products))
+	return nil
+}
+
+// seedLoanProducts creates the default paylater and cash loan products
+func seedLoanProducts() error {
+	log.Println("Seeding loan products...")
+
+	var count int64
+	DB.Model(&models.LoanProduct{}).Count(&count)
+	if count > 0 {
+		log.Printf("Loan products already exist (%d products), skipping seeding", count)
+		return nil
+	}
+
+	products := []models.LoanProduct{
+		{
+			Code:                "PAYLATER",
+			Name:                "Paylater",
+			MinAmount:           100000,
+			MaxAmount:           5000000,
+			MinTenorMonths:      1,
+			MaxTenorMonths:      12,
+			RateBasisPoints:     2400,
+			InterestMethod:      utils.INTEREST_METHOD_FLAT,
+			AdminFeeBasisPoints: 100,
+			LateFeePerDay:       5000,
+			LateFeeCap:          100000,
+			IsActive:            true,
+		},
+		{
+			Code:                "CASH_LOAN",
+			Name:                "Pinjaman Tunai",
+			MinAmount:           1000000,
+			MaxAmount:           50000000,
+			MinTenorMonths:      3,
+			MaxTenorMonths:      24,
+			RateBasisPoints:     1800,
+			InterestMethod:      utils.INTEREST_METHOD_ANNUITY,
+			AdminFeeBasisPoints: 200,
+			LateFeePerDay:       10000,
+			LateFeeCap:          300000,
+			IsActive:            true,
+		},
+	}
+
+	for _, product := range products {
+		if err := DB.Create(&product).Error; err != nil {
+			log.Printf("Failed to create loan product %s: %v", product.Code, err)
+			return err
+		}
+	}
+
+	log.Printf("✅ %d loan products created", len(products))
+	return nil
+}
+
+// seedFraudRules creates the default fraud rules evaluated before transfers and withdrawals
+func seedFraudRules() error {
+	log.Println("Seeding fraud rules...")
+
+	var count int64
+	DB.Model(&models.FraudRule{}).Count(&count)
+	if count > 0 {
+		log.Printf("Fraud rules already exist (%d rules), skipping seeding", count)
+		return nil
+	}
+
+	rules := []models.FraudRule{
+		{
+			Code:             "VELOCITY_SHORT",
+			Name:             "Many transfers in a short time",
+			RuleType:         models.FRAUD_RULE_VELOCITY,
+			TransactionTypes: models.FRAUD_CHECK_TYPE_TRANSFER,
+			Parameters:       models.FraudRuleParameters{MaxCount: 5, WindowMinutes: 10},
+			Action:           models.FRAUD_DECISION_CHALLENGE,
+		},
+		{
+			Code:       "VELOCITY_BURST",
+			Name:       "Burst of outgoing transactions",
+			RuleType:   models.FRAUD_RULE_VELOCITY,
+			Parameters: models.FraudRuleParameters{MaxCount: 20, WindowMinutes: 60},
+			Action:     models.FRAUD_DECISION_BLOCK,
+		},
+		{
+			Code:       "AMOUNT_ANOMALY",
+			Name:       "Amount far above the usual amount",
+			RuleType:   models.FRAUD_RULE_AMOUNT_ANOMALY,
+			Parameters: models.FraudRuleParameters{MinAmount: 1000000, MultiplierPercent: 500, LookbackDays: 90, MinHistory: 5},
+			Action:     models.FRAUD_DECISION_HOLD,
+		},
+		{
+			Code:       "NEW_DEVICE_LARGE_AMOUNT",
+			Name:       "Large amount from a new device",
+			RuleType:   models.FRAUD_RULE_NEW_DEVICE_AMOUNT,
+			Parameters: models.FraudRuleParameters{MinAmount: 5000000, NewDeviceHours: 24},
+			Action:     models.FRAUD_DECISION_CHALLENGE,
+		},
+		{
+			Code:             "NEW_BENEFICIARY",
+			Name:             "First transfer to a beneficiary",
+			RuleType:         models.FRAUD_RULE_NEW_BENEFICIARY,
+			TransactionTypes: models.FRAUD_CHECK_TYPE_TRANSFER,
+			Parameters:       models.FraudRuleParameters{MinAmount: 10000000},
+			Action:           models.FRAUD_DECISION_CHALLENGE,
+		},
+		{
+			Code:       "NIGHT_TIME",
+			Name:       "Large amount at night",
+			RuleType:   models.FRAUD_RULE_NIGHT_TIME,
+			Parameters: models.FraudRuleParameters{MinAmount: 5000000, StartHour: 0, EndHour: 5, Timezone: "Asia/Jakarta"},
+			Action:     models.FRAUD_DECISION_CHALLENGE,
+		},
+	}
+
+	for _, rule := range rules {
+		rule.IsActive = true
+		if err := DB.Create(&rule).Error; err != nil {
+			log.Printf("Failed to create fraud rule %s: %v", rule.Code, err)
+			return err
+		}
+	}
+
+	log.Printf("✅ %d fraud rules created", len(rules))
+	return nil
+}
+
+// seedBillers creates the default bill payment catalog
+func seedBillers() error {
+	log.Println("Seeding billers...")
+
+	billers := []models.Biller{
+		{Code: "PLN_POSTPAID", Name: "PLN Tagihan Listrik", Category: models.BILLER_CATEGORY_ELECTRICITY, ProductType: models.BILLER_PRODUCT_POSTPAID, AdminFee: 2500},
+		{Code: "PLN_PREPAID", Name: "PLN Token Listrik", Category: models.BILLER_CATEGORY_ELECTRICITY, ProductType: models.BILLER_PRODUCT_PREPAID, Denominations: "20000,50000,100000,200000,500000,1000000", AdminFee: 2500},
+		{Code: "TELKOMSEL_PULSA", Name: "Pulsa Telkomsel", Category: models.BILLER_CATEGORY_PHONE_CREDIT, ProductType: models.BILLER_PRODUCT_PREPAID, Denominations: "10000,25000,50000,100000"},
+		{Code: "INDOSAT_PULSA", Name: "Pulsa Indosat Ooredoo", Category: models.BILLER_CATEGORY_PHONE_CREDIT, ProductType: models.BILLER_PRODUCT_PREPAID, Denominations: "10000,25000,50000,100000"},
+		{Code: "PDAM_JAKARTA", Name: "PAM Jaya", Category: models.BILLER_CATEGORY_WATER, ProductType: models.BILLER_PRODUCT_POSTPAID, AdminFee: 2500},
+		{Code: "BPJS_KESEHATAN", Name: "BPJS Kesehatan", Category: models.BILLER_CATEGORY_BPJS, ProductType: models.BILLER_PRODUCT_POSTPAID, AdminFee: 2500},
+	}
+
+	for _, biller := range billers {
+		if err := DB.Where("code = ?", biller.Code).FirstOrCreate(&biller).Error; err != nil {
+			log.Printf("Failed to create biller %s: %v", biller.Code, err)
+			return err
+		}
+	}
+
+	log.Printf("✅ %d billers available", len(billers))
 	return nil
 }
 
@@ -461,3 +922,27 @@ func getAdminPrivacyPolicyContent() string {
 <li>Mematuhi protokol keamanan</li>
 </ul>`
 }
+
+// seedAuditRetentionPolicies sets shorter retention for high-volume audit entity types that carry
+// no financial record; the rest keep audit_retention_default_days
+func seedAuditRetentionPolicies() error {
+	log.Println("Seeding audit retention policies...")
+
+	policies := []models.AuditRetentionPolicy{
+		{EntityType: "system", RetentionDays: 90, Description: "Unclassified API activity"},
+		{EntityType: "article", RetentionDays: 180, Description: "Article content management"},
+		{EntityType: "photo", RetentionDays: 180, Description: "Photo content management"},
+		{EntityType: "onboarding", RetentionDays: 180, Description: "Onboarding content management"},
+		{EntityType: models.AUDIT_RETENTION_ENTITY_LOGIN, RetentionDays: 730, Description: "Login audits"},
+	}
+
+	for _, policy := range policies {
+		if err := DB.Where("entity_type = ?", policy.EntityType).FirstOrCreate(&policy).Error; err != nil {
+			log.Printf("Failed to create audit retention policy %s: %v", policy.EntityType, err)
+			return err
+		}
+	}
+
+	log.Printf("✅ %d audit retention policies available", len(policies))
+	return nil
+}
@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"log"
 	"mbankingcore/models"
 	"mbankingcore/utils"
 	"net/http"
@@ -33,37 +35,59 @@ func (h *AdminHandler) AdminLogin(c *gin.Context) {
 		return
 	}
 
-	// Find admin by email
-	var admin models.Admin
-	if err := h.DB.Where("email = ?", request.Email).First(&admin).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, models.Response{
-			Code:    models.CODE_LOGIN_FAILED,
-			Message: "Invalid email or password",
-			Data:    nil,
-		})
+	// Reject attempts while the email or the client IP is throttled
+	throttleKeys := loginThrottleKeys{
+		Scope:      models.LOGIN_THROTTLE_SCOPE_ADMIN,
+		Identifier: request.Email,
+		IPAddress:  c.ClientIP(),
+	}
+	c.Set("login_identifier", request.Email)
+	if wait := checkLoginThrottle(h.DB, throttleKeys); wait > 0 {
+		respondLoginThrottled(c, wait)
 		return
 	}
 
-	// Check if admin is active
-	if !admin.IsActive() {
-		c.JSON(http.StatusUnauthorized, models.Response{
-			Code:    models.CODE_LOGIN_FAILED,
-			Message: "Admin account is not active",
-			Data:    nil,
-		})
-		return
+	// Find admin by email. Unknown emails still go through a bcrypt comparison so the response time
+	// does not reveal whether the account exists.
+	var admin models.Admin
+	var failureReason string
+	adminExists := h.DB.Where("email = ?", request.Email).First(&admin).Error == nil
+	if adminExists {
+		c.Set("admin_id", admin.ID)
+		c.Request = c.Request.WithContext(models.WithAuditActor(c.Request.Context(), &admin.ID, nil))
+		if err := bcrypt.CompareHashAndPassword([]byte(admin.Password), []byte(request.Password)); err != nil {
+			failureReason = "invalid_password"
+		} else if !admin.IsActive() {
+			failureReason = "admin_not_active"
+		}
+	} else {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(request.Password))
+		failureReason = "unknown_email"
 	}
 
-	// Verify password
-	if err := bcrypt.CompareHashAndPassword([]byte(admin.Password), []byte(request.Password)); err != nil {
+	if failureReason != "" {
+		c.Set("failure_reason", failureReason)
+		var target *models.Admin
+		if adminExists {
+			target = &admin
+		}
+		err := recordAdminLoginFailure(h.DB.WithContext(c), throttleKeys, target)
+		if errors.Is(err, errLoginLockedOut) {
+			c.Set("failure_reason", failureReason+"; account_blocked")
+			log.Printf("Admin %d blocked after repeated login failures", admin.ID)
+		} else if err != nil {
+			log.Printf("Failed to record admin login failure: %v", err)
+		}
 		c.JSON(http.StatusUnauthorized, models.Response{
 			Code:    models.CODE_LOGIN_FAILED,
-			Message: "Invalid email or password",
+			Message: msgAdminLoginFailed,
 			Data:    nil,
 		})
 		return
 	}
 
+	resetLoginFailures(h.DB, throttleKeys.Scope, throttleKeys.Identifier)
+
 	// Generate JWT token
 	token, err := utils.GenerateAdminJWT(admin.ID, admin.Email, admin.Role)
 	if err != nil {
@@ -78,7 +102,7 @@ func (h *AdminHandler) AdminLogin(c *gin.Context) {
 	// Update last login
 	now := time.Now()
 	admin.LastLogin = &now
-	h.DB.Save(&admin)
+	h.DB.WithContext(c).Save(&admin)
 
 	c.JSON(http.StatusOK, models.AdminLoginSuccessResponse(admin, token, 24*60*60)) // 24 hours
 }
@@ -133,7 +157,7 @@ func (h *AdminHandler) CreateAdmin(c *gin.Context) {
 		Status:   models.ADMIN_STATUS_ACTIVE,
 	}
 
-	if err := h.DB.Create(&admin).Error; err != nil {
+	if err := h.DB.WithContext(c).Create(&admin).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.Response{
 			Code:    models.CODE_USER_CREATE_FAILED,
 			Message: "Failed to create admin",
@@ -232,7 +256,7 @@ func (h *AdminHandler) UpdateAdmin(c *gin.Context) {
 	}
 
 	// Save changes
-	if err := h.DB.Save(&admin).Error; err != nil {
+	if err := h.DB.WithContext(c).Save(&admin).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.Response{
 			Code:    models.CODE_USER_UPDATE_FAILED,
 			Message: "Failed to update admin",
@@ -290,7 +314,7 @@ func (h *AdminHandler) DeleteAdmin(c *gin.Context) {
 	}
 
 	// Soft delete admin
-	if err := h.DB.Delete(&admin).Error; err != nil {
+	if err := h.DB.WithContext(c).Delete(&admin).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.Response{
 			Code:    models.CODE_USER_DELETE_FAILED,
 			Message: "Failed to soft delete admin",
@@ -335,7 +359,7 @@ func (h *AdminHandler) RestoreAdmin(c *gin.Context) {
 	}
 
 	// Restore the admin by setting deleted_at to NULL
-	if err := h.DB.Unscoped().Model(&admin).Update("deleted_at", nil).Error; err != nil {
+	if err := h.DB.WithContext(c).Unscoped().Model(&admin).Update("deleted_at", nil).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.Response{
 			Code:    models.CODE_USER_DELETE_FAILED,
 			Message: "Failed to restore admin",
@@ -453,7 +477,7 @@ func (h *AdminHandler) PermanentDeleteAdmin(c *gin.Context) {
 	}
 
 	// Permanently delete the admin
-	if err := h.DB.Unscoped().Delete(&admin, uint(id)).Error; err != nil {
+	if err := h.DB.WithContext(c).Unscoped().Delete(&admin, uint(id)).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.Response{
 			Code:    models.CODE_USER_DELETE_FAILED,
 			Message: "Failed to permanently delete admin",
@@ -1064,7 +1088,7 @@ func (h *AdminHandler) AdminTopupUserBalance(c *gin.Context) {
 	}
 
 	// Start database transaction
-	tx := h.DB.Begin()
+	tx := h.DB.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
@@ -1275,7 +1299,7 @@ func (h *AdminHandler) AdminAdjustUserBalance(c *gin.Context) {
 	}
 
 	// Start database transaction
-	tx := h.DB.Begin()
+	tx := h.DB.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
@@ -1507,7 +1531,7 @@ func (h *AdminHandler) AdminSetUserBalance(c *gin.Context) {
 	}
 
 	// Start database transaction
-	tx := h.DB.Begin()
+	tx := h.DB.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
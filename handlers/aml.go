@@ -0,0 +1,1072 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"mbankingcore/models"
+	"mbankingcore/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Default AML monitoring parameters, overridable through config
+const (
+	defaultAMLReportingThreshold      = 500000000 // Cash transaction reporting threshold (LTKT)
+	defaultAMLStructuringLowerPercent = 80        // Amounts from this share of the threshold count as near-threshold
+	defaultAMLStructuringMinCount     = 3
+	defaultAMLStructuringWindowDays   = 7
+	defaultAMLRapidMovementMinAmount  = 100000000
+	defaultAMLRapidMovementPercent    = 80 // Share of the inflow moved out on the same day
+	defaultAMLDormantDays             = 180
+	defaultAMLDormantMinAmount        = 10000000
+)
+
+// Transaction types the AML detectors look at
+var (
+	amlInboundTypes = []string{
+		models.TRANSACTION_TYPE_TOPUP,
+		models.TRANSACTION_TYPE_TRANSFER_IN,
+		models.TRANSACTION_TYPE_DISBURSEMENT,
+		models.TRANSACTION_TYPE_MERCHANT_SETTLEMENT,
+		models.TRANSACTION_TYPE_LOAN_DISBURSEMENT,
+	}
+	amlOutboundTypes = []string{
+		models.TRANSACTION_TYPE_WITHDRAW,
+		models.TRANSACTION_TYPE_TRANSFER_OUT,
+		models.TRANSACTION_TYPE_INTERBANK_TRANSFER_OUT,
+		models.TRANSACTION_TYPE_BILL_PAYMENT,
+		models.TRANSACTION_TYPE_QR_PAYMENT,
+	}
+	amlStructuringTypes = []string{
+		models.TRANSACTION_TYPE_TOPUP,
+		models.TRANSACTION_TYPE_WITHDRAW,
+		models.TRANSACTION_TYPE_TRANSFER_IN,
+		models.TRANSACTION_TYPE_TRANSFER_OUT,
+		models.TRANSACTION_TYPE_INTERBANK_TRANSFER_OUT,
+	}
+	amlSettledStatuses = []string{models.TRANSACTION_STATUS_COMPLETED, models.TRANSACTION_STATUS_PARTIALLY_REVERSED}
+)
+
+type AMLHandler struct {
+	DB *gorm.DB
+}
+
+func NewAMLHandler(db *gorm.DB) *AMLHandler {
+	return &AMLHandler{DB: db}
+}
+
+// AMLMonitoringRunResult summarises one monitoring run
+type AMLMonitoringRunResult struct {
+	BusinessDate string            `json:"business_date"`
+	Raised       int               `json:"raised"`
+	Existing     int               `json:"existing"` // Already raised by an earlier run for the same date
+	Failed       int               `json:"failed"`
+	Alerts       []models.AMLAlert `json:"alerts"`
+}
+
+// amlCandidate is a detector hit before it is stored as an alert
+type amlCandidate struct {
+	UserID         uint
+	AlertType      string
+	RiskScore      int
+	WindowStart    time.Time
+	WindowEnd      time.Time
+	TransactionIDs []uint
+	TotalAmount    int64
+	Detail         string
+}
+
+// RunMonitoring scans the transactions of a business date for structuring, rapid in/out movement
+// and dormant account reactivation, and raises an alert for every hit. Runs are idempotent: a user
+// gets one alert per type and date, and alerts that failed to store are retried on the next run.
+func (h *AMLHandler) RunMonitoring(businessDate time.Time) (*AMLMonitoringRunResult, error) {
+	start := time.Date(businessDate.Year(), businessDate.Month(), businessDate.Day(), 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 0, 1)
+
+	result := &AMLMonitoringRunResult{
+		BusinessDate: start.Format("2006-01-02"),
+		Alerts:       []models.AMLAlert{},
+	}
+
+	detectors := []func(start, end time.Time) ([]amlCandidate, error){
+		h.detectStructuring,
+		h.detectRapidMovement,
+		h.detectDormantReactivation,
+	}
+	for _, detect := range detectors {
+		candidates, err := detect(start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, candidate := range candidates {
+			alert, created, err := h.raiseAlert(start, candidate)
+			switch {
+			case err != nil:
+				log.Printf("AML alert %s for user %d on %s failed: %v", candidate.AlertType, candidate.UserID, result.BusinessDate, err)
+				result.Failed++
+			case created:
+				result.Raised++
+				result.Alerts = append(result.Alerts, *alert)
+			default:
+				result.Existing++
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// amlTransactions scopes settled base currency transactions of the given types created in [from, to)
+func (h *AMLHandler) amlTransactions(types []string, from, to time.Time) *gorm.DB {
+	return h.DB.Model(&models.Transaction{}).
+		Where("type IN ? AND currency = ? AND status IN ? AND created_at >= ? AND created_at < ?",
+			types, models.BASE_CURRENCY, amlSettledStatuses, from, to)
+}
+
+// detectStructuring finds users splitting amounts just below the reporting threshold over the
+// structuring window, with at least one such transaction on the business date
+func (h *AMLHandler) detectStructuring(start, end time.Time) ([]amlCandidate, error) {
+	threshold := getConfigInt64(h.DB, "aml_reporting_threshold", defaultAMLReportingThreshold)
+	lowerPercent := getConfigInt64(h.DB, "aml_structuring_lower_percent", defaultAMLStructuringLowerPercent)
+	minCount := getConfigInt64(h.DB, "aml_structuring_min_count", defaultAMLStructuringMinCount)
+	windowDays := getConfigInt64(h.DB, "aml_structuring_window_days", defaultAMLStructuringWindowDays)
+
+	lower := threshold * lowerPercent / 100
+	windowStart := start.AddDate(0, 0, -int(windowDays-1))
+
+	var rows []struct {
+		UserID uint
+		Count  int64
+		Total  int64
+	}
+	if err := h.amlTransactions(amlStructuringTypes, windowStart, end).
+		Where("amount >= ? AND amount < ?", lower, threshold).
+		Select("user_id, COUNT(*) AS count, SUM(amount) AS total").
+		Group("user_id").
+		Having("COUNT(*) >= ? AND MAX(created_at) >= ?", minCount, start).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to scan for structuring: %v", err)
+	}
+
+	candidates := make([]amlCandidate, 0, len(rows))
+	for _, row := range rows {
+		var ids []uint
+		if err := h.amlTransactions(amlStructuringTypes, windowStart, end).
+			Where("user_id = ? AND amount >= ? AND amount < ?", row.UserID, lower, threshold).
+			Order("created_at").Pluck("id", &ids).Error; err != nil {
+			return nil, fmt.Errorf("failed to get structuring transactions: %v", err)
+		}
+		candidates = append(candidates, amlCandidate{
+			UserID:         row.UserID,
+			AlertType:      models.AML_ALERT_TYPE_STRUCTURING,
+			RiskScore:      min(100, 50+10*int(row.Count)),
+			WindowStart:    windowStart,
+			WindowEnd:      end,
+			TransactionIDs: ids,
+			TotalAmount:    row.Total,
+			Detail: fmt.Sprintf("%d transactions between %d and %d within %d days, %d in total",
+				row.Count, lower, threshold, windowDays, row.Total),
+		})
+	}
+	return candidates, nil
+}
+
+// detectRapidMovement finds users who moved most of a large inflow out again on the same day
+func (h *AMLHandler) detectRapidMovement(start, end time.Time) ([]amlCandidate, error) {
+	minAmount := getConfigInt64(h.DB, "aml_rapid_movement_min_amount", defaultAMLRapidMovementMinAmount)
+	percent := getConfigInt64(h.DB, "aml_rapid_movement_percent", defaultAMLRapidMovementPercent)
+
+	types := append(append([]string{}, amlInboundTypes...), amlOutboundTypes...)
+	var rows []struct {
+		UserID uint
+		Type   string
+		Total  int64
+	}
+	if err := h.amlTransactions(types, start, end).
+		Select("user_id, type, SUM(amount) AS total").
+		Group("user_id, type").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to scan for rapid movement: %v", err)
+	}
+
+	inbound := map[uint]int64{}
+	outbound := map[uint]int64{}
+	for _, row := range rows {
+		if containsString(amlInboundTypes, row.Type) {
+			inbound[row.UserID] += row.Total
+		} else {
+			outbound[row.UserID] += row.Total
+		}
+	}
+
+	userIDs := make([]uint, 0, len(inbound))
+	for userID, in := range inbound {
+		if in >= minAmount && outbound[userID]*100 >= in*percent {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	sort.Slice(userIDs, func(i, j int) bool { return userIDs[i] < userIDs[j] })
+
+	candidates := make([]amlCandidate, 0, len(userIDs))
+	for _, userID := range userIDs {
+		in, out := inbound[userID], outbound[userID]
+		var ids []uint
+		if err := h.amlTransactions(types, start, end).Where("user_id = ?", userID).
+			Order("created_at").Pluck("id", &ids).Error; err != nil {
+			return nil, fmt.Errorf("failed to get rapid movement transactions: %v", err)
+		}
+		candidates = append(candidates, amlCandidate{
+			UserID:         userID,
+			AlertType:      models.AML_ALERT_TYPE_RAPID_MOVEMENT,
+			RiskScore:      min(100, 60+int(40*min(out, in)/in)),
+			WindowStart:    start,
+			WindowEnd:      end,
+			TransactionIDs: ids,
+			TotalAmount:    in + out,
+			Detail:         fmt.Sprintf("received %d and moved out %d (%d%%) on the same day", in, out, out*100/in),
+		})
+	}
+	return candidates, nil
+}
+
+// detectDormantReactivation finds users moving large amounts after a long period without activity
+func (h *AMLHandler) detectDormantReactivation(start, end time.Time) ([]amlCandidate, error) {
+	dormantDays := getConfigInt64(h.DB, "aml_dormant_days", defaultAMLDormantDays)
+	minAmount := getConfigInt64(h.DB, "aml_dormant_min_amount", defaultAMLDormantMinAmount)
+	dormantBefore := start.AddDate(0, 0, -int(dormantDays))
+
+	types := append(append([]string{}, amlInboundTypes...), amlOutboundTypes...)
+	var rows []struct {
+		UserID uint
+		Total  int64
+	}
+	if err := h.amlTransactions(types, start, end).
+		Select("user_id, SUM(amount) AS total").
+		Group("user_id").
+		Having("SUM(amount) >= ?", minAmount).
+		Order("user_id").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to scan for dormant reactivation: %v", err)
+	}
+
+	candidates := []amlCandidate{}
+	for _, row := range rows {
+		var user models.User
+		if err := h.DB.Select("id, created_at").First(&user, row.UserID).Error; err != nil {
+			return nil, fmt.Errorf("failed to get user %d: %v", row.UserID, err)
+		}
+
+		// System postings such as interest do not count as customer activity
+		var last struct {
+			LastAt *time.Time
+		}
+		if err := h.DB.Model(&models.Transaction{}).Select("MAX(created_at) AS last_at").
+			Where("user_id = ? AND type IN ? AND created_at < ?", row.UserID, types, start).
+			Scan(&last).Error; err != nil {
+			return nil, fmt.Errorf("failed to get last activity of user %d: %v", row.UserID, err)
+		}
+		inactiveSince := user.CreatedAt
+		if last.LastAt != nil {
+			inactiveSince = *last.LastAt
+		}
+		if inactiveSince.After(dormantBefore) {
+			continue
+		}
+
+		var ids []uint
+		if err := h.amlTransactions(types, start, end).Where("user_id = ?", row.UserID).
+			Order("created_at").Pluck("id", &ids).Error; err != nil {
+			return nil, fmt.Errorf("failed to get reactivation transactions: %v", err)
+		}
+		inactiveDays := int(start.Sub(inactiveSince).Hours() / 24)
+		candidates = append(candidates, amlCandidate{
+			UserID:         row.UserID,
+			AlertType:      models.AML_ALERT_TYPE_DORMANT_REACTIVATION,
+			RiskScore:      min(100, 60+inactiveDays/30*5),
+			WindowStart:    start,
+			WindowEnd:      end,
+			TransactionIDs: ids,
+			TotalAmount:    row.Total,
+			Detail:         fmt.Sprintf("%d moved after %d days without activity", row.Total, inactiveDays),
+		})
+	}
+	return candidates, nil
+}
+
+// raiseAlert stores a candidate unless the user already has an alert of that type for the date
+func (h *AMLHandler) raiseAlert(businessDate time.Time, candidate amlCandidate) (*models.AMLAlert, bool, error) {
+	var existing int64
+	if err := h.DB.Model(&models.AMLAlert{}).
+		Where("user_id = ? AND alert_type = ? AND business_date = ?", candidate.UserID, candidate.AlertType, businessDate.Format("2006-01-02")).
+		Count(&existing).Error; err != nil {
+		return nil, false, err
+	}
+	if existing > 0 {
+		return nil, false, nil
+	}
+
+	alertNumber, err := generateAMLAlertNumber()
+	if err != nil {
+		return nil, false, err
+	}
+
+	alert := models.AMLAlert{
+		AlertNumber:      alertNumber,
+		UserID:           candidate.UserID,
+		AlertType:        candidate.AlertType,
+		BusinessDate:     businessDate,
+		RiskScore:        candidate.RiskScore,
+		WindowStart:      candidate.WindowStart,
+		WindowEnd:        candidate.WindowEnd,
+		TransactionCount: len(candidate.TransactionIDs),
+		TotalAmount:      candidate.TotalAmount,
+		Detail:           candidate.Detail,
+		TransactionIDs:   candidate.TransactionIDs,
+		Status:           models.AML_ALERT_STATUS_OPEN,
+	}
+	if err := h.DB.Create(&alert).Error; err != nil {
+		return nil, false, fmt.Errorf("failed to create alert: %v", err)
+	}
+	return &alert, true, nil
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// generateAMLAlertNumber returns a unique-enough alert number such as AML2501123456789012
+func generateAMLAlertNumber() (string, error) {
+	digits, err := generateDigits(12)
+	if err != nil {
+		return "", err
+	}
+	return "AML" + time.Now().Format("0601") + digits, nil
+}
+
+// generateSTRNumber returns a unique-enough report number such as LTKM2501123456789012
+func generateSTRNumber() (string, error) {
+	digits, err := generateDigits(12)
+	if err != nil {
+		return "", err
+	}
+	return utils.GOAML_REPORT_CODE_STR + time.Now().Format("0601") + digits, nil
+}
+
+// Admin endpoints
+
+// RunMonitoringJob - Trigger the AML monitoring batch (Admin only)
+func (h *AMLHandler) RunMonitoringJob(c *gin.Context) {
+	var req models.RunAMLMonitoringRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	date := time.Now().AddDate(0, 0, -1)
+	if req.Date != "" {
+		date, _ = time.ParseInLocation("2006-01-02", req.Date, time.Local)
+	}
+
+	result, err := h.RunMonitoring(date)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "AML monitoring completed",
+		Data:    result,
+	})
+}
+
+// GetAlerts - Investigation queue, highest risk first (Admin only)
+func (h *AMLHandler) GetAlerts(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.AMLAlert{})
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if alertType := c.Query("alert_type"); alertType != "" {
+		query = query.Where("alert_type = ?", alertType)
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if assignedAdminID := c.Query("assigned_admin_id"); assignedAdminID != "" {
+		query = query.Where("assigned_admin_id = ?", assignedAdminID)
+	}
+	if c.Query("unassigned") == "true" {
+		query = query.Where("assigned_admin_id IS NULL")
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var alerts []models.AMLAlert
+	if err := query.Order("risk_score DESC, created_at").Limit(limit).Offset(offset).Find(&alerts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch AML alerts",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "AML alerts retrieved successfully",
+		Data: gin.H{
+			"alerts": alerts,
+			"pagination": gin.H{
+				"page":        page,
+				"limit":       limit,
+				"total":       total,
+				"total_pages": int(math.Ceil(float64(total) / float64(limit))),
+			},
+		},
+	})
+}
+
+// GetAlertByID - Get an alert with the customer and the flagged transactions (Admin only)
+func (h *AMLHandler) GetAlertByID(c *gin.Context) {
+	var alert models.AMLAlert
+	if err := h.DB.Preload("User").First(&alert, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "AML alert not found",
+		})
+		return
+	}
+
+	transactions := []models.Transaction{}
+	if len(alert.TransactionIDs) > 0 {
+		h.DB.Where("id IN ?", alert.TransactionIDs).Order("created_at").Find(&transactions)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "AML alert retrieved successfully",
+		Data: gin.H{
+			"alert":        alert,
+			"transactions": transactions,
+		},
+	})
+}
+
+// AssignAlert - Assign an alert to an analyst and start the investigation (Admin only)
+func (h *AMLHandler) AssignAlert(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+
+	var req models.AssignAMLAlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var analyst models.Admin
+	if err := h.DB.First(&analyst, req.AdminID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Analyst admin not found",
+		})
+		return
+	}
+
+	var alert models.AMLAlert
+	if err := h.DB.First(&alert, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "AML alert not found",
+		})
+		return
+	}
+	if alert.Status != models.AML_ALERT_STATUS_OPEN && alert.Status != models.AML_ALERT_STATUS_INVESTIGATING {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("AML alert is already %s", alert.Status),
+		})
+		return
+	}
+
+	now := time.Now()
+	alert.Status = models.AML_ALERT_STATUS_INVESTIGATING
+	alert.AssignedAdminID = &analyst.ID
+	alert.AssignedAt = &now
+	if err := h.DB.Save(&alert).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to assign AML alert",
+		})
+		return
+	}
+
+	createAuditLog(h.DB, c, "aml_alert", alert.ID, adminID.(uint), "ASSIGN", map[string]interface{}{
+		"alert_number":      alert.AlertNumber,
+		"assigned_admin_id": analyst.ID,
+	})
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "AML alert assigned successfully",
+		Data:    alert,
+	})
+}
+
+// CloseAlert - Close an alert as not suspicious (Admin only)
+func (h *AMLHandler) CloseAlert(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+
+	var req models.CloseAMLAlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var alert models.AMLAlert
+	if err := h.DB.First(&alert, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "AML alert not found",
+		})
+		return
+	}
+	if alert.Status != models.AML_ALERT_STATUS_OPEN && alert.Status != models.AML_ALERT_STATUS_INVESTIGATING {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("AML alert is already %s", alert.Status),
+		})
+		return
+	}
+
+	now := time.Now()
+	adminIDValue := adminID.(uint)
+	alert.Status = models.AML_ALERT_STATUS_CLOSED
+	alert.ClosedByAdminID = &adminIDValue
+	alert.ClosedAt = &now
+	alert.ResolutionNotes = req.Notes
+	if err := h.DB.Save(&alert).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to close AML alert",
+		})
+		return
+	}
+
+	createAuditLog(h.DB, c, "aml_alert", alert.ID, adminIDValue, "CLOSE", map[string]interface{}{
+		"alert_number": alert.AlertNumber,
+		"notes":        req.Notes,
+	})
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "AML alert closed successfully",
+		Data:    alert,
+	})
+}
+
+// CreateReport - Escalate alerts of one customer into a suspicious transaction report (Admin only)
+func (h *AMLHandler) CreateReport(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+
+	var req models.CreateSTRRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	tx := h.DB.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var alerts []models.AMLAlert
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", req.AlertIDs).Order("id").Find(&alerts).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch AML alerts",
+		})
+		return
+	}
+	if len(alerts) != len(req.AlertIDs) {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "One or more AML alerts not found",
+		})
+		return
+	}
+
+	// Collect the flagged transactions of every alert once
+	seen := map[uint]bool{}
+	transactionIDs := []uint{}
+	indicators := req.Indicators
+	for _, alert := range alerts {
+		if alert.UserID != alerts[0].UserID {
+			tx.Rollback()
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "All alerts in a report must belong to the same customer",
+			})
+			return
+		}
+		if alert.Status != models.AML_ALERT_STATUS_OPEN && alert.Status != models.AML_ALERT_STATUS_INVESTIGATING {
+			tx.Rollback()
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("AML alert %s is already %s", alert.AlertNumber, alert.Status),
+			})
+			return
+		}
+		for _, id := range alert.TransactionIDs {
+			if !seen[id] {
+				seen[id] = true
+				transactionIDs = append(transactionIDs, id)
+			}
+		}
+		if len(req.Indicators) == 0 && !containsString(indicators, alert.AlertType) {
+			indicators = append(indicators, alert.AlertType)
+		}
+	}
+
+	var totalAmount int64
+	if len(transactionIDs) > 0 {
+		tx.Model(&models.Transaction{}).Where("id IN ?", transactionIDs).Select("COALESCE(SUM(amount), 0)").Scan(&totalAmount)
+	}
+
+	reportNumber, err := generateSTRNumber()
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to generate report number",
+		})
+		return
+	}
+
+	report := models.SuspiciousTransactionReport{
+		ReportNumber:      reportNumber,
+		UserID:            alerts[0].UserID,
+		Status:            models.STR_STATUS_DRAFT,
+		Reason:            req.Reason,
+		Action:            req.Action,
+		Indicators:        strings.Join(indicators, ","),
+		TransactionIDs:    transactionIDs,
+		TotalAmount:       totalAmount,
+		PreparedByAdminID: adminID.(uint),
+	}
+	if err := tx.Create(&report).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create suspicious transaction report",
+		})
+		return
+	}
+
+	if err := tx.Model(&models.AMLAlert{}).Where("id IN ?", req.AlertIDs).Updates(map[string]interface{}{
+		"status":    models.AML_ALERT_STATUS_ESCALATED,
+		"report_id": report.ID,
+	}).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to escalate AML alerts",
+		})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create suspicious transaction report",
+		})
+		return
+	}
+
+	createAuditLog(h.DB, c, "suspicious_transaction_report", report.ID, adminID.(uint), "CREATE", map[string]interface{}{
+		"report_number": report.ReportNumber,
+		"user_id":       report.UserID,
+		"alert_ids":     req.AlertIDs,
+		"total_amount":  report.TotalAmount,
+	})
+
+	h.DB.Preload("Alerts").First(&report, report.ID)
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Code:    http.StatusCreated,
+		Message: "Suspicious transaction report created successfully",
+		Data:    report,
+	})
+}
+
+// GetReports - List suspicious transaction reports (Admin only)
+func (h *AMLHandler) GetReports(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.SuspiciousTransactionReport{})
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var reports []models.SuspiciousTransactionReport
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&reports).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch suspicious transaction reports",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Suspicious transaction reports retrieved successfully",
+		Data: gin.H{
+			"reports": reports,
+			"pagination": gin.H{
+				"page":        page,
+				"limit":       limit,
+				"total":       total,
+				"total_pages": int(math.Ceil(float64(total) / float64(limit))),
+			},
+		},
+	})
+}
+
+// GetReportByID - Get a suspicious transaction report with its alerts (Admin only)
+func (h *AMLHandler) GetReportByID(c *gin.Context) {
+	var report models.SuspiciousTransactionReport
+	if err := h.DB.Preload("User").Preload("Alerts").First(&report, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Suspicious transaction report not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Suspicious transaction report retrieved successfully",
+		Data:    report,
+	})
+}
+
+// ExportReport - Download a suspicious transaction report as goAML XML for PPATK (Admin only)
+func (h *AMLHandler) ExportReport(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+
+	var report models.SuspiciousTransactionReport
+	if err := h.DB.Preload("User.BankAccounts").First(&report, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Suspicious transaction report not found",
+		})
+		return
+	}
+
+	var reporter models.Admin
+	if err := h.DB.First(&reporter, adminID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to load reporting officer",
+		})
+		return
+	}
+
+	document, err := h.buildGoAMLReport(&report, &reporter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to build goAML report",
+		})
+		return
+	}
+
+	payload, err := utils.EncodeGoAMLReport(document)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to encode goAML report",
+		})
+		return
+	}
+
+	createAuditLog(h.DB, c, "suspicious_transaction_report", report.ID, adminID.(uint), "EXPORT", map[string]interface{}{
+		"report_number": report.ReportNumber,
+	})
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.xml", report.ReportNumber))
+	c.Data(http.StatusOK, "application/xml", payload)
+}
+
+// buildGoAMLReport maps a report and its transactions to the goAML schema. The reported customer
+// is always on the _my_client side; the other side is the counterparty leg of a transfer, cash
+// for top ups and withdrawals, or an unspecified party otherwise.
+func (h *AMLHandler) buildGoAMLReport(report *models.SuspiciousTransactionReport, reporter *models.Admin) (*utils.GoAMLReport, error) {
+	var transactions []models.Transaction
+	if len(report.TransactionIDs) > 0 {
+		if err := h.DB.Where("id IN ?", report.TransactionIDs).Order("created_at").Find(&transactions).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	reportingPerson := utils.SplitGoAMLName(reporter.Name)
+	reportingPerson.Email = reporter.Email
+
+	document := &utils.GoAMLReport{
+		RentityID:         utils.GoAMLRentityID(),
+		SubmissionCode:    utils.GOAML_SUBMISSION_ELECTRONIC,
+		ReportCode:        utils.GOAML_REPORT_CODE_STR,
+		EntityReference:   report.ReportNumber,
+		SubmissionDate:    utils.FormatGoAMLDateTime(time.Now()),
+		CurrencyCodeLocal: models.BASE_CURRENCY,
+		ReportingPerson:   reportingPerson,
+		Reason:            report.Reason,
+		Action:            report.Action,
+		Transactions:      make([]utils.GoAMLTransaction, 0, len(transactions)),
+	}
+	if report.Indicators != "" {
+		document.ReportIndicators = strings.Split(report.Indicators, ",")
+	}
+
+	for i, txn := range transactions {
+		amount := txn.Amount
+		if txn.Currency != "" && txn.Currency != models.BASE_CURRENCY && txn.CounterCurrency == models.BASE_CURRENCY {
+			amount = txn.CounterAmount
+		}
+
+		fundsCode := utils.GOAML_FUNDS_OTHER
+		switch txn.Type {
+		case models.TRANSACTION_TYPE_TOPUP, models.TRANSACTION_TYPE_WITHDRAW:
+			fundsCode = utils.GOAML_FUNDS_CASH
+		case models.TRANSACTION_TYPE_TRANSFER_IN, models.TRANSACTION_TYPE_TRANSFER_OUT, models.TRANSACTION_TYPE_INTERBANK_TRANSFER_OUT:
+			fundsCode = utils.GOAML_FUNDS_TRANSFER
+		}
+
+		clientAccount := goAMLAccount(&report.User, txn.Currency)
+		counterpartyAccount, err := h.goAMLCounterpartyAccount(&txn)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := utils.GoAMLTransaction{
+			TransactionNumber:      strconv.Itoa(int(txn.ID)),
+			InternalRefNumber:      fmt.Sprintf("%s-%d", report.ReportNumber, i+1),
+			TransactionLocation:    "Mobile Banking",
+			TransactionDescription: txn.Description,
+			DateTransaction:        utils.FormatGoAMLDateTime(txn.CreatedAt),
+			TransmodeCode:          fundsCode,
+			AmountLocal:            utils.FormatGoAMLAmount(amount),
+		}
+		if containsString(amlInboundTypes, txn.Type) {
+			entry.From = &utils.GoAMLFrom{FundsCode: fundsCode, Account: counterpartyAccount, Country: utils.GOAML_COUNTRY_INDONESIA}
+			entry.ToMyClient = &utils.GoAMLTo{FundsCode: fundsCode, Account: clientAccount, Country: utils.GOAML_COUNTRY_INDONESIA}
+		} else {
+			entry.FromMyClient = &utils.GoAMLFrom{FundsCode: fundsCode, Account: clientAccount, Country: utils.GOAML_COUNTRY_INDONESIA}
+			entry.To = &utils.GoAMLTo{FundsCode: fundsCode, Account: counterpartyAccount, Country: utils.GOAML_COUNTRY_INDONESIA}
+		}
+		document.Transactions = append(document.Transactions, entry)
+	}
+
+	return document, nil
+}
+
+// goAMLCounterpartyAccount returns the account on the other leg of a transfer, or nil when the
+// transaction has no linked counterparty
+func (h *AMLHandler) goAMLCounterpartyAccount(txn *models.Transaction) (*utils.GoAMLAccount, error) {
+	if txn.CounterpartyTxnID == nil {
+		return nil, nil
+	}
+
+	var counterpartTxn models.Transaction
+	if err := h.DB.First(&counterpartTxn, *txn.CounterpartyTxnID).Error; err != nil {
+		return nil, err
+	}
+	var counterparty models.User
+	if err := h.DB.Preload("BankAccounts").First(&counterparty, counterpartTxn.UserID).Error; err != nil {
+		return nil, err
+	}
+	return goAMLAccount(&counterparty, counterpartTxn.Currency), nil
+}
+
+// goAMLAccount describes the user's account in currency, preferring the primary account
+func goAMLAccount(user *models.User, currency string) *utils.GoAMLAccount {
+	if currency == "" {
+		currency = models.BASE_CURRENCY
+	}
+
+	var selected *models.BankAccount
+	for i := range user.BankAccounts {
+		account := &user.BankAccounts[i]
+		accountCurrency := account.Currency
+		if accountCurrency == "" {
+			accountCurrency = models.BASE_CURRENCY
+		}
+		if accountCurrency != currency {
+			continue
+		}
+		if selected == nil || account.IsPrimary {
+			selected = account
+		}
+	}
+
+	account := &utils.GoAMLAccount{
+		InstitutionName: "MBankingCore",
+		CurrencyCode:    currency,
+		Signatory:       &utils.GoAMLSignatory{IsPrimary: true, Person: utils.SplitGoAMLName(user.Name)},
+	}
+	if selected != nil {
+		account.Account = selected.AccountNumber
+		if selected.BankName != "" {
+			account.InstitutionName = selected.BankName
+		}
+	}
+	return account
+}
+
+// SubmitReport - Record that a report was filed with the regulator (Admin only)
+func (h *AMLHandler) SubmitReport(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+
+	var req models.SubmitSTRRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var report models.SuspiciousTransactionReport
+	if err := h.DB.First(&report, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Suspicious transaction report not found",
+		})
+		return
+	}
+	if report.Status != models.STR_STATUS_DRAFT {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Report has already been submitted",
+		})
+		return
+	}
+
+	now := time.Now()
+	adminIDValue := adminID.(uint)
+	report.Status = models.STR_STATUS_SUBMITTED
+	report.SubmittedByAdminID = &adminIDValue
+	report.SubmittedAt = &now
+	report.RegulatorReference = req.RegulatorReference
+	if err := h.DB.Save(&report).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update suspicious transaction report",
+		})
+		return
+	}
+
+	createAuditLog(h.DB, c, "suspicious_transaction_report", report.ID, adminIDValue, "SUBMIT", map[string]interface{}{
+		"report_number":       report.ReportNumber,
+		"regulator_reference": req.RegulatorReference,
+	})
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Suspicious transaction report submitted successfully",
+		Data:    report,
+	})
+}
@@ -111,7 +111,7 @@ func (h *ApprovalThresholdHandler) CreateOrUpdateApprovalThreshold(c *gin.Contex
 			IsActive:              true,
 		}
 
-		if err := h.DB.Create(&threshold).Error; err != nil {
+		if err := h.DB.WithContext(c).Create(&threshold).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 				Code:    http.StatusInternalServerError,
 				Message: "Failed to create approval threshold",
@@ -169,7 +169,7 @@ func (h *ApprovalThresholdHandler) CreateOrUpdateApprovalThreshold(c *gin.Contex
 	existingThreshold.AutoExpireHours = req.AutoExpireHours
 	existingThreshold.IsActive = true
 
-	if err := h.DB.Save(&existingThreshold).Error; err != nil {
+	if err := h.DB.WithContext(c).Save(&existingThreshold).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Code:    http.StatusInternalServerError,
 			Message: "Failed to update approval threshold",
@@ -252,7 +252,7 @@ func (h *ApprovalThresholdHandler) DeactivateApprovalThreshold(c *gin.Context) {
 	}
 
 	threshold.IsActive = false
-	if err := h.DB.Save(&threshold).Error; err != nil {
+	if err := h.DB.WithContext(c).Save(&threshold).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Code:    http.StatusInternalServerError,
 			Message: "Failed to deactivate approval threshold",
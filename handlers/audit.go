@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"encoding/json"
+	"log"
 	"net/http"
 	"strconv"
 	"time"
@@ -33,6 +35,7 @@ func NewAuditHandler() *AuditHandler {
 // @Security BearerAuth
 // @Param entity_type query string false "Filter by entity type"
 // @Param entity_id query int false "Filter by entity ID"
+// @Param entity_key query string false "Filter by entity key, e.g. a config key"
 // @Param user_id query int false "Filter by user ID"
 // @Param admin_id query int false "Filter by admin ID"
 // @Param action query string false "Filter by action"
@@ -58,6 +61,9 @@ func (h *AuditHandler) GetAuditLogs(c *gin.Context) {
 			req.EntityID = uint(id)
 		}
 	}
+	if entityKey := c.Query("entity_key"); entityKey != "" {
+		req.EntityKey = entityKey
+	}
 	if userID := c.Query("user_id"); userID != "" {
 		if id, err := strconv.ParseUint(userID, 10, 32); err == nil {
 			req.UserID = uint(id)
@@ -126,6 +132,7 @@ func (h *AuditHandler) GetAuditLogs(c *gin.Context) {
 // @Param start_date query string false "Start date (YYYY-MM-DD)"
 // @Param end_date query string false "End date (YYYY-MM-DD)"
 // @Param ip_address query string false "Filter by IP address"
+// @Param identifier query string false "Filter by phone or email the login was attempted for"
 // @Param page query int false "Page number (default: 1)"
 // @Param limit query int false "Items per page (default: 10, max: 100)"
 // @Success 200 {object} models.APIResponse{data=models.LoginAuditResponse}
@@ -166,6 +173,9 @@ func (h *AuditHandler) GetLoginAuditLogs(c *gin.Context) {
 	if ipAddress := c.Query("ip_address"); ipAddress != "" {
 		req.IPAddress = ipAddress
 	}
+	if identifier := c.Query("identifier"); identifier != "" {
+		req.Identifier = identifier
+	}
 
 	// Parse pagination
 	page := 1
@@ -194,47 +204,60 @@ func (h *AuditHandler) GetLoginAuditLogs(c *gin.Context) {
 	c.JSON(http.StatusOK, models.NewSuccessResponse(200, "Login audit logs retrieved successfully", response))
 }
 
-// LogUserActivity creates an audit log entry for user activities
-func LogUserActivity(userID uint, action, entityType string, entityID uint, ipAddress, userAgent, endpoint, method string, statusCode int) {
+// LogUserActivity creates an audit log entry for user activities. A JSON request body, when given,
+// is kept as the entry's new values; sensitive fields are redacted as the entry is written.
+func LogUserActivity(userID uint, action, entityType string, entityID uint, entityKey, ipAddress, userAgent, endpoint, method string, statusCode int, requestBody []byte) {
 	auditLog := models.AuditLog{
 		UserID:        &userID,
 		Action:        action,
 		EntityType:    entityType,
 		EntityID:      entityID,
+		EntityKey:     entityKey,
 		IPAddress:     ipAddress,
 		UserAgent:     userAgent,
 		APIEndpoint:   endpoint,
 		RequestMethod: method,
 		StatusCode:    statusCode,
 	}
+	if len(requestBody) > 0 {
+		payload := json.RawMessage(requestBody)
+		auditLog.NewValues = &payload
+	}
 
 	config.DB.Create(&auditLog)
 }
 
-// LogAdminActivity creates an audit log entry for admin activities
-func LogAdminActivity(adminID uint, action, entityType string, entityID uint, ipAddress, userAgent, endpoint, method string, statusCode int) {
+// LogAdminActivity creates an audit log entry for admin activities, keeping the JSON request body
+// like LogUserActivity
+func LogAdminActivity(adminID uint, action, entityType string, entityID uint, entityKey, ipAddress, userAgent, endpoint, method string, statusCode int, requestBody []byte) {
 	auditLog := models.AuditLog{
 		AdminID:       &adminID,
 		Action:        action,
 		EntityType:    entityType,
 		EntityID:      entityID,
+		EntityKey:     entityKey,
 		IPAddress:     ipAddress,
 		UserAgent:     userAgent,
 		APIEndpoint:   endpoint,
 		RequestMethod: method,
 		StatusCode:    statusCode,
 	}
+	if len(requestBody) > 0 {
+		payload := json.RawMessage(requestBody)
+		auditLog.NewValues = &payload
+	}
 
 	config.DB.Create(&auditLog)
 }
 
 // LogLoginActivity creates a login audit entry
-func LogLoginActivity(userID *uint, adminID *uint, loginType, status, ipAddress, userAgent, failureReason string) {
+func LogLoginActivity(userID *uint, adminID *uint, loginType, status, identifier, ipAddress, userAgent, failureReason string) {
 	loginAudit := models.LoginAudit{
 		UserID:        userID,
 		AdminID:       adminID,
 		LoginType:     loginType,
 		Status:        status,
+		Identifier:    identifier,
 		IPAddress:     ipAddress,
 		UserAgent:     userAgent,
 		FailureReason: failureReason,
@@ -242,3 +265,23 @@ func LogLoginActivity(userID *uint, adminID *uint, loginType, status, ipAddress,
 
 	config.DB.Create(&loginAudit)
 }
+
+// createAuditLog records an admin action taken through a handler, with its details as the entry's
+// new values. A failure is logged and does not fail the action itself.
+func createAuditLog(db *gorm.DB, c *gin.Context, entityType string, entityID, adminID uint, action string, details map[string]interface{}) {
+	detailsJSON, _ := json.Marshal(details)
+	detailsRaw := json.RawMessage(detailsJSON)
+
+	auditLog := models.AuditLog{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		AdminID:    &adminID,
+		IPAddress:  c.ClientIP(),
+		NewValues:  &detailsRaw,
+	}
+
+	if err := db.Create(&auditLog).Error; err != nil {
+		log.Printf("Failed to create audit log: %v", err)
+	}
+}
@@ -0,0 +1,719 @@
+package handlers
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"mbankingcore/models"
+	"mbankingcore/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultAuditArchiveAfterDays    = 90
+	defaultAuditRetentionDays       = 2555 // Seven years
+	defaultAuditRestoreHoldDays     = 30
+	auditArchiveBatchSize           = 1000
+	auditRestoreInsertBatchSize     = 200
+	auditPartitionMaintenancePeriod = 24 * time.Hour
+)
+
+// auditRetention is the retention in days of each entity type, with a default for the rest
+type auditRetention struct {
+	days        map[string]int
+	defaultDays int
+}
+
+func (r auditRetention) expired(entityType string, createdAt, now time.Time) bool {
+	days, ok := r.days[entityType]
+	if !ok {
+		days = r.defaultDays
+	}
+	return createdAt.Before(now.AddDate(0, 0, -days))
+}
+
+func (h *AuditHandler) loadAuditRetention() (auditRetention, error) {
+	retention := auditRetention{
+		days:        map[string]int{},
+		defaultDays: int(getConfigInt64(h.DB, "audit_retention_default_days", defaultAuditRetentionDays)),
+	}
+	var policies []models.AuditRetentionPolicy
+	if err := h.DB.Find(&policies).Error; err != nil {
+		return retention, err
+	}
+	for _, policy := range policies {
+		retention.days[policy.EntityType] = policy.RetentionDays
+	}
+	return retention, nil
+}
+
+// auditArchiveRow is a record read from a partition for archiving
+type auditArchiveRow struct {
+	line       models.AuditArchiveRecord
+	entityType string
+	seq        uint64
+	prevHash   string
+	hash       string
+}
+
+// loadAuditArchiveBatch reads the records of a partition after afterSeq in chain order, reducing
+// those past retention to their chain position and hashes
+func loadAuditArchiveBatch(db *gorm.DB, partition models.AuditPartition, afterSeq uint64, retention auditRetention, now time.Time) ([]auditArchiveRow, error) {
+	query := db.Table(partition.Name).Where("chain_seq > ?", afterSeq).Order("chain_seq ASC").Limit(auditArchiveBatchSize)
+
+	var rows []auditArchiveRow
+	switch partition.Table {
+	case models.AUDIT_CHAIN_AUDIT_LOG:
+		var logs []models.AuditLog
+		if err := query.Find(&logs).Error; err != nil {
+			return nil, err
+		}
+		for i := range logs {
+			row := auditArchiveRow{entityType: logs[i].EntityType, seq: *logs[i].ChainSeq, prevHash: logs[i].PrevHash, hash: logs[i].Hash}
+			row.line = models.NewAuditLogArchiveRecord(&logs[i], retention.expired(logs[i].EntityType, logs[i].CreatedAt, now))
+			rows = append(rows, row)
+		}
+	case models.AUDIT_CHAIN_LOGIN_AUDIT:
+		var logs []models.LoginAudit
+		if err := query.Find(&logs).Error; err != nil {
+			return nil, err
+		}
+		for i := range logs {
+			row := auditArchiveRow{entityType: models.AUDIT_RETENTION_ENTITY_LOGIN, seq: *logs[i].ChainSeq, prevHash: logs[i].PrevHash, hash: logs[i].Hash}
+			row.line = models.NewLoginAuditArchiveRecord(&logs[i], retention.expired(models.AUDIT_RETENTION_ENTITY_LOGIN, logs[i].CreatedAt, now))
+			rows = append(rows, row)
+		}
+	default:
+		return nil, fmt.Errorf("unknown audit table %q", partition.Table)
+	}
+	return rows, nil
+}
+
+// auditArchiveManifestPath returns the manifest file that sits next to an archive file
+func auditArchiveManifestPath(archivePath string) string {
+	return strings.TrimSuffix(archivePath, ".jsonl.gz") + ".manifest.json"
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// ArchiveAuditPartitions archives every monthly audit partition that ended more than
+// audit_archive_after_days ago: its records are written to a gzip-compressed JSON-lines file with a
+// signed manifest, the archive is recorded in audit_archives and the partition is dropped. Partitions
+// restored for an investigation are dropped again, without a new archive, audit_restore_hold_days
+// after the restore. With dryRun the partitions that would be archived are returned unchanged.
+func (h *AuditHandler) ArchiveAuditPartitions(dir, createdBy string, dryRun bool) ([]models.AuditArchive, error) {
+	now := time.Now()
+	archiveAfter := getConfigInt64(h.DB, "audit_archive_after_days", defaultAuditArchiveAfterDays)
+	restoreHold := getConfigInt64(h.DB, "audit_restore_hold_days", defaultAuditRestoreHoldDays)
+	cutoff := now.AddDate(0, 0, -int(archiveAfter))
+
+	retention, err := h.loadAuditRetention()
+	if err != nil {
+		return nil, err
+	}
+
+	var archives []models.AuditArchive
+	for _, chain := range []string{models.AUDIT_CHAIN_AUDIT_LOG, models.AUDIT_CHAIN_LOGIN_AUDIT} {
+		partitions, err := models.ListAuditPartitions(h.DB, chain)
+		if err != nil {
+			return archives, err
+		}
+		headSeq, _, err := models.AuditChainHead(h.DB, chain)
+		if err != nil {
+			return archives, err
+		}
+
+		for _, partition := range partitions {
+			if partition.To.After(cutoff) {
+				continue
+			}
+
+			var existing models.AuditArchive
+			if err := h.DB.Where("partition = ?", partition.Name).First(&existing).Error; err == nil {
+				// Restored for an investigation; the archive already holds these records
+				if existing.RestoredAt != nil && existing.RestoredAt.Before(now.AddDate(0, 0, -int(restoreHold))) && !dryRun {
+					if err := h.dropRestoredPartition(partition, &existing); err != nil {
+						return archives, err
+					}
+					log.Printf("Audit archive: dropped restored partition %s", partition.Name)
+				}
+				continue
+			} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return archives, err
+			}
+
+			var unsealed int64
+			if err := h.DB.Table(partition.Name).Where("chain_seq IS NULL").Count(&unsealed).Error; err != nil {
+				return archives, err
+			}
+			if unsealed > 0 {
+				log.Printf("Audit archive: skipping %s, %d records are not sealed yet", partition.Name, unsealed)
+				continue
+			}
+
+			// The sealer links new records to the head of the chain, so it must stay in the database
+			var holdsHead int64
+			if err := h.DB.Table(partition.Name).Where("chain_seq = ?", headSeq).Count(&holdsHead).Error; err != nil {
+				return archives, err
+			}
+			if holdsHead > 0 {
+				log.Printf("Audit archive: skipping %s, it holds the head of the chain", partition.Name)
+				continue
+			}
+
+			if dryRun {
+				var count int64
+				h.DB.Table(partition.Name).Count(&count)
+				archives = append(archives, models.AuditArchive{
+					Chain: chain, Partition: partition.Name, PeriodStart: partition.From, PeriodEnd: partition.To, RecordCount: count,
+				})
+				continue
+			}
+
+			archive, err := h.archivePartition(dir, partition, retention, createdBy, now)
+			if err != nil {
+				return archives, fmt.Errorf("failed to archive %s: %v", partition.Name, err)
+			}
+			if archive != nil {
+				archives = append(archives, *archive)
+			}
+		}
+	}
+	return archives, nil
+}
+
+// archivePartition writes one partition to its archive file and manifest, checks the file, then
+// records the archive and drops the partition in one transaction. Empty partitions are dropped
+// without an archive and nil is returned.
+func (h *AuditHandler) archivePartition(dir string, partition models.AuditPartition, retention auditRetention, createdBy string, now time.Time) (*models.AuditArchive, error) {
+	fileName := filepath.Join(partition.Table, partition.Name+".jsonl.gz")
+	path := filepath.Join(dir, fileName)
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path+".tmp", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(path + ".tmp")
+
+	hasher := sha256.New()
+	counter := &countingWriter{}
+	compressed := gzip.NewWriter(io.MultiWriter(file, hasher, counter))
+	encoder := json.NewEncoder(compressed)
+
+	archive := &models.AuditArchive{
+		Chain:       partition.Table,
+		Partition:   partition.Name,
+		PeriodStart: partition.From,
+		PeriodEnd:   partition.To,
+		FileName:    fileName,
+		CreatedBy:   createdBy,
+	}
+	entityCounts := map[string]int64{}
+	var ranges []models.AuditArchiveRange
+
+	var lastSeq uint64
+	for {
+		rows, err := loadAuditArchiveBatch(h.DB, partition, lastSeq, retention, now)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		for _, row := range rows {
+			if err := encoder.Encode(row.line); err != nil {
+				file.Close()
+				return nil, err
+			}
+
+			archive.RecordCount++
+			if row.line.Pruned {
+				archive.PrunedCount++
+			}
+			entityCounts[row.entityType]++
+			if archive.FirstSeq == 0 {
+				archive.FirstSeq = row.seq
+			}
+			archive.LastSeq = row.seq
+
+			// Rows written around a month boundary can interleave with the next partition's, so an
+			// archive may hold more than one run of chain positions
+			if n := len(ranges); n > 0 && ranges[n-1].ToSeq+1 == row.seq {
+				ranges[n-1].ToSeq = row.seq
+				ranges[n-1].Hash = row.hash
+			} else {
+				ranges = append(ranges, models.AuditArchiveRange{FromSeq: row.seq, ToSeq: row.seq, PrevHash: row.prevHash, Hash: row.hash})
+			}
+			lastSeq = row.seq
+		}
+		if len(rows) < auditArchiveBatchSize {
+			break
+		}
+	}
+
+	if err := compressed.Close(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+
+	if archive.RecordCount == 0 {
+		if err := models.DropAuditPartition(h.DB, partition); err != nil {
+			return nil, err
+		}
+		log.Printf("Audit archive: dropped empty partition %s", partition.Name)
+		return nil, nil
+	}
+
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return nil, err
+	}
+	archive.SHA256 = hex.EncodeToString(hasher.Sum(nil))
+	archive.SizeBytes = counter.n
+	rangesJSON, _ := json.Marshal(ranges)
+	countsJSON, _ := json.Marshal(entityCounts)
+	archive.SeqRanges = (*json.RawMessage)(&rangesJSON)
+	archive.EntityCounts = (*json.RawMessage)(&countsJSON)
+	archive.CreatedAt = now
+
+	payload, err := archive.SigningPayload()
+	if err != nil {
+		return nil, err
+	}
+	archive.Signature = utils.SignAuditArchive(payload)
+
+	manifest, _ := json.MarshalIndent(archive, "", "  ")
+	if err := os.WriteFile(auditArchiveManifestPath(path), manifest, 0640); err != nil {
+		return nil, err
+	}
+
+	// Read the archive back before the partition is dropped
+	if _, err := readAuditArchive(path, archive, nil); err != nil {
+		return nil, fmt.Errorf("archive check failed: %v", err)
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(archive).Error; err != nil {
+			return err
+		}
+		return models.DropAuditPartition(tx, partition)
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Audit archive: %s archived to %s (%d records, %d pruned)", partition.Name, path, archive.RecordCount, archive.PrunedCount)
+	return archive, nil
+}
+
+// dropRestoredPartition drops a partition that was restored from an archive, once it holds no
+// records beyond those in the archive
+func (h *AuditHandler) dropRestoredPartition(partition models.AuditPartition, archive *models.AuditArchive) error {
+	var count int64
+	if err := h.DB.Table(partition.Name).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > archive.RecordCount-archive.PrunedCount {
+		return fmt.Errorf("restored partition %s holds %d records, more than its archive", partition.Name, count)
+	}
+	return h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := models.DropAuditPartition(tx, partition); err != nil {
+			return err
+		}
+		return tx.Model(archive).Update("restored_at", nil).Error
+	})
+}
+
+// readAuditArchive checks an archive file against its manifest and passes each record to fn, which
+// may be nil; returns the number of records read
+func readAuditArchive(path string, archive *models.AuditArchive, fn func(models.AuditArchiveRecord) error) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return 0, err
+	}
+	if hex.EncodeToString(hasher.Sum(nil)) != archive.SHA256 {
+		return 0, errors.New("archive file does not match the checksum in its manifest")
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	compressed, err := gzip.NewReader(bufio.NewReader(file))
+	if err != nil {
+		return 0, err
+	}
+	defer compressed.Close()
+
+	var count int64
+	decoder := json.NewDecoder(compressed)
+	for {
+		var record models.AuditArchiveRecord
+		if err := decoder.Decode(&record); err == io.EOF {
+			break
+		} else if err != nil {
+			return count, fmt.Errorf("record %d: %v", count+1, err)
+		}
+		count++
+		record.RestoreJSONColumns()
+		if fn != nil {
+			if err := fn(record); err != nil {
+				return count, fmt.Errorf("record %d: %v", count, err)
+			}
+		}
+	}
+	if count != archive.RecordCount {
+		return count, fmt.Errorf("archive holds %d records, its manifest lists %d", count, archive.RecordCount)
+	}
+	return count, nil
+}
+
+// AuditRestoreResult summarises the restore of one archive
+type AuditRestoreResult struct {
+	Partition      string `json:"partition"`
+	Records        int64  `json:"records"`         // Records in the archive
+	Restored       int64  `json:"restored"`        // Records inserted
+	AlreadyPresent int64  `json:"already_present"` // Records already in the database
+	Pruned         int64  `json:"pruned"`          // Records archived without content, not restored
+}
+
+// LoadAuditArchiveManifest reads an archive manifest and checks its signature
+func LoadAuditArchiveManifest(manifestPath string) (*models.AuditArchive, error) {
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	var archive models.AuditArchive
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %v", err)
+	}
+	payload, err := archive.SigningPayload()
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest: %v", err)
+	}
+	if !models.IsValidAuditChain(archive.Chain) || !utils.VerifyAuditArchive(payload, archive.Signature) {
+		return nil, errors.New("manifest signature does not verify")
+	}
+	return &archive, nil
+}
+
+// RestoreAuditArchive puts the records of an archive back into their partition for an
+// investigation. Every record's hash is checked before anything is written, and rows are inserted
+// with plain SQL so that no model hooks or GORM callbacks (payload redaction, change capture) alter
+// them: chain positions and hashes are restored exactly and the chain verifies as before. Pruned
+// records, archived without content, are not restored. With verifyOnly the archive is checked and
+// nothing is written.
+func (h *AuditHandler) RestoreAuditArchive(manifestPath string, verifyOnly bool) (*AuditRestoreResult, error) {
+	archive, err := LoadAuditArchiveManifest(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(filepath.Dir(manifestPath), filepath.Base(archive.FileName))
+	result := &AuditRestoreResult{Partition: archive.Partition}
+
+	var pending []interface{}
+	check := func(record models.AuditArchiveRecord) error {
+		if record.Pruned {
+			result.Pruned++
+			return nil
+		}
+		switch {
+		case archive.Chain == models.AUDIT_CHAIN_AUDIT_LOG && record.AuditLog != nil:
+			if record.AuditLog.ComputeChainHash() != record.AuditLog.Hash {
+				return fmt.Errorf("audit log %d does not match its hash", record.AuditLog.ID)
+			}
+			pending = append(pending, record.AuditLog)
+		case archive.Chain == models.AUDIT_CHAIN_LOGIN_AUDIT && record.LoginAudit != nil:
+			if record.LoginAudit.ComputeChainHash() != record.LoginAudit.Hash {
+				return fmt.Errorf("login audit %d does not match its hash", record.LoginAudit.ID)
+			}
+			pending = append(pending, record.LoginAudit)
+		default:
+			return errors.New("record does not belong to the archive's chain")
+		}
+		return nil
+	}
+
+	result.Records, err = readAuditArchive(path, archive, check)
+	if err != nil || verifyOnly {
+		return result, err
+	}
+
+	if _, err := models.EnsureAuditPartition(h.DB, archive.Chain, archive.PeriodStart); err != nil {
+		return result, err
+	}
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		for start := 0; start < len(pending); start += auditRestoreInsertBatchSize {
+			end := start + auditRestoreInsertBatchSize
+			if end > len(pending) {
+				end = len(pending)
+			}
+			inserted, err := insertAuditRowsRaw(tx, archive.Chain, pending[start:end])
+			if err != nil {
+				return err
+			}
+			result.Restored += inserted
+		}
+		return tx.Model(&models.AuditArchive{}).Where("partition = ?", archive.Partition).
+			Update("restored_at", time.Now()).Error
+	})
+	result.AlreadyPresent = int64(len(pending)) - result.Restored
+	return result, err
+}
+
+// insertAuditRowsRaw inserts audit rows with a plain INSERT, bypassing GORM's create callbacks;
+// rows already present are skipped. Returns the number of rows inserted.
+func insertAuditRowsRaw(tx *gorm.DB, table string, rows []interface{}) (int64, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(rows[0]); err != nil {
+		return 0, err
+	}
+
+	var columns []string
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName != "" {
+			columns = append(columns, `"`+field.DBName+`"`)
+		}
+	}
+	placeholders := "(" + strings.TrimSuffix(strings.Repeat("?,", len(columns)), ",") + ")"
+
+	var tuples []string
+	var values []interface{}
+	for _, row := range rows {
+		rowValue := reflect.ValueOf(row).Elem()
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			value, _ := field.ValueOf(tx.Statement.Context, rowValue)
+			switch v := value.(type) {
+			case *json.RawMessage:
+				if v == nil {
+					value = nil
+				} else {
+					value = string(*v)
+				}
+			case string:
+				// Missing addresses are stored as NULL, an empty string is not a valid inet
+				if v == "" && strings.EqualFold(field.TagSettings["TYPE"], "inet") {
+					value = nil
+				}
+			}
+			values = append(values, value)
+		}
+		tuples = append(tuples, placeholders)
+	}
+
+	result := tx.Exec(fmt.Sprintf("INSERT INTO %s (%s) VALUES %s ON CONFLICT DO NOTHING",
+		table, strings.Join(columns, ", "), strings.Join(tuples, ", ")), values...)
+	return result.RowsAffected, result.Error
+}
+
+// RunAuditPartitionMaintenance creates upcoming audit partitions daily, intended to run in its own
+// goroutine for the lifetime of the server. Archiving is left to the audit-archive command, which
+// runs where the archive directory is kept.
+func (h *AuditHandler) RunAuditPartitionMaintenance() {
+	ticker := time.NewTicker(auditPartitionMaintenancePeriod)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := models.EnsureAuditPartitions(h.DB, time.Now()); err != nil {
+			log.Printf("Audit partition maintenance: %v", err)
+		}
+	}
+}
+
+// loadAuditArchivedRanges returns the archived ranges of a chain ordered by position, reporting
+// archives whose manifest signature does not verify; their ranges are not trusted
+func (h *AuditHandler) loadAuditArchivedRanges(chain string, report *AuditChainReport) ([]models.AuditArchiveRange, error) {
+	var archives []models.AuditArchive
+	if err := h.DB.Where("chain = ?", chain).Order("first_seq ASC").Find(&archives).Error; err != nil {
+		return nil, err
+	}
+
+	var archived []models.AuditArchiveRange
+	for i := range archives {
+		report.ArchivesChecked++
+		payload, err := archives[i].SigningPayload()
+		if err != nil || !utils.VerifyAuditArchive(payload, archives[i].Signature) {
+			report.addIssue(models.AUDIT_CHAIN_ISSUE_ARCHIVE_INVALID, archives[i].FirstSeq, 0,
+				fmt.Sprintf("archive %d of %s has an invalid signature", archives[i].ID, archives[i].Partition))
+			continue
+		}
+		ranges, _ := archives[i].Ranges()
+		archived = append(archived, ranges...)
+	}
+	sort.Slice(archived, func(i, j int) bool { return archived[i].FromSeq < archived[j].FromSeq })
+	return archived, nil
+}
+
+// GetAuditRetentionPolicies lists the audit retention policies
+// @Summary Get audit retention policies
+// @Description Retention per entity type and the default for other types (Admin only)
+// @Tags Audit
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/admin/audit-retention [get]
+func (h *AuditHandler) GetAuditRetentionPolicies(c *gin.Context) {
+	var policies []models.AuditRetentionPolicy
+	if err := h.DB.Order("entity_type ASC").Find(&policies).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(500, "Failed to retrieve audit retention policies"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(200, "Audit retention policies retrieved successfully", gin.H{
+		"policies":               policies,
+		"default_retention_days": getConfigInt64(h.DB, "audit_retention_default_days", defaultAuditRetentionDays),
+		"archive_after_days":     getConfigInt64(h.DB, "audit_archive_after_days", defaultAuditArchiveAfterDays),
+	}))
+}
+
+// UpdateAuditRetentionPolicy sets the retention of an entity type
+// @Summary Update audit retention policy
+// @Description Create or update the retention of an audit entity type; "login" covers login audits (Admin only)
+// @Tags Audit
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param entity_type path string true "Audit entity type"
+// @Param request body models.AuditRetentionPolicyRequest true "Retention"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/admin/audit-retention/{entity_type} [put]
+func (h *AuditHandler) UpdateAuditRetentionPolicy(c *gin.Context) {
+	entityType := strings.TrimSpace(c.Param("entity_type"))
+	if entityType == "" || len(entityType) > 50 {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(400, "Invalid entity type"))
+		return
+	}
+
+	var req models.AuditRetentionPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(400, "Invalid request format: "+err.Error()))
+		return
+	}
+
+	var updatedBy *uint
+	if adminID, ok := c.Get("admin_id"); ok {
+		if id, ok := adminID.(uint); ok {
+			updatedBy = &id
+		}
+	}
+
+	db := h.DB.WithContext(c)
+	var policy models.AuditRetentionPolicy
+	err := db.Where("entity_type = ?", entityType).First(&policy).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		policy = models.AuditRetentionPolicy{
+			EntityType:    entityType,
+			RetentionDays: req.RetentionDays,
+			Description:   req.Description,
+			UpdatedBy:     updatedBy,
+		}
+		err = db.Create(&policy).Error
+	case err == nil:
+		policy.RetentionDays = req.RetentionDays
+		policy.Description = req.Description
+		policy.UpdatedBy = updatedBy
+		err = db.Save(&policy).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(500, "Failed to update audit retention policy"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(200, "Audit retention policy updated successfully", policy))
+}
+
+// GetAuditArchives lists archived audit partitions
+// @Summary Get audit archives
+// @Description List audit partitions archived to files, newest first (Admin only)
+// @Tags Audit
+// @Produce json
+// @Security BearerAuth
+// @Param chain query string false "audit_logs or login_audits"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 10, max: 100)"
+// @Success 200 {object} models.APIResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/admin/audit-archives [get]
+func (h *AuditHandler) GetAuditArchives(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	query := h.DB.Model(&models.AuditArchive{})
+	if chain := c.Query("chain"); chain != "" {
+		query = query.Where("chain = ?", chain)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(500, "Failed to retrieve audit archives"))
+		return
+	}
+
+	var archives []models.AuditArchive
+	if err := query.Order("period_start DESC, id DESC").Offset((page - 1) * limit).Limit(limit).Find(&archives).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(500, "Failed to retrieve audit archives"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(200, "Audit archives retrieved successfully", gin.H{
+		"archives": archives,
+		"pagination": gin.H{
+			"page":        page,
+			"limit":       limit,
+			"total":       total,
+			"total_pages": int(math.Ceil(float64(total) / float64(limit))),
+		},
+	}))
+}
@@ -0,0 +1,461 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mbankingcore/models"
+	"mbankingcore/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	auditChainBatchSize                  = 1000
+	maxAuditChainIssues                  = 500 // Issues listed in a report, the total is always counted
+	defaultAuditChainSealIntervalSeconds = 10
+	auditUnsealedGracePeriod             = 10 * time.Minute
+)
+
+// AuditChainIssue is one problem found while verifying an audit chain
+type AuditChainIssue struct {
+	Type     string `json:"type"`
+	ChainSeq uint64 `json:"chain_seq,omitempty"`
+	RecordID uint   `json:"record_id,omitempty"`
+	Detail   string `json:"detail"`
+}
+
+// AuditChainReport is the result of verifying one audit chain
+type AuditChainReport struct {
+	Chain              string            `json:"chain"`
+	Valid              bool              `json:"valid"`
+	RecordsChecked     int64             `json:"records_checked"`
+	FirstSeq           uint64            `json:"first_seq"`
+	LastSeq            uint64            `json:"last_seq"`
+	HeadHash           string            `json:"head_hash"`
+	UnsealedRecords    int64             `json:"unsealed_records"` // Written but not yet linked into the chain
+	CheckpointsChecked int               `json:"checkpoints_checked"`
+	LastCheckpointSeq  uint64            `json:"last_checkpoint_seq"`
+	ArchivesChecked    int               `json:"archives_checked"`
+	ArchivedRecords    uint64            `json:"archived_records"` // Positions held by archives rather than the database
+	IssueCount         int               `json:"issue_count"`
+	Issues             []AuditChainIssue `json:"issues"`
+	VerifiedAt         time.Time         `json:"verified_at"`
+}
+
+func (r *AuditChainReport) addIssue(issueType string, seq uint64, recordID uint, detail string) {
+	r.IssueCount++
+	if len(r.Issues) < maxAuditChainIssues {
+		r.Issues = append(r.Issues, AuditChainIssue{Type: issueType, ChainSeq: seq, RecordID: recordID, Detail: detail})
+	}
+}
+
+// auditChainRecord is the part of an audit row needed to verify its place in the chain
+type auditChainRecord struct {
+	ID           uint
+	ChainSeq     uint64
+	PrevHash     string
+	Hash         string
+	ComputedHash string
+}
+
+// loadAuditChainBatch returns up to limit chained records of a chain after afterSeq, in chain order
+func loadAuditChainBatch(db *gorm.DB, chain string, afterSeq uint64, limit int) ([]auditChainRecord, error) {
+	query := db.Where("chain_seq > ?", afterSeq).Order("chain_seq ASC").Limit(limit)
+
+	var records []auditChainRecord
+	switch chain {
+	case models.AUDIT_CHAIN_AUDIT_LOG:
+		var logs []models.AuditLog
+		if err := query.Find(&logs).Error; err != nil {
+			return nil, err
+		}
+		for i := range logs {
+			records = append(records, auditChainRecord{
+				ID: logs[i].ID, ChainSeq: *logs[i].ChainSeq, PrevHash: logs[i].PrevHash,
+				Hash: logs[i].Hash, ComputedHash: logs[i].ComputeChainHash(),
+			})
+		}
+	case models.AUDIT_CHAIN_LOGIN_AUDIT:
+		var logs []models.LoginAudit
+		if err := query.Find(&logs).Error; err != nil {
+			return nil, err
+		}
+		for i := range logs {
+			records = append(records, auditChainRecord{
+				ID: logs[i].ID, ChainSeq: *logs[i].ChainSeq, PrevHash: logs[i].PrevHash,
+				Hash: logs[i].Hash, ComputedHash: logs[i].ComputeChainHash(),
+			})
+		}
+	default:
+		return nil, fmt.Errorf("unknown audit chain %q", chain)
+	}
+	return records, nil
+}
+
+// auditChainModel returns the model backing a chain
+func auditChainModel(chain string) interface{} {
+	if chain == models.AUDIT_CHAIN_LOGIN_AUDIT {
+		return &models.LoginAudit{}
+	}
+	return &models.AuditLog{}
+}
+
+// VerifyChain walks a chain from its first record and reports gaps, broken links, modified
+// records, records left unsealed and checkpoints that no longer match. Positions held by archived
+// partitions are not gaps: their signed manifests give the hashes that link them to the records
+// around them.
+func (h *AuditHandler) VerifyChain(chain string) (*AuditChainReport, error) {
+	if !models.IsValidAuditChain(chain) {
+		return nil, fmt.Errorf("unknown audit chain %q", chain)
+	}
+
+	report := &AuditChainReport{Chain: chain, Issues: []AuditChainIssue{}}
+
+	var checkpoints []models.AuditCheckpoint
+	if err := h.DB.Where("chain = ?", chain).Order("chain_seq ASC").Find(&checkpoints).Error; err != nil {
+		return nil, err
+	}
+	checkpointsBySeq := make(map[uint64]models.AuditCheckpoint, len(checkpoints))
+	for _, checkpoint := range checkpoints {
+		report.CheckpointsChecked++
+		report.LastCheckpointSeq = checkpoint.ChainSeq
+		if !utils.VerifyAuditCheckpoint(checkpoint.Chain, checkpoint.ChainSeq, checkpoint.Hash, checkpoint.Signature) {
+			report.addIssue(models.AUDIT_CHAIN_ISSUE_CHECKPOINT_INVALID, checkpoint.ChainSeq, 0,
+				fmt.Sprintf("checkpoint %d has an invalid signature", checkpoint.ID))
+			continue
+		}
+		checkpointsBySeq[checkpoint.ChainSeq] = checkpoint
+	}
+
+	archived, err := h.loadAuditArchivedRanges(chain, report)
+	if err != nil {
+		return nil, err
+	}
+
+	var expectedSeq uint64 = 1
+	var prevHash string
+	var lastSeq uint64
+	linked := true // Whether prevHash is the hash at expectedSeq-1
+
+	// skipArchived moves past the archived positions from expectedSeq up to before. The hash of an
+	// archived position is only known where a range ends, so a record following a position that is
+	// archived in the middle of a range, such as one pruned from a restored partition, is not linked.
+	nextRange := 0
+	skipArchived := func(before uint64) {
+		for expectedSeq < before {
+			for nextRange < len(archived) && archived[nextRange].ToSeq < expectedSeq {
+				nextRange++
+			}
+			if nextRange == len(archived) || archived[nextRange].FromSeq > expectedSeq {
+				return
+			}
+			r := archived[nextRange]
+			if report.FirstSeq == 0 {
+				report.FirstSeq = expectedSeq
+			}
+			if r.FromSeq == expectedSeq && linked && r.PrevHash != prevHash {
+				report.addIssue(models.AUDIT_CHAIN_ISSUE_BROKEN_LINK, r.FromSeq, 0,
+					"archived records do not link to the hash of the previous record")
+			}
+
+			end := r.ToSeq
+			if end >= before {
+				end = before - 1
+			}
+			report.ArchivedRecords += end - expectedSeq + 1
+			expectedSeq = end + 1
+			report.LastSeq = end
+			linked = end == r.ToSeq
+			if !linked {
+				continue
+			}
+			if checkpoint, ok := checkpointsBySeq[r.ToSeq]; ok && checkpoint.Hash != r.Hash {
+				report.addIssue(models.AUDIT_CHAIN_ISSUE_CHECKPOINT_MISMATCH, r.ToSeq, 0,
+					fmt.Sprintf("archived hash differs from checkpoint %d", checkpoint.ID))
+			}
+			prevHash = r.Hash
+			report.HeadHash = r.Hash
+		}
+	}
+
+	for {
+		records, err := loadAuditChainBatch(h.DB, chain, lastSeq, auditChainBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			skipArchived(record.ChainSeq)
+			if report.FirstSeq == 0 {
+				report.FirstSeq = record.ChainSeq
+			}
+			report.RecordsChecked++
+
+			if record.ChainSeq > expectedSeq {
+				report.addIssue(models.AUDIT_CHAIN_ISSUE_GAP, expectedSeq, 0,
+					fmt.Sprintf("records %d to %d are missing", expectedSeq, record.ChainSeq-1))
+				for _, checkpoint := range checkpoints {
+					if checkpoint.ChainSeq >= expectedSeq && checkpoint.ChainSeq < record.ChainSeq {
+						report.addIssue(models.AUDIT_CHAIN_ISSUE_CHECKPOINT_MISMATCH, checkpoint.ChainSeq, 0,
+							fmt.Sprintf("record at checkpoint %d is missing", checkpoint.ID))
+					}
+				}
+			} else if linked && record.PrevHash != prevHash {
+				report.addIssue(models.AUDIT_CHAIN_ISSUE_BROKEN_LINK, record.ChainSeq, record.ID,
+					"prev_hash does not match the hash of the previous record")
+			}
+
+			if record.ComputedHash != record.Hash {
+				report.addIssue(models.AUDIT_CHAIN_ISSUE_HASH_MISMATCH, record.ChainSeq, record.ID,
+					"record content does not match its hash")
+			}
+
+			if checkpoint, ok := checkpointsBySeq[record.ChainSeq]; ok && checkpoint.Hash != record.Hash {
+				report.addIssue(models.AUDIT_CHAIN_ISSUE_CHECKPOINT_MISMATCH, record.ChainSeq, record.ID,
+					fmt.Sprintf("hash differs from checkpoint %d", checkpoint.ID))
+			}
+
+			// Continue from the stored hash so one modified record is reported once, not for the rest of the chain
+			prevHash = record.Hash
+			linked = true
+			expectedSeq = record.ChainSeq + 1
+			lastSeq = record.ChainSeq
+			report.LastSeq = record.ChainSeq
+			report.HeadHash = record.Hash
+		}
+		if len(records) < auditChainBatchSize {
+			break
+		}
+	}
+	skipArchived(math.MaxUint64)
+
+	for _, checkpoint := range checkpoints {
+		if checkpoint.ChainSeq > report.LastSeq {
+			report.addIssue(models.AUDIT_CHAIN_ISSUE_TRUNCATED, checkpoint.ChainSeq, 0,
+				fmt.Sprintf("checkpoint %d is past the last record %d", checkpoint.ID, report.LastSeq))
+		}
+	}
+
+	// Records are sealed shortly after they are written; one that stays unsealed means the sealer
+	// is not running or the record was inserted in a way that keeps it out of the chain
+	model := auditChainModel(chain)
+	if err := h.DB.Model(model).Where("chain_seq IS NULL").Count(&report.UnsealedRecords).Error; err != nil {
+		return nil, err
+	}
+	var staleIDs []uint
+	if err := h.DB.Model(model).Where("chain_seq IS NULL AND created_at < ?", time.Now().Add(-auditUnsealedGracePeriod)).
+		Order("id ASC").Limit(maxAuditChainIssues).Pluck("id", &staleIDs).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range staleIDs {
+		report.addIssue(models.AUDIT_CHAIN_ISSUE_UNSEALED, 0, id, "record has not been sealed into the chain")
+	}
+
+	report.Valid = report.IssueCount == 0
+	report.VerifiedAt = time.Now()
+	return report, nil
+}
+
+// SealChains links newly written audit and login audit records into their chains
+func (h *AuditHandler) SealChains() (map[string]int, error) {
+	sealed := map[string]int{}
+	for _, chain := range []string{models.AUDIT_CHAIN_AUDIT_LOG, models.AUDIT_CHAIN_LOGIN_AUDIT} {
+		count, err := models.SealAuditChain(h.DB, chain)
+		if err != nil {
+			return sealed, fmt.Errorf("failed to seal %s: %v", chain, err)
+		}
+		sealed[chain] = count
+	}
+	return sealed, nil
+}
+
+// RunChainSealer seals the audit chains every audit_chain_seal_interval_seconds, intended to run
+// in its own goroutine for the lifetime of the server
+func (h *AuditHandler) RunChainSealer() {
+	interval := time.Duration(getConfigInt64(h.DB, "audit_chain_seal_interval_seconds", defaultAuditChainSealIntervalSeconds)) * time.Second
+	if interval <= 0 {
+		interval = defaultAuditChainSealIntervalSeconds * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if _, err := h.SealChains(); err != nil {
+			log.Printf("Audit chain sealer: %v", err)
+		}
+	}
+}
+
+// CreateCheckpoint signs the current head of a chain. The head is returned unchanged when it
+// already has a checkpoint; nil is returned for an empty chain.
+func (h *AuditHandler) CreateCheckpoint(chain, createdBy string) (*models.AuditCheckpoint, error) {
+	seq, hash, err := models.AuditChainHead(h.DB, chain)
+	if err != nil {
+		return nil, err
+	}
+	if seq == 0 {
+		return nil, nil
+	}
+
+	var checkpoint models.AuditCheckpoint
+	if err := h.DB.Where("chain = ? AND chain_seq = ?", chain, seq).First(&checkpoint).Error; err == nil {
+		return &checkpoint, nil
+	}
+
+	checkpoint = models.AuditCheckpoint{
+		Chain:     chain,
+		ChainSeq:  seq,
+		Hash:      hash,
+		Signature: utils.SignAuditCheckpoint(chain, seq, hash),
+		CreatedBy: createdBy,
+	}
+	if err := h.DB.Create(&checkpoint).Error; err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+// auditChainsFromQuery returns the chain named in the "chain" query parameter, or both chains
+func auditChainsFromQuery(c *gin.Context) ([]string, bool) {
+	chain := c.Query("chain")
+	if chain == "" {
+		return []string{models.AUDIT_CHAIN_AUDIT_LOG, models.AUDIT_CHAIN_LOGIN_AUDIT}, true
+	}
+	if !models.IsValidAuditChain(chain) {
+		return nil, false
+	}
+	return []string{chain}, true
+}
+
+// VerifyAuditChain verifies the audit hash chains
+// @Summary Verify audit chains
+// @Description Verify the hash chain of audit logs and login audits and report gaps or modified records (Admin only)
+// @Tags Audit
+// @Produce json
+// @Security BearerAuth
+// @Param chain query string false "audit_logs or login_audits (default: both)"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/admin/audit-chain/verify [get]
+func (h *AuditHandler) VerifyAuditChain(c *gin.Context) {
+	chains, ok := auditChainsFromQuery(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(400, "Invalid chain, use audit_logs or login_audits"))
+		return
+	}
+
+	reports := make([]*AuditChainReport, 0, len(chains))
+	valid := true
+	for _, chain := range chains {
+		report, err := h.VerifyChain(chain)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse(500, "Failed to verify audit chain"))
+			return
+		}
+		valid = valid && report.Valid
+		reports = append(reports, report)
+	}
+
+	message := "Audit chains verified successfully"
+	if !valid {
+		message = "Audit chain verification found issues"
+	}
+	c.JSON(http.StatusOK, models.NewSuccessResponse(200, message, gin.H{
+		"valid":   valid,
+		"reports": reports,
+	}))
+}
+
+// CreateAuditCheckpoint signs the current head of the audit chains
+// @Summary Create audit checkpoint
+// @Description Sign the current head of the audit chains (Admin only)
+// @Tags Audit
+// @Produce json
+// @Security BearerAuth
+// @Param chain query string false "audit_logs or login_audits (default: both)"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/admin/audit-chain/checkpoints [post]
+func (h *AuditHandler) CreateAuditCheckpoint(c *gin.Context) {
+	chains, ok := auditChainsFromQuery(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(400, "Invalid chain, use audit_logs or login_audits"))
+		return
+	}
+
+	createdBy := "admin"
+	if adminID, exists := c.Get("admin_id"); exists {
+		createdBy = fmt.Sprintf("admin:%v", adminID)
+	}
+
+	checkpoints := []models.AuditCheckpoint{}
+	for _, chain := range chains {
+		checkpoint, err := h.CreateCheckpoint(chain, createdBy)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse(500, "Failed to create audit checkpoint"))
+			return
+		}
+		if checkpoint != nil {
+			checkpoints = append(checkpoints, *checkpoint)
+		}
+	}
+
+	c.JSON(http.StatusCreated, models.NewSuccessResponse(201, "Audit checkpoints created successfully", checkpoints))
+}
+
+// GetAuditCheckpoints lists signed audit chain checkpoints
+// @Summary Get audit checkpoints
+// @Description List signed audit chain checkpoints, newest first (Admin only)
+// @Tags Audit
+// @Produce json
+// @Security BearerAuth
+// @Param chain query string false "audit_logs or login_audits"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 10, max: 100)"
+// @Success 200 {object} models.APIResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/admin/audit-chain/checkpoints [get]
+func (h *AuditHandler) GetAuditCheckpoints(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	query := h.DB.Model(&models.AuditCheckpoint{})
+	if chain := c.Query("chain"); chain != "" {
+		query = query.Where("chain = ?", chain)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(500, "Failed to retrieve audit checkpoints"))
+		return
+	}
+
+	var checkpoints []models.AuditCheckpoint
+	if err := query.Order("created_at DESC, id DESC").Offset((page - 1) * limit).Limit(limit).Find(&checkpoints).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(500, "Failed to retrieve audit checkpoints"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(200, "Audit checkpoints retrieved successfully", gin.H{
+		"checkpoints": checkpoints,
+		"pagination": gin.H{
+			"page":        page,
+			"limit":       limit,
+			"total":       total,
+			"total_pages": int(math.Ceil(float64(total) / float64(limit))),
+		},
+	}))
+}
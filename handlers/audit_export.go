@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"mbankingcore/models"
+	"mbankingcore/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	auditExportBatchSize              = 500
+	defaultAuditExportIntervalSeconds = 5
+	maxAuditExportRetryDelay          = 5 * time.Minute
+	maxAuditExportErrorLength         = 500
+)
+
+// AuditExportStatus is the delivery position of one sink on one chain
+type AuditExportStatus struct {
+	models.AuditExportCursor
+	HeadSeq uint64 `json:"head_seq"`
+	Lag     uint64 `json:"lag"` // Sealed records not yet delivered
+}
+
+// loadAuditExportBatch returns the sealed records of a chain after afterSeq, in chain order
+func loadAuditExportBatch(db *gorm.DB, chain string, afterSeq uint64) ([]models.AuditEvent, error) {
+	var events []models.AuditEvent
+	query := db.Where("chain_seq > ?", afterSeq).Order("chain_seq ASC").Limit(auditExportBatchSize)
+
+	switch chain {
+	case models.AUDIT_CHAIN_AUDIT_LOG:
+		var logs []models.AuditLog
+		if err := query.Find(&logs).Error; err != nil {
+			return nil, err
+		}
+		for i := range logs {
+			events = append(events, logs[i].AuditEvent())
+		}
+	case models.AUDIT_CHAIN_LOGIN_AUDIT:
+		var logs []models.LoginAudit
+		if err := query.Find(&logs).Error; err != nil {
+			return nil, err
+		}
+		for i := range logs {
+			events = append(events, logs[i].AuditEvent())
+		}
+	}
+	return events, nil
+}
+
+// ExportChain delivers the sealed records of a chain that a sink has not acknowledged yet and
+// returns the number delivered. The sink's cursor row stays locked while a batch is sent, so
+// server instances running side by side never deliver the same batch concurrently; a failed
+// batch is left in place and sent again on the next run.
+func (h *AuditHandler) ExportChain(sink utils.AuditSink, chain string) (int, error) {
+	if !models.IsValidAuditChain(chain) {
+		return 0, fmt.Errorf("unknown audit chain %q", chain)
+	}
+	if err := h.DB.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.AuditExportCursor{Sink: sink.Name(), Chain: chain}).Error; err != nil {
+		return 0, err
+	}
+
+	exported := 0
+	for {
+		tx := h.DB.Begin()
+
+		var cursors []models.AuditExportCursor
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("sink = ? AND chain = ?", sink.Name(), chain).Find(&cursors).Error; err != nil {
+			tx.Rollback()
+			return exported, err
+		}
+		if len(cursors) == 0 {
+			// Another instance is exporting this chain
+			tx.Rollback()
+			return exported, nil
+		}
+		cursor := cursors[0]
+
+		events, err := loadAuditExportBatch(tx, chain, cursor.LastSeq)
+		if err != nil || len(events) == 0 {
+			tx.Rollback()
+			return exported, err
+		}
+
+		if err := sink.Send(events); err != nil {
+			tx.Rollback()
+			h.recordAuditExportFailure(sink.Name(), chain, err)
+			return exported, err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&cursor).Updates(map[string]interface{}{
+			"last_seq":         events[len(events)-1].ChainSeq,
+			"last_exported_at": now,
+			"failure_count":    0,
+			"last_error":       "",
+			"last_error_at":    nil,
+		}).Error; err != nil {
+			tx.Rollback()
+			return exported, err
+		}
+		if err := tx.Commit().Error; err != nil {
+			return exported, err
+		}
+
+		exported += len(events)
+		if len(events) < auditExportBatchSize {
+			return exported, nil
+		}
+	}
+}
+
+func (h *AuditHandler) recordAuditExportFailure(sinkName, chain string, sendErr error) {
+	message := sendErr.Error()
+	if len(message) > maxAuditExportErrorLength {
+		message = message[:maxAuditExportErrorLength]
+	}
+	h.DB.Model(&models.AuditExportCursor{}).
+		Where("sink = ? AND chain = ?", sinkName, chain).
+		Updates(map[string]interface{}{
+			"failure_count": gorm.Expr("failure_count + 1"),
+			"last_error":    message,
+			"last_error_at": time.Now(),
+		})
+}
+
+// RunAuditExporter delivers sealed audit and login records to the configured sinks every
+// audit_export_interval_seconds, intended to run in its own goroutine for the lifetime of the
+// server. Records wait in the database until delivered; a sink that fails is retried with a delay
+// that doubles after each consecutive failure, up to five minutes.
+func (h *AuditHandler) RunAuditExporter(sinks []utils.AuditSink) {
+	interval := time.Duration(getConfigInt64(h.DB, "audit_export_interval_seconds", defaultAuditExportIntervalSeconds)) * time.Second
+	if interval <= 0 {
+		interval = defaultAuditExportIntervalSeconds * time.Second
+	}
+
+	failures := map[string]int{}
+	nextAttempt := map[string]time.Time{}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		for _, sink := range sinks {
+			if time.Now().Before(nextAttempt[sink.Name()]) {
+				continue
+			}
+
+			var sinkErr error
+			for _, chain := range []string{models.AUDIT_CHAIN_AUDIT_LOG, models.AUDIT_CHAIN_LOGIN_AUDIT} {
+				if _, err := h.ExportChain(sink, chain); err != nil {
+					sinkErr = err
+					break
+				}
+			}
+
+			if sinkErr == nil {
+				failures[sink.Name()] = 0
+				continue
+			}
+			failures[sink.Name()]++
+			delay := maxAuditExportRetryDelay
+			if n := failures[sink.Name()]; n <= 16 {
+				if d := interval << uint(n-1); d < maxAuditExportRetryDelay {
+					delay = d
+				}
+			}
+			nextAttempt[sink.Name()] = time.Now().Add(delay)
+			log.Printf("Audit export to %s failed (attempt %d), retrying in %s: %v", sink.Name(), failures[sink.Name()], delay, sinkErr)
+		}
+	}
+}
+
+// GetAuditExportStatus reports how far each SIEM sink has received the audit chains
+// @Summary Get audit export status
+// @Description Delivery position, lag and last error of each audit export sink (Admin only)
+// @Tags Audit
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/admin/audit-export/status [get]
+func (h *AuditHandler) GetAuditExportStatus(c *gin.Context) {
+	heads := map[string]uint64{}
+	for _, chain := range []string{models.AUDIT_CHAIN_AUDIT_LOG, models.AUDIT_CHAIN_LOGIN_AUDIT} {
+		seq, _, err := models.AuditChainHead(h.DB, chain)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse(500, "Failed to retrieve audit export status"))
+			return
+		}
+		heads[chain] = seq
+	}
+
+	var cursors []models.AuditExportCursor
+	if err := h.DB.Order("sink ASC, chain ASC").Find(&cursors).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(500, "Failed to retrieve audit export status"))
+		return
+	}
+
+	statuses := make([]AuditExportStatus, 0, len(cursors))
+	for _, cursor := range cursors {
+		status := AuditExportStatus{AuditExportCursor: cursor, HeadSeq: heads[cursor.Chain]}
+		if status.HeadSeq > cursor.LastSeq {
+			status.Lag = status.HeadSeq - cursor.LastSeq
+		}
+		statuses = append(statuses, status)
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(200, "Audit export status retrieved successfully", gin.H{
+		"sinks":      statuses,
+		"chain_head": heads,
+	}))
+}
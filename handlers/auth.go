@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"crypto/subtle"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
@@ -98,43 +100,64 @@ func (h *AuthHandler) BankingLogin(c *gin.Context) {
 		return
 	}
 
+	// Reject attempts while the phone number or the client IP is throttled
+	throttleKeys := loginThrottleKeys{
+		Scope:      models.LOGIN_THROTTLE_SCOPE_USER,
+		Identifier: req.Phone,
+		IPAddress:  c.ClientIP(),
+	}
+	c.Set("login_identifier", req.Phone)
+	if wait := checkLoginThrottle(h.DB, throttleKeys); wait > 0 {
+		respondLoginThrottled(c, wait)
+		return
+	}
+
 	// Check if phone number is already registered
 	var existingUser models.User
 	phoneExists := h.DB.Preload("BankAccounts").Where("phone = ?", req.Phone).First(&existingUser).Error == nil
 
 	if phoneExists {
-		// Phone is registered - validate user data matches
-		if existingUser.Name != req.Name || existingUser.MotherName != req.MotherName {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "User information does not match our records",
-			})
-			return
-		}
+		c.Set("user_id", existingUser.ID)
+		c.Request = c.Request.WithContext(models.WithAuditActor(c.Request.Context(), nil, &existingUser.ID))
 
-		// Check if account number exists for this user
+		// Every check runs, PIN included, so a mismatch takes as long as a wrong PIN and all
+		// failures get the same response
+		var failureReason string
 		var bankAccount models.BankAccount
 		accountExists := h.DB.Where("user_id = ? AND account_number = ?", existingUser.ID, req.AccountNumber).First(&bankAccount).Error == nil
-
-		if !accountExists {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "Account number not found for this user",
-			})
-			return
+		pinValid := utils.CheckPassword(existingUser.PinAtm, req.PinAtm) == nil
+
+		switch {
+		case existingUser.Status == models.USER_STATUS_LOCKED:
+			failureReason = "account_locked"
+		case existingUser.Name != req.Name || existingUser.MotherName != req.MotherName:
+			failureReason = "user_information_mismatch"
+		case !accountExists:
+			failureReason = "account_number_not_found"
+		case !pinValid:
+			failureReason = "invalid_pin"
 		}
 
-		// Verify PIN (compare plain PIN with hashed PIN in database)
-		if err := utils.CheckPassword(existingUser.PinAtm, req.PinAtm); err != nil {
+		if failureReason != "" {
+			c.Set("failure_reason", failureReason)
+			err := recordUserLoginFailure(h.DB.WithContext(c), throttleKeys, &existingUser)
+			if errors.Is(err, errLoginLockedOut) {
+				c.Set("failure_reason", failureReason+"; account_locked")
+				log.Printf("User %d locked after repeated login failures", existingUser.ID)
+			} else if err != nil {
+				log.Printf("Failed to record login failure for user %d: %v", existingUser.ID, err)
+			}
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"code":    401,
-				"message": "Invalid PIN ATM",
+				"message": msgUserLoginFailed,
 			})
 			return
 		}
+
+		resetLoginFailures(h.DB, throttleKeys.Scope, throttleKeys.Identifier)
 	} else {
 		// Phone is not registered - will auto-register during OTP verification
-		log.Printf("New phone number %s will be registered after OTP verification", req.Phone)
+		log.Printf("New phone number %s will be registered after OTP verification", utils.MaskPartial(req.Phone))
 	}
 
 	// Generate 6-digit OTP and unique login token
@@ -163,9 +186,12 @@ func (h *AuthHandler) BankingLogin(c *gin.Context) {
 		return
 	}
 
-	// TODO: Send OTP via SMS to req.Phone
-	// For now, we'll log it (in production, integrate with SMS service)
-	log.Printf("OTP for phone %s: %s", req.Phone, otpCode)
+	// TODO: Send OTP via SMS to req.Phone. Until then the OTP is logged in debug mode only, so that
+	// logins can be completed in development.
+	log.Printf("OTP generated for phone %s", utils.MaskPartial(req.Phone))
+	if gin.IsDebugging() {
+		log.Printf("Login OTP for phone %s: %s", utils.MaskPartial(req.Phone), otpCode)
+	}
 
 	var message string
 	if phoneExists {
@@ -185,8 +211,8 @@ func (h *AuthHandler) BankingLogin(c *gin.Context) {
 	})
 }
 
-// BankingLoginVerify handles second step of banking authentication - simplified for development
-// Currently only validates login_token and always returns success
+// BankingLoginVerify handles second step of banking authentication: checks the OTP sent for the
+// login token. Wrong OTPs count against the same phone and IP throttling counters as the first step.
 func (h *AuthHandler) BankingLoginVerify(c *gin.Context) {
 	var req models.OTPVerifyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -194,9 +220,9 @@ func (h *AuthHandler) BankingLoginVerify(c *gin.Context) {
 		return
 	}
 
-	// Only validate login_token exists and is not used
+	// The login token must exist, be unused and not expired
 	var otpSession models.OTPSession
-	err := h.DB.Where("login_token = ? AND is_used = ?", req.LoginToken, false).
+	err := h.DB.Where("login_token = ? AND is_used = ? AND expires_at > ?", req.LoginToken, false, time.Now()).
 		First(&otpSession).Error
 
 	if err != nil {
@@ -207,13 +233,74 @@ func (h *AuthHandler) BankingLoginVerify(c *gin.Context) {
 		return
 	}
 
-	// Mark OTP as used
-	otpSession.IsUsed = true
-	h.DB.Save(&otpSession)
+	// Reject attempts while the phone number or the client IP is throttled
+	throttleKeys := loginThrottleKeys{
+		Scope:      models.LOGIN_THROTTLE_SCOPE_USER,
+		Identifier: otpSession.Phone,
+		IPAddress:  c.ClientIP(),
+	}
+	c.Set("login_identifier", otpSession.Phone)
+	if wait := checkLoginThrottle(h.DB, throttleKeys); wait > 0 {
+		respondLoginThrottled(c, wait)
+		return
+	}
 
 	// Check if user exists by phone
 	var user models.User
 	userExists := h.DB.Where("phone = ?", otpSession.Phone).First(&user).Error == nil
+	if userExists {
+		c.Set("user_id", user.ID)
+	}
+
+	var failureReason string
+	switch {
+	case subtle.ConstantTimeCompare([]byte(otpSession.OtpCode), []byte(req.OtpCode)) != 1:
+		failureReason = "invalid_otp"
+	case userExists && user.Status == models.USER_STATUS_LOCKED:
+		failureReason = "account_locked"
+	}
+	if failureReason != "" {
+		c.Set("failure_reason", failureReason)
+		var existingUser *models.User
+		if userExists {
+			existingUser = &user
+		}
+		err := recordUserLoginFailure(h.DB.WithContext(c), throttleKeys, existingUser)
+		if errors.Is(err, errLoginLockedOut) {
+			c.Set("failure_reason", failureReason+"; account_locked")
+			log.Printf("User %d locked after repeated login failures", user.ID)
+		} else if err != nil {
+			log.Printf("Failed to record OTP failure for phone %s: %v", utils.MaskPartial(otpSession.Phone), err)
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": msgUserLoginFailed,
+		})
+		return
+	}
+	resetLoginFailures(h.DB, throttleKeys.Scope, throttleKeys.Identifier)
+
+	// The device completing the login must be the one that started it
+	if req.DeviceInfo.DeviceID != "" && req.DeviceInfo.DeviceID != otpSession.DeviceID {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "Device does not match the login request",
+		})
+		return
+	}
+	if req.DeviceInfo.PublicKey != "" {
+		if _, _, _, err := utils.ParseDevicePublicKey(req.DeviceInfo.PublicKey); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": err.Error(),
+			})
+			return
+		}
+	}
+
+	// Mark OTP as used
+	otpSession.IsUsed = true
+	h.DB.Save(&otpSession)
 
 	if !userExists {
 		// Hash PIN for new user
@@ -231,7 +318,7 @@ func (h *AuthHandler) BankingLoginVerify(c *gin.Context) {
 			PinAtm:     hashedPin,
 		}
 
-		if err := h.DB.Create(&user).Error; err != nil {
+		if err := h.DB.WithContext(c).Create(&user).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, models.CreateFailedResponse())
 			return
 		}
@@ -247,20 +334,57 @@ func (h *AuthHandler) BankingLoginVerify(c *gin.Context) {
 			IsPrimary:     true,
 		}
 
-		if err := h.DB.Create(&bankAccount).Error; err != nil {
+		if err := h.DB.WithContext(c).Create(&bankAccount).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, models.CreateFailedResponse())
 			return
 		}
+
+		// Screen the new customer against the sanctions and PEP watchlists; hits wait for review
+		if _, err := screenCustomer(h.DB, &user, models.SCREENING_CONTEXT_REGISTRATION); err != nil {
+			log.Printf("Watchlist screening of new user %d failed: %v", user.ID, err)
+		}
 	}
 
-	// Create device session without validation checks (simplified for development)
+	// Bind the device on the first login; any further device needs an extra verification first
+	deviceInfo := models.DeviceInfo{
+		DeviceType: models.DeviceType(otpSession.DeviceType),
+		DeviceID:   otpSession.DeviceID,
+		DeviceName: otpSession.DeviceName,
+		PublicKey:  req.DeviceInfo.PublicKey,
+	}
+	ipAddress := utils.GetClientIP(c)
+	verification, err := bindLoginDevice(h.DB, &user, deviceInfo, ipAddress)
+	if errors.Is(err, errTooManyDevices) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": "Maximum number of devices reached. Unbind a device from one of your other devices first",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.InternalServerResponse())
+		return
+	}
+	if verification != nil {
+		c.JSON(http.StatusPreconditionRequired, models.Response{
+			Code:    http.StatusPreconditionRequired,
+			Message: "New device must be verified with the code sent to your registered phone number",
+			Data: gin.H{
+				"device_verification_required": true,
+				"verification_token":           verification.VerificationToken,
+				"expires_in":                   int(deviceVerificationValidity.Seconds()),
+			},
+		})
+		return
+	}
+
+	// Create device session for the bound device
 	loginReq := models.MultiPlatformLoginRequest{
 		Phone:      otpSession.Phone,
 		Provider:   models.LoginProviderEmail,
-		DeviceInfo: req.DeviceInfo,
+		DeviceInfo: deviceInfo,
 	}
 
-	ipAddress := utils.GetClientIP(c)
 	session, err := h.SessionManager.CreateSession(user.ID, loginReq, ipAddress)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.InternalServerResponse())
@@ -269,6 +393,7 @@ func (h *AuthHandler) BankingLoginVerify(c *gin.Context) {
 
 	// Remove sensitive data from response
 	user.PinAtm = ""
+	deviceInfo.PublicKey = ""
 
 	response := models.MultiPlatformLoginResponse{
 		User:         user,
@@ -276,7 +401,7 @@ func (h *AuthHandler) BankingLoginVerify(c *gin.Context) {
 		RefreshToken: session.RefreshToken,
 		ExpiresIn:    24 * 60 * 60,
 		SessionID:    session.ID,
-		DeviceInfo:   req.DeviceInfo,
+		DeviceInfo:   deviceInfo,
 	}
 
 	message := "Login successful"
@@ -453,9 +578,11 @@ func (h *AuthHandler) Profile(c *gin.Context) {
 		return
 	}
 
-	// Find user
+	// Find user with open loans and applications
 	var user models.User
-	if err := h.DB.First(&user, userID).Error; err != nil {
+	if err := h.DB.Preload("Loans", "status IN ?", []string{
+		models.LOAN_STATUS_SUBMITTED, models.LOAN_STATUS_RECOMMENDED, models.LOAN_STATUS_ACTIVE,
+	}).First(&user, userID).Error; err != nil {
 		c.JSON(http.StatusNotFound, models.UserNotFoundResponse())
 		return
 	}
@@ -492,6 +619,7 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	}
 
 	// Update user fields
+	nameChanged := req.Name != "" && req.Name != user.Name
 	if req.Name != "" {
 		user.Name = req.Name
 	}
@@ -499,11 +627,18 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 		user.Phone = req.Phone
 	}
 
-	if err := h.DB.Save(&user).Error; err != nil {
+	if err := h.DB.WithContext(c).Save(&user).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.UpdateFailedResponse())
 		return
 	}
 
+	// A new name is screened against the sanctions and PEP watchlists
+	if nameChanged {
+		if _, err := screenCustomer(h.DB, &user, models.SCREENING_CONTEXT_PROFILE_UPDATE); err != nil {
+			log.Printf("Watchlist screening of user %d failed: %v", user.ID, err)
+		}
+	}
+
 	// Remove sensitive data from response
 	user.PinAtm = ""
 
@@ -553,7 +688,7 @@ func (h *AuthHandler) ChangePIN(c *gin.Context) {
 
 	// Update PIN
 	user.PinAtm = hashedPIN
-	if err := h.DB.Save(&user).Error; err != nil {
+	if err := h.DB.WithContext(c).Save(&user).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.UpdateFailedResponse())
 		return
 	}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"mbankingcore/models"
 
@@ -45,6 +46,7 @@ func (h *BankAccountHandler) GetBankAccounts(c *gin.Context) {
 			BankName:      account.BankName,
 			BankCode:      account.BankCode,
 			AccountType:   account.AccountType,
+			Currency:      account.Currency,
 			IsActive:      account.IsActive,
 			IsPrimary:     account.IsPrimary,
 			CreatedAt:     account.CreatedAt,
@@ -87,9 +89,22 @@ func (h *BankAccountHandler) CreateBankAccount(c *gin.Context) {
 		return
 	}
 
+	// Validate the account currency (defaults to the base currency)
+	currency := models.BASE_CURRENCY
+	if req.Currency != "" {
+		currency = strings.ToUpper(req.Currency)
+		if _, err := getActiveCurrency(h.DB, currency); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "Unsupported currency",
+			})
+			return
+		}
+	}
+
 	// If this is set as primary, make other accounts non-primary
 	if req.IsPrimary {
-		h.DB.Model(&models.BankAccount{}).Where("user_id = ?", userID).Update("is_primary", false)
+		h.DB.WithContext(c).Model(&models.BankAccount{}).Where("user_id = ?", userID).Update("is_primary", false)
 	}
 
 	// Create new bank account
@@ -100,11 +115,12 @@ func (h *BankAccountHandler) CreateBankAccount(c *gin.Context) {
 		BankName:      req.BankName,
 		BankCode:      req.BankCode,
 		AccountType:   req.AccountType,
+		Currency:      currency,
 		IsActive:      true,
 		IsPrimary:     req.IsPrimary,
 	}
 
-	if err := h.DB.Create(&bankAccount).Error; err != nil {
+	if err := h.DB.WithContext(c).Create(&bankAccount).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.CreateFailedResponse())
 		return
 	}
@@ -116,6 +132,7 @@ func (h *BankAccountHandler) CreateBankAccount(c *gin.Context) {
 		BankName:      bankAccount.BankName,
 		BankCode:      bankAccount.BankCode,
 		AccountType:   bankAccount.AccountType,
+		Currency:      bankAccount.Currency,
 		IsActive:      bankAccount.IsActive,
 		IsPrimary:     bankAccount.IsPrimary,
 		CreatedAt:     bankAccount.CreatedAt,
@@ -165,7 +182,7 @@ func (h *BankAccountHandler) UpdateBankAccount(c *gin.Context) {
 
 	// If this is set as primary, make other accounts non-primary
 	if req.IsPrimary && !bankAccount.IsPrimary {
-		h.DB.Model(&models.BankAccount{}).Where("user_id = ? AND id != ?", userID, accountID).Update("is_primary", false)
+		h.DB.WithContext(c).Model(&models.BankAccount{}).Where("user_id = ? AND id != ?", userID, accountID).Update("is_primary", false)
 	}
 
 	// Update bank account
@@ -175,7 +192,7 @@ func (h *BankAccountHandler) UpdateBankAccount(c *gin.Context) {
 	bankAccount.AccountType = req.AccountType
 	bankAccount.IsPrimary = req.IsPrimary
 
-	if err := h.DB.Save(&bankAccount).Error; err != nil {
+	if err := h.DB.WithContext(c).Save(&bankAccount).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.UpdateFailedResponse())
 		return
 	}
@@ -187,6 +204,7 @@ func (h *BankAccountHandler) UpdateBankAccount(c *gin.Context) {
 		BankName:      bankAccount.BankName,
 		BankCode:      bankAccount.BankCode,
 		AccountType:   bankAccount.AccountType,
+		Currency:      bankAccount.Currency,
 		IsActive:      bankAccount.IsActive,
 		IsPrimary:     bankAccount.IsPrimary,
 		CreatedAt:     bankAccount.CreatedAt,
@@ -242,7 +260,7 @@ func (h *BankAccountHandler) DeleteBankAccount(c *gin.Context) {
 
 	// Soft delete the account
 	bankAccount.IsActive = false
-	if err := h.DB.Save(&bankAccount).Error; err != nil {
+	if err := h.DB.WithContext(c).Save(&bankAccount).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.DeleteFailedResponse())
 		return
 	}
@@ -254,7 +272,7 @@ func (h *BankAccountHandler) DeleteBankAccount(c *gin.Context) {
 			First(&newPrimary).Error
 		if err == nil {
 			newPrimary.IsPrimary = true
-			h.DB.Save(&newPrimary)
+			h.DB.WithContext(c).Save(&newPrimary)
 		}
 	}
 
@@ -294,11 +312,11 @@ func (h *BankAccountHandler) SetPrimaryAccount(c *gin.Context) {
 	}
 
 	// Make other accounts non-primary
-	h.DB.Model(&models.BankAccount{}).Where("user_id = ?", userID).Update("is_primary", false)
+	h.DB.WithContext(c).Model(&models.BankAccount{}).Where("user_id = ?", userID).Update("is_primary", false)
 
 	// Set this account as primary
 	bankAccount.IsPrimary = true
-	if err := h.DB.Save(&bankAccount).Error; err != nil {
+	if err := h.DB.WithContext(c).Save(&bankAccount).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.UpdateFailedResponse())
 		return
 	}
@@ -0,0 +1,745 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"mbankingcore/models"
+	"mbankingcore/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BillPaymentHandler struct {
+	DB      *gorm.DB
+	Adapter utils.BillerAdapter
+}
+
+func NewBillPaymentHandler(db *gorm.DB, adapter utils.BillerAdapter) *BillPaymentHandler {
+	return &BillPaymentHandler{DB: db, Adapter: adapter}
+}
+
+// GetBillers - List active billers, optionally filtered by ?category=
+func (h *BillPaymentHandler) GetBillers(c *gin.Context) {
+	query := h.DB.Where("is_active = ?", true)
+	if category := c.Query("category"); category != "" {
+		query = query.Where("category = ?", category)
+	}
+
+	var billers []models.Biller
+	if err := query.Order("category ASC, name ASC").Find(&billers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch billers",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Billers retrieved successfully",
+		Data:    billers,
+	})
+}
+
+// InquireBill - Look up the bill of a customer number before paying
+func (h *BillPaymentHandler) InquireBill(c *gin.Context) {
+	var req models.BillInquiryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	biller, inquiry, ok := h.inquire(c, req.BillerCode, req.CustomerNumber, req.Amount)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Bill inquiry successful",
+		Data: gin.H{
+			"biller":          biller,
+			"customer_number": inquiry.CustomerNumber,
+			"customer_name":   inquiry.CustomerName,
+			"period":          inquiry.Period,
+			"amount":          inquiry.Amount,
+			"admin_fee":       biller.AdminFee,
+			"total_amount":    inquiry.Amount + biller.AdminFee,
+		},
+	})
+}
+
+// PayBill - Pay a bill. The bill is inquired again so the amount always comes from the biller.
+func (h *BillPaymentHandler) PayBill(c *gin.Context) {
+	userIDValue, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+	userID := userIDValue.(uint)
+
+	var req models.BillPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	biller, inquiry, ok := h.inquire(c, req.BillerCode, req.CustomerNumber, req.Amount)
+	if !ok {
+		return
+	}
+
+	payment := models.BillPayment{
+		Reference:        utils.GenerateReference("BIL"),
+		UserID:           userID,
+		BillerID:         biller.ID,
+		BillerCode:       biller.Code,
+		BillerName:       biller.Name,
+		Category:         biller.Category,
+		CustomerNumber:   inquiry.CustomerNumber,
+		CustomerName:     inquiry.CustomerName,
+		Period:           inquiry.Period,
+		Amount:           inquiry.Amount,
+		AdminFee:         biller.AdminFee,
+		TotalAmount:      inquiry.Amount + biller.AdminFee,
+		Status:           models.BILL_PAYMENT_STATUS_PENDING,
+		BillerAdapter:    h.Adapter.Name(),
+		InquiryReference: inquiry.InquiryReference,
+	}
+
+	if !checkDeviceCoolingOff(c, h.DB, userID, models.BASE_CURRENCY, payment.TotalAmount) {
+		return
+	}
+
+	// Debit the customer and park the funds in the biller suspense account
+	tx := h.DB.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	debitTxn, err := debitUserBalance(tx, userID, payment.TotalAmount, "bill_payment",
+		fmt.Sprintf("%s %s", biller.Name, payment.CustomerNumber))
+	if err != nil {
+		tx.Rollback()
+		respondLedgerError(c, err, "Failed to debit account")
+		return
+	}
+	payment.DebitTransactionID = &debitTxn.ID
+
+	if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_BILLER_SUSPENSE, payment.TotalAmount, payment.Reference,
+		"Bill payment hold", &debitTxn.ID); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to post to suspense account",
+		})
+		return
+	}
+
+	if err := tx.Create(&payment).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create bill payment",
+		})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to commit bill payment",
+		})
+		return
+	}
+
+	// Pay at the biller outside of the database transaction
+	response, payErr := h.Adapter.Payment(utils.BillerPaymentRequest{
+		Reference:        payment.Reference,
+		BillerCode:       payment.BillerCode,
+		CustomerNumber:   payment.CustomerNumber,
+		Amount:           payment.Amount,
+		InquiryReference: payment.InquiryReference,
+	})
+
+	switch {
+	case payErr != nil && errors.Is(payErr, utils.ErrBillerUnavailable):
+		// Never reached the biller, safe to reverse immediately
+		h.applyBillerResult(payment.Reference, &utils.BillerPaymentResponse{Status: utils.BILLER_STATUS_FAILED, FailureReason: payErr.Error()})
+	case payErr != nil:
+		// Outcome unknown; keep the funds in suspense until an advice resolves it
+		h.DB.Model(&payment).Updates(map[string]interface{}{
+			"status":         models.BILL_PAYMENT_STATUS_SUSPECT,
+			"failure_reason": payErr.Error(),
+		})
+	case response.Status == utils.BILLER_STATUS_PENDING:
+		if response.BillerReference != "" {
+			h.DB.Model(&payment).Update("biller_reference", response.BillerReference)
+		}
+	default:
+		h.applyBillerResult(payment.Reference, response)
+	}
+
+	h.DB.First(&payment, payment.ID)
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Bill payment submitted",
+		Data:    payment,
+	})
+}
+
+// GetUserBillPayments - Get bill payment history for the authenticated user
+func (h *BillPaymentHandler) GetUserBillPayments(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.BillPayment{}).Where("user_id = ?", userID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if category := c.Query("category"); category != "" {
+		query = query.Where("category = ?", category)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var payments []models.BillPayment
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&payments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch bill payments",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Bill payments retrieved successfully",
+		Data: gin.H{
+			"payments": payments,
+			"pagination": gin.H{
+				"current_page": page,
+				"per_page":     limit,
+				"total":        total,
+				"total_pages":  (total + int64(limit) - 1) / int64(limit),
+			},
+		},
+	})
+}
+
+// GetUserBillPaymentByID - Get a bill payment owned by the authenticated user
+func (h *BillPaymentHandler) GetUserBillPaymentByID(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid bill payment ID",
+		})
+		return
+	}
+
+	var payment models.BillPayment
+	if err := h.DB.Where("id = ? AND user_id = ?", uint(id), userID).First(&payment).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Bill payment not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Bill payment retrieved successfully",
+		Data:    payment,
+	})
+}
+
+// CreateBiller - Add a biller to the catalog (admin)
+func (h *BillPaymentHandler) CreateBiller(c *gin.Context) {
+	var req models.CreateBillerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if req.ProductType == models.BILLER_PRODUCT_PREPAID {
+		if _, err := parseDenominations(req.Denominations); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	biller := models.Biller{
+		Code:          strings.ToUpper(req.Code),
+		Name:          req.Name,
+		Category:      req.Category,
+		ProductType:   req.ProductType,
+		Denominations: req.Denominations,
+		AdminFee:      req.AdminFee,
+		IsActive:      true,
+	}
+
+	var existing int64
+	h.DB.Model(&models.Biller{}).Where("code = ?", biller.Code).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "Biller code already exists",
+		})
+		return
+	}
+
+	if err := h.DB.Create(&biller).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create biller",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Code:    http.StatusCreated,
+		Message: "Biller created successfully",
+		Data:    biller,
+	})
+}
+
+// GetAllBillers - List the full biller catalog including inactive billers (admin)
+func (h *BillPaymentHandler) GetAllBillers(c *gin.Context) {
+	query := h.DB.Model(&models.Biller{})
+	if category := c.Query("category"); category != "" {
+		query = query.Where("category = ?", category)
+	}
+
+	var billers []models.Biller
+	if err := query.Order("category ASC, name ASC").Find(&billers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch billers",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Billers retrieved successfully",
+		Data:    billers,
+	})
+}
+
+// UpdateBiller - Update a catalog entry or enable/disable it (admin)
+func (h *BillPaymentHandler) UpdateBiller(c *gin.Context) {
+	var biller models.Biller
+	if err := h.DB.First(&biller, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Biller not found",
+		})
+		return
+	}
+
+	var req models.UpdateBillerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != "" {
+		updates["name"] = req.Name
+	}
+	if req.Denominations != "" {
+		if _, err := parseDenominations(req.Denominations); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			return
+		}
+		updates["denominations"] = req.Denominations
+	}
+	if req.AdminFee != nil {
+		updates["admin_fee"] = *req.AdminFee
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+
+	if len(updates) > 0 {
+		if err := h.DB.Model(&biller).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to update biller",
+			})
+			return
+		}
+	}
+
+	h.DB.First(&biller, biller.ID)
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Biller updated successfully",
+		Data:    biller,
+	})
+}
+
+// GetAllBillPayments - Get all bill payments for admin monitoring
+func (h *BillPaymentHandler) GetAllBillPayments(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.BillPayment{}).Preload("User")
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if billerCode := c.Query("biller_code"); billerCode != "" {
+		query = query.Where("biller_code = ?", billerCode)
+	}
+	if reference := c.Query("reference"); reference != "" {
+		query = query.Where("reference = ?", reference)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var payments []models.BillPayment
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&payments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch bill payments",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "All bill payments retrieved successfully",
+		Data: gin.H{
+			"payments": payments,
+			"pagination": gin.H{
+				"current_page": page,
+				"per_page":     limit,
+				"total":        total,
+				"total_pages":  (total + int64(limit) - 1) / int64(limit),
+			},
+		},
+	})
+}
+
+// SendAdvice - Query the biller for a pending or suspect payment and apply the result (admin)
+func (h *BillPaymentHandler) SendAdvice(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid bill payment ID",
+		})
+		return
+	}
+
+	var payment models.BillPayment
+	if err := h.DB.First(&payment, uint(id)).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Bill payment not found",
+		})
+		return
+	}
+
+	if payment.Status == models.BILL_PAYMENT_STATUS_SUCCESS || payment.Status == models.BILL_PAYMENT_STATUS_FAILED {
+		c.JSON(http.StatusOK, models.APIResponse{
+			Code:    http.StatusOK,
+			Message: "Bill payment is already final",
+			Data:    payment,
+		})
+		return
+	}
+
+	response, err := h.sendAdvice(&payment)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Code:    http.StatusServiceUnavailable,
+			Message: "Advice failed: " + err.Error(),
+		})
+		return
+	}
+
+	h.DB.First(&payment, payment.ID)
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Bill payment advice sent",
+		Data: gin.H{
+			"payment":       payment,
+			"biller_status": response.Status,
+		},
+	})
+}
+
+// ResolvePendingPayments sends an advice for every pending or suspect payment,
+// e.g. after a restart interrupted the biller call.
+func (h *BillPaymentHandler) ResolvePendingPayments() {
+	var payments []models.BillPayment
+	if err := h.DB.Where("status IN ?", []string{models.BILL_PAYMENT_STATUS_PENDING, models.BILL_PAYMENT_STATUS_SUSPECT}).
+		Find(&payments).Error; err != nil {
+		log.Printf("Failed to load pending bill payments: %v", err)
+		return
+	}
+
+	for i := range payments {
+		if _, err := h.sendAdvice(&payments[i]); err != nil {
+			log.Printf("Advice for bill payment %s failed: %v", payments[i].Reference, err)
+		}
+	}
+}
+
+// sendAdvice asks the biller for the final status of a payment and applies it.
+// A payment the biller has no record of never left the bank and is reversed.
+func (h *BillPaymentHandler) sendAdvice(payment *models.BillPayment) (*utils.BillerPaymentResponse, error) {
+	now := time.Now()
+	h.DB.Model(payment).Updates(map[string]interface{}{
+		"advice_count":   gorm.Expr("advice_count + 1"),
+		"last_advice_at": &now,
+	})
+
+	response, err := h.Adapter.Advice(payment.Reference)
+	if err != nil {
+		return nil, err
+	}
+
+	switch response.Status {
+	case utils.BILLER_STATUS_SUCCESS, utils.BILLER_STATUS_FAILED:
+		if _, err := h.applyBillerResult(payment.Reference, response); err != nil {
+			return nil, err
+		}
+	case utils.BILLER_STATUS_UNKNOWN:
+		if _, err := h.applyBillerResult(payment.Reference, &utils.BillerPaymentResponse{
+			Status:        utils.BILLER_STATUS_FAILED,
+			FailureReason: "payment not found at biller",
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return response, nil
+}
+
+// inquire validates the biller and amount and runs the biller inquiry, writing the error response on failure
+func (h *BillPaymentHandler) inquire(c *gin.Context, billerCode, customerNumber string, amount int64) (*models.Biller, *utils.BillerInquiryResponse, bool) {
+	var biller models.Biller
+	if err := h.DB.Where("code = ? AND is_active = ?", strings.ToUpper(billerCode), true).First(&biller).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Biller not found",
+		})
+		return nil, nil, false
+	}
+
+	if biller.ProductType == models.BILLER_PRODUCT_PREPAID {
+		denominations, _ := parseDenominations(biller.Denominations)
+		valid := false
+		for _, denomination := range denominations {
+			if denomination == amount {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Amount must be one of the denominations: " + biller.Denominations,
+			})
+			return nil, nil, false
+		}
+	} else {
+		amount = 0
+	}
+
+	inquiry, err := h.Adapter.Inquiry(utils.BillerInquiryRequest{
+		BillerCode:     biller.Code,
+		CustomerNumber: customerNumber,
+		Amount:         amount,
+	})
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, utils.ErrBillerUnavailable) || errors.Is(err, utils.ErrBillerTimeout) {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, models.ErrorResponse{
+			Code:    status,
+			Message: "Bill inquiry failed: " + err.Error(),
+		})
+		return nil, nil, false
+	}
+	if inquiry.Amount <= 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "No outstanding bill for this customer number",
+		})
+		return nil, nil, false
+	}
+
+	return &biller, inquiry, true
+}
+
+// applyBillerResult settles or reverses a bill payment. Final payments are left untouched,
+// so repeated advices are harmless.
+func (h *BillPaymentHandler) applyBillerResult(reference string, response *utils.BillerPaymentResponse) (*models.BillPayment, error) {
+	tx := h.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var payment models.BillPayment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("reference = ?", reference).
+		First(&payment).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if payment.Status == models.BILL_PAYMENT_STATUS_SUCCESS || payment.Status == models.BILL_PAYMENT_STATUS_FAILED {
+		tx.Rollback()
+		return &payment, nil
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"completed_at": &now,
+	}
+	if response.BillerReference != "" {
+		updates["biller_reference"] = response.BillerReference
+	}
+
+	if response.Status == utils.BILLER_STATUS_SUCCESS {
+		// Move the bill amount to the biller settlement account and recognise the admin fee
+		if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_BILLER_SUSPENSE, -payment.TotalAmount, payment.Reference,
+			"Bill payment settled", payment.DebitTransactionID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_BILLER_SETTLEMENT, payment.Amount, payment.Reference,
+			"Bill payment to "+payment.BillerCode, payment.DebitTransactionID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if payment.AdminFee > 0 {
+			if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_FEE_INCOME, payment.AdminFee, payment.Reference,
+				"Bill payment admin fee", payment.DebitTransactionID); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+		}
+
+		updates["status"] = models.BILL_PAYMENT_STATUS_SUCCESS
+		updates["failure_reason"] = ""
+		if response.Token != "" {
+			updates["token"] = response.Token
+		}
+	} else {
+		// Automatic reversal of the full debit from suspense
+		refundTxn, err := creditUserBalance(tx, payment.UserID, payment.TotalAmount, "bill_payment_refund",
+			"Reversal of bill payment "+payment.Reference)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_BILLER_SUSPENSE, -payment.TotalAmount, payment.Reference,
+			"Bill payment reversed", &refundTxn.ID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		updates["status"] = models.BILL_PAYMENT_STATUS_FAILED
+		updates["failure_reason"] = response.FailureReason
+		updates["refund_transaction_id"] = refundTxn.ID
+	}
+
+	if err := tx.Model(&payment).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	h.DB.First(&payment, payment.ID)
+	return &payment, nil
+}
+
+// parseDenominations parses a comma separated list of prepaid amounts
+func parseDenominations(value string) ([]int64, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, errors.New("prepaid billers require denominations")
+	}
+
+	var denominations []int64
+	for _, part := range strings.Split(value, ",") {
+		amount, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+		if err != nil || amount <= 0 {
+			return nil, fmt.Errorf("invalid denomination %q", part)
+		}
+		denominations = append(denominations, amount)
+	}
+	return denominations, nil
+}
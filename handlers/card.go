@@ -0,0 +1,1025 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"mbankingcore/models"
+	"mbankingcore/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Card defaults and the most a cardholder can raise their limits to
+const (
+	defaultMaxCardsPerUser       = 3
+	defaultCardHoldExpiryDays    = 7
+	defaultCardPerTxnLimit       = 5000000
+	defaultCardDailyLimit        = 10000000
+	defaultCardMonthlyLimit      = 50000000
+	maxCardPerTxnLimit           = 25000000
+	maxCardDailyLimit            = 50000000
+	maxCardMonthlyLimit          = 200000000
+	cardPANAccountDigits         = 9
+	cardValidityYears            = 5
+	cardPurchaseProcessingPrefix = "00" // ISO 8583 processing code for goods and services
+)
+
+type CardHandler struct {
+	DB *gorm.DB
+}
+
+func NewCardHandler(db *gorm.DB) *CardHandler {
+	return &CardHandler{DB: db}
+}
+
+// CardHoldExpiryRunResult summarises one stale hold release run
+type CardHoldExpiryRunResult struct {
+	Date     string `json:"date"`
+	Released int    `json:"released"`
+	Amount   int64  `json:"amount"` // Total hold amount returned to cardholders
+	Failed   int    `json:"failed"`
+}
+
+// IssueCard - Issue a virtual debit card on one of the user's accounts
+func (h *CardHandler) IssueCard(c *gin.Context) {
+	userIDValue, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+	userID := userIDValue.(uint)
+
+	var req models.IssueCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+		})
+		return
+	}
+	if user.Status != models.USER_STATUS_ACTIVE {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Code:    http.StatusForbidden,
+			Message: "Account is not active",
+		})
+		return
+	}
+
+	var account models.BankAccount
+	if err := h.DB.Where("id = ? AND user_id = ? AND is_active = ?", req.BankAccountID, userID, true).First(&account).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Bank account not found or inactive",
+		})
+		return
+	}
+	if account.Currency != "" && account.Currency != models.BASE_CURRENCY {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Cards can only be issued on " + models.BASE_CURRENCY + " accounts",
+		})
+		return
+	}
+
+	var count int64
+	h.DB.Model(&models.Card{}).Where("user_id = ? AND status <> ?", userID, models.CARD_STATUS_CLOSED).Count(&count)
+	if maxCards := getConfigInt64(h.DB, "max_cards_per_user", defaultMaxCardsPerUser); count >= maxCards {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Maximum of %d cards reached", maxCards),
+		})
+		return
+	}
+
+	pan, err := h.generateUniquePAN()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to generate card number",
+		})
+		return
+	}
+	token, err := utils.GenerateCardToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to tokenize card",
+		})
+		return
+	}
+	cvv, err := generateDigits(3)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to generate CVV",
+		})
+		return
+	}
+
+	expiry := time.Now().AddDate(cardValidityYears, 0, 0)
+	cardholderName := strings.ToUpper(strings.TrimSpace(user.Name))
+	if len(cardholderName) > 26 {
+		cardholderName = cardholderName[:26]
+	}
+
+	card := models.Card{
+		UserID:              userID,
+		BankAccountID:       account.ID,
+		Token:               token,
+		PANHash:             utils.HashCardPAN(pan),
+		MaskedPAN:           utils.MaskCardPAN(pan),
+		CVVHash:             utils.HashCardCVV(token, cvv, int(expiry.Month()), expiry.Year()),
+		CardholderName:      cardholderName,
+		ExpiryMonth:         int(expiry.Month()),
+		ExpiryYear:          expiry.Year(),
+		PerTransactionLimit: defaultCardPerTxnLimit,
+		DailyLimit:          defaultCardDailyLimit,
+		MonthlyLimit:        defaultCardMonthlyLimit,
+		Status:              models.CARD_STATUS_ACTIVE,
+	}
+	if err := h.DB.Create(&card).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to issue card",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Code:    http.StatusCreated,
+		Message: "Card issued successfully. Card number and CVV are shown only once.",
+		Data:    models.IssuedCardResponse{Card: card, PAN: pan, CVV: cvv},
+	})
+}
+
+// GetCards - List the authenticated user's cards
+func (h *CardHandler) GetCards(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	var cards []models.Card
+	if err := h.DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&cards).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch cards",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Cards retrieved successfully",
+		Data:    cards,
+	})
+}
+
+// GetCard - Get a card with its limit usage
+func (h *CardHandler) GetCard(c *gin.Context) {
+	card, ok := h.findUserCard(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Card retrieved successfully",
+		Data: gin.H{
+			"card":          card,
+			"daily_used":    cardUsage(h.DB, card.ID, today()),
+			"monthly_used":  cardUsage(h.DB, card.ID, time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)),
+			"pending_holds": cardPendingHolds(h.DB, card.ID),
+			"expiry":        fmt.Sprintf("%02d/%02d", card.ExpiryMonth, card.ExpiryYear%100),
+			"is_expired":    card.IsExpired(now),
+			"max_limits": gin.H{
+				"per_transaction": maxCardPerTxnLimit,
+				"daily":           maxCardDailyLimit,
+				"monthly":         maxCardMonthlyLimit,
+			},
+		},
+	})
+}
+
+// GetCardTransactions - List authorizations made with a card
+func (h *CardHandler) GetCardTransactions(c *gin.Context) {
+	card, ok := h.findUserCard(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.CardAuthorization{}).Where("card_id = ?", card.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var authorizations []models.CardAuthorization
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&authorizations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch card transactions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Card transactions retrieved successfully",
+		Data: gin.H{
+			"transactions": authorizations,
+			"pagination": gin.H{
+				"current_page": page,
+				"per_page":     limit,
+				"total":        total,
+				"total_pages":  (total + int64(limit) - 1) / int64(limit),
+			},
+		},
+	})
+}
+
+// FreezeCard - Temporarily block a card
+func (h *CardHandler) FreezeCard(c *gin.Context) {
+	h.setCardStatus(c, models.CARD_STATUS_ACTIVE, models.CARD_STATUS_FROZEN, "Card frozen successfully")
+}
+
+// UnfreezeCard - Unblock a frozen card
+func (h *CardHandler) UnfreezeCard(c *gin.Context) {
+	h.setCardStatus(c, models.CARD_STATUS_FROZEN, models.CARD_STATUS_ACTIVE, "Card unfrozen successfully")
+}
+
+// CloseCard - Permanently close a card. Holds already placed are still cleared or expire normally.
+func (h *CardHandler) CloseCard(c *gin.Context) {
+	card, ok := h.findUserCard(c)
+	if !ok {
+		return
+	}
+	if card.Status == models.CARD_STATUS_CLOSED {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Card is already closed",
+		})
+		return
+	}
+
+	now := time.Now()
+	if err := h.DB.Model(card).Updates(map[string]interface{}{
+		"status":    models.CARD_STATUS_CLOSED,
+		"closed_at": &now,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to close card",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Card closed successfully",
+		Data:    card,
+	})
+}
+
+// UpdateLimits - Change a card's per-transaction, daily and monthly limits
+func (h *CardHandler) UpdateLimits(c *gin.Context) {
+	card, ok := h.findUserCard(c)
+	if !ok {
+		return
+	}
+	if card.Status == models.CARD_STATUS_CLOSED {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Card is closed",
+		})
+		return
+	}
+
+	var req models.CardLimitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+	if req.PerTransactionLimit > maxCardPerTxnLimit || req.DailyLimit > maxCardDailyLimit || req.MonthlyLimit > maxCardMonthlyLimit {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code: http.StatusBadRequest,
+			Message: fmt.Sprintf("Limits cannot exceed %d per transaction, %d daily and %d monthly",
+				int64(maxCardPerTxnLimit), int64(maxCardDailyLimit), int64(maxCardMonthlyLimit)),
+		})
+		return
+	}
+
+	if err := h.DB.Model(card).Updates(map[string]interface{}{
+		"per_transaction_limit": req.PerTransactionLimit,
+		"daily_limit":           req.DailyLimit,
+		"monthly_limit":         req.MonthlyLimit,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update card limits",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Card limits updated successfully",
+		Data:    card,
+	})
+}
+
+// setCardStatus moves a card between active and frozen
+func (h *CardHandler) setCardStatus(c *gin.Context, from, to, message string) {
+	card, ok := h.findUserCard(c)
+	if !ok {
+		return
+	}
+	if card.Status != from {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Card is %s", card.Status),
+		})
+		return
+	}
+
+	updates := map[string]interface{}{"status": to, "frozen_at": nil}
+	if to == models.CARD_STATUS_FROZEN {
+		now := time.Now()
+		updates["frozen_at"] = &now
+	}
+	if err := h.DB.Model(card).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update card",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: message,
+		Data:    card,
+	})
+}
+
+// findUserCard loads the card in the :id path parameter owned by the authenticated user,
+// writing the error response when it cannot
+func (h *CardHandler) findUserCard(c *gin.Context) (*models.Card, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return nil, false
+	}
+
+	var card models.Card
+	if err := h.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&card).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Card not found",
+		})
+		return nil, false
+	}
+	return &card, true
+}
+
+// generateUniquePAN returns a Luhn-valid PAN that is not yet issued
+func (h *CardHandler) generateUniquePAN() (string, error) {
+	for attempt := 0; attempt < 5; attempt++ {
+		digits, err := generateDigits(cardPANAccountDigits)
+		if err != nil {
+			return "", err
+		}
+		pan := utils.BuildCardPAN(digits)
+
+		var count int64
+		h.DB.Model(&models.Card{}).Where("pan_hash = ?", utils.HashCardPAN(pan)).Count(&count)
+		if count == 0 {
+			return pan, nil
+		}
+	}
+	return "", errors.New("could not generate a unique card number")
+}
+
+// cardUsage sums approved and captured authorizations on a card since a point in time
+func cardUsage(db *gorm.DB, cardID uint, since time.Time) int64 {
+	var used int64
+	db.Model(&models.CardAuthorization{}).
+		Where("card_id = ? AND status IN ? AND created_at >= ?", cardID,
+			[]string{models.CARD_AUTH_STATUS_APPROVED, models.CARD_AUTH_STATUS_CAPTURED}, since).
+		Select("COALESCE(SUM(CASE WHEN status = ? THEN captured_amount ELSE amount END), 0)", models.CARD_AUTH_STATUS_CAPTURED).
+		Scan(&used)
+	return used
+}
+
+// cardPendingHolds sums the holds on a card that are waiting for clearing
+func cardPendingHolds(db *gorm.DB, cardID uint) int64 {
+	var held int64
+	db.Model(&models.CardAuthorization{}).
+		Where("card_id = ? AND status = ?", cardID, models.CARD_AUTH_STATUS_APPROVED).
+		Select("COALESCE(SUM(amount), 0)").Scan(&held)
+	return held
+}
+
+// HandleNetworkMessage - Receive an authorization (0100) or reversal (0400) from the card network (signature verified)
+func (h *CardHandler) HandleNetworkMessage(c *gin.Context) {
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Failed to read message body",
+		})
+		return
+	}
+
+	if !utils.VerifyCardNetworkMessage(payload, c.GetHeader("X-Signature")) {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Invalid message signature",
+		})
+		return
+	}
+
+	var msg models.CardNetworkMessage
+	if err := binding.JSON.BindBody(payload, &msg); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var response *models.CardNetworkResponse
+	if msg.MTI == models.CARD_MTI_REVERSAL_REQUEST {
+		response, err = h.reverse(&msg)
+	} else {
+		response, err = h.authorize(&msg)
+	}
+	if err != nil {
+		log.Printf("Card network message %s RRN %s failed: %v", msg.MTI, msg.RRN, err)
+		response = &models.CardNetworkResponse{
+			MTI:          responseMTI(msg.MTI),
+			STAN:         msg.STAN,
+			RRN:          msg.RRN,
+			ResponseCode: models.CARD_RC_SYSTEM_MALFUNCTION,
+		}
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Message processed",
+		Data:    response,
+	})
+}
+
+// authorize checks an authorization request and places a hold for the amount when approved.
+// Every request is stored, including declines; a retransmitted RRN gets the original answer.
+func (h *CardHandler) authorize(msg *models.CardNetworkMessage) (*models.CardNetworkResponse, error) {
+	var existing models.CardAuthorization
+	if err := h.DB.Where("rrn = ?", msg.RRN).First(&existing).Error; err == nil {
+		return authorizationResponse(&existing), nil
+	}
+
+	tx := h.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	auth := models.CardAuthorization{
+		RRN:                  msg.RRN,
+		STAN:                 msg.STAN,
+		MaskedPAN:            utils.MaskCardPAN(msg.PAN),
+		ProcessingCode:       msg.ProcessingCode,
+		Amount:               msg.Amount,
+		Currency:             msg.Currency,
+		MerchantCategoryCode: msg.MerchantCategoryCode,
+		TerminalID:           msg.TerminalID,
+		MerchantID:           msg.MerchantID,
+		MerchantName:         msg.MerchantName,
+		POSEntryMode:         msg.POSEntryMode,
+	}
+
+	var card models.Card
+	responseCode, reason := models.CARD_RC_APPROVED, ""
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("pan_hash = ?", utils.HashCardPAN(msg.PAN)).First(&card).Error; err != nil {
+		responseCode, reason = models.CARD_RC_INVALID_CARD, "unknown card"
+	} else {
+		auth.CardID = &card.ID
+		auth.UserID = &card.UserID
+		responseCode, reason = h.checkAuthorization(tx, &card, msg)
+	}
+
+	if responseCode == models.CARD_RC_APPROVED {
+		description := "Card purchase"
+		if msg.MerchantName != "" {
+			description += " " + msg.MerchantName
+		}
+		// The hold stays pending until it is captured or released
+		holdTxn, err := debitUserBalanceWithStatus(tx, card.UserID, msg.Amount, "card_authorization", description,
+			models.TRANSACTION_STATUS_PENDING)
+		switch {
+		case errors.Is(err, errInsufficientBalance):
+			responseCode, reason = models.CARD_RC_INSUFFICIENT_FUNDS, "insufficient balance"
+		case errors.Is(err, errUserNotActive):
+			responseCode, reason = models.CARD_RC_RESTRICTED_CARD, "cardholder account not active"
+		case err != nil:
+			tx.Rollback()
+			return nil, err
+		default:
+			if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_CARD_HOLD, msg.Amount, msg.RRN,
+				"Card authorization hold "+auth.MaskedPAN, &holdTxn.ID); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+
+			authCode, err := generateDigits(6)
+			if err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			expiresAt := time.Now().AddDate(0, 0, int(getConfigInt64(h.DB, "card_hold_expiry_days", defaultCardHoldExpiryDays)))
+			auth.AuthCode = authCode
+			auth.HoldTxnID = &holdTxn.ID
+			auth.HoldExpiresAt = &expiresAt
+		}
+	}
+
+	auth.ResponseCode = responseCode
+	auth.DeclineReason = reason
+	auth.Status = models.CARD_AUTH_STATUS_APPROVED
+	if responseCode != models.CARD_RC_APPROVED {
+		auth.Status = models.CARD_AUTH_STATUS_DECLINED
+	}
+
+	if err := tx.Create(&auth).Error; err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to record authorization: %v", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("failed to commit authorization: %v", err)
+	}
+
+	return authorizationResponse(&auth), nil
+}
+
+// checkAuthorization applies the card controls to an authorization request and returns the
+// ISO response code with the decline reason. The balance check is left to the debit.
+func (h *CardHandler) checkAuthorization(tx *gorm.DB, card *models.Card, msg *models.CardNetworkMessage) (string, string) {
+	now := time.Now()
+
+	switch {
+	case card.Status == models.CARD_STATUS_CLOSED:
+		return models.CARD_RC_INVALID_CARD, "card closed"
+	case msg.Expiry != fmt.Sprintf("%02d%02d", card.ExpiryYear%100, card.ExpiryMonth):
+		return models.CARD_RC_EXPIRED_CARD, "expiry date mismatch"
+	case card.IsExpired(now):
+		return models.CARD_RC_EXPIRED_CARD, "card expired"
+	case card.Status == models.CARD_STATUS_FROZEN:
+		return models.CARD_RC_RESTRICTED_CARD, "card frozen"
+	case msg.CVV != "" && !utils.VerifyCardCVV(card.CVVHash, card.Token, msg.CVV, card.ExpiryMonth, card.ExpiryYear):
+		return models.CARD_RC_CVV_MISMATCH, "CVV mismatch"
+	case !strings.HasPrefix(msg.ProcessingCode, cardPurchaseProcessingPrefix):
+		return models.CARD_RC_INVALID_TRANSACTION, "unsupported processing code"
+	case msg.Currency != models.BASE_CURRENCY:
+		return models.CARD_RC_NOT_PERMITTED, "unsupported currency"
+	case msg.Amount <= 0:
+		return models.CARD_RC_INVALID_AMOUNT, "invalid amount"
+	case msg.Amount > card.PerTransactionLimit:
+		return models.CARD_RC_EXCEEDS_LIMIT, "per-transaction limit exceeded"
+	case cardUsage(tx, card.ID, today())+msg.Amount > card.DailyLimit:
+		return models.CARD_RC_EXCEEDS_LIMIT, "daily limit exceeded"
+	case cardUsage(tx, card.ID, time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local))+msg.Amount > card.MonthlyLimit:
+		return models.CARD_RC_EXCEEDS_LIMIT, "monthly limit exceeded"
+	}
+	return models.CARD_RC_APPROVED, ""
+}
+
+// reverse releases the hold of an approved authorization. Reversing twice answers approved again.
+func (h *CardHandler) reverse(msg *models.CardNetworkMessage) (*models.CardNetworkResponse, error) {
+	response := &models.CardNetworkResponse{
+		MTI:  models.CARD_MTI_REVERSAL_RESPONSE,
+		STAN: msg.STAN,
+		RRN:  msg.RRN,
+	}
+
+	tx := h.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var auth models.CardAuthorization
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("rrn = ?", msg.RRN).First(&auth).Error; err != nil ||
+		auth.MaskedPAN != utils.MaskCardPAN(msg.PAN) {
+		tx.Rollback()
+		response.ResponseCode = models.CARD_RC_ORIGINAL_NOT_FOUND
+		return response, nil
+	}
+
+	switch auth.Status {
+	case models.CARD_AUTH_STATUS_REVERSED, models.CARD_AUTH_STATUS_DECLINED:
+		// Nothing is held, so the reversal is already satisfied
+		tx.Rollback()
+		response.AuthCode = auth.AuthCode
+		response.ResponseCode = models.CARD_RC_APPROVED
+		return response, nil
+	case models.CARD_AUTH_STATUS_APPROVED:
+	default:
+		tx.Rollback()
+		response.ResponseCode = models.CARD_RC_INVALID_TRANSACTION
+		return response, nil
+	}
+
+	if err := releaseCardHold(tx, &auth, auth.Amount, "Card authorization reversed "+auth.RRN); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	auth.Status = models.CARD_AUTH_STATUS_REVERSED
+	if err := tx.Save(&auth).Error; err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to update authorization: %v", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("failed to commit reversal: %v", err)
+	}
+
+	response.AuthCode = auth.AuthCode
+	response.ResponseCode = models.CARD_RC_APPROVED
+	return response, nil
+}
+
+// HandleClearing - Receive a clearing batch from the card network and capture the holds (signature verified)
+func (h *CardHandler) HandleClearing(c *gin.Context) {
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Failed to read clearing body",
+		})
+		return
+	}
+
+	if !utils.VerifyCardNetworkMessage(payload, c.GetHeader("X-Signature")) {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Invalid clearing signature",
+		})
+		return
+	}
+
+	var req models.CardClearingRequest
+	if err := binding.JSON.BindBody(payload, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	results := make([]models.CardClearingResult, 0, len(req.Records))
+	var captured int
+	for _, record := range req.Records {
+		reason, err := h.capture(&record, req.BatchReference)
+		if err != nil {
+			log.Printf("Card clearing %s RRN %s failed: %v", req.BatchReference, record.RRN, err)
+			reason = "processing error"
+		}
+
+		result := models.CardClearingResult{RRN: record.RRN, Status: models.CARD_AUTH_STATUS_CAPTURED}
+		if reason != "" {
+			result.Status = "rejected"
+			result.Reason = reason
+		} else {
+			captured++
+		}
+		results = append(results, result)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: fmt.Sprintf("Clearing processed: %d captured, %d rejected", captured, len(results)-captured),
+		Data: gin.H{
+			"batch_reference": req.BatchReference,
+			"results":         results,
+		},
+	})
+}
+
+// capture settles one clearing record against its authorization. The captured amount moves
+// from the hold account to card settlement and any remainder is returned to the cardholder.
+// It returns a rejection reason, or "" when captured; capturing the same record twice is a no-op.
+func (h *CardHandler) capture(record *models.CardClearingRecord, batchReference string) (string, error) {
+	tx := h.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var auth models.CardAuthorization
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("rrn = ?", record.RRN).First(&auth).Error; err != nil {
+		tx.Rollback()
+		return "authorization not found", nil
+	}
+	if auth.AuthCode != record.AuthCode {
+		tx.Rollback()
+		return "authorization code mismatch", nil
+	}
+	if auth.Status == models.CARD_AUTH_STATUS_CAPTURED {
+		tx.Rollback()
+		if auth.CapturedAmount == record.Amount {
+			return "", nil
+		}
+		return "already captured with a different amount", nil
+	}
+	if auth.Status != models.CARD_AUTH_STATUS_APPROVED {
+		tx.Rollback()
+		return fmt.Sprintf("authorization is %s", auth.Status), nil
+	}
+	if record.Amount > auth.Amount {
+		tx.Rollback()
+		return "amount exceeds authorization", nil
+	}
+
+	if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_CARD_HOLD, -record.Amount, auth.RRN,
+		"Card hold captured "+batchReference, auth.HoldTxnID); err != nil {
+		tx.Rollback()
+		return "", err
+	}
+	if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_CARD_SETTLEMENT, record.Amount, auth.RRN,
+		"Card clearing "+batchReference, auth.HoldTxnID); err != nil {
+		tx.Rollback()
+		return "", err
+	}
+	if remainder := auth.Amount - record.Amount; remainder > 0 {
+		if err := releaseCardHold(tx, &auth, remainder, "Card hold remainder released "+auth.RRN); err != nil {
+			tx.Rollback()
+			return "", err
+		}
+	} else if err := completeCardHold(tx, &auth); err != nil {
+		tx.Rollback()
+		return "", err
+	}
+
+	now := time.Now()
+	auth.Status = models.CARD_AUTH_STATUS_CAPTURED
+	auth.CapturedAmount = record.Amount
+	auth.CapturedAt = &now
+	if err := tx.Save(&auth).Error; err != nil {
+		tx.Rollback()
+		return "", fmt.Errorf("failed to update authorization: %v", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return "", fmt.Errorf("failed to commit capture: %v", err)
+	}
+	return "", nil
+}
+
+// RunHoldExpiry releases approved holds whose clearing window ended before the end of date.
+// Each hold is released in its own database transaction, so failures are retried on the next run.
+func (h *CardHandler) RunHoldExpiry(date time.Time) (*CardHoldExpiryRunResult, error) {
+	endOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
+
+	var authIDs []uint
+	if err := h.DB.Model(&models.CardAuthorization{}).
+		Where("status = ? AND hold_expires_at < ?", models.CARD_AUTH_STATUS_APPROVED, endOfDay).
+		Order("id ASC").Pluck("id", &authIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to find expired holds: %v", err)
+	}
+
+	result := &CardHoldExpiryRunResult{Date: date.Format("2006-01-02")}
+	for _, authID := range authIDs {
+		amount, err := h.expireHold(authID)
+		if err != nil {
+			log.Printf("Card hold %d expiry failed: %v", authID, err)
+			result.Failed++
+			continue
+		}
+		if amount > 0 {
+			result.Released++
+			result.Amount += amount
+		}
+	}
+
+	return result, nil
+}
+
+// expireHold releases one stale hold and returns the amount released
+func (h *CardHandler) expireHold(authID uint) (int64, error) {
+	tx := h.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var auth models.CardAuthorization
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&auth, authID).Error; err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("failed to lock authorization: %v", err)
+	}
+	if auth.Status != models.CARD_AUTH_STATUS_APPROVED {
+		// Captured or reversed since the run started
+		tx.Rollback()
+		return 0, nil
+	}
+
+	if err := releaseCardHold(tx, &auth, auth.Amount, "Card hold expired "+auth.RRN); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	auth.Status = models.CARD_AUTH_STATUS_EXPIRED
+	if err := tx.Save(&auth).Error; err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("failed to update authorization: %v", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, fmt.Errorf("failed to commit hold expiry: %v", err)
+	}
+	return auth.Amount, nil
+}
+
+// releaseCardHold returns amount of a hold to the cardholder and completes the hold transaction.
+// It must be called inside a database transaction; the caller sets the authorization status.
+func releaseCardHold(tx *gorm.DB, auth *models.CardAuthorization, amount int64, description string) error {
+	if auth.UserID == nil {
+		return errors.New("authorization has no cardholder")
+	}
+
+	releaseTxn, err := creditUserBalance(tx, *auth.UserID, amount, "card_hold_release", description)
+	if err != nil {
+		return err
+	}
+	if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_CARD_HOLD, -amount, auth.RRN,
+		description, &releaseTxn.ID); err != nil {
+		return err
+	}
+	if err := completeCardHold(tx, auth); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	auth.ReleaseTxnID = &releaseTxn.ID
+	auth.ReleasedAt = &now
+	return nil
+}
+
+// completeCardHold moves the pending hold transaction of an authorization to completed
+func completeCardHold(tx *gorm.DB, auth *models.CardAuthorization) error {
+	if auth.HoldTxnID == nil {
+		return errors.New("authorization has no hold transaction")
+	}
+
+	var holdTxn models.Transaction
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&holdTxn, *auth.HoldTxnID).Error; err != nil {
+		return fmt.Errorf("failed to get hold transaction: %v", err)
+	}
+	return transitionTransaction(tx, &holdTxn, models.TRANSACTION_STATUS_COMPLETED, nil)
+}
+
+// authorizationResponse builds the 0110 answer for a stored authorization
+func authorizationResponse(auth *models.CardAuthorization) *models.CardNetworkResponse {
+	return &models.CardNetworkResponse{
+		MTI:          models.CARD_MTI_AUTH_RESPONSE,
+		STAN:         auth.STAN,
+		RRN:          auth.RRN,
+		AuthCode:     auth.AuthCode,
+		ResponseCode: auth.ResponseCode,
+	}
+}
+
+// responseMTI returns the response message type for a request message type
+func responseMTI(mti string) string {
+	if mti == models.CARD_MTI_REVERSAL_REQUEST {
+		return models.CARD_MTI_REVERSAL_RESPONSE
+	}
+	return models.CARD_MTI_AUTH_RESPONSE
+}
+
+// GetAllAuthorizations - List card authorizations (admin)
+func (h *CardHandler) GetAllAuthorizations(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.CardAuthorization{})
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if cardID := c.Query("card_id"); cardID != "" {
+		query = query.Where("card_id = ?", cardID)
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if responseCode := c.Query("response_code"); responseCode != "" {
+		query = query.Where("response_code = ?", responseCode)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var authorizations []models.CardAuthorization
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&authorizations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch card authorizations",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Card authorizations retrieved successfully",
+		Data: gin.H{
+			"authorizations": authorizations,
+			"pagination": gin.H{
+				"page":        page,
+				"limit":       limit,
+				"total":       total,
+				"total_pages": int(math.Ceil(float64(total) / float64(limit))),
+			},
+		},
+	})
+}
+
+// RunHoldExpiryJob - Release card holds that were not cleared in time (admin)
+func (h *CardHandler) RunHoldExpiryJob(c *gin.Context) {
+	var req models.RunCardHoldExpiryRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	date := time.Now()
+	if req.Date != "" {
+		date, _ = time.ParseInLocation("2006-01-02", req.Date, time.Local)
+	}
+
+	result, err := h.RunHoldExpiry(date)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Card hold expiry completed",
+		Data:    result,
+	})
+}
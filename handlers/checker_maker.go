@@ -361,7 +361,7 @@ func (h *CheckerMakerHandler) ApproveOrRejectTransaction(c *gin.Context) {
 	}
 
 	// Start transaction
-	tx := h.DB.Begin()
+	tx := h.DB.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
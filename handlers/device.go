@@ -0,0 +1,739 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"mbankingcore/config"
+	"mbankingcore/models"
+	"mbankingcore/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Default device binding parameters, overridable through config
+const (
+	defaultDeviceCoolingOffHours         = 24
+	defaultDeviceCoolingOffMaxAmount     = 5000000 // Total outgoing amount allowed from a new device during cooling-off
+	defaultDeviceMaxBound                = 3
+	defaultDeviceSignatureMaxSkewSeconds = 300
+	deviceVerificationMaxAttempts        = 5
+	deviceVerificationValidity           = 5 * time.Minute
+	deviceLastUsedUpdateInterval         = time.Minute
+	deviceNonceCleanupInterval           = 10 * time.Minute
+)
+
+// deviceNoncePattern is the accepted X-Nonce format, e.g. a UUID or 32 hex characters
+var deviceNoncePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{16,64}$`)
+
+// errTooManyDevices is returned when binding another device would exceed device_max_bound
+var errTooManyDevices = errors.New("maximum number of bound devices reached")
+
+// coolingOffTransactionTypes are the debits counted against the cooling-off limit of a new device
+var coolingOffTransactionTypes = []string{
+	models.TRANSACTION_TYPE_WITHDRAW,
+	models.TRANSACTION_TYPE_TRANSFER_OUT,
+	models.TRANSACTION_TYPE_INTERBANK_TRANSFER_OUT,
+	models.TRANSACTION_TYPE_BILL_PAYMENT,
+	models.TRANSACTION_TYPE_QR_PAYMENT,
+}
+
+type DeviceHandler struct {
+	DB             *gorm.DB
+	SessionManager *utils.SessionManager
+}
+
+func NewDeviceHandler(db *gorm.DB) *DeviceHandler {
+	return &DeviceHandler{
+		DB:             db,
+		SessionManager: utils.NewSessionManager(db),
+	}
+}
+
+// bindLoginDevice checks the login device against the user's bound devices. A known device passes;
+// the very first device of an account is bound straight away. Any other device gets a pending
+// verification which the caller must return to the app.
+func bindLoginDevice(db *gorm.DB, user *models.User, info models.DeviceInfo, ipAddress string) (*models.DeviceVerification, error) {
+	now := time.Now()
+
+	var device models.TrustedDevice
+	err := db.Where("user_id = ? AND device_id = ? AND status = ?", user.ID, info.DeviceID, models.TRUSTED_DEVICE_STATUS_ACTIVE).
+		First(&device).Error
+	if err == nil {
+		return nil, db.Model(&device).Updates(map[string]interface{}{
+			"last_used_at":    now,
+			"last_ip_address": ipAddress,
+		}).Error
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var everBound, active int64
+	if err := db.Model(&models.TrustedDevice{}).Where("user_id = ?", user.ID).Count(&everBound).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Model(&models.TrustedDevice{}).Where("user_id = ? AND status = ?", user.ID, models.TRUSTED_DEVICE_STATUS_ACTIVE).
+		Count(&active).Error; err != nil {
+		return nil, err
+	}
+
+	if everBound == 0 {
+		_, err := activateTrustedDevice(db, user.ID, info, nil, true, ipAddress)
+		return nil, err
+	}
+	if active >= getConfigInt64(db, "device_max_bound", defaultDeviceMaxBound) {
+		return nil, errTooManyDevices
+	}
+
+	code, err := generateDigits(6)
+	if err != nil {
+		return nil, err
+	}
+	verification := models.DeviceVerification{
+		UserID:            user.ID,
+		VerificationToken: utils.GenerateLoginToken(),
+		VerificationCode:  code,
+		DeviceType:        info.DeviceType,
+		DeviceID:          info.DeviceID,
+		DeviceName:        info.DeviceName,
+		PublicKey:         info.PublicKey,
+		IPAddress:         ipAddress,
+		ExpiresAt:         now.Add(deviceVerificationValidity),
+	}
+	if err := db.Create(&verification).Error; err != nil {
+		return nil, err
+	}
+
+	// TODO: Send the code via SMS to the registered phone number. Until then the code is logged in
+	// debug mode only, so that devices can be verified in development.
+	if gin.IsDebugging() {
+		log.Printf("Device verification code for phone %s: %s", utils.MaskPartial(user.Phone), code)
+	}
+	return &verification, nil
+}
+
+// activateTrustedDevice binds a device to a user, reusing the row of a previously unbound device
+func activateTrustedDevice(db *gorm.DB, userID uint, info models.DeviceInfo, coolingOffUntil *time.Time, isPrimary bool, ipAddress string) (*models.TrustedDevice, error) {
+	var device models.TrustedDevice
+	err := db.Where("user_id = ? AND device_id = ?", userID, info.DeviceID).First(&device).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	now := time.Now()
+	device.UserID = userID
+	device.DeviceID = info.DeviceID
+	device.DeviceType = info.DeviceType
+	device.DeviceName = info.DeviceName
+	device.Status = models.TRUSTED_DEVICE_STATUS_ACTIVE
+	device.IsPrimary = device.IsPrimary || isPrimary
+	device.BoundAt = now
+	device.CoolingOffUntil = coolingOffUntil
+	device.LastUsedAt = &now
+	device.LastIPAddress = ipAddress
+	device.UnboundAt = nil
+	device.UnboundBy = ""
+	device.UnboundReason = ""
+	if info.PublicKey != "" {
+		_, algorithm, fingerprint, err := utils.ParseDevicePublicKey(info.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		device.PublicKey = info.PublicKey
+		device.KeyAlgorithm = algorithm
+		device.KeyFingerprint = fingerprint
+	}
+
+	if err := db.Save(&device).Error; err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+// VerifyRequestDevice lets a request through only from an active session on a device bound to the
+// user, and checks the request signature of mutating requests when the device registered a key.
+// Writes the error response and returns false otherwise. Used by the device binding middleware.
+func VerifyRequestDevice(c *gin.Context) bool {
+	db := config.DB
+
+	userIDValue, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return false
+	}
+	userID := userIDValue.(uint)
+
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	var session models.DeviceSession
+	if err := db.Where("session_token = ? AND user_id = ? AND is_active = ? AND expires_at > ?", token, userID, true, time.Now()).
+		First(&session).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Session is no longer active, please log in again",
+		})
+		return false
+	}
+	if session.DeviceID == "" {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Code:    http.StatusForbidden,
+			Message: "Session is not bound to a device, please log in again",
+		})
+		return false
+	}
+	if headerDeviceID := c.GetHeader("X-Device-ID"); headerDeviceID != "" && headerDeviceID != session.DeviceID {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Code:    http.StatusForbidden,
+			Message: "Device does not match the session",
+		})
+		return false
+	}
+
+	var device models.TrustedDevice
+	err := db.Where("user_id = ? AND device_id = ?", userID, session.DeviceID).First(&device).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Sessions created before device binding bind their device on first use, as long as the
+		// account has never had a bound device
+		var everBound int64
+		db.Model(&models.TrustedDevice{}).Where("user_id = ?", userID).Count(&everBound)
+		if everBound > 0 {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Code:    http.StatusForbidden,
+				Message: "This device is not bound to your account",
+			})
+			return false
+		}
+		var bound *models.TrustedDevice
+		bound, err = activateTrustedDevice(db, userID, models.DeviceInfo{
+			DeviceType: session.DeviceType,
+			DeviceID:   session.DeviceID,
+			DeviceName: session.DeviceName,
+		}, nil, true, c.ClientIP())
+		if bound != nil {
+			device = *bound
+		}
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to verify device",
+		})
+		return false
+	}
+	if device.Status != models.TRUSTED_DEVICE_STATUS_ACTIVE {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Code:    http.StatusForbidden,
+			Message: "This device is not bound to your account",
+		})
+		return false
+	}
+
+	if device.PublicKey != "" && c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		if message := verifyRequestSignature(c, db, &device); message != "" {
+			c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+				Code:    http.StatusUnauthorized,
+				Message: message,
+			})
+			return false
+		}
+	}
+
+	now := time.Now()
+	if device.LastUsedAt == nil || now.Sub(*device.LastUsedAt) > deviceLastUsedUpdateInterval {
+		db.Model(&device).Updates(map[string]interface{}{
+			"last_used_at":    now,
+			"last_ip_address": c.ClientIP(),
+		})
+	}
+
+	c.Set("trusted_device", device)
+	return true
+}
+
+// verifyRequestSignature checks the X-Timestamp, X-Nonce and X-Signature headers of a request
+// against the device key and returns an error message when they do not verify. The timestamp (unix
+// seconds) must be within device_signature_max_skew_seconds of the server time, and a nonce is
+// accepted once per device within that window.
+func verifyRequestSignature(c *gin.Context, db *gorm.DB, device *models.TrustedDevice) string {
+	timestamp := c.GetHeader("X-Timestamp")
+	nonce := c.GetHeader("X-Nonce")
+	signature := c.GetHeader("X-Signature")
+	if timestamp == "" || nonce == "" || signature == "" {
+		return "Request signature required (X-Timestamp, X-Nonce and X-Signature headers)"
+	}
+
+	signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "Invalid X-Timestamp header"
+	}
+	if !deviceNoncePattern.MatchString(nonce) {
+		return "Invalid X-Nonce header"
+	}
+	maxSkew := getConfigInt64(db, "device_signature_max_skew_seconds", defaultDeviceSignatureMaxSkewSeconds)
+	if skew := time.Now().Unix() - signedAt; skew > maxSkew || skew < -maxSkew {
+		return "Request timestamp is outside the allowed window"
+	}
+
+	var body []byte
+	if c.Request.Body != nil {
+		body, _ = io.ReadAll(c.Request.Body)
+		c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+	}
+
+	payload := utils.DeviceSigningPayload(c.Request.Method, c.Request.URL.RequestURI(), timestamp, nonce, body)
+	if err := utils.VerifyDeviceSignature(device.PublicKey, payload, signature); err != nil {
+		return "Invalid request signature"
+	}
+
+	// Record the nonce only once the signature verifies, so nobody else can use up a device's nonces
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.DeviceRequestNonce{
+		TrustedDeviceID: device.ID,
+		Nonce:           nonce,
+		ExpiresAt:       time.Unix(signedAt+maxSkew, 0),
+	})
+	if result.Error != nil {
+		log.Printf("Failed to record request nonce for device %d: %v", device.ID, result.Error)
+		return "Failed to verify request signature"
+	}
+	if result.RowsAffected == 0 {
+		return "Request nonce has already been used"
+	}
+	return ""
+}
+
+// RunNonceCleanup deletes expired request nonces periodically, intended to run in its own goroutine
+// for the lifetime of the server. An expired nonce can no longer be replayed since its timestamp is
+// outside the allowed skew.
+func (h *DeviceHandler) RunNonceCleanup() {
+	ticker := time.NewTicker(deviceNonceCleanupInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := h.DB.Where("expires_at < ?", time.Now()).Delete(&models.DeviceRequestNonce{}).Error; err != nil {
+			log.Printf("Device nonce cleanup: %v", err)
+		}
+	}
+}
+
+// checkDeviceCoolingOff writes the refusal response and returns false when a debit from a device
+// still in its cooling-off period would take the debits since the device was bound over the
+// cooling-off limit
+func checkDeviceCoolingOff(c *gin.Context, db *gorm.DB, userID uint, currency string, amount int64) bool {
+	deviceValue, exists := c.Get("trusted_device")
+	if !exists {
+		return true
+	}
+	device := deviceValue.(models.TrustedDevice)
+	if !device.InCoolingOff(time.Now()) {
+		return true
+	}
+
+	baseAmount, err := coolingOffBaseAmount(db, currency, amount)
+	if err == nil {
+		var spent int64
+		spent, err = coolingOffSpent(db, userID, device.BoundAt)
+		baseAmount += spent
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to check device limits",
+		})
+		return false
+	}
+
+	limit := getConfigInt64(db, "device_cooling_off_max_amount", defaultDeviceCoolingOffMaxAmount)
+	if baseAmount > limit {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Code: http.StatusForbidden,
+			Message: fmt.Sprintf("Transactions from a newly bound device are limited to %d in total until %s",
+				limit, device.CoolingOffUntil.Format(time.RFC3339)),
+		})
+		return false
+	}
+	return true
+}
+
+// coolingOffSpent sums the user's debits since a device was bound, in the base currency
+func coolingOffSpent(db *gorm.DB, userID uint, since time.Time) (int64, error) {
+	var rows []struct {
+		Currency string
+		Total    int64
+	}
+	if err := db.Model(&models.Transaction{}).Select("currency, SUM(amount) AS total").
+		Where("user_id = ? AND type IN ? AND status NOT IN ? AND created_at >= ?", userID, coolingOffTransactionTypes,
+			[]string{models.TRANSACTION_STATUS_FAILED, models.TRANSACTION_STATUS_REVERSED}, since).
+		Group("currency").Scan(&rows).Error; err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, row := range rows {
+		converted, err := coolingOffBaseAmount(db, row.Currency, row.Total)
+		if err != nil {
+			return 0, err
+		}
+		total += converted
+	}
+	return total, nil
+}
+
+// coolingOffBaseAmount converts an amount to the base currency
+func coolingOffBaseAmount(db *gorm.DB, currency string, amount int64) (int64, error) {
+	if currency == "" || currency == models.BASE_CURRENCY {
+		return amount, nil
+	}
+	conversion, err := getFXConversion(db, currency, models.BASE_CURRENCY)
+	if err != nil {
+		return 0, err
+	}
+	return conversion.Convert(amount)
+}
+
+// VerifyDevice - Complete the verification of a new device and log in (public, second factor of login)
+func (h *DeviceHandler) VerifyDevice(c *gin.Context) {
+	var req models.DeviceVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.InvalidRequestResponse())
+		return
+	}
+
+	var verification models.DeviceVerification
+	if err := h.DB.Where("verification_token = ? AND is_used = ? AND expires_at > ?", req.VerificationToken, false, time.Now()).
+		First(&verification).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "Invalid or expired device verification",
+		})
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(verification.VerificationCode), []byte(req.OtpCode)) != 1 {
+		verification.Attempts++
+		if verification.Attempts >= deviceVerificationMaxAttempts {
+			verification.IsUsed = true
+		}
+		h.DB.Save(&verification)
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "Invalid verification code",
+		})
+		return
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, verification.UserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.UserNotFoundResponse())
+		return
+	}
+
+	info := models.DeviceInfo{
+		DeviceType: verification.DeviceType,
+		DeviceID:   verification.DeviceID,
+		DeviceName: verification.DeviceName,
+		PublicKey:  verification.PublicKey,
+	}
+	ipAddress := utils.GetClientIP(c)
+	coolingOffUntil := time.Now().Add(time.Duration(getConfigInt64(h.DB, "device_cooling_off_hours", defaultDeviceCoolingOffHours)) * time.Hour)
+
+	tx := h.DB.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	now := time.Now()
+	verification.IsUsed = true
+	verification.VerifiedAt = &now
+	if err := tx.Save(&verification).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.InternalServerResponse())
+		return
+	}
+
+	device, err := activateTrustedDevice(tx, user.ID, info, &coolingOffUntil, false, ipAddress)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.InternalServerResponse())
+		return
+	}
+
+	session, err := utils.NewSessionManager(tx).CreateSession(user.ID, models.MultiPlatformLoginRequest{
+		Phone:      user.Phone,
+		Provider:   models.LoginProviderEmail,
+		DeviceInfo: info,
+	}, ipAddress)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.InternalServerResponse())
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.InternalServerResponse())
+		return
+	}
+
+	// Remove sensitive data from response
+	user.PinAtm = ""
+	info.PublicKey = ""
+
+	c.JSON(http.StatusOK, models.Response{
+		Code: 200,
+		Message: fmt.Sprintf("Device verified and login successful. Lower limits apply to this device until %s",
+			device.CoolingOffUntil.Format(time.RFC3339)),
+		Data: models.MultiPlatformLoginResponse{
+			User:         user,
+			AccessToken:  session.SessionToken,
+			RefreshToken: session.RefreshToken,
+			ExpiresIn:    24 * 60 * 60,
+			SessionID:    session.ID,
+			DeviceInfo:   info,
+		},
+	})
+}
+
+// GetDevices - List the user's devices (?status=active|unbound)
+func (h *DeviceHandler) GetDevices(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	query := h.DB.Where("user_id = ?", userID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var devices []models.TrustedDevice
+	if err := query.Order("status, bound_at DESC").Find(&devices).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch devices",
+		})
+		return
+	}
+
+	currentDeviceID := ""
+	if deviceValue, ok := c.Get("trusted_device"); ok {
+		currentDeviceID = deviceValue.(models.TrustedDevice).DeviceID
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Devices retrieved successfully",
+		Data: gin.H{
+			"devices":           devices,
+			"current_device_id": currentDeviceID,
+		},
+	})
+}
+
+// RegisterPublicKey - Register or rotate the signing key of the current device. Rotation must be
+// signed with the old key, which the device binding middleware enforces.
+func (h *DeviceHandler) RegisterPublicKey(c *gin.Context) {
+	deviceValue, exists := c.Get("trusted_device")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+	device := deviceValue.(models.TrustedDevice)
+
+	var req models.RegisterDeviceKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	_, algorithm, fingerprint, err := utils.ParseDevicePublicKey(req.PublicKey)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.DB.Model(&device).Updates(map[string]interface{}{
+		"public_key":      req.PublicKey,
+		"key_algorithm":   algorithm,
+		"key_fingerprint": fingerprint,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to register device key",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Device key registered successfully",
+		Data:    device,
+	})
+}
+
+// UnbindDevice - Unbind one of the user's devices and end its sessions (PIN required)
+func (h *DeviceHandler) UnbindDevice(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	var req models.UnbindDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.UserNotFoundResponse())
+		return
+	}
+	if err := utils.CheckPassword(user.PinAtm, req.PIN); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "Invalid PIN",
+		})
+		return
+	}
+
+	var device models.TrustedDevice
+	if err := h.DB.Where("id = ? AND user_id = ? AND status = ?", c.Param("id"), user.ID, models.TRUSTED_DEVICE_STATUS_ACTIVE).
+		First(&device).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Device not found",
+		})
+		return
+	}
+
+	if err := h.unbind(&device, "user", req.Reason); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to unbind device",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Device unbound successfully",
+		Data:    device,
+	})
+}
+
+// unbind marks a device unbound and ends its sessions
+func (h *DeviceHandler) unbind(device *models.TrustedDevice, unboundBy, reason string) error {
+	tx := h.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	now := time.Now()
+	device.Status = models.TRUSTED_DEVICE_STATUS_UNBOUND
+	device.UnboundAt = &now
+	device.UnboundBy = unboundBy
+	device.UnboundReason = reason
+	if err := tx.Save(device).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := utils.NewSessionManager(tx).LogoutDeviceSessions(device.UserID, device.DeviceID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// Admin endpoints
+
+// GetUserDevices - List the devices of a user (Admin only)
+func (h *DeviceHandler) GetUserDevices(c *gin.Context) {
+	var devices []models.TrustedDevice
+	if err := h.DB.Where("user_id = ?", c.Param("user_id")).Order("status, bound_at DESC").Find(&devices).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch devices",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Devices retrieved successfully",
+		Data:    devices,
+	})
+}
+
+// AdminUnbindDevice - Unbind a user's device, e.g. a reported lost phone (Admin only)
+func (h *DeviceHandler) AdminUnbindDevice(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+
+	var req models.AdminUnbindDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var device models.TrustedDevice
+	if err := h.DB.Where("id = ? AND status = ?", c.Param("id"), models.TRUSTED_DEVICE_STATUS_ACTIVE).First(&device).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Device not found",
+		})
+		return
+	}
+
+	if err := h.unbind(&device, "admin", req.Reason); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to unbind device",
+		})
+		return
+	}
+
+	createAuditLog(h.DB, c, "trusted_device", device.ID, adminID.(uint), "UNBIND", map[string]interface{}{
+		"user_id":   device.UserID,
+		"device_id": device.DeviceID,
+		"reason":    req.Reason,
+	})
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Device unbound successfully",
+		Data:    device,
+	})
+}
@@ -0,0 +1,930 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"mbankingcore/models"
+	"mbankingcore/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxDisbursementFileSize = 5 << 20 // 5 MB
+	maxDisbursementRows     = 10000
+)
+
+type DisbursementHandler struct {
+	DB *gorm.DB
+}
+
+func NewDisbursementHandler(db *gorm.DB) *DisbursementHandler {
+	return &DisbursementHandler{DB: db}
+}
+
+// UploadDisbursement - Upload a CSV file of account numbers and amounts (maker)
+// Expected columns: account_number, amount, description (optional). A header row is allowed.
+func (h *DisbursementHandler) UploadDisbursement(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "CSV file is required (form field 'file')",
+		})
+		return
+	}
+
+	if fileHeader.Size > maxDisbursementFileSize {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("File is too large (max %d bytes)", maxDisbursementFileSize),
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Failed to read uploaded file",
+		})
+		return
+	}
+	defer file.Close()
+
+	rows, err := readDisbursementCSV(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	items, err := h.validateDisbursementRows(rows)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to validate disbursement rows",
+		})
+		return
+	}
+
+	batch := models.DisbursementBatch{
+		BatchRef:     utils.GenerateReference("DSB"),
+		FileName:     fileHeader.Filename,
+		Description:  c.PostForm("description"),
+		SourceFormat: models.DISBURSEMENT_SOURCE_CSV,
+		MakerAdminID: adminID.(uint),
+	}
+
+	h.submitBatch(c, &batch, items)
+}
+
+// submitBatch stores a validated batch with its items, submits it for checker approval
+// and writes the upload response. Used by both the CSV and the ISO 20022 pain.001 upload.
+func (h *DisbursementHandler) submitBatch(c *gin.Context, batch *models.DisbursementBatch, items []models.DisbursementItem) {
+	batch.TotalRows = len(items)
+
+	for _, item := range items {
+		if item.Status == models.DISBURSEMENT_ITEM_VALID {
+			batch.ValidRows++
+			batch.TotalAmount += item.Amount
+		} else {
+			batch.InvalidRows++
+		}
+	}
+
+	if batch.ValidRows == 0 {
+		batch.Status = models.DISBURSEMENT_STATUS_INVALID
+	} else {
+		batch.Status = models.DISBURSEMENT_STATUS_PENDING
+		expiresAt := time.Now().Add(time.Duration(h.approvalExpiryHours()) * time.Hour)
+		batch.ExpiresAt = &expiresAt
+	}
+
+	tx := h.DB.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Create(batch).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create disbursement batch",
+		})
+		return
+	}
+
+	for i := range items {
+		items[i].BatchID = batch.ID
+	}
+
+	if err := tx.CreateInBatches(&items, 500).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to store disbursement rows",
+		})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to commit disbursement batch",
+		})
+		return
+	}
+
+	createAuditLog(h.DB, c, "disbursement_batch", batch.ID, batch.MakerAdminID, "CREATE", map[string]interface{}{
+		"batch_ref":     batch.BatchRef,
+		"file_name":     batch.FileName,
+		"source_format": batch.SourceFormat,
+		"total_rows":    batch.TotalRows,
+		"valid_rows":    batch.ValidRows,
+		"invalid_rows":  batch.InvalidRows,
+		"total_amount":  batch.TotalAmount,
+	})
+
+	h.DB.Preload("MakerAdmin").First(batch, batch.ID)
+
+	invalidItems := []models.DisbursementItem{}
+	for _, item := range items {
+		if item.Status == models.DISBURSEMENT_ITEM_INVALID {
+			invalidItems = append(invalidItems, item)
+		}
+	}
+
+	if batch.Status == models.DISBURSEMENT_STATUS_INVALID {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Disbursement file has no valid rows",
+			Data: gin.H{
+				"batch":         batch.ToResponse(),
+				"invalid_items": invalidItems,
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Code:    http.StatusCreated,
+		Message: "Disbursement batch uploaded and submitted for approval",
+		Data: gin.H{
+			"batch":         batch.ToResponse(),
+			"invalid_items": invalidItems,
+		},
+	})
+}
+
+// GetDisbursements - List disbursement batches
+func (h *DisbursementHandler) GetDisbursements(c *gin.Context) {
+	page := 1
+	limit := 20
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+			limit = parsed
+		}
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.DisbursementBatch{})
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if makerAdminID := c.Query("maker_admin_id"); makerAdminID != "" {
+		if parsed, err := strconv.ParseUint(makerAdminID, 10, 32); err == nil {
+			query = query.Where("maker_admin_id = ?", uint(parsed))
+		}
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var batches []models.DisbursementBatch
+	if err := query.Preload("MakerAdmin").Preload("CheckerAdmin").
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&batches).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch disbursement batches",
+		})
+		return
+	}
+
+	responses := []models.DisbursementBatchResponse{}
+	for i := range batches {
+		responses = append(responses, batches[i].ToResponse())
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Disbursement batches retrieved successfully",
+		Data: gin.H{
+			"batches": responses,
+			"pagination": gin.H{
+				"page":        page,
+				"limit":       limit,
+				"total":       total,
+				"total_pages": (total + int64(limit) - 1) / int64(limit),
+			},
+		},
+	})
+}
+
+// GetDisbursementByID - Get batch detail with its per-row report
+func (h *DisbursementHandler) GetDisbursementByID(c *gin.Context) {
+	batchID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid disbursement batch ID",
+		})
+		return
+	}
+
+	var batch models.DisbursementBatch
+	if err := h.DB.Preload("MakerAdmin").Preload("CheckerAdmin").First(&batch, uint(batchID)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "Disbursement batch not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch disbursement batch",
+		})
+		return
+	}
+
+	page := 1
+	limit := 100
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 500 {
+			limit = parsed
+		}
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.DisbursementItem{}).Where("batch_id = ?", batch.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var items []models.DisbursementItem
+	if err := query.Order("row_number ASC").Offset(offset).Limit(limit).Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch disbursement items",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Disbursement batch retrieved successfully",
+		Data: gin.H{
+			"batch": batch.ToResponse(),
+			"items": items,
+			"pagination": gin.H{
+				"page":        page,
+				"limit":       limit,
+				"total":       total,
+				"total_pages": (total + int64(limit) - 1) / int64(limit),
+			},
+		},
+	})
+}
+
+// DownloadDisbursementReport - Download the per-row result report as CSV
+func (h *DisbursementHandler) DownloadDisbursementReport(c *gin.Context) {
+	batchID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid disbursement batch ID",
+		})
+		return
+	}
+
+	var batch models.DisbursementBatch
+	if err := h.DB.First(&batch, uint(batchID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Disbursement batch not found",
+		})
+		return
+	}
+
+	var items []models.DisbursementItem
+	if err := h.DB.Where("batch_id = ?", batch.ID).Order("row_number ASC").Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch disbursement items",
+		})
+		return
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s-report.csv", batch.BatchRef))
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{"row_number", "account_number", "account_name", "amount", "description", "status", "failure_reason", "transaction_id", "processed_at"})
+	for _, item := range items {
+		transactionID := ""
+		if item.TransactionID != nil {
+			transactionID = strconv.FormatUint(uint64(*item.TransactionID), 10)
+		}
+		processedAt := ""
+		if item.ProcessedAt != nil {
+			processedAt = item.ProcessedAt.Format(time.RFC3339)
+		}
+		writer.Write([]string{
+			strconv.Itoa(item.RowNumber),
+			item.AccountNumber,
+			item.AccountName,
+			strconv.FormatInt(item.Amount, 10),
+			item.Description,
+			item.Status,
+			item.FailureReason,
+			transactionID,
+			processedAt,
+		})
+	}
+	writer.Flush()
+}
+
+// ReviewDisbursement - Approve or reject a pending batch (checker)
+func (h *DisbursementHandler) ReviewDisbursement(c *gin.Context) {
+	checkerAdminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+
+	batchID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid disbursement batch ID",
+		})
+		return
+	}
+
+	var req models.DisbursementReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if req.Action == "reject" && req.RejectionReason == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Rejection reason is required when rejecting a disbursement batch",
+		})
+		return
+	}
+
+	tx := h.DB.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var batch models.DisbursementBatch
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&batch, uint(batchID)).Error; err != nil {
+		tx.Rollback()
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "Disbursement batch not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch disbursement batch",
+		})
+		return
+	}
+
+	if batch.Status != models.DISBURSEMENT_STATUS_PENDING {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Disbursement batch is already %s", batch.Status),
+		})
+		return
+	}
+
+	now := time.Now()
+	checkerID := checkerAdminID.(uint)
+
+	if batch.ExpiresAt != nil && now.After(*batch.ExpiresAt) {
+		tx.Model(&batch).Update("status", models.DISBURSEMENT_STATUS_EXPIRED)
+		tx.Model(&models.DisbursementItem{}).
+			Where("batch_id = ? AND status = ?", batch.ID, models.DISBURSEMENT_ITEM_VALID).
+			Update("status", models.DISBURSEMENT_ITEM_SKIPPED)
+		tx.Commit()
+
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Disbursement batch has expired",
+		})
+		return
+	}
+
+	// Segregation of duties
+	if batch.MakerAdminID == checkerID {
+		tx.Rollback()
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Code:    http.StatusForbidden,
+			Message: "Admin cannot approve their own disbursement batch (segregation of duties)",
+		})
+		return
+	}
+
+	var updates map[string]interface{}
+	if req.Action == "approve" {
+		updates = map[string]interface{}{
+			"status":            models.DISBURSEMENT_STATUS_PROCESSING,
+			"checker_admin_id":  checkerID,
+			"approval_comments": req.Comments,
+			"approved_at":       &now,
+		}
+	} else {
+		updates = map[string]interface{}{
+			"status":            models.DISBURSEMENT_STATUS_REJECTED,
+			"checker_admin_id":  checkerID,
+			"approval_comments": req.Comments,
+			"rejection_reason":  req.RejectionReason,
+			"rejected_at":       &now,
+		}
+
+		if err := tx.Model(&models.DisbursementItem{}).
+			Where("batch_id = ? AND status = ?", batch.ID, models.DISBURSEMENT_ITEM_VALID).
+			Update("status", models.DISBURSEMENT_ITEM_SKIPPED).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to update disbursement items",
+			})
+			return
+		}
+	}
+
+	if err := tx.Model(&batch).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update disbursement batch",
+		})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to commit disbursement review",
+		})
+		return
+	}
+
+	createAuditLog(h.DB, c, "disbursement_batch", batch.ID, checkerID, strings.ToUpper(req.Action), map[string]interface{}{
+		"batch_ref":        batch.BatchRef,
+		"action":           req.Action,
+		"comments":         req.Comments,
+		"rejection_reason": req.RejectionReason,
+	})
+
+	// Execute asynchronously; progress is visible through the batch detail endpoint
+	if req.Action == "approve" {
+		go h.ProcessBatch(batch.ID)
+	}
+
+	h.DB.Preload("MakerAdmin").Preload("CheckerAdmin").First(&batch, batch.ID)
+
+	message := "Disbursement batch rejected successfully"
+	if req.Action == "approve" {
+		message = "Disbursement batch approved and queued for execution"
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: message,
+		Data:    batch.ToResponse(),
+	})
+}
+
+// FundDisbursements - Record funds received to pay out bulk disbursements (admin)
+func (h *DisbursementHandler) FundDisbursements(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+
+	var req models.DisbursementFundingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	description := req.Description
+	if description == "" {
+		description = "Bulk disbursement funding"
+	}
+
+	var funding models.SystemAccount
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_DISBURSEMENT_FUNDING, req.Amount, req.Reference, description, nil); err != nil {
+			return err
+		}
+		return tx.Where("code = ?", models.SYSTEM_ACCOUNT_DISBURSEMENT_FUNDING).First(&funding).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to record disbursement funding",
+		})
+		return
+	}
+
+	createAuditLog(h.DB, c, "system_account", funding.ID, adminID.(uint), "FUND", map[string]interface{}{
+		"code":      funding.Code,
+		"amount":    req.Amount,
+		"reference": req.Reference,
+		"balance":   funding.Balance,
+	})
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Disbursement funding recorded",
+		Data:    funding,
+	})
+}
+
+// ProcessBatch executes all valid rows of an approved batch. Each row is credited in its own
+// database transaction so a single failure does not affect the rest of the batch.
+// Rows that are no longer in "valid" status are skipped, which makes the call safe to re-run.
+func (h *DisbursementHandler) ProcessBatch(batchID uint) {
+	var batch models.DisbursementBatch
+	if err := h.DB.First(&batch, batchID).Error; err != nil {
+		log.Printf("Disbursement batch %d not found: %v", batchID, err)
+		return
+	}
+
+	if batch.Status != models.DISBURSEMENT_STATUS_PROCESSING {
+		return
+	}
+
+	startedAt := time.Now()
+	h.DB.Model(&batch).Update("started_at", &startedAt)
+
+	var items []models.DisbursementItem
+	if err := h.DB.Where("batch_id = ? AND status = ?", batch.ID, models.DISBURSEMENT_ITEM_VALID).
+		Order("row_number ASC").
+		Find(&items).Error; err != nil {
+		log.Printf("Failed to load items for disbursement batch %s: %v", batch.BatchRef, err)
+		return
+	}
+
+	for i := range items {
+		h.processItem(&batch, &items[i])
+	}
+
+	h.finalizeBatch(batch.ID)
+}
+
+// ResumeProcessingBatches restarts execution of batches interrupted by a server restart
+func (h *DisbursementHandler) ResumeProcessingBatches() {
+	var batches []models.DisbursementBatch
+	if err := h.DB.Where("status = ?", models.DISBURSEMENT_STATUS_PROCESSING).Find(&batches).Error; err != nil {
+		log.Printf("Failed to load processing disbursement batches: %v", err)
+		return
+	}
+
+	for _, batch := range batches {
+		log.Printf("Resuming disbursement batch %s", batch.BatchRef)
+		go h.ProcessBatch(batch.ID)
+	}
+}
+
+// processItem credits a single disbursement row against the disbursement funding account
+func (h *DisbursementHandler) processItem(batch *models.DisbursementBatch, item *models.DisbursementItem) {
+	now := time.Now()
+
+	tx := h.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Lock the row and skip it unless it is still waiting; batches are resumed by every instance at
+	// startup and may be processed by more than one worker
+	var current models.DisbursementItem
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, item.ID).Error; err != nil {
+		tx.Rollback()
+		return
+	}
+	if current.Status != models.DISBURSEMENT_ITEM_VALID {
+		tx.Rollback()
+		return
+	}
+
+	// Re-check the account at execution time; it may have been closed since upload
+	var bankAccount models.BankAccount
+	if err := tx.Where("account_number = ? AND is_active = ?", item.AccountNumber, true).
+		First(&bankAccount).Error; err != nil {
+		tx.Rollback()
+		h.failItem(item, "Account number not found or inactive at execution time")
+		return
+	}
+
+	description := item.Description
+	if description == "" {
+		description = batch.Description
+	}
+	if description == "" {
+		description = "Bulk disbursement " + batch.BatchRef
+	}
+
+	// Every row is paid out of the funding account; a row it cannot cover fails
+	var funding models.SystemAccount
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("code = ?", models.SYSTEM_ACCOUNT_DISBURSEMENT_FUNDING).
+		First(&funding).Error; err != nil {
+		tx.Rollback()
+		h.failItem(item, "Disbursement funding account not available")
+		return
+	}
+	if funding.Balance < item.Amount {
+		tx.Rollback()
+		h.failItem(item, "Insufficient disbursement funding balance")
+		return
+	}
+
+	transaction, err := creditUserBalance(tx, bankAccount.UserID, item.Amount, "disbursement", description)
+	if err != nil {
+		tx.Rollback()
+		h.failItem(item, err.Error())
+		return
+	}
+
+	if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_DISBURSEMENT_FUNDING, -item.Amount, batch.BatchRef,
+		fmt.Sprintf("Bulk disbursement row %d", item.RowNumber), &transaction.ID); err != nil {
+		tx.Rollback()
+		h.failItem(item, "Failed to debit disbursement funding account")
+		return
+	}
+
+	result := tx.Model(item).Where("status = ?", models.DISBURSEMENT_ITEM_VALID).Updates(map[string]interface{}{
+		"status":         models.DISBURSEMENT_ITEM_SUCCESS,
+		"transaction_id": transaction.ID,
+		"processed_at":   &now,
+	})
+	if result.Error != nil {
+		tx.Rollback()
+		h.failItem(item, "Failed to update disbursement item")
+		return
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		h.failItem(item, "Failed to commit disbursement")
+	}
+}
+
+// failItem marks a disbursement row that is still waiting as failed with a reason
+func (h *DisbursementHandler) failItem(item *models.DisbursementItem, reason string) {
+	now := time.Now()
+	h.DB.Model(item).Where("status = ?", models.DISBURSEMENT_ITEM_VALID).Updates(map[string]interface{}{
+		"status":         models.DISBURSEMENT_ITEM_FAILED,
+		"failure_reason": reason,
+		"processed_at":   &now,
+	})
+}
+
+// finalizeBatch recalculates batch totals from its items and sets the final status
+func (h *DisbursementHandler) finalizeBatch(batchID uint) {
+	var summary struct {
+		SuccessCount  int
+		FailedCount   int
+		SuccessAmount int64
+	}
+
+	h.DB.Model(&models.DisbursementItem{}).
+		Select("COUNT(CASE WHEN status = ? THEN 1 END) AS success_count, "+
+			"COUNT(CASE WHEN status = ? THEN 1 END) AS failed_count, "+
+			"COALESCE(SUM(CASE WHEN status = ? THEN amount END), 0) AS success_amount",
+			models.DISBURSEMENT_ITEM_SUCCESS, models.DISBURSEMENT_ITEM_FAILED, models.DISBURSEMENT_ITEM_SUCCESS).
+		Where("batch_id = ?", batchID).
+		Scan(&summary)
+
+	status := models.DISBURSEMENT_STATUS_COMPLETED
+	if summary.FailedCount > 0 {
+		status = models.DISBURSEMENT_STATUS_COMPLETED_WITH_ERRORS
+	}
+
+	now := time.Now()
+	h.DB.Model(&models.DisbursementBatch{}).Where("id = ?", batchID).Updates(map[string]interface{}{
+		"status":         status,
+		"success_count":  summary.SuccessCount,
+		"failed_count":   summary.FailedCount,
+		"success_amount": summary.SuccessAmount,
+		"completed_at":   &now,
+	})
+}
+
+// validateDisbursementRows validates parsed CSV rows and resolves recipients
+func (h *DisbursementHandler) validateDisbursementRows(rows [][]string) ([]models.DisbursementItem, error) {
+	items := make([]models.DisbursementItem, 0, len(rows))
+	accountNumbers := []string{}
+	seen := map[string]int{}
+
+	for i, row := range rows {
+		item := models.DisbursementItem{
+			RowNumber: i + 1,
+			Status:    models.DISBURSEMENT_ITEM_VALID,
+		}
+
+		if len(row) < 2 {
+			item.Status = models.DISBURSEMENT_ITEM_INVALID
+			item.FailureReason = "Row must contain account_number and amount"
+			items = append(items, item)
+			continue
+		}
+
+		item.AccountNumber = strings.TrimSpace(row[0])
+		if len(row) > 2 {
+			item.Description = strings.TrimSpace(row[2])
+		}
+
+		amount, err := strconv.ParseInt(strings.TrimSpace(row[1]), 10, 64)
+		switch {
+		case item.AccountNumber == "":
+			item.Status = models.DISBURSEMENT_ITEM_INVALID
+			item.FailureReason = "Account number is empty"
+		case err != nil:
+			item.Status = models.DISBURSEMENT_ITEM_INVALID
+			item.FailureReason = "Amount must be a whole number"
+		case amount <= 0:
+			item.Status = models.DISBURSEMENT_ITEM_INVALID
+			item.FailureReason = "Amount must be greater than zero"
+		}
+		item.Amount = amount
+
+		if item.Status == models.DISBURSEMENT_ITEM_VALID {
+			if firstRow, duplicate := seen[item.AccountNumber]; duplicate {
+				item.Status = models.DISBURSEMENT_ITEM_INVALID
+				item.FailureReason = fmt.Sprintf("Duplicate account number (first seen on row %d)", firstRow)
+			} else {
+				seen[item.AccountNumber] = item.RowNumber
+				accountNumbers = append(accountNumbers, item.AccountNumber)
+			}
+		}
+
+		items = append(items, item)
+	}
+
+	if len(accountNumbers) == 0 {
+		return items, nil
+	}
+
+	// Resolve all recipients in a single query
+	var bankAccounts []models.BankAccount
+	if err := h.DB.Preload("User").Where("account_number IN ?", accountNumbers).Find(&bankAccounts).Error; err != nil {
+		return nil, err
+	}
+
+	accountsByNumber := map[string]models.BankAccount{}
+	for _, account := range bankAccounts {
+		accountsByNumber[account.AccountNumber] = account
+	}
+
+	for i := range items {
+		if items[i].Status != models.DISBURSEMENT_ITEM_VALID {
+			continue
+		}
+
+		account, found := accountsByNumber[items[i].AccountNumber]
+		switch {
+		case !found:
+			items[i].Status = models.DISBURSEMENT_ITEM_INVALID
+			items[i].FailureReason = "Account number not found"
+		case !account.IsActive:
+			items[i].Status = models.DISBURSEMENT_ITEM_INVALID
+			items[i].FailureReason = "Account is inactive"
+		case account.User.ID == 0 || account.User.Status != models.USER_STATUS_ACTIVE:
+			items[i].Status = models.DISBURSEMENT_ITEM_INVALID
+			items[i].FailureReason = "Account holder is not active"
+		default:
+			userID := account.UserID
+			items[i].UserID = &userID
+			items[i].AccountName = account.AccountName
+		}
+	}
+
+	return items, nil
+}
+
+// approvalExpiryHours returns the configured auto-expiry for bulk disbursement approvals
+func (h *DisbursementHandler) approvalExpiryHours() int {
+	var threshold models.ApprovalThreshold
+	if err := h.DB.Where("transaction_type = ? AND is_active = ?", "bulk_disbursement", true).
+		First(&threshold).Error; err == nil && threshold.AutoExpireHours > 0 {
+		return threshold.AutoExpireHours
+	}
+	return 24
+}
+
+// readDisbursementCSV reads all data rows from an uploaded CSV, skipping an optional header
+func readDisbursementCSV(r io.Reader) ([][]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("Invalid CSV file: %v", err)
+	}
+
+	rows := [][]string{}
+	for i, record := range records {
+		// Skip blank lines
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		// Skip header row
+		if i == 0 && len(record) > 0 && strings.Contains(strings.ToLower(record[0]), "account") {
+			continue
+		}
+		rows = append(rows, record)
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("CSV file contains no data rows")
+	}
+	if len(rows) > maxDisbursementRows {
+		return nil, fmt.Errorf("CSV file has too many rows (max %d)", maxDisbursementRows)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"testing"
+
+	"mbankingcore/models"
+
+	"gorm.io/gorm"
+)
+
+// createTestBankAccount opens an account for user; an inactive account is closed after creation
+func createTestBankAccount(t *testing.T, db *gorm.DB, user *models.User, accountNumber string, active bool) {
+	t.Helper()
+
+	account := models.BankAccount{UserID: user.ID, AccountNumber: accountNumber, AccountName: user.Name}
+	if err := db.Create(&account).Error; err != nil {
+		t.Fatalf("failed to create bank account %s: %v", accountNumber, err)
+	}
+	if !active {
+		if err := db.Model(&account).Update("is_active", false).Error; err != nil {
+			t.Fatalf("failed to close bank account %s: %v", accountNumber, err)
+		}
+	}
+}
+
+func TestProcessBatch(t *testing.T) {
+	db := newTestDB(t, &models.BankAccount{}, &models.DisbursementBatch{}, &models.DisbursementItem{})
+	h := NewDisbursementHandler(db)
+
+	alice := createTestUser(t, db, "alice", 0)
+	bob := createTestUser(t, db, "bob", 0)
+	carol := createTestUser(t, db, "carol", 0)
+	createTestBankAccount(t, db, alice, "1000000001", true)
+	createTestBankAccount(t, db, bob, "1000000002", false)
+	createTestBankAccount(t, db, carol, "1000000003", true)
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		return postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_DISBURSEMENT_FUNDING, 150000, "FUND-1", "Funding", nil)
+	}); err != nil {
+		t.Fatalf("failed to fund disbursements: %v", err)
+	}
+
+	batch := models.DisbursementBatch{BatchRef: "DSB-TEST", MakerAdminID: 1, Status: models.DISBURSEMENT_STATUS_PROCESSING}
+	if err := db.Create(&batch).Error; err != nil {
+		t.Fatalf("failed to create batch: %v", err)
+	}
+	items := []models.DisbursementItem{
+		{BatchID: batch.ID, RowNumber: 1, AccountNumber: "1000000001", Amount: 100000, Status: models.DISBURSEMENT_ITEM_VALID},
+		{BatchID: batch.ID, RowNumber: 2, AccountNumber: "1000000002", Amount: 10000, Status: models.DISBURSEMENT_ITEM_VALID},
+		{BatchID: batch.ID, RowNumber: 3, AccountNumber: "1000000003", Amount: 60000, Status: models.DISBURSEMENT_ITEM_VALID},
+		{BatchID: batch.ID, RowNumber: 4, AccountNumber: "1000000003", Amount: 50000, Status: models.DISBURSEMENT_ITEM_VALID},
+		{BatchID: batch.ID, RowNumber: 5, AccountNumber: "1000000003", Amount: 70000, Status: models.DISBURSEMENT_ITEM_INVALID},
+	}
+	if err := db.Create(&items).Error; err != nil {
+		t.Fatalf("failed to create items: %v", err)
+	}
+
+	h.ProcessBatch(batch.ID)
+
+	want := []struct {
+		status string
+		reason string
+	}{
+		{models.DISBURSEMENT_ITEM_SUCCESS, ""},
+		{models.DISBURSEMENT_ITEM_FAILED, "Account number not found or inactive at execution time"},
+		{models.DISBURSEMENT_ITEM_FAILED, "Insufficient disbursement funding balance"},
+		{models.DISBURSEMENT_ITEM_SUCCESS, ""},
+		{models.DISBURSEMENT_ITEM_INVALID, ""},
+	}
+	for i, w := range want {
+		var item models.DisbursementItem
+		if err := db.First(&item, items[i].ID).Error; err != nil {
+			t.Fatalf("failed to get item %d: %v", i+1, err)
+		}
+		if item.Status != w.status || item.FailureReason != w.reason {
+			t.Errorf("row %d: status %s reason %q, want %s %q", i+1, item.Status, item.FailureReason, w.status, w.reason)
+		}
+		if (item.Status == models.DISBURSEMENT_ITEM_SUCCESS) != (item.TransactionID != nil) {
+			t.Errorf("row %d: transaction_id %v with status %s", i+1, item.TransactionID, item.Status)
+		}
+	}
+
+	if got := userBalance(t, db, alice.ID); got != 100000 {
+		t.Errorf("alice balance = %d, want 100000", got)
+	}
+	if got := userBalance(t, db, bob.ID); got != 0 {
+		t.Errorf("bob balance = %d, want 0", got)
+	}
+	if got := userBalance(t, db, carol.ID); got != 50000 {
+		t.Errorf("carol balance = %d, want 50000", got)
+	}
+	if got := systemAccountBalance(t, db, models.SYSTEM_ACCOUNT_DISBURSEMENT_FUNDING); got != 0 {
+		t.Errorf("disbursement funding = %d, want 0", got)
+	}
+
+	var entries int64
+	db.Model(&models.SystemAccountEntry{}).Where("reference = ?", batch.BatchRef).Count(&entries)
+	if entries != 2 {
+		t.Errorf("got %d funding entries for the batch, want 2", entries)
+	}
+
+	if err := db.First(&batch, batch.ID).Error; err != nil {
+		t.Fatalf("failed to get batch: %v", err)
+	}
+	if batch.Status != models.DISBURSEMENT_STATUS_COMPLETED_WITH_ERRORS || batch.SuccessCount != 2 ||
+		batch.FailedCount != 2 || batch.SuccessAmount != 150000 || batch.CompletedAt == nil {
+		t.Errorf("batch: status %s success %d failed %d amount %d, want completed_with_errors 2 2 150000",
+			batch.Status, batch.SuccessCount, batch.FailedCount, batch.SuccessAmount)
+	}
+}
+
+func TestProcessItemSkipsProcessedRows(t *testing.T) {
+	db := newTestDB(t, &models.BankAccount{}, &models.DisbursementBatch{}, &models.DisbursementItem{})
+	h := NewDisbursementHandler(db)
+
+	alice := createTestUser(t, db, "alice", 0)
+	createTestBankAccount(t, db, alice, "1000000001", true)
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		return postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_DISBURSEMENT_FUNDING, 500000, "FUND-1", "Funding", nil)
+	}); err != nil {
+		t.Fatalf("failed to fund disbursements: %v", err)
+	}
+
+	batch := models.DisbursementBatch{BatchRef: "DSB-TEST", MakerAdminID: 1, Status: models.DISBURSEMENT_STATUS_PROCESSING}
+	if err := db.Create(&batch).Error; err != nil {
+		t.Fatalf("failed to create batch: %v", err)
+	}
+	item := models.DisbursementItem{BatchID: batch.ID, RowNumber: 1, AccountNumber: "1000000001", Amount: 100000, Status: models.DISBURSEMENT_ITEM_VALID}
+	if err := db.Create(&item).Error; err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+
+	// A second worker holding a stale copy of the row must not pay it again
+	stale := item
+	h.processItem(&batch, &item)
+	h.processItem(&batch, &stale)
+
+	if got := userBalance(t, db, alice.ID); got != 100000 {
+		t.Errorf("alice balance = %d, want 100000", got)
+	}
+	if got := systemAccountBalance(t, db, models.SYSTEM_ACCOUNT_DISBURSEMENT_FUNDING); got != 400000 {
+		t.Errorf("disbursement funding = %d, want 400000", got)
+	}
+}
@@ -0,0 +1,987 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"mbankingcore/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Dispute filing window and SLA defaults, overridable through config
+const (
+	defaultDisputeFilingWindowDays  = 60
+	defaultDisputeResponseSLAHours  = 24
+	defaultDisputeResolutionSLADays = 14
+)
+
+// nonDisputableTransactionTypes are corrections that cannot themselves be disputed
+var nonDisputableTransactionTypes = map[string]bool{
+	"reversal":                     true,
+	"adjustment_credit":            true,
+	"adjustment_debit":             true,
+	"dispute_provisional_credit":   true,
+	"dispute_provisional_reversal": true,
+	"dispute_adjustment":           true,
+}
+
+// settledElsewhereTransactionTypes have been passed on to a merchant, the card network or a biller.
+// A dispute could only be resolved by a ledger reversal that never recovers those funds, so these
+// are refused when filed, with where the customer can raise them instead.
+var settledElsewhereTransactionTypes = map[string]string{
+	models.TRANSACTION_TYPE_QR_PAYMENT:         "QR payments are paid out to the merchant and cannot be disputed here. Please ask the merchant for a refund",
+	models.TRANSACTION_TYPE_CARD_AUTHORIZATION: "Card payments are disputed as a chargeback with the card network. Please contact customer support",
+	models.TRANSACTION_TYPE_BILL_PAYMENT:       "Bill payments are passed on to the biller and cannot be disputed here. Please contact the biller",
+}
+
+// errProvisionalClawback is returned when the customer cannot repay a provisional credit
+var errProvisionalClawback = errors.New("insufficient balance to take back provisional credit")
+
+type DisputeHandler struct {
+	DB *gorm.DB
+}
+
+func NewDisputeHandler(db *gorm.DB) *DisputeHandler {
+	return &DisputeHandler{DB: db}
+}
+
+// CreateDispute - File a dispute against one of the user's transactions
+func (h *DisputeHandler) CreateDispute(c *gin.Context) {
+	userIDValue, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+	userID := userIDValue.(uint)
+
+	var req models.CreateDisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var txn models.Transaction
+	if err := h.DB.Where("id = ? AND user_id = ?", req.TransactionID, userID).First(&txn).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Transaction not found",
+		})
+		return
+	}
+
+	if message := h.checkDisputable(&txn, req.Amount); message != "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: message,
+		})
+		return
+	}
+
+	var open int64
+	h.DB.Model(&models.Dispute{}).
+		Where("transaction_id = ? AND status NOT IN ?", txn.ID, []string{models.DISPUTE_STATUS_RESOLVED, models.DISPUTE_STATUS_REJECTED}).
+		Count(&open)
+	if open > 0 {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "This transaction already has an open dispute",
+		})
+		return
+	}
+
+	caseNumber, err := generateDisputeCaseNumber()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to generate case number",
+		})
+		return
+	}
+
+	amount := req.Amount
+	if amount == 0 {
+		amount = txn.Amount
+	}
+
+	now := time.Now()
+	dispute := models.Dispute{
+		CaseNumber:      caseNumber,
+		UserID:          userID,
+		TransactionID:   txn.ID,
+		Reason:          req.Reason,
+		Description:     req.Description,
+		DisputedAmount:  amount,
+		Status:          models.DISPUTE_STATUS_OPENED,
+		ResponseDueAt:   now.Add(time.Duration(getConfigInt64(h.DB, "dispute_response_sla_hours", defaultDisputeResponseSLAHours)) * time.Hour),
+		ResolutionDueAt: now.AddDate(0, 0, int(getConfigInt64(h.DB, "dispute_resolution_sla_days", defaultDisputeResolutionSLADays))),
+	}
+
+	tx := h.DB.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Create(&dispute).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to file dispute",
+		})
+		return
+	}
+	if err := addDisputeAttachments(tx, dispute.ID, req.Attachments, &userID, nil); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to save attachments",
+		})
+		return
+	}
+	if err := recordDisputeStatus(tx, &dispute, "", nil, "Dispute filed by customer"); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to record dispute status",
+		})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to file dispute",
+		})
+		return
+	}
+
+	h.DB.Preload("Attachments").First(&dispute, dispute.ID)
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Code:    http.StatusCreated,
+		Message: "Dispute filed successfully",
+		Data:    dispute,
+	})
+}
+
+// checkDisputable returns a message when the transaction cannot be disputed for amount
+func (h *DisputeHandler) checkDisputable(txn *models.Transaction, amount int64) string {
+	if nonDisputableTransactionTypes[txn.Type] {
+		return "This transaction type cannot be disputed"
+	}
+	if reason, ok := settledElsewhereTransactionTypes[txn.Type]; ok {
+		return reason
+	}
+	if txn.Status != models.TRANSACTION_STATUS_COMPLETED {
+		return "Only completed transactions can be disputed"
+	}
+	if txn.IsReversed {
+		return "Transaction has already been reversed"
+	}
+	if amount > txn.Amount {
+		return "Disputed amount cannot exceed the transaction amount"
+	}
+
+	windowDays := getConfigInt64(h.DB, "dispute_filing_window_days", defaultDisputeFilingWindowDays)
+	if time.Since(txn.CreatedAt) > time.Duration(windowDays)*24*time.Hour {
+		return fmt.Sprintf("Transactions can only be disputed within %d days", windowDays)
+	}
+	return ""
+}
+
+// GetUserDisputes - List the authenticated user's disputes
+func (h *DisputeHandler) GetUserDisputes(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.Dispute{}).Where("user_id = ?", userID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var disputes []models.Dispute
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&disputes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch disputes",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Disputes retrieved successfully",
+		Data: gin.H{
+			"disputes": disputes,
+			"pagination": gin.H{
+				"current_page": page,
+				"per_page":     limit,
+				"total":        total,
+				"total_pages":  (total + int64(limit) - 1) / int64(limit),
+			},
+		},
+	})
+}
+
+// GetUserDispute - Get a dispute with its evidence, notes and history (internal notes hidden)
+func (h *DisputeHandler) GetUserDispute(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	var dispute models.Dispute
+	if err := h.DB.Preload("Transaction").Preload("Attachments").
+		Preload("Notes", "is_internal = ?", false).
+		Preload("StatusHistory", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&dispute).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Dispute not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Dispute retrieved successfully",
+		Data:    dispute,
+	})
+}
+
+// AddUserNote - Add a customer comment to an open dispute
+func (h *DisputeHandler) AddUserNote(c *gin.Context) {
+	userIDValue, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+	userID := userIDValue.(uint)
+
+	dispute, ok := h.findOpenUserDispute(c, userID)
+	if !ok {
+		return
+	}
+
+	var req models.DisputeNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	note := models.DisputeNote{DisputeID: dispute.ID, UserID: &userID, Note: req.Note}
+	if err := h.DB.Create(&note).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to add note",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Code:    http.StatusCreated,
+		Message: "Note added successfully",
+		Data:    note,
+	})
+}
+
+// AddUserAttachments - Add evidence to an open dispute
+func (h *DisputeHandler) AddUserAttachments(c *gin.Context) {
+	userIDValue, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+	userID := userIDValue.(uint)
+
+	dispute, ok := h.findOpenUserDispute(c, userID)
+	if !ok {
+		return
+	}
+
+	var req models.AddDisputeAttachmentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := addDisputeAttachments(h.DB, dispute.ID, req.Attachments, &userID, nil); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to save attachments",
+		})
+		return
+	}
+
+	var attachments []models.DisputeAttachment
+	h.DB.Where("dispute_id = ?", dispute.ID).Order("created_at ASC").Find(&attachments)
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Code:    http.StatusCreated,
+		Message: "Attachments added successfully",
+		Data:    attachments,
+	})
+}
+
+// findOpenUserDispute loads the user's dispute in the :id path parameter, writing the error
+// response when it is missing or already closed
+func (h *DisputeHandler) findOpenUserDispute(c *gin.Context, userID uint) (*models.Dispute, bool) {
+	var dispute models.Dispute
+	if err := h.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&dispute).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Dispute not found",
+		})
+		return nil, false
+	}
+	if dispute.IsClosed() {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Dispute is already %s", dispute.Status),
+		})
+		return nil, false
+	}
+	return &dispute, true
+}
+
+// GetAllDisputes - List disputes (admin)
+func (h *DisputeHandler) GetAllDisputes(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.Dispute{})
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if assignedAdminID := c.Query("assigned_admin_id"); assignedAdminID != "" {
+		query = query.Where("assigned_admin_id = ?", assignedAdminID)
+	}
+	if c.Query("unassigned") == "true" {
+		query = query.Where("assigned_admin_id IS NULL")
+	}
+	if c.Query("overdue") == "true" {
+		now := time.Now()
+		query = query.Where("status NOT IN ?", []string{models.DISPUTE_STATUS_RESOLVED, models.DISPUTE_STATUS_REJECTED}).
+			Where("resolution_due_at < ? OR (status = ? AND response_due_at < ?)", now, models.DISPUTE_STATUS_OPENED, now)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var disputes []models.Dispute
+	if err := query.Order("resolution_due_at ASC").Limit(limit).Offset(offset).Find(&disputes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch disputes",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Disputes retrieved successfully",
+		Data: gin.H{
+			"disputes": disputes,
+			"pagination": gin.H{
+				"page":        page,
+				"limit":       limit,
+				"total":       total,
+				"total_pages": int(math.Ceil(float64(total) / float64(limit))),
+			},
+		},
+	})
+}
+
+// GetDisputeByID - Get a dispute with everything attached to it (admin)
+func (h *DisputeHandler) GetDisputeByID(c *gin.Context) {
+	var dispute models.Dispute
+	if err := h.DB.Preload("Transaction").Preload("Attachments").
+		Preload("Notes", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Preload("StatusHistory", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		First(&dispute, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Dispute not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Dispute retrieved successfully",
+		Data: gin.H{
+			"dispute":      dispute,
+			"sla_breached": dispute.SLABreached(time.Now()),
+		},
+	})
+}
+
+// AssignDispute - Assign a dispute to an admin and start the investigation (admin)
+func (h *DisputeHandler) AssignDispute(c *gin.Context) {
+	adminIDValue, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+	adminID := adminIDValue.(uint)
+
+	var req models.AssignDisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var assignee models.Admin
+	if err := h.DB.Where("id = ? AND status = ?", req.AdminID, models.ADMIN_STATUS_ACTIVE).First(&assignee).Error; err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Assignee not found or inactive",
+		})
+		return
+	}
+
+	tx := h.DB.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var dispute models.Dispute
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&dispute, c.Param("id")).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Dispute not found",
+		})
+		return
+	}
+	if dispute.IsClosed() {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Dispute is already %s", dispute.Status),
+		})
+		return
+	}
+
+	previousAssignee := dispute.AssignedAdminID
+	now := time.Now()
+	dispute.AssignedAdminID = &assignee.ID
+	dispute.AssignedAt = &now
+
+	note := fmt.Sprintf("Assigned to %s", assignee.Name)
+	if req.Note != "" {
+		note += ": " + req.Note
+	}
+	fromStatus := dispute.Status
+	if dispute.Status == models.DISPUTE_STATUS_OPENED {
+		dispute.Status = models.DISPUTE_STATUS_INVESTIGATING
+	}
+	if err := recordDisputeStatus(tx, &dispute, fromStatus, &adminID, note); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to record dispute status",
+		})
+		return
+	}
+	if err := tx.Save(&dispute).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to assign dispute",
+		})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to assign dispute",
+		})
+		return
+	}
+
+	createAuditLog(h.DB, c, "dispute", dispute.ID, adminID, "ASSIGN", map[string]interface{}{
+		"case_number":       dispute.CaseNumber,
+		"previous_assignee": previousAssignee,
+		"assigned_admin_id": assignee.ID,
+		"status":            dispute.Status,
+	})
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Dispute assigned successfully",
+		Data:    dispute,
+	})
+}
+
+// AddAdminNote - Add an admin note, optionally internal, to a dispute (admin)
+func (h *DisputeHandler) AddAdminNote(c *gin.Context) {
+	adminIDValue, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+	adminID := adminIDValue.(uint)
+
+	var req models.DisputeNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var dispute models.Dispute
+	if err := h.DB.First(&dispute, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Dispute not found",
+		})
+		return
+	}
+
+	note := models.DisputeNote{DisputeID: dispute.ID, AdminID: &adminID, Note: req.Note, IsInternal: req.IsInternal}
+	if err := h.DB.Create(&note).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to add note",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Code:    http.StatusCreated,
+		Message: "Note added successfully",
+		Data:    note,
+	})
+}
+
+// GrantProvisionalCredit - Credit the customer while the investigation continues (admin)
+func (h *DisputeHandler) GrantProvisionalCredit(c *gin.Context) {
+	adminIDValue, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+	adminID := adminIDValue.(uint)
+
+	var req models.ProvisionalCreditRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	tx := h.DB.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var dispute models.Dispute
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&dispute, c.Param("id")).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Dispute not found",
+		})
+		return
+	}
+	if !dispute.CanTransitionTo(models.DISPUTE_STATUS_PROVISIONAL_CREDIT) {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Provisional credit cannot be granted while the dispute is %s", dispute.Status),
+		})
+		return
+	}
+
+	amount := req.Amount
+	if amount == 0 {
+		amount = dispute.DisputedAmount
+	}
+	if amount > dispute.DisputedAmount {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Provisional credit cannot exceed the disputed amount",
+		})
+		return
+	}
+
+	creditTxn, err := creditUserBalance(tx, dispute.UserID, amount, "dispute_provisional_credit",
+		"Provisional credit for dispute "+dispute.CaseNumber)
+	if err != nil {
+		tx.Rollback()
+		respondLedgerError(c, err, "Failed to grant provisional credit")
+		return
+	}
+	if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_DISPUTE_SUSPENSE, -amount, dispute.CaseNumber,
+		"Provisional credit "+dispute.CaseNumber, &creditTxn.ID); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to grant provisional credit",
+		})
+		return
+	}
+
+	fromStatus := dispute.Status
+	dispute.Status = models.DISPUTE_STATUS_PROVISIONAL_CREDIT
+	dispute.ProvisionalCreditAmount = amount
+	dispute.ProvisionalCreditTxnID = &creditTxn.ID
+	if err := recordDisputeStatus(tx, &dispute, fromStatus, &adminID, req.Note); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to record dispute status",
+		})
+		return
+	}
+	if err := tx.Save(&dispute).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update dispute",
+		})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to grant provisional credit",
+		})
+		return
+	}
+
+	createAuditLog(h.DB, c, "dispute", dispute.ID, adminID, "PROVISIONAL_CREDIT", map[string]interface{}{
+		"case_number":    dispute.CaseNumber,
+		"amount":         amount,
+		"transaction_id": creditTxn.ID,
+		"note":           req.Note,
+	})
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Provisional credit granted successfully",
+		Data:    dispute,
+	})
+}
+
+// ResolveDispute - Close a dispute by reversing the transaction, crediting an adjustment or
+// rejecting it. Any provisional credit is settled against the outcome. (admin)
+func (h *DisputeHandler) ResolveDispute(c *gin.Context) {
+	adminIDValue, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+	adminID := adminIDValue.(uint)
+
+	var req models.ResolveDisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	tx := h.DB.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var dispute models.Dispute
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&dispute, c.Param("id")).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Dispute not found",
+		})
+		return
+	}
+
+	targetStatus := models.DISPUTE_STATUS_RESOLVED
+	if req.Action == models.DISPUTE_RESOLUTION_REJECT {
+		targetStatus = models.DISPUTE_STATUS_REJECTED
+	}
+	if !dispute.CanTransitionTo(targetStatus) {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Dispute cannot be %s while it is %s", targetStatus, dispute.Status),
+		})
+		return
+	}
+	if req.Action == models.DISPUTE_RESOLUTION_ADJUST && req.Amount > dispute.DisputedAmount {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Adjustment cannot exceed the disputed amount",
+		})
+		return
+	}
+
+	var err error
+	switch req.Action {
+	case models.DISPUTE_RESOLUTION_REVERSE:
+		err = h.resolveByReversal(tx, &dispute)
+	case models.DISPUTE_RESOLUTION_ADJUST:
+		err = h.resolveByAdjustment(tx, &dispute, req.Amount)
+	default:
+		err = clawBackProvisionalCredit(tx, &dispute, dispute.ProvisionalCreditAmount)
+	}
+	if err != nil {
+		tx.Rollback()
+		switch {
+		case errors.Is(err, errProvisionalClawback):
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Customer balance is insufficient to take back the provisional credit",
+			})
+		case errors.Is(err, errUserNotActive):
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Customer account is not active",
+			})
+		case req.Action == models.DISPUTE_RESOLUTION_REVERSE:
+			respondReversalError(c, err)
+		default:
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to resolve dispute",
+			})
+		}
+		return
+	}
+
+	now := time.Now()
+	fromStatus := dispute.Status
+	dispute.Status = targetStatus
+	dispute.Resolution = req.Action
+	dispute.ResolutionNotes = req.Notes
+	dispute.ResolvedByAdminID = &adminID
+	dispute.ResolvedAt = &now
+	dispute.ProvisionalCreditAmount = 0
+	if err := recordDisputeStatus(tx, &dispute, fromStatus, &adminID, req.Notes); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to record dispute status",
+		})
+		return
+	}
+	if err := tx.Save(&dispute).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update dispute",
+		})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to resolve dispute",
+		})
+		return
+	}
+
+	createAuditLog(h.DB, c, "dispute", dispute.ID, adminID, "RESOLVE_"+strings.ToUpper(req.Action), map[string]interface{}{
+		"case_number":       dispute.CaseNumber,
+		"transaction_id":    dispute.TransactionID,
+		"resolution":        req.Action,
+		"resolution_amount": dispute.ResolutionAmount,
+		"resolution_txn_id": dispute.ResolutionTxnID,
+		"notes":             req.Notes,
+		"sla_breached":      dispute.SLABreached(now),
+	})
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: fmt.Sprintf("Dispute %s successfully", targetStatus),
+		Data:    dispute,
+	})
+}
+
+// resolveByReversal reverses the disputed amount of the transaction through the shared reversal path, then
+// takes back any provisional credit so the customer is refunded once
+func (h *DisputeHandler) resolveByReversal(tx *gorm.DB, dispute *models.Dispute) error {
+	result, err := reverseTransaction(tx, dispute.TransactionID, dispute.DisputedAmount, "Dispute "+dispute.CaseNumber,
+		models.REVERSAL_POLICY_REJECT)
+	if err != nil {
+		return err
+	}
+	if err := clawBackProvisionalCredit(tx, dispute, dispute.ProvisionalCreditAmount); err != nil {
+		return err
+	}
+
+	dispute.ResolutionAmount = result.ReversalTxn.Amount
+	dispute.ResolutionTxnID = &result.ReversalTxn.ID
+	return nil
+}
+
+// resolveByAdjustment credits the customer amount at the bank's expense. A provisional credit
+// counts towards the adjustment: it is kept up to amount and any excess is taken back.
+func (h *DisputeHandler) resolveByAdjustment(tx *gorm.DB, dispute *models.Dispute, amount int64) error {
+	kept := min(dispute.ProvisionalCreditAmount, amount)
+	if kept > 0 {
+		if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_DISPUTE_SUSPENSE, kept, dispute.CaseNumber,
+			"Provisional credit finalized "+dispute.CaseNumber, dispute.ProvisionalCreditTxnID); err != nil {
+			return err
+		}
+		if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_DISPUTE_EXPENSE, -kept, dispute.CaseNumber,
+			"Dispute adjustment "+dispute.CaseNumber, dispute.ProvisionalCreditTxnID); err != nil {
+			return err
+		}
+		dispute.ResolutionTxnID = dispute.ProvisionalCreditTxnID
+	}
+
+	if remaining := amount - kept; remaining > 0 {
+		creditTxn, err := creditUserBalance(tx, dispute.UserID, remaining, "dispute_adjustment",
+			"Dispute adjustment "+dispute.CaseNumber)
+		if err != nil {
+			return err
+		}
+		if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_DISPUTE_EXPENSE, -remaining, dispute.CaseNumber,
+			"Dispute adjustment "+dispute.CaseNumber, &creditTxn.ID); err != nil {
+			return err
+		}
+		dispute.ResolutionTxnID = &creditTxn.ID
+	}
+
+	if excess := dispute.ProvisionalCreditAmount - kept; excess > 0 {
+		if err := clawBackProvisionalCredit(tx, dispute, excess); err != nil {
+			return err
+		}
+	}
+
+	dispute.ResolutionAmount = amount
+	return nil
+}
+
+// clawBackProvisionalCredit debits amount of a provisional credit back from the customer
+func clawBackProvisionalCredit(tx *gorm.DB, dispute *models.Dispute, amount int64) error {
+	if amount <= 0 {
+		return nil
+	}
+
+	debitTxn, err := debitUserBalance(tx, dispute.UserID, amount, "dispute_provisional_reversal",
+		"Provisional credit reversed for dispute "+dispute.CaseNumber)
+	if errors.Is(err, errInsufficientBalance) {
+		return errProvisionalClawback
+	}
+	if err != nil {
+		return err
+	}
+	return postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_DISPUTE_SUSPENSE, amount, dispute.CaseNumber,
+		"Provisional credit reversed "+dispute.CaseNumber, &debitTxn.ID)
+}
+
+// recordDisputeStatus writes a status history row for a dispute moving from fromStatus to its current status
+func recordDisputeStatus(tx *gorm.DB, dispute *models.Dispute, fromStatus string, adminID *uint, note string) error {
+	history := models.DisputeStatusHistory{
+		DisputeID:  dispute.ID,
+		FromStatus: fromStatus,
+		ToStatus:   dispute.Status,
+		AdminID:    adminID,
+		Note:       note,
+	}
+	return tx.Create(&history).Error
+}
+
+// addDisputeAttachments stores evidence files uploaded by a customer or an admin
+func addDisputeAttachments(db *gorm.DB, disputeID uint, attachments []models.DisputeAttachmentRequest, userID, adminID *uint) error {
+	for _, attachment := range attachments {
+		record := models.DisputeAttachment{
+			DisputeID:         disputeID,
+			URL:               attachment.URL,
+			FileName:          attachment.FileName,
+			UploadedByUserID:  userID,
+			UploadedByAdminID: adminID,
+		}
+		if err := db.Create(&record).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generateDisputeCaseNumber returns a case number such as DSP2410123456789012
+func generateDisputeCaseNumber() (string, error) {
+	digits, err := generateDigits(12)
+	if err != nil {
+		return "", err
+	}
+	return "DSP" + time.Now().Format("0601") + digits, nil
+}
@@ -0,0 +1,830 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"mbankingcore/models"
+	"mbankingcore/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Default fraud decision validity, overridable through config
+const (
+	defaultFraudChallengeValidityMinutes = 10
+	defaultFraudApprovalValidityHours    = 24
+	defaultFraudChallengeMaxAttempts     = 3
+)
+
+// errFraudDecisionUsed is returned when the decision was used by a concurrent resubmission
+var errFraudDecisionUsed = errors.New("fraud decision has already been used")
+
+// defaultFraudTimezone is used by night-time rules without a timezone
+const defaultFraudTimezone = "Asia/Jakarta"
+
+// fraudHistoryTypes maps an evaluated transaction type to the ledger transaction types it produces
+var fraudHistoryTypes = map[string][]string{
+	models.FRAUD_CHECK_TYPE_TRANSFER: {models.TRANSACTION_TYPE_TRANSFER_OUT, models.TRANSACTION_TYPE_INTERBANK_TRANSFER_OUT},
+	models.FRAUD_CHECK_TYPE_WITHDRAW: {models.TRANSACTION_TYPE_WITHDRAW},
+}
+
+type FraudHandler struct {
+	DB *gorm.DB
+}
+
+func NewFraudHandler(db *gorm.DB) *FraudHandler {
+	return &FraudHandler{DB: db}
+}
+
+// fraudCheck describes a transaction attempt evaluated by the fraud rules
+type fraudCheck struct {
+	UserID             uint
+	TransactionType    string
+	Amount             int64
+	Currency           string
+	BeneficiaryUserID  *uint
+	BeneficiaryAccount string
+	DeviceID           string
+	IPAddress          string
+}
+
+// screenTransaction evaluates the fraud rules for a transaction attempt, or checks the earlier
+// decision the customer resubmitted with. It writes the response and returns nil when the
+// transaction must not proceed; otherwise the returned decision is linked to the executed
+// transaction with completeFraudDecision.
+func screenTransaction(c *gin.Context, db *gorm.DB, check fraudCheck, decisionID *uint, pin string) *models.FraudDecision {
+	if decisionID != nil {
+		return resumeFraudDecision(c, db, check, *decisionID, pin)
+	}
+
+	decision, err := evaluateFraudRules(db, check, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to evaluate transaction risk",
+		})
+		return nil
+	}
+
+	switch decision.Decision {
+	case models.FRAUD_DECISION_ALLOW:
+		return decision
+	case models.FRAUD_DECISION_CHALLENGE:
+		c.JSON(http.StatusPreconditionRequired, models.APIResponse{
+			Code:    http.StatusPreconditionRequired,
+			Message: "Additional verification required, resubmit with fraud_decision_id and your PIN",
+			Data:    fraudDecisionSummary(decision),
+		})
+	case models.FRAUD_DECISION_HOLD:
+		c.JSON(http.StatusAccepted, models.APIResponse{
+			Code:    http.StatusAccepted,
+			Message: "Transaction is held for review",
+			Data:    fraudDecisionSummary(decision),
+		})
+	default:
+		c.JSON(http.StatusForbidden, models.APIResponse{
+			Code:    http.StatusForbidden,
+			Message: "Transaction blocked",
+			Data:    fraudDecisionSummary(decision),
+		})
+	}
+	return nil
+}
+
+// fraudDecisionSummary is the part of a decision shown to the customer; rule details stay internal
+func fraudDecisionSummary(decision *models.FraudDecision) gin.H {
+	return gin.H{
+		"fraud_decision_id": decision.ID,
+		"decision":          decision.Decision,
+		"review_status":     decision.ReviewStatus,
+		"expires_at":        decision.ExpiresAt,
+	}
+}
+
+// resumeFraudDecision lets a transaction through on a decision that was challenged and is now
+// answered with the PIN, or held and since approved by an admin. A challenge stays pending until
+// the transaction commits, so a resubmission that fails for another reason can be retried; wrong
+// PINs are counted and fail the challenge after fraud_challenge_max_attempts.
+func resumeFraudDecision(c *gin.Context, db *gorm.DB, check fraudCheck, decisionID uint, pin string) *models.FraudDecision {
+	var decision models.FraudDecision
+	if err := db.Where("id = ? AND user_id = ?", decisionID, check.UserID).First(&decision).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Fraud decision not found",
+		})
+		return nil
+	}
+
+	if decision.TransactionType != check.TransactionType || decision.Amount != check.Amount ||
+		decision.Currency != check.Currency || decision.BeneficiaryAccount != check.BeneficiaryAccount {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Transaction does not match the fraud decision",
+		})
+		return nil
+	}
+	if decision.TransactionID != nil || decision.ReviewStatus == models.FRAUD_REVIEW_STATUS_COMPLETED {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "Fraud decision has already been used",
+		})
+		return nil
+	}
+	if decision.ExpiresAt != nil && time.Now().After(*decision.ExpiresAt) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Fraud decision has expired, please submit the transaction again",
+		})
+		return nil
+	}
+
+	switch {
+	case decision.Decision == models.FRAUD_DECISION_CHALLENGE && decision.ReviewStatus == models.FRAUD_REVIEW_STATUS_PENDING:
+		var user models.User
+		if err := db.First(&user, check.UserID).Error; err == nil && pin != "" && utils.CheckPassword(user.PinAtm, pin) == nil {
+			return &decision
+		}
+
+		maxAttempts := int(getConfigInt64(db, "fraud_challenge_max_attempts", defaultFraudChallengeMaxAttempts))
+		failed, err := recordFraudChallengeFailure(db, &decision, maxAttempts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to update fraud decision",
+			})
+			return nil
+		}
+		if failed {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Code:    http.StatusForbidden,
+				Message: "Too many invalid PIN attempts, please submit the transaction again",
+			})
+			return nil
+		}
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Code:    http.StatusForbidden,
+			Message: "Invalid PIN",
+		})
+		return nil
+	case decision.ReviewStatus == models.FRAUD_REVIEW_STATUS_APPROVED:
+		return &decision
+	case decision.ReviewStatus == models.FRAUD_REVIEW_STATUS_REJECTED:
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Code:    http.StatusForbidden,
+			Message: "Transaction was rejected by the fraud review",
+		})
+	case decision.ReviewStatus == models.FRAUD_REVIEW_STATUS_FAILED:
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Code:    http.StatusForbidden,
+			Message: "Too many invalid PIN attempts, please submit the transaction again",
+		})
+	default:
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Code:    http.StatusForbidden,
+			Message: "Transaction is still waiting for the fraud review",
+		})
+	}
+	return nil
+}
+
+// respondFraudDecisionError writes the response for a completeFraudDecision failure
+func respondFraudDecisionError(c *gin.Context, err error) {
+	if errors.Is(err, errFraudDecisionUsed) {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "Fraud decision has already been used",
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+		Code:    http.StatusInternalServerError,
+		Message: "Failed to record fraud decision",
+	})
+}
+
+// recordFraudChallengeFailure counts a wrong PIN given for a challenge and fails the challenge once
+// maxAttempts are used up; returns whether the challenge has failed
+func recordFraudChallengeFailure(db *gorm.DB, decision *models.FraudDecision, maxAttempts int) (bool, error) {
+	var failed bool
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var current models.FraudDecision
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, decision.ID).Error; err != nil {
+			return err
+		}
+		if current.ReviewStatus != models.FRAUD_REVIEW_STATUS_PENDING {
+			failed = current.ReviewStatus == models.FRAUD_REVIEW_STATUS_FAILED
+			return nil
+		}
+
+		updates := map[string]interface{}{"challenge_attempts": current.ChallengeAttempts + 1}
+		if current.ChallengeAttempts+1 >= maxAttempts {
+			updates["review_status"] = models.FRAUD_REVIEW_STATUS_FAILED
+			failed = true
+		}
+		return tx.Model(&current).Updates(updates).Error
+	})
+	return failed, err
+}
+
+// completeFraudDecision links the decision a transaction was executed under to the transaction.
+// It must be called inside the database transaction that records it: the decision row is locked and
+// checked again, so concurrent resubmissions of one decision cannot each execute a transaction, and
+// errFraudDecisionUsed is returned to all but the first.
+func completeFraudDecision(tx *gorm.DB, decision *models.FraudDecision, transactionID uint) error {
+	var current models.FraudDecision
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, decision.ID).Error; err != nil {
+		return fmt.Errorf("failed to get fraud decision: %v", err)
+	}
+	if current.TransactionID != nil || current.ReviewStatus != decision.ReviewStatus {
+		return errFraudDecisionUsed
+	}
+
+	updates := map[string]interface{}{"transaction_id": transactionID}
+	if decision.Decision != models.FRAUD_DECISION_ALLOW {
+		updates["review_status"] = models.FRAUD_REVIEW_STATUS_COMPLETED
+	}
+	result := tx.Model(&current).Where("transaction_id IS NULL").Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update fraud decision: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errFraudDecisionUsed
+	}
+	return nil
+}
+
+// evaluateFraudRules runs every active rule for the transaction type and stores the decision with
+// its rule hits. The most severe action among the hits wins.
+func evaluateFraudRules(db *gorm.DB, check fraudCheck, now time.Time) (*models.FraudDecision, error) {
+	var rules []models.FraudRule
+	if err := db.Where("is_active = ?", true).Order("id").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to get fraud rules: %v", err)
+	}
+
+	baseAmount := check.Amount
+	if check.Currency != "" && check.Currency != models.BASE_CURRENCY {
+		conversion, err := getFXConversion(db, check.Currency, models.BASE_CURRENCY)
+		if err != nil {
+			return nil, err
+		}
+		if baseAmount, err = conversion.Convert(check.Amount); err != nil {
+			return nil, err
+		}
+	}
+
+	decision := models.FraudDecision{
+		UserID:             check.UserID,
+		TransactionType:    check.TransactionType,
+		Amount:             check.Amount,
+		Currency:           check.Currency,
+		BaseAmount:         baseAmount,
+		BeneficiaryUserID:  check.BeneficiaryUserID,
+		BeneficiaryAccount: check.BeneficiaryAccount,
+		DeviceID:           check.DeviceID,
+		IPAddress:          check.IPAddress,
+		Decision:           models.FRAUD_DECISION_ALLOW,
+		ReviewStatus:       models.FRAUD_REVIEW_STATUS_NONE,
+	}
+
+	for _, rule := range rules {
+		if !fraudRuleAppliesTo(&rule, check.TransactionType) || baseAmount < rule.Parameters.MinAmount {
+			continue
+		}
+		detail, hit, err := evaluateFraudRule(db, &rule, check, baseAmount, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate fraud rule %s: %v", rule.Code, err)
+		}
+		if !hit {
+			continue
+		}
+		decision.RuleHits = append(decision.RuleHits, models.FraudRuleHit{
+			RuleID:   rule.ID,
+			RuleCode: rule.Code,
+			RuleType: rule.RuleType,
+			Action:   rule.Action,
+			Detail:   detail,
+		})
+		decision.Decision = models.MoreSevereFraudDecision(decision.Decision, rule.Action)
+	}
+
+	if decision.Decision != models.FRAUD_DECISION_ALLOW {
+		decision.ReviewStatus = models.FRAUD_REVIEW_STATUS_PENDING
+	}
+	if decision.Decision == models.FRAUD_DECISION_CHALLENGE {
+		validity := getConfigInt64(db, "fraud_challenge_validity_minutes", defaultFraudChallengeValidityMinutes)
+		expiresAt := now.Add(time.Duration(validity) * time.Minute)
+		decision.ExpiresAt = &expiresAt
+	}
+
+	// Creating the decision also creates its rule hits
+	if err := db.Create(&decision).Error; err != nil {
+		return nil, fmt.Errorf("failed to store fraud decision: %v", err)
+	}
+	return &decision, nil
+}
+
+// fraudRuleAppliesTo reports whether a rule covers the transaction type
+func fraudRuleAppliesTo(rule *models.FraudRule, transactionType string) bool {
+	if rule.TransactionTypes == "" {
+		return true
+	}
+	for _, t := range strings.Split(rule.TransactionTypes, ",") {
+		if strings.TrimSpace(t) == transactionType {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateFraudRule checks one rule against a transaction attempt and describes why it matched
+func evaluateFraudRule(db *gorm.DB, rule *models.FraudRule, check fraudCheck, baseAmount int64, now time.Time) (string, bool, error) {
+	params := rule.Parameters
+	historyTypes := fraudHistoryTypes[check.TransactionType]
+
+	switch rule.RuleType {
+	case models.FRAUD_RULE_VELOCITY:
+		var count int64
+		if err := db.Model(&models.Transaction{}).
+			Where("user_id = ? AND type IN ? AND created_at >= ?", check.UserID, historyTypes,
+				now.Add(-time.Duration(params.WindowMinutes)*time.Minute)).
+			Count(&count).Error; err != nil {
+			return "", false, err
+		}
+		if count+1 <= int64(params.MaxCount) {
+			return "", false, nil
+		}
+		return fmt.Sprintf("%d %s transactions in %d minutes, limit %d", count+1, check.TransactionType,
+			params.WindowMinutes, params.MaxCount), true, nil
+
+	case models.FRAUD_RULE_AMOUNT_ANOMALY:
+		var history struct {
+			Count   int64
+			Average float64
+		}
+		if err := db.Model(&models.Transaction{}).
+			Select("COUNT(*) AS count, COALESCE(AVG(amount), 0) AS average").
+			Where("user_id = ? AND type IN ? AND currency = ? AND status IN ? AND created_at >= ?",
+				check.UserID, historyTypes, models.BASE_CURRENCY,
+				[]string{models.TRANSACTION_STATUS_COMPLETED, models.TRANSACTION_STATUS_PARTIALLY_REVERSED},
+				now.AddDate(0, 0, -params.LookbackDays)).
+			Scan(&history).Error; err != nil {
+			return "", false, err
+		}
+		if history.Count < int64(params.MinHistory) {
+			return "", false, nil
+		}
+		threshold := int64(math.Round(history.Average * float64(params.MultiplierPercent) / 100))
+		if baseAmount <= threshold {
+			return "", false, nil
+		}
+		return fmt.Sprintf("amount %d exceeds %d%% of the %d-day average %.0f", baseAmount,
+			params.MultiplierPercent, params.LookbackDays, history.Average), true, nil
+
+	case models.FRAUD_RULE_NEW_DEVICE_AMOUNT:
+		if check.DeviceID == "" {
+			return fmt.Sprintf("amount %d from an unidentified device", baseAmount), true, nil
+		}
+		var session models.DeviceSession
+		err := db.Where("user_id = ? AND device_id = ?", check.UserID, check.DeviceID).Order("created_at").First(&session).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", false, err
+		}
+		if err == nil && session.CreatedAt.Before(now.Add(-time.Duration(params.NewDeviceHours)*time.Hour)) {
+			return "", false, nil
+		}
+		return fmt.Sprintf("amount %d from device first seen within %d hours", baseAmount, params.NewDeviceHours), true, nil
+
+	case models.FRAUD_RULE_NEW_BENEFICIARY:
+		if check.BeneficiaryUserID == nil {
+			return "", false, nil
+		}
+		var count int64
+		if err := db.Table("transactions AS t").
+			Joins("JOIN transactions AS c ON c.id = t.counterparty_txn_id").
+			Where("t.user_id = ? AND t.type = ? AND c.user_id = ? AND t.deleted_at IS NULL",
+				check.UserID, models.TRANSACTION_TYPE_TRANSFER_OUT, *check.BeneficiaryUserID).
+			Count(&count).Error; err != nil {
+			return "", false, err
+		}
+		if count > 0 {
+			return "", false, nil
+		}
+		return "first transfer to account " + check.BeneficiaryAccount, true, nil
+
+	case models.FRAUD_RULE_NIGHT_TIME:
+		hour := now.In(fraudRuleLocation(params.Timezone)).Hour()
+		inWindow := false
+		switch {
+		case params.StartHour < params.EndHour:
+			inWindow = hour >= params.StartHour && hour < params.EndHour
+		case params.StartHour > params.EndHour:
+			inWindow = hour >= params.StartHour || hour < params.EndHour
+		}
+		if !inWindow {
+			return "", false, nil
+		}
+		return fmt.Sprintf("activity at %02d:00, between %02d:00 and %02d:00", hour, params.StartHour, params.EndHour), true, nil
+	}
+
+	return "", false, nil
+}
+
+// fraudRuleLocation loads a rule timezone, falling back to Western Indonesian Time
+func fraudRuleLocation(timezone string) *time.Location {
+	if timezone == "" {
+		timezone = defaultFraudTimezone
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.FixedZone("WIB", 7*60*60)
+	}
+	return location
+}
+
+// fraudDeviceID identifies the device of a request from the X-Device-ID header, falling back to
+// the device of the session the bearer token belongs to
+func fraudDeviceID(c *gin.Context, db *gorm.DB) string {
+	if deviceID := c.GetHeader("X-Device-ID"); deviceID != "" {
+		return deviceID
+	}
+
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if token == "" {
+		return ""
+	}
+	var session models.DeviceSession
+	if err := db.Select("device_id").Where("session_token = ? AND is_active = ?", token, true).First(&session).Error; err != nil {
+		return ""
+	}
+	return session.DeviceID
+}
+
+// validateFraudRuleParameters returns a message when the parameters do not suit the rule type
+func validateFraudRuleParameters(ruleType string, params *models.FraudRuleParameters) string {
+	if params.MinAmount < 0 {
+		return "min_amount cannot be negative"
+	}
+
+	switch ruleType {
+	case models.FRAUD_RULE_VELOCITY:
+		if params.MaxCount < 1 || params.WindowMinutes < 1 || params.WindowMinutes > 7*24*60 {
+			return "Velocity rules need max_count of at least 1 and window_minutes between 1 and 10080"
+		}
+	case models.FRAUD_RULE_AMOUNT_ANOMALY:
+		if params.MultiplierPercent < 100 || params.LookbackDays < 1 || params.LookbackDays > 365 || params.MinHistory < 1 {
+			return "Amount anomaly rules need multiplier_percent of at least 100, lookback_days between 1 and 365 and min_history of at least 1"
+		}
+	case models.FRAUD_RULE_NEW_DEVICE_AMOUNT:
+		if params.NewDeviceHours < 1 {
+			return "New device rules need new_device_hours of at least 1"
+		}
+	case models.FRAUD_RULE_NIGHT_TIME:
+		if params.StartHour < 0 || params.StartHour > 23 || params.EndHour < 0 || params.EndHour > 23 ||
+			params.StartHour == params.EndHour {
+			return "Night-time rules need different start_hour and end_hour between 0 and 23"
+		}
+		if params.Timezone != "" {
+			if _, err := time.LoadLocation(params.Timezone); err != nil {
+				return "Unknown timezone " + params.Timezone
+			}
+		}
+	}
+	return ""
+}
+
+// applyFraudRuleRequest copies the editable fields of a request onto a rule
+func applyFraudRuleRequest(rule *models.FraudRule, req *models.FraudRuleRequest) {
+	rule.Name = req.Name
+	rule.Description = req.Description
+	rule.RuleType = req.RuleType
+	rule.TransactionTypes = strings.Join(req.TransactionTypes, ",")
+	rule.Parameters = req.Parameters
+	rule.Action = req.Action
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+}
+
+// GetRules - List fraud rules (Admin only)
+func (h *FraudHandler) GetRules(c *gin.Context) {
+	query := h.DB.Model(&models.FraudRule{})
+	if ruleType := c.Query("rule_type"); ruleType != "" {
+		query = query.Where("rule_type = ?", ruleType)
+	}
+	if active := c.Query("is_active"); active != "" {
+		query = query.Where("is_active = ?", active == "true")
+	}
+
+	var rules []models.FraudRule
+	if err := query.Order("id").Find(&rules).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch fraud rules",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Fraud rules retrieved successfully",
+		Data:    rules,
+	})
+}
+
+// CreateRule - Create a fraud rule (Admin only)
+func (h *FraudHandler) CreateRule(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+
+	var req models.FraudRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+	if message := validateFraudRuleParameters(req.RuleType, &req.Parameters); message != "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: message,
+		})
+		return
+	}
+
+	var count int64
+	h.DB.Model(&models.FraudRule{}).Where("code = ?", req.Code).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "Fraud rule code already exists",
+		})
+		return
+	}
+
+	adminIDValue := adminID.(uint)
+	rule := models.FraudRule{Code: req.Code, IsActive: true, CreatedByAdminID: &adminIDValue, UpdatedByAdminID: &adminIDValue}
+	applyFraudRuleRequest(&rule, &req)
+	if err := h.DB.Create(&rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create fraud rule",
+		})
+		return
+	}
+
+	createAuditLog(h.DB, c, "fraud_rule", rule.ID, adminIDValue, "CREATE", map[string]interface{}{
+		"rule": rule,
+	})
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Code:    http.StatusCreated,
+		Message: "Fraud rule created successfully",
+		Data:    rule,
+	})
+}
+
+// UpdateRule - Update a fraud rule (Admin only)
+func (h *FraudHandler) UpdateRule(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+
+	var rule models.FraudRule
+	if err := h.DB.First(&rule, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Fraud rule not found",
+		})
+		return
+	}
+
+	var req models.FraudRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+	if req.Code != rule.Code {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Fraud rule code cannot be changed",
+		})
+		return
+	}
+	if message := validateFraudRuleParameters(req.RuleType, &req.Parameters); message != "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: message,
+		})
+		return
+	}
+
+	adminIDValue := adminID.(uint)
+	oldRule := rule
+	applyFraudRuleRequest(&rule, &req)
+	rule.UpdatedByAdminID = &adminIDValue
+	if err := h.DB.Save(&rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update fraud rule",
+		})
+		return
+	}
+
+	createAuditLog(h.DB, c, "fraud_rule", rule.ID, adminIDValue, "UPDATE", map[string]interface{}{
+		"old_rule": oldRule,
+		"new_rule": rule,
+	})
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Fraud rule updated successfully",
+		Data:    rule,
+	})
+}
+
+// GetDecisions - List stored fraud decisions for review (Admin only)
+func (h *FraudHandler) GetDecisions(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.FraudDecision{})
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if decision := c.Query("decision"); decision != "" {
+		query = query.Where("decision = ?", decision)
+	}
+	if reviewStatus := c.Query("review_status"); reviewStatus != "" {
+		query = query.Where("review_status = ?", reviewStatus)
+	}
+	if ruleCode := c.Query("rule_code"); ruleCode != "" {
+		query = query.Where("id IN (?)", h.DB.Model(&models.FraudRuleHit{}).Select("decision_id").Where("rule_code = ?", ruleCode))
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var decisions []models.FraudDecision
+	if err := query.Preload("RuleHits").Order("created_at DESC").Limit(limit).Offset(offset).Find(&decisions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch fraud decisions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Fraud decisions retrieved successfully",
+		Data: gin.H{
+			"decisions": decisions,
+			"pagination": gin.H{
+				"page":        page,
+				"limit":       limit,
+				"total":       total,
+				"total_pages": int(math.Ceil(float64(total) / float64(limit))),
+			},
+		},
+	})
+}
+
+// GetDecisionByID - Get a fraud decision with its rule hits (Admin only)
+func (h *FraudHandler) GetDecisionByID(c *gin.Context) {
+	var decision models.FraudDecision
+	if err := h.DB.Preload("RuleHits").First(&decision, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Fraud decision not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Fraud decision retrieved successfully",
+		Data:    decision,
+	})
+}
+
+// ReviewDecision - Approve or reject a held or blocked transaction (Admin only). An approved
+// transaction may be resubmitted by the customer with the decision ID until the approval expires.
+func (h *FraudHandler) ReviewDecision(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+
+	var req models.ReviewFraudDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	tx := h.DB.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var decision models.FraudDecision
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&decision, c.Param("id")).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Fraud decision not found",
+		})
+		return
+	}
+
+	if (decision.Decision != models.FRAUD_DECISION_HOLD && decision.Decision != models.FRAUD_DECISION_BLOCK) ||
+		decision.ReviewStatus != models.FRAUD_REVIEW_STATUS_PENDING {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Only pending held or blocked transactions can be reviewed",
+		})
+		return
+	}
+
+	now := time.Now()
+	adminIDValue := adminID.(uint)
+	decision.ReviewedByAdminID = &adminIDValue
+	decision.ReviewNotes = req.Notes
+	decision.ReviewedAt = &now
+	if req.Action == "approve" {
+		validity := getConfigInt64(h.DB, "fraud_approval_validity_hours", defaultFraudApprovalValidityHours)
+		expiresAt := now.Add(time.Duration(validity) * time.Hour)
+		decision.ReviewStatus = models.FRAUD_REVIEW_STATUS_APPROVED
+		decision.ExpiresAt = &expiresAt
+	} else {
+		decision.ReviewStatus = models.FRAUD_REVIEW_STATUS_REJECTED
+	}
+
+	if err := tx.Save(&decision).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update fraud decision",
+		})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to review fraud decision",
+		})
+		return
+	}
+
+	createAuditLog(h.DB, c, "fraud_decision", decision.ID, adminIDValue, strings.ToUpper(req.Action), map[string]interface{}{
+		"user_id":       decision.UserID,
+		"decision":      decision.Decision,
+		"review_status": decision.ReviewStatus,
+		"notes":         req.Notes,
+	})
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Fraud decision reviewed successfully",
+		Data:    decision,
+	})
+}
@@ -0,0 +1,591 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"mbankingcore/models"
+	"mbankingcore/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Default FX quote validity, overridable via config key "fx_quote_validity_seconds"
+const defaultFXQuoteValiditySeconds = 60
+
+// errFXQuoteUsed is returned when a quote was used by a concurrent transfer
+var errFXQuoteUsed = errors.New("FX quote has already been used")
+
+type FXHandler struct {
+	DB *gorm.DB
+}
+
+func NewFXHandler(db *gorm.DB) *FXHandler {
+	return &FXHandler{DB: db}
+}
+
+// GetCurrencies - List active currencies
+func (h *FXHandler) GetCurrencies(c *gin.Context) {
+	var currencies []models.Currency
+	if err := h.DB.Where("is_active = ?", true).Order("code ASC").Find(&currencies).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch currencies",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Currencies retrieved successfully",
+		Data:    currencies,
+	})
+}
+
+// CreateCurrency - Add a supported currency (admin)
+func (h *FXHandler) CreateCurrency(c *gin.Context) {
+	var req models.CreateCurrencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	currency := models.Currency{
+		Code:       strings.ToUpper(req.Code),
+		Name:       req.Name,
+		MinorUnits: req.MinorUnits,
+		IsActive:   true,
+	}
+
+	var existing int64
+	h.DB.Model(&models.Currency{}).Where("code = ?", currency.Code).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "Currency already exists",
+		})
+		return
+	}
+
+	if err := h.DB.Create(&currency).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create currency",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Code:    http.StatusCreated,
+		Message: "Currency created successfully",
+		Data:    currency,
+	})
+}
+
+// ProposeRate - Propose a new FX rate against the base currency (maker)
+func (h *FXHandler) ProposeRate(c *gin.Context) {
+	makerAdminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+
+	var req models.ProposeFXRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	code := strings.ToUpper(req.Currency)
+	if code == models.BASE_CURRENCY {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Rates are quoted against " + models.BASE_CURRENCY + ", it cannot have a rate itself",
+		})
+		return
+	}
+
+	var currency models.Currency
+	if err := h.DB.Where("code = ? AND is_active = ?", code, true).First(&currency).Error; err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Unsupported currency",
+		})
+		return
+	}
+
+	buyRate, err := utils.ParseFXRate(req.BuyRate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid buy rate: " + err.Error(),
+		})
+		return
+	}
+	sellRate, err := utils.ParseFXRate(req.SellRate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid sell rate: " + err.Error(),
+		})
+		return
+	}
+	if buyRate > sellRate {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Buy rate cannot be higher than sell rate",
+		})
+		return
+	}
+
+	rate := models.FXRate{
+		Currency:     currency.Code,
+		BuyRate:      buyRate,
+		SellRate:     sellRate,
+		Status:       models.FX_RATE_STATUS_PENDING,
+		MakerAdminID: makerAdminID.(uint),
+		Comments:     req.Comments,
+	}
+	if err := h.DB.Create(&rate).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create FX rate",
+		})
+		return
+	}
+
+	createAuditLog(h.DB, c, "fx_rate", rate.ID, rate.MakerAdminID, "CREATE", map[string]interface{}{
+		"currency":  rate.Currency,
+		"buy_rate":  utils.FormatFXRate(rate.BuyRate),
+		"sell_rate": utils.FormatFXRate(rate.SellRate),
+	})
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Code:    http.StatusCreated,
+		Message: "FX rate submitted for approval",
+		Data:    fxRateResponse(rate),
+	})
+}
+
+// ReviewRate - Approve or reject a proposed FX rate (checker)
+func (h *FXHandler) ReviewRate(c *gin.Context) {
+	checkerAdminIDValue, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+	checkerAdminID := checkerAdminIDValue.(uint)
+
+	var req models.ApprovalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+	if req.Action == "reject" && req.RejectionReason == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Rejection reason is required when rejecting",
+		})
+		return
+	}
+
+	tx := h.DB.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var rate models.FXRate
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&rate, c.Param("id")).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "FX rate not found",
+		})
+		return
+	}
+
+	if rate.Status != models.FX_RATE_STATUS_PENDING {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "FX rate is not pending approval",
+		})
+		return
+	}
+
+	if rate.MakerAdminID == checkerAdminID {
+		tx.Rollback()
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Code:    http.StatusForbidden,
+			Message: "Maker cannot approve their own FX rate",
+		})
+		return
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"checker_admin_id": checkerAdminID,
+		"reviewed_at":      &now,
+	}
+
+	if req.Action == "approve" {
+		if err := tx.Model(&models.FXRate{}).
+			Where("currency = ? AND status = ?", rate.Currency, models.FX_RATE_STATUS_ACTIVE).
+			Update("status", models.FX_RATE_STATUS_SUPERSEDED).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to supersede current FX rate",
+			})
+			return
+		}
+		updates["status"] = models.FX_RATE_STATUS_ACTIVE
+		updates["effective_at"] = &now
+	} else {
+		updates["status"] = models.FX_RATE_STATUS_REJECTED
+		updates["rejection_reason"] = req.RejectionReason
+	}
+
+	if err := tx.Model(&rate).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update FX rate",
+		})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to commit FX rate review",
+		})
+		return
+	}
+
+	h.DB.First(&rate, rate.ID)
+	createAuditLog(h.DB, c, "fx_rate", rate.ID, checkerAdminID, strings.ToUpper(req.Action), map[string]interface{}{
+		"currency":         rate.Currency,
+		"comments":         req.Comments,
+		"rejection_reason": req.RejectionReason,
+	})
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "FX rate " + req.Action + "d successfully",
+		Data:    fxRateResponse(rate),
+	})
+}
+
+// GetRates - List FX rates with optional ?status= and ?currency= filters (admin)
+func (h *FXHandler) GetRates(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.FXRate{})
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if currency := c.Query("currency"); currency != "" {
+		query = query.Where("currency = ?", strings.ToUpper(currency))
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var rates []models.FXRate
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&rates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch FX rates",
+		})
+		return
+	}
+
+	responses := make([]models.FXRateResponse, 0, len(rates))
+	for _, rate := range rates {
+		responses = append(responses, fxRateResponse(rate))
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "FX rates retrieved successfully",
+		Data: gin.H{
+			"rates": responses,
+			"pagination": gin.H{
+				"current_page": page,
+				"per_page":     limit,
+				"total":        total,
+				"total_pages":  (total + int64(limit) - 1) / int64(limit),
+			},
+		},
+	})
+}
+
+// GetActiveRates - Current buy and sell rates for every currency
+func (h *FXHandler) GetActiveRates(c *gin.Context) {
+	var rates []models.FXRate
+	if err := h.DB.Where("status = ?", models.FX_RATE_STATUS_ACTIVE).Order("currency ASC").Find(&rates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch FX rates",
+		})
+		return
+	}
+
+	responses := make([]models.FXRateResponse, 0, len(rates))
+	for _, rate := range rates {
+		responses = append(responses, fxRateResponse(rate))
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "FX rates retrieved successfully",
+		Data: gin.H{
+			"base_currency": models.BASE_CURRENCY,
+			"rates":         responses,
+		},
+	})
+}
+
+// Quote - Quote a conversion at the current rates; the quote is honoured by a transfer until it expires
+func (h *FXHandler) Quote(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	var req models.FXQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	from := strings.ToUpper(req.FromCurrency)
+	to := strings.ToUpper(req.ToCurrency)
+	if from == to {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Source and target currency must differ",
+		})
+		return
+	}
+
+	conversion, err := getFXConversion(h.DB, from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	converted, err := conversion.Convert(req.Amount)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+	if converted <= 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Amount is too small to convert",
+		})
+		return
+	}
+
+	validity := getConfigInt64(h.DB, "fx_quote_validity_seconds", defaultFXQuoteValiditySeconds)
+	quote := models.FXQuote{
+		UserID:          userID.(uint),
+		FromCurrency:    from,
+		ToCurrency:      to,
+		Amount:          req.Amount,
+		ConvertedAmount: converted,
+		FromRate:        conversion.FromRate,
+		ToRate:          conversion.ToRate,
+		ExchangeRate:    conversion.RateString(),
+		ExpiresAt:       time.Now().Add(time.Duration(validity) * time.Second),
+	}
+	if err := h.DB.Create(&quote).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create FX quote",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "FX quote created successfully",
+		Data:    quote,
+	})
+}
+
+// GetBalances - Balances of the authenticated user in every currency
+func (h *FXHandler) GetBalances(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+		})
+		return
+	}
+
+	var balances []models.CurrencyBalance
+	h.DB.Where("user_id = ?", user.ID).Order("currency ASC").Find(&balances)
+
+	result := []gin.H{{"currency": models.BASE_CURRENCY, "balance": user.Balance}}
+	for _, balance := range balances {
+		result = append(result, gin.H{"currency": balance.Currency, "balance": balance.Balance})
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Balances retrieved successfully",
+		Data:    result,
+	})
+}
+
+// getFXConversion builds the conversion between two currencies from the active rates.
+// Non-base to non-base conversions go through the base currency (buy, then sell).
+func getFXConversion(db *gorm.DB, from, to string) (utils.FXConversion, error) {
+	var conversion utils.FXConversion
+
+	fromCurrency, err := getActiveCurrency(db, from)
+	if err != nil {
+		return conversion, err
+	}
+	toCurrency, err := getActiveCurrency(db, to)
+	if err != nil {
+		return conversion, err
+	}
+	conversion.FromMinorUnits = fromCurrency.MinorUnits
+	conversion.ToMinorUnits = toCurrency.MinorUnits
+
+	if from == to {
+		return conversion, nil
+	}
+
+	if from != models.BASE_CURRENCY {
+		rate, err := getActiveFXRate(db, from)
+		if err != nil {
+			return conversion, err
+		}
+		conversion.FromRate = rate.BuyRate
+	}
+	if to != models.BASE_CURRENCY {
+		rate, err := getActiveFXRate(db, to)
+		if err != nil {
+			return conversion, err
+		}
+		conversion.ToRate = rate.SellRate
+	}
+
+	return conversion, nil
+}
+
+// getFXQuote returns the customer's quote for a transfer of amount between two currencies,
+// refusing quotes that are used, expired or were given for a different conversion
+func getFXQuote(db *gorm.DB, quoteID, userID uint, from, to string, amount int64, now time.Time) (*models.FXQuote, error) {
+	var quote models.FXQuote
+	if err := db.Where("id = ? AND user_id = ?", quoteID, userID).First(&quote).Error; err != nil {
+		return nil, errors.New("FX quote not found")
+	}
+	if quote.UsedAt != nil {
+		return nil, errFXQuoteUsed
+	}
+	if !now.Before(quote.ExpiresAt) {
+		return nil, errors.New("FX quote has expired, request a new quote")
+	}
+	if quote.FromCurrency != from || quote.ToCurrency != to || quote.Amount != amount {
+		return nil, errors.New("transfer does not match the FX quote")
+	}
+	return &quote, nil
+}
+
+// useFXQuote marks a quote as used by a transfer, failing with errFXQuoteUsed when a concurrent
+// transfer used it first
+func useFXQuote(tx *gorm.DB, quote *models.FXQuote, transactionID uint) error {
+	now := time.Now()
+	result := tx.Model(quote).Where("used_at IS NULL").Updates(map[string]interface{}{
+		"used_at":        &now,
+		"transaction_id": transactionID,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update FX quote: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errFXQuoteUsed
+	}
+	return nil
+}
+
+func getActiveCurrency(db *gorm.DB, code string) (*models.Currency, error) {
+	var currency models.Currency
+	if err := db.Where("code = ? AND is_active = ?", code, true).First(&currency).Error; err != nil {
+		return nil, fmt.Errorf("unsupported currency %s", code)
+	}
+	return &currency, nil
+}
+
+func getActiveFXRate(db *gorm.DB, currency string) (*models.FXRate, error) {
+	var rate models.FXRate
+	if err := db.Where("currency = ? AND status = ?", currency, models.FX_RATE_STATUS_ACTIVE).First(&rate).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("no active FX rate for %s", currency)
+		}
+		return nil, fmt.Errorf("failed to get FX rate for %s: %v", currency, err)
+	}
+	return &rate, nil
+}
+
+func fxRateResponse(rate models.FXRate) models.FXRateResponse {
+	return models.FXRateResponse{
+		FXRate:          rate,
+		BuyRateDecimal:  utils.FormatFXRate(rate.BuyRate),
+		SellRateDecimal: utils.FormatFXRate(rate.SellRate),
+	}
+}
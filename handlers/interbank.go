@@ -0,0 +1,748 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mbankingcore/models"
+	"mbankingcore/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Default interbank transfer fee (overridable via config key "interbank_transfer_fee")
+const defaultInterbankTransferFee = 2500
+
+type InterbankHandler struct {
+	DB      *gorm.DB
+	Adapter utils.SwitchingAdapter
+}
+
+func NewInterbankHandler(db *gorm.DB, adapter utils.SwitchingAdapter) *InterbankHandler {
+	h := &InterbankHandler{DB: db, Adapter: adapter}
+
+	// In-process adapters (e.g. the simulator) deliver callbacks directly
+	adapter.SetCallbackHandler(func(callback utils.SwitchingCallback) {
+		if _, err := h.applySwitchingResult(callback.Reference, callback.SwitchReference, callback.Status, callback.FailureReason); err != nil {
+			log.Printf("Failed to apply switching callback for %s: %v", callback.Reference, err)
+		}
+	})
+
+	return h
+}
+
+// InquireAccount - Validate a beneficiary account at another bank
+func (h *InterbankHandler) InquireAccount(c *gin.Context) {
+	var req models.InterbankInquiryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	info, err := h.Adapter.InquireAccount(req.BankCode, req.AccountNumber)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, utils.ErrSwitchingUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, models.ErrorResponse{
+			Code:    status,
+			Message: "Account inquiry failed: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Account inquiry successful",
+		Data: gin.H{
+			"bank_code":      info.BankCode,
+			"bank_name":      info.BankName,
+			"account_number": info.AccountNumber,
+			"account_name":   info.AccountName,
+			"fee":            getConfigInt64(h.DB, "interbank_transfer_fee", defaultInterbankTransferFee),
+		},
+	})
+}
+
+// CreateTransfer - Send money to an account at another bank
+func (h *InterbankHandler) CreateTransfer(c *gin.Context) {
+	userIDValue, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+	userID := userIDValue.(uint)
+
+	var req models.InterbankTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Validate the beneficiary before moving any money
+	info, err := h.Adapter.InquireAccount(req.BankCode, req.AccountNumber)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, utils.ErrSwitchingUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, models.ErrorResponse{
+			Code:    status,
+			Message: "Beneficiary validation failed: " + err.Error(),
+		})
+		return
+	}
+
+	var sourceAccount models.BankAccount
+	if err := h.DB.Where("user_id = ? AND is_active = ?", userID, true).
+		Order("is_primary DESC, created_at ASC").
+		First(&sourceAccount).Error; err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "No active source account found",
+		})
+		return
+	}
+
+	// Sanctions screening of the customer and the external beneficiary, new device limits, then the
+	// fraud rules, before any money moves
+	var sourceUser models.User
+	if err := h.DB.First(&sourceUser, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+		})
+		return
+	}
+	if !screenTransfer(c, h.DB, &sourceUser, nil, info.AccountName, info.BankCode+"-"+info.AccountNumber) {
+		return
+	}
+
+	fee := getConfigInt64(h.DB, "interbank_transfer_fee", defaultInterbankTransferFee)
+	if !checkDeviceCoolingOff(c, h.DB, userID, models.BASE_CURRENCY, req.Amount+fee) {
+		return
+	}
+	fraudDecision := screenTransaction(c, h.DB, fraudCheck{
+		UserID:             userID,
+		TransactionType:    models.FRAUD_CHECK_TYPE_TRANSFER,
+		Amount:             req.Amount,
+		Currency:           models.BASE_CURRENCY,
+		BeneficiaryAccount: info.BankCode + "-" + info.AccountNumber,
+		DeviceID:           fraudDeviceID(c, h.DB),
+		IPAddress:          c.ClientIP(),
+	}, req.FraudDecisionID, req.PIN)
+	if fraudDecision == nil {
+		return
+	}
+	description := req.Description
+	if description == "" {
+		description = fmt.Sprintf("Transfer to %s %s", info.BankName, info.AccountNumber)
+	}
+
+	transfer := models.InterbankTransfer{
+		Reference:                utils.GenerateReference("IBT"),
+		UserID:                   userID,
+		SourceAccountNumber:      sourceAccount.AccountNumber,
+		BeneficiaryBankCode:      info.BankCode,
+		BeneficiaryBankName:      info.BankName,
+		BeneficiaryAccountNumber: info.AccountNumber,
+		BeneficiaryAccountName:   info.AccountName,
+		Amount:                   req.Amount,
+		Fee:                      fee,
+		Description:              description,
+		Status:                   models.INTERBANK_STATUS_PENDING,
+		SwitchingAdapter:         h.Adapter.Name(),
+	}
+
+	// Debit the customer and park the funds in the suspense account
+	tx := h.DB.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	debitTxn, err := debitUserBalance(tx, userID, req.Amount, "interbank_transfer_out", description)
+	if err != nil {
+		tx.Rollback()
+		respondLedgerError(c, err, "Failed to debit account")
+		return
+	}
+	transfer.DebitTransactionID = &debitTxn.ID
+
+	if err := completeFraudDecision(tx, fraudDecision, debitTxn.ID); err != nil {
+		tx.Rollback()
+		respondFraudDecisionError(c, err)
+		return
+	}
+
+	if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_INTERBANK_SUSPENSE, req.Amount, transfer.Reference,
+		"Interbank transfer hold", &debitTxn.ID); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to post to suspense account",
+		})
+		return
+	}
+
+	if fee > 0 {
+		feeTxn, err := debitUserBalance(tx, userID, fee, "fee", "Interbank transfer fee "+transfer.Reference)
+		if err != nil {
+			tx.Rollback()
+			respondLedgerError(c, err, "Failed to debit account")
+			return
+		}
+		transfer.FeeTransactionID = &feeTxn.ID
+
+		if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_INTERBANK_SUSPENSE, fee, transfer.Reference,
+			"Interbank transfer fee hold", &feeTxn.ID); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to post to suspense account",
+			})
+			return
+		}
+	}
+
+	// Sweep pocket auto-save rules on the transfer out
+	if err := applyAutoSave(tx, userID, debitTxn); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to apply pocket auto-save",
+		})
+		return
+	}
+
+	if err := tx.Create(&transfer).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create interbank transfer",
+		})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to commit interbank transfer",
+		})
+		return
+	}
+
+	// Send through the switch outside of the database transaction
+	now := time.Now()
+	response, sendErr := h.Adapter.SendTransfer(utils.SwitchingTransferRequest{
+		Reference:                transfer.Reference,
+		SourceAccountNumber:      sourceAccount.AccountNumber,
+		SourceAccountName:        sourceUser.Name,
+		BeneficiaryBankCode:      transfer.BeneficiaryBankCode,
+		BeneficiaryAccountNumber: transfer.BeneficiaryAccountNumber,
+		BeneficiaryAccountName:   transfer.BeneficiaryAccountName,
+		Amount:                   transfer.Amount,
+		Description:              transfer.Description,
+	})
+
+	switch {
+	case sendErr != nil && errors.Is(sendErr, utils.ErrSwitchingUnavailable):
+		// Never reached the switch, safe to refund immediately
+		h.applySwitchingResult(transfer.Reference, "", utils.SWITCHING_STATUS_FAILED, sendErr.Error())
+	case sendErr != nil:
+		// Outcome unknown; keep the funds in suspense until a status check resolves it
+		h.markTransferSuspect(&transfer, "", sendErr.Error(), now)
+	case response.Status == utils.SWITCHING_STATUS_ACCEPTED:
+		h.DB.Model(&transfer).Where("status = ?", models.INTERBANK_STATUS_PENDING).Updates(map[string]interface{}{
+			"status":           models.INTERBANK_STATUS_PROCESSING,
+			"switch_reference": response.SwitchReference,
+			"sent_at":          &now,
+		})
+	case response.Status == utils.SWITCHING_STATUS_SUCCESS || response.Status == utils.SWITCHING_STATUS_FAILED:
+		h.DB.Model(&transfer).Update("sent_at", &now)
+		h.applySwitchingResult(transfer.Reference, response.SwitchReference, response.Status, response.FailureReason)
+	default:
+		// Not a final result, the transfer may still settle
+		h.markTransferSuspect(&transfer, response.SwitchReference, "unexpected switching status "+response.Status, now)
+	}
+
+	h.DB.First(&transfer, transfer.ID)
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Interbank transfer submitted",
+		Data:    transfer,
+	})
+}
+
+// markTransferSuspect moves a transfer just sent to the switch from pending to suspect, keeping the
+// funds in suspense. A callback that arrived first has already moved it on and is left in place.
+func (h *InterbankHandler) markTransferSuspect(transfer *models.InterbankTransfer, switchReference, reason string, sentAt time.Time) {
+	updates := map[string]interface{}{
+		"status":         models.INTERBANK_STATUS_SUSPECT,
+		"failure_reason": reason,
+		"sent_at":        &sentAt,
+	}
+	if switchReference != "" {
+		updates["switch_reference"] = switchReference
+	}
+
+	result := h.DB.Model(transfer).Where("status = ?", models.INTERBANK_STATUS_PENDING).Updates(updates)
+	if result.Error != nil {
+		log.Printf("Failed to mark interbank transfer %s suspect: %v", transfer.Reference, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		log.Printf("Interbank transfer %s already updated by the switch, not marked suspect", transfer.Reference)
+	}
+}
+
+// GetUserTransfers - Get interbank transfer history for the authenticated user
+func (h *InterbankHandler) GetUserTransfers(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.InterbankTransfer{}).Where("user_id = ?", userID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var transfers []models.InterbankTransfer
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&transfers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch interbank transfers",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Interbank transfers retrieved successfully",
+		Data: gin.H{
+			"transfers": transfers,
+			"pagination": gin.H{
+				"current_page": page,
+				"per_page":     limit,
+				"total":        total,
+				"total_pages":  (total + int64(limit) - 1) / int64(limit),
+			},
+		},
+	})
+}
+
+// GetUserTransferByID - Get an interbank transfer owned by the authenticated user
+func (h *InterbankHandler) GetUserTransferByID(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid transfer ID",
+		})
+		return
+	}
+
+	var transfer models.InterbankTransfer
+	if err := h.DB.Where("id = ? AND user_id = ?", uint(id), userID).First(&transfer).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Interbank transfer not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Interbank transfer retrieved successfully",
+		Data:    transfer,
+	})
+}
+
+// GetAllTransfers - Get all interbank transfers for admin monitoring
+func (h *InterbankHandler) GetAllTransfers(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.InterbankTransfer{}).Preload("User")
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if bankCode := c.Query("bank_code"); bankCode != "" {
+		query = query.Where("beneficiary_bank_code = ?", bankCode)
+	}
+	if reference := c.Query("reference"); reference != "" {
+		query = query.Where("reference = ?", reference)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var transfers []models.InterbankTransfer
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&transfers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch interbank transfers",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "All interbank transfers retrieved successfully",
+		Data: gin.H{
+			"transfers": transfers,
+			"pagination": gin.H{
+				"current_page": page,
+				"per_page":     limit,
+				"total":        total,
+				"total_pages":  (total + int64(limit) - 1) / int64(limit),
+			},
+		},
+	})
+}
+
+// CheckTransferStatus - Query the switch for a processing or suspect transfer and apply the result (admin)
+func (h *InterbankHandler) CheckTransferStatus(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid transfer ID",
+		})
+		return
+	}
+
+	var transfer models.InterbankTransfer
+	if err := h.DB.First(&transfer, uint(id)).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Interbank transfer not found",
+		})
+		return
+	}
+
+	if transfer.Status == models.INTERBANK_STATUS_SUCCESS || transfer.Status == models.INTERBANK_STATUS_FAILED {
+		c.JSON(http.StatusOK, models.APIResponse{
+			Code:    http.StatusOK,
+			Message: "Interbank transfer is already final",
+			Data:    transfer,
+		})
+		return
+	}
+
+	response, err := h.Adapter.CheckStatus(transfer.Reference)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Code:    http.StatusServiceUnavailable,
+			Message: "Status check failed: " + err.Error(),
+		})
+		return
+	}
+
+	switch response.Status {
+	case utils.SWITCHING_STATUS_SUCCESS, utils.SWITCHING_STATUS_FAILED:
+		if _, err := h.applySwitchingResult(transfer.Reference, response.SwitchReference, response.Status, response.FailureReason); err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to apply switching result: " + err.Error(),
+			})
+			return
+		}
+	case utils.SWITCHING_STATUS_UNKNOWN:
+		// No record at the switch is not a definitive failure; keep the funds in suspense for an operator
+		if _, err := h.applySwitchingResult(transfer.Reference, "", utils.SWITCHING_STATUS_UNKNOWN, "transfer not found at switch"); err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to apply switching result: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	h.DB.First(&transfer, transfer.ID)
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Interbank transfer status checked",
+		Data: gin.H{
+			"transfer":      transfer,
+			"switch_status": response.Status,
+		},
+	})
+}
+
+// ResolveTransfer - Settle or refund a suspect transfer on an operator decision (admin)
+func (h *InterbankHandler) ResolveTransfer(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid transfer ID",
+		})
+		return
+	}
+
+	var req models.InterbankResolveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var transfer models.InterbankTransfer
+	if err := h.DB.First(&transfer, uint(id)).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Interbank transfer not found",
+		})
+		return
+	}
+
+	if transfer.Status != models.INTERBANK_STATUS_SUSPECT {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "Only suspect interbank transfers can be resolved by an operator",
+		})
+		return
+	}
+
+	resolved, err := h.applySwitchingResult(transfer.Reference, "", req.Status, "Resolved by operator: "+req.Reason)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to resolve interbank transfer: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Interbank transfer resolved",
+		Data:    resolved,
+	})
+}
+
+// HandleCallback - Receive asynchronous transfer results from the switch (signature verified)
+func (h *InterbankHandler) HandleCallback(c *gin.Context) {
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Failed to read callback body",
+		})
+		return
+	}
+
+	if !h.Adapter.VerifyCallback(payload, c.GetHeader("X-Signature")) {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Invalid callback signature",
+		})
+		return
+	}
+
+	var callback utils.SwitchingCallback
+	if err := binding.JSON.BindBody(payload, &callback); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	transfer, err := h.applySwitchingResult(callback.Reference, callback.SwitchReference, callback.Status, callback.FailureReason)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "Interbank transfer not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to apply callback",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Callback processed",
+		Data: gin.H{
+			"reference": transfer.Reference,
+			"status":    transfer.Status,
+		},
+	})
+}
+
+// applySwitchingResult settles a transfer on success and refunds it only on an explicit failure; any
+// other status leaves the funds in suspense with the transfer suspect. Final transfers are left
+// untouched, so repeated callbacks are harmless.
+func (h *InterbankHandler) applySwitchingResult(reference, switchReference, status, failureReason string) (*models.InterbankTransfer, error) {
+	tx := h.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var transfer models.InterbankTransfer
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("reference = ?", reference).
+		First(&transfer).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if transfer.Status == models.INTERBANK_STATUS_SUCCESS || transfer.Status == models.INTERBANK_STATUS_FAILED {
+		tx.Rollback()
+		return &transfer, nil
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"completed_at": &now,
+	}
+	if switchReference != "" {
+		updates["switch_reference"] = switchReference
+	}
+
+	switch status {
+	case utils.SWITCHING_STATUS_SUCCESS:
+		// Move principal to the settlement account and recognise the fee
+		if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_INTERBANK_SUSPENSE, -transfer.Amount, transfer.Reference,
+			"Interbank transfer settled", transfer.DebitTransactionID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_INTERBANK_SETTLEMENT, transfer.Amount, transfer.Reference,
+			"Interbank transfer settled", transfer.DebitTransactionID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if transfer.Fee > 0 {
+			if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_INTERBANK_SUSPENSE, -transfer.Fee, transfer.Reference,
+				"Interbank transfer fee recognised", transfer.FeeTransactionID); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_FEE_INCOME, transfer.Fee, transfer.Reference,
+				"Interbank transfer fee", transfer.FeeTransactionID); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+		}
+
+		updates["status"] = models.INTERBANK_STATUS_SUCCESS
+	case utils.SWITCHING_STATUS_FAILED:
+		// Automatic refund of principal and fee from suspense
+		refundAmount := transfer.Amount + transfer.Fee
+		refundTxn, err := creditUserBalance(tx, transfer.UserID, refundAmount, "interbank_refund",
+			"Refund of interbank transfer "+transfer.Reference)
+		switch {
+		case err == nil:
+			if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_INTERBANK_SUSPENSE, -refundAmount, transfer.Reference,
+				"Interbank transfer refunded", &refundTxn.ID); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			updates["refund_transaction_id"] = refundTxn.ID
+		case errors.Is(err, errUserNotActive):
+			// The account was locked or closed since the debit; hold the refund for the customer
+			if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_INTERBANK_SUSPENSE, -refundAmount, transfer.Reference,
+				"Interbank transfer refund held", nil); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_UNCLAIMED_REFUNDS, refundAmount, transfer.Reference,
+				"Interbank transfer refund held, customer account not active", nil); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			updates["refund_held"] = true
+		default:
+			tx.Rollback()
+			return nil, err
+		}
+
+		updates["status"] = models.INTERBANK_STATUS_FAILED
+		updates["failure_reason"] = failureReason
+	case utils.SWITCHING_STATUS_ACCEPTED:
+		// Still processing at the switch
+		tx.Rollback()
+		return &transfer, nil
+	case utils.SWITCHING_STATUS_UNKNOWN:
+		// The switch has no record; only an explicit failure or an operator decision releases the funds
+		delete(updates, "completed_at")
+		updates["status"] = models.INTERBANK_STATUS_SUSPECT
+		updates["failure_reason"] = failureReason
+	default:
+		// Not a final result; keep the funds in suspense until a status check resolves it
+		delete(updates, "completed_at")
+		updates["status"] = models.INTERBANK_STATUS_SUSPECT
+		updates["failure_reason"] = "unexpected switching status " + status
+	}
+
+	if err := tx.Model(&transfer).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	tx.First(&transfer, transfer.ID)
+	return &transfer, nil
+}
@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"mbankingcore/models"
+	"mbankingcore/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const testSwitchingSecret = "test-switching-secret"
+
+// callInterbankHandler runs handler against a request with the given body, headers and :id param
+func callInterbankHandler(handler gin.HandlerFunc, id uint, body []byte, headers map[string]string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		c.Request.Header.Set(key, value)
+	}
+	c.Params = gin.Params{{Key: "id", Value: strconv.FormatUint(uint64(id), 10)}}
+	handler(c)
+	return w
+}
+
+// createTestInterbankTransfer books a transfer of 100000 with a 2500 fee that has been debited from
+// the user into suspense and accepted by the switch
+func createTestInterbankTransfer(t *testing.T, db *gorm.DB, user *models.User, reference, status string) *models.InterbankTransfer {
+	t.Helper()
+
+	transfer := models.InterbankTransfer{
+		Reference:                reference,
+		UserID:                   user.ID,
+		BeneficiaryBankCode:      "014",
+		BeneficiaryAccountNumber: "1234567890",
+		Amount:                   100000,
+		Fee:                      2500,
+		Status:                   status,
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		debitTxn, err := debitUserBalance(tx, user.ID, transfer.Amount, models.TRANSACTION_TYPE_INTERBANK_TRANSFER_OUT, "Interbank transfer")
+		if err != nil {
+			return err
+		}
+		feeTxn, err := debitUserBalance(tx, user.ID, transfer.Fee, models.TRANSACTION_TYPE_FEE, "Interbank transfer fee")
+		if err != nil {
+			return err
+		}
+		transfer.DebitTransactionID = &debitTxn.ID
+		transfer.FeeTransactionID = &feeTxn.ID
+		if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_INTERBANK_SUSPENSE, transfer.Amount+transfer.Fee,
+			reference, "Interbank transfer debited", &debitTxn.ID); err != nil {
+			return err
+		}
+		return tx.Create(&transfer).Error
+	})
+	if err != nil {
+		t.Fatalf("failed to create interbank transfer: %v", err)
+	}
+	return &transfer
+}
+
+func getTestInterbankTransfer(t *testing.T, db *gorm.DB, id uint) models.InterbankTransfer {
+	t.Helper()
+
+	var transfer models.InterbankTransfer
+	if err := db.First(&transfer, id).Error; err != nil {
+		t.Fatalf("failed to get interbank transfer %d: %v", id, err)
+	}
+	return transfer
+}
+
+func TestHandleCallback(t *testing.T) {
+	tests := []struct {
+		name           string
+		callback       utils.SwitchingCallback
+		badSignature   bool
+		userStatus     int
+		wantCode       int
+		wantStatus     string
+		wantBalance    int64
+		wantSuspense   int64
+		wantSettlement int64
+		wantFeeIncome  int64
+		wantUnclaimed  int64
+		wantRefundHeld bool
+	}{
+		{
+			name:     "success settles principal and fee",
+			callback: utils.SwitchingCallback{Status: utils.SWITCHING_STATUS_SUCCESS, SwitchReference: "SIM-1"},
+			wantCode: http.StatusOK, wantStatus: models.INTERBANK_STATUS_SUCCESS,
+			wantBalance: 397500, wantSettlement: 100000, wantFeeIncome: 2500,
+		},
+		{
+			name:     "failure refunds principal and fee",
+			callback: utils.SwitchingCallback{Status: utils.SWITCHING_STATUS_FAILED, FailureReason: "beneficiary account closed"},
+			wantCode: http.StatusOK, wantStatus: models.INTERBANK_STATUS_FAILED,
+			wantBalance: 500000,
+		},
+		{
+			name:       "failure for an inactive customer holds the refund",
+			callback:   utils.SwitchingCallback{Status: utils.SWITCHING_STATUS_FAILED, FailureReason: "beneficiary account closed"},
+			userStatus: models.USER_STATUS_BLOCKED,
+			wantCode:   http.StatusOK, wantStatus: models.INTERBANK_STATUS_FAILED,
+			wantBalance: 397500, wantUnclaimed: 102500, wantRefundHeld: true,
+		},
+		{
+			name:         "invalid signature changes nothing",
+			callback:     utils.SwitchingCallback{Status: utils.SWITCHING_STATUS_FAILED},
+			badSignature: true,
+			wantCode:     http.StatusUnauthorized, wantStatus: models.INTERBANK_STATUS_PROCESSING,
+			wantBalance: 397500, wantSuspense: 102500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.InterbankTransfer{})
+			h := NewInterbankHandler(db, utils.NewSimulatorSwitchingAdapter(testSwitchingSecret, time.Hour))
+			user := createTestUser(t, db, "sender", 500000)
+			transfer := createTestInterbankTransfer(t, db, user, "IBT-TEST", models.INTERBANK_STATUS_PROCESSING)
+			if tt.userStatus != 0 {
+				db.Model(user).Update("status", tt.userStatus)
+			}
+
+			tt.callback.Reference = transfer.Reference
+			payload, _ := json.Marshal(tt.callback)
+			signature := utils.SignSwitchingPayload(testSwitchingSecret, payload)
+			if tt.badSignature {
+				signature = utils.SignSwitchingPayload("other-secret", payload)
+			}
+
+			// A repeated callback must not settle or refund twice
+			for range 2 {
+				w := callInterbankHandler(h.HandleCallback, transfer.ID, payload, map[string]string{"X-Signature": signature})
+				if w.Code != tt.wantCode {
+					t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantCode, w.Body.String())
+				}
+			}
+
+			got := getTestInterbankTransfer(t, db, transfer.ID)
+			if got.Status != tt.wantStatus || got.RefundHeld != tt.wantRefundHeld {
+				t.Errorf("transfer: status %s refund_held %v, want %s %v", got.Status, got.RefundHeld, tt.wantStatus, tt.wantRefundHeld)
+			}
+			if got := userBalance(t, db, user.ID); got != tt.wantBalance {
+				t.Errorf("user balance = %d, want %d", got, tt.wantBalance)
+			}
+			for code, want := range map[string]int64{
+				models.SYSTEM_ACCOUNT_INTERBANK_SUSPENSE:   tt.wantSuspense,
+				models.SYSTEM_ACCOUNT_INTERBANK_SETTLEMENT: tt.wantSettlement,
+				models.SYSTEM_ACCOUNT_FEE_INCOME:           tt.wantFeeIncome,
+				models.SYSTEM_ACCOUNT_UNCLAIMED_REFUNDS:    tt.wantUnclaimed,
+			} {
+				if got := systemAccountBalance(t, db, code); got != want {
+					t.Errorf("%s balance = %d, want %d", code, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckTransferStatusUnknownKeepsFundsInSuspense(t *testing.T) {
+	db := newTestDB(t, &models.InterbankTransfer{})
+	h := NewInterbankHandler(db, utils.NewSimulatorSwitchingAdapter(testSwitchingSecret, time.Hour))
+	user := createTestUser(t, db, "sender", 500000)
+	transfer := createTestInterbankTransfer(t, db, user, "IBT-TEST", models.INTERBANK_STATUS_PROCESSING)
+
+	// The simulator has never seen this reference, so it reports the transfer as unknown
+	w := callInterbankHandler(h.CheckTransferStatus, transfer.ID, nil, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	got := getTestInterbankTransfer(t, db, transfer.ID)
+	if got.Status != models.INTERBANK_STATUS_SUSPECT || got.CompletedAt != nil || got.RefundTransactionID != nil {
+		t.Errorf("transfer: status %s completed_at %v refund %v, want suspect without refund",
+			got.Status, got.CompletedAt, got.RefundTransactionID)
+	}
+	if got := userBalance(t, db, user.ID); got != 397500 {
+		t.Errorf("user balance = %d, want 397500", got)
+	}
+	if got := systemAccountBalance(t, db, models.SYSTEM_ACCOUNT_INTERBANK_SUSPENSE); got != 102500 {
+		t.Errorf("interbank suspense = %d, want 102500", got)
+	}
+}
+
+func TestResolveTransfer(t *testing.T) {
+	tests := []struct {
+		name          string
+		initialStatus string
+		req           models.InterbankResolveRequest
+		wantCode      int
+		wantStatus    string
+		wantBalance   int64
+		wantSuspense  int64
+	}{
+		{
+			name: "failed refunds the customer", initialStatus: models.INTERBANK_STATUS_SUSPECT,
+			req:      models.InterbankResolveRequest{Status: utils.SWITCHING_STATUS_FAILED, Reason: "not received per switch report"},
+			wantCode: http.StatusOK, wantStatus: models.INTERBANK_STATUS_FAILED, wantBalance: 500000,
+		},
+		{
+			name: "success settles", initialStatus: models.INTERBANK_STATUS_SUSPECT,
+			req:      models.InterbankResolveRequest{Status: utils.SWITCHING_STATUS_SUCCESS, Reason: "credited per switch report"},
+			wantCode: http.StatusOK, wantStatus: models.INTERBANK_STATUS_SUCCESS, wantBalance: 397500,
+		},
+		{
+			name: "processing transfers are left to the switch", initialStatus: models.INTERBANK_STATUS_PROCESSING,
+			req:      models.InterbankResolveRequest{Status: utils.SWITCHING_STATUS_FAILED, Reason: "impatient"},
+			wantCode: http.StatusConflict, wantStatus: models.INTERBANK_STATUS_PROCESSING,
+			wantBalance: 397500, wantSuspense: 102500,
+		},
+		{
+			name: "reason is required", initialStatus: models.INTERBANK_STATUS_SUSPECT,
+			req:      models.InterbankResolveRequest{Status: utils.SWITCHING_STATUS_FAILED},
+			wantCode: http.StatusBadRequest, wantStatus: models.INTERBANK_STATUS_SUSPECT,
+			wantBalance: 397500, wantSuspense: 102500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.InterbankTransfer{})
+			h := NewInterbankHandler(db, utils.NewSimulatorSwitchingAdapter(testSwitchingSecret, time.Hour))
+			user := createTestUser(t, db, "sender", 500000)
+			transfer := createTestInterbankTransfer(t, db, user, "IBT-TEST", tt.initialStatus)
+
+			body, _ := json.Marshal(tt.req)
+			w := callInterbankHandler(h.ResolveTransfer, transfer.ID, body, nil)
+			if w.Code != tt.wantCode {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+
+			got := getTestInterbankTransfer(t, db, transfer.ID)
+			if got.Status != tt.wantStatus {
+				t.Errorf("transfer status = %s, want %s", got.Status, tt.wantStatus)
+			}
+			if got := userBalance(t, db, user.ID); got != tt.wantBalance {
+				t.Errorf("user balance = %d, want %d", got, tt.wantBalance)
+			}
+			if got := systemAccountBalance(t, db, models.SYSTEM_ACCOUNT_INTERBANK_SUSPENSE); got != tt.wantSuspense {
+				t.Errorf("interbank suspense = %d, want %d", got, tt.wantSuspense)
+			}
+		})
+	}
+}
+
+func TestMarkTransferSuspectKeepsEarlierCallback(t *testing.T) {
+	db := newTestDB(t, &models.InterbankTransfer{})
+	h := NewInterbankHandler(db, utils.NewSimulatorSwitchingAdapter(testSwitchingSecret, time.Hour))
+	user := createTestUser(t, db, "sender", 500000)
+	pending := createTestInterbankTransfer(t, db, user, "IBT-PENDING", models.INTERBANK_STATUS_PENDING)
+	settled := createTestInterbankTransfer(t, db, user, "IBT-SETTLED", models.INTERBANK_STATUS_PENDING)
+
+	// The callback for the second transfer arrives before the send times out
+	if _, err := h.applySwitchingResult(settled.Reference, "SIM-2", utils.SWITCHING_STATUS_SUCCESS, ""); err != nil {
+		t.Fatalf("failed to apply callback: %v", err)
+	}
+
+	now := time.Now()
+	h.markTransferSuspect(pending, "", "switch timeout", now)
+	h.markTransferSuspect(settled, "", "switch timeout", now)
+
+	if got := getTestInterbankTransfer(t, db, pending.ID); got.Status != models.INTERBANK_STATUS_SUSPECT || got.FailureReason != "switch timeout" {
+		t.Errorf("pending transfer: status %s reason %q, want suspect %q", got.Status, got.FailureReason, "switch timeout")
+	}
+	if got := getTestInterbankTransfer(t, db, settled.ID); got.Status != models.INTERBANK_STATUS_SUCCESS || got.FailureReason != "" {
+		t.Errorf("settled transfer: status %s reason %q, want success", got.Status, got.FailureReason)
+	}
+}
@@ -0,0 +1,837 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mbankingcore/models"
+	"mbankingcore/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InterestHandler struct {
+	DB *gorm.DB
+}
+
+func NewInterestHandler(db *gorm.DB) *InterestHandler {
+	return &InterestHandler{DB: db}
+}
+
+// InterestAccrualRunResult summarises one daily accrual run
+type InterestAccrualRunResult struct {
+	AccrualDate string `json:"accrual_date"`
+	Accrued     int    `json:"accrued"`
+	Skipped     int    `json:"skipped"` // Already accrued, no product or no interest-bearing balance
+	Failed      int    `json:"failed"`
+}
+
+// InterestCapitalizationRunResult summarises one monthly capitalization run
+type InterestCapitalizationRunResult struct {
+	Period          string                          `json:"period"`
+	Capitalized     int                             `json:"capitalized"`
+	Failed          int                             `json:"failed"`
+	Capitalizations []models.InterestCapitalization `json:"capitalizations"`
+}
+
+// RunDailyAccrual writes one accrual per user holding an active saving account, based on the
+// end-of-day balance of accrualDate. Users already accrued for the date are skipped, so the run
+// can be repeated safely.
+func (h *InterestHandler) RunDailyAccrual(accrualDate time.Time) (*InterestAccrualRunResult, error) {
+	start := time.Date(accrualDate.Year(), accrualDate.Month(), accrualDate.Day(), 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 0, 1)
+
+	var accounts []models.BankAccount
+	if err := h.DB.Where("account_type = ? AND is_active = ? AND created_at < ?", "saving", true, end).
+		Order("user_id ASC, is_primary DESC, created_at ASC").
+		Find(&accounts).Error; err != nil {
+		return nil, fmt.Errorf("failed to find saving accounts: %v", err)
+	}
+
+	defaultProduct, err := h.getDefaultProduct()
+	if err != nil {
+		return nil, err
+	}
+
+	products := map[uint]*models.InterestProduct{}
+	result := &InterestAccrualRunResult{AccrualDate: start.Format("2006-01-02")}
+
+	seen := map[uint]bool{}
+	for _, account := range accounts {
+		// Interest is paid on the user's balance once, through their primary (or oldest) saving account
+		if seen[account.UserID] {
+			continue
+		}
+		seen[account.UserID] = true
+
+		product := defaultProduct
+		if account.InterestProductID != nil {
+			if products[*account.InterestProductID] == nil {
+				var assigned models.InterestProduct
+				if err := h.DB.Preload("Tiers").Where("is_active = ?", true).First(&assigned, *account.InterestProductID).Error; err == nil {
+					products[assigned.ID] = &assigned
+				}
+			}
+			if assigned := products[*account.InterestProductID]; assigned != nil {
+				product = assigned
+			}
+		}
+		if product == nil {
+			result.Skipped++
+			continue
+		}
+
+		accrued, err := h.accrueUser(account, product, start, end)
+		if err != nil {
+			log.Printf("Interest accrual for user %d on %s failed: %v", account.UserID, result.AccrualDate, err)
+			result.Failed++
+		} else if accrued {
+			result.Accrued++
+		} else {
+			result.Skipped++
+		}
+	}
+
+	return result, nil
+}
+
+// accrueUser writes the accrual of one user and day. It returns false when nothing was written.
+func (h *InterestHandler) accrueUser(account models.BankAccount, product *models.InterestProduct, start, end time.Time) (bool, error) {
+	balance, err := h.balanceAsOf(account.UserID, end)
+	if err != nil {
+		return false, err
+	}
+	if balance <= 0 {
+		return false, nil
+	}
+
+	rate := product.RateFor(balance)
+	if rate <= 0 {
+		return false, nil
+	}
+
+	accrual := models.InterestAccrual{
+		UserID:          account.UserID,
+		AccrualDate:     start,
+		BankAccountID:   account.ID,
+		ProductID:       product.ID,
+		Balance:         balance,
+		RateBasisPoints: rate,
+		DayCountBasis:   product.DayCountBasis,
+		AmountMicros:    dailyInterestMicros(balance, rate, product.DayCountBasis, start),
+	}
+
+	created := h.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&accrual)
+	if created.Error != nil {
+		return false, fmt.Errorf("failed to create accrual: %v", created.Error)
+	}
+	return created.RowsAffected > 0, nil
+}
+
+// balanceAsOf returns the base currency balance of a user at the given instant, derived from the
+// transaction history so that a late run still uses the balance of the accrual date
+func (h *InterestHandler) balanceAsOf(userID uint, at time.Time) (int64, error) {
+	baseCurrency := "currency = ? OR currency = '' OR currency IS NULL"
+
+	var last models.Transaction
+	found := h.DB.Where("user_id = ? AND created_at < ?", userID, at).Where(baseCurrency, models.BASE_CURRENCY).
+		Order("created_at DESC, id DESC").Limit(1).Find(&last)
+	if found.Error != nil {
+		return 0, fmt.Errorf("failed to get balance history: %v", found.Error)
+	}
+	if found.RowsAffected > 0 {
+		return last.BalanceAfter, nil
+	}
+
+	var next models.Transaction
+	found = h.DB.Where("user_id = ? AND created_at >= ?", userID, at).Where(baseCurrency, models.BASE_CURRENCY).
+		Order("created_at ASC, id ASC").Limit(1).Find(&next)
+	if found.Error != nil {
+		return 0, fmt.Errorf("failed to get balance history: %v", found.Error)
+	}
+	if found.RowsAffected > 0 {
+		return next.BalanceBefore, nil
+	}
+
+	var user models.User
+	if err := h.DB.Select("id", "balance").First(&user, userID).Error; err != nil {
+		return 0, fmt.Errorf("failed to get user: %v", err)
+	}
+	return user.Balance, nil
+}
+
+// dailyInterestMicros calculates one day of interest in micro units, rounding down
+func dailyInterestMicros(balance int64, rateBasisPoints int, dayCountBasis string, day time.Time) int64 {
+	daysInYear := int64(365)
+	switch dayCountBasis {
+	case models.DAY_COUNT_ACT_360:
+		daysInYear = 360
+	case models.DAY_COUNT_ACT_ACT:
+		firstDay := time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		daysInYear = int64(firstDay.AddDate(1, 0, 0).Sub(firstDay).Hours() / 24)
+	}
+
+	amount := new(big.Int).Mul(big.NewInt(balance), big.NewInt(int64(rateBasisPoints)))
+	amount.Mul(amount, big.NewInt(models.INTEREST_MICRO_UNITS))
+	amount.Quo(amount, big.NewInt(10000*daysInYear))
+	return amount.Int64()
+}
+
+// RunMonthlyCapitalization credits each user's uncapitalized accruals of a month as one interest
+// transaction and withholds tax as a separate transaction. Users with a completed capitalization
+// for the period are skipped and failed users are retried on the next run.
+func (h *InterestHandler) RunMonthlyCapitalization(period time.Time) (*InterestCapitalizationRunResult, error) {
+	start := time.Date(period.Year(), period.Month(), 1, 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 1, 0)
+
+	var userIDs []uint
+	if err := h.DB.Model(&models.InterestAccrual{}).
+		Where("capitalization_id IS NULL AND accrual_date >= ? AND accrual_date < ?", start, end).
+		Distinct().Pluck("user_id", &userIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to find uncapitalized accruals: %v", err)
+	}
+
+	result := &InterestCapitalizationRunResult{
+		Period:          start.Format("2006-01"),
+		Capitalizations: []models.InterestCapitalization{},
+	}
+
+	for _, userID := range userIDs {
+		capitalization, err := h.capitalizeUser(userID, result.Period, start, end)
+		if err != nil {
+			log.Printf("Interest capitalization for user %d in %s failed: %v", userID, result.Period, err)
+			capitalization = h.recordFailedCapitalization(userID, result.Period, err.Error())
+			result.Failed++
+		} else if capitalization == nil {
+			continue
+		} else {
+			result.Capitalized++
+		}
+		if capitalization != nil {
+			result.Capitalizations = append(result.Capitalizations, *capitalization)
+		}
+	}
+
+	return result, nil
+}
+
+// capitalizeUser capitalizes one user's month in a single database transaction.
+// It returns nil without error when there is nothing left to capitalize.
+func (h *InterestHandler) capitalizeUser(userID uint, period string, start, end time.Time) (*models.InterestCapitalization, error) {
+	tx := h.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var capitalization models.InterestCapitalization
+	existing := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND period = ?", userID, period).
+		Limit(1).Find(&capitalization)
+	if existing.Error != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to get capitalization: %v", existing.Error)
+	}
+	if existing.RowsAffected > 0 && capitalization.Status == models.INTEREST_CAPITALIZATION_COMPLETED {
+		tx.Rollback()
+		return nil, nil
+	}
+
+	var accruals []models.InterestAccrual
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND capitalization_id IS NULL AND accrual_date >= ? AND accrual_date < ?", userID, start, end).
+		Order("accrual_date ASC").
+		Find(&accruals).Error; err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to lock accruals: %v", err)
+	}
+	if len(accruals) == 0 {
+		tx.Rollback()
+		return nil, nil
+	}
+
+	var totalMicros int64
+	accrualIDs := make([]uint, 0, len(accruals))
+	for _, accrual := range accruals {
+		totalMicros += accrual.AmountMicros
+		accrualIDs = append(accrualIDs, accrual.ID)
+	}
+
+	// Tax follows the product of the latest accrual in the period
+	var product models.InterestProduct
+	if err := tx.Unscoped().First(&product, accruals[len(accruals)-1].ProductID).Error; err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to get interest product: %v", err)
+	}
+
+	grossInterest := totalMicros / models.INTEREST_MICRO_UNITS
+	taxAmount := grossInterest * int64(product.TaxRateBasisPoints) / 10000
+
+	capitalization.UserID = userID
+	capitalization.Period = period
+	capitalization.AccrualCount = len(accruals)
+	capitalization.GrossInterest = grossInterest
+	capitalization.TaxAmount = taxAmount
+	capitalization.NetInterest = grossInterest - taxAmount
+
+	reference := utils.GenerateReference("INT")
+	var interestTxnID, taxTxnID *uint
+	if grossInterest > 0 {
+		interestTxn, err := creditUserBalance(tx, userID, grossInterest, "interest",
+			fmt.Sprintf("Savings interest %s", period))
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		interestTxnID = &interestTxn.ID
+
+		if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_INTEREST_EXPENSE, -grossInterest, reference,
+			fmt.Sprintf("Savings interest %s user %d", period, userID), interestTxnID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	if taxAmount > 0 {
+		taxTxn, err := debitUserBalance(tx, userID, taxAmount, "withholding_tax",
+			fmt.Sprintf("Withholding tax on savings interest %s", period))
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		taxTxnID = &taxTxn.ID
+
+		if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_TAX_PAYABLE, taxAmount, reference,
+			fmt.Sprintf("Withholding tax %s user %d", period, userID), taxTxnID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	capitalization.Status = models.INTEREST_CAPITALIZATION_COMPLETED
+	capitalization.FailureReason = ""
+	capitalization.InterestTransactionID = interestTxnID
+	capitalization.TaxTransactionID = taxTxnID
+	capitalization.CapitalizedAt = &now
+	if err := tx.Save(&capitalization).Error; err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to save capitalization: %v", err)
+	}
+
+	if err := tx.Model(&models.InterestAccrual{}).Where("id IN ?", accrualIDs).
+		Update("capitalization_id", capitalization.ID).Error; err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to mark accruals capitalized: %v", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("failed to commit capitalization: %v", err)
+	}
+
+	return &capitalization, nil
+}
+
+// recordFailedCapitalization stores the failure reason so the run can be inspected and retried
+func (h *InterestHandler) recordFailedCapitalization(userID uint, period, reason string) *models.InterestCapitalization {
+	var capitalization models.InterestCapitalization
+	if err := h.DB.Where("user_id = ? AND period = ?", userID, period).First(&capitalization).Error; err != nil {
+		capitalization = models.InterestCapitalization{UserID: userID, Period: period}
+	}
+
+	capitalization.Status = models.INTEREST_CAPITALIZATION_FAILED
+	capitalization.FailureReason = reason
+	if err := h.DB.Save(&capitalization).Error; err != nil {
+		log.Printf("Failed to record failed interest capitalization for user %d: %v", userID, err)
+		return nil
+	}
+	return &capitalization
+}
+
+// getDefaultProduct returns the active default product, or nil when none is configured
+func (h *InterestHandler) getDefaultProduct() (*models.InterestProduct, error) {
+	var product models.InterestProduct
+	found := h.DB.Preload("Tiers").Where("is_default = ? AND is_active = ?", true, true).Limit(1).Find(&product)
+	if found.Error != nil {
+		return nil, fmt.Errorf("failed to get default interest product: %v", found.Error)
+	}
+	if found.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &product, nil
+}
+
+// GetUserInterest - Get accrued and credited interest for the authenticated user
+func (h *InterestHandler) GetUserInterest(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	var pendingMicros int64
+	h.DB.Model(&models.InterestAccrual{}).
+		Where("user_id = ? AND capitalization_id IS NULL", userID).
+		Select("COALESCE(SUM(amount_micros), 0)").Scan(&pendingMicros)
+
+	var accruals []models.InterestAccrual
+	if err := h.DB.Where("user_id = ?", userID).Order("accrual_date DESC").Limit(31).Find(&accruals).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch interest accruals",
+		})
+		return
+	}
+
+	var capitalizations []models.InterestCapitalization
+	if err := h.DB.Where("user_id = ? AND status = ?", userID, models.INTEREST_CAPITALIZATION_COMPLETED).
+		Order("period DESC").Limit(12).Find(&capitalizations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch interest capitalizations",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Interest retrieved successfully",
+		Data: gin.H{
+			"pending_interest": pendingMicros / models.INTEREST_MICRO_UNITS,
+			"recent_accruals":  accruals,
+			"capitalizations":  capitalizations,
+		},
+	})
+}
+
+// GetProducts - List interest products (admin)
+func (h *InterestHandler) GetProducts(c *gin.Context) {
+	var products []models.InterestProduct
+	if err := h.DB.Preload("Tiers", func(db *gorm.DB) *gorm.DB {
+		return db.Order("min_balance ASC")
+	}).Order("code ASC").Find(&products).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch interest products",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Interest products retrieved successfully",
+		Data:    products,
+	})
+}
+
+// CreateProduct - Create an interest product with its tiers (admin)
+func (h *InterestHandler) CreateProduct(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+
+	var req models.InterestProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var count int64
+	h.DB.Model(&models.InterestProduct{}).Where("code = ?", req.Code).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "Interest product code already exists",
+		})
+		return
+	}
+
+	product := models.InterestProduct{Code: req.Code, IsActive: true}
+	applyInterestProductRequest(&product, &req)
+
+	tx := h.DB.WithContext(c).Begin()
+	if err := tx.Create(&product).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create interest product",
+		})
+		return
+	}
+	if product.IsDefault {
+		if err := tx.Model(&models.InterestProduct{}).Where("id <> ?", product.ID).Update("is_default", false).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to update default interest product",
+			})
+			return
+		}
+	}
+	tx.Commit()
+
+	createAuditLog(h.DB, c, "interest_product", product.ID, adminID.(uint), "CREATE", map[string]interface{}{
+		"code":  product.Code,
+		"tiers": product.Tiers,
+	})
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Code:    http.StatusCreated,
+		Message: "Interest product created successfully",
+		Data:    product,
+	})
+}
+
+// UpdateProduct - Update an interest product, replacing its tiers (admin).
+// New rates apply from the next accrual; past accruals keep the rate they were calculated with.
+func (h *InterestHandler) UpdateProduct(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+
+	var product models.InterestProduct
+	if err := h.DB.Preload("Tiers").First(&product, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Interest product not found",
+		})
+		return
+	}
+
+	var req models.InterestProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+	if req.Code != product.Code {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Interest product code cannot be changed",
+		})
+		return
+	}
+
+	oldTiers := product.Tiers
+	applyInterestProductRequest(&product, &req)
+
+	tx := h.DB.WithContext(c).Begin()
+	if err := tx.Where("product_id = ?", product.ID).Delete(&models.InterestTier{}).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to replace interest tiers",
+		})
+		return
+	}
+	if err := tx.Session(&gorm.Session{FullSaveAssociations: true}).Save(&product).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update interest product",
+		})
+		return
+	}
+	if product.IsDefault {
+		if err := tx.Model(&models.InterestProduct{}).Where("id <> ?", product.ID).Update("is_default", false).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to update default interest product",
+			})
+			return
+		}
+	}
+	tx.Commit()
+
+	createAuditLog(h.DB, c, "interest_product", product.ID, adminID.(uint), "UPDATE", map[string]interface{}{
+		"code":      product.Code,
+		"old_tiers": oldTiers,
+		"new_tiers": product.Tiers,
+		"is_active": product.IsActive,
+	})
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Interest product updated successfully",
+		Data:    product,
+	})
+}
+
+// applyInterestProductRequest copies a product request onto a product, rebuilding its tiers
+func applyInterestProductRequest(product *models.InterestProduct, req *models.InterestProductRequest) {
+	product.Name = req.Name
+	product.DayCountBasis = req.DayCountBasis
+	product.TaxRateBasisPoints = req.TaxRateBasisPoints
+	product.IsDefault = req.IsDefault
+	if req.IsActive != nil {
+		product.IsActive = *req.IsActive
+	}
+
+	product.Tiers = make([]models.InterestTier, 0, len(req.Tiers))
+	for _, tier := range req.Tiers {
+		product.Tiers = append(product.Tiers, models.InterestTier{
+			MinBalance:      tier.MinBalance,
+			RateBasisPoints: tier.RateBasisPoints,
+		})
+	}
+}
+
+// AssignProduct - Link a saving account to an interest product (admin)
+func (h *InterestHandler) AssignProduct(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+
+	var account models.BankAccount
+	if err := h.DB.First(&account, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Bank account not found",
+		})
+		return
+	}
+	if account.AccountType != "saving" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Interest products can only be assigned to saving accounts",
+		})
+		return
+	}
+
+	var req models.AssignInterestProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if req.ProductID != nil {
+		var product models.InterestProduct
+		if err := h.DB.Where("is_active = ?", true).First(&product, *req.ProductID).Error; err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Interest product not found or inactive",
+			})
+			return
+		}
+	}
+
+	oldProductID := account.InterestProductID
+	if err := h.DB.WithContext(c).Model(&account).Update("interest_product_id", req.ProductID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to assign interest product",
+		})
+		return
+	}
+	account.InterestProductID = req.ProductID
+
+	createAuditLog(h.DB, c, "bank_account", account.ID, adminID.(uint), "ASSIGN_PRODUCT", map[string]interface{}{
+		"bank_account_id": account.ID,
+		"old_product_id":  oldProductID,
+		"new_product_id":  req.ProductID,
+	})
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Interest product assigned successfully",
+		Data:    account,
+	})
+}
+
+// RunAccrual - Accrue interest for a date (admin)
+func (h *InterestHandler) RunAccrual(c *gin.Context) {
+	var req models.RunInterestAccrualRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	accrualDate := time.Now().AddDate(0, 0, -1)
+	if req.AccrualDate != "" {
+		accrualDate, _ = time.ParseInLocation("2006-01-02", req.AccrualDate, time.Local)
+	}
+
+	result, err := h.RunDailyAccrual(accrualDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Interest accrual completed",
+		Data:    result,
+	})
+}
+
+// RunCapitalization - Capitalize accrued interest for a month (admin)
+func (h *InterestHandler) RunCapitalization(c *gin.Context) {
+	var req models.RunInterestCapitalizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	now := time.Now()
+	period := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local).AddDate(0, -1, 0)
+	if req.Period != "" {
+		period, _ = time.ParseInLocation("2006-01", req.Period, time.Local)
+	}
+
+	result, err := h.RunMonthlyCapitalization(period)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Interest capitalization completed",
+		Data:    result,
+	})
+}
+
+// GetAccruals - List interest accruals (admin)
+func (h *InterestHandler) GetAccruals(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.InterestAccrual{})
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if date := c.Query("accrual_date"); date != "" {
+		query = query.Where("accrual_date = ?", date)
+	}
+	if c.Query("capitalized") == "false" {
+		query = query.Where("capitalization_id IS NULL")
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var accruals []models.InterestAccrual
+	if err := query.Order("accrual_date DESC, id DESC").Limit(limit).Offset(offset).Find(&accruals).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch interest accruals",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Interest accruals retrieved successfully",
+		Data: gin.H{
+			"accruals": accruals,
+			"pagination": gin.H{
+				"page":        page,
+				"limit":       limit,
+				"total":       total,
+				"total_pages": int(math.Ceil(float64(total) / float64(limit))),
+			},
+		},
+	})
+}
+
+// GetCapitalizations - List interest capitalizations (admin)
+func (h *InterestHandler) GetCapitalizations(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.InterestCapitalization{})
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if period := c.Query("period"); period != "" {
+		query = query.Where("period = ?", period)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var capitalizations []models.InterestCapitalization
+	if err := query.Order("period DESC, id DESC").Limit(limit).Offset(offset).Find(&capitalizations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch interest capitalizations",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Interest capitalizations retrieved successfully",
+		Data: gin.H{
+			"capitalizations": capitalizations,
+			"pagination": gin.H{
+				"page":        page,
+				"limit":       limit,
+				"total":       total,
+				"total_pages": int(math.Ceil(float64(total) / float64(limit))),
+			},
+		},
+	})
+}
@@ -0,0 +1,765 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"mbankingcore/models"
+	"mbankingcore/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const maxISO20022Transactions = 10000
+
+type ISO20022Handler struct {
+	DB           *gorm.DB
+	Disbursement *DisbursementHandler
+}
+
+func NewISO20022Handler(db *gorm.DB, disbursementHandler *DisbursementHandler) *ISO20022Handler {
+	return &ISO20022Handler{DB: db, Disbursement: disbursementHandler}
+}
+
+// ImportPain001 - Upload a pain.001 customer credit transfer initiation as a bulk disbursement (maker)
+// Every CdtTrfTxInf becomes a disbursement row and the batch follows the normal checker-maker flow.
+func (h *ISO20022Handler) ImportPain001(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "pain.001 XML file is required (form field 'file')",
+		})
+		return
+	}
+
+	if fileHeader.Size > maxDisbursementFileSize {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("File is too large (max %d bytes)", maxDisbursementFileSize),
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Failed to read uploaded file",
+		})
+		return
+	}
+	defer file.Close()
+
+	payload, err := io.ReadAll(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Failed to read uploaded file",
+		})
+		return
+	}
+
+	document, validationErrors := utils.DecodePain001(payload)
+	if len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Code:    http.StatusBadRequest,
+			Message: "pain.001 schema validation failed",
+			Data:    gin.H{"errors": validationErrors},
+		})
+		return
+	}
+
+	header := document.Initiate.GroupHeader
+	var existing int64
+	h.DB.Model(&models.ISO20022Message{}).
+		Where("message_id = ? AND direction = ?", header.MessageID, models.ISO20022_DIRECTION_INBOUND).
+		Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "pain.001 message " + header.MessageID + " has already been imported",
+		})
+		return
+	}
+
+	// Flatten all payment instructions into disbursement rows
+	rows := [][]string{}
+	transfers := []utils.Pain001CreditTransferInfo{}
+	for _, payment := range document.Initiate.PaymentInfo {
+		for _, transfer := range payment.CreditTransfers {
+			amount := transfer.Amount.InstructedAmount.Value
+			if parsed, err := utils.ParseISO20022Amount(amount); err == nil {
+				amount = strconv.FormatInt(parsed, 10)
+			}
+
+			description := ""
+			if transfer.Remittance != nil {
+				description = transfer.Remittance.Unstructured
+			}
+
+			rows = append(rows, []string{transfer.CreditorAccount.AccountNumber(), amount, description})
+			transfers = append(transfers, transfer)
+		}
+	}
+
+	if len(rows) > maxDisbursementRows {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Too many transactions (max %d)", maxDisbursementRows),
+		})
+		return
+	}
+
+	items, err := h.Disbursement.validateDisbursementRows(rows)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to validate pain.001 transactions",
+		})
+		return
+	}
+
+	bankBIC := iso20022BankBIC()
+	var controlSum int64
+	for i, transfer := range transfers {
+		items[i].EndToEndID = transfer.PaymentID.EndToEndID
+		if items[i].Status != models.DISBURSEMENT_ITEM_VALID {
+			continue
+		}
+
+		// Only IDR credits to accounts held at this bank can be executed
+		currency := transfer.Amount.InstructedAmount.Currency
+		creditorBIC := ""
+		if transfer.CreditorAgent != nil {
+			creditorBIC = transfer.CreditorAgent.FinInstnID.BICFI
+		}
+		switch {
+		case currency != "IDR":
+			items[i].Status = models.DISBURSEMENT_ITEM_INVALID
+			items[i].FailureReason = "Currency " + currency + " is not supported"
+			items[i].UserID = nil
+		case creditorBIC != "" && creditorBIC != bankBIC:
+			items[i].Status = models.DISBURSEMENT_ITEM_INVALID
+			items[i].FailureReason = "Creditor agent " + creditorBIC + " is not this bank"
+			items[i].UserID = nil
+		default:
+			controlSum += items[i].Amount
+		}
+	}
+
+	description := c.PostForm("description")
+	if description == "" {
+		description = strings.TrimSpace("pain.001 " + header.MessageID + " " + header.InitiatingParty.Name)
+	}
+
+	batch := models.DisbursementBatch{
+		BatchRef:        utils.GenerateReference("DSB"),
+		FileName:        fileHeader.Filename,
+		Description:     description,
+		SourceFormat:    models.DISBURSEMENT_SOURCE_PAIN001,
+		SourceMessageID: header.MessageID,
+		MakerAdminID:    adminID.(uint),
+	}
+
+	h.Disbursement.submitBatch(c, &batch, items)
+
+	if batch.ID == 0 {
+		return
+	}
+
+	message := models.ISO20022Message{
+		MessageID:   header.MessageID,
+		MessageType: utils.ISO20022_PAIN001,
+		Direction:   models.ISO20022_DIRECTION_INBOUND,
+		NumberOfTxs: len(transfers),
+		ControlSum:  controlSum,
+		BatchID:     &batch.ID,
+		AdminID:     adminID.(uint),
+		Payload:     string(payload),
+	}
+	if err := h.DB.Create(&message).Error; err != nil {
+		log.Printf("Failed to store pain.001 message %s: %v", header.MessageID, err)
+	}
+}
+
+// ExportPacs008 - Export outgoing transfers as a pacs.008 FI to FI customer credit transfer
+// Query: transaction_ids (comma separated) or from/to dates (YYYY-MM-DD, default today).
+func (h *ISO20022Handler) ExportPacs008(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+
+	query := h.DB.Model(&models.Transaction{}).
+		Where("type IN ? AND status IN ?", []string{"transfer_out", "interbank_transfer_out"},
+			[]string{models.TRANSACTION_STATUS_COMPLETED, models.TRANSACTION_STATUS_PARTIALLY_REVERSED})
+
+	if ids := c.Query("transaction_ids"); ids != "" {
+		transactionIDs := []uint{}
+		for _, part := range strings.Split(ids, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, models.ErrorResponse{
+					Code:    http.StatusBadRequest,
+					Message: "Invalid transaction ID: " + part,
+				})
+				return
+			}
+			transactionIDs = append(transactionIDs, uint(id))
+		}
+		query = query.Where("id IN ?", transactionIDs)
+	} else {
+		from, to, err := parseISO20022DateRange(c.Query("from"), c.Query("to"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			return
+		}
+		query = query.Where("created_at >= ? AND created_at < ?", from, to)
+	}
+
+	var transactions []models.Transaction
+	if err := query.Preload("User").Order("id ASC").Limit(maxISO20022Transactions + 1).Find(&transactions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch transactions",
+		})
+		return
+	}
+
+	if len(transactions) == 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "No outgoing transfers found",
+		})
+		return
+	}
+	if len(transactions) > maxISO20022Transactions {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Too many transactions (max %d), narrow the date range", maxISO20022Transactions),
+		})
+		return
+	}
+
+	bankBIC := iso20022BankBIC()
+	now := time.Now()
+	messageID := utils.NewISO20022MessageID("PACS008")
+
+	document := utils.Pacs008Document{Xmlns: utils.ISO20022Namespace(utils.ISO20022_PACS008)}
+	var total int64
+	for _, transaction := range transactions {
+		info, err := h.buildPacs008Transaction(transaction, bankBIC)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: fmt.Sprintf("Failed to build transaction #%d: %v", transaction.ID, err),
+			})
+			return
+		}
+		document.Transfer.CreditTransfers = append(document.Transfer.CreditTransfers, *info)
+		total += transaction.Amount
+	}
+
+	header := &document.Transfer.GroupHeader
+	header.MessageID = messageID
+	header.CreationDateTime = utils.FormatISO20022DateTime(now)
+	header.NumberOfTxs = strconv.Itoa(len(transactions))
+	header.TotalInterbankSettlementAmt = utils.ISO20022Amount{Currency: "IDR", Value: utils.FormatISO20022Amount(total)}
+	header.InterbankSettlementDate = now.Format("2006-01-02")
+	header.SettlementInfo.SettlementMethod = "CLRG"
+
+	// Never send a message we would not accept ourselves
+	if validationErrors := utils.ValidatePacs008(&document, document.Xmlns); len(validationErrors) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, models.APIResponse{
+			Code:    http.StatusUnprocessableEntity,
+			Message: "Generated pacs.008 failed schema validation",
+			Data:    gin.H{"errors": validationErrors},
+		})
+		return
+	}
+
+	payload, err := utils.EncodeISO20022(document)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to encode pacs.008",
+		})
+		return
+	}
+
+	message := models.ISO20022Message{
+		MessageID:   messageID,
+		MessageType: utils.ISO20022_PACS008,
+		Direction:   models.ISO20022_DIRECTION_OUTBOUND,
+		NumberOfTxs: len(transactions),
+		ControlSum:  total,
+		AdminID:     adminID.(uint),
+		Payload:     string(payload),
+	}
+	if err := h.DB.Create(&message).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to store pacs.008 message",
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.xml", messageID))
+	c.Data(http.StatusOK, "application/xml", payload)
+}
+
+// GetMessages - List imported and generated ISO 20022 messages
+func (h *ISO20022Handler) GetMessages(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.ISO20022Message{})
+	if messageType := c.Query("message_type"); messageType != "" {
+		query = query.Where("message_type = ?", messageType)
+	}
+	if direction := c.Query("direction"); direction != "" {
+		query = query.Where("direction = ?", direction)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var messages []models.ISO20022Message
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&messages).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch ISO 20022 messages",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "ISO 20022 messages retrieved successfully",
+		Data: gin.H{
+			"messages": messages,
+			"pagination": gin.H{
+				"page":        page,
+				"limit":       limit,
+				"total":       total,
+				"total_pages": (total + int64(limit) - 1) / int64(limit),
+			},
+		},
+	})
+}
+
+// DownloadMessage - Download the XML payload of a stored message
+func (h *ISO20022Handler) DownloadMessage(c *gin.Context) {
+	message, ok := h.findMessage(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.xml", message.MessageID))
+	c.Data(http.StatusOK, "application/xml", []byte(message.Payload))
+}
+
+// GenerateStatusReport - Generate a pacs.002 status report for an imported pain.001 or exported pacs.008
+func (h *ISO20022Handler) GenerateStatusReport(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+
+	original, ok := h.findMessage(c)
+	if !ok {
+		return
+	}
+
+	var statuses []utils.Pacs002TransactionStatus
+	var err error
+	switch {
+	case original.MessageType == utils.ISO20022_PAIN001 && original.BatchID != nil:
+		statuses, err = h.batchStatuses(*original.BatchID)
+	case original.MessageType == utils.ISO20022_PACS008:
+		statuses, err = h.transactionStatuses(original)
+	default:
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Status reports are only available for imported pain.001 and exported pacs.008 messages",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to collect transaction statuses: " + err.Error(),
+		})
+		return
+	}
+
+	now := time.Now()
+	messageID := utils.NewISO20022MessageID("PACS002")
+
+	document := utils.Pacs002Document{Xmlns: utils.ISO20022Namespace(utils.ISO20022_PACS002)}
+	document.Report.GroupHeader = utils.Pacs002GroupHeader{
+		MessageID:        messageID,
+		CreationDateTime: utils.FormatISO20022DateTime(now),
+	}
+	document.Report.OriginalGroupInfo = utils.Pacs002OriginalGroupInfo{
+		OriginalMessageID:     original.MessageID,
+		OriginalMessageNameID: original.MessageType,
+		OriginalNumberOfTxs:   strconv.Itoa(original.NumberOfTxs),
+		GroupStatus:           iso20022GroupStatus(statuses),
+	}
+	document.Report.TransactionStatuses = statuses
+
+	payload, err := utils.EncodeISO20022(document)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to encode pacs.002",
+		})
+		return
+	}
+
+	message := models.ISO20022Message{
+		MessageID:         messageID,
+		MessageType:       utils.ISO20022_PACS002,
+		Direction:         models.ISO20022_DIRECTION_OUTBOUND,
+		NumberOfTxs:       len(statuses),
+		OriginalMessageID: original.MessageID,
+		BatchID:           original.BatchID,
+		AdminID:           adminID.(uint),
+		Payload:           string(payload),
+	}
+	if err := h.DB.Create(&message).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to store pacs.002 message",
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.xml", messageID))
+	c.Data(http.StatusOK, "application/xml", payload)
+}
+
+// findMessage loads the message referenced by the :id path parameter, writing an error response if missing
+func (h *ISO20022Handler) findMessage(c *gin.Context) (*models.ISO20022Message, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid message ID",
+		})
+		return nil, false
+	}
+
+	var message models.ISO20022Message
+	if err := h.DB.First(&message, uint(id)).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "ISO 20022 message not found",
+		})
+		return nil, false
+	}
+	return &message, true
+}
+
+// buildPacs008Transaction maps an outgoing transfer to a CdtTrfTxInf
+func (h *ISO20022Handler) buildPacs008Transaction(transaction models.Transaction, bankBIC string) (*utils.Pacs008CreditTransferInfo, error) {
+	transactionRef := iso20022TransactionRef(transaction.ID)
+
+	info := utils.Pacs008CreditTransferInfo{
+		PaymentID: utils.ISO20022PaymentID{
+			InstructionID: transactionRef,
+			EndToEndID:    transactionRef,
+			TransactionID: transactionRef,
+		},
+		InterbankSettlementAmt: utils.ISO20022Amount{Currency: "IDR", Value: utils.FormatISO20022Amount(transaction.Amount)},
+		ChargeBearer:           "SLEV",
+		Debtor:                 utils.ISO20022Party{Name: transaction.User.Name},
+		DebtorAccount:          iso20022OtherAccount(h.primaryAccountNumber(transaction.UserID)),
+		DebtorAgent:            utils.ISO20022Agent{FinInstnID: utils.ISO20022FinancialInstitution{BICFI: bankBIC}},
+	}
+	if transaction.Description != "" {
+		info.Remittance = &utils.ISO20022Remittance{Unstructured: transaction.Description}
+	}
+
+	if transaction.Type == "interbank_transfer_out" {
+		var transfer models.InterbankTransfer
+		if err := h.DB.Where("debit_transaction_id = ?", transaction.ID).First(&transfer).Error; err != nil {
+			return nil, fmt.Errorf("interbank transfer not found")
+		}
+
+		info.PaymentID.EndToEndID = transfer.Reference
+		info.CreditorAgent = utils.ISO20022Agent{FinInstnID: utils.ISO20022FinancialInstitution{
+			ClearingMemberID: &utils.ISO20022ClearingMember{MemberID: transfer.BeneficiaryBankCode},
+		}}
+		info.Creditor = utils.ISO20022Party{Name: transfer.BeneficiaryAccountName}
+		info.CreditorAccount = iso20022OtherAccount(transfer.BeneficiaryAccountNumber)
+		return &info, nil
+	}
+
+	// Internal transfer: the credit leg is recorded in the same database transaction
+	var creditLeg models.Transaction
+	if err := h.DB.Preload("User").
+		Where("user_id != ? AND type = 'transfer_in' AND amount = ? AND created_at BETWEEN ? AND ?",
+			transaction.UserID, transaction.Amount,
+			transaction.CreatedAt.Add(-time.Second), transaction.CreatedAt.Add(time.Second)).
+		Order("id ASC").
+		First(&creditLeg).Error; err != nil {
+		return nil, fmt.Errorf("credit leg not found")
+	}
+
+	info.CreditorAgent = utils.ISO20022Agent{FinInstnID: utils.ISO20022FinancialInstitution{BICFI: bankBIC}}
+	info.Creditor = utils.ISO20022Party{Name: creditLeg.User.Name}
+	info.CreditorAccount = iso20022OtherAccount(h.primaryAccountNumber(creditLeg.UserID))
+	return &info, nil
+}
+
+// primaryAccountNumber returns the user's primary (or oldest active) account number
+func (h *ISO20022Handler) primaryAccountNumber(userID uint) string {
+	var account models.BankAccount
+	if err := h.DB.Where("user_id = ? AND is_active = ?", userID, true).
+		Order("is_primary DESC, created_at ASC").
+		First(&account).Error; err != nil {
+		return ""
+	}
+	return account.AccountNumber
+}
+
+// batchStatuses reports the outcome of every row of a disbursement batch imported from pain.001
+func (h *ISO20022Handler) batchStatuses(batchID uint) ([]utils.Pacs002TransactionStatus, error) {
+	var batch models.DisbursementBatch
+	if err := h.DB.First(&batch, batchID).Error; err != nil {
+		return nil, err
+	}
+
+	var items []models.DisbursementItem
+	if err := h.DB.Where("batch_id = ?", batchID).Order("row_number ASC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	statuses := make([]utils.Pacs002TransactionStatus, 0, len(items))
+	for _, item := range items {
+		status := utils.Pacs002TransactionStatus{OriginalEndToEndID: item.EndToEndID}
+
+		switch item.Status {
+		case models.DISBURSEMENT_ITEM_SUCCESS:
+			status.TransactionStatus = utils.ISO20022_STATUS_ACCEPTED_SETTLED
+			if item.ProcessedAt != nil {
+				status.AcceptanceDateTime = utils.FormatISO20022DateTime(*item.ProcessedAt)
+			}
+		case models.DISBURSEMENT_ITEM_VALID:
+			if batch.Status == models.DISBURSEMENT_STATUS_PROCESSING {
+				status.TransactionStatus = utils.ISO20022_STATUS_ACCEPTED
+			} else {
+				status.TransactionStatus = utils.ISO20022_STATUS_PENDING
+			}
+		case models.DISBURSEMENT_ITEM_SKIPPED:
+			status.TransactionStatus = utils.ISO20022_STATUS_REJECTED
+			status.StatusReason = utils.NewPacs002StatusReason("NARR", "Batch "+batch.Status)
+		default:
+			status.TransactionStatus = utils.ISO20022_STATUS_REJECTED
+			status.StatusReason = utils.NewPacs002StatusReason(iso20022ReasonCode(item.FailureReason), item.FailureReason)
+		}
+
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// transactionStatuses reports the current outcome of every transaction in an exported pacs.008
+func (h *ISO20022Handler) transactionStatuses(original *models.ISO20022Message) ([]utils.Pacs002TransactionStatus, error) {
+	document, validationErrors := utils.DecodePacs008([]byte(original.Payload))
+	if len(validationErrors) > 0 {
+		return nil, fmt.Errorf("stored pacs.008 is invalid: %s", strings.Join(validationErrors, "; "))
+	}
+
+	statuses := make([]utils.Pacs002TransactionStatus, 0, len(document.Transfer.CreditTransfers))
+	for _, transfer := range document.Transfer.CreditTransfers {
+		status := utils.Pacs002TransactionStatus{
+			OriginalInstructionID: transfer.PaymentID.InstructionID,
+			OriginalEndToEndID:    transfer.PaymentID.EndToEndID,
+			OriginalTransactionID: transfer.PaymentID.TransactionID,
+		}
+
+		var transaction models.Transaction
+		transactionID, ok := parseISO20022TransactionRef(transfer.PaymentID.TransactionID)
+		if !ok || h.DB.First(&transaction, transactionID).Error != nil {
+			status.TransactionStatus = utils.ISO20022_STATUS_REJECTED
+			status.StatusReason = utils.NewPacs002StatusReason("NARR", "Transaction not found")
+			statuses = append(statuses, status)
+			continue
+		}
+
+		switch {
+		case transaction.IsReversed:
+			status.TransactionStatus = utils.ISO20022_STATUS_REJECTED
+			status.StatusReason = utils.NewPacs002StatusReason("NARR", "Reversed: "+transaction.ReversalReason)
+		case transaction.Type == "interbank_transfer_out":
+			var interbank models.InterbankTransfer
+			if err := h.DB.Where("debit_transaction_id = ?", transaction.ID).First(&interbank).Error; err != nil {
+				return nil, err
+			}
+			switch interbank.Status {
+			case models.INTERBANK_STATUS_SUCCESS:
+				status.TransactionStatus = utils.ISO20022_STATUS_ACCEPTED_SETTLED
+				if interbank.CompletedAt != nil {
+					status.AcceptanceDateTime = utils.FormatISO20022DateTime(*interbank.CompletedAt)
+				}
+			case models.INTERBANK_STATUS_FAILED:
+				status.TransactionStatus = utils.ISO20022_STATUS_REJECTED
+				status.StatusReason = utils.NewPacs002StatusReason("NARR", interbank.FailureReason)
+			case models.INTERBANK_STATUS_PROCESSING:
+				status.TransactionStatus = utils.ISO20022_STATUS_ACCEPTED
+			default:
+				status.TransactionStatus = utils.ISO20022_STATUS_PENDING
+			}
+		case transaction.Status == models.TRANSACTION_STATUS_COMPLETED,
+			transaction.Status == models.TRANSACTION_STATUS_PARTIALLY_REVERSED:
+			status.TransactionStatus = utils.ISO20022_STATUS_ACCEPTED_SETTLED
+			status.AcceptanceDateTime = utils.FormatISO20022DateTime(transaction.CreatedAt)
+		case transaction.Status == models.TRANSACTION_STATUS_FAILED:
+			status.TransactionStatus = utils.ISO20022_STATUS_REJECTED
+			status.StatusReason = utils.NewPacs002StatusReason("NARR", "Transaction failed")
+		default:
+			status.TransactionStatus = utils.ISO20022_STATUS_PENDING
+		}
+
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// iso20022GroupStatus derives GrpSts from the individual transaction statuses
+func iso20022GroupStatus(statuses []utils.Pacs002TransactionStatus) string {
+	counts := map[string]int{}
+	for _, status := range statuses {
+		counts[status.TransactionStatus]++
+	}
+
+	switch {
+	case len(statuses) == 0:
+		return utils.ISO20022_STATUS_REJECTED
+	case counts[utils.ISO20022_STATUS_PENDING] > 0:
+		return utils.ISO20022_STATUS_PENDING
+	case counts[utils.ISO20022_STATUS_ACCEPTED_SETTLED] == len(statuses):
+		return utils.ISO20022_STATUS_ACCEPTED_SETTLED
+	case counts[utils.ISO20022_STATUS_REJECTED] == len(statuses):
+		return utils.ISO20022_STATUS_REJECTED
+	case counts[utils.ISO20022_STATUS_REJECTED] > 0:
+		return utils.ISO20022_STATUS_PARTIAL
+	default:
+		return utils.ISO20022_STATUS_ACCEPTED
+	}
+}
+
+// iso20022ReasonCode maps a disbursement failure reason to an ExternalStatusReason1Code
+func iso20022ReasonCode(reason string) string {
+	switch {
+	case strings.Contains(reason, "Account number not found"), strings.Contains(reason, "Account number is empty"):
+		return "AC01" // IncorrectAccountNumber
+	case strings.Contains(reason, "inactive"):
+		return "AC04" // ClosedAccountNumber
+	case strings.Contains(reason, "not active"):
+		return "AC06" // BlockedAccount
+	case strings.Contains(reason, "Duplicate"):
+		return "AM05" // Duplication
+	case strings.Contains(reason, "Amount"):
+		return "AM12" // InvalidAmount
+	case strings.Contains(reason, "Currency"):
+		return "AM11" // InvalidTransactionCurrency
+	case strings.Contains(reason, "Creditor agent"):
+		return "RC01" // BankIdentifierIncorrect
+	default:
+		return "NARR"
+	}
+}
+
+// iso20022BankBIC returns this bank's BIC used as debtor/creditor agent
+func iso20022BankBIC() string {
+	if bic := os.Getenv("ISO20022_BANK_BIC"); bic != "" {
+		return bic
+	}
+	return "MBCOIDJA"
+}
+
+func iso20022OtherAccount(accountNumber string) utils.ISO20022Account {
+	return utils.ISO20022Account{ID: utils.ISO20022AccountID{Other: &utils.ISO20022OtherAccountID{ID: accountNumber}}}
+}
+
+// iso20022TransactionRef is the InstrId/TxId used for a ledger transaction
+func iso20022TransactionRef(transactionID uint) string {
+	return fmt.Sprintf("TXN%010d", transactionID)
+}
+
+func parseISO20022TransactionRef(ref string) (uint, bool) {
+	if !strings.HasPrefix(ref, "TXN") {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(strings.TrimPrefix(ref, "TXN"), 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// parseISO20022DateRange parses an inclusive from/to date range, defaulting to today
+func parseISO20022DateRange(fromValue, toValue string) (time.Time, time.Time, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	from, to := today, today
+
+	if fromValue != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", fromValue, time.Local)
+		if err != nil {
+			return from, to, fmt.Errorf("invalid from date, expected YYYY-MM-DD")
+		}
+		from = parsed
+	}
+	if toValue != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", toValue, time.Local)
+		if err != nil {
+			return from, to, fmt.Errorf("invalid to date, expected YYYY-MM-DD")
+		}
+		to = parsed
+	}
+	if to.Before(from) {
+		return from, to, fmt.Errorf("to date must not be before from date")
+	}
+
+	return from, to.AddDate(0, 0, 1), nil
+}
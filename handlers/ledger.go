@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"mbankingcore/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errInsufficientBalance is returned when a debit would take the balance below zero
+var errInsufficientBalance = errors.New("insufficient balance")
+
+// errUserNotActive is returned when the target user cannot receive or send funds
+var errUserNotActive = errors.New("user account is not active")
+
+// errInvalidStatusTransition is returned when a status change is not allowed by the transaction state machine
+var errInvalidStatusTransition = errors.New("invalid transaction status transition")
+
+// creditUserBalance locks the user, adds amount to the balance and records the transaction.
+// It must be called inside a database transaction.
+func creditUserBalance(tx *gorm.DB, userID uint, amount int64, txnType, description string) (*models.Transaction, error) {
+	var user models.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get user: %v", err)
+	}
+
+	if user.Status != models.USER_STATUS_ACTIVE {
+		return nil, errUserNotActive
+	}
+
+	balanceBefore := user.Balance
+	balanceAfter := balanceBefore + amount
+
+	if err := tx.Model(&user).Update("balance", balanceAfter).Error; err != nil {
+		return nil, fmt.Errorf("failed to update balance: %v", err)
+	}
+
+	transaction := models.Transaction{
+		UserID:        user.ID,
+		Type:          txnType,
+		Amount:        amount,
+		BalanceBefore: balanceBefore,
+		BalanceAfter:  balanceAfter,
+		Description:   description,
+		Status:        models.TRANSACTION_STATUS_COMPLETED,
+	}
+
+	if err := tx.Create(&transaction).Error; err != nil {
+		return nil, fmt.Errorf("failed to create transaction record: %v", err)
+	}
+
+	return &transaction, nil
+}
+
+// debitUserBalance locks the user, deducts amount from the balance and records the transaction.
+// It must be called inside a database transaction.
+func debitUserBalance(tx *gorm.DB, userID uint, amount int64, txnType, description string) (*models.Transaction, error) {
+	return debitUserBalanceWithStatus(tx, userID, amount, txnType, description, models.TRANSACTION_STATUS_COMPLETED)
+}
+
+// debitUserBalanceWithStatus is debitUserBalance for debits that are not final yet, such as card
+// holds recorded as pending. It must be called inside a database transaction.
+func debitUserBalanceWithStatus(tx *gorm.DB, userID uint, amount int64, txnType, description, status string) (*models.Transaction, error) {
+	var user models.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get user: %v", err)
+	}
+
+	if user.Status != models.USER_STATUS_ACTIVE {
+		return nil, errUserNotActive
+	}
+
+	if user.Balance < amount {
+		return nil, errInsufficientBalance
+	}
+
+	balanceBefore := user.Balance
+	balanceAfter := balanceBefore - amount
+
+	if err := tx.Model(&user).Update("balance", balanceAfter).Error; err != nil {
+		return nil, fmt.Errorf("failed to update balance: %v", err)
+	}
+
+	transaction := models.Transaction{
+		UserID:        user.ID,
+		Type:          txnType,
+		Amount:        amount,
+		BalanceBefore: balanceBefore,
+		BalanceAfter:  balanceAfter,
+		Description:   description,
+		Status:        status,
+	}
+
+	if err := tx.Create(&transaction).Error; err != nil {
+		return nil, fmt.Errorf("failed to create transaction record: %v", err)
+	}
+
+	return &transaction, nil
+}
+
+// creditUserCurrencyBalance credits a user in any currency. Base currency amounts go to User.Balance,
+// other currencies to the user's CurrencyBalance. It must be called inside a database transaction.
+func creditUserCurrencyBalance(tx *gorm.DB, userID uint, currency string, amount int64, txnType, description string) (*models.Transaction, error) {
+	if currency == "" || currency == models.BASE_CURRENCY {
+		return creditUserBalance(tx, userID, amount, txnType, description)
+	}
+	return applyCurrencyBalance(tx, userID, currency, amount, txnType, description)
+}
+
+// debitUserCurrencyBalance debits a user in any currency (see creditUserCurrencyBalance).
+// It must be called inside a database transaction.
+func debitUserCurrencyBalance(tx *gorm.DB, userID uint, currency string, amount int64, txnType, description string) (*models.Transaction, error) {
+	if currency == "" || currency == models.BASE_CURRENCY {
+		return debitUserBalance(tx, userID, amount, txnType, description)
+	}
+	return applyCurrencyBalance(tx, userID, currency, -amount, txnType, description)
+}
+
+// applyCurrencyBalance applies a signed amount to a non-base currency balance, creating it on first credit
+func applyCurrencyBalance(tx *gorm.DB, userID uint, currency string, signedAmount int64, txnType, description string) (*models.Transaction, error) {
+	var user models.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get user: %v", err)
+	}
+
+	if user.Status != models.USER_STATUS_ACTIVE {
+		return nil, errUserNotActive
+	}
+
+	balance := models.CurrencyBalance{UserID: userID, Currency: currency}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND currency = ?", userID, currency).
+		FirstOrCreate(&balance).Error; err != nil {
+		return nil, fmt.Errorf("failed to get %s balance: %v", currency, err)
+	}
+
+	if balance.Balance+signedAmount < 0 {
+		return nil, errInsufficientBalance
+	}
+
+	balanceBefore := balance.Balance
+	balanceAfter := balanceBefore + signedAmount
+
+	if err := tx.Model(&balance).Update("balance", balanceAfter).Error; err != nil {
+		return nil, fmt.Errorf("failed to update %s balance: %v", currency, err)
+	}
+
+	amount := signedAmount
+	if amount < 0 {
+		amount = -amount
+	}
+
+	transaction := models.Transaction{
+		UserID:        user.ID,
+		Type:          txnType,
+		Amount:        amount,
+		Currency:      currency,
+		BalanceBefore: balanceBefore,
+		BalanceAfter:  balanceAfter,
+		Description:   description,
+		Status:        models.TRANSACTION_STATUS_COMPLETED,
+	}
+
+	if err := tx.Create(&transaction).Error; err != nil {
+		return nil, fmt.Errorf("failed to create transaction record: %v", err)
+	}
+
+	return &transaction, nil
+}
+
+// transitionTransaction moves a transaction to status, together with any extra column updates,
+// after checking the move against the transaction state machine. It must be called inside a
+// database transaction.
+func transitionTransaction(tx *gorm.DB, txn *models.Transaction, status string, updates map[string]interface{}) error {
+	if !txn.CanTransitionTo(status) {
+		return fmt.Errorf("%w: %s to %s", errInvalidStatusTransition, txn.Status, status)
+	}
+
+	if updates == nil {
+		updates = map[string]interface{}{}
+	}
+	updates["status"] = status
+	if err := tx.Model(txn).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to update transaction %d: %v", txn.ID, err)
+	}
+
+	txn.Status = status
+	return nil
+}
+
+// postSystemAccountEntry locks a system account, applies a signed amount and records the entry.
+// It must be called inside a database transaction.
+func postSystemAccountEntry(tx *gorm.DB, code string, amount int64, reference, description string, transactionID *uint) error {
+	var account models.SystemAccount
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("code = ?", code).First(&account).Error; err != nil {
+		return fmt.Errorf("failed to get system account %s: %v", code, err)
+	}
+
+	balanceBefore := account.Balance
+	balanceAfter := balanceBefore + amount
+
+	if err := tx.Model(&account).Update("balance", balanceAfter).Error; err != nil {
+		return fmt.Errorf("failed to update system account %s: %v", code, err)
+	}
+
+	entry := models.SystemAccountEntry{
+		SystemAccountID: account.ID,
+		Amount:          amount,
+		BalanceBefore:   balanceBefore,
+		BalanceAfter:    balanceAfter,
+		Reference:       reference,
+		Description:     description,
+		TransactionID:   transactionID,
+	}
+
+	if err := tx.Create(&entry).Error; err != nil {
+		return fmt.Errorf("failed to create system account entry: %v", err)
+	}
+
+	return nil
+}
+
+// getConfigInt64 reads an integer configuration value, falling back to defaultValue
+func getConfigInt64(db *gorm.DB, key string, defaultValue int64) int64 {
+	var cfg models.Config
+	if err := db.Where("key = ?", key).First(&cfg).Error; err != nil {
+		return defaultValue
+	}
+
+	value, err := strconv.ParseInt(cfg.Value, 10, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getConfigString reads a configuration value, falling back to defaultValue when it is missing or empty
+func getConfigString(db *gorm.DB, key, defaultValue string) string {
+	var cfg models.Config
+	if err := db.Where("key = ?", key).First(&cfg).Error; err != nil || cfg.Value == "" {
+		return defaultValue
+	}
+	return cfg.Value
+}
+
+// respondLedgerError maps ledger errors to API responses, using fallbackMessage for unexpected errors
+func respondLedgerError(c *gin.Context, err error, fallbackMessage string) {
+	switch {
+	case errors.Is(err, errInsufficientBalance):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Insufficient balance",
+		})
+	case errors.Is(err, errUserNotActive):
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Code:    http.StatusForbidden,
+			Message: "User account is not active",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: fallbackMessage,
+		})
+	}
+}
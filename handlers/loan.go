@@ -0,0 +1,1110 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"mbankingcore/models"
+	"mbankingcore/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoanHandler struct {
+	DB *gorm.DB
+}
+
+func NewLoanHandler(db *gorm.DB) *LoanHandler {
+	return &LoanHandler{DB: db}
+}
+
+// LoanCollectionRunResult summarises one installment auto-debit run
+type LoanCollectionRunResult struct {
+	Date      string `json:"date"`
+	Processed int    `json:"processed"`
+	Collected int64  `json:"collected"` // Total amount debited from customers
+	PaidOff   int    `json:"paid_off"`
+	Overdue   int    `json:"overdue"` // Loans still past due after the run
+	Failed    int    `json:"failed"`
+}
+
+// loanCollection is the outcome of collecting one loan
+type loanCollection struct {
+	Collected int64
+	Loan      models.Loan
+}
+
+// errNothingDue is returned when a loan has no installment due for collection
+var errNothingDue = errors.New("no installment is due")
+
+// GetProducts - List active loan products
+func (h *LoanHandler) GetProducts(c *gin.Context) {
+	var products []models.LoanProduct
+	if err := h.DB.Where("is_active = ?", true).Order("code ASC").Find(&products).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch loan products",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Loan products retrieved successfully",
+		Data:    products,
+	})
+}
+
+// Simulate - Preview the installment schedule of a loan
+func (h *LoanHandler) Simulate(c *gin.Context) {
+	var req models.LoanSimulationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	product, message := h.validateLoanTerms(req.ProductID, req.Amount, req.TenorMonths)
+	if message != "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: message,
+		})
+		return
+	}
+
+	schedule := utils.BuildAmortizationSchedule(req.Amount, product.RateBasisPoints, req.TenorMonths, product.InterestMethod)
+	adminFee := req.Amount * int64(product.AdminFeeBasisPoints) / 10000
+
+	var totalInterest int64
+	for _, row := range schedule {
+		totalInterest += row.InterestAmount
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Loan simulation calculated successfully",
+		Data: gin.H{
+			"product":          product,
+			"principal":        req.Amount,
+			"admin_fee":        adminFee,
+			"disbursed_amount": req.Amount - adminFee,
+			"total_interest":   totalInterest,
+			"total_repayment":  req.Amount + totalInterest,
+			"schedule":         schedule,
+		},
+	})
+}
+
+// Apply - Submit a loan application for admin review
+func (h *LoanHandler) Apply(c *gin.Context) {
+	userIDValue, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+	userID := userIDValue.(uint)
+
+	var req models.LoanApplicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	product, message := h.validateLoanTerms(req.ProductID, req.Amount, req.TenorMonths)
+	if message != "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: message,
+		})
+		return
+	}
+
+	var account models.BankAccount
+	if err := h.DB.Where("id = ? AND user_id = ? AND is_active = ?", req.BankAccountID, userID, true).First(&account).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Bank account not found or inactive",
+		})
+		return
+	}
+	if account.Currency != "" && account.Currency != models.BASE_CURRENCY {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Loans are disbursed to " + models.BASE_CURRENCY + " accounts only",
+		})
+		return
+	}
+
+	var pending int64
+	h.DB.Model(&models.Loan{}).
+		Where("user_id = ? AND status IN ?", userID, []string{models.LOAN_STATUS_SUBMITTED, models.LOAN_STATUS_RECOMMENDED}).
+		Count(&pending)
+	if pending > 0 {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "You already have a loan application under review",
+		})
+		return
+	}
+
+	loanNumber, err := generateLoanNumber()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to generate loan number",
+		})
+		return
+	}
+
+	schedule := utils.BuildAmortizationSchedule(req.Amount, product.RateBasisPoints, req.TenorMonths, product.InterestMethod)
+	loan := models.Loan{
+		LoanNumber:        loanNumber,
+		UserID:            userID,
+		ProductID:         product.ID,
+		BankAccountID:     account.ID,
+		Purpose:           req.Purpose,
+		Principal:         req.Amount,
+		TenorMonths:       req.TenorMonths,
+		RateBasisPoints:   product.RateBasisPoints,
+		InterestMethod:    product.InterestMethod,
+		AdminFee:          req.Amount * int64(product.AdminFeeBasisPoints) / 10000,
+		LateFeePerDay:     product.LateFeePerDay,
+		LateFeeCap:        product.LateFeeCap,
+		InstallmentAmount: schedule[0].Amount,
+		Status:            models.LOAN_STATUS_SUBMITTED,
+	}
+
+	if err := h.DB.Create(&loan).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to submit loan application",
+		})
+		return
+	}
+
+	loan.Product = *product
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Code:    http.StatusCreated,
+		Message: "Loan application submitted successfully",
+		Data:    loan,
+	})
+}
+
+// GetUserLoans - List the authenticated user's loans
+func (h *LoanHandler) GetUserLoans(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.Loan{}).Where("user_id = ?", userID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var loans []models.Loan
+	if err := query.Preload("Product").Order("created_at DESC").Limit(limit).Offset(offset).Find(&loans).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch loans",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Loans retrieved successfully",
+		Data: gin.H{
+			"loans": loans,
+			"pagination": gin.H{
+				"current_page": page,
+				"per_page":     limit,
+				"total":        total,
+				"total_pages":  (total + int64(limit) - 1) / int64(limit),
+			},
+		},
+	})
+}
+
+// GetUserLoanByID - Get a loan with its installment schedule
+func (h *LoanHandler) GetUserLoanByID(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	var loan models.Loan
+	if err := h.DB.Preload("Product").Preload("Installments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sequence ASC")
+	}).Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&loan).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Loan not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Loan retrieved successfully",
+		Data:    loan,
+	})
+}
+
+// CancelLoan - Withdraw a loan application that has not been approved yet
+func (h *LoanHandler) CancelLoan(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	result := h.DB.Model(&models.Loan{}).
+		Where("id = ? AND user_id = ? AND status IN ?", c.Param("id"), userID,
+			[]string{models.LOAN_STATUS_SUBMITTED, models.LOAN_STATUS_RECOMMENDED}).
+		Update("status", models.LOAN_STATUS_CANCELLED)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to cancel loan application",
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Loan application not found or already processed",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Loan application cancelled successfully",
+	})
+}
+
+// PayLoan - Pay the installments that are due now from the main balance
+func (h *LoanHandler) PayLoan(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	var loan models.Loan
+	if err := h.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&loan).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Loan not found",
+		})
+		return
+	}
+
+	collection, err := h.collectLoan(loan.ID, today())
+	if err != nil {
+		if errors.Is(err, errNothingDue) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "No installment is due",
+			})
+			return
+		}
+		respondLedgerError(c, err, "Failed to pay loan")
+		return
+	}
+	if collection.Collected == 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Insufficient balance",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Loan payment successful",
+		Data: gin.H{
+			"paid_amount": collection.Collected,
+			"loan":        collection.Loan,
+		},
+	})
+}
+
+// RunCollection auto-debits every active loan with installments due on or before date, accrues
+// late fees and updates days past due. Amounts already collected are never taken twice, so the
+// run can be repeated safely.
+func (h *LoanHandler) RunCollection(date time.Time) (*LoanCollectionRunResult, error) {
+	asOf := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local)
+
+	var loanIDs []uint
+	if err := h.DB.Model(&models.LoanInstallment{}).
+		Joins("JOIN loans ON loans.id = loan_installments.loan_id").
+		Where("loans.status = ? AND loan_installments.status <> ? AND loan_installments.due_date <= ?",
+			models.LOAN_STATUS_ACTIVE, models.LOAN_INSTALLMENT_PAID, asOf.Format("2006-01-02")).
+		Distinct().Pluck("loan_installments.loan_id", &loanIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to find due installments: %v", err)
+	}
+
+	result := &LoanCollectionRunResult{Date: asOf.Format("2006-01-02")}
+	for _, loanID := range loanIDs {
+		collection, err := h.collectLoan(loanID, asOf)
+		if errors.Is(err, errNothingDue) {
+			continue
+		}
+		if err != nil {
+			log.Printf("Loan %d collection for %s failed: %v", loanID, result.Date, err)
+			result.Failed++
+			continue
+		}
+
+		result.Processed++
+		result.Collected += collection.Collected
+		if collection.Loan.Status == models.LOAN_STATUS_PAID_OFF {
+			result.PaidOff++
+		} else if collection.Loan.DaysPastDue > 0 {
+			result.Overdue++
+		}
+	}
+
+	return result, nil
+}
+
+// collectLoan collects one loan's due installments from the borrower's balance in a single
+// database transaction. Each installment is paid late fee first, then interest, then principal.
+func (h *LoanHandler) collectLoan(loanID uint, asOf time.Time) (*loanCollection, error) {
+	tx := h.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var loan models.Loan
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&loan, loanID).Error; err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to lock loan: %v", err)
+	}
+	if loan.Status != models.LOAN_STATUS_ACTIVE {
+		tx.Rollback()
+		return nil, errNothingDue
+	}
+
+	var installments []models.LoanInstallment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("loan_id = ? AND status <> ? AND due_date <= ?", loan.ID, models.LOAN_INSTALLMENT_PAID, asOf.Format("2006-01-02")).
+		Order("sequence ASC").Find(&installments).Error; err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to lock installments: %v", err)
+	}
+	if len(installments) == 0 {
+		tx.Rollback()
+		return nil, errNothingDue
+	}
+
+	var user models.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "balance", "status").First(&user, loan.UserID).Error; err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to get borrower: %v", err)
+	}
+	available := user.Balance
+	if user.Status != models.USER_STATUS_ACTIVE || available < 0 {
+		available = 0
+	}
+
+	now := time.Now()
+	var principalTotal, interestTotal, lateFeeTotal int64
+	for i := range installments {
+		installment := &installments[i]
+
+		// Late fees accrue per day past due and never decrease, so re-runs on the same date are stable
+		if daysPastDue := daysBetween(installment.DueDate, asOf); daysPastDue > 0 && loan.LateFeePerDay > 0 {
+			lateFee := int64(daysPastDue) * loan.LateFeePerDay
+			if loan.LateFeeCap > 0 && lateFee > loan.LateFeeCap {
+				lateFee = loan.LateFeeCap
+			}
+			if lateFee > installment.LateFee {
+				installment.LateFee = lateFee
+			}
+		}
+
+		pay := min(available, installment.LateFee-installment.LateFeePaid)
+		installment.LateFeePaid += pay
+		lateFeeTotal += pay
+		available -= pay
+
+		pay = min(available, installment.InterestAmount-installment.InterestPaid)
+		installment.InterestPaid += pay
+		interestTotal += pay
+		available -= pay
+
+		pay = min(available, installment.PrincipalAmount-installment.PrincipalPaid)
+		installment.PrincipalPaid += pay
+		principalTotal += pay
+		available -= pay
+
+		if installment.AmountDue() == 0 {
+			installment.Status = models.LOAN_INSTALLMENT_PAID
+			installment.PaidAt = &now
+		} else if installment.PrincipalPaid+installment.InterestPaid+installment.LateFeePaid > 0 {
+			installment.Status = models.LOAN_INSTALLMENT_PARTIAL
+		}
+
+		if err := tx.Model(installment).Select("late_fee", "late_fee_paid", "interest_paid", "principal_paid",
+			"status", "paid_at").Updates(installment).Error; err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to update installment: %v", err)
+		}
+	}
+
+	if repayment := principalTotal + interestTotal; repayment > 0 {
+		repaymentTxn, err := debitUserBalance(tx, loan.UserID, repayment, "loan_repayment",
+			"Loan installment "+loan.LoanNumber)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if principalTotal > 0 {
+			if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_LOAN_RECEIVABLE, -principalTotal, loan.LoanNumber,
+				"Loan principal repaid "+loan.LoanNumber, &repaymentTxn.ID); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+		}
+		if interestTotal > 0 {
+			if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_INTEREST_INCOME, interestTotal, loan.LoanNumber,
+				"Loan interest "+loan.LoanNumber, &repaymentTxn.ID); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+		}
+	}
+	if lateFeeTotal > 0 {
+		lateFeeTxn, err := debitUserBalance(tx, loan.UserID, lateFeeTotal, "loan_late_fee",
+			"Loan late fee "+loan.LoanNumber)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_FEE_INCOME, lateFeeTotal, loan.LoanNumber,
+			"Loan late fee "+loan.LoanNumber, &lateFeeTxn.ID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := refreshLoanPosition(tx, &loan, asOf); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("failed to commit loan collection: %v", err)
+	}
+
+	return &loanCollection{Collected: principalTotal + interestTotal + lateFeeTotal, Loan: loan}, nil
+}
+
+// refreshLoanPosition recalculates outstanding principal, next due date and days past due,
+// closing the loan when every installment is paid
+func refreshLoanPosition(tx *gorm.DB, loan *models.Loan, asOf time.Time) error {
+	var principalPaid int64
+	if err := tx.Model(&models.LoanInstallment{}).Where("loan_id = ?", loan.ID).
+		Select("COALESCE(SUM(principal_paid), 0)").Scan(&principalPaid).Error; err != nil {
+		return fmt.Errorf("failed to sum repaid principal: %v", err)
+	}
+
+	var next models.LoanInstallment
+	found := tx.Where("loan_id = ? AND status <> ?", loan.ID, models.LOAN_INSTALLMENT_PAID).
+		Order("sequence ASC").Limit(1).Find(&next)
+	if found.Error != nil {
+		return fmt.Errorf("failed to get next installment: %v", found.Error)
+	}
+
+	loan.OutstandingPrincipal = loan.Principal - principalPaid
+	loan.DaysPastDue = 0
+	loan.NextDueDate = nil
+	if found.RowsAffected == 0 {
+		now := time.Now()
+		loan.Status = models.LOAN_STATUS_PAID_OFF
+		loan.PaidOffAt = &now
+	} else {
+		dueDate := next.DueDate
+		loan.NextDueDate = &dueDate
+		loan.DaysPastDue = max(daysBetween(next.DueDate, asOf), 0)
+	}
+
+	if err := tx.Model(loan).Select("outstanding_principal", "next_due_date", "days_past_due", "status",
+		"paid_off_at").Updates(loan).Error; err != nil {
+		return fmt.Errorf("failed to update loan: %v", err)
+	}
+	return nil
+}
+
+// validateLoanTerms checks an amount and tenor against an active product, returning a message on error
+func (h *LoanHandler) validateLoanTerms(productID uint, amount int64, tenorMonths int) (*models.LoanProduct, string) {
+	var product models.LoanProduct
+	if err := h.DB.Where("is_active = ?", true).First(&product, productID).Error; err != nil {
+		return nil, "Loan product not found or inactive"
+	}
+	if amount < product.MinAmount || amount > product.MaxAmount {
+		return nil, fmt.Sprintf("Amount must be between %d and %d", product.MinAmount, product.MaxAmount)
+	}
+	if tenorMonths < product.MinTenorMonths || tenorMonths > product.MaxTenorMonths {
+		return nil, fmt.Sprintf("Tenor must be between %d and %d months", product.MinTenorMonths, product.MaxTenorMonths)
+	}
+	return &product, ""
+}
+
+// daysBetween returns the number of calendar days from one date to another
+func daysBetween(from, to time.Time) int {
+	fromDate := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDate := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(math.Round(toDate.Sub(fromDate).Hours() / 24))
+}
+
+// generateLoanNumber returns a loan number such as LN2410123456789012
+func generateLoanNumber() (string, error) {
+	digits, err := generateDigits(12)
+	if err != nil {
+		return "", err
+	}
+	return "LN" + time.Now().Format("0601") + digits, nil
+}
+
+// GetAllProducts - List all loan products (admin)
+func (h *LoanHandler) GetAllProducts(c *gin.Context) {
+	var products []models.LoanProduct
+	if err := h.DB.Order("code ASC").Find(&products).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch loan products",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Loan products retrieved successfully",
+		Data:    products,
+	})
+}
+
+// CreateProduct - Create a loan product (admin)
+func (h *LoanHandler) CreateProduct(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+
+	var req models.LoanProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var count int64
+	h.DB.Model(&models.LoanProduct{}).Where("code = ?", req.Code).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "Loan product code already exists",
+		})
+		return
+	}
+
+	product := models.LoanProduct{Code: req.Code, IsActive: true}
+	applyLoanProductRequest(&product, &req)
+	if err := h.DB.Create(&product).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create loan product",
+		})
+		return
+	}
+
+	createAuditLog(h.DB, c, "loan_product", product.ID, adminID.(uint), "CREATE", map[string]interface{}{
+		"product": product,
+	})
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Code:    http.StatusCreated,
+		Message: "Loan product created successfully",
+		Data:    product,
+	})
+}
+
+// UpdateProduct - Update a loan product (admin). Existing loans keep the terms they were applied with.
+func (h *LoanHandler) UpdateProduct(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+
+	var product models.LoanProduct
+	if err := h.DB.First(&product, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Loan product not found",
+		})
+		return
+	}
+
+	var req models.LoanProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+	if req.Code != product.Code {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Loan product code cannot be changed",
+		})
+		return
+	}
+
+	oldProduct := product
+	applyLoanProductRequest(&product, &req)
+	if err := h.DB.Save(&product).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update loan product",
+		})
+		return
+	}
+
+	createAuditLog(h.DB, c, "loan_product", product.ID, adminID.(uint), "UPDATE", map[string]interface{}{
+		"old_values": oldProduct,
+		"new_values": product,
+	})
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Loan product updated successfully",
+		Data:    product,
+	})
+}
+
+// applyLoanProductRequest copies a product request onto a product
+func applyLoanProductRequest(product *models.LoanProduct, req *models.LoanProductRequest) {
+	product.Name = req.Name
+	product.MinAmount = req.MinAmount
+	product.MaxAmount = req.MaxAmount
+	product.MinTenorMonths = req.MinTenorMonths
+	product.MaxTenorMonths = req.MaxTenorMonths
+	product.RateBasisPoints = req.RateBasisPoints
+	product.InterestMethod = req.InterestMethod
+	product.AdminFeeBasisPoints = req.AdminFeeBasisPoints
+	product.LateFeePerDay = req.LateFeePerDay
+	product.LateFeeCap = req.LateFeeCap
+	if req.IsActive != nil {
+		product.IsActive = *req.IsActive
+	}
+}
+
+// GetAllLoans - List loans and applications (admin)
+func (h *LoanHandler) GetAllLoans(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.Loan{})
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if minDPD := c.Query("min_dpd"); minDPD != "" {
+		query = query.Where("days_past_due >= ?", minDPD)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var loans []models.Loan
+	if err := query.Preload("Product").Order("created_at DESC").Limit(limit).Offset(offset).Find(&loans).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch loans",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Loans retrieved successfully",
+		Data: gin.H{
+			"loans": loans,
+			"pagination": gin.H{
+				"page":        page,
+				"limit":       limit,
+				"total":       total,
+				"total_pages": int(math.Ceil(float64(total) / float64(limit))),
+			},
+		},
+	})
+}
+
+// GetLoanByID - Get a loan with its schedule (admin)
+func (h *LoanHandler) GetLoanByID(c *gin.Context) {
+	var loan models.Loan
+	if err := h.DB.Preload("Product").Preload("Installments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sequence ASC")
+	}).First(&loan, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Loan not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Loan retrieved successfully",
+		Data:    loan,
+	})
+}
+
+// RecommendLoan - Review a submitted application and recommend or reject it (maker)
+func (h *LoanHandler) RecommendLoan(c *gin.Context) {
+	makerAdminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+	makerID := makerAdminID.(uint)
+
+	var req models.LoanReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+	if req.Action == "reject" && req.RejectionReason == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Rejection reason is required when rejecting a loan",
+		})
+		return
+	}
+
+	var loan models.Loan
+	if err := h.DB.First(&loan, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Loan not found",
+		})
+		return
+	}
+	if loan.Status != models.LOAN_STATUS_SUBMITTED {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Loan is already %s", loan.Status),
+		})
+		return
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"maker_admin_id": makerID,
+		"maker_comments": req.Comments,
+	}
+	if req.Action == "approve" {
+		updates["status"] = models.LOAN_STATUS_RECOMMENDED
+		updates["recommended_at"] = &now
+	} else {
+		updates["status"] = models.LOAN_STATUS_REJECTED
+		updates["rejection_reason"] = req.RejectionReason
+		updates["rejected_at"] = &now
+	}
+
+	// Guard on status so two reviewers cannot act on the same application
+	result := h.DB.Model(&models.Loan{}).Where("id = ? AND status = ?", loan.ID, models.LOAN_STATUS_SUBMITTED).Updates(updates)
+	if result.Error != nil || result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "Loan was updated by another reviewer",
+		})
+		return
+	}
+
+	createAuditLog(h.DB, c, "loan", loan.ID, makerID, "RECOMMEND_"+strings.ToUpper(req.Action), map[string]interface{}{
+		"loan_number":      loan.LoanNumber,
+		"comments":         req.Comments,
+		"rejection_reason": req.RejectionReason,
+	})
+
+	h.DB.First(&loan, loan.ID)
+	message := "Loan rejected successfully"
+	if req.Action == "approve" {
+		message = "Loan recommended and waiting for checker approval"
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: message,
+		Data:    loan,
+	})
+}
+
+// ApproveLoan - Approve a recommended loan and disburse it, or reject it (checker)
+func (h *LoanHandler) ApproveLoan(c *gin.Context) {
+	checkerAdminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+	checkerID := checkerAdminID.(uint)
+
+	var req models.LoanReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+	if req.Action == "reject" && req.RejectionReason == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Rejection reason is required when rejecting a loan",
+		})
+		return
+	}
+
+	tx := h.DB.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var loan models.Loan
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&loan, c.Param("id")).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Loan not found",
+		})
+		return
+	}
+	if loan.Status != models.LOAN_STATUS_RECOMMENDED {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Loan is %s, only recommended loans can be approved", loan.Status),
+		})
+		return
+	}
+
+	// Segregation of duties
+	if loan.MakerAdminID != nil && *loan.MakerAdminID == checkerID {
+		tx.Rollback()
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Code:    http.StatusForbidden,
+			Message: "Admin cannot approve a loan they recommended (segregation of duties)",
+		})
+		return
+	}
+
+	now := time.Now()
+	loan.CheckerAdminID = &checkerID
+	loan.CheckerComments = req.Comments
+
+	if req.Action == "reject" {
+		loan.Status = models.LOAN_STATUS_REJECTED
+		loan.RejectionReason = req.RejectionReason
+		loan.RejectedAt = &now
+	} else if err := h.disburse(tx, &loan); err != nil {
+		tx.Rollback()
+		if errors.Is(err, errUserNotActive) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Borrower account is not active",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to disburse loan",
+		})
+		return
+	}
+
+	if err := tx.Save(&loan).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update loan",
+		})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to commit loan approval",
+		})
+		return
+	}
+
+	createAuditLog(h.DB, c, "loan", loan.ID, checkerID, strings.ToUpper(req.Action), map[string]interface{}{
+		"loan_number":      loan.LoanNumber,
+		"principal":        loan.Principal,
+		"disbursed_amount": loan.DisbursedAmount,
+		"comments":         req.Comments,
+		"rejection_reason": req.RejectionReason,
+	})
+
+	message := "Loan rejected successfully"
+	if req.Action == "approve" {
+		message = "Loan approved and disbursed successfully"
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: message,
+		Data:    loan,
+	})
+}
+
+// disburse creates the installment schedule, credits the borrower net of the admin fee and
+// books the receivable. It must be called inside a database transaction.
+func (h *LoanHandler) disburse(tx *gorm.DB, loan *models.Loan) error {
+	disbursementDate := today()
+	schedule := utils.BuildAmortizationSchedule(loan.Principal, loan.RateBasisPoints, loan.TenorMonths, loan.InterestMethod)
+	for _, row := range schedule {
+		installment := models.LoanInstallment{
+			LoanID:          loan.ID,
+			Sequence:        row.Sequence,
+			DueDate:         addMonthsClamped(disbursementDate, row.Sequence),
+			PrincipalAmount: row.PrincipalAmount,
+			InterestAmount:  row.InterestAmount,
+			Amount:          row.Amount,
+			Status:          models.LOAN_INSTALLMENT_PENDING,
+		}
+		if err := tx.Create(&installment).Error; err != nil {
+			return fmt.Errorf("failed to create installment: %v", err)
+		}
+	}
+
+	disbursedAmount := loan.Principal - loan.AdminFee
+	creditTxn, err := creditUserBalance(tx, loan.UserID, disbursedAmount, "loan_disbursement",
+		fmt.Sprintf("Loan disbursement %s (%d months)", loan.LoanNumber, loan.TenorMonths))
+	if err != nil {
+		return err
+	}
+
+	if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_LOAN_RECEIVABLE, loan.Principal, loan.LoanNumber,
+		"Loan disbursed "+loan.LoanNumber, &creditTxn.ID); err != nil {
+		return err
+	}
+	if loan.AdminFee > 0 {
+		if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_FEE_INCOME, loan.AdminFee, loan.LoanNumber,
+			"Loan admin fee "+loan.LoanNumber, &creditTxn.ID); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	firstDueDate := addMonthsClamped(disbursementDate, 1)
+	loan.Status = models.LOAN_STATUS_ACTIVE
+	loan.InstallmentAmount = schedule[0].Amount
+	loan.DisbursedAmount = disbursedAmount
+	loan.DisbursementTxnID = &creditTxn.ID
+	loan.DisbursedAt = &now
+	loan.OutstandingPrincipal = loan.Principal
+	loan.NextDueDate = &firstDueDate
+	return nil
+}
+
+// RunCollectionJob - Auto-debit due loan installments (admin)
+func (h *LoanHandler) RunCollectionJob(c *gin.Context) {
+	var req models.RunLoanCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	date := time.Now()
+	if req.Date != "" {
+		date, _ = time.ParseInLocation("2006-01-02", req.Date, time.Local)
+	}
+
+	result, err := h.RunCollection(date)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Loan collection completed",
+		Data:    result,
+	})
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mbankingcore/models"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Default login throttling parameters, overridable through config
+const (
+	defaultLoginFailureWindowMinutes  = 15
+	defaultLoginFreeAttempts          = 3  // Failures per identifier before delays start
+	defaultLoginIPFreeAttempts        = 10 // Failures per IP before delays start
+	defaultLoginDelayBaseSeconds      = 2
+	defaultLoginDelayMaxSeconds       = 900
+	defaultLoginUserLockoutThreshold  = 5
+	defaultLoginAdminLockoutThreshold = 5
+)
+
+// Uniform login failure messages. The same message is returned whether the account does not exist,
+// the details do not match or the account is locked, so responses do not reveal which accounts exist.
+const (
+	msgUserLoginFailed  = "Invalid login credentials"
+	msgAdminLoginFailed = "Invalid email or password"
+	msgLoginThrottled   = "Too many login attempts. Please try again later"
+)
+
+// errLoginLockedOut is returned by recordUserLoginFailure and recordAdminLoginFailure when the
+// failure has just locked the account
+var errLoginLockedOut = errors.New("account locked after repeated login failures")
+
+// dummyPasswordHash is compared against when the login identifier is unknown, so that unknown and
+// known identifiers take the same time to reject
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("mbankingcore-login-timing"), bcrypt.DefaultCost)
+
+// loginThrottleKeys lists the counters a login attempt is checked against
+type loginThrottleKeys struct {
+	Scope      string // models.LOGIN_THROTTLE_SCOPE_USER or models.LOGIN_THROTTLE_SCOPE_ADMIN
+	Identifier string
+	IPAddress  string
+}
+
+// checkLoginThrottle returns how long the caller must wait before another attempt is accepted for
+// either the identifier or the IP address, or zero when the attempt may proceed
+func checkLoginThrottle(db *gorm.DB, keys loginThrottleKeys) time.Duration {
+	now := time.Now()
+
+	var throttles []models.LoginThrottle
+	db.Where("(scope = ? AND key = ?) OR (scope = ? AND key = ?)",
+		keys.Scope, keys.Identifier, models.LOGIN_THROTTLE_SCOPE_IP, keys.IPAddress).
+		Find(&throttles)
+
+	var wait time.Duration
+	for _, throttle := range throttles {
+		if throttle.BlockedUntil != nil && throttle.BlockedUntil.After(now) {
+			if remaining := throttle.BlockedUntil.Sub(now); remaining > wait {
+				wait = remaining
+			}
+		}
+	}
+	return wait
+}
+
+// recordLoginFailure counts a failed attempt against the identifier and the IP address and returns
+// the identifier's failure count within the current window
+func recordLoginFailure(db *gorm.DB, keys loginThrottleKeys) (int, error) {
+	freeAttempts := getConfigInt64(db, "login_free_attempts", defaultLoginFreeAttempts)
+	ipFreeAttempts := getConfigInt64(db, "login_ip_free_attempts", defaultLoginIPFreeAttempts)
+
+	count, err := incrementLoginThrottle(db, keys.Scope, keys.Identifier, int(freeAttempts))
+	if err != nil {
+		return 0, err
+	}
+	if keys.IPAddress != "" {
+		if _, err := incrementLoginThrottle(db, models.LOGIN_THROTTLE_SCOPE_IP, keys.IPAddress, int(ipFreeAttempts)); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+// incrementLoginThrottle adds one failure to a counter. Failures older than the window are
+// forgotten; past freeAttempts every failure blocks the key for a delay that doubles each time.
+func incrementLoginThrottle(db *gorm.DB, scope, key string, freeAttempts int) (int, error) {
+	now := time.Now()
+	window := time.Duration(getConfigInt64(db, "login_failure_window_minutes", defaultLoginFailureWindowMinutes)) * time.Minute
+	baseDelay := time.Duration(getConfigInt64(db, "login_delay_base_seconds", defaultLoginDelayBaseSeconds)) * time.Second
+	maxDelay := time.Duration(getConfigInt64(db, "login_delay_max_seconds", defaultLoginDelayMaxSeconds)) * time.Second
+
+	var count int
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.LoginThrottle{Scope: scope, Key: key}).Error; err != nil {
+			return err
+		}
+
+		var throttle models.LoginThrottle
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("scope = ? AND key = ?", scope, key).First(&throttle).Error; err != nil {
+			return err
+		}
+
+		if throttle.LastFailureAt == nil || now.Sub(*throttle.LastFailureAt) > window {
+			throttle.FailureCount = 0
+		}
+		throttle.FailureCount++
+		throttle.LastFailureAt = &now
+		throttle.BlockedUntil = nil
+
+		if excess := throttle.FailureCount - freeAttempts; excess > 0 {
+			delay := maxDelay
+			if excess <= 30 {
+				if d := baseDelay << uint(excess-1); d < maxDelay {
+					delay = d
+				}
+			}
+			blockedUntil := now.Add(delay)
+			throttle.BlockedUntil = &blockedUntil
+		}
+
+		count = throttle.FailureCount
+		return tx.Save(&throttle).Error
+	})
+	return count, err
+}
+
+// resetLoginFailures clears the identifier's counter. The IP counter is left alone so that one
+// successful login cannot be used to reset a password-spraying source.
+func resetLoginFailures(db *gorm.DB, scope, identifier string) {
+	db.Model(&models.LoginThrottle{}).
+		Where("scope = ? AND key = ?", scope, identifier).
+		Updates(map[string]interface{}{
+			"failure_count":   0,
+			"last_failure_at": nil,
+			"blocked_until":   nil,
+		})
+}
+
+// respondLoginThrottled rejects an attempt made while the identifier or IP address is blocked
+func respondLoginThrottled(c *gin.Context, wait time.Duration) {
+	seconds := int(wait.Round(time.Second).Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	c.Set("login_status", models.LOGIN_STATUS_BLOCKED)
+	c.Set("failure_reason", "throttled")
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, models.APIResponse{
+		Code:    models.CODE_LOGIN_FAILED,
+		Message: msgLoginThrottled,
+		Data: gin.H{
+			"retry_after": seconds,
+		},
+	})
+}
+
+// recordUserLoginFailure records a failed banking login and locks the user once the threshold is
+// reached. user is nil when the phone number is not registered.
+func recordUserLoginFailure(db *gorm.DB, keys loginThrottleKeys, user *models.User) error {
+	count, err := recordLoginFailure(db, keys)
+	if err != nil || user == nil {
+		return err
+	}
+
+	threshold := getConfigInt64(db, "login_user_lockout_threshold", defaultLoginUserLockoutThreshold)
+	if threshold <= 0 || int64(count) < threshold || user.Status == models.USER_STATUS_LOCKED {
+		return nil
+	}
+
+	if err := db.Model(user).Update("status", models.USER_STATUS_LOCKED).Error; err != nil {
+		return err
+	}
+	// Start from a clean counter once an admin unlocks the account
+	resetLoginFailures(db, keys.Scope, keys.Identifier)
+	return errLoginLockedOut
+}
+
+// recordAdminLoginFailure records a failed admin login and blocks the admin once the threshold is
+// reached. admin is nil when the email is not registered.
+func recordAdminLoginFailure(db *gorm.DB, keys loginThrottleKeys, admin *models.Admin) error {
+	count, err := recordLoginFailure(db, keys)
+	if err != nil || admin == nil {
+		return err
+	}
+
+	threshold := getConfigInt64(db, "login_admin_lockout_threshold", defaultLoginAdminLockoutThreshold)
+	if threshold <= 0 || int64(count) < threshold || admin.IsBlocked() {
+		return nil
+	}
+
+	if err := db.Model(admin).Update("status", models.ADMIN_STATUS_BLOCKED).Error; err != nil {
+		return err
+	}
+	resetLoginFailures(db, keys.Scope, keys.Identifier)
+	return errLoginLockedOut
+}
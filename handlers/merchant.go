@@ -0,0 +1,493 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"mbankingcore/models"
+	"mbankingcore/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// QRIS merchant PAN prefix: "9360" (Indonesia) followed by this bank's 4-digit NNS
+const merchantPANPrefix = "93600999"
+
+type MerchantHandler struct {
+	DB *gorm.DB
+}
+
+func NewMerchantHandler(db *gorm.DB) *MerchantHandler {
+	return &MerchantHandler{DB: db}
+}
+
+// CreateMerchant - Register a QRIS merchant (admin)
+func (h *MerchantHandler) CreateMerchant(c *gin.Context) {
+	var req models.CreateMerchantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.validateSettlementAccount(req.UserID, req.SettlementAccountNumber); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	merchantID := strings.ToUpper(req.MerchantID)
+	if merchantID == "" {
+		generated, err := generateDigits(13)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to generate merchant ID",
+			})
+			return
+		}
+		merchantID = "ID" + generated
+	}
+
+	var existing int64
+	h.DB.Model(&models.Merchant{}).Where("merchant_id = ?", merchantID).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "Merchant ID already registered",
+		})
+		return
+	}
+
+	panBody, err := generateDigits(10)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to generate merchant PAN",
+		})
+		return
+	}
+	pan := merchantPANPrefix + panBody
+	pan += string(utils.LuhnCheckDigit(pan))
+
+	merchant := models.Merchant{
+		MerchantID:              merchantID,
+		MerchantPAN:             pan,
+		Name:                    strings.ToUpper(req.Name),
+		City:                    strings.ToUpper(req.City),
+		PostalCode:              req.PostalCode,
+		MCC:                     req.MCC,
+		Criteria:                req.Criteria,
+		UserID:                  req.UserID,
+		SettlementAccountNumber: req.SettlementAccountNumber,
+		MDRBasisPoints:          req.MDRBasisPoints,
+		MDRFixedFee:             req.MDRFixedFee,
+		Status:                  models.MERCHANT_STATUS_ACTIVE,
+	}
+
+	if err := h.DB.Create(&merchant).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create merchant",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Code:    http.StatusCreated,
+		Message: "Merchant registered successfully",
+		Data:    merchant,
+	})
+}
+
+// GetMerchants - List registered merchants (admin)
+func (h *MerchantHandler) GetMerchants(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.Merchant{})
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if search := c.Query("search"); search != "" {
+		like := "%" + strings.ToUpper(search) + "%"
+		query = query.Where("name LIKE ? OR merchant_id LIKE ? OR merchant_pan LIKE ?", like, like, like)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var merchants []models.Merchant
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&merchants).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch merchants",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Merchants retrieved successfully",
+		Data: gin.H{
+			"merchants": merchants,
+			"pagination": gin.H{
+				"page":        page,
+				"limit":       limit,
+				"total":       total,
+				"total_pages": (total + int64(limit) - 1) / int64(limit),
+			},
+		},
+	})
+}
+
+// GetMerchantByID - Get merchant detail (admin)
+func (h *MerchantHandler) GetMerchantByID(c *gin.Context) {
+	merchant, ok := h.findMerchant(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Merchant retrieved successfully",
+		Data:    merchant,
+	})
+}
+
+// UpdateMerchant - Update merchant details or status (admin)
+func (h *MerchantHandler) UpdateMerchant(c *gin.Context) {
+	merchant, ok := h.findMerchant(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateMerchantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != "" {
+		updates["name"] = strings.ToUpper(req.Name)
+	}
+	if req.City != "" {
+		updates["city"] = strings.ToUpper(req.City)
+	}
+	if req.PostalCode != "" {
+		updates["postal_code"] = req.PostalCode
+	}
+	if req.MCC != "" {
+		updates["mcc"] = req.MCC
+	}
+	if req.Criteria != "" {
+		updates["criteria"] = req.Criteria
+	}
+	if req.Status != "" {
+		updates["status"] = req.Status
+	}
+	if req.MDRBasisPoints != nil {
+		updates["mdr_basis_points"] = *req.MDRBasisPoints
+	}
+	if req.MDRFixedFee != nil {
+		updates["mdr_fixed_fee"] = *req.MDRFixedFee
+	}
+	if req.SettlementAccountNumber != "" {
+		if err := h.validateSettlementAccount(merchant.UserID, req.SettlementAccountNumber); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			return
+		}
+		updates["settlement_account_number"] = req.SettlementAccountNumber
+	}
+
+	if len(updates) > 0 {
+		if err := h.DB.Model(merchant).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to update merchant",
+			})
+			return
+		}
+	}
+
+	h.DB.First(merchant, merchant.ID)
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Merchant updated successfully",
+		Data:    merchant,
+	})
+}
+
+// GenerateMerchantQR - Generate the merchant's QRIS payload (admin)
+// Without an amount a static QR is returned; with ?amount= a dynamic QR for a single payment.
+func (h *MerchantHandler) GenerateMerchantQR(c *gin.Context) {
+	merchant, ok := h.findMerchant(c)
+	if !ok {
+		return
+	}
+
+	var amount int64
+	if value := c.Query("amount"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Amount must be a positive whole number",
+			})
+			return
+		}
+		amount = parsed
+	}
+
+	qr := buildMerchantQR(merchant, amount, c.Query("bill_number"), c.Query("terminal_label"))
+	payload, err := utils.GenerateEMVQR(qr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to generate QR: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Merchant QR generated successfully",
+		Data: gin.H{
+			"payload": payload,
+			"qr":      qr,
+		},
+	})
+}
+
+// CreateAPIKey - Issue an API key the merchant uses to accept payments (admin)
+// The plaintext key is only returned in this response.
+func (h *MerchantHandler) CreateAPIKey(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+
+	merchant, ok := h.findMerchant(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateMerchantAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	key, prefix, hash, err := utils.GenerateAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to generate API key",
+		})
+		return
+	}
+
+	apiKey := models.MerchantAPIKey{
+		MerchantID: merchant.ID,
+		Name:       req.Name,
+		KeyPrefix:  prefix,
+		KeyHash:    hash,
+		CreatedBy:  adminID.(uint),
+	}
+	if err := h.DB.Create(&apiKey).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to store API key",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Code:    http.StatusCreated,
+		Message: "API key created. Store it securely, it will not be shown again",
+		Data: gin.H{
+			"api_key": key,
+			"key":     apiKey,
+		},
+	})
+}
+
+// GetAPIKeys - List a merchant's API keys (admin)
+func (h *MerchantHandler) GetAPIKeys(c *gin.Context) {
+	merchant, ok := h.findMerchant(c)
+	if !ok {
+		return
+	}
+
+	var keys []models.MerchantAPIKey
+	if err := h.DB.Where("merchant_id = ?", merchant.ID).Order("created_at DESC").Find(&keys).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch API keys",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "API keys retrieved successfully",
+		Data:    keys,
+	})
+}
+
+// RevokeAPIKey - Revoke a merchant API key (admin)
+func (h *MerchantHandler) RevokeAPIKey(c *gin.Context) {
+	merchant, ok := h.findMerchant(c)
+	if !ok {
+		return
+	}
+
+	var apiKey models.MerchantAPIKey
+	if err := h.DB.Where("id = ? AND merchant_id = ?", c.Param("key_id"), merchant.ID).First(&apiKey).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "API key not found",
+		})
+		return
+	}
+
+	if apiKey.RevokedAt == nil {
+		now := time.Now()
+		if err := h.DB.Model(&apiKey).Update("revoked_at", &now).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to revoke API key",
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "API key revoked successfully",
+		Data:    apiKey,
+	})
+}
+
+// findMerchant loads the merchant referenced by the :id path parameter, writing an error response if missing
+func (h *MerchantHandler) findMerchant(c *gin.Context) (*models.Merchant, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid merchant ID",
+		})
+		return nil, false
+	}
+
+	var merchant models.Merchant
+	if err := h.DB.First(&merchant, uint(id)).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Merchant not found",
+		})
+		return nil, false
+	}
+	return &merchant, true
+}
+
+// validateSettlementAccount ensures the settlement account is an active account of the merchant owner
+func (h *MerchantHandler) validateSettlementAccount(userID uint, accountNumber string) error {
+	var user models.User
+	if err := h.DB.First(&user, userID).Error; err != nil {
+		return fmt.Errorf("merchant owner not found")
+	}
+	if user.Status != models.USER_STATUS_ACTIVE {
+		return fmt.Errorf("merchant owner is not active")
+	}
+
+	var count int64
+	h.DB.Model(&models.BankAccount{}).
+		Where("user_id = ? AND account_number = ? AND is_active = ?", userID, accountNumber, true).
+		Count(&count)
+	if count == 0 {
+		return fmt.Errorf("settlement account must be an active account of the merchant owner")
+	}
+	return nil
+}
+
+// buildMerchantQR builds the QRIS template for a merchant
+func buildMerchantQR(merchant *models.Merchant, amount int64, billNumber, terminalLabel string) *utils.EMVQR {
+	qr := &utils.EMVQR{
+		MerchantAccounts: []utils.EMVQRMerchantAccount{
+			{
+				Tag:         "26",
+				GUI:         utils.QRIS_ACQUIRER_GUI,
+				MerchantPAN: merchant.MerchantPAN,
+				MerchantID:  merchant.MerchantID,
+				Criteria:    merchant.Criteria,
+			},
+			{
+				Tag:        "51",
+				GUI:        utils.QRIS_GUI,
+				MerchantID: merchant.MerchantID,
+				Criteria:   merchant.Criteria,
+			},
+		},
+		MCC:          merchant.MCC,
+		Currency:     utils.QRIS_CURRENCY_IDR,
+		Amount:       amount,
+		CountryCode:  utils.QRIS_COUNTRY_ID,
+		MerchantName: merchant.Name,
+		MerchantCity: merchant.City,
+		PostalCode:   merchant.PostalCode,
+	}
+
+	if billNumber != "" || terminalLabel != "" {
+		qr.AdditionalData = &utils.EMVQRAdditionalData{
+			BillNumber:    billNumber,
+			TerminalLabel: terminalLabel,
+		}
+	}
+	return qr
+}
+
+// generateDigits returns a cryptographically random string of n digits
+func generateDigits(n int) (string, error) {
+	digits := make([]byte, n)
+	for i := range digits {
+		d, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		digits[i] = byte('0' + d.Int64())
+	}
+	return string(digits), nil
+}
@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mbankingcore/models"
+	"mbankingcore/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// MerchantAPIHandler serves merchants calling with an API key (see MerchantAPIKeyMiddleware)
+type MerchantAPIHandler struct {
+	DB *gorm.DB
+}
+
+func NewMerchantAPIHandler(db *gorm.DB) *MerchantAPIHandler {
+	return &MerchantAPIHandler{DB: db}
+}
+
+// currentMerchant loads the merchant authenticated by the API key
+func (h *MerchantAPIHandler) currentMerchant(c *gin.Context) (*models.Merchant, bool) {
+	merchantID, exists := c.Get("merchant_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return nil, false
+	}
+
+	var merchant models.Merchant
+	if err := h.DB.First(&merchant, merchantID.(uint)).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return nil, false
+	}
+	return &merchant, true
+}
+
+// CreatePaymentRequest - Generate a dynamic QR for a single payment (merchant API)
+func (h *MerchantAPIHandler) CreatePaymentRequest(c *gin.Context) {
+	merchant, ok := h.currentMerchant(c)
+	if !ok {
+		return
+	}
+
+	var req models.MerchantPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	qr := buildMerchantQR(merchant, req.Amount, req.BillNumber, req.TerminalLabel)
+	payload, err := utils.GenerateEMVQR(qr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Failed to generate QR: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Code:    http.StatusCreated,
+		Message: "Payment request created successfully",
+		Data: gin.H{
+			"payload":        payload,
+			"amount":         req.Amount,
+			"bill_number":    req.BillNumber,
+			"terminal_label": req.TerminalLabel,
+		},
+	})
+}
+
+// GetPayments - List payments received by the merchant (merchant API)
+func (h *MerchantAPIHandler) GetPayments(c *gin.Context) {
+	merchant, ok := h.currentMerchant(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.QRPayment{}).Where("merchant_id = ?", merchant.ID)
+	if billNumber := c.Query("bill_number"); billNumber != "" {
+		query = query.Where("bill_number = ?", billNumber)
+	}
+	if date := c.Query("date"); date != "" {
+		day, err := time.ParseInLocation("2006-01-02", date, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid date format, use YYYY-MM-DD",
+			})
+			return
+		}
+		query = query.Where("created_at >= ? AND created_at < ?", day, day.AddDate(0, 0, 1))
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var payments []models.QRPayment
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&payments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch payments",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Payments retrieved successfully",
+		Data: gin.H{
+			"payments": payments,
+			"pagination": gin.H{
+				"page":        page,
+				"limit":       limit,
+				"total":       total,
+				"total_pages": int(math.Ceil(float64(total) / float64(limit))),
+			},
+		},
+	})
+}
+
+// GetPaymentByReference - Get a single payment to confirm it was paid (merchant API)
+func (h *MerchantAPIHandler) GetPaymentByReference(c *gin.Context) {
+	merchant, ok := h.currentMerchant(c)
+	if !ok {
+		return
+	}
+
+	var payment models.QRPayment
+	if err := h.DB.Where("reference = ? AND merchant_id = ?", c.Param("reference"), merchant.ID).First(&payment).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Payment not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Payment retrieved successfully",
+		Data:    payment,
+	})
+}
+
+// GetSettlements - List the merchant's settlements (merchant API)
+func (h *MerchantAPIHandler) GetSettlements(c *gin.Context) {
+	merchant, ok := h.currentMerchant(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.MerchantSettlement{}).Where("merchant_id = ?", merchant.ID)
+
+	var total int64
+	query.Count(&total)
+
+	var settlements []models.MerchantSettlement
+	if err := query.Order("business_date DESC").Limit(limit).Offset(offset).Find(&settlements).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch settlements",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Settlements retrieved successfully",
+		Data: gin.H{
+			"settlements": settlements,
+			"pagination": gin.H{
+				"page":        page,
+				"limit":       limit,
+				"total":       total,
+				"total_pages": int(math.Ceil(float64(total) / float64(limit))),
+			},
+		},
+	})
+}
+
+// DownloadSettlementReport - Download a settlement report as CSV (merchant API)
+func (h *MerchantAPIHandler) DownloadSettlementReport(c *gin.Context) {
+	merchant, ok := h.currentMerchant(c)
+	if !ok {
+		return
+	}
+
+	var settlement models.MerchantSettlement
+	if err := h.DB.Where("id = ? AND merchant_id = ?", c.Param("id"), merchant.ID).First(&settlement).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Settlement not found",
+		})
+		return
+	}
+
+	writeSettlementReport(c, h.DB, &settlement)
+}
@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mbankingcore/models"
+	"mbankingcore/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MerchantSettlementHandler struct {
+	DB *gorm.DB
+}
+
+func NewMerchantSettlementHandler(db *gorm.DB) *MerchantSettlementHandler {
+	return &MerchantSettlementHandler{DB: db}
+}
+
+// MerchantSettlementRunResult summarises one settlement run
+type MerchantSettlementRunResult struct {
+	BusinessDate string                      `json:"business_date"`
+	Settled      int                         `json:"settled"`
+	Failed       int                         `json:"failed"`
+	Settlements  []models.MerchantSettlement `json:"settlements"`
+}
+
+// RunDailySettlement nets every merchant's unsettled payments of a business date into its
+// settlement account. Runs are idempotent: settled payments are skipped and failed merchants
+// are retried on the next run.
+func (h *MerchantSettlementHandler) RunDailySettlement(businessDate time.Time) (*MerchantSettlementRunResult, error) {
+	start := time.Date(businessDate.Year(), businessDate.Month(), businessDate.Day(), 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 0, 1)
+
+	var merchantIDs []uint
+	if err := h.DB.Model(&models.QRPayment{}).
+		Where("payment_type = ? AND status = ? AND settlement_id IS NULL AND created_at >= ? AND created_at < ?",
+			models.QR_PAYMENT_TYPE_MERCHANT, "completed", start, end).
+		Distinct().Pluck("merchant_id", &merchantIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to find unsettled payments: %v", err)
+	}
+
+	result := &MerchantSettlementRunResult{
+		BusinessDate: start.Format("2006-01-02"),
+		Settlements:  []models.MerchantSettlement{},
+	}
+
+	for _, merchantID := range merchantIDs {
+		settlement, err := h.settleMerchant(merchantID, start, end)
+		if err != nil {
+			log.Printf("Merchant settlement %d for %s failed: %v", merchantID, result.BusinessDate, err)
+			settlement = h.recordFailedSettlement(merchantID, start, err.Error())
+			result.Failed++
+		} else if settlement == nil {
+			continue
+		} else {
+			result.Settled++
+		}
+		if settlement != nil {
+			result.Settlements = append(result.Settlements, *settlement)
+		}
+	}
+
+	return result, nil
+}
+
+// settleMerchant settles one merchant's business date in a single database transaction.
+// It returns nil without error when there is nothing left to settle.
+func (h *MerchantSettlementHandler) settleMerchant(merchantID uint, start, end time.Time) (*models.MerchantSettlement, error) {
+	tx := h.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var merchant models.Merchant
+	if err := tx.First(&merchant, merchantID).Error; err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to get merchant: %v", err)
+	}
+
+	var settlement models.MerchantSettlement
+	existing := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("merchant_id = ? AND business_date = ?", merchant.ID, start.Format("2006-01-02")).
+		Limit(1).Find(&settlement)
+	if existing.Error != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to get settlement: %v", existing.Error)
+	}
+	if existing.RowsAffected > 0 && settlement.Status == models.MERCHANT_SETTLEMENT_COMPLETED {
+		tx.Rollback()
+		return nil, nil
+	}
+
+	var payments []models.QRPayment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("merchant_id = ? AND payment_type = ? AND status = ? AND settlement_id IS NULL AND created_at >= ? AND created_at < ?",
+			merchant.ID, models.QR_PAYMENT_TYPE_MERCHANT, "completed", start, end).
+		Find(&payments).Error; err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to lock payments: %v", err)
+	}
+	if len(payments) == 0 {
+		tx.Rollback()
+		return nil, nil
+	}
+
+	var grossAmount, mdrAmount int64
+	paymentIDs := make([]uint, 0, len(payments))
+	for _, payment := range payments {
+		grossAmount += payment.TotalAmount
+		mdrAmount += payment.MDRAmount
+		paymentIDs = append(paymentIDs, payment.ID)
+	}
+	netAmount := grossAmount - mdrAmount
+
+	if settlement.Reference == "" {
+		settlement.Reference = utils.GenerateReference("MST")
+	}
+	settlement.MerchantID = merchant.ID
+	settlement.BusinessDate = start
+	settlement.PaymentCount = len(payments)
+	settlement.GrossAmount = grossAmount
+	settlement.MDRAmount = mdrAmount
+	settlement.NetAmount = netAmount
+	settlement.SettlementAccountNumber = merchant.SettlementAccountNumber
+
+	var transactionID *uint
+	if netAmount > 0 {
+		creditTxn, err := creditUserBalance(tx, merchant.UserID, netAmount, "merchant_settlement",
+			fmt.Sprintf("QRIS settlement %s %s", merchant.MerchantID, settlement.BusinessDate.Format("2006-01-02")))
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		transactionID = &creditTxn.ID
+	}
+
+	if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_MERCHANT_PAYABLE, -grossAmount, settlement.Reference,
+		"Settlement to merchant "+merchant.MerchantID, transactionID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if mdrAmount > 0 {
+		if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_FEE_INCOME, mdrAmount, settlement.Reference,
+			"MDR from merchant "+merchant.MerchantID, transactionID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	settlement.Status = models.MERCHANT_SETTLEMENT_COMPLETED
+	settlement.FailureReason = ""
+	settlement.TransactionID = transactionID
+	settlement.SettledAt = &now
+	if err := tx.Save(&settlement).Error; err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to save settlement: %v", err)
+	}
+
+	if err := tx.Model(&models.QRPayment{}).Where("id IN ?", paymentIDs).
+		Update("settlement_id", settlement.ID).Error; err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to mark payments settled: %v", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("failed to commit settlement: %v", err)
+	}
+
+	settlement.Merchant = merchant
+	return &settlement, nil
+}
+
+// recordFailedSettlement stores the failure reason so the run can be inspected and retried
+func (h *MerchantSettlementHandler) recordFailedSettlement(merchantID uint, businessDate time.Time, reason string) *models.MerchantSettlement {
+	var settlement models.MerchantSettlement
+	err := h.DB.Where("merchant_id = ? AND business_date = ?", merchantID, businessDate.Format("2006-01-02")).First(&settlement).Error
+	if err != nil {
+		settlement = models.MerchantSettlement{
+			Reference:    utils.GenerateReference("MST"),
+			MerchantID:   merchantID,
+			BusinessDate: businessDate,
+		}
+	}
+
+	settlement.Status = models.MERCHANT_SETTLEMENT_FAILED
+	settlement.FailureReason = reason
+	if err := h.DB.Save(&settlement).Error; err != nil {
+		log.Printf("Failed to record failed merchant settlement %d: %v", merchantID, err)
+		return nil
+	}
+	return &settlement
+}
+
+// RunSettlement - Settle merchant payments for a business date (admin)
+func (h *MerchantSettlementHandler) RunSettlement(c *gin.Context) {
+	var req models.RunMerchantSettlementRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	businessDate := time.Now().AddDate(0, 0, -1)
+	if req.BusinessDate != "" {
+		businessDate, _ = time.ParseInLocation("2006-01-02", req.BusinessDate, time.Local)
+	}
+
+	result, err := h.RunDailySettlement(businessDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Merchant settlement completed",
+		Data:    result,
+	})
+}
+
+// GetSettlements - List merchant settlements (admin)
+func (h *MerchantSettlementHandler) GetSettlements(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.MerchantSettlement{})
+	if merchantID := c.Query("merchant_id"); merchantID != "" {
+		query = query.Where("merchant_id = ?", merchantID)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if date := c.Query("business_date"); date != "" {
+		query = query.Where("business_date = ?", date)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var settlements []models.MerchantSettlement
+	if err := query.Preload("Merchant").Order("business_date DESC, id DESC").Limit(limit).Offset(offset).Find(&settlements).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch merchant settlements",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Merchant settlements retrieved successfully",
+		Data: gin.H{
+			"settlements": settlements,
+			"pagination": gin.H{
+				"page":        page,
+				"limit":       limit,
+				"total":       total,
+				"total_pages": int(math.Ceil(float64(total) / float64(limit))),
+			},
+		},
+	})
+}
+
+// DownloadSettlementReport - Download the payments behind a settlement as CSV (admin)
+func (h *MerchantSettlementHandler) DownloadSettlementReport(c *gin.Context) {
+	var settlement models.MerchantSettlement
+	if err := h.DB.First(&settlement, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Merchant settlement not found",
+		})
+		return
+	}
+
+	writeSettlementReport(c, h.DB, &settlement)
+}
+
+// writeSettlementReport streams a settlement's payments as CSV, shared by the admin and merchant APIs
+func writeSettlementReport(c *gin.Context, db *gorm.DB, settlement *models.MerchantSettlement) {
+	var payments []models.QRPayment
+	if err := db.Where("settlement_id = ?", settlement.ID).Order("created_at ASC").Find(&payments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch settled payments",
+		})
+		return
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s-report.csv", settlement.Reference))
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{"reference", "paid_at", "bill_number", "terminal_label", "amount", "tip_amount", "total_amount", "mdr_amount", "net_amount"})
+	for _, payment := range payments {
+		writer.Write([]string{
+			payment.Reference,
+			payment.CreatedAt.Format(time.RFC3339),
+			payment.BillNumber,
+			payment.TerminalLabel,
+			strconv.FormatInt(payment.Amount, 10),
+			strconv.FormatInt(payment.TipAmount, 10),
+			strconv.FormatInt(payment.TotalAmount, 10),
+			strconv.FormatInt(payment.MDRAmount, 10),
+			strconv.FormatInt(payment.TotalAmount-payment.MDRAmount, 10),
+		})
+	}
+	writer.Write([]string{"TOTAL", settlement.BusinessDate.Format("2006-01-02"), "", "", "", "",
+		strconv.FormatInt(settlement.GrossAmount, 10),
+		strconv.FormatInt(settlement.MDRAmount, 10),
+		strconv.FormatInt(settlement.NetAmount, 10),
+	})
+	writer.Flush()
+}
@@ -0,0 +1,468 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"mbankingcore/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const defaultMaxPocketsPerUser = 10
+
+// errInsufficientPocketBalance is returned when moving more out of a pocket than it holds
+var errInsufficientPocketBalance = errors.New("insufficient pocket balance")
+
+type PocketHandler struct {
+	DB *gorm.DB
+}
+
+func NewPocketHandler(db *gorm.DB) *PocketHandler {
+	return &PocketHandler{DB: db}
+}
+
+// GetPockets - List the authenticated user's pockets with available and total balance
+func (h *PocketHandler) GetPockets(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	var user models.User
+	if err := h.DB.Select("id", "balance").First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+		})
+		return
+	}
+
+	var pockets []models.Pocket
+	if err := h.DB.Where("user_id = ? AND status = ?", userID, models.POCKET_STATUS_ACTIVE).
+		Order("created_at ASC").Find(&pockets).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch pockets",
+		})
+		return
+	}
+
+	var pocketBalance int64
+	items := make([]models.PocketResponse, 0, len(pockets))
+	for _, pocket := range pockets {
+		pocketBalance += pocket.Balance
+		items = append(items, pocket.ToResponse())
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Pockets retrieved successfully",
+		Data: gin.H{
+			"pockets":           items,
+			"available_balance": user.Balance,
+			"pocket_balance":    pocketBalance,
+			"total_balance":     user.Balance + pocketBalance,
+		},
+	})
+}
+
+// CreatePocket - Create a savings pocket
+func (h *PocketHandler) CreatePocket(c *gin.Context) {
+	userIDValue, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+	userID := userIDValue.(uint)
+
+	var req models.PocketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var count int64
+	h.DB.Model(&models.Pocket{}).Where("user_id = ? AND status = ?", userID, models.POCKET_STATUS_ACTIVE).Count(&count)
+	if maxPockets := getConfigInt64(h.DB, "max_pockets_per_user", defaultMaxPocketsPerUser); count >= maxPockets {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("You can have at most %d pockets", maxPockets),
+		})
+		return
+	}
+
+	pocket := models.Pocket{UserID: userID, Status: models.POCKET_STATUS_ACTIVE}
+	if message := applyPocketRequest(&pocket, &req); message != "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: message,
+		})
+		return
+	}
+
+	if err := h.DB.Create(&pocket).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create pocket",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Code:    http.StatusCreated,
+		Message: "Pocket created successfully",
+		Data:    pocket.ToResponse(),
+	})
+}
+
+// GetPocket - Get a pocket with its recent movements
+func (h *PocketHandler) GetPocket(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	var pocket models.Pocket
+	if err := h.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&pocket).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Pocket not found",
+		})
+		return
+	}
+
+	var movements []models.PocketMovement
+	if err := h.DB.Where("pocket_id = ?", pocket.ID).Order("created_at DESC").Limit(50).Find(&movements).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch pocket movements",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Pocket retrieved successfully",
+		Data: gin.H{
+			"pocket":    pocket.ToResponse(),
+			"movements": movements,
+		},
+	})
+}
+
+// UpdatePocket - Update a pocket's name, target and auto-save rule
+func (h *PocketHandler) UpdatePocket(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	var pocket models.Pocket
+	if err := h.DB.Where("id = ? AND user_id = ? AND status = ?", c.Param("id"), userID, models.POCKET_STATUS_ACTIVE).
+		First(&pocket).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Pocket not found",
+		})
+		return
+	}
+
+	var req models.PocketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if message := applyPocketRequest(&pocket, &req); message != "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: message,
+		})
+		return
+	}
+
+	if err := h.DB.Model(&pocket).Select("name", "target_amount", "target_date", "auto_save_type",
+		"auto_save_amount", "round_up_unit").Updates(&pocket).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update pocket",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Pocket updated successfully",
+		Data:    pocket.ToResponse(),
+	})
+}
+
+// MoveIn - Move money from the main balance into a pocket
+func (h *PocketHandler) MoveIn(c *gin.Context) {
+	h.move(c, models.POCKET_MOVEMENT_IN)
+}
+
+// MoveOut - Move money from a pocket back to the main balance
+func (h *PocketHandler) MoveOut(c *gin.Context) {
+	h.move(c, models.POCKET_MOVEMENT_OUT)
+}
+
+func (h *PocketHandler) move(c *gin.Context, movementType string) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	var req models.PocketMoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	tx := h.DB.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var pocket models.Pocket
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND user_id = ? AND status = ?", c.Param("id"), userID, models.POCKET_STATUS_ACTIVE).
+		First(&pocket).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Pocket not found",
+		})
+		return
+	}
+
+	movement, err := movePocketFunds(tx, &pocket, movementType, req.Amount, nil)
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, errInsufficientPocketBalance) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Insufficient pocket balance",
+			})
+			return
+		}
+		respondLedgerError(c, err, "Failed to move money")
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to commit pocket movement",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Money moved successfully",
+		Data: gin.H{
+			"pocket":   pocket.ToResponse(),
+			"movement": movement,
+		},
+	})
+}
+
+// ClosePocket - Move the remaining balance back to the main balance and close the pocket
+func (h *PocketHandler) ClosePocket(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	tx := h.DB.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var pocket models.Pocket
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND user_id = ? AND status = ?", c.Param("id"), userID, models.POCKET_STATUS_ACTIVE).
+		First(&pocket).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Pocket not found",
+		})
+		return
+	}
+
+	if pocket.Balance > 0 {
+		if _, err := movePocketFunds(tx, &pocket, models.POCKET_MOVEMENT_OUT, pocket.Balance, nil); err != nil {
+			tx.Rollback()
+			respondLedgerError(c, err, "Failed to move pocket balance")
+			return
+		}
+	}
+
+	now := time.Now()
+	if err := tx.Model(&pocket).Updates(map[string]interface{}{
+		"status":    models.POCKET_STATUS_CLOSED,
+		"closed_at": now,
+	}).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to close pocket",
+		})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to commit pocket closure",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Pocket closed successfully",
+		Data:    pocket.ToResponse(),
+	})
+}
+
+// applyAutoSave sweeps money into the user's pockets after a transfer out according to each
+// pocket's auto-save rule. Pockets that reached their target or that the remaining balance
+// cannot cover are skipped. It must be called inside the transfer's database transaction.
+func applyAutoSave(tx *gorm.DB, userID uint, transfer *models.Transaction) error {
+	var pockets []models.Pocket
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND status = ? AND auto_save_type <> ''", userID, models.POCKET_STATUS_ACTIVE).
+		Order("id ASC").Find(&pockets).Error; err != nil {
+		return fmt.Errorf("failed to get auto-save pockets: %v", err)
+	}
+
+	for i := range pockets {
+		pocket := &pockets[i]
+		if pocket.TargetReached() {
+			continue
+		}
+		amount := pocket.AutoSaveAmountFor(transfer.Amount)
+		if amount <= 0 {
+			continue
+		}
+
+		_, err := movePocketFunds(tx, pocket, models.POCKET_MOVEMENT_AUTO_SAVE, amount, &transfer.ID)
+		if errors.Is(err, errInsufficientBalance) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// movePocketFunds moves amount between the main balance and a locked pocket and records the movement.
+// It must be called inside a database transaction.
+func movePocketFunds(tx *gorm.DB, pocket *models.Pocket, movementType string, amount int64, sourceTxnID *uint) (*models.PocketMovement, error) {
+	var txn *models.Transaction
+	var err error
+	balanceBefore := pocket.Balance
+
+	switch movementType {
+	case models.POCKET_MOVEMENT_OUT:
+		if pocket.Balance < amount {
+			return nil, errInsufficientPocketBalance
+		}
+		txn, err = creditUserBalance(tx, pocket.UserID, amount, "pocket_move_out", "Move from pocket "+pocket.Name)
+		if err != nil {
+			return nil, err
+		}
+		pocket.Balance -= amount
+	default:
+		description := "Move to pocket " + pocket.Name
+		if movementType == models.POCKET_MOVEMENT_AUTO_SAVE {
+			description = "Auto-save to pocket " + pocket.Name
+		}
+		txn, err = debitUserBalance(tx, pocket.UserID, amount, "pocket_move_in", description)
+		if err != nil {
+			return nil, err
+		}
+		pocket.Balance += amount
+	}
+
+	if err := tx.Model(pocket).Update("balance", pocket.Balance).Error; err != nil {
+		return nil, fmt.Errorf("failed to update pocket balance: %v", err)
+	}
+
+	movement := models.PocketMovement{
+		PocketID:            pocket.ID,
+		UserID:              pocket.UserID,
+		Type:                movementType,
+		Amount:              amount,
+		BalanceBefore:       balanceBefore,
+		BalanceAfter:        pocket.Balance,
+		TransactionID:       txn.ID,
+		SourceTransactionID: sourceTxnID,
+		Description:         txn.Description,
+	}
+	if err := tx.Create(&movement).Error; err != nil {
+		return nil, fmt.Errorf("failed to create pocket movement: %v", err)
+	}
+
+	return &movement, nil
+}
+
+// applyPocketRequest copies a pocket request onto a pocket, returning a validation message on error
+func applyPocketRequest(pocket *models.Pocket, req *models.PocketRequest) string {
+	pocket.Name = req.Name
+	pocket.TargetAmount = req.TargetAmount
+	pocket.TargetDate = nil
+	if req.TargetDate != "" {
+		targetDate, _ := time.ParseInLocation("2006-01-02", req.TargetDate, time.Local)
+		if !targetDate.After(time.Now()) {
+			return "Target date must be in the future"
+		}
+		pocket.TargetDate = &targetDate
+	}
+
+	pocket.AutoSaveType = req.AutoSaveType
+	pocket.AutoSaveAmount = 0
+	pocket.RoundUpUnit = 0
+	switch req.AutoSaveType {
+	case models.POCKET_AUTO_SAVE_FIXED:
+		if req.AutoSaveAmount <= 0 {
+			return "auto_save_amount is required for fixed auto-save"
+		}
+		pocket.AutoSaveAmount = req.AutoSaveAmount
+	case models.POCKET_AUTO_SAVE_ROUND_UP:
+		if req.RoundUpUnit <= 0 {
+			return "round_up_unit is required for round-up auto-save"
+		}
+		pocket.RoundUpUnit = req.RoundUpUnit
+	}
+
+	return ""
+}
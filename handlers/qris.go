@@ -0,0 +1,529 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"mbankingcore/models"
+	"mbankingcore/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Default city printed on personal receive-money QR (overridable via config key "qris_default_city")
+const defaultQRISCity = "JAKARTA"
+
+type QRISHandler struct {
+	DB *gorm.DB
+}
+
+func NewQRISHandler(db *gorm.DB) *QRISHandler {
+	return &QRISHandler{DB: db}
+}
+
+// qrTarget is the resolved recipient of a scanned QR
+type qrTarget struct {
+	PaymentType string
+	Merchant    *models.Merchant
+	Account     *models.BankAccount
+	UserID      uint
+	Name        string
+	AccountRef  string
+}
+
+// ParseQR - Decode a scanned QR and resolve the recipient before payment
+func (h *QRISHandler) ParseQR(c *gin.Context) {
+	var req models.QRParseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	qr, target, err := h.resolveQR(req.Payload)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "QR parsed successfully",
+		Data: gin.H{
+			"payment_type":      target.PaymentType,
+			"recipient_name":    target.Name,
+			"recipient_account": target.AccountRef,
+			"qr_type":           qrType(qr),
+			"amount":            qr.Amount,
+			"tip_indicator":     qr.TipIndicator,
+			"convenience_fee":   qr.ConvenienceFee(qr.Amount),
+			"qr":                qr,
+		},
+	})
+}
+
+// PayQR - Pay a merchant or another customer by scanning their QR
+func (h *QRISHandler) PayQR(c *gin.Context) {
+	userIDValue, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+	userID := userIDValue.(uint)
+
+	var req models.QRPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	qr, target, err := h.resolveQR(req.Payload)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if target.UserID == userID {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Cannot pay your own QR",
+		})
+		return
+	}
+
+	// Dynamic QR carries the amount; static QR needs it from the customer
+	amount := qr.Amount
+	if qr.IsDynamic() {
+		if req.Amount != 0 && req.Amount != qr.Amount {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Amount cannot be changed for a dynamic QR",
+			})
+			return
+		}
+	} else {
+		if req.Amount <= 0 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Amount is required for a static QR",
+			})
+			return
+		}
+		amount = req.Amount
+	}
+
+	tip := qr.ConvenienceFee(amount)
+	if qr.TipIndicator == utils.EMVQR_TIP_PROMPT {
+		tip = req.Tip
+	}
+
+	payloadHash := ""
+	if qr.IsDynamic() {
+		sum := sha256.Sum256([]byte(strings.TrimSpace(req.Payload)))
+		payloadHash = hex.EncodeToString(sum[:])
+
+		if h.dynamicQRPaid(payloadHash) {
+			c.JSON(http.StatusConflict, models.ErrorResponse{
+				Code:    http.StatusConflict,
+				Message: "This QR has already been paid",
+			})
+			return
+		}
+	}
+
+	payment := models.QRPayment{
+		Reference:        utils.GenerateReference("QRP"),
+		UserID:           userID,
+		PaymentType:      target.PaymentType,
+		RecipientName:    target.Name,
+		RecipientAccount: target.AccountRef,
+		Amount:           amount,
+		TipAmount:        tip,
+		TotalAmount:      amount + tip,
+		QRType:           qrType(qr),
+		PayloadHash:      payloadHash,
+		Status:           "completed",
+	}
+	if qr.AdditionalData != nil {
+		payment.BillNumber = qr.AdditionalData.BillNumber
+		payment.TerminalLabel = qr.AdditionalData.TerminalLabel
+	}
+
+	debitType := "qr_payment"
+	debitDesc := "QR payment to " + target.Name
+	if target.PaymentType == models.QR_PAYMENT_TYPE_PERSONAL {
+		debitType = "transfer_out"
+		debitDesc = "QR transfer to " + target.AccountRef
+		payment.RecipientUserID = &target.UserID
+	} else {
+		payment.MerchantID = &target.Merchant.ID
+		payment.MDRAmount = target.Merchant.CalculateMDR(payment.TotalAmount)
+	}
+	if payment.BillNumber != "" {
+		debitDesc += " (bill " + payment.BillNumber + ")"
+	}
+
+	// A personal QR moves money between customers, so it is screened like a transfer: sanctions on both
+	// parties, new device limits, then the fraud rules
+	var fraudDecision *models.FraudDecision
+	if target.PaymentType == models.QR_PAYMENT_TYPE_PERSONAL {
+		var payer models.User
+		if err := h.DB.First(&payer, userID).Error; err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "User not found",
+			})
+			return
+		}
+		if !screenTransfer(c, h.DB, &payer, &target.UserID, target.Account.User.Name, target.AccountRef) {
+			return
+		}
+	}
+	if !checkDeviceCoolingOff(c, h.DB, userID, models.BASE_CURRENCY, payment.TotalAmount) {
+		return
+	}
+	if target.PaymentType == models.QR_PAYMENT_TYPE_PERSONAL {
+		fraudDecision = screenTransaction(c, h.DB, fraudCheck{
+			UserID:             userID,
+			TransactionType:    models.FRAUD_CHECK_TYPE_TRANSFER,
+			Amount:             payment.TotalAmount,
+			Currency:           models.BASE_CURRENCY,
+			BeneficiaryUserID:  &target.UserID,
+			BeneficiaryAccount: target.AccountRef,
+			DeviceID:           fraudDeviceID(c, h.DB),
+			IPAddress:          c.ClientIP(),
+		}, req.FraudDecisionID, req.PIN)
+		if fraudDecision == nil {
+			return
+		}
+	}
+
+	tx := h.DB.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	debitTxn, err := debitUserBalance(tx, userID, payment.TotalAmount, debitType, debitDesc)
+	if err != nil {
+		tx.Rollback()
+		respondLedgerError(c, err, "Failed to debit account")
+		return
+	}
+	payment.DebitTransactionID = &debitTxn.ID
+
+	if target.PaymentType == models.QR_PAYMENT_TYPE_PERSONAL {
+		creditTxn, err := creditUserBalance(tx, target.UserID, payment.TotalAmount, "transfer_in", "QR transfer "+payment.Reference)
+		if err != nil {
+			tx.Rollback()
+			if errors.Is(err, errUserNotActive) {
+				c.JSON(http.StatusBadRequest, models.ErrorResponse{
+					Code:    http.StatusBadRequest,
+					Message: "Recipient cannot receive payments",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to credit recipient",
+			})
+			return
+		}
+		payment.CreditTransactionID = &creditTxn.ID
+
+		// Link the two legs so a reversal of either one reverses both
+		if err := tx.Model(debitTxn).Update("counterparty_txn_id", creditTxn.ID).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to link transfer transactions",
+			})
+			return
+		}
+		if err := tx.Model(creditTxn).Update("counterparty_txn_id", debitTxn.ID).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to link transfer transactions",
+			})
+			return
+		}
+
+		if err := completeFraudDecision(tx, fraudDecision, debitTxn.ID); err != nil {
+			tx.Rollback()
+			respondFraudDecisionError(c, err)
+			return
+		}
+
+		if err := applyAutoSave(tx, userID, debitTxn); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to apply pocket auto-save",
+			})
+			return
+		}
+	} else {
+		// Merchant funds are held until the daily settlement nets them into the settlement account
+		if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_MERCHANT_PAYABLE, payment.TotalAmount, payment.Reference,
+			"QR payment to "+target.Merchant.MerchantID, &debitTxn.ID); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to credit merchant",
+			})
+			return
+		}
+	}
+
+	if err := tx.Create(&payment).Error; err != nil {
+		tx.Rollback()
+		// A concurrent payment of the same dynamic QR won the unique index; the debit above is rolled back
+		if payloadHash != "" && h.dynamicQRPaid(payloadHash) {
+			c.JSON(http.StatusConflict, models.ErrorResponse{
+				Code:    http.StatusConflict,
+				Message: "This QR has already been paid",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to record QR payment",
+		})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to commit QR payment",
+		})
+		return
+	}
+
+	data := gin.H{
+		"payment":        payment,
+		"transaction_id": debitTxn.ID,
+		"balance":        debitTxn.BalanceAfter,
+	}
+	if fraudDecision != nil {
+		data["fraud_decision_id"] = fraudDecision.ID
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "QR payment successful",
+		Data:    data,
+	})
+}
+
+// GenerateReceiveQR - Generate a personal receive-money QR for the user's primary account
+// Without an amount a static QR is returned; with ?amount= a dynamic QR for a single payment.
+func (h *QRISHandler) GenerateReceiveQR(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	var amount int64
+	if value := c.Query("amount"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Amount must be a positive whole number",
+			})
+			return
+		}
+		amount = parsed
+	}
+
+	var account models.BankAccount
+	if err := h.DB.Where("user_id = ? AND is_primary = ? AND is_active = ?", userID, true, true).
+		First(&account).Error; err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Set an active primary bank account before generating a receive QR",
+		})
+		return
+	}
+
+	city := defaultQRISCity
+	var cityConfig models.Config
+	if err := h.DB.Where("key = ?", "qris_default_city").First(&cityConfig).Error; err == nil && cityConfig.Value != "" {
+		city = strings.ToUpper(cityConfig.Value)
+	}
+
+	qr := &utils.EMVQR{
+		MerchantAccounts: []utils.EMVQRMerchantAccount{
+			{
+				Tag:         "26",
+				GUI:         utils.QRIS_PERSONAL_GUI,
+				MerchantPAN: account.AccountNumber,
+			},
+		},
+		MCC:          utils.QRIS_MCC_PERSONAL,
+		Currency:     utils.QRIS_CURRENCY_IDR,
+		Amount:       amount,
+		CountryCode:  utils.QRIS_COUNTRY_ID,
+		MerchantName: strings.ToUpper(account.AccountName),
+		MerchantCity: city,
+	}
+	if note := c.Query("note"); note != "" {
+		qr.AdditionalData = &utils.EMVQRAdditionalData{Purpose: note}
+	}
+
+	payload, err := utils.GenerateEMVQR(qr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Failed to generate QR: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Receive QR generated successfully",
+		Data: gin.H{
+			"payload":        payload,
+			"account_number": account.AccountNumber,
+			"account_name":   account.AccountName,
+			"amount":         amount,
+			"qr_type":        qrType(qr),
+		},
+	})
+}
+
+// GetUserQRPayments - Get QR payment history for the authenticated user
+func (h *QRISHandler) GetUserQRPayments(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.QRPayment{}).Where("user_id = ?", userID)
+
+	var total int64
+	query.Count(&total)
+
+	var payments []models.QRPayment
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&payments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch QR payments",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "QR payments retrieved successfully",
+		Data: gin.H{
+			"payments": payments,
+			"pagination": gin.H{
+				"current_page": page,
+				"per_page":     limit,
+				"total":        total,
+				"total_pages":  (total + int64(limit) - 1) / int64(limit),
+			},
+		},
+	})
+}
+
+// resolveQR parses a payload and finds the merchant or customer it pays
+func (h *QRISHandler) resolveQR(payload string) (*utils.EMVQR, *qrTarget, error) {
+	qr, err := utils.ParseEMVQR(payload)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid QR: %v", err)
+	}
+
+	if qr.Currency != utils.QRIS_CURRENCY_IDR || qr.CountryCode != utils.QRIS_COUNTRY_ID {
+		return nil, nil, errors.New("only IDR payments in Indonesia are supported")
+	}
+
+	// Personal receive-money QR issued by this bank
+	if personal := qr.FindMerchantAccount(utils.QRIS_PERSONAL_GUI); personal != nil {
+		var account models.BankAccount
+		if err := h.DB.Preload("User").
+			Where("account_number = ? AND is_active = ?", personal.MerchantPAN, true).
+			First(&account).Error; err != nil {
+			return nil, nil, errors.New("recipient account not found or inactive")
+		}
+		return qr, &qrTarget{
+			PaymentType: models.QR_PAYMENT_TYPE_PERSONAL,
+			Account:     &account,
+			UserID:      account.UserID,
+			Name:        account.AccountName,
+			AccountRef:  account.AccountNumber,
+		}, nil
+	}
+
+	// Merchant QR: match on our acquirer PAN first, then on the national merchant ID
+	var merchant models.Merchant
+	found := false
+	if acquirer := qr.FindMerchantAccount(utils.QRIS_ACQUIRER_GUI); acquirer != nil && acquirer.MerchantPAN != "" {
+		found = h.DB.Where("merchant_pan = ?", acquirer.MerchantPAN).First(&merchant).Error == nil
+	}
+	if national := qr.FindMerchantAccount(utils.QRIS_GUI); !found && national != nil && national.MerchantID != "" {
+		found = h.DB.Where("merchant_id = ?", national.MerchantID).First(&merchant).Error == nil
+	}
+	if !found {
+		return nil, nil, errors.New("merchant is not registered with this bank")
+	}
+	if merchant.Status != models.MERCHANT_STATUS_ACTIVE {
+		return nil, nil, errors.New("merchant is not active")
+	}
+
+	return qr, &qrTarget{
+		PaymentType: models.QR_PAYMENT_TYPE_MERCHANT,
+		Merchant:    &merchant,
+		UserID:      merchant.UserID,
+		Name:        merchant.Name,
+		AccountRef:  merchant.MerchantPAN,
+	}, nil
+}
+
+// dynamicQRPaid reports whether a completed payment already exists for a dynamic QR payload hash
+func (h *QRISHandler) dynamicQRPaid(payloadHash string) bool {
+	var paid int64
+	h.DB.Model(&models.QRPayment{}).Where("payload_hash = ? AND status = ?", payloadHash, "completed").Count(&paid)
+	return paid > 0
+}
+
+func qrType(qr *utils.EMVQR) string {
+	if qr.IsDynamic() {
+		return "dynamic"
+	}
+	return "static"
+}
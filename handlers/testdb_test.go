@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"testing"
+
+	"mbankingcore/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB opens an in-memory database with the given models migrated and every system account
+// created with a zero balance. A single connection keeps the database alive for the whole test.
+func newTestDB(t *testing.T, tables ...interface{}) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get database handle: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	tables = append(tables, &models.User{}, &models.Config{}, &models.Transaction{},
+		&models.SystemAccount{}, &models.SystemAccountEntry{})
+	if err := db.AutoMigrate(tables...); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	for _, code := range []string{
+		models.SYSTEM_ACCOUNT_INTERBANK_SUSPENSE,
+		models.SYSTEM_ACCOUNT_INTERBANK_SETTLEMENT,
+		models.SYSTEM_ACCOUNT_FEE_INCOME,
+		models.SYSTEM_ACCOUNT_VA_SUSPENSE,
+		models.SYSTEM_ACCOUNT_REVERSAL_RECEIVABLE,
+		models.SYSTEM_ACCOUNT_UNCLAIMED_REFUNDS,
+		models.SYSTEM_ACCOUNT_DISBURSEMENT_FUNDING,
+	} {
+		if err := db.Create(&models.SystemAccount{Code: code, Name: code}).Error; err != nil {
+			t.Fatalf("failed to create system account %s: %v", code, err)
+		}
+	}
+	return db
+}
+
+// createTestUser creates an active user with the given balance; names must be unique within a test
+func createTestUser(t *testing.T, db *gorm.DB, name string, balance int64) *models.User {
+	t.Helper()
+
+	user := models.User{
+		Name:       name,
+		Phone:      "0812-" + name,
+		MotherName: "Mother",
+		PinAtm:     "hash",
+		Balance:    balance,
+		Status:     models.USER_STATUS_ACTIVE,
+	}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user %s: %v", name, err)
+	}
+	return &user
+}
+
+// userBalance reads the current balance of a user
+func userBalance(t *testing.T, db *gorm.DB, userID uint) int64 {
+	t.Helper()
+
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		t.Fatalf("failed to get user %d: %v", userID, err)
+	}
+	return user.Balance
+}
+
+// systemAccountBalance reads the current balance of a system account
+func systemAccountBalance(t *testing.T, db *gorm.DB, code string) int64 {
+	t.Helper()
+
+	var account models.SystemAccount
+	if err := db.Where("code = ?", code).First(&account).Error; err != nil {
+		t.Fatalf("failed to get system account %s: %v", code, err)
+	}
+	return account.Balance
+}
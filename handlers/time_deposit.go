@@ -0,0 +1,949 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mbankingcore/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TimeDepositHandler struct {
+	DB *gorm.DB
+}
+
+func NewTimeDepositHandler(db *gorm.DB) *TimeDepositHandler {
+	return &TimeDepositHandler{DB: db}
+}
+
+// TimeDepositMaturityRunResult summarises one maturity run
+type TimeDepositMaturityRunResult struct {
+	Date       string `json:"date"`
+	RolledOver int    `json:"rolled_over"`
+	PaidOut    int    `json:"paid_out"`
+	Failed     int    `json:"failed"`
+}
+
+// errTimeDepositClosed is returned when a deposit is no longer active
+var errTimeDepositClosed = errors.New("time deposit is no longer active")
+
+// GetProducts - List active deposito products
+func (h *TimeDepositHandler) GetProducts(c *gin.Context) {
+	var products []models.TimeDepositProduct
+	if err := h.DB.Where("is_active = ?", true).Order("tenor_months ASC").Find(&products).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch time deposit products",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Time deposit products retrieved successfully",
+		Data:    products,
+	})
+}
+
+// OpenDeposit - Place a deposito by debiting the authenticated user's savings
+func (h *TimeDepositHandler) OpenDeposit(c *gin.Context) {
+	userIDValue, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+	userID := userIDValue.(uint)
+
+	var req models.OpenTimeDepositRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var product models.TimeDepositProduct
+	if err := h.DB.Where("is_active = ?", true).First(&product, req.ProductID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Time deposit product not found or inactive",
+		})
+		return
+	}
+	if req.Amount < product.MinAmount {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Minimum placement for %s is %d", product.Name, product.MinAmount),
+		})
+		return
+	}
+
+	var account models.BankAccount
+	if err := h.DB.Where("id = ? AND user_id = ?", req.BankAccountID, userID).First(&account).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Bank account not found",
+		})
+		return
+	}
+	if account.AccountType != "saving" || !account.IsActive ||
+		(account.Currency != "" && account.Currency != models.BASE_CURRENCY) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Time deposits must be funded from an active " + models.BASE_CURRENCY + " saving account",
+		})
+		return
+	}
+
+	depositNumber, err := generateDepositNumber()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to generate deposit number",
+		})
+		return
+	}
+
+	placementDate := today()
+	deposit := models.TimeDeposit{
+		DepositNumber:       depositNumber,
+		UserID:              userID,
+		ProductID:           product.ID,
+		BankAccountID:       account.ID,
+		Principal:           req.Amount,
+		TenorMonths:         product.TenorMonths,
+		RateBasisPoints:     product.RateBasisPoints,
+		PenaltyBasisPoints:  product.PenaltyBasisPoints,
+		TaxRateBasisPoints:  product.TaxRateBasisPoints,
+		MaturityInstruction: req.MaturityInstruction,
+		PlacementDate:       placementDate,
+		MaturityDate:        addMonthsClamped(placementDate, product.TenorMonths),
+		Status:              models.TIME_DEPOSIT_STATUS_ACTIVE,
+	}
+
+	tx := h.DB.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	debitTxn, err := debitUserBalance(tx, userID, deposit.Principal, "time_deposit_placement",
+		fmt.Sprintf("Deposito placement %s (%d months)", deposit.DepositNumber, deposit.TenorMonths))
+	if err != nil {
+		tx.Rollback()
+		respondLedgerError(c, err, "Failed to place time deposit")
+		return
+	}
+	deposit.PlacementTxnID = &debitTxn.ID
+
+	if err := tx.Create(&deposit).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create time deposit",
+		})
+		return
+	}
+
+	if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_TIME_DEPOSIT, deposit.Principal, deposit.DepositNumber,
+		"Deposito placement "+deposit.DepositNumber, &debitTxn.ID); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to place time deposit",
+		})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to commit time deposit",
+		})
+		return
+	}
+
+	deposit.Product = product
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Code:    http.StatusCreated,
+		Message: "Time deposit placed successfully",
+		Data:    deposit,
+	})
+}
+
+// GetUserDeposits - List the authenticated user's deposits
+func (h *TimeDepositHandler) GetUserDeposits(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.TimeDeposit{}).Where("user_id = ?", userID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var deposits []models.TimeDeposit
+	if err := query.Preload("Product").Order("created_at DESC").Limit(limit).Offset(offset).Find(&deposits).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch time deposits",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Time deposits retrieved successfully",
+		Data: gin.H{
+			"deposits": deposits,
+			"pagination": gin.H{
+				"current_page": page,
+				"per_page":     limit,
+				"total":        total,
+				"total_pages":  (total + int64(limit) - 1) / int64(limit),
+			},
+		},
+	})
+}
+
+// GetUserDepositByID - Get a deposit with its projected interest and early break amount
+func (h *TimeDepositHandler) GetUserDepositByID(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	var deposit models.TimeDeposit
+	if err := h.DB.Preload("Product").Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&deposit).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Time deposit not found",
+		})
+		return
+	}
+
+	data := gin.H{"deposit": deposit}
+	if deposit.Status == models.TIME_DEPOSIT_STATUS_ACTIVE {
+		gross := timeDepositInterest(deposit.Principal, deposit.RateBasisPoints, deposit.PlacementDate, deposit.MaturityDate)
+		tax := gross * int64(deposit.TaxRateBasisPoints) / 10000
+		penalty := deposit.Principal * int64(deposit.PenaltyBasisPoints) / 10000
+		data["projected_interest"] = gin.H{
+			"gross_interest": gross,
+			"tax_amount":     tax,
+			"net_interest":   gross - tax,
+		}
+		data["break_quote"] = gin.H{
+			"penalty_amount": penalty,
+			"payout_amount":  deposit.Principal - penalty,
+		}
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Time deposit retrieved successfully",
+		Data:    data,
+	})
+}
+
+// UpdateMaturityInstruction - Change the maturity instruction of an active deposit
+func (h *TimeDepositHandler) UpdateMaturityInstruction(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	var req models.UpdateMaturityInstructionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	result := h.DB.Model(&models.TimeDeposit{}).
+		Where("id = ? AND user_id = ? AND status = ?", c.Param("id"), userID, models.TIME_DEPOSIT_STATUS_ACTIVE).
+		Update("maturity_instruction", req.MaturityInstruction)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update maturity instruction",
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Active time deposit not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Maturity instruction updated successfully",
+		Data:    gin.H{"maturity_instruction": req.MaturityInstruction},
+	})
+}
+
+// BreakDeposit - Close a deposit before maturity. Interest is forfeited and the break penalty is
+// deducted from the principal.
+func (h *TimeDepositHandler) BreakDeposit(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	tx := h.DB.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var deposit models.TimeDeposit
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&deposit).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Time deposit not found",
+		})
+		return
+	}
+	if deposit.Status != models.TIME_DEPOSIT_STATUS_ACTIVE {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Time deposit is no longer active",
+		})
+		return
+	}
+	if !today().Before(deposit.MaturityDate) {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Time deposit has matured and will be processed by its maturity instruction",
+		})
+		return
+	}
+
+	penalty := deposit.Principal * int64(deposit.PenaltyBasisPoints) / 10000
+	payout := deposit.Principal - penalty
+
+	creditTxn, err := creditUserBalance(tx, deposit.UserID, payout, "time_deposit_break",
+		fmt.Sprintf("Deposito %s broken before maturity, penalty %d", deposit.DepositNumber, penalty))
+	if err != nil {
+		tx.Rollback()
+		respondLedgerError(c, err, "Failed to break time deposit")
+		return
+	}
+
+	if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_TIME_DEPOSIT, -deposit.Principal, deposit.DepositNumber,
+		"Deposito broken "+deposit.DepositNumber, &creditTxn.ID); err != nil {
+		tx.Rollback()
+		respondLedgerError(c, err, "Failed to break time deposit")
+		return
+	}
+	if penalty > 0 {
+		if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_FEE_INCOME, penalty, deposit.DepositNumber,
+			"Deposito break penalty "+deposit.DepositNumber, &creditTxn.ID); err != nil {
+			tx.Rollback()
+			respondLedgerError(c, err, "Failed to break time deposit")
+			return
+		}
+	}
+
+	now := time.Now()
+	deposit.Status = models.TIME_DEPOSIT_STATUS_BROKEN
+	deposit.PenaltyAmount = penalty
+	deposit.PayoutAmount = payout
+	deposit.ClosedAt = &now
+	if err := tx.Save(&deposit).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update time deposit",
+		})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to commit time deposit break",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Time deposit broken successfully",
+		Data:    deposit,
+	})
+}
+
+// RunMaturity processes every active deposit maturing on or before date according to its
+// maturity instruction. Each deposit is locked and re-checked, so the run can be repeated safely.
+func (h *TimeDepositHandler) RunMaturity(date time.Time) (*TimeDepositMaturityRunResult, error) {
+	asOf := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local)
+
+	var depositIDs []uint
+	if err := h.DB.Model(&models.TimeDeposit{}).
+		Where("status = ? AND maturity_date <= ?", models.TIME_DEPOSIT_STATUS_ACTIVE, asOf).
+		Order("maturity_date ASC, id ASC").
+		Pluck("id", &depositIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to find maturing deposits: %v", err)
+	}
+
+	result := &TimeDepositMaturityRunResult{Date: asOf.Format("2006-01-02")}
+	for _, depositID := range depositIDs {
+		deposit, err := h.matureDeposit(depositID, asOf)
+		if errors.Is(err, errTimeDepositClosed) {
+			continue
+		}
+		if err != nil {
+			log.Printf("Time deposit %d maturity failed: %v", depositID, err)
+			result.Failed++
+			continue
+		}
+		if deposit.Status == models.TIME_DEPOSIT_STATUS_ROLLED_OVER {
+			result.RolledOver++
+		} else {
+			result.PaidOut++
+		}
+	}
+
+	return result, nil
+}
+
+// matureDeposit pays the interest of one matured deposit and rolls it over or pays it out
+func (h *TimeDepositHandler) matureDeposit(depositID uint, asOf time.Time) (*models.TimeDeposit, error) {
+	tx := h.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var deposit models.TimeDeposit
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&deposit, depositID).Error; err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to lock deposit: %v", err)
+	}
+	if deposit.Status != models.TIME_DEPOSIT_STATUS_ACTIVE || deposit.MaturityDate.After(asOf) {
+		tx.Rollback()
+		return nil, errTimeDepositClosed
+	}
+
+	gross := timeDepositInterest(deposit.Principal, deposit.RateBasisPoints, deposit.PlacementDate, deposit.MaturityDate)
+	tax := gross * int64(deposit.TaxRateBasisPoints) / 10000
+	net := gross - tax
+	period := deposit.PlacementDate.Format("2006-01-02") + " - " + deposit.MaturityDate.Format("2006-01-02")
+
+	if gross > 0 {
+		if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_INTEREST_EXPENSE, -gross, deposit.DepositNumber,
+			"Deposito interest "+deposit.DepositNumber, nil); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	if tax > 0 {
+		if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_TAX_PAYABLE, tax, deposit.DepositNumber,
+			"Withholding tax deposito "+deposit.DepositNumber, nil); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// Interest leaves the deposit unless it is rolled over together with the principal
+	if deposit.MaturityInstruction != models.MATURITY_ARO_PRINCIPAL_INTEREST && gross > 0 {
+		if _, err := creditUserBalance(tx, deposit.UserID, gross, "interest",
+			fmt.Sprintf("Deposito %s interest %s", deposit.DepositNumber, period)); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if tax > 0 {
+			if _, err := debitUserBalance(tx, deposit.UserID, tax, "withholding_tax",
+				fmt.Sprintf("Withholding tax on deposito %s interest", deposit.DepositNumber)); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+		}
+		deposit.PayoutAmount = net
+	}
+
+	now := time.Now()
+	deposit.GrossInterest = gross
+	deposit.TaxAmount = tax
+	deposit.ClosedAt = &now
+
+	switch deposit.MaturityInstruction {
+	case models.MATURITY_PAYOUT:
+		principalTxn, err := creditUserBalance(tx, deposit.UserID, deposit.Principal, "time_deposit_payout",
+			fmt.Sprintf("Deposito %s matured", deposit.DepositNumber))
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_TIME_DEPOSIT, -deposit.Principal, deposit.DepositNumber,
+			"Deposito matured "+deposit.DepositNumber, &principalTxn.ID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		deposit.Status = models.TIME_DEPOSIT_STATUS_PAID_OUT
+		deposit.PayoutAmount = deposit.Principal + net
+
+	default:
+		principal := deposit.Principal
+		if deposit.MaturityInstruction == models.MATURITY_ARO_PRINCIPAL_INTEREST {
+			principal += net
+			if net > 0 {
+				if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_TIME_DEPOSIT, net, deposit.DepositNumber,
+					"Deposito interest rolled over "+deposit.DepositNumber, nil); err != nil {
+					tx.Rollback()
+					return nil, err
+				}
+			}
+		}
+
+		renewal, err := h.rollOver(tx, &deposit, principal)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		deposit.Status = models.TIME_DEPOSIT_STATUS_ROLLED_OVER
+		deposit.RolledToID = &renewal.ID
+	}
+
+	if err := tx.Save(&deposit).Error; err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to update deposit: %v", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("failed to commit maturity: %v", err)
+	}
+
+	return &deposit, nil
+}
+
+// rollOver places a renewal of a matured deposit for the same tenor. The product's current terms
+// apply when it is still active, otherwise the previous terms are kept.
+func (h *TimeDepositHandler) rollOver(tx *gorm.DB, deposit *models.TimeDeposit, principal int64) (*models.TimeDeposit, error) {
+	depositNumber, err := generateDepositNumber()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate deposit number: %v", err)
+	}
+
+	renewal := models.TimeDeposit{
+		DepositNumber:       depositNumber,
+		UserID:              deposit.UserID,
+		ProductID:           deposit.ProductID,
+		BankAccountID:       deposit.BankAccountID,
+		Principal:           principal,
+		TenorMonths:         deposit.TenorMonths,
+		RateBasisPoints:     deposit.RateBasisPoints,
+		PenaltyBasisPoints:  deposit.PenaltyBasisPoints,
+		TaxRateBasisPoints:  deposit.TaxRateBasisPoints,
+		MaturityInstruction: deposit.MaturityInstruction,
+		PlacementDate:       deposit.MaturityDate,
+		Status:              models.TIME_DEPOSIT_STATUS_ACTIVE,
+		RolledFromID:        &deposit.ID,
+	}
+
+	var product models.TimeDepositProduct
+	if err := tx.Where("is_active = ?", true).First(&product, deposit.ProductID).Error; err == nil {
+		renewal.TenorMonths = product.TenorMonths
+		renewal.RateBasisPoints = product.RateBasisPoints
+		renewal.PenaltyBasisPoints = product.PenaltyBasisPoints
+		renewal.TaxRateBasisPoints = product.TaxRateBasisPoints
+	}
+	renewal.MaturityDate = addMonthsClamped(renewal.PlacementDate, renewal.TenorMonths)
+
+	if err := tx.Create(&renewal).Error; err != nil {
+		return nil, fmt.Errorf("failed to create renewal: %v", err)
+	}
+	return &renewal, nil
+}
+
+// timeDepositInterest calculates simple interest over the actual days of the term (ACT/365), rounding down
+func timeDepositInterest(principal int64, rateBasisPoints int, placementDate, maturityDate time.Time) int64 {
+	days := int64(math.Round(maturityDate.Sub(placementDate).Hours() / 24))
+
+	interest := new(big.Int).Mul(big.NewInt(principal), big.NewInt(int64(rateBasisPoints)))
+	interest.Mul(interest, big.NewInt(days))
+	interest.Quo(interest, big.NewInt(10000*365))
+	return interest.Int64()
+}
+
+// addMonthsClamped adds months to a date, keeping month-end placements on the last day of the
+// target month (31 January + 1 month = 28/29 February)
+func addMonthsClamped(date time.Time, months int) time.Time {
+	firstOfTarget := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location()).AddDate(0, months, 0)
+	lastDay := firstOfTarget.AddDate(0, 1, -1).Day()
+	day := date.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(firstOfTarget.Year(), firstOfTarget.Month(), day, 0, 0, 0, 0, date.Location())
+}
+
+// today returns the current local date at midnight
+func today() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// generateDepositNumber returns a deposit number such as TD2410123456789012
+func generateDepositNumber() (string, error) {
+	digits, err := generateDigits(12)
+	if err != nil {
+		return "", err
+	}
+	return "TD" + time.Now().Format("0601") + digits, nil
+}
+
+// GetAllProducts - List all deposito products (admin)
+func (h *TimeDepositHandler) GetAllProducts(c *gin.Context) {
+	var products []models.TimeDepositProduct
+	if err := h.DB.Order("tenor_months ASC, code ASC").Find(&products).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch time deposit products",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Time deposit products retrieved successfully",
+		Data:    products,
+	})
+}
+
+// CreateProduct - Create a deposito product (admin)
+func (h *TimeDepositHandler) CreateProduct(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+
+	var req models.TimeDepositProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var count int64
+	h.DB.Model(&models.TimeDepositProduct{}).Where("code = ?", req.Code).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "Time deposit product code already exists",
+		})
+		return
+	}
+
+	product := models.TimeDepositProduct{Code: req.Code, IsActive: true}
+	applyTimeDepositProductRequest(&product, &req)
+	if err := h.DB.Create(&product).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create time deposit product",
+		})
+		return
+	}
+
+	createAuditLog(h.DB, c, "time_deposit_product", product.ID, adminID.(uint), "CREATE", map[string]interface{}{
+		"product": product,
+	})
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Code:    http.StatusCreated,
+		Message: "Time deposit product created successfully",
+		Data:    product,
+	})
+}
+
+// UpdateProduct - Update a deposito product (admin). Changes apply to new placements and rollovers only.
+func (h *TimeDepositHandler) UpdateProduct(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+
+	var product models.TimeDepositProduct
+	if err := h.DB.First(&product, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Time deposit product not found",
+		})
+		return
+	}
+
+	var req models.TimeDepositProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+	if req.Code != product.Code {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Time deposit product code cannot be changed",
+		})
+		return
+	}
+
+	oldProduct := product
+	applyTimeDepositProductRequest(&product, &req)
+	if err := h.DB.Save(&product).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update time deposit product",
+		})
+		return
+	}
+
+	createAuditLog(h.DB, c, "time_deposit_product", product.ID, adminID.(uint), "UPDATE", map[string]interface{}{
+		"old_values": oldProduct,
+		"new_values": product,
+	})
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Time deposit product updated successfully",
+		Data:    product,
+	})
+}
+
+// applyTimeDepositProductRequest copies a product request onto a product
+func applyTimeDepositProductRequest(product *models.TimeDepositProduct, req *models.TimeDepositProductRequest) {
+	product.Name = req.Name
+	product.TenorMonths = req.TenorMonths
+	product.RateBasisPoints = req.RateBasisPoints
+	product.MinAmount = req.MinAmount
+	product.PenaltyBasisPoints = req.PenaltyBasisPoints
+	product.TaxRateBasisPoints = req.TaxRateBasisPoints
+	if req.IsActive != nil {
+		product.IsActive = *req.IsActive
+	}
+}
+
+// GetAllDeposits - List time deposits (admin)
+func (h *TimeDepositHandler) GetAllDeposits(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.TimeDeposit{})
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if productID := c.Query("product_id"); productID != "" {
+		query = query.Where("product_id = ?", productID)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if from := c.Query("maturity_from"); from != "" {
+		query = query.Where("maturity_date >= ?", from)
+	}
+	if to := c.Query("maturity_to"); to != "" {
+		query = query.Where("maturity_date <= ?", to)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var deposits []models.TimeDeposit
+	if err := query.Preload("Product").Order("maturity_date ASC, id ASC").Limit(limit).Offset(offset).Find(&deposits).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch time deposits",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Time deposits retrieved successfully",
+		Data: gin.H{
+			"deposits": deposits,
+			"pagination": gin.H{
+				"page":        page,
+				"limit":       limit,
+				"total":       total,
+				"total_pages": int(math.Ceil(float64(total) / float64(limit))),
+			},
+		},
+	})
+}
+
+// GetDepositReport - Outstanding deposits by status and product, plus upcoming maturities (admin)
+func (h *TimeDepositHandler) GetDepositReport(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if days < 1 || days > 366 {
+		days = 30
+	}
+
+	type statusRow struct {
+		Status         string `json:"status"`
+		Count          int64  `json:"count"`
+		TotalPrincipal int64  `json:"total_principal"`
+		TotalInterest  int64  `json:"total_gross_interest"`
+		TotalTax       int64  `json:"total_tax"`
+		TotalPenalty   int64  `json:"total_penalty"`
+	}
+	var byStatus []statusRow
+	if err := h.DB.Model(&models.TimeDeposit{}).
+		Select("status, COUNT(*) AS count, COALESCE(SUM(principal), 0) AS total_principal, " +
+			"COALESCE(SUM(gross_interest), 0) AS total_interest, COALESCE(SUM(tax_amount), 0) AS total_tax, " +
+			"COALESCE(SUM(penalty_amount), 0) AS total_penalty").
+		Group("status").Order("status").Scan(&byStatus).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to build time deposit report",
+		})
+		return
+	}
+
+	type productRow struct {
+		ProductID      uint   `json:"product_id"`
+		Code           string `json:"code"`
+		TenorMonths    int    `json:"tenor_months"`
+		Count          int64  `json:"count"`
+		TotalPrincipal int64  `json:"total_principal"`
+	}
+	var activeByProduct []productRow
+	if err := h.DB.Table("time_deposits").
+		Select("time_deposits.product_id, time_deposit_products.code, time_deposit_products.tenor_months, "+
+			"COUNT(*) AS count, COALESCE(SUM(time_deposits.principal), 0) AS total_principal").
+		Joins("JOIN time_deposit_products ON time_deposit_products.id = time_deposits.product_id").
+		Where("time_deposits.status = ?", models.TIME_DEPOSIT_STATUS_ACTIVE).
+		Group("time_deposits.product_id, time_deposit_products.code, time_deposit_products.tenor_months").
+		Order("time_deposit_products.tenor_months").
+		Scan(&activeByProduct).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to build time deposit report",
+		})
+		return
+	}
+
+	type maturityRow struct {
+		MaturityDate   string `json:"maturity_date"`
+		Count          int64  `json:"count"`
+		TotalPrincipal int64  `json:"total_principal"`
+		PayoutCount    int64  `json:"payout_count"` // Deposits that will leave the bank at maturity
+	}
+	from := today()
+	var upcoming []maturityRow
+	if err := h.DB.Model(&models.TimeDeposit{}).
+		Select("TO_CHAR(maturity_date, 'YYYY-MM-DD') AS maturity_date, COUNT(*) AS count, "+
+			"COALESCE(SUM(principal), 0) AS total_principal, "+
+			"COUNT(*) FILTER (WHERE maturity_instruction = ?) AS payout_count", models.MATURITY_PAYOUT).
+		Where("status = ? AND maturity_date >= ? AND maturity_date < ?",
+			models.TIME_DEPOSIT_STATUS_ACTIVE, from, from.AddDate(0, 0, days)).
+		Group("maturity_date").Order("maturity_date").
+		Scan(&upcoming).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to build time deposit report",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Time deposit report retrieved successfully",
+		Data: gin.H{
+			"by_status":           byStatus,
+			"active_by_product":   activeByProduct,
+			"upcoming_maturities": upcoming,
+			"maturity_window":     days,
+		},
+	})
+}
+
+// RunMaturityJob - Process matured deposits (admin)
+func (h *TimeDepositHandler) RunMaturityJob(c *gin.Context) {
+	var req models.RunTimeDepositMaturityRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	date := time.Now()
+	if req.Date != "" {
+		date, _ = time.ParseInLocation("2006-01-02", req.Date, time.Local)
+	}
+
+	result, err := h.RunMaturity(date)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Time deposit maturity processing completed",
+		Data:    result,
+	})
+}
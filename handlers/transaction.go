@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"math/big"
 	"net/http"
 	"strconv"
 	"time"
@@ -20,8 +23,8 @@ func NewTransactionHandler(db *gorm.DB) *TransactionHandler {
 	return &TransactionHandler{DB: db}
 }
 
-// Topup - Add balance to user account
-func (h *TransactionHandler) Topup(c *gin.Context) {
+// Withdraw - Deduct balance from user account
+func (h *TransactionHandler) Withdraw(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
@@ -31,7 +34,7 @@ func (h *TransactionHandler) Topup(c *gin.Context) {
 		return
 	}
 
-	var req models.TopupRequest
+	var req models.WithdrawRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Code:    http.StatusBadRequest,
@@ -40,104 +43,24 @@ func (h *TransactionHandler) Topup(c *gin.Context) {
 		return
 	}
 
-	// Start transaction
-	tx := h.DB.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
-
-	// Get user with lock
-	var user models.User
-	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
-		First(&user, userID).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusNotFound, models.ErrorResponse{
-			Code:    http.StatusNotFound,
-			Message: "User not found",
-		})
-		return
-	}
-
-	// Record balance before transaction
-	balanceBefore := user.Balance
-
-	// Update user balance
-	user.Balance += req.Amount
-	if err := tx.Save(&user).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Failed to update balance",
-		})
+	// Check new device limits and evaluate the fraud rules before any money moves
+	if !checkDeviceCoolingOff(c, h.DB, userID.(uint), models.BASE_CURRENCY, req.Amount) {
 		return
 	}
-
-	// Create transaction record
-	transaction := models.Transaction{
-		UserID:        user.ID,
-		Type:          "topup",
-		Amount:        req.Amount,
-		BalanceBefore: balanceBefore,
-		BalanceAfter:  user.Balance,
-		Status:        "completed",
-		Description:   "Balance top-up",
-	}
-
-	if err := tx.Create(&transaction).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Failed to create transaction record",
-		})
-		return
-	}
-
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Failed to commit transaction",
-		})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"code":    http.StatusOK,
-		"message": "Top-up successful",
-		"data": gin.H{
-			"transaction_id": transaction.ID,
-			"amount":         req.Amount,
-			"balance_before": balanceBefore,
-			"balance_after":  user.Balance,
-			"transaction_at": transaction.CreatedAt,
-		},
-	})
-}
-
-// Withdraw - Deduct balance from user account
-func (h *TransactionHandler) Withdraw(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
-			Code:    http.StatusUnauthorized,
-			Message: "User not authenticated",
-		})
-		return
-	}
-
-	var req models.WithdrawRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Code:    http.StatusBadRequest,
-			Message: err.Error(),
-		})
+	fraudDecision := screenTransaction(c, h.DB, fraudCheck{
+		UserID:          userID.(uint),
+		TransactionType: models.FRAUD_CHECK_TYPE_WITHDRAW,
+		Amount:          req.Amount,
+		Currency:        models.BASE_CURRENCY,
+		DeviceID:        fraudDeviceID(c, h.DB),
+		IPAddress:       c.ClientIP(),
+	}, req.FraudDecisionID, req.PIN)
+	if fraudDecision == nil {
 		return
 	}
 
 	// Start transaction
-	tx := h.DB.Begin()
+	tx := h.DB.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
@@ -171,7 +94,7 @@ func (h *TransactionHandler) Withdraw(c *gin.Context) {
 
 	// Update user balance
 	user.Balance -= req.Amount
-	if err := tx.Save(&user).Error; err != nil {
+	if err := tx.Model(&user).Update("balance", user.Balance).Error; err != nil {
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Code:    http.StatusInternalServerError,
@@ -187,7 +110,7 @@ func (h *TransactionHandler) Withdraw(c *gin.Context) {
 		Amount:        req.Amount,
 		BalanceBefore: balanceBefore,
 		BalanceAfter:  user.Balance,
-		Status:        "completed",
+		Status:        models.TRANSACTION_STATUS_COMPLETED,
 		Description:   "Balance withdrawal",
 	}
 
@@ -200,6 +123,12 @@ func (h *TransactionHandler) Withdraw(c *gin.Context) {
 		return
 	}
 
+	if err := completeFraudDecision(tx, fraudDecision, transaction.ID); err != nil {
+		tx.Rollback()
+		respondFraudDecisionError(c, err)
+		return
+	}
+
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -213,11 +142,12 @@ func (h *TransactionHandler) Withdraw(c *gin.Context) {
 		"code":    http.StatusOK,
 		"message": "Withdrawal successful",
 		"data": gin.H{
-			"transaction_id": transaction.ID,
-			"amount":         req.Amount,
-			"balance_before": balanceBefore,
-			"balance_after":  user.Balance,
-			"transaction_at": transaction.CreatedAt,
+			"transaction_id":    transaction.ID,
+			"fraud_decision_id": fraudDecision.ID,
+			"amount":            req.Amount,
+			"balance_before":    balanceBefore,
+			"balance_after":     user.Balance,
+			"transaction_at":    transaction.CreatedAt,
 		},
 	})
 }
@@ -381,19 +311,8 @@ func (h *TransactionHandler) Transfer(c *gin.Context) {
 		return
 	}
 
-	// Start transaction
-	tx := h.DB.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
-
-	// Get sender user with lock
 	var senderUser models.User
-	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
-		First(&senderUser, senderUserID).Error; err != nil {
-		tx.Rollback()
+	if err := h.DB.First(&senderUser, senderUserID).Error; err != nil {
 		c.JSON(http.StatusNotFound, models.ErrorResponse{
 			Code:    http.StatusNotFound,
 			Message: "Sender user not found",
@@ -401,11 +320,27 @@ func (h *TransactionHandler) Transfer(c *gin.Context) {
 		return
 	}
 
+	// Source currency comes from the selected source account (base currency when omitted)
+	fromCurrency := models.BASE_CURRENCY
+	if req.FromAccountNumber != "" {
+		var senderBankAccount models.BankAccount
+		if err := h.DB.Where("account_number = ? AND user_id = ? AND is_active = ?", req.FromAccountNumber, senderUser.ID, true).
+			First(&senderBankAccount).Error; err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "Source account number not found or inactive",
+			})
+			return
+		}
+		if senderBankAccount.Currency != "" {
+			fromCurrency = senderBankAccount.Currency
+		}
+	}
+
 	// Find receiver user by account number through bank_accounts table
 	var receiverBankAccount models.BankAccount
-	if err := tx.Preload("User").Where("account_number = ? AND is_active = ?", req.ToAccountNumber, true).
+	if err := h.DB.Preload("User").Where("account_number = ? AND is_active = ?", req.ToAccountNumber, true).
 		First(&receiverBankAccount).Error; err != nil {
-		tx.Rollback()
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, models.ErrorResponse{
 				Code:    http.StatusNotFound,
@@ -420,9 +355,13 @@ func (h *TransactionHandler) Transfer(c *gin.Context) {
 		return
 	}
 
-	// Check if sender is trying to transfer to themselves
-	if senderUser.ID == receiverBankAccount.User.ID {
-		tx.Rollback()
+	toCurrency := receiverBankAccount.Currency
+	if toCurrency == "" {
+		toCurrency = models.BASE_CURRENCY
+	}
+
+	// Transfers to yourself are only allowed as a conversion between currencies
+	if senderUser.ID == receiverBankAccount.User.ID && fromCurrency == toCurrency {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Code:    http.StatusBadRequest,
 			Message: "Cannot transfer to your own account",
@@ -430,51 +369,53 @@ func (h *TransactionHandler) Transfer(c *gin.Context) {
 		return
 	}
 
-	// Get receiver user with lock
-	var receiverUser models.User
-	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
-		First(&receiverUser, receiverBankAccount.User.ID).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusNotFound, models.ErrorResponse{
-			Code:    http.StatusNotFound,
-			Message: "Recipient user not found",
-		})
-		return
+	// Convert at the rate of the customer's FX quote when the accounts use different currencies
+	creditAmount := req.Amount
+	exchangeRate := ""
+	var fxQuote *models.FXQuote
+	if fromCurrency != toCurrency {
+		if req.FXQuoteID == nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "fx_quote_id is required for a transfer between currencies",
+			})
+			return
+		}
+		quote, err := getFXQuote(h.DB, *req.FXQuoteID, senderUser.ID, fromCurrency, toCurrency, req.Amount, time.Now())
+		if err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, errFXQuoteUsed) {
+				status = http.StatusConflict
+			}
+			c.JSON(status, models.ErrorResponse{
+				Code:    status,
+				Message: err.Error(),
+			})
+			return
+		}
+		fxQuote = quote
+		creditAmount = quote.ConvertedAmount
+		exchangeRate = quote.ExchangeRate
 	}
 
-	// Check if sender has sufficient balance
-	if senderUser.Balance < req.Amount {
-		tx.Rollback()
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Code:    http.StatusBadRequest,
-			Message: "Insufficient balance",
-		})
+	// Sanctions screening of both parties, new device limits, then the fraud rules, before any money moves
+	if !screenTransfer(c, h.DB, &senderUser, &receiverBankAccount.User.ID, receiverBankAccount.User.Name, receiverBankAccount.AccountNumber) {
 		return
 	}
-
-	// Record balances before transaction
-	senderBalanceBefore := senderUser.Balance
-	receiverBalanceBefore := receiverUser.Balance
-
-	// Update sender balance (subtract)
-	senderUser.Balance -= req.Amount
-	if err := tx.Save(&senderUser).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Failed to update sender balance",
-		})
+	if !checkDeviceCoolingOff(c, h.DB, senderUser.ID, fromCurrency, req.Amount) {
 		return
 	}
-
-	// Update receiver balance (add)
-	receiverUser.Balance += req.Amount
-	if err := tx.Save(&receiverUser).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Failed to update receiver balance",
-		})
+	fraudDecision := screenTransaction(c, h.DB, fraudCheck{
+		UserID:             senderUser.ID,
+		TransactionType:    models.FRAUD_CHECK_TYPE_TRANSFER,
+		Amount:             req.Amount,
+		Currency:           fromCurrency,
+		BeneficiaryUserID:  &receiverBankAccount.User.ID,
+		BeneficiaryAccount: receiverBankAccount.AccountNumber,
+		DeviceID:           fraudDeviceID(c, h.DB),
+		IPAddress:          c.ClientIP(),
+	}, req.FraudDecisionID, req.PIN)
+	if fraudDecision == nil {
 		return
 	}
 
@@ -484,18 +425,52 @@ func (h *TransactionHandler) Transfer(c *gin.Context) {
 		transferDesc = "Transfer to " + receiverBankAccount.AccountNumber
 	}
 
-	// Create transaction record for sender (debit)
-	senderTransaction := models.Transaction{
-		UserID:        senderUser.ID,
-		Type:          "transfer_out",
-		Amount:        req.Amount,
-		BalanceBefore: senderBalanceBefore,
-		BalanceAfter:  senderUser.Balance,
-		Status:        "completed",
-		Description:   transferDesc,
+	// Start transaction
+	tx := h.DB.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Debit sender
+	senderTransaction, err := debitUserCurrencyBalance(tx, senderUser.ID, fromCurrency, req.Amount, "transfer_out", transferDesc)
+	if err != nil {
+		tx.Rollback()
+		respondLedgerError(c, err, "Failed to update sender balance")
+		return
 	}
 
-	if err := tx.Create(&senderTransaction).Error; err != nil {
+	// Credit receiver
+	receiverTransaction, err := creditUserCurrencyBalance(tx, receiverBankAccount.User.ID, toCurrency, creditAmount, "transfer_in",
+		"Transfer from "+senderUser.Phone)
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, errUserNotActive) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Recipient account is not active",
+			})
+			return
+		}
+		respondLedgerError(c, err, "Failed to update receiver balance")
+		return
+	}
+
+	// Sweep pocket auto-save rules on transfers from the main balance
+	if fromCurrency == models.BASE_CURRENCY {
+		if err := applyAutoSave(tx, senderUser.ID, senderTransaction); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to apply pocket auto-save",
+			})
+			return
+		}
+	}
+
+	// Link the two legs so a reversal of either one reverses both
+	if err := tx.Model(senderTransaction).Update("counterparty_txn_id", receiverTransaction.ID).Error; err != nil {
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Code:    http.StatusInternalServerError,
@@ -503,19 +478,7 @@ func (h *TransactionHandler) Transfer(c *gin.Context) {
 		})
 		return
 	}
-
-	// Create transaction record for receiver (credit)
-	receiverTransaction := models.Transaction{
-		UserID:        receiverUser.ID,
-		Type:          "transfer_in",
-		Amount:        req.Amount,
-		BalanceBefore: receiverBalanceBefore,
-		BalanceAfter:  receiverUser.Balance,
-		Status:        "completed",
-		Description:   "Transfer from " + senderUser.Phone,
-	}
-
-	if err := tx.Create(&receiverTransaction).Error; err != nil {
+	if err := tx.Model(receiverTransaction).Update("counterparty_txn_id", senderTransaction.ID).Error; err != nil {
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Code:    http.StatusInternalServerError,
@@ -524,6 +487,52 @@ func (h *TransactionHandler) Transfer(c *gin.Context) {
 		return
 	}
 
+	if err := completeFraudDecision(tx, fraudDecision, senderTransaction.ID); err != nil {
+		tx.Rollback()
+		respondFraudDecisionError(c, err)
+		return
+	}
+
+	// Record the rate used on both legs of a conversion
+	if fxQuote != nil {
+		if err := useFXQuote(tx, fxQuote, senderTransaction.ID); err != nil {
+			tx.Rollback()
+			status := http.StatusInternalServerError
+			if errors.Is(err, errFXQuoteUsed) {
+				status = http.StatusConflict
+			}
+			c.JSON(status, models.ErrorResponse{
+				Code:    status,
+				Message: err.Error(),
+			})
+			return
+		}
+		if err := tx.Model(senderTransaction).Updates(map[string]interface{}{
+			"exchange_rate":    exchangeRate,
+			"counter_amount":   creditAmount,
+			"counter_currency": toCurrency,
+		}).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to create sender transaction record",
+			})
+			return
+		}
+		if err := tx.Model(receiverTransaction).Updates(map[string]interface{}{
+			"exchange_rate":    exchangeRate,
+			"counter_amount":   req.Amount,
+			"counter_currency": fromCurrency,
+		}).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to create receiver transaction record",
+			})
+			return
+		}
+	}
+
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -538,18 +547,25 @@ func (h *TransactionHandler) Transfer(c *gin.Context) {
 		"message": "Transfer successful",
 		"data": gin.H{
 			"transaction_id":        senderTransaction.ID,
+			"fraud_decision_id":     fraudDecision.ID,
 			"to_account_number":     req.ToAccountNumber,
 			"to_account_name":       receiverBankAccount.AccountName,
 			"amount":                req.Amount,
-			"sender_balance_before": senderBalanceBefore,
-			"sender_balance_after":  senderUser.Balance,
+			"currency":              fromCurrency,
+			"credited_amount":       creditAmount,
+			"credited_currency":     toCurrency,
+			"exchange_rate":         exchangeRate,
+			"sender_balance_before": senderTransaction.BalanceBefore,
+			"sender_balance_after":  senderTransaction.BalanceAfter,
 			"description":           transferDesc,
 			"transaction_at":        senderTransaction.CreatedAt,
 		},
 	})
 }
 
-// Reversal - Reverse a completed transaction (Admin only)
+// Reversal - Reverse all or part of a completed transaction (Admin only). Reversing one leg of a
+// transfer reverses the other leg in the same database transaction, and reversing a reversal
+// re-applies the original transaction.
 func (h *TransactionHandler) Reversal(c *gin.Context) {
 	var req models.ReversalRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -560,272 +576,387 @@ func (h *TransactionHandler) Reversal(c *gin.Context) {
 		return
 	}
 
+	policy := req.CounterpartyPolicy
+	if policy == "" {
+		policy = getConfigString(h.DB, "reversal_counterparty_policy", models.REVERSAL_POLICY_REJECT)
+	}
+	if policy != models.REVERSAL_POLICY_ABSORB {
+		policy = models.REVERSAL_POLICY_REJECT
+	}
+
 	// Start database transaction
-	tx := h.DB.Begin()
+	tx := h.DB.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
 		}
 	}()
 
-	// Lock and get original transaction
-	var originalTxn models.Transaction
-	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
-		Where("id = ? AND deleted_at IS NULL", req.TransactionID).
-		First(&originalTxn).Error; err != nil {
+	result, err := reverseTransaction(tx, req.TransactionID, req.Amount, req.ReversalReason, policy)
+	if err != nil {
 		tx.Rollback()
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, models.ErrorResponse{
-				Code:    http.StatusNotFound,
-				Message: "Transaction not found",
-			})
-		} else {
-			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-				Code:    http.StatusInternalServerError,
-				Message: "Failed to get transaction",
-			})
-		}
+		respondReversalError(c, err)
 		return
 	}
 
-	// Check if transaction is already reversed
-	if originalTxn.IsReversed {
-		tx.Rollback()
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Code:    http.StatusBadRequest,
-			Message: "Transaction has already been reversed",
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to commit reversal transaction",
 		})
 		return
 	}
 
-	// Check if transaction can be reversed (only completed transactions)
-	if originalTxn.Status != "completed" {
-		tx.Rollback()
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Code:    http.StatusBadRequest,
-			Message: "Only completed transactions can be reversed",
-		})
-		return
+	data := gin.H{
+		"reversal_transaction_id": result.ReversalTxn.ID,
+		"original_transaction_id": result.OriginalTxn.ID,
+		"reversed_amount":         result.ReversalTxn.Amount,
+		"total_reversed_amount":   result.OriginalTxn.ReversedAmount,
+		"remaining_amount":        result.OriginalTxn.RemainingReversible(),
+		"original_status":         result.OriginalTxn.Status,
+		"balance_before":          result.ReversalTxn.BalanceBefore,
+		"balance_after":           result.ReversalTxn.BalanceAfter,
+		"reversal_reason":         req.ReversalReason,
+		"reversed_at":             result.ReversedAt,
+	}
+	if result.CounterpartyTxn != nil {
+		data["counterparty_transaction_id"] = result.CounterpartyTxn.ID
+		data["counterparty_status"] = result.CounterpartyTxn.Status
+		data["counterparty_policy"] = policy
+		data["counterparty_shortfall"] = result.Shortfall
+		if result.CounterpartyReversalTxn != nil {
+			data["counterparty_reversal_transaction_id"] = result.CounterpartyReversalTxn.ID
+		}
 	}
 
-	// Check if transaction is not too old (business rule: can only reverse within 30 days)
-	// You can adjust this timeframe based on business requirements
-	// For now, we'll allow all completed transactions to be reversed
+	message := "Transaction reversed successfully"
+	if result.OriginalTxn.Status == models.TRANSACTION_STATUS_PARTIALLY_REVERSED {
+		message = "Transaction partially reversed successfully"
+	}
 
-	// Lock and get user for balance update
-	var user models.User
+	c.JSON(http.StatusOK, gin.H{
+		"code":    http.StatusOK,
+		"message": message,
+		"data":    data,
+	})
+}
+
+// Reversal errors, mapped to responses by respondReversalError
+var (
+	errReversalTxnNotFound          = errors.New("transaction not found")
+	errReversalAlreadyReversed      = errors.New("transaction has already been reversed")
+	errReversalNotCompleted         = errors.New("only completed transactions can be reversed")
+	errReversalTypeNotSupported     = errors.New("transaction type cannot be reversed")
+	errReversalAmountExceeded       = errors.New("reversal amount exceeds the amount not yet reversed")
+	errReversalInsufficientBalance  = errors.New("insufficient balance for reversal")
+	errReversalReceiverInsufficient = errors.New("receiver has insufficient balance for transfer reversal")
+	errReversalCounterpartyMissing  = errors.New("other leg of the transfer not found")
+)
+
+// reversalResult is the outcome of reverseTransaction
+type reversalResult struct {
+	OriginalTxn             models.Transaction
+	ReversalTxn             models.Transaction
+	CounterpartyTxn         *models.Transaction // Other leg of a transfer, when it was reversed too
+	CounterpartyReversalTxn *models.Transaction // Nil when nothing could be collected from the counterparty
+	Shortfall               int64               // Counterparty amount booked to REVERSAL_RECEIVABLE
+	ReversedAt              time.Time
+}
+
+// reverseTransaction reverses amount of a completed or partially reversed transaction, or what is
+// left of it when amount is 0. The other leg of a transfer is reversed proportionally; when its
+// owner cannot cover the debit, policy decides between failing the whole reversal and booking the
+// shortfall as a receivable. It is shared by the admin reversal endpoint and dispute resolution and
+// must be called inside a database transaction.
+func reverseTransaction(tx *gorm.DB, transactionID uint, amount int64, reason, policy string) (*reversalResult, error) {
+	// Lock and get original transaction
+	var originalTxn models.Transaction
 	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
-		Where("id = ?", originalTxn.UserID).
-		First(&user).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Failed to get user",
-		})
-		return
+		Where("id = ? AND deleted_at IS NULL", transactionID).
+		First(&originalTxn).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errReversalTxnNotFound
+		}
+		return nil, fmt.Errorf("failed to get transaction: %v", err)
 	}
 
-	// Calculate reversal operation
-	var reversalType string
-	var newBalance int64
-	var reversalAmount int64
+	if originalTxn.Status == models.TRANSACTION_STATUS_REVERSED {
+		return nil, errReversalAlreadyReversed
+	}
+	if !originalTxn.IsReversible() {
+		return nil, errReversalNotCompleted
+	}
+	if _, ok := reversalDirection(&originalTxn); !ok {
+		return nil, errReversalTypeNotSupported
+	}
 
-	currentBalance := user.Balance
+	remaining := originalTxn.RemainingReversible()
+	if amount == 0 {
+		amount = remaining
+	}
+	if amount > remaining {
+		return nil, errReversalAmountExceeded
+	}
 
-	switch originalTxn.Type {
-	case "topup":
-		// Reverse topup: deduct the amount
-		reversalType = "reversal"
-		reversalAmount = originalTxn.Amount
-		newBalance = currentBalance - originalTxn.Amount
-		if newBalance < 0 {
-			tx.Rollback()
-			c.JSON(http.StatusBadRequest, models.ErrorResponse{
-				Code:    http.StatusBadRequest,
-				Message: "Insufficient balance for reversal. Cannot reverse topup transaction.",
-			})
-			return
+	counterpartTxn, err := findCounterpartyTxn(tx, &originalTxn)
+	if err != nil {
+		return nil, err
+	}
+
+	// The counterparty share follows the reversed fraction; a reversal of everything left takes
+	// everything left on the other leg so rounding never leaves a residue
+	var counterAmount int64
+	if counterpartTxn != nil {
+		counterAmount = counterpartTxn.RemainingReversible()
+		if amount < remaining {
+			counterAmount = min(scaleAmount(counterpartTxn.Amount, amount, originalTxn.Amount), counterAmount)
 		}
-	case "withdraw":
-		// Reverse withdraw: add the amount back
-		reversalType = "reversal"
-		reversalAmount = originalTxn.Amount
-		newBalance = currentBalance + originalTxn.Amount
-	case "transfer_out":
-		// Reverse outgoing transfer: add the amount back to sender
-		reversalType = "reversal"
-		reversalAmount = originalTxn.Amount
-		newBalance = currentBalance + originalTxn.Amount
-
-		// For transfer reversals, we also need to reverse the corresponding transfer_in
-		// Find the corresponding transfer_in transaction
-		var correspondingTxn models.Transaction
-		if err := tx.Where("user_id != ? AND type = 'transfer_in' AND amount = ? AND created_at BETWEEN ? AND ?",
-			originalTxn.UserID, originalTxn.Amount,
-			originalTxn.CreatedAt.Add(-time.Minute), originalTxn.CreatedAt.Add(time.Minute)).
-			First(&correspondingTxn).Error; err == nil {
-
-			// Lock and get receiver user
-			var receiverUser models.User
-			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
-				Where("id = ?", correspondingTxn.UserID).
-				First(&receiverUser).Error; err != nil {
-				tx.Rollback()
-				c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-					Code:    http.StatusInternalServerError,
-					Message: "Failed to get receiver user for transfer reversal",
-				})
-				return
-			}
+	}
 
-			// Check if receiver has sufficient balance for reversal
-			if receiverUser.Balance < correspondingTxn.Amount {
-				tx.Rollback()
-				c.JSON(http.StatusBadRequest, models.ErrorResponse{
-					Code:    http.StatusBadRequest,
-					Message: "Receiver has insufficient balance for transfer reversal",
-				})
-				return
-			}
+	now := time.Now()
+	reversalTxn, _, err := reverseLeg(tx, &originalTxn, amount, reason,
+		"Reversal of transaction #"+strconv.Itoa(int(originalTxn.ID))+" - "+reason, false, now)
+	if err != nil {
+		if errors.Is(err, errInsufficientBalance) {
+			return nil, errReversalInsufficientBalance
+		}
+		return nil, err
+	}
 
-			// Create reversal transaction for receiver (deduct the amount)
-			receiverReversalBalance := receiverUser.Balance - correspondingTxn.Amount
-			receiverReversalTxn := models.Transaction{
-				UserID:         correspondingTxn.UserID,
-				Type:           "reversal",
-				Amount:         correspondingTxn.Amount,
-				BalanceBefore:  receiverUser.Balance,
-				BalanceAfter:   receiverReversalBalance,
-				Description:    "Transfer reversal - " + req.ReversalReason,
-				Status:         "completed",
-				OriginalTxnID:  &correspondingTxn.ID,
-				ReversalReason: req.ReversalReason,
-			}
+	result := &reversalResult{OriginalTxn: originalTxn, ReversalTxn: *reversalTxn, ReversedAt: now}
+	if counterpartTxn == nil || counterAmount <= 0 {
+		return result, nil
+	}
 
-			if err := tx.Create(&receiverReversalTxn).Error; err != nil {
-				tx.Rollback()
-				c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-					Code:    http.StatusInternalServerError,
-					Message: "Failed to create receiver reversal transaction",
-				})
-				return
-			}
+	counterReversalTxn, collected, err := reverseLeg(tx, counterpartTxn, counterAmount, reason,
+		"Transfer reversal - "+reason, policy == models.REVERSAL_POLICY_ABSORB, now)
+	if err != nil {
+		if errors.Is(err, errInsufficientBalance) {
+			return nil, errReversalReceiverInsufficient
+		}
+		return nil, err
+	}
 
-			// Update receiver balance
-			if err := tx.Model(&receiverUser).Update("balance", receiverReversalBalance).Error; err != nil {
-				tx.Rollback()
-				c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-					Code:    http.StatusInternalServerError,
-					Message: "Failed to update receiver balance",
-				})
-				return
-			}
+	result.CounterpartyTxn = counterpartTxn
+	result.CounterpartyReversalTxn = counterReversalTxn
+	result.Shortfall = counterAmount - collected
 
-			// Mark corresponding transaction as reversed
-			now := time.Now()
-			if err := tx.Model(&correspondingTxn).Updates(map[string]interface{}{
-				"is_reversed":     true,
-				"reversed_txn_id": receiverReversalTxn.ID,
-				"reversed_at":     &now,
-			}).Error; err != nil {
-				tx.Rollback()
-				c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-					Code:    http.StatusInternalServerError,
-					Message: "Failed to mark corresponding transaction as reversed",
-				})
-				return
-			}
+	if counterReversalTxn != nil {
+		// Link the two reversal legs so reversing one of them reverses both
+		if err := tx.Model(reversalTxn).Update("counterparty_txn_id", counterReversalTxn.ID).Error; err != nil {
+			return nil, fmt.Errorf("failed to link reversal transactions: %v", err)
 		}
-	case "transfer_in":
-		// Reverse incoming transfer: deduct the amount
-		reversalType = "reversal"
-		reversalAmount = originalTxn.Amount
-		newBalance = currentBalance - originalTxn.Amount
-		if newBalance < 0 {
-			tx.Rollback()
-			c.JSON(http.StatusBadRequest, models.ErrorResponse{
-				Code:    http.StatusBadRequest,
-				Message: "Insufficient balance for transfer_in reversal",
-			})
-			return
+		if err := tx.Model(counterReversalTxn).Update("counterparty_txn_id", reversalTxn.ID).Error; err != nil {
+			return nil, fmt.Errorf("failed to link reversal transactions: %v", err)
 		}
+		result.ReversalTxn.CounterpartyTxnID = &counterReversalTxn.ID
+	}
+
+	if result.Shortfall > 0 {
+		if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_REVERSAL_RECEIVABLE, result.Shortfall,
+			fmt.Sprintf("REV-%d", originalTxn.ID),
+			fmt.Sprintf("Reversal shortfall of transaction #%d", counterpartTxn.ID), &reversalTxn.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// reversalDirection reports whether txn added to its owner's balance, so that reversing it is a
+// debit. Reversals are classified by their own balance movement; ok is false for transaction
+// types that cannot be reversed.
+func reversalDirection(txn *models.Transaction) (credited bool, ok bool) {
+	switch txn.Type {
+	case models.TRANSACTION_TYPE_TOPUP, models.TRANSACTION_TYPE_TRANSFER_IN:
+		return true, true
+	case models.TRANSACTION_TYPE_WITHDRAW, models.TRANSACTION_TYPE_TRANSFER_OUT:
+		return false, true
+	case models.TRANSACTION_TYPE_REVERSAL:
+		return txn.BalanceAfter > txn.BalanceBefore, true
 	default:
-		tx.Rollback()
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Code:    http.StatusBadRequest,
-			Message: "Transaction type cannot be reversed",
-		})
-		return
+		return false, false
 	}
+}
 
-	// Create reversal transaction
-	reversalTxn := models.Transaction{
-		UserID:         originalTxn.UserID,
-		Type:           reversalType,
-		Amount:         reversalAmount,
-		BalanceBefore:  currentBalance,
-		BalanceAfter:   newBalance,
-		Description:    "Reversal of transaction #" + strconv.Itoa(int(originalTxn.ID)) + " - " + req.ReversalReason,
-		Status:         "completed",
-		OriginalTxnID:  &originalTxn.ID,
-		ReversalReason: req.ReversalReason,
+// findCounterpartyTxn locks the other leg of a transfer or transfer reversal. Transfers made
+// before the legs were linked are matched by amount and time; errReversalCounterpartyMissing is
+// returned when no match is found, so one leg of a transfer is never reversed alone.
+func findCounterpartyTxn(tx *gorm.DB, txn *models.Transaction) (*models.Transaction, error) {
+	var counterpartTxn models.Transaction
+	if txn.CounterpartyTxnID != nil {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&counterpartTxn, *txn.CounterpartyTxnID).Error; err != nil {
+			return nil, fmt.Errorf("failed to get counterparty transaction: %v", err)
+		}
+		return &counterpartTxn, nil
 	}
 
-	if err := tx.Create(&reversalTxn).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Failed to create reversal transaction",
-		})
-		return
+	var counterpartType string
+	switch txn.Type {
+	case models.TRANSACTION_TYPE_TRANSFER_OUT:
+		counterpartType = models.TRANSACTION_TYPE_TRANSFER_IN
+	case models.TRANSACTION_TYPE_TRANSFER_IN:
+		counterpartType = models.TRANSACTION_TYPE_TRANSFER_OUT
+	default:
+		return nil, nil
+	}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id != ? AND type = ? AND amount = ? AND counterparty_txn_id IS NULL AND created_at BETWEEN ? AND ?",
+			txn.UserID, counterpartType, txn.Amount, txn.CreatedAt.Add(-time.Minute), txn.CreatedAt.Add(time.Minute)).
+		Order("id ASC").
+		First(&counterpartTxn).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errReversalCounterpartyMissing
+		}
+		return nil, fmt.Errorf("failed to get counterparty transaction: %v", err)
 	}
+	return &counterpartTxn, nil
+}
 
-	// Update user balance
-	if err := tx.Model(&user).Update("balance", newBalance).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Failed to update user balance",
-		})
-		return
+// reverseLeg moves amount of txn back to or from its owner, records the reversal transaction and
+// updates the reversed amount and status of txn. Reversing a reversal also restores the
+// transaction it reversed. With allowShortfall a debit the owner cannot cover takes whatever is
+// available instead of failing; collected is what actually moved and the reversal transaction is
+// nil when nothing did. It must be called inside a database transaction.
+func reverseLeg(tx *gorm.DB, txn *models.Transaction, amount int64, reason, description string, allowShortfall bool, now time.Time) (*models.Transaction, int64, error) {
+	credited, _ := reversalDirection(txn)
+
+	collected := amount
+	var reversalTxn *models.Transaction
+	var err error
+	if credited {
+		reversalTxn, err = debitUserCurrencyBalance(tx, txn.UserID, txn.Currency, amount, models.TRANSACTION_TYPE_REVERSAL, description)
+		if errors.Is(err, errInsufficientBalance) && allowShortfall {
+			available, balanceErr := userCurrencyBalance(tx, txn.UserID, txn.Currency)
+			if balanceErr != nil {
+				return nil, 0, balanceErr
+			}
+			collected, reversalTxn, err = max(available, 0), nil, nil
+			if collected > 0 {
+				reversalTxn, err = debitUserCurrencyBalance(tx, txn.UserID, txn.Currency, collected, models.TRANSACTION_TYPE_REVERSAL, description)
+			}
+		}
+	} else {
+		reversalTxn, err = creditUserCurrencyBalance(tx, txn.UserID, txn.Currency, amount, models.TRANSACTION_TYPE_REVERSAL, description)
+	}
+	if err != nil {
+		return nil, 0, err
 	}
 
-	// Mark original transaction as reversed
-	now := time.Now()
-	if err := tx.Model(&originalTxn).Updates(map[string]interface{}{
-		"is_reversed":     true,
-		"reversed_txn_id": reversalTxn.ID,
+	updates := map[string]interface{}{
 		"reversed_at":     &now,
-	}).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Failed to mark original transaction as reversed",
-		})
-		return
+		"reversal_reason": reason,
+	}
+	if reversalTxn != nil {
+		if err := tx.Model(reversalTxn).Updates(map[string]interface{}{
+			"original_txn_id": txn.ID,
+			"reversal_reason": reason,
+		}).Error; err != nil {
+			return nil, 0, fmt.Errorf("failed to link reversal transaction: %v", err)
+		}
+		reversalTxn.OriginalTxnID = &txn.ID
+		reversalTxn.ReversalReason = reason
+		updates["reversed_txn_id"] = reversalTxn.ID
 	}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Failed to commit reversal transaction",
-		})
-		return
+	if err := setReversedAmount(tx, txn, txn.ReversedAmount+amount, updates); err != nil {
+		return nil, 0, err
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"code":    http.StatusOK,
-		"message": "Transaction reversed successfully",
-		"data": gin.H{
-			"reversal_transaction_id": reversalTxn.ID,
-			"original_transaction_id": originalTxn.ID,
-			"reversed_amount":         reversalAmount,
-			"balance_before":          currentBalance,
-			"balance_after":           newBalance,
-			"reversal_reason":         req.ReversalReason,
-			"reversed_at":             now,
-		},
+	// A reversal of a reversal gives the amount back to the transaction that was reversed
+	if txn.Type == models.TRANSACTION_TYPE_REVERSAL && txn.OriginalTxnID != nil {
+		var reversedTxn models.Transaction
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&reversedTxn, *txn.OriginalTxnID).Error; err != nil {
+			return nil, 0, fmt.Errorf("failed to get reversed transaction: %v", err)
+		}
+		if err := setReversedAmount(tx, &reversedTxn, max(reversedTxn.ReversedAmount-amount, 0), nil); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return reversalTxn, collected, nil
+}
+
+// setReversedAmount records the total reversed amount of txn and moves it to the matching status
+func setReversedAmount(tx *gorm.DB, txn *models.Transaction, reversedAmount int64, updates map[string]interface{}) error {
+	status := txn.StatusForReversedAmount(reversedAmount)
+	if updates == nil {
+		updates = map[string]interface{}{}
+	}
+	updates["reversed_amount"] = reversedAmount
+	updates["is_reversed"] = status == models.TRANSACTION_STATUS_REVERSED
+
+	if status == txn.Status {
+		if err := tx.Model(txn).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update transaction %d: %v", txn.ID, err)
+		}
+	} else if err := transitionTransaction(tx, txn, status, updates); err != nil {
+		return err
+	}
+
+	txn.ReversedAmount = reversedAmount
+	txn.IsReversed = status == models.TRANSACTION_STATUS_REVERSED
+	return nil
+}
+
+// userCurrencyBalance returns a user's balance in currency
+func userCurrencyBalance(tx *gorm.DB, userID uint, currency string) (int64, error) {
+	if currency == "" || currency == models.BASE_CURRENCY {
+		var user models.User
+		if err := tx.Select("balance").First(&user, userID).Error; err != nil {
+			return 0, fmt.Errorf("failed to get user: %v", err)
+		}
+		return user.Balance, nil
+	}
+
+	var balance models.CurrencyBalance
+	if err := tx.Where("user_id = ? AND currency = ?", userID, currency).First(&balance).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get %s balance: %v", currency, err)
+	}
+	return balance.Balance, nil
+}
+
+// scaleAmount returns amount * numerator / denominator without overflowing int64 on the product
+func scaleAmount(amount, numerator, denominator int64) int64 {
+	scaled := new(big.Int).Mul(big.NewInt(amount), big.NewInt(numerator))
+	return scaled.Quo(scaled, big.NewInt(denominator)).Int64()
+}
+
+// respondReversalError writes the response for an error returned by reverseTransaction
+func respondReversalError(c *gin.Context, err error) {
+	status, message := http.StatusBadRequest, ""
+	switch {
+	case errors.Is(err, errReversalTxnNotFound):
+		status, message = http.StatusNotFound, "Transaction not found"
+	case errors.Is(err, errReversalAlreadyReversed):
+		message = "Transaction has already been reversed"
+	case errors.Is(err, errReversalNotCompleted):
+		message = "Only completed or partially reversed transactions can be reversed"
+	case errors.Is(err, errReversalTypeNotSupported):
+		message = "Transaction type cannot be reversed"
+	case errors.Is(err, errReversalAmountExceeded):
+		message = "Reversal amount exceeds the amount not yet reversed"
+	case errors.Is(err, errReversalInsufficientBalance):
+		message = "Insufficient balance for reversal"
+	case errors.Is(err, errReversalReceiverInsufficient):
+		message = "Receiver has insufficient balance for transfer reversal"
+	case errors.Is(err, errReversalCounterpartyMissing):
+		status, message = http.StatusConflict, "Other leg of the transfer not found, reverse it manually"
+	case errors.Is(err, errUserNotActive):
+		message = "Account involved in the reversal is not active"
+	case errors.Is(err, errInvalidStatusTransition):
+		status, message = http.StatusConflict, "Transaction status does not allow this reversal"
+	default:
+		status, message = http.StatusInternalServerError, "Failed to reverse transaction"
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Code:    status,
+		Message: message,
 	})
 }
 
@@ -857,6 +988,7 @@ func (h *TransactionHandler) GetTransactionByID(c *gin.Context) {
 	if err := h.DB.Preload("User").
 		Preload("OriginalTxn").
 		Preload("ReversedTxn").
+		Preload("Reversals").
 		Where("id = ?", uint(id)).
 		First(&transaction).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
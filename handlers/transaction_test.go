@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+
+	"mbankingcore/models"
+
+	"gorm.io/gorm"
+)
+
+// createTestTransfer books a completed transfer of amount from sender to receiver as two linked
+// legs, moving the balances the way the transfer endpoint does
+func createTestTransfer(t *testing.T, db *gorm.DB, sender, receiver *models.User, amount int64) (out, in *models.Transaction) {
+	t.Helper()
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if out, err = debitUserBalance(tx, sender.ID, amount, models.TRANSACTION_TYPE_TRANSFER_OUT, "Transfer"); err != nil {
+			return err
+		}
+		if in, err = creditUserBalance(tx, receiver.ID, amount, models.TRANSACTION_TYPE_TRANSFER_IN, "Transfer"); err != nil {
+			return err
+		}
+		if err := tx.Model(out).Update("counterparty_txn_id", in.ID).Error; err != nil {
+			return err
+		}
+		return tx.Model(in).Update("counterparty_txn_id", out.ID).Error
+	})
+	if err != nil {
+		t.Fatalf("failed to create transfer: %v", err)
+	}
+	return out, in
+}
+
+func getTestTransaction(t *testing.T, db *gorm.DB, id uint) models.Transaction {
+	t.Helper()
+
+	var txn models.Transaction
+	if err := db.First(&txn, id).Error; err != nil {
+		t.Fatalf("failed to get transaction %d: %v", id, err)
+	}
+	return txn
+}
+
+func TestReverseTransactionPartial(t *testing.T) {
+	db := newTestDB(t)
+	sender := createTestUser(t, db, "sender", 500000)
+	receiver := createTestUser(t, db, "receiver", 0)
+	out, in := createTestTransfer(t, db, sender, receiver, 100000)
+
+	var result *reversalResult
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = reverseTransaction(tx, out.ID, 30000, "wrong amount", models.REVERSAL_POLICY_REJECT)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("partial reversal failed: %v", err)
+	}
+
+	if result.ReversalTxn.Amount != 30000 || result.CounterpartyReversalTxn == nil || result.CounterpartyReversalTxn.Amount != 30000 {
+		t.Fatalf("got reversal legs %+v / %+v, want 30000 on both", result.ReversalTxn, result.CounterpartyReversalTxn)
+	}
+	if got := userBalance(t, db, sender.ID); got != 430000 {
+		t.Errorf("sender balance = %d, want 430000", got)
+	}
+	if got := userBalance(t, db, receiver.ID); got != 70000 {
+		t.Errorf("receiver balance = %d, want 70000", got)
+	}
+	for _, id := range []uint{out.ID, in.ID} {
+		txn := getTestTransaction(t, db, id)
+		if txn.Status != models.TRANSACTION_STATUS_PARTIALLY_REVERSED || txn.ReversedAmount != 30000 || txn.IsReversed {
+			t.Errorf("transaction %d: status %s reversed %d is_reversed %v, want partially_reversed 30000 false",
+				id, txn.Status, txn.ReversedAmount, txn.IsReversed)
+		}
+	}
+	reversal := getTestTransaction(t, db, result.ReversalTxn.ID)
+	if reversal.CounterpartyTxnID == nil || *reversal.CounterpartyTxnID != result.CounterpartyReversalTxn.ID {
+		t.Errorf("reversal legs are not linked")
+	}
+
+	// Asking for more than is left fails without moving anything
+	err = db.Transaction(func(tx *gorm.DB) error {
+		_, err := reverseTransaction(tx, out.ID, 80000, "too much", models.REVERSAL_POLICY_REJECT)
+		return err
+	})
+	if !errors.Is(err, errReversalAmountExceeded) {
+		t.Fatalf("got error %v, want %v", err, errReversalAmountExceeded)
+	}
+
+	// Amount 0 reverses the rest and closes both legs
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = reverseTransaction(tx, out.ID, 0, "rest", models.REVERSAL_POLICY_REJECT)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("reversal of the rest failed: %v", err)
+	}
+	if result.ReversalTxn.Amount != 70000 {
+		t.Errorf("reversed amount = %d, want 70000", result.ReversalTxn.Amount)
+	}
+	if got := userBalance(t, db, sender.ID); got != 500000 {
+		t.Errorf("sender balance = %d, want 500000", got)
+	}
+	if got := userBalance(t, db, receiver.ID); got != 0 {
+		t.Errorf("receiver balance = %d, want 0", got)
+	}
+	for _, id := range []uint{out.ID, in.ID} {
+		txn := getTestTransaction(t, db, id)
+		if txn.Status != models.TRANSACTION_STATUS_REVERSED || txn.ReversedAmount != 100000 || !txn.IsReversed {
+			t.Errorf("transaction %d: status %s reversed %d is_reversed %v, want reversed 100000 true",
+				id, txn.Status, txn.ReversedAmount, txn.IsReversed)
+		}
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		_, err := reverseTransaction(tx, out.ID, 0, "again", models.REVERSAL_POLICY_REJECT)
+		return err
+	})
+	if !errors.Is(err, errReversalAlreadyReversed) {
+		t.Fatalf("got error %v, want %v", err, errReversalAlreadyReversed)
+	}
+}
+
+func TestReverseTransactionPartialRounding(t *testing.T) {
+	db := newTestDB(t)
+	sender := createTestUser(t, db, "sender", 500000)
+	receiver := createTestUser(t, db, "receiver", 0)
+	out, in := createTestTransfer(t, db, sender, receiver, 100)
+
+	// Three reversals of a third each leave no residue on the other leg
+	for i, amount := range []int64{33, 33, 0} {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			_, err := reverseTransaction(tx, in.ID, amount, "split", models.REVERSAL_POLICY_REJECT)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("reversal %d failed: %v", i+1, err)
+		}
+	}
+
+	for _, id := range []uint{out.ID, in.ID} {
+		if txn := getTestTransaction(t, db, id); txn.Status != models.TRANSACTION_STATUS_REVERSED || txn.ReversedAmount != 100 {
+			t.Errorf("transaction %d: status %s reversed %d, want reversed 100", id, txn.Status, txn.ReversedAmount)
+		}
+	}
+	if got := userBalance(t, db, sender.ID); got != 500000 {
+		t.Errorf("sender balance = %d, want 500000", got)
+	}
+}
+
+func TestReverseTransactionReceiverShortfall(t *testing.T) {
+	tests := []struct {
+		name           string
+		policy         string
+		wantErr        error
+		wantSender     int64
+		wantReceiver   int64
+		wantShortfall  int64
+		wantReceivable int64
+		wantStatus     string
+	}{
+		{
+			name: "reject", policy: models.REVERSAL_POLICY_REJECT, wantErr: errReversalReceiverInsufficient,
+			wantSender: 400000, wantReceiver: 40000, wantStatus: models.TRANSACTION_STATUS_COMPLETED,
+		},
+		{
+			name: "absorb", policy: models.REVERSAL_POLICY_ABSORB,
+			wantSender: 500000, wantReceiver: 0, wantShortfall: 60000, wantReceivable: 60000,
+			wantStatus: models.TRANSACTION_STATUS_REVERSED,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			sender := createTestUser(t, db, "sender", 500000)
+			receiver := createTestUser(t, db, "receiver", 0)
+			out, in := createTestTransfer(t, db, sender, receiver, 100000)
+
+			// The receiver spent most of the transfer before it was reversed
+			if err := db.Model(receiver).Update("balance", 40000).Error; err != nil {
+				t.Fatalf("failed to spend receiver balance: %v", err)
+			}
+
+			var result *reversalResult
+			err := db.Transaction(func(tx *gorm.DB) error {
+				var err error
+				result, err = reverseTransaction(tx, out.ID, 0, "fraud", tt.policy)
+				return err
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if err == nil {
+				if result.Shortfall != tt.wantShortfall {
+					t.Errorf("shortfall = %d, want %d", result.Shortfall, tt.wantShortfall)
+				}
+				if result.CounterpartyReversalTxn == nil || result.CounterpartyReversalTxn.Amount != 40000 {
+					t.Errorf("counterparty reversal = %+v, want 40000 collected", result.CounterpartyReversalTxn)
+				}
+			}
+
+			if got := userBalance(t, db, sender.ID); got != tt.wantSender {
+				t.Errorf("sender balance = %d, want %d", got, tt.wantSender)
+			}
+			if got := userBalance(t, db, receiver.ID); got != tt.wantReceiver {
+				t.Errorf("receiver balance = %d, want %d", got, tt.wantReceiver)
+			}
+			if got := systemAccountBalance(t, db, models.SYSTEM_ACCOUNT_REVERSAL_RECEIVABLE); got != tt.wantReceivable {
+				t.Errorf("reversal receivable = %d, want %d", got, tt.wantReceivable)
+			}
+			for _, id := range []uint{out.ID, in.ID} {
+				if txn := getTestTransaction(t, db, id); txn.Status != tt.wantStatus {
+					t.Errorf("transaction %d status = %s, want %s", id, txn.Status, tt.wantStatus)
+				}
+			}
+		})
+	}
+}
+
+func TestReverseTransactionAbsorbWithEmptyReceiver(t *testing.T) {
+	db := newTestDB(t)
+	sender := createTestUser(t, db, "sender", 500000)
+	receiver := createTestUser(t, db, "receiver", 0)
+	out, in := createTestTransfer(t, db, sender, receiver, 100000)
+	if err := db.Model(receiver).Update("balance", 0).Error; err != nil {
+		t.Fatalf("failed to spend receiver balance: %v", err)
+	}
+
+	var result *reversalResult
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = reverseTransaction(tx, out.ID, 0, "fraud", models.REVERSAL_POLICY_ABSORB)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("reversal failed: %v", err)
+	}
+
+	// Nothing could be collected, so there is no counterparty reversal and the whole leg is a receivable
+	if result.CounterpartyReversalTxn != nil {
+		t.Errorf("got counterparty reversal %+v, want none", result.CounterpartyReversalTxn)
+	}
+	if result.Shortfall != 100000 {
+		t.Errorf("shortfall = %d, want 100000", result.Shortfall)
+	}
+	if got := systemAccountBalance(t, db, models.SYSTEM_ACCOUNT_REVERSAL_RECEIVABLE); got != 100000 {
+		t.Errorf("reversal receivable = %d, want 100000", got)
+	}
+	if txn := getTestTransaction(t, db, in.ID); txn.Status != models.TRANSACTION_STATUS_REVERSED || txn.ReversedTxnID != nil {
+		t.Errorf("receiver leg: status %s reversed_txn_id %v, want reversed without a reversal transaction", txn.Status, txn.ReversedTxnID)
+	}
+}
@@ -148,7 +148,7 @@ func DeleteUser(c *gin.Context) {
 	}
 
 	// Soft delete the user
-	result = config.DB.Delete(&user, uint(id))
+	result = config.DB.WithContext(c).Delete(&user, uint(id))
 	if result.Error != nil {
 		c.JSON(500, gin.H{
 			"code":    models.CODE_USER_DELETE_FAILED,
@@ -193,7 +193,7 @@ func RestoreUser(c *gin.Context) {
 	}
 
 	// Restore the user by setting deleted_at to NULL
-	result = config.DB.Unscoped().Model(&user).Update("deleted_at", nil)
+	result = config.DB.WithContext(c).Unscoped().Model(&user).Update("deleted_at", nil)
 	if result.Error != nil {
 		c.JSON(500, gin.H{
 			"code":    models.CODE_USER_DELETE_FAILED,
@@ -302,7 +302,7 @@ func PermanentDeleteUser(c *gin.Context) {
 	}
 
 	// Permanently delete the user
-	result = config.DB.Unscoped().Delete(&user, uint(id))
+	result = config.DB.WithContext(c).Unscoped().Delete(&user, uint(id))
 	if result.Error != nil {
 		c.JSON(500, gin.H{
 			"code":    models.CODE_USER_DELETE_FAILED,
@@ -391,7 +391,7 @@ func UpdateUserStatus(c *gin.Context) {
 
 	// Update status
 	user.Status = req.Status
-	result = config.DB.Save(&user)
+	result = config.DB.WithContext(c).Save(&user)
 	if result.Error != nil {
 		c.JSON(500, gin.H{
 			"code":    models.CODE_USER_UPDATE_FAILED,
@@ -784,7 +784,7 @@ func ReviewPendingUserStatusChange(c *gin.Context) {
 		}
 
 		user.Status = pending.RequestedStatus
-		userResult = config.DB.Save(&user)
+		userResult = config.DB.WithContext(c).Save(&user)
 		if userResult.Error != nil {
 			c.JSON(500, gin.H{
 				"code":    models.CODE_USER_UPDATE_FAILED,
@@ -0,0 +1,629 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mbankingcore/models"
+	"mbankingcore/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Default lifetime of a dynamic virtual account
+const defaultDynamicVAExpiry = 24 * time.Hour
+
+type VirtualAccountHandler struct {
+	DB *gorm.DB
+}
+
+func NewVirtualAccountHandler(db *gorm.DB) *VirtualAccountHandler {
+	return &VirtualAccountHandler{DB: db}
+}
+
+// GetStaticVA - Get (or create on first use) the user's permanent virtual account
+func (h *VirtualAccountHandler) GetStaticVA(c *gin.Context) {
+	userIDValue, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+	userID := userIDValue.(uint)
+
+	var user models.User
+	if err := h.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+		})
+		return
+	}
+
+	va := models.VirtualAccount{
+		VANumber: utils.StaticVANumber(userID),
+		UserID:   userID,
+		Type:     models.VIRTUAL_ACCOUNT_TYPE_STATIC,
+		Name:     user.Name,
+		Status:   models.VIRTUAL_ACCOUNT_STATUS_ACTIVE,
+	}
+	if err := h.DB.Where("va_number = ?", va.VANumber).FirstOrCreate(&va).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get virtual account",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Virtual account retrieved successfully",
+		Data:    va,
+	})
+}
+
+// CreateDynamicVA - Issue a single-use virtual account for an exact amount
+func (h *VirtualAccountHandler) CreateDynamicVA(c *gin.Context) {
+	userIDValue, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+	userID := userIDValue.(uint)
+
+	var req models.CreateDynamicVARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+		})
+		return
+	}
+
+	expiry := defaultDynamicVAExpiry
+	if req.ExpiresInMinutes > 0 {
+		expiry = time.Duration(req.ExpiresInMinutes) * time.Minute
+	}
+	expiresAt := time.Now().Add(expiry)
+
+	var va models.VirtualAccount
+	for attempt := 0; attempt < 5; attempt++ {
+		digits, err := generateDigits(11)
+		if err != nil {
+			break
+		}
+		number := utils.DynamicVANumber(digits)
+
+		var existing int64
+		h.DB.Model(&models.VirtualAccount{}).Where("va_number = ?", number).Count(&existing)
+		if existing == 0 {
+			va = models.VirtualAccount{
+				VANumber:    number,
+				UserID:      userID,
+				Type:        models.VIRTUAL_ACCOUNT_TYPE_DYNAMIC,
+				Name:        user.Name,
+				Amount:      req.Amount,
+				Description: req.Description,
+				Status:      models.VIRTUAL_ACCOUNT_STATUS_ACTIVE,
+				ExpiresAt:   &expiresAt,
+			}
+			break
+		}
+	}
+	if va.VANumber == "" {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to generate virtual account number",
+		})
+		return
+	}
+
+	if err := h.DB.Create(&va).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create virtual account",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Code:    http.StatusCreated,
+		Message: "Virtual account created successfully",
+		Data:    va,
+	})
+}
+
+// GetUserVAs - List the user's virtual accounts
+func (h *VirtualAccountHandler) GetUserVAs(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	h.expireDynamicVAs()
+
+	query := h.DB.Model(&models.VirtualAccount{}).Where("user_id = ?", userID)
+	if vaType := c.Query("type"); vaType != "" {
+		query = query.Where("type = ?", vaType)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var vas []models.VirtualAccount
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&vas).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch virtual accounts",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Virtual accounts retrieved successfully",
+		Data: gin.H{
+			"virtual_accounts": vas,
+			"pagination": gin.H{
+				"current_page": page,
+				"per_page":     limit,
+				"total":        total,
+				"total_pages":  (total + int64(limit) - 1) / int64(limit),
+			},
+		},
+	})
+}
+
+// CloseDynamicVA - Cancel an unpaid dynamic virtual account
+func (h *VirtualAccountHandler) CloseDynamicVA(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.UnauthorizedResponse())
+		return
+	}
+
+	var va models.VirtualAccount
+	if err := h.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&va).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Virtual account not found",
+		})
+		return
+	}
+
+	if va.Type != models.VIRTUAL_ACCOUNT_TYPE_DYNAMIC || va.Status != models.VIRTUAL_ACCOUNT_STATUS_ACTIVE {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Only active dynamic virtual accounts can be closed",
+		})
+		return
+	}
+
+	if err := h.DB.Model(&va).Update("status", models.VIRTUAL_ACCOUNT_STATUS_CLOSED).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to close virtual account",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Virtual account closed successfully",
+		Data:    va,
+	})
+}
+
+// HandleNotification - Receive an inbound credit to a virtual account (signature verified)
+func (h *VirtualAccountHandler) HandleNotification(c *gin.Context) {
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Failed to read notification body",
+		})
+		return
+	}
+
+	if !utils.VerifyVANotification(payload, c.GetHeader("X-Signature")) {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Invalid notification signature",
+		})
+		return
+	}
+
+	var notification models.VANotificationRequest
+	if err := binding.JSON.BindBody(payload, &notification); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	payment, err := h.applyNotification(&notification)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to process notification",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Notification processed",
+		Data: gin.H{
+			"external_reference": payment.ExternalReference,
+			"status":             payment.Status,
+		},
+	})
+}
+
+// applyNotification matches an inbound credit to a virtual account and credits the owner,
+// or parks it in the suspense account. Repeated notifications return the first result.
+func (h *VirtualAccountHandler) applyNotification(notification *models.VANotificationRequest) (*models.VirtualAccountPayment, error) {
+	var existing models.VirtualAccountPayment
+	if err := h.DB.Where("external_reference = ?", notification.ExternalReference).First(&existing).Error; err == nil {
+		return &existing, nil
+	}
+
+	tx := h.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	payment := models.VirtualAccountPayment{
+		ExternalReference: notification.ExternalReference,
+		VANumber:          notification.VANumber,
+		Amount:            notification.Amount,
+		PayerName:         notification.PayerName,
+		PayerBank:         notification.PayerBank,
+	}
+	if !notification.PaidAt.IsZero() {
+		payment.PaidAt = &notification.PaidAt
+	}
+
+	var va models.VirtualAccount
+	reason := ""
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("va_number = ?", notification.VANumber).First(&va).Error; err != nil {
+		reason = "unknown virtual account number"
+	} else {
+		payment.VirtualAccountID = &va.ID
+		reason = matchVAPayment(&va, notification.Amount)
+	}
+
+	if reason == "" {
+		creditTxn, err := creditUserBalance(tx, va.UserID, notification.Amount, "topup",
+			fmt.Sprintf("Virtual account %s from %s %s", va.VANumber, notification.PayerBank, notification.PayerName))
+		switch {
+		case errors.Is(err, errUserNotActive):
+			reason = "user account is not active"
+		case err != nil:
+			tx.Rollback()
+			return nil, err
+		default:
+			payment.Status = models.VA_PAYMENT_STATUS_CREDITED
+			payment.UserID = &va.UserID
+			payment.TransactionID = &creditTxn.ID
+
+			if va.Type == models.VIRTUAL_ACCOUNT_TYPE_DYNAMIC {
+				now := time.Now()
+				if err := tx.Model(&va).Updates(map[string]interface{}{
+					"status":  models.VIRTUAL_ACCOUNT_STATUS_PAID,
+					"paid_at": &now,
+				}).Error; err != nil {
+					tx.Rollback()
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if reason != "" {
+		payment.Status = models.VA_PAYMENT_STATUS_SUSPENSE
+		payment.SuspenseReason = reason
+		if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_VA_SUSPENSE, notification.Amount, notification.ExternalReference,
+			"Unmatched virtual account payment: "+reason, nil); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Create(&payment).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+// matchVAPayment returns why a payment cannot be credited to a virtual account, or "" when it matches
+func matchVAPayment(va *models.VirtualAccount, amount int64) string {
+	if va.Status != models.VIRTUAL_ACCOUNT_STATUS_ACTIVE {
+		return "virtual account is " + va.Status
+	}
+	if va.Type == models.VIRTUAL_ACCOUNT_TYPE_DYNAMIC {
+		if va.ExpiresAt != nil && time.Now().After(*va.ExpiresAt) {
+			return "virtual account has expired"
+		}
+		if amount != va.Amount {
+			return fmt.Sprintf("amount %d does not match invoice amount %d", amount, va.Amount)
+		}
+	}
+	return ""
+}
+
+// expireDynamicVAs marks unpaid dynamic virtual accounts past their expiry as expired
+func (h *VirtualAccountHandler) expireDynamicVAs() {
+	h.DB.Model(&models.VirtualAccount{}).
+		Where("type = ? AND status = ? AND expires_at < ?", models.VIRTUAL_ACCOUNT_TYPE_DYNAMIC, models.VIRTUAL_ACCOUNT_STATUS_ACTIVE, time.Now()).
+		Update("status", models.VIRTUAL_ACCOUNT_STATUS_EXPIRED)
+}
+
+// GetAllVAs - List virtual accounts (admin)
+func (h *VirtualAccountHandler) GetAllVAs(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+	offset := (page - 1) * limit
+
+	h.expireDynamicVAs()
+
+	query := h.DB.Model(&models.VirtualAccount{})
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if vaNumber := c.Query("va_number"); vaNumber != "" {
+		query = query.Where("va_number = ?", vaNumber)
+	}
+	if vaType := c.Query("type"); vaType != "" {
+		query = query.Where("type = ?", vaType)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var vas []models.VirtualAccount
+	if err := query.Preload("User").Order("created_at DESC").Limit(limit).Offset(offset).Find(&vas).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch virtual accounts",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Virtual accounts retrieved successfully",
+		Data: gin.H{
+			"virtual_accounts": vas,
+			"pagination": gin.H{
+				"current_page": page,
+				"per_page":     limit,
+				"total":        total,
+				"total_pages":  (total + int64(limit) - 1) / int64(limit),
+			},
+		},
+	})
+}
+
+// GetVAPayments - List inbound virtual account payments, e.g. ?status=suspense for the suspense queue (admin)
+func (h *VirtualAccountHandler) GetVAPayments(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.VirtualAccountPayment{})
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if vaNumber := c.Query("va_number"); vaNumber != "" {
+		query = query.Where("va_number = ?", vaNumber)
+	}
+	if reference := c.Query("external_reference"); reference != "" {
+		query = query.Where("external_reference = ?", reference)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var payments []models.VirtualAccountPayment
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&payments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch virtual account payments",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Virtual account payments retrieved successfully",
+		Data: gin.H{
+			"payments": payments,
+			"pagination": gin.H{
+				"current_page": page,
+				"per_page":     limit,
+				"total":        total,
+				"total_pages":  (total + int64(limit) - 1) / int64(limit),
+			},
+		},
+	})
+}
+
+// ResolveVAPayment - Assign a suspense payment to a user or mark it returned to the sender (admin)
+func (h *VirtualAccountHandler) ResolveVAPayment(c *gin.Context) {
+	adminIDValue, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+	adminID := adminIDValue.(uint)
+
+	var req models.ResolveVAPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if req.Action == "assign" {
+		var user models.User
+		if err := h.DB.First(&user, req.UserID).Error; err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "User not found",
+			})
+			return
+		}
+	}
+
+	tx := h.DB.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var payment models.VirtualAccountPayment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&payment, c.Param("id")).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Virtual account payment not found",
+		})
+		return
+	}
+
+	if payment.Status != models.VA_PAYMENT_STATUS_SUSPENSE {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Only suspense payments can be resolved",
+		})
+		return
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"resolved_by":     adminID,
+		"resolution_note": req.Note,
+		"resolved_at":     &now,
+	}
+
+	var transactionID *uint
+	if req.Action == "assign" {
+		creditTxn, err := creditUserBalance(tx, req.UserID, payment.Amount, "topup",
+			fmt.Sprintf("Virtual account payment %s assigned by admin", payment.ExternalReference))
+		if err != nil {
+			tx.Rollback()
+			respondLedgerError(c, err, "Failed to credit user")
+			return
+		}
+		transactionID = &creditTxn.ID
+		updates["status"] = models.VA_PAYMENT_STATUS_ASSIGNED
+		updates["user_id"] = req.UserID
+		updates["transaction_id"] = creditTxn.ID
+	} else {
+		updates["status"] = models.VA_PAYMENT_STATUS_RETURNED
+	}
+
+	if err := postSystemAccountEntry(tx, models.SYSTEM_ACCOUNT_VA_SUSPENSE, -payment.Amount, payment.ExternalReference,
+		"Virtual account suspense "+req.Action+": "+req.Note, transactionID); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to post to suspense account",
+		})
+		return
+	}
+
+	if err := tx.Model(&payment).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to resolve virtual account payment",
+		})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to commit resolution",
+		})
+		return
+	}
+
+	h.DB.First(&payment, payment.ID)
+	details := map[string]interface{}{
+		"action": req.Action,
+		"note":   req.Note,
+	}
+	if req.UserID != 0 {
+		details["user_id"] = req.UserID
+	}
+	createAuditLog(h.DB, c, "virtual_account_payment", payment.ID, adminID, "RESOLVE", details)
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Virtual account payment resolved",
+		Data:    payment,
+	})
+}
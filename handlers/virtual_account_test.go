@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"mbankingcore/models"
+)
+
+func TestApplyNotification(t *testing.T) {
+	expired := time.Now().Add(-time.Minute)
+	valid := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name         string
+		va           *models.VirtualAccount // nil when the number is not issued
+		userStatus   int
+		amount       int64
+		wantStatus   string
+		wantReason   string
+		wantBalance  int64
+		wantSuspense int64
+		wantVAStatus string
+	}{
+		{
+			name:   "static accepts any amount",
+			va:     &models.VirtualAccount{Type: models.VIRTUAL_ACCOUNT_TYPE_STATIC, Status: models.VIRTUAL_ACCOUNT_STATUS_ACTIVE},
+			amount: 12345, wantStatus: models.VA_PAYMENT_STATUS_CREDITED, wantBalance: 12345,
+			wantVAStatus: models.VIRTUAL_ACCOUNT_STATUS_ACTIVE,
+		},
+		{
+			name:   "dynamic with the invoice amount is paid",
+			va:     &models.VirtualAccount{Type: models.VIRTUAL_ACCOUNT_TYPE_DYNAMIC, Status: models.VIRTUAL_ACCOUNT_STATUS_ACTIVE, Amount: 50000, ExpiresAt: &valid},
+			amount: 50000, wantStatus: models.VA_PAYMENT_STATUS_CREDITED, wantBalance: 50000,
+			wantVAStatus: models.VIRTUAL_ACCOUNT_STATUS_PAID,
+		},
+		{
+			name:   "dynamic with another amount goes to suspense",
+			va:     &models.VirtualAccount{Type: models.VIRTUAL_ACCOUNT_TYPE_DYNAMIC, Status: models.VIRTUAL_ACCOUNT_STATUS_ACTIVE, Amount: 50000, ExpiresAt: &valid},
+			amount: 49999, wantStatus: models.VA_PAYMENT_STATUS_SUSPENSE, wantSuspense: 49999,
+			wantReason:   "amount 49999 does not match invoice amount 50000",
+			wantVAStatus: models.VIRTUAL_ACCOUNT_STATUS_ACTIVE,
+		},
+		{
+			name:   "dynamic past its expiry goes to suspense",
+			va:     &models.VirtualAccount{Type: models.VIRTUAL_ACCOUNT_TYPE_DYNAMIC, Status: models.VIRTUAL_ACCOUNT_STATUS_ACTIVE, Amount: 50000, ExpiresAt: &expired},
+			amount: 50000, wantStatus: models.VA_PAYMENT_STATUS_SUSPENSE, wantSuspense: 50000,
+			wantReason:   "virtual account has expired",
+			wantVAStatus: models.VIRTUAL_ACCOUNT_STATUS_ACTIVE,
+		},
+		{
+			name:   "closed goes to suspense",
+			va:     &models.VirtualAccount{Type: models.VIRTUAL_ACCOUNT_TYPE_STATIC, Status: models.VIRTUAL_ACCOUNT_STATUS_CLOSED},
+			amount: 10000, wantStatus: models.VA_PAYMENT_STATUS_SUSPENSE, wantSuspense: 10000,
+			wantReason:   "virtual account is closed",
+			wantVAStatus: models.VIRTUAL_ACCOUNT_STATUS_CLOSED,
+		},
+		{
+			name:   "inactive owner goes to suspense",
+			va:     &models.VirtualAccount{Type: models.VIRTUAL_ACCOUNT_TYPE_STATIC, Status: models.VIRTUAL_ACCOUNT_STATUS_ACTIVE},
+			amount: 10000, userStatus: models.USER_STATUS_BLOCKED, wantStatus: models.VA_PAYMENT_STATUS_SUSPENSE, wantSuspense: 10000,
+			wantReason:   "user account is not active",
+			wantVAStatus: models.VIRTUAL_ACCOUNT_STATUS_ACTIVE,
+		},
+		{
+			name:   "unknown number goes to suspense",
+			amount: 10000, wantStatus: models.VA_PAYMENT_STATUS_SUSPENSE, wantSuspense: 10000,
+			wantReason: "unknown virtual account number",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.VirtualAccount{}, &models.VirtualAccountPayment{})
+			h := NewVirtualAccountHandler(db)
+			user := createTestUser(t, db, "owner", 0)
+			if tt.userStatus != 0 {
+				db.Model(user).Update("status", tt.userStatus)
+			}
+			if tt.va != nil {
+				tt.va.VANumber = "8808000000000001"
+				tt.va.UserID = user.ID
+				if err := db.Create(tt.va).Error; err != nil {
+					t.Fatalf("failed to create virtual account: %v", err)
+				}
+			}
+
+			notification := models.VANotificationRequest{
+				ExternalReference: "EXT-1",
+				VANumber:          "8808000000000001",
+				Amount:            tt.amount,
+				PayerName:         "Payer",
+				PayerBank:         "BCA",
+			}
+
+			// A repeated notification returns the first result without crediting again
+			for range 2 {
+				payment, err := h.applyNotification(&notification)
+				if err != nil {
+					t.Fatalf("applyNotification failed: %v", err)
+				}
+				if payment.Status != tt.wantStatus || payment.SuspenseReason != tt.wantReason {
+					t.Fatalf("payment: status %s reason %q, want %s %q", payment.Status, payment.SuspenseReason, tt.wantStatus, tt.wantReason)
+				}
+			}
+
+			if got := userBalance(t, db, user.ID); got != tt.wantBalance {
+				t.Errorf("user balance = %d, want %d", got, tt.wantBalance)
+			}
+			if got := systemAccountBalance(t, db, models.SYSTEM_ACCOUNT_VA_SUSPENSE); got != tt.wantSuspense {
+				t.Errorf("VA suspense = %d, want %d", got, tt.wantSuspense)
+			}
+			if tt.va != nil {
+				var va models.VirtualAccount
+				db.First(&va, tt.va.ID)
+				if va.Status != tt.wantVAStatus {
+					t.Errorf("virtual account status = %s, want %s", va.Status, tt.wantVAStatus)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyNotificationDynamicPaidOnce(t *testing.T) {
+	db := newTestDB(t, &models.VirtualAccount{}, &models.VirtualAccountPayment{})
+	h := NewVirtualAccountHandler(db)
+	user := createTestUser(t, db, "owner", 0)
+	expiresAt := time.Now().Add(time.Hour)
+	va := models.VirtualAccount{
+		VANumber:  "8808000000000002",
+		UserID:    user.ID,
+		Type:      models.VIRTUAL_ACCOUNT_TYPE_DYNAMIC,
+		Status:    models.VIRTUAL_ACCOUNT_STATUS_ACTIVE,
+		Amount:    50000,
+		ExpiresAt: &expiresAt,
+	}
+	if err := db.Create(&va).Error; err != nil {
+		t.Fatalf("failed to create virtual account: %v", err)
+	}
+
+	// A second transfer to a paid invoice, under another reference, is not credited
+	for i, want := range []string{models.VA_PAYMENT_STATUS_CREDITED, models.VA_PAYMENT_STATUS_SUSPENSE} {
+		payment, err := h.applyNotification(&models.VANotificationRequest{
+			ExternalReference: fmt.Sprintf("EXT-%d", i+1),
+			VANumber:          va.VANumber,
+			Amount:            50000,
+		})
+		if err != nil {
+			t.Fatalf("applyNotification %d failed: %v", i+1, err)
+		}
+		if payment.Status != want {
+			t.Errorf("payment %d status = %s, want %s", i+1, payment.Status, want)
+		}
+	}
+
+	if got := userBalance(t, db, user.ID); got != 50000 {
+		t.Errorf("user balance = %d, want 50000", got)
+	}
+	if got := systemAccountBalance(t, db, models.SYSTEM_ACCOUNT_VA_SUSPENSE); got != 50000 {
+		t.Errorf("VA suspense = %d, want 50000", got)
+	}
+}
@@ -0,0 +1,696 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"mbankingcore/models"
+	"mbankingcore/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultWatchlistMatchThreshold = 90       // Name similarity in percent that raises a match
+	maxWatchlistFileSize           = 20 << 20 // 20 MB, the UN consolidated list is about 2 MB
+)
+
+type WatchlistHandler struct {
+	DB *gorm.DB
+}
+
+func NewWatchlistHandler(db *gorm.DB) *WatchlistHandler {
+	return &WatchlistHandler{DB: db}
+}
+
+// watchlistHit is an active watchlist entry resembling a screened name
+type watchlistHit struct {
+	Entry       models.WatchlistEntry
+	MatchedName string
+	Score       float64
+}
+
+// findWatchlistHits compares a name with every active watchlist entry and its aliases and returns
+// the entries scoring at or above the configured threshold, best match first
+func findWatchlistHits(db *gorm.DB, name string) (string, []watchlistHit, error) {
+	normalized := utils.NormalizeName(name)
+	if normalized == "" {
+		return normalized, nil, nil
+	}
+	threshold := float64(getConfigInt64(db, "watchlist_match_threshold", defaultWatchlistMatchThreshold)) / 100
+
+	var entries []models.WatchlistEntry
+	if err := db.Where("is_active = ?", true).Find(&entries).Error; err != nil {
+		return normalized, nil, err
+	}
+
+	hits := []watchlistHit{}
+	for _, entry := range entries {
+		best := watchlistHit{Entry: entry}
+		candidates := append([]string{entry.NormalizedName}, entry.NormalizedAliases...)
+		listedNames := append([]string{entry.Name}, entry.Aliases...)
+		for i, candidate := range candidates {
+			if score := utils.NameMatchScore(normalized, candidate); score > best.Score {
+				best.Score = score
+				best.MatchedName = listedNames[min(i, len(listedNames)-1)]
+			}
+		}
+		if best.Score >= threshold {
+			hits = append(hits, best)
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	return normalized, hits, nil
+}
+
+// screeningOutcome holds the open and confirmed matches of one screening
+type screeningOutcome struct {
+	Matches []models.ScreeningMatch
+}
+
+// Confirmed reports whether any match was confirmed as a true hit
+func (o *screeningOutcome) Confirmed() bool {
+	for _, match := range o.Matches {
+		if match.Status == models.SCREENING_MATCH_STATUS_CONFIRMED {
+			return true
+		}
+	}
+	return false
+}
+
+// Clear reports whether the name has no open or confirmed matches
+func (o *screeningOutcome) Clear() bool {
+	return len(o.Matches) == 0
+}
+
+// screenName screens a name on behalf of a customer and queues a pending match for every new hit.
+// Hits already reviewed for the same customer and name keep their outcome, so dismissed false
+// positives are not raised again.
+func screenName(db *gorm.DB, userID uint, subject, context, name, beneficiaryAccount string) (*screeningOutcome, error) {
+	normalized, hits, err := findWatchlistHits(db, name)
+	if err != nil {
+		return nil, err
+	}
+
+	outcome := &screeningOutcome{Matches: []models.ScreeningMatch{}}
+	for _, hit := range hits {
+		match := models.ScreeningMatch{
+			UserID:             userID,
+			EntryID:            hit.Entry.ID,
+			NormalizedName:     normalized,
+			Subject:            subject,
+			Context:            context,
+			ScreenedName:       name,
+			BeneficiaryAccount: beneficiaryAccount,
+			MatchedName:        hit.MatchedName,
+			Score:              math.Round(hit.Score*1000) / 1000,
+			Status:             models.SCREENING_MATCH_STATUS_PENDING,
+		}
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&match).Error; err != nil {
+			return nil, err
+		}
+		if match.ID == 0 {
+			// Screened before; reuse the stored match and its review outcome
+			if err := db.Where("user_id = ? AND entry_id = ? AND normalized_name = ?", userID, hit.Entry.ID, normalized).
+				First(&match).Error; err != nil {
+				return nil, err
+			}
+		} else {
+			log.Printf("Watchlist match %d: user %d %s %q resembles %q (%s, score %.3f)",
+				match.ID, userID, subject, name, hit.MatchedName, hit.Entry.Source, hit.Score)
+		}
+
+		if match.Status != models.SCREENING_MATCH_STATUS_FALSE_POSITIVE {
+			outcome.Matches = append(outcome.Matches, match)
+		}
+	}
+	return outcome, nil
+}
+
+// screenCustomer screens a customer's own name
+func screenCustomer(db *gorm.DB, user *models.User, context string) (*screeningOutcome, error) {
+	return screenName(db, user.ID, models.SCREENING_SUBJECT_CUSTOMER, context, user.Name, "")
+}
+
+// screenTransfer screens the sender and the beneficiary of a transfer before any money moves and
+// writes the refusal response when either has an open or confirmed match. An internal beneficiary
+// is screened as the customer they are; an external one is recorded against the sender. Returns
+// false when the transfer must not proceed.
+func screenTransfer(c *gin.Context, db *gorm.DB, sender *models.User, beneficiaryUserID *uint, beneficiaryName, beneficiaryAccount string) bool {
+	outcome, err := screenName(db, sender.ID, models.SCREENING_SUBJECT_CUSTOMER, models.SCREENING_CONTEXT_TRANSFER, sender.Name, "")
+	if err == nil && outcome.Clear() && beneficiaryName != "" {
+		if beneficiaryUserID != nil {
+			outcome, err = screenName(db, *beneficiaryUserID, models.SCREENING_SUBJECT_CUSTOMER, models.SCREENING_CONTEXT_TRANSFER, beneficiaryName, "")
+		} else {
+			outcome, err = screenName(db, sender.ID, models.SCREENING_SUBJECT_BENEFICIARY, models.SCREENING_CONTEXT_TRANSFER, beneficiaryName, beneficiaryAccount)
+		}
+	}
+
+	if err != nil {
+		log.Printf("Watchlist screening for user %d failed: %v", sender.ID, err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to screen transaction",
+		})
+		return false
+	}
+	if !outcome.Clear() {
+		// Do not tell the customer why; disclosing a watchlist hit would tip off the listed party
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Code:    http.StatusForbidden,
+			Message: "Transaction cannot be processed at this time. Please contact customer support",
+		})
+		return false
+	}
+	return true
+}
+
+// Admin endpoints
+
+// ImportWatchlist - Import a sanctions or PEP list from CSV or UN consolidated list XML (Admin only)
+// Form fields: file, source (e.g. UN, DTTOT, OFAC), list_type (sanctions or pep, default sanctions)
+// and replace=true to deactivate entries of the source that are missing from the file.
+func (h *WatchlistHandler) ImportWatchlist(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+
+	source := strings.ToUpper(strings.TrimSpace(c.PostForm("source")))
+	if source == "" || len(source) > 50 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Source is required (form field 'source', max 50 characters)",
+		})
+		return
+	}
+	listType := c.DefaultPostForm("list_type", models.WATCHLIST_TYPE_SANCTIONS)
+	if listType != models.WATCHLIST_TYPE_SANCTIONS && listType != models.WATCHLIST_TYPE_PEP {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "list_type must be sanctions or pep",
+		})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "CSV or XML file is required (form field 'file')",
+		})
+		return
+	}
+	if fileHeader.Size > maxWatchlistFileSize {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("File is too large (max %d bytes)", maxWatchlistFileSize),
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Failed to read uploaded file",
+		})
+		return
+	}
+	defer file.Close()
+
+	var records []utils.WatchlistRecord
+	if strings.EqualFold(filepath.Ext(fileHeader.Filename), ".xml") {
+		records, err = utils.ParseWatchlistXML(file)
+	} else {
+		records, err = utils.ParseWatchlistCSV(file)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	adminIDValue := adminID.(uint)
+	created, updated, skipped := 0, 0, 0
+	var deactivated int64
+	importedIDs := []string{}
+
+	tx := h.DB.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	for _, record := range records {
+		entryListType := listType
+		if record.ListType != "" {
+			entryListType = record.ListType
+		}
+		normalized := utils.NormalizeName(record.Name)
+		if normalized == "" || (entryListType != models.WATCHLIST_TYPE_SANCTIONS && entryListType != models.WATCHLIST_TYPE_PEP) {
+			skipped++
+			continue
+		}
+
+		// Lists without identifiers are keyed by the normalized name
+		externalID := record.ExternalID
+		if externalID == "" {
+			externalID = normalized
+		}
+		normalizedAliases := []string{}
+		for _, alias := range record.Aliases {
+			normalizedAliases = append(normalizedAliases, utils.NormalizeName(alias))
+		}
+
+		var entry models.WatchlistEntry
+		err := tx.Where("source = ? AND external_id = ?", source, externalID).First(&entry).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to import watchlist",
+			})
+			return
+		}
+		isNew := err == gorm.ErrRecordNotFound
+
+		entry.Source = source
+		entry.ExternalID = externalID
+		entry.ListType = entryListType
+		entry.Name = record.Name
+		entry.NormalizedName = normalized
+		entry.Aliases = record.Aliases
+		entry.NormalizedAliases = normalizedAliases
+		entry.DateOfBirth = record.DateOfBirth
+		entry.Nationality = record.Nationality
+		entry.Remarks = record.Remarks
+		entry.IsActive = true
+		entry.ImportedByAdminID = &adminIDValue
+		if err := tx.Save(&entry).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: fmt.Sprintf("Failed to import watchlist entry %s", externalID),
+			})
+			return
+		}
+
+		if isNew {
+			created++
+		} else {
+			updated++
+		}
+		importedIDs = append(importedIDs, externalID)
+	}
+
+	if c.PostForm("replace") == "true" && len(importedIDs) > 0 {
+		result := tx.Model(&models.WatchlistEntry{}).
+			Where("source = ? AND is_active = ? AND external_id NOT IN ?", source, true, importedIDs).
+			Update("is_active", false)
+		if result.Error != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to deactivate removed watchlist entries",
+			})
+			return
+		}
+		deactivated = result.RowsAffected
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to import watchlist",
+		})
+		return
+	}
+
+	createAuditLog(h.DB, c, "watchlist", 0, adminIDValue, "IMPORT", map[string]interface{}{
+		"source":      source,
+		"list_type":   listType,
+		"file_name":   fileHeader.Filename,
+		"created":     created,
+		"updated":     updated,
+		"skipped":     skipped,
+		"deactivated": deactivated,
+	})
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Watchlist imported successfully",
+		Data: gin.H{
+			"source":      source,
+			"created":     created,
+			"updated":     updated,
+			"skipped":     skipped,
+			"deactivated": deactivated,
+		},
+	})
+}
+
+// GetEntries - List watchlist entries (Admin only)
+func (h *WatchlistHandler) GetEntries(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.WatchlistEntry{})
+	if source := c.Query("source"); source != "" {
+		query = query.Where("source = ?", strings.ToUpper(source))
+	}
+	if listType := c.Query("list_type"); listType != "" {
+		query = query.Where("list_type = ?", listType)
+	}
+	if isActive := c.Query("is_active"); isActive != "" {
+		query = query.Where("is_active = ?", isActive == "true")
+	}
+	if search := c.Query("search"); search != "" {
+		query = query.Where("normalized_name LIKE ?", "%"+utils.NormalizeName(search)+"%")
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var entries []models.WatchlistEntry
+	if err := query.Order("source, name").Limit(limit).Offset(offset).Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch watchlist entries",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Watchlist entries retrieved successfully",
+		Data: gin.H{
+			"entries": entries,
+			"pagination": gin.H{
+				"page":        page,
+				"limit":       limit,
+				"total":       total,
+				"total_pages": int(math.Ceil(float64(total) / float64(limit))),
+			},
+		},
+	})
+}
+
+// ScreenName - Check a name against the watchlist without recording anything (Admin only)
+func (h *WatchlistHandler) ScreenName(c *gin.Context) {
+	var req models.ScreenNameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	normalized, hits, err := findWatchlistHits(h.DB, req.Name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to screen name",
+		})
+		return
+	}
+
+	results := make([]gin.H, 0, len(hits))
+	for _, hit := range hits {
+		results = append(results, gin.H{
+			"entry":        hit.Entry,
+			"matched_name": hit.MatchedName,
+			"score":        math.Round(hit.Score*1000) / 1000,
+		})
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Name screened successfully",
+		Data: gin.H{
+			"name":            req.Name,
+			"normalized_name": normalized,
+			"hits":            results,
+		},
+	})
+}
+
+// ScreenUser - Rescreen a customer, e.g. after a list import, queueing any new matches (Admin only)
+func (h *WatchlistHandler) ScreenUser(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, c.Param("user_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+		})
+		return
+	}
+
+	outcome, err := screenCustomer(h.DB, &user, models.SCREENING_CONTEXT_ADMIN)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to screen user",
+		})
+		return
+	}
+
+	createAuditLog(h.DB, c, "user", user.ID, adminID.(uint), "WATCHLIST_SCREEN", map[string]interface{}{
+		"matches": len(outcome.Matches),
+	})
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "User screened successfully",
+		Data: gin.H{
+			"user_id": user.ID,
+			"clear":   outcome.Clear(),
+			"matches": outcome.Matches,
+		},
+	})
+}
+
+// GetMatches - Screening review queue (Admin only)
+func (h *WatchlistHandler) GetMatches(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.ScreeningMatch{})
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if subject := c.Query("subject"); subject != "" {
+		query = query.Where("subject = ?", subject)
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var matches []models.ScreeningMatch
+	if err := query.Preload("Entry").Order("score DESC, created_at").Limit(limit).Offset(offset).Find(&matches).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to fetch screening matches",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Screening matches retrieved successfully",
+		Data: gin.H{
+			"matches": matches,
+			"pagination": gin.H{
+				"page":        page,
+				"limit":       limit,
+				"total":       total,
+				"total_pages": int(math.Ceil(float64(total) / float64(limit))),
+			},
+		},
+	})
+}
+
+// GetMatchByID - Get a screening match with the customer and the listed entry (Admin only)
+func (h *WatchlistHandler) GetMatchByID(c *gin.Context) {
+	var match models.ScreeningMatch
+	if err := h.DB.Preload("User").Preload("Entry").First(&match, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Screening match not found",
+		})
+		return
+	}
+	match.User.PinAtm = ""
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Screening match retrieved successfully",
+		Data:    match,
+	})
+}
+
+// ReviewMatch - Confirm a match, freezing the customer's account, or dismiss it as a false positive (Admin only)
+func (h *WatchlistHandler) ReviewMatch(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin authentication required",
+		})
+		return
+	}
+
+	var req models.ReviewScreeningMatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	tx := h.DB.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var match models.ScreeningMatch
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&match, c.Param("id")).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Screening match not found",
+		})
+		return
+	}
+	if match.Status != models.SCREENING_MATCH_STATUS_PENDING {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Screening match is already %s", match.Status),
+		})
+		return
+	}
+
+	now := time.Now()
+	adminIDValue := adminID.(uint)
+	match.ReviewedByAdminID = &adminIDValue
+	match.ReviewNotes = req.Notes
+	match.ReviewedAt = &now
+	match.Status = models.SCREENING_MATCH_STATUS_FALSE_POSITIVE
+	if req.Action == "confirm" {
+		match.Status = models.SCREENING_MATCH_STATUS_CONFIRMED
+	}
+	if err := tx.Save(&match).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update screening match",
+		})
+		return
+	}
+
+	// A confirmed hit freezes the account and ends every session of the customer
+	var previousStatus int
+	if match.Status == models.SCREENING_MATCH_STATUS_CONFIRMED {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, match.UserID).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to load customer",
+			})
+			return
+		}
+		previousStatus = user.Status
+		if err := tx.Model(&user).Update("status", models.USER_STATUS_FROZEN).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to freeze customer account",
+			})
+			return
+		}
+		if err := utils.NewSessionManager(tx).LogoutAllSessions(user.ID); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to end customer sessions",
+			})
+			return
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to review screening match",
+		})
+		return
+	}
+
+	details := map[string]interface{}{
+		"user_id":      match.UserID,
+		"entry_id":     match.EntryID,
+		"matched_name": match.MatchedName,
+		"status":       match.Status,
+		"notes":        req.Notes,
+	}
+	if match.Status == models.SCREENING_MATCH_STATUS_CONFIRMED {
+		details["previous_user_status"] = previousStatus
+		details["new_user_status"] = models.USER_STATUS_FROZEN
+	}
+	createAuditLog(h.DB, c, "screening_match", match.ID, adminIDValue, strings.ToUpper(req.Action), details)
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Code:    http.StatusOK,
+		Message: "Screening match reviewed successfully",
+		Data:    match,
+	})
+}
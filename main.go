@@ -13,6 +13,7 @@ import (
 	"mbankingcore/config"
 	"mbankingcore/handlers"
 	"mbankingcore/middleware"
+	"mbankingcore/utils"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -127,6 +128,10 @@ func main() {
 	// Record server start time
 	serverStartTime = time.Now()
 
+	// Redact PINs, tokens and other sensitive values from application and request logs
+	log.SetOutput(utils.NewRedactingWriter(os.Stderr))
+	gin.DefaultWriter = utils.NewRedactingWriter(os.Stdout)
+
 	// Load environment variables
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found or error loading .env file")
@@ -138,6 +143,9 @@ func main() {
 	// Initialize Gin router
 	router := gin.Default()
 
+	// Let the request context back the gin context, so db.WithContext(c) sees the audit request metadata
+	router.ContextWithFallback = true
+
 	// Add comprehensive CORS middleware for all APIs
 	router.Use(func(c *gin.Context) {
 		origin := c.GetHeader("Origin")
@@ -179,18 +187,65 @@ func main() {
 	auditHandler := handlers.NewAuditHandler()
 	checkerMakerHandler := handlers.NewCheckerMakerHandler(config.DB)
 	approvalThresholdHandler := handlers.NewApprovalThresholdHandler(config.DB)
+	disbursementHandler := handlers.NewDisbursementHandler(config.DB)
+	interbankHandler := handlers.NewInterbankHandler(config.DB, utils.NewSwitchingAdapter())
+	iso20022Handler := handlers.NewISO20022Handler(config.DB, disbursementHandler)
+	merchantHandler := handlers.NewMerchantHandler(config.DB)
+	qrisHandler := handlers.NewQRISHandler(config.DB)
+	merchantSettlementHandler := handlers.NewMerchantSettlementHandler(config.DB)
+	merchantAPIHandler := handlers.NewMerchantAPIHandler(config.DB)
+	billPaymentHandler := handlers.NewBillPaymentHandler(config.DB, utils.NewBillerAdapter())
+	virtualAccountHandler := handlers.NewVirtualAccountHandler(config.DB)
+	fxHandler := handlers.NewFXHandler(config.DB)
+	interestHandler := handlers.NewInterestHandler(config.DB)
+	timeDepositHandler := handlers.NewTimeDepositHandler(config.DB)
+	pocketHandler := handlers.NewPocketHandler(config.DB)
+	loanHandler := handlers.NewLoanHandler(config.DB)
+	cardHandler := handlers.NewCardHandler(config.DB)
+	disputeHandler := handlers.NewDisputeHandler(config.DB)
+	fraudHandler := handlers.NewFraudHandler(config.DB)
+	amlHandler := handlers.NewAMLHandler(config.DB)
+	watchlistHandler := handlers.NewWatchlistHandler(config.DB)
+	deviceHandler := handlers.NewDeviceHandler(config.DB)
+
+	// Resume bulk disbursements interrupted by a restart
+	disbursementHandler.ResumeProcessingBatches()
+
+	// Resolve bill payments left pending or suspect by a restart
+	go billPaymentHandler.ResolvePendingPayments()
+
+	// Link new audit records into the tamper-evident audit chains
+	go auditHandler.RunChainSealer()
+
+	// Export sealed audit and login records to the SIEM sinks configured by AUDIT_SINKS
+	auditSinks, err := utils.NewAuditSinks(APP_VERSION)
+	if err != nil {
+		log.Fatal("Invalid audit export configuration:", err)
+	}
+	if len(auditSinks) > 0 {
+		go auditHandler.RunAuditExporter(auditSinks)
+	}
+
+	// Create the monthly audit partitions ahead of time
+	go auditHandler.RunAuditPartitionMaintenance()
+
+	// Drop device request nonces that can no longer be replayed
+	go deviceHandler.RunNonceCleanup()
 
 	// API routes
 	api := router.Group("/api")
 
 	// Add audit logging middleware
+	api.Use(middleware.AuditRequestMiddleware())
 	api.Use(middleware.AuditLogMiddleware())
+	api.Use(middleware.DatabaseMiddleware())
 
 	{
 		// Authentication routes (public)
-		api.POST("/login", middleware.AuditLoginMiddleware(), authHandler.BankingLogin)              // Banking Login Step 1 - Send OTP
-		api.POST("/login/verify", middleware.AuditLoginMiddleware(), authHandler.BankingLoginVerify) // Banking Login Step 2 - Verify OTP
-		api.POST("/refresh", authHandler.RefreshToken)                                               // Refresh token
+		api.POST("/login", middleware.AuditLoginMiddleware(), authHandler.BankingLogin)                 // Banking Login Step 1 - Send OTP
+		api.POST("/login/verify", middleware.AuditLoginMiddleware(), authHandler.BankingLoginVerify)    // Banking Login Step 2 - Verify OTP
+		api.POST("/login/device/verify", middleware.AuditLoginMiddleware(), deviceHandler.VerifyDevice) // Banking Login Step 3 - Verify a new device
+		api.POST("/refresh", authHandler.RefreshToken)                                                  // Refresh token
 
 		// Public onboarding routes (remain public)
 		api.GET("/onboardings", handlers.GetOnboardings)    // Get all onboardings (public)
@@ -208,6 +263,27 @@ func main() {
 		// Privacy policy management (authenticated users)
 		api.POST("/privacy-policy", middleware.AuthMiddleware(), handlers.SetPrivacyPolicy) // Set privacy policy content (authenticated users)
 
+		// Switching network callback (public, signature verified)
+		api.POST("/interbank/callback", interbankHandler.HandleCallback) // Receive asynchronous interbank transfer result
+
+		// Virtual account credit notification (public, signature verified)
+		api.POST("/virtual-accounts/notify", virtualAccountHandler.HandleNotification) // Receive inbound credit to a virtual account
+
+		// Card network authorization and clearing (public, signature verified)
+		api.POST("/card-network/authorize", cardHandler.HandleNetworkMessage) // ISO 8583-like 0100 authorization / 0400 reversal
+		api.POST("/card-network/clearing", cardHandler.HandleClearing)        // Capture holds from a clearing batch
+
+		// Merchant server-to-server API (API key authenticated)
+		merchantAPI := api.Group("/merchant-api")
+		merchantAPI.Use(middleware.MerchantAPIKeyMiddleware(config.DB))
+		{
+			merchantAPI.POST("/payment-requests", merchantAPIHandler.CreatePaymentRequest)          // Generate dynamic QR for a payment
+			merchantAPI.GET("/payments", merchantAPIHandler.GetPayments)                            // List received payments
+			merchantAPI.GET("/payments/:reference", merchantAPIHandler.GetPaymentByReference)       // Get payment status
+			merchantAPI.GET("/settlements", merchantAPIHandler.GetSettlements)                      // List settlements
+			merchantAPI.GET("/settlements/:id/report", merchantAPIHandler.DownloadSettlementReport) // Download settlement report CSV
+		}
+
 		// Admin authentication routes (public)
 		admin := api.Group("/admin")
 		{
@@ -245,8 +321,15 @@ func main() {
 				adminProtected.POST("/transactions/reversal", transactionHandler.Reversal)    // Reverse a transaction
 
 				// Audit trails (admin only)
-				adminProtected.GET("/audit-logs", auditHandler.GetAuditLogs)        // Get audit logs with filtering
-				adminProtected.GET("/login-audits", auditHandler.GetLoginAuditLogs) // Get login audit logs with filtering
+				adminProtected.GET("/audit-logs", auditHandler.GetAuditLogs)                                 // Get audit logs with filtering
+				adminProtected.GET("/login-audits", auditHandler.GetLoginAuditLogs)                          // Get login audit logs with filtering
+				adminProtected.GET("/audit-chain/verify", auditHandler.VerifyAuditChain)                     // Verify audit hash chains
+				adminProtected.GET("/audit-chain/checkpoints", auditHandler.GetAuditCheckpoints)             // List signed chain checkpoints
+				adminProtected.POST("/audit-chain/checkpoints", auditHandler.CreateAuditCheckpoint)          // Sign the current chain heads
+				adminProtected.GET("/audit-export/status", auditHandler.GetAuditExportStatus)                // SIEM export position of each sink
+				adminProtected.GET("/audit-retention", auditHandler.GetAuditRetentionPolicies)               // Audit retention per entity type
+				adminProtected.PUT("/audit-retention/:entity_type", auditHandler.UpdateAuditRetentionPolicy) // Set retention of an entity type
+				adminProtected.GET("/audit-archives", auditHandler.GetAuditArchives)                         // Archived audit partitions
 
 				// Config management (admin only)
 				adminProtected.POST("/config", handlers.SetConfig)           // Set config value (admin only)
@@ -272,6 +355,132 @@ func main() {
 				adminProtected.POST("/approval-thresholds", approvalThresholdHandler.CreateOrUpdateApprovalThreshold)     // Create/update approval threshold
 				adminProtected.DELETE("/approval-thresholds/:type", approvalThresholdHandler.DeactivateApprovalThreshold) // Deactivate approval threshold
 
+				// Bulk disbursement (admin only, checker-maker)
+				adminProtected.POST("/disbursements", disbursementHandler.UploadDisbursement)                   // Upload disbursement CSV (maker)
+				adminProtected.GET("/disbursements", disbursementHandler.GetDisbursements)                      // List disbursement batches
+				adminProtected.GET("/disbursements/:id", disbursementHandler.GetDisbursementByID)               // Get batch detail with per-row results
+				adminProtected.GET("/disbursements/:id/report", disbursementHandler.DownloadDisbursementReport) // Download per-row report as CSV
+				adminProtected.POST("/disbursements/:id/review", disbursementHandler.ReviewDisbursement)        // Approve or reject batch (checker)
+				adminProtected.POST("/disbursements/funding", disbursementHandler.FundDisbursements)            // Record funds received for bulk disbursements
+
+				// Interbank transfer monitoring (admin only)
+				adminProtected.GET("/interbank/transfers", interbankHandler.GetAllTransfers)                       // Get all interbank transfers
+				adminProtected.POST("/interbank/transfers/:id/check-status", interbankHandler.CheckTransferStatus) // Resolve processing/suspect transfer via switch status check
+				adminProtected.POST("/interbank/transfers/:id/resolve", interbankHandler.ResolveTransfer)          // Settle or refund a suspect transfer on an operator decision
+
+				// ISO 20022 message exchange (admin only)
+				adminProtected.POST("/iso20022/pain001", iso20022Handler.ImportPain001)                           // Import pain.001 as bulk disbursement (maker)
+				adminProtected.GET("/iso20022/pacs008", iso20022Handler.ExportPacs008)                            // Export outgoing transfers as pacs.008
+				adminProtected.GET("/iso20022/messages", iso20022Handler.GetMessages)                             // List imported/generated messages
+				adminProtected.GET("/iso20022/messages/:id", iso20022Handler.DownloadMessage)                     // Download message XML
+				adminProtected.POST("/iso20022/messages/:id/status-report", iso20022Handler.GenerateStatusReport) // Generate pacs.002 status report
+
+				// QRIS merchant registry (admin only)
+				adminProtected.POST("/merchants", merchantHandler.CreateMerchant)                      // Register merchant
+				adminProtected.GET("/merchants", merchantHandler.GetMerchants)                         // List merchants
+				adminProtected.GET("/merchants/:id", merchantHandler.GetMerchantByID)                  // Get merchant detail
+				adminProtected.PUT("/merchants/:id", merchantHandler.UpdateMerchant)                   // Update merchant details or status
+				adminProtected.GET("/merchants/:id/qr", merchantHandler.GenerateMerchantQR)            // Generate static or dynamic merchant QR
+				adminProtected.POST("/merchants/:id/api-keys", merchantHandler.CreateAPIKey)           // Issue merchant API key
+				adminProtected.GET("/merchants/:id/api-keys", merchantHandler.GetAPIKeys)              // List merchant API keys
+				adminProtected.DELETE("/merchants/:id/api-keys/:key_id", merchantHandler.RevokeAPIKey) // Revoke merchant API key
+
+				// Bill payment catalog and monitoring (admin only)
+				adminProtected.POST("/billers", billPaymentHandler.CreateBiller)                // Add biller to catalog
+				adminProtected.GET("/billers", billPaymentHandler.GetAllBillers)                // List all billers
+				adminProtected.PUT("/billers/:id", billPaymentHandler.UpdateBiller)             // Update biller or enable/disable it
+				adminProtected.GET("/bill-payments", billPaymentHandler.GetAllBillPayments)     // Get all bill payments
+				adminProtected.POST("/bill-payments/:id/advice", billPaymentHandler.SendAdvice) // Resolve pending/suspect payment via biller advice
+
+				// Virtual accounts and suspense queue (admin only)
+				adminProtected.GET("/virtual-accounts", virtualAccountHandler.GetAllVAs)                             // List virtual accounts
+				adminProtected.GET("/virtual-account-payments", virtualAccountHandler.GetVAPayments)                 // List inbound payments (?status=suspense)
+				adminProtected.POST("/virtual-account-payments/:id/resolve", virtualAccountHandler.ResolveVAPayment) // Assign or return suspense payment
+
+				// Currencies and FX rates (admin only, checker-maker)
+				adminProtected.POST("/currencies", fxHandler.CreateCurrency)      // Add supported currency
+				adminProtected.GET("/fx-rates", fxHandler.GetRates)               // List FX rates (?status=pending)
+				adminProtected.POST("/fx-rates", fxHandler.ProposeRate)           // Propose new FX rate (maker)
+				adminProtected.POST("/fx-rates/:id/review", fxHandler.ReviewRate) // Approve or reject FX rate (checker)
+
+				// Savings interest (admin only)
+				adminProtected.GET("/interest-products", interestHandler.GetProducts)                    // List interest products
+				adminProtected.POST("/interest-products", interestHandler.CreateProduct)                 // Create tiered interest product
+				adminProtected.PUT("/interest-products/:id", interestHandler.UpdateProduct)              // Update product and replace tiers
+				adminProtected.PUT("/bank-accounts/:id/interest-product", interestHandler.AssignProduct) // Assign product to saving account
+				adminProtected.POST("/interest/accruals/run", interestHandler.RunAccrual)                // Accrue a date (default yesterday)
+				adminProtected.GET("/interest/accruals", interestHandler.GetAccruals)                    // List daily accruals
+				adminProtected.POST("/interest/capitalizations/run", interestHandler.RunCapitalization)  // Capitalize a month (default last month)
+				adminProtected.GET("/interest/capitalizations", interestHandler.GetCapitalizations)      // List monthly capitalizations
+
+				// Time deposits (admin only)
+				adminProtected.GET("/time-deposit-products", timeDepositHandler.GetAllProducts)       // List deposito products
+				adminProtected.POST("/time-deposit-products", timeDepositHandler.CreateProduct)       // Create deposito product
+				adminProtected.PUT("/time-deposit-products/:id", timeDepositHandler.UpdateProduct)    // Update deposito product
+				adminProtected.GET("/time-deposits", timeDepositHandler.GetAllDeposits)               // List deposits (?status=&maturity_from=)
+				adminProtected.GET("/time-deposits/report", timeDepositHandler.GetDepositReport)      // Outstanding and upcoming maturities report
+				adminProtected.POST("/time-deposits/maturity/run", timeDepositHandler.RunMaturityJob) // Process matured deposits (default today)
+
+				// Loans (admin only)
+				adminProtected.GET("/loan-products", loanHandler.GetAllProducts)           // List loan products
+				adminProtected.POST("/loan-products", loanHandler.CreateProduct)           // Create loan product
+				adminProtected.PUT("/loan-products/:id", loanHandler.UpdateProduct)        // Update loan product
+				adminProtected.GET("/loans", loanHandler.GetAllLoans)                      // List loans (?status=&user_id=&min_dpd=)
+				adminProtected.GET("/loans/:id", loanHandler.GetLoanByID)                  // Get loan with schedule
+				adminProtected.POST("/loans/:id/recommend", loanHandler.RecommendLoan)     // Maker: recommend or reject application
+				adminProtected.POST("/loans/:id/approve", loanHandler.ApproveLoan)         // Checker: approve and disburse, or reject
+				adminProtected.POST("/loans/collection/run", loanHandler.RunCollectionJob) // Auto-debit due installments (default today)
+
+				// Disputes (admin only)
+				adminProtected.GET("/disputes", disputeHandler.GetAllDisputes)                                 // List disputes (?status=&assigned_admin_id=&unassigned=&overdue=)
+				adminProtected.GET("/disputes/:id", disputeHandler.GetDisputeByID)                             // Get dispute with notes, evidence and history
+				adminProtected.POST("/disputes/:id/assign", disputeHandler.AssignDispute)                      // Assign and start investigation
+				adminProtected.POST("/disputes/:id/notes", disputeHandler.AddAdminNote)                        // Add note (optionally internal)
+				adminProtected.POST("/disputes/:id/provisional-credit", disputeHandler.GrantProvisionalCredit) // Credit customer during investigation
+				adminProtected.POST("/disputes/:id/resolve", disputeHandler.ResolveDispute)                    // Reverse, adjust or reject
+
+				// Virtual cards (admin only)
+				adminProtected.GET("/card-authorizations", cardHandler.GetAllAuthorizations) // List authorizations (?status=&card_id=&user_id=&response_code=)
+				adminProtected.POST("/card-holds/expiry/run", cardHandler.RunHoldExpiryJob)  // Release uncleared holds (default today)
+
+				// Fraud rules engine (admin only)
+				adminProtected.GET("/fraud/rules", fraudHandler.GetRules)                       // List fraud rules (?rule_type=&is_active=)
+				adminProtected.POST("/fraud/rules", fraudHandler.CreateRule)                    // Create fraud rule
+				adminProtected.PUT("/fraud/rules/:id", fraudHandler.UpdateRule)                 // Update fraud rule
+				adminProtected.GET("/fraud/decisions", fraudHandler.GetDecisions)               // List decisions (?decision=&review_status=&user_id=&rule_code=)
+				adminProtected.GET("/fraud/decisions/:id", fraudHandler.GetDecisionByID)        // Get decision with rule hits
+				adminProtected.POST("/fraud/decisions/:id/review", fraudHandler.ReviewDecision) // Approve or reject a held or blocked transaction
+
+				// AML monitoring (admin only)
+				adminProtected.POST("/aml/monitoring/run", amlHandler.RunMonitoringJob) // Run monitoring for a business date (default yesterday)
+				adminProtected.GET("/aml/alerts", amlHandler.GetAlerts)                 // Investigation queue (?status=&alert_type=&user_id=&assigned_admin_id=&unassigned=true)
+				adminProtected.GET("/aml/alerts/:id", amlHandler.GetAlertByID)          // Get alert with flagged transactions
+				adminProtected.POST("/aml/alerts/:id/assign", amlHandler.AssignAlert)   // Assign alert to an analyst
+				adminProtected.POST("/aml/alerts/:id/close", amlHandler.CloseAlert)     // Close alert as not suspicious
+				adminProtected.GET("/aml/reports", amlHandler.GetReports)               // List suspicious transaction reports (?status=&user_id=)
+				adminProtected.POST("/aml/reports", amlHandler.CreateReport)            // Escalate alerts into an STR (LTKM)
+				adminProtected.GET("/aml/reports/:id", amlHandler.GetReportByID)        // Get report with alerts
+				adminProtected.GET("/aml/reports/:id/export", amlHandler.ExportReport)  // Download report as goAML XML
+				adminProtected.POST("/aml/reports/:id/submit", amlHandler.SubmitReport) // Record filing with the regulator
+
+				// Sanctions / PEP watchlist screening (admin only)
+				adminProtected.POST("/watchlist/import", watchlistHandler.ImportWatchlist)           // Import list from CSV or UN consolidated XML (multipart: file, source, list_type, replace)
+				adminProtected.GET("/watchlist/entries", watchlistHandler.GetEntries)                // List entries (?source=&list_type=&is_active=&search=)
+				adminProtected.POST("/watchlist/screen", watchlistHandler.ScreenName)                // Check a name without recording matches
+				adminProtected.POST("/watchlist/screen/users/:user_id", watchlistHandler.ScreenUser) // Rescreen a customer
+				adminProtected.GET("/watchlist/matches", watchlistHandler.GetMatches)                // Review queue (?status=&subject=&user_id=)
+				adminProtected.GET("/watchlist/matches/:id", watchlistHandler.GetMatchByID)          // Get match with customer and entry
+				adminProtected.POST("/watchlist/matches/:id/review", watchlistHandler.ReviewMatch)   // Confirm (freezes account) or dismiss
+
+				// Merchant settlement (admin only)
+				adminProtected.POST("/merchant-settlements/run", merchantSettlementHandler.RunSettlement)                  // Settle a business date (default yesterday)
+				adminProtected.GET("/merchant-settlements", merchantSettlementHandler.GetSettlements)                      // List merchant settlements
+				adminProtected.GET("/merchant-settlements/:id/report", merchantSettlementHandler.DownloadSettlementReport) // Download settlement report CSV
+
+				// Trusted devices (admin only)
+				adminProtected.GET("/users/:user_id/devices", deviceHandler.GetUserDevices) // List a user's devices
+				adminProtected.POST("/devices/:id/unbind", deviceHandler.AdminUnbindDevice) // Unbind a device, e.g. a lost phone
+
 				// User status management (admin only)
 				adminProtected.PUT("/users/:user_id/status", handlers.UpdateUserStatus)                                 // Direct status update (admin only)
 				adminProtected.POST("/users/:user_id/status/request", handlers.CreatePendingUserStatusChange)           // Create pending status change (maker-checker)
@@ -280,7 +489,7 @@ func main() {
 			}
 		} // Protected routes (require authentication)
 		protected := api.Group("/")
-		protected.Use(middleware.AuthMiddleware())
+		protected.Use(middleware.AuthMiddleware(), middleware.DeviceBindingMiddleware())
 		{
 			// Profile management
 			protected.GET("/profile", authHandler.Profile)       // Get user profile
@@ -292,6 +501,11 @@ func main() {
 			protected.POST("/logout", middleware.AuditLoginMiddleware(), authHandler.Logout)                     // Logout
 			protected.POST("/logout-others", middleware.AuditLoginMiddleware(), authHandler.LogoutOtherSessions) // Logout other sessions
 
+			// Trusted devices
+			protected.GET("/devices", deviceHandler.GetDevices)                           // List bound devices (?status=)
+			protected.PUT("/devices/current/public-key", deviceHandler.RegisterPublicKey) // Register or rotate the signing key of this device
+			protected.POST("/devices/:id/unbind", deviceHandler.UnbindDevice)             // Unbind a device (PIN required)
+
 			// Article management (all operations require authentication)
 			protected.GET("/articles", articleHandler.GetArticles)          // Get all articles (protected)
 			protected.GET("/articles/:id", articleHandler.GetArticleByID)   // Get article by ID (protected)
@@ -332,11 +546,87 @@ func main() {
 			protected.DELETE("/users/:user_id/permanent", handlers.PermanentDeleteUser) // Permanently delete user
 
 			// Transaction management (authenticated users)
-			protected.POST("/transactions/topup", transactionHandler.Topup)                // Top up balance
 			protected.POST("/transactions/withdraw", transactionHandler.Withdraw)          // Withdraw balance
 			protected.POST("/transactions/transfer", transactionHandler.Transfer)          // Transfer balance to other user
 			protected.GET("/transactions/history", transactionHandler.GetUserTransactions) // Get user transaction history
 			protected.GET("/transactions/:id", transactionHandler.GetTransactionByID)      // Get transaction detail by ID
+
+			// Interbank transfer (authenticated users)
+			protected.POST("/interbank/inquiry", interbankHandler.InquireAccount)           // Validate beneficiary account at another bank
+			protected.POST("/interbank/transfers", interbankHandler.CreateTransfer)         // Transfer to another bank
+			protected.GET("/interbank/transfers", interbankHandler.GetUserTransfers)        // Get user interbank transfer history
+			protected.GET("/interbank/transfers/:id", interbankHandler.GetUserTransferByID) // Get interbank transfer detail
+
+			// QRIS payments (authenticated users)
+			protected.POST("/qris/parse", qrisHandler.ParseQR)             // Decode scanned QR and resolve recipient
+			protected.POST("/qris/pay", qrisHandler.PayQR)                 // Pay merchant or personal QR
+			protected.GET("/qris/receive", qrisHandler.GenerateReceiveQR)  // Generate personal receive-money QR
+			protected.GET("/qris/payments", qrisHandler.GetUserQRPayments) // Get QR payment history
+
+			// Multi-currency (authenticated users)
+			protected.GET("/currencies", fxHandler.GetCurrencies) // List supported currencies
+			protected.GET("/fx/rates", fxHandler.GetActiveRates)  // Get current FX rates
+			protected.POST("/fx/quote", fxHandler.Quote)          // Preview currency conversion
+			protected.GET("/balances", fxHandler.GetBalances)     // Get balances in every currency
+
+			// Savings interest (authenticated users)
+			protected.GET("/interest", interestHandler.GetUserInterest) // Get accrued and credited interest
+
+			// Savings pockets (authenticated users)
+			protected.GET("/pockets", pocketHandler.GetPockets)            // List pockets with available balance
+			protected.POST("/pockets", pocketHandler.CreatePocket)         // Create savings pocket
+			protected.GET("/pockets/:id", pocketHandler.GetPocket)         // Get pocket with movements
+			protected.PUT("/pockets/:id", pocketHandler.UpdatePocket)      // Update target and auto-save rule
+			protected.POST("/pockets/:id/move-in", pocketHandler.MoveIn)   // Move money into pocket
+			protected.POST("/pockets/:id/move-out", pocketHandler.MoveOut) // Move money back to main balance
+			protected.DELETE("/pockets/:id", pocketHandler.ClosePocket)    // Close pocket and return balance
+
+			// Time deposits (authenticated users)
+			protected.GET("/time-deposit-products", timeDepositHandler.GetProducts)                       // List deposito products
+			protected.POST("/time-deposits", timeDepositHandler.OpenDeposit)                              // Place deposito from savings
+			protected.GET("/time-deposits", timeDepositHandler.GetUserDeposits)                           // List user deposits
+			protected.GET("/time-deposits/:id", timeDepositHandler.GetUserDepositByID)                    // Get deposit with break quote
+			protected.PUT("/time-deposits/:id/instruction", timeDepositHandler.UpdateMaturityInstruction) // Change maturity instruction
+			protected.POST("/time-deposits/:id/break", timeDepositHandler.BreakDeposit)                   // Break deposit before maturity
+
+			// Loans (authenticated users)
+			protected.GET("/loan-products", loanHandler.GetProducts)    // List loan products
+			protected.POST("/loans/simulate", loanHandler.Simulate)     // Preview installment schedule
+			protected.POST("/loans", loanHandler.Apply)                 // Apply for a loan
+			protected.GET("/loans", loanHandler.GetUserLoans)           // List user loans
+			protected.GET("/loans/:id", loanHandler.GetUserLoanByID)    // Get loan with schedule
+			protected.POST("/loans/:id/cancel", loanHandler.CancelLoan) // Cancel application before approval
+			protected.POST("/loans/:id/pay", loanHandler.PayLoan)       // Pay due installments from balance
+
+			// Disputes (authenticated users)
+			protected.POST("/disputes", disputeHandler.CreateDispute)                      // File dispute against a transaction
+			protected.GET("/disputes", disputeHandler.GetUserDisputes)                     // List user disputes
+			protected.GET("/disputes/:id", disputeHandler.GetUserDispute)                  // Get dispute with notes and history
+			protected.POST("/disputes/:id/notes", disputeHandler.AddUserNote)              // Add comment
+			protected.POST("/disputes/:id/attachments", disputeHandler.AddUserAttachments) // Add evidence
+
+			// Virtual cards (authenticated users)
+			protected.POST("/cards", cardHandler.IssueCard)                           // Issue virtual debit card (PAN and CVV shown once)
+			protected.GET("/cards", cardHandler.GetCards)                             // List user cards
+			protected.GET("/cards/:id", cardHandler.GetCard)                          // Get card with limit usage
+			protected.GET("/cards/:id/transactions", cardHandler.GetCardTransactions) // List card authorizations
+			protected.POST("/cards/:id/freeze", cardHandler.FreezeCard)               // Freeze card
+			protected.POST("/cards/:id/unfreeze", cardHandler.UnfreezeCard)           // Unfreeze card
+			protected.PUT("/cards/:id/limits", cardHandler.UpdateLimits)              // Update spending limits
+			protected.DELETE("/cards/:id", cardHandler.CloseCard)                     // Close card
+
+			// Virtual accounts (authenticated users)
+			protected.GET("/virtual-accounts/static", virtualAccountHandler.GetStaticVA)    // Get permanent top-up virtual account
+			protected.POST("/virtual-accounts", virtualAccountHandler.CreateDynamicVA)      // Create invoice virtual account
+			protected.GET("/virtual-accounts", virtualAccountHandler.GetUserVAs)            // List user virtual accounts
+			protected.DELETE("/virtual-accounts/:id", virtualAccountHandler.CloseDynamicVA) // Close unpaid invoice virtual account
+
+			// Bill payments (authenticated users)
+			protected.GET("/billers", billPaymentHandler.GetBillers)                        // List billers
+			protected.POST("/bills/inquiry", billPaymentHandler.InquireBill)                // Look up bill amount
+			protected.POST("/bills/payments", billPaymentHandler.PayBill)                   // Pay a bill
+			protected.GET("/bills/payments", billPaymentHandler.GetUserBillPayments)        // Get bill payment history
+			protected.GET("/bills/payments/:id", billPaymentHandler.GetUserBillPaymentByID) // Get bill payment detail
 		}
 	}
 
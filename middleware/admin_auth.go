@@ -53,6 +53,7 @@ func AdminAuthMiddleware() gin.HandlerFunc {
 		c.Set("admin_id", claims.AdminID)
 		c.Set("admin_email", claims.Email)
 		c.Set("admin_role", claims.Role)
+		setAuditActor(c, &claims.AdminID, nil)
 
 		c.Next()
 	}
@@ -2,26 +2,52 @@ package middleware
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"strconv"
 	"time"
 
 	"mbankingcore/handlers"
+	"mbankingcore/models"
 
 	"github.com/gin-gonic/gin"
 )
 
+// AuditRequestMiddleware stores the request metadata the audit capture callbacks record with each
+// change. The auth middlewares add the acting user or admin with setAuditActor.
+func AuditRequestMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(models.WithAuditRequest(c.Request.Context(), models.AuditRequestInfo{
+			IPAddress: c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			Endpoint:  c.Request.URL.Path,
+			Method:    c.Request.Method,
+		}))
+		c.Next()
+	}
+}
+
+// setAuditActor attributes the changes audited during the rest of the request to an admin or user
+func setAuditActor(c *gin.Context, adminID, userID *uint) {
+	c.Request = c.Request.WithContext(models.WithAuditActor(c.Request.Context(), adminID, userID))
+}
+
 // AuditLogMiddleware logs all API requests for audit trail
 func AuditLogMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Start timer
 		start := time.Now()
 
-		// Read request body for logging
+		// Keep small JSON request bodies for the audit trail. Other bodies (uploads, forms) are not
+		// read; sensitive fields are redacted when the audit log is written.
 		var body []byte
-		if c.Request.Body != nil {
+		if c.Request.Body != nil && c.Request.Method != "GET" && c.ContentType() == "application/json" &&
+			c.Request.ContentLength > 0 && c.Request.ContentLength <= maxAuditBodySize {
 			body, _ = io.ReadAll(c.Request.Body)
 			c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+			if !json.Valid(body) {
+				body = nil
+			}
 		}
 
 		// Process request
@@ -46,11 +72,15 @@ func AuditLogMiddleware() gin.HandlerFunc {
 
 		// Get entity ID from URL parameters if available
 		var entityIDValue uint = 0
-		if idParam := c.Param("id"); idParam != "" {
-			if id, err := strconv.ParseUint(idParam, 10, 32); err == nil {
-				entityIDValue = uint(id)
+		for _, param := range entityIDParams {
+			if idParam := c.Param(param); idParam != "" {
+				if id, err := strconv.ParseUint(idParam, 10, 32); err == nil {
+					entityIDValue = uint(id)
+					break
+				}
 			}
 		}
+		entityKey := c.Param("key")
 
 		// Determine action based on HTTP method
 		action := determineAction(method)
@@ -63,11 +93,13 @@ func AuditLogMiddleware() gin.HandlerFunc {
 					action,
 					entityType,
 					entityIDValue,
+					entityKey,
 					clientIP,
 					userAgent,
 					endpoint,
 					method,
 					statusCode,
+					body,
 				)
 			}
 		} else if adminExists {
@@ -77,11 +109,13 @@ func AuditLogMiddleware() gin.HandlerFunc {
 					action,
 					entityType,
 					entityIDValue,
+					entityKey,
 					clientIP,
 					userAgent,
 					endpoint,
 					method,
 					statusCode,
+					body,
 				)
 			}
 		}
@@ -95,6 +129,13 @@ func AuditLogMiddleware() gin.HandlerFunc {
 	}
 }
 
+// maxAuditBodySize is the largest request body recorded with an audit log
+const maxAuditBodySize = 16 << 10
+
+// entityIDParams are the route parameters holding the ID of the entity a request acts on, in order
+// of precedence
+var entityIDParams = []string{"id", "user_id", "admin_id"}
+
 // determineEntityType extracts entity type from API endpoint
 func determineEntityType(endpoint string) string {
 	switch {
@@ -179,30 +220,31 @@ func AuditLoginMiddleware() gin.HandlerFunc {
 		var loginType, status string
 		var userID, adminID *uint
 
+		// Failed logins keep the account they were attempted for, when the handler resolved it
 		if contains(endpoint, "/admin/login") {
 			loginType = "admin_login"
 			if statusCode == 200 {
 				status = "success"
-				if adminIDValue, exists := c.Get("admin_id"); exists {
-					if id, ok := adminIDValue.(uint); ok {
-						adminID = &id
-					}
-				}
 			} else {
 				status = "failed"
 			}
+			if adminIDValue, exists := c.Get("admin_id"); exists {
+				if id, ok := adminIDValue.(uint); ok {
+					adminID = &id
+				}
+			}
 		} else if contains(endpoint, "/login") {
 			loginType = "user_login"
 			if statusCode == 200 {
 				status = "success"
-				if userIDValue, exists := c.Get("user_id"); exists {
-					if id, ok := userIDValue.(uint); ok {
-						userID = &id
-					}
-				}
 			} else {
 				status = "failed"
 			}
+			if userIDValue, exists := c.Get("user_id"); exists {
+				if id, ok := userIDValue.(uint); ok {
+					userID = &id
+				}
+			}
 		} else if contains(endpoint, "/logout") {
 			if contains(endpoint, "/admin") {
 				loginType = "admin_logout"
@@ -226,9 +268,16 @@ func AuditLoginMiddleware() gin.HandlerFunc {
 			return // Not a login/logout endpoint
 		}
 
+		// Throttled or locked out attempts are recorded as blocked
+		if loginStatus, exists := c.Get("login_status"); exists {
+			if value, ok := loginStatus.(string); ok && status != "success" {
+				status = value
+			}
+		}
+
 		// Get failure reason if failed
 		failureReason := ""
-		if status == "failed" {
+		if status != "success" {
 			if msg, exists := c.Get("failure_reason"); exists {
 				if reason, ok := msg.(string); ok {
 					failureReason = reason
@@ -236,7 +285,12 @@ func AuditLoginMiddleware() gin.HandlerFunc {
 			}
 		}
 
+		identifier := ""
+		if value, exists := c.Get("login_identifier"); exists {
+			identifier, _ = value.(string)
+		}
+
 		// Log the login activity
-		handlers.LogLoginActivity(userID, adminID, loginType, status, clientIP, userAgent, failureReason)
+		handlers.LogLoginActivity(userID, adminID, loginType, status, identifier, clientIP, userAgent, failureReason)
 	}
 }
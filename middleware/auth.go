@@ -57,6 +57,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		// Set user info in context
 		c.Set("userID", claims.UserID)
 		c.Set("phone", claims.Phone)
+		setAuditActor(c, nil, &claims.UserID)
 		c.Next()
 	}
 }
@@ -72,6 +73,7 @@ func OptionalAuth() gin.HandlerFunc {
 				if err == nil {
 					c.Set("userID", claims.UserID)
 					c.Set("phone", claims.Phone)
+					setAuditActor(c, nil, &claims.UserID)
 				}
 			}
 		}
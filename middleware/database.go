@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"mbankingcore/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DatabaseMiddleware makes the database available to handlers as "db", bound to the request so
+// that audited changes made through it are attributed to the acting user or admin
+func DatabaseMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("db", config.DB.WithContext(c))
+		c.Next()
+	}
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"mbankingcore/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeviceBindingMiddleware only lets authenticated requests through from an active session on a
+// device bound to the user, verifying the request signature when the device registered a key.
+// Must run after AuthMiddleware.
+func DeviceBindingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !handlers.VerifyRequestDevice(c) {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
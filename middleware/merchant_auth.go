@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"mbankingcore/models"
+	"mbankingcore/utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// MerchantAPIKeyMiddleware authenticates merchant server-to-server calls with the X-API-Key header
+func MerchantAPIKeyMiddleware(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("X-API-Key")
+		if key == "" {
+			c.JSON(http.StatusUnauthorized, models.Response{
+				Code:    models.CODE_MISSING_TOKEN,
+				Message: "X-API-Key header required",
+				Data:    nil,
+			})
+			c.Abort()
+			return
+		}
+
+		var apiKey models.MerchantAPIKey
+		err := db.Where("key_prefix = ? AND revoked_at IS NULL", utils.APIKeyPrefix(key)).First(&apiKey).Error
+		if err != nil || subtle.ConstantTimeCompare([]byte(apiKey.KeyHash), []byte(utils.HashAPIKey(key))) != 1 {
+			c.JSON(http.StatusUnauthorized, models.Response{
+				Code:    models.CODE_INVALID_TOKEN,
+				Message: "Invalid or revoked API key",
+				Data:    nil,
+			})
+			c.Abort()
+			return
+		}
+
+		var merchant models.Merchant
+		if err := db.First(&merchant, apiKey.MerchantID).Error; err != nil || merchant.Status != models.MERCHANT_STATUS_ACTIVE {
+			c.JSON(http.StatusForbidden, models.Response{
+				Code:    403,
+				Message: "Merchant is not active",
+				Data:    nil,
+			})
+			c.Abort()
+			return
+		}
+
+		db.Model(&apiKey).UpdateColumn("last_used_at", time.Now())
+
+		c.Set("merchant_id", merchant.ID)
+		c.Set("merchant_api_key_id", apiKey.ID)
+
+		c.Next()
+	}
+}
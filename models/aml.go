@@ -0,0 +1,107 @@
+package models
+
+import (
+	"time"
+)
+
+// AML alert type constants
+const (
+	AML_ALERT_TYPE_STRUCTURING          = "structuring"          // repeated amounts just below the reporting threshold
+	AML_ALERT_TYPE_RAPID_MOVEMENT       = "rapid_movement"       // funds moved out shortly after they came in
+	AML_ALERT_TYPE_DORMANT_REACTIVATION = "dormant_reactivation" // long inactive account suddenly moving large amounts
+)
+
+// AML alert status constants
+const (
+	AML_ALERT_STATUS_OPEN          = "open"          // raised by the monitoring job
+	AML_ALERT_STATUS_INVESTIGATING = "investigating" // assigned to an analyst
+	AML_ALERT_STATUS_CLOSED        = "closed"        // not suspicious
+	AML_ALERT_STATUS_ESCALATED     = "escalated"     // included in a suspicious transaction report
+)
+
+// Suspicious transaction report status constants
+const (
+	STR_STATUS_DRAFT     = "draft"     // prepared, not yet filed
+	STR_STATUS_SUBMITTED = "submitted" // filed with the regulator
+)
+
+// AMLAlert is a monitoring hit waiting in the investigation queue. A user gets at most one alert
+// of a type per business date, so monitoring runs can be repeated safely.
+type AMLAlert struct {
+	ID               uint       `json:"id" gorm:"primaryKey"`
+	AlertNumber      string     `json:"alert_number" gorm:"uniqueIndex;size:30;not null"`
+	UserID           uint       `json:"user_id" gorm:"not null;uniqueIndex:idx_aml_alert_scope"`
+	AlertType        string     `json:"alert_type" gorm:"size:30;not null;uniqueIndex:idx_aml_alert_scope"` // "structuring", "rapid_movement", "dormant_reactivation"
+	BusinessDate     time.Time  `json:"business_date" gorm:"type:date;not null;uniqueIndex:idx_aml_alert_scope"`
+	RiskScore        int        `json:"risk_score" gorm:"not null;index"` // 0-100, higher is investigated first
+	WindowStart      time.Time  `json:"window_start"`
+	WindowEnd        time.Time  `json:"window_end"`
+	TransactionCount int        `json:"transaction_count"`
+	TotalAmount      int64      `json:"total_amount"`
+	Detail           string     `json:"detail" gorm:"type:text"`
+	TransactionIDs   []uint     `json:"transaction_ids" gorm:"serializer:json;type:text"`
+	Status           string     `json:"status" gorm:"size:20;not null;index"`
+	AssignedAdminID  *uint      `json:"assigned_admin_id,omitempty" gorm:"index"`
+	AssignedAt       *time.Time `json:"assigned_at,omitempty"`
+	ClosedByAdminID  *uint      `json:"closed_by_admin_id,omitempty"`
+	ClosedAt         *time.Time `json:"closed_at,omitempty"`
+	ResolutionNotes  string     `json:"resolution_notes,omitempty" gorm:"type:text"`
+	ReportID         *uint      `json:"report_id,omitempty" gorm:"index"` // Suspicious transaction report it was escalated into
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+
+	// Relationships
+	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// SuspiciousTransactionReport is a suspicious transaction report (STR, LTKM in Indonesia) built
+// from one or more escalated alerts of the same customer
+type SuspiciousTransactionReport struct {
+	ID                 uint       `json:"id" gorm:"primaryKey"`
+	ReportNumber       string     `json:"report_number" gorm:"uniqueIndex;size:30;not null"`
+	UserID             uint       `json:"user_id" gorm:"not null;index"`
+	Status             string     `json:"status" gorm:"size:20;not null;index"` // "draft", "submitted"
+	Reason             string     `json:"reason" gorm:"type:text;not null"`     // Why the transactions are suspicious
+	Action             string     `json:"action" gorm:"type:text"`              // Action taken by the bank
+	Indicators         string     `json:"indicators" gorm:"size:255"`           // Comma separated regulator indicator codes
+	TransactionIDs     []uint     `json:"transaction_ids" gorm:"serializer:json;type:text"`
+	TotalAmount        int64      `json:"total_amount"`
+	PreparedByAdminID  uint       `json:"prepared_by_admin_id" gorm:"not null"`
+	SubmittedByAdminID *uint      `json:"submitted_by_admin_id,omitempty"`
+	SubmittedAt        *time.Time `json:"submitted_at,omitempty"`
+	RegulatorReference string     `json:"regulator_reference,omitempty" gorm:"size:100"` // Receipt number from the regulator
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+
+	// Relationships
+	User   User       `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Alerts []AMLAlert `json:"alerts,omitempty" gorm:"foreignKey:ReportID"`
+}
+
+// AssignAMLAlertRequest for assigning an alert to an analyst
+type AssignAMLAlertRequest struct {
+	AdminID uint `json:"admin_id" binding:"required"`
+}
+
+// CloseAMLAlertRequest for closing an alert as not suspicious
+type CloseAMLAlertRequest struct {
+	Notes string `json:"notes" binding:"required,min=10,max=2000"`
+}
+
+// CreateSTRRequest for escalating alerts into a suspicious transaction report
+type CreateSTRRequest struct {
+	AlertIDs   []uint   `json:"alert_ids" binding:"required,min=1,max=50"`
+	Reason     string   `json:"reason" binding:"required,min=20,max=4000"`
+	Action     string   `json:"action" binding:"max=2000"`
+	Indicators []string `json:"indicators" binding:"omitempty,max=10,dive,min=1,max=20"`
+}
+
+// SubmitSTRRequest for recording that a report was filed with the regulator
+type SubmitSTRRequest struct {
+	RegulatorReference string `json:"regulator_reference" binding:"required,max=100"`
+}
+
+// RunAMLMonitoringRequest for triggering the monitoring batch
+type RunAMLMonitoringRequest struct {
+	Date string `json:"date" binding:"omitempty,datetime=2006-01-02"` // Defaults to yesterday
+}
@@ -22,15 +22,21 @@ type AuditLog struct {
 	AdminID       *uint            `json:"admin_id,omitempty"`
 	EntityType    string           `json:"entity_type" gorm:"not null;size:50"` // 'user', 'transaction', 'admin', etc.
 	EntityID      uint             `json:"entity_id" gorm:"not null"`
+	EntityKey     string           `json:"entity_key,omitempty" gorm:"size:128"`   // Non-numeric entity identifier, e.g. a config key
 	Action        string           `json:"action" gorm:"not null;size:20"`         // 'CREATE', 'UPDATE', 'DELETE', 'LOGIN', etc.
 	OldValues     *json.RawMessage `json:"old_values,omitempty" gorm:"type:jsonb"` // Data before change
 	NewValues     *json.RawMessage `json:"new_values,omitempty" gorm:"type:jsonb"` // Data after change
-	IPAddress     string           `json:"ip_address" gorm:"type:inet"`
+	Changes       *json.RawMessage `json:"changes,omitempty" gorm:"type:jsonb"`    // Changed fields as {"field": {"old": ..., "new": ...}}
+	IPAddress     string           `json:"ip_address" gorm:"type:inet;default:null"`
 	UserAgent     string           `json:"user_agent"`
 	APIEndpoint   string           `json:"api_endpoint" gorm:"size:255"`
 	RequestMethod string           `json:"request_method" gorm:"size:10"`
 	StatusCode    int              `json:"status_code"`
-	CreatedAt     time.Time        `json:"created_at"`
+	ChainSeq      *uint64          `json:"chain_seq,omitempty" gorm:"uniqueIndex:idx_audit_logs_chain_seq_created_at"` // Position in the hash chain, NULL until the record is sealed
+	PrevHash      string           `json:"prev_hash,omitempty" gorm:"size:64"`
+	Hash          string           `json:"hash,omitempty" gorm:"size:64"`
+	HashVersion   int              `json:"hash_version,omitempty" gorm:"not null;default:1"` // Hash formula the record was sealed with, see AUDIT_LOG_HASH_*
+	CreatedAt     time.Time        `json:"created_at" gorm:"uniqueIndex:idx_audit_logs_chain_seq_created_at"`
 }
 
 // LoginAudit represents login/logout audit trail
@@ -38,19 +44,24 @@ type LoginAudit struct {
 	ID            uint             `json:"id" gorm:"primaryKey"`
 	UserID        *uint            `json:"user_id,omitempty"`
 	AdminID       *uint            `json:"admin_id,omitempty"`
-	LoginType     string           `json:"login_type" gorm:"not null;size:20"` // 'user_login', 'admin_login', 'logout'
-	Status        string           `json:"status" gorm:"not null;size:20"`     // 'success', 'failed', 'blocked'
+	LoginType     string           `json:"login_type" gorm:"not null;size:20"`         // 'user_login', 'admin_login', 'logout'
+	Status        string           `json:"status" gorm:"not null;size:20"`             // 'success', 'failed', 'blocked'
+	Identifier    string           `json:"identifier,omitempty" gorm:"size:255;index"` // Phone or email the login was attempted for
 	IPAddress     string           `json:"ip_address" gorm:"type:inet"`
 	UserAgent     string           `json:"user_agent"`
 	DeviceInfo    *json.RawMessage `json:"device_info,omitempty" gorm:"type:jsonb"`
 	FailureReason string           `json:"failure_reason,omitempty"`
-	CreatedAt     time.Time        `json:"created_at"`
+	ChainSeq      *uint64          `json:"chain_seq,omitempty" gorm:"uniqueIndex:idx_login_audits_chain_seq_created_at"` // Position in the hash chain, NULL until the record is sealed
+	PrevHash      string           `json:"prev_hash,omitempty" gorm:"size:64"`
+	Hash          string           `json:"hash,omitempty" gorm:"size:64"`
+	CreatedAt     time.Time        `json:"created_at" gorm:"uniqueIndex:idx_login_audits_chain_seq_created_at"`
 }
 
 // AuditRequest represents request structure for audit queries
 type AuditRequest struct {
 	EntityType string    `form:"entity_type"` // Filter by entity type
 	EntityID   uint      `form:"entity_id"`   // Filter by entity ID
+	EntityKey  string    `form:"entity_key"`  // Filter by entity key, e.g. a config key
 	UserID     uint      `form:"user_id"`     // Filter by user ID
 	AdminID    uint      `form:"admin_id"`    // Filter by admin ID
 	Action     string    `form:"action"`      // Filter by action
@@ -63,15 +74,16 @@ type AuditRequest struct {
 
 // LoginAuditRequest represents request structure for login audit queries
 type LoginAuditRequest struct {
-	UserID    uint      `form:"user_id"`    // Filter by user ID
-	AdminID   uint      `form:"admin_id"`   // Filter by admin ID
-	LoginType string    `form:"login_type"` // Filter by login type
-	Status    string    `form:"status"`     // Filter by status
-	StartDate time.Time `form:"start_date"` // Date range start
-	EndDate   time.Time `form:"end_date"`   // Date range end
-	IPAddress string    `form:"ip_address"` // Filter by IP
-	Page      int       `form:"page"`       // Pagination
-	Limit     int       `form:"limit"`      // Items per page
+	UserID     uint      `form:"user_id"`    // Filter by user ID
+	AdminID    uint      `form:"admin_id"`   // Filter by admin ID
+	LoginType  string    `form:"login_type"` // Filter by login type
+	Status     string    `form:"status"`     // Filter by status
+	StartDate  time.Time `form:"start_date"` // Date range start
+	EndDate    time.Time `form:"end_date"`   // Date range end
+	IPAddress  string    `form:"ip_address"` // Filter by IP
+	Identifier string    `form:"identifier"` // Filter by phone or email
+	Page       int       `form:"page"`       // Pagination
+	Limit      int       `form:"limit"`      // Items per page
 }
 
 // AuditResponse represents paginated audit response
@@ -110,6 +122,9 @@ func GetAuditLogs(db *gorm.DB, req *AuditRequest) (*AuditResponse, error) {
 	if req.EntityID > 0 {
 		query = query.Where("entity_id = ?", req.EntityID)
 	}
+	if req.EntityKey != "" {
+		query = query.Where("entity_key = ?", req.EntityKey)
+	}
 	if req.UserID > 0 {
 		query = query.Where("user_id = ?", req.UserID)
 	}
@@ -192,6 +207,9 @@ func GetLoginAuditLogs(db *gorm.DB, req *LoginAuditRequest) (*LoginAuditResponse
 	if req.IPAddress != "" {
 		query = query.Where("ip_address = ?", req.IPAddress)
 	}
+	if req.Identifier != "" {
+		query = query.Where("identifier = ?", req.Identifier)
+	}
 
 	// Count total records
 	if err := query.Count(&total).Error; err != nil {
@@ -0,0 +1,174 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AUDIT_RETENTION_ENTITY_LOGIN is the entity type retention policies use for login audits
+const AUDIT_RETENTION_ENTITY_LOGIN = "login"
+
+// AuditRetentionPolicy sets how long audit records of one entity type are kept. Records past their
+// retention when their partition is archived are reduced to their chain position and hashes, so the
+// archive stays verifiable without keeping their content.
+type AuditRetentionPolicy struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	EntityType    string    `json:"entity_type" gorm:"size:50;not null;uniqueIndex"` // AuditLog.EntityType, or "login" for login audits
+	RetentionDays int       `json:"retention_days" gorm:"not null"`
+	Description   string    `json:"description" gorm:"size:255"`
+	UpdatedBy     *uint     `json:"updated_by,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// AuditRetentionPolicyRequest sets the retention of an entity type
+type AuditRetentionPolicyRequest struct {
+	RetentionDays int    `json:"retention_days" binding:"required,min=1"`
+	Description   string `json:"description" binding:"max=255"`
+}
+
+// AuditArchiveRange is a run of consecutive chain positions held by an archive, with the hashes
+// that link it to the records before and after it
+type AuditArchiveRange struct {
+	FromSeq  uint64 `json:"from_seq"`
+	ToSeq    uint64 `json:"to_seq"`
+	PrevHash string `json:"prev_hash"` // prev_hash of the record at FromSeq
+	Hash     string `json:"hash"`      // hash of the record at ToSeq
+}
+
+// AuditArchive records an audit partition written to a compressed archive file and dropped from the
+// database. The same fields, signed, form the manifest stored next to the archive file.
+type AuditArchive struct {
+	ID           uint             `json:"id" gorm:"primaryKey"`
+	Chain        string           `json:"chain" gorm:"size:20;not null;index"`
+	Partition    string           `json:"partition" gorm:"size:63;not null;uniqueIndex"`
+	PeriodStart  time.Time        `json:"period_start" gorm:"not null"`
+	PeriodEnd    time.Time        `json:"period_end" gorm:"not null"`
+	FileName     string           `json:"file_name" gorm:"size:255;not null"` // Relative to the archive directory
+	SHA256       string           `json:"sha256" gorm:"size:64;not null"`     // Of the compressed file
+	SizeBytes    int64            `json:"size_bytes"`
+	RecordCount  int64            `json:"record_count"`
+	PrunedCount  int64            `json:"pruned_count"` // Records past retention, archived without content
+	FirstSeq     uint64           `json:"first_seq"`
+	LastSeq      uint64           `json:"last_seq"`
+	SeqRanges    *json.RawMessage `json:"seq_ranges" gorm:"type:jsonb"`      // []AuditArchiveRange
+	EntityCounts *json.RawMessage `json:"entity_counts" gorm:"type:jsonb"`   // Records per entity type
+	Signature    string           `json:"signature" gorm:"size:64;not null"` // HMAC-SHA256 of SigningPayload
+	CreatedBy    string           `json:"created_by" gorm:"size:50"`
+	CreatedAt    time.Time        `json:"created_at"`
+	RestoredAt   *time.Time       `json:"restored_at,omitempty"` // Set while the records are restored to the database
+}
+
+// Ranges decodes SeqRanges
+func (a *AuditArchive) Ranges() ([]AuditArchiveRange, error) {
+	var ranges []AuditArchiveRange
+	if a.SeqRanges == nil {
+		return ranges, nil
+	}
+	err := json.Unmarshal(*a.SeqRanges, &ranges)
+	return ranges, err
+}
+
+// SigningPayload returns the canonical form of the archive's manifest that is signed. JSON columns
+// are re-encoded because the database does not preserve their formatting.
+func (a *AuditArchive) SigningPayload() (string, error) {
+	ranges, err := a.Ranges()
+	if err != nil {
+		return "", err
+	}
+	var counts map[string]int64
+	if a.EntityCounts != nil {
+		if err := json.Unmarshal(*a.EntityCounts, &counts); err != nil {
+			return "", err
+		}
+	}
+	rangesJSON, _ := json.Marshal(ranges)
+	countsJSON, _ := json.Marshal(counts)
+
+	return fmt.Sprintf("%s|%s|%s|%s|%s|%s|%d|%d|%d|%d|%d|%s|%s",
+		a.Chain, a.Partition, a.PeriodStart.UTC().Format(time.RFC3339), a.PeriodEnd.UTC().Format(time.RFC3339),
+		a.FileName, a.SHA256, a.SizeBytes, a.RecordCount, a.PrunedCount, a.FirstSeq, a.LastSeq,
+		rangesJSON, countsJSON), nil
+}
+
+// AuditArchiveRecord is one line of an archive file. Pruned records keep only their identity, chain
+// position and hashes.
+type AuditArchiveRecord struct {
+	Pruned     bool        `json:"pruned,omitempty"`
+	AuditLog   *AuditLog   `json:"audit_log,omitempty"`
+	LoginAudit *LoginAudit `json:"login_audit,omitempty"`
+	// Stored text of the record's JSON columns, which are hashed as stored but compacted when the
+	// record is encoded
+	JSONColumns map[string]string `json:"json_columns,omitempty"`
+}
+
+// NewAuditLogArchiveRecord returns the archive line of an audit log, pruned when it is past retention
+func NewAuditLogArchiveRecord(a *AuditLog, pruned bool) AuditArchiveRecord {
+	if pruned {
+		return AuditArchiveRecord{Pruned: true, AuditLog: &AuditLog{
+			ID:          a.ID,
+			EntityType:  a.EntityType,
+			Action:      a.Action,
+			ChainSeq:    a.ChainSeq,
+			PrevHash:    a.PrevHash,
+			Hash:        a.Hash,
+			HashVersion: a.HashVersion,
+			CreatedAt:   a.CreatedAt,
+		}}
+	}
+	return AuditArchiveRecord{AuditLog: a, JSONColumns: archiveJSONColumns(map[string]*json.RawMessage{
+		"old_values": a.OldValues,
+		"new_values": a.NewValues,
+		"changes":    a.Changes,
+	})}
+}
+
+// NewLoginAuditArchiveRecord returns the archive line of a login audit, pruned when it is past retention
+func NewLoginAuditArchiveRecord(l *LoginAudit, pruned bool) AuditArchiveRecord {
+	if pruned {
+		return AuditArchiveRecord{Pruned: true, LoginAudit: &LoginAudit{
+			ID:        l.ID,
+			LoginType: l.LoginType,
+			Status:    l.Status,
+			ChainSeq:  l.ChainSeq,
+			PrevHash:  l.PrevHash,
+			Hash:      l.Hash,
+			CreatedAt: l.CreatedAt,
+		}}
+	}
+	return AuditArchiveRecord{LoginAudit: l, JSONColumns: archiveJSONColumns(map[string]*json.RawMessage{
+		"device_info": l.DeviceInfo,
+	})}
+}
+
+func archiveJSONColumns(columns map[string]*json.RawMessage) map[string]string {
+	stored := map[string]string{}
+	for name, value := range columns {
+		if value != nil {
+			stored[name] = string(*value)
+		}
+	}
+	if len(stored) == 0 {
+		return nil
+	}
+	return stored
+}
+
+// RestoreJSONColumns puts the stored text of the JSON columns back into the decoded record
+func (r *AuditArchiveRecord) RestoreJSONColumns() {
+	restore := func(name string, target **json.RawMessage) {
+		if value, ok := r.JSONColumns[name]; ok {
+			raw := json.RawMessage(value)
+			*target = &raw
+		}
+	}
+	if r.AuditLog != nil {
+		restore("old_values", &r.AuditLog.OldValues)
+		restore("new_values", &r.AuditLog.NewValues)
+		restore("changes", &r.AuditLog.Changes)
+	}
+	if r.LoginAudit != nil {
+		restore("device_info", &r.LoginAudit.DeviceInfo)
+	}
+}
@@ -0,0 +1,507 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// auditedTables maps the tables whose changes are captured field by field to their audit entity type
+var auditedTables = map[string]string{
+	"users":                    "user",
+	"admins":                   "admin",
+	"configs":                  "config",
+	"bank_accounts":            "bank_account",
+	"approval_thresholds":      "approval_threshold",
+	"audit_retention_policies": "audit_retention",
+}
+
+// Columns left out of field diffs: bookkeeping that changes on every write, and login times that
+// are already recorded in login audits
+var auditIgnoredColumns = map[string]bool{
+	"updated_at": true,
+	"last_login": true,
+}
+
+// High-churn columns of audited tables left out of capture because every change to them is already
+// recorded elsewhere: user balances move with each row in transactions, and admin balance changes
+// also write their own user_balance audit entry. Balance writes go through Update("balance", ...),
+// so they are not snapshotted at all, keeping the extra reads out of the money transactions.
+var auditIgnoredTableColumns = map[string]map[string]bool{
+	"users": {"balance": true},
+}
+
+// auditIgnored reports whether changes to column of table are left out of field diffs
+func auditIgnored(table, column string) bool {
+	return auditIgnoredColumns[column] || auditIgnoredTableColumns[table][column]
+}
+
+const (
+	auditSnapshotsKey    = "audit:snapshots"
+	maxAuditSnapshotRows = 1000 // Bulk updates touching more rows are captured for the first rows only
+)
+
+// AuditRequestInfo is the request behind audited changes: who acted and where the request came
+// from. Middleware stores it on the request context and the capture callbacks read it from the
+// statement context.
+type AuditRequestInfo struct {
+	AdminID   *uint
+	UserID    *uint
+	IPAddress string
+	UserAgent string
+	Endpoint  string
+	Method    string
+}
+
+// auditRequestKey is the context key of the AuditRequestInfo
+type auditRequestKey struct{}
+
+// WithAuditRequest returns a copy of ctx carrying request
+func WithAuditRequest(ctx context.Context, request AuditRequestInfo) context.Context {
+	return context.WithValue(ctx, auditRequestKey{}, request)
+}
+
+// WithAuditActor returns a copy of ctx whose AuditRequestInfo is attributed to the admin or user
+func WithAuditActor(ctx context.Context, adminID, userID *uint) context.Context {
+	request, _ := AuditRequestFrom(ctx)
+	request.AdminID = adminID
+	request.UserID = userID
+	return WithAuditRequest(ctx, request)
+}
+
+// AuditRequestFrom returns the AuditRequestInfo stored in ctx, if any
+func AuditRequestFrom(ctx context.Context) (AuditRequestInfo, bool) {
+	if ctx == nil {
+		return AuditRequestInfo{}, false
+	}
+	request, ok := ctx.Value(auditRequestKey{}).(AuditRequestInfo)
+	return request, ok
+}
+
+// AuditFieldChange is one changed field in AuditLog.Changes
+type AuditFieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// auditHiddenValue stands in for fields hidden from JSON (PINs, password hashes) in snapshots and diffs
+const auditHiddenValue = "[HIDDEN]"
+
+// auditSnapshot is the column values of one row, keyed by its primary key. Values of hidden fields
+// are kept apart so a change to them is detected without their values being recorded.
+type auditSnapshot struct {
+	key    string
+	id     uint
+	values map[string]interface{}
+	hidden map[string]interface{}
+}
+
+// RegisterAuditCallbacks installs GORM callbacks that write an AuditLog with before/after snapshots
+// and a field diff for every create, update and delete of an audited model. The acting user or admin
+// is read from the AuditRequestInfo on the statement context, so handlers pass the request with
+// db.WithContext(c). redact is applied to the JSON payloads of every audit log written, whichever
+// code path writes it.
+func RegisterAuditCallbacks(db *gorm.DB, redact func([]byte) []byte) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("audit:redact_payload", auditRedactPayload(redact)); err != nil {
+		return err
+	}
+	if err := db.Callback().Create().After("gorm:create").Register("audit:after_create", auditAfterCreate); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("audit:before_update", auditBeforeUpdate); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("audit:after_update", auditAfterUpdate); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("audit:before_delete", auditBeforeChange); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Register("audit:after_delete", auditAfterDelete)
+}
+
+// auditRedactPayload returns a callback that redacts the old values, new values and changes of audit
+// logs before they are inserted
+func auditRedactPayload(redact func([]byte) []byte) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if redact == nil || db.Statement.Schema == nil || db.Statement.Schema.Table != AUDIT_CHAIN_AUDIT_LOG {
+			return
+		}
+
+		redactLog := func(row reflect.Value) {
+			for row.Kind() == reflect.Ptr {
+				if row.IsNil() {
+					return
+				}
+				row = row.Elem()
+			}
+			if !row.CanAddr() {
+				return
+			}
+			auditLog, ok := row.Addr().Interface().(*AuditLog)
+			if !ok {
+				return
+			}
+			for _, payload := range []*json.RawMessage{auditLog.OldValues, auditLog.NewValues, auditLog.Changes} {
+				if payload != nil && len(*payload) > 0 {
+					*payload = redact(*payload)
+				}
+			}
+		}
+
+		value := db.Statement.ReflectValue
+		switch value.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < value.Len(); i++ {
+				redactLog(value.Index(i))
+			}
+		case reflect.Struct, reflect.Ptr:
+			redactLog(value)
+		}
+	}
+}
+
+func auditEntityType(db *gorm.DB) (string, bool) {
+	if db.Statement.Schema == nil {
+		return "", false
+	}
+	entityType, ok := auditedTables[db.Statement.Schema.Table]
+	return entityType, ok
+}
+
+func auditAfterCreate(db *gorm.DB) {
+	entityType, ok := auditEntityType(db)
+	if !ok || db.Error != nil || db.RowsAffected == 0 {
+		return
+	}
+
+	for _, snapshot := range auditSnapshotsOf(db.Statement, db.Statement.ReflectValue) {
+		writeAuditChange(db, entityType, "CREATE", nil, &snapshot)
+	}
+}
+
+// auditBeforeChange loads the rows an update or delete is about to touch
+func auditBeforeChange(db *gorm.DB) {
+	if _, ok := auditEntityType(db); !ok || db.Error != nil {
+		return
+	}
+
+	query, ok := auditTargetQuery(db)
+	if !ok {
+		return
+	}
+	snapshots, err := loadAuditSnapshots(query, db.Statement.Schema)
+	if err != nil {
+		log.Printf("Audit capture: failed to load %s before change: %v", db.Statement.Schema.Table, err)
+		return
+	}
+	db.InstanceSet(auditSnapshotsKey, snapshots)
+}
+
+// auditBeforeUpdate loads the rows an update is about to touch, unless it only assigns ignored columns
+func auditBeforeUpdate(db *gorm.DB) {
+	if db.Statement.Schema == nil || auditUpdatesIgnoredOnly(db) {
+		return
+	}
+	auditBeforeChange(db)
+}
+
+// auditUpdatesIgnoredOnly reports whether an update assigns ignored columns only, as the ledger's
+// Update("balance", ...) calls do. Updates whose columns cannot be told before they run are captured.
+func auditUpdatesIgnoredOnly(db *gorm.DB) bool {
+	stmt := db.Statement
+	var columns []string
+	switch dest := stmt.Dest.(type) {
+	case map[string]interface{}:
+		for column := range dest {
+			columns = append(columns, column)
+		}
+	default:
+		if len(stmt.Selects) == 0 {
+			return false
+		}
+		columns = stmt.Selects
+	}
+	if len(columns) == 0 || len(stmt.Omits) > 0 {
+		return false
+	}
+
+	for _, column := range columns {
+		field := stmt.Schema.LookUpField(column)
+		if field == nil || !auditIgnored(stmt.Schema.Table, field.DBName) {
+			return false
+		}
+	}
+	return true
+}
+
+func auditAfterUpdate(db *gorm.DB) {
+	entityType, ok := auditEntityType(db)
+	if !ok || db.Error != nil {
+		return
+	}
+	before := auditStoredSnapshots(db)
+	if len(before) == 0 {
+		return
+	}
+
+	after, err := reloadAuditSnapshots(db, before)
+	if err != nil {
+		log.Printf("Audit capture: failed to reload %s after update: %v", db.Statement.Schema.Table, err)
+		return
+	}
+	for i := range before {
+		if current, ok := after[before[i].key]; ok {
+			writeAuditChange(db, entityType, "UPDATE", &before[i], &current)
+		}
+	}
+}
+
+func auditAfterDelete(db *gorm.DB) {
+	entityType, ok := auditEntityType(db)
+	if !ok || db.Error != nil {
+		return
+	}
+	before := auditStoredSnapshots(db)
+	if len(before) == 0 {
+		return
+	}
+
+	// Soft deletes leave the row in place with deleted_at set
+	after, err := reloadAuditSnapshots(db, before)
+	if err != nil {
+		log.Printf("Audit capture: failed to reload %s after delete: %v", db.Statement.Schema.Table, err)
+		return
+	}
+	for i := range before {
+		if current, ok := after[before[i].key]; ok {
+			writeAuditChange(db, entityType, "DELETE", &before[i], &current)
+		} else {
+			writeAuditChange(db, entityType, "DELETE", &before[i], nil)
+		}
+	}
+}
+
+func auditStoredSnapshots(db *gorm.DB) []auditSnapshot {
+	value, ok := db.InstanceGet(auditSnapshotsKey)
+	if !ok {
+		return nil
+	}
+	snapshots, _ := value.([]auditSnapshot)
+	return snapshots
+}
+
+// auditTargetQuery builds a query selecting the rows the statement applies to: its primary key when
+// the model carries one, plus any explicit conditions. Statements without either are not captured.
+func auditTargetQuery(db *gorm.DB) (*gorm.DB, bool) {
+	stmt := db.Statement
+	query := db.Session(&gorm.Session{NewDB: true}).Model(reflect.New(stmt.Schema.ModelType).Interface())
+	if stmt.Unscoped {
+		query = query.Unscoped()
+	}
+
+	conditions := false
+	if stmt.ReflectValue.Kind() == reflect.Struct {
+		for _, field := range stmt.Schema.PrimaryFields {
+			if value, isZero := field.ValueOf(stmt.Context, stmt.ReflectValue); !isZero {
+				query = query.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: value})
+				conditions = true
+			}
+		}
+	}
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok && len(where.Exprs) > 0 {
+			query = query.Clauses(clause.Where{Exprs: where.Exprs})
+			conditions = true
+		}
+	}
+	return query, conditions
+}
+
+func loadAuditSnapshots(query *gorm.DB, s *schema.Schema) ([]auditSnapshot, error) {
+	rows := reflect.New(reflect.SliceOf(s.ModelType))
+	if err := query.Limit(maxAuditSnapshotRows).Find(rows.Interface()).Error; err != nil {
+		return nil, err
+	}
+	stmt := &gorm.Statement{Schema: s, Context: query.Statement.Context}
+	return auditSnapshotsOf(stmt, rows.Elem()), nil
+}
+
+// reloadAuditSnapshots reads the rows captured before a change again, including soft-deleted rows
+func reloadAuditSnapshots(db *gorm.DB, before []auditSnapshot) (map[string]auditSnapshot, error) {
+	s := db.Statement.Schema
+	query := db.Session(&gorm.Session{NewDB: true}).Unscoped().Model(reflect.New(s.ModelType).Interface())
+
+	keys := make([]interface{}, 0, len(before))
+	for _, snapshot := range before {
+		keys = append(keys, snapshot.values[s.PrioritizedPrimaryField.DBName])
+	}
+	query = query.Where(clause.IN{Column: clause.Column{Table: clause.CurrentTable, Name: s.PrioritizedPrimaryField.DBName}, Values: keys})
+
+	snapshots, err := loadAuditSnapshots(query, s)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]auditSnapshot, len(snapshots))
+	for _, snapshot := range snapshots {
+		byKey[snapshot.key] = snapshot
+	}
+	return byKey, nil
+}
+
+// auditSnapshotsOf reads the column values of a struct, slice or array of models
+func auditSnapshotsOf(stmt *gorm.Statement, value reflect.Value) []auditSnapshot {
+	s := stmt.Schema
+	if s == nil || s.PrioritizedPrimaryField == nil {
+		return nil
+	}
+
+	var snapshots []auditSnapshot
+	add := func(row reflect.Value) {
+		for row.Kind() == reflect.Ptr {
+			if row.IsNil() {
+				return
+			}
+			row = row.Elem()
+		}
+		if row.Kind() != reflect.Struct {
+			return
+		}
+
+		snapshot := auditSnapshot{values: map[string]interface{}{}, hidden: map[string]interface{}{}}
+		for _, field := range s.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			fieldValue, _ := field.ValueOf(stmt.Context, row)
+			if field.Tag.Get("json") == "-" {
+				snapshot.hidden[field.DBName] = fieldValue
+				snapshot.values[field.DBName] = auditHiddenValue
+				continue
+			}
+			snapshot.values[field.DBName] = fieldValue
+		}
+		primaryKey, _ := s.PrioritizedPrimaryField.ValueOf(stmt.Context, row)
+		snapshot.key = fmt.Sprint(primaryKey)
+		snapshot.values[s.PrioritizedPrimaryField.DBName] = primaryKey
+		if id, ok := primaryKey.(uint); ok {
+			snapshot.id = id
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			add(value.Index(i))
+		}
+	default:
+		add(value)
+	}
+	return snapshots
+}
+
+// compared returns the value a column is compared on, the real value for hidden fields
+func (s *auditSnapshot) compared(column string) interface{} {
+	if value, ok := s.hidden[column]; ok {
+		return value
+	}
+	return s.values[column]
+}
+
+// auditFieldChanges returns the fields whose values differ between two snapshots
+func auditFieldChanges(table string, before, after *auditSnapshot) map[string]AuditFieldChange {
+	changes := map[string]AuditFieldChange{}
+	columns := map[string]bool{}
+	if before != nil {
+		for column := range before.values {
+			columns[column] = true
+		}
+	}
+	if after != nil {
+		for column := range after.values {
+			columns[column] = true
+		}
+	}
+
+	for column := range columns {
+		if auditIgnored(table, column) {
+			continue
+		}
+		var oldValue, newValue, oldCompared, newCompared interface{}
+		if before != nil {
+			oldValue, oldCompared = before.values[column], before.compared(column)
+		}
+		if after != nil {
+			newValue, newCompared = after.values[column], after.compared(column)
+		}
+		oldJSON, _ := json.Marshal(oldCompared)
+		newJSON, _ := json.Marshal(newCompared)
+		if !bytes.Equal(oldJSON, newJSON) {
+			changes[column] = AuditFieldChange{Old: oldValue, New: newValue}
+		}
+	}
+	return changes
+}
+
+// writeAuditChange stores one captured change in the same transaction as the change itself
+func writeAuditChange(db *gorm.DB, entityType, action string, before, after *auditSnapshot) {
+	changes := auditFieldChanges(db.Statement.Schema.Table, before, after)
+	if len(changes) == 0 {
+		return
+	}
+
+	subject := after
+	if subject == nil {
+		subject = before
+	}
+	auditLog := AuditLog{
+		EntityType: entityType,
+		EntityID:   subject.id,
+		Action:     action,
+		OldValues:  auditJSON(before),
+		NewValues:  auditJSON(after),
+		Changes:    auditJSON(changes),
+	}
+	if subject.id == 0 {
+		auditLog.EntityKey = subject.key
+	}
+
+	if request, ok := AuditRequestFrom(db.Statement.Context); ok {
+		auditLog.AdminID = request.AdminID
+		if auditLog.AdminID == nil {
+			auditLog.UserID = request.UserID
+		}
+		auditLog.IPAddress = request.IPAddress
+		auditLog.UserAgent = request.UserAgent
+		auditLog.APIEndpoint = request.Endpoint
+		auditLog.RequestMethod = request.Method
+	}
+
+	if err := db.Session(&gorm.Session{NewDB: true}).Create(&auditLog).Error; err != nil {
+		log.Printf("Audit capture: failed to write %s %s %s: %v", action, entityType, subject.key, err)
+	}
+}
+
+// auditJSON encodes a snapshot's values or a change set for a jsonb column
+func auditJSON(value interface{}) *json.RawMessage {
+	if snapshot, ok := value.(*auditSnapshot); ok {
+		if snapshot == nil {
+			return nil
+		}
+		value = snapshot.values
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	raw := json.RawMessage(data)
+	return &raw
+}
@@ -0,0 +1,237 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Audit chain names
+const (
+	AUDIT_CHAIN_AUDIT_LOG   = "audit_logs"
+	AUDIT_CHAIN_LOGIN_AUDIT = "login_audits"
+)
+
+// Audit chain verification issue types
+const (
+	AUDIT_CHAIN_ISSUE_GAP                 = "gap"                 // chain sequence numbers are missing, rows were deleted
+	AUDIT_CHAIN_ISSUE_BROKEN_LINK         = "broken_link"         // prev_hash does not match the hash of the previous record
+	AUDIT_CHAIN_ISSUE_HASH_MISMATCH       = "hash_mismatch"       // record content no longer matches its hash, the row was modified
+	AUDIT_CHAIN_ISSUE_UNSEALED            = "unsealed"            // record still not linked into the chain well after it was written
+	AUDIT_CHAIN_ISSUE_CHECKPOINT_MISMATCH = "checkpoint_mismatch" // record at a checkpoint is missing or has a different hash
+	AUDIT_CHAIN_ISSUE_CHECKPOINT_INVALID  = "checkpoint_invalid"  // checkpoint signature does not verify
+	AUDIT_CHAIN_ISSUE_TRUNCATED           = "truncated"           // a checkpoint refers to records past the end of the chain
+	AUDIT_CHAIN_ISSUE_ARCHIVE_INVALID     = "archive_invalid"     // archive manifest signature does not verify
+)
+
+// Audit log hash formulas. Records keep the version they were sealed with, so a change to the
+// formula does not invalidate records sealed before it.
+const (
+	AUDIT_LOG_HASH_V1      = 1 // Without entity_key and changes
+	AUDIT_LOG_HASH_V2      = 2 // Covers entity_key and changes
+	AUDIT_LOG_HASH_CURRENT = AUDIT_LOG_HASH_V2
+)
+
+// Advisory lock keys that serialise sealing of each chain
+const (
+	auditLogChainLockKey   int64 = 0x6d62_6175_6469_7401
+	loginAuditChainLockKey int64 = 0x6d62_6175_6469_7402
+)
+
+const auditSealBatchSize = 500
+
+// AuditCheckpoint is a signed snapshot of a chain head. The signature uses a key that is not stored
+// in the database, so rewriting the chain from some record onwards is detected at the next checkpoint.
+type AuditCheckpoint struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Chain     string    `json:"chain" gorm:"size:20;not null;uniqueIndex:idx_audit_checkpoint_chain_seq"` // "audit_logs", "login_audits"
+	ChainSeq  uint64    `json:"chain_seq" gorm:"not null;uniqueIndex:idx_audit_checkpoint_chain_seq"`
+	Hash      string    `json:"hash" gorm:"size:64;not null"`
+	Signature string    `json:"signature" gorm:"size:64;not null"` // HMAC-SHA256 of chain, sequence and hash
+	CreatedBy string    `json:"created_by" gorm:"size:50"`         // "system" or "admin:<id>"
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// auditChainHash hashes a record's content together with the hash of the record before it
+func auditChainHash(prevHash string, content interface{}) string {
+	payload, _ := json.Marshal(content)
+	sum := sha256.Sum256(append([]byte(prevHash+"\n"), payload...))
+	return hex.EncodeToString(sum[:])
+}
+
+// rawJSON returns the stored JSON text, or an empty string when the column is NULL
+func rawJSON(value *json.RawMessage) string {
+	if value == nil {
+		return ""
+	}
+	return string(*value)
+}
+
+// ComputeChainHash returns the hash the record should carry given its content, PrevHash and the
+// formula of its HashVersion
+func (a *AuditLog) ComputeChainHash() string {
+	var seq uint64
+	if a.ChainSeq != nil {
+		seq = *a.ChainSeq
+	}
+	if a.HashVersion == AUDIT_LOG_HASH_V1 {
+		return auditChainHash(a.PrevHash, []interface{}{
+			a.ID, seq, a.UserID, a.AdminID, a.EntityType, a.EntityID, a.Action,
+			rawJSON(a.OldValues), rawJSON(a.NewValues), a.IPAddress, a.UserAgent,
+			a.APIEndpoint, a.RequestMethod, a.StatusCode, a.CreatedAt.UnixMicro(),
+		})
+	}
+	return auditChainHash(a.PrevHash, []interface{}{
+		a.ID, seq, a.UserID, a.AdminID, a.EntityType, a.EntityID, a.EntityKey, a.Action,
+		rawJSON(a.OldValues), rawJSON(a.NewValues), rawJSON(a.Changes), a.IPAddress, a.UserAgent,
+		a.APIEndpoint, a.RequestMethod, a.StatusCode, a.CreatedAt.UnixMicro(),
+	})
+}
+
+// ComputeChainHash returns the hash the record should carry given its content and PrevHash
+func (l *LoginAudit) ComputeChainHash() string {
+	var seq uint64
+	if l.ChainSeq != nil {
+		seq = *l.ChainSeq
+	}
+	return auditChainHash(l.PrevHash, []interface{}{
+		l.ID, seq, l.UserID, l.AdminID, l.LoginType, l.Status, l.Identifier,
+		l.IPAddress, l.UserAgent, rawJSON(l.DeviceInfo), l.FailureReason, l.CreatedAt.UnixMicro(),
+	})
+}
+
+// SealAuditChain links every record of a chain that has no chain position yet, in insertion order.
+// Records are sealed after they are committed rather than while they are written, so writers never
+// wait on the chain lock inside their own transactions; returns the number of records sealed.
+func SealAuditChain(db *gorm.DB, chain string) (int, error) {
+	lockKey := auditLogChainLockKey
+	if chain == AUDIT_CHAIN_LOGIN_AUDIT {
+		lockKey = loginAuditChainLockKey
+	} else if chain != AUDIT_CHAIN_AUDIT_LOG {
+		return 0, errors.New("unknown audit chain")
+	}
+
+	sealed := 0
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", lockKey).Error; err != nil {
+			return err
+		}
+		seq, hash, err := AuditChainHead(tx, chain)
+		if err != nil {
+			return err
+		}
+
+		for {
+			var records []auditChainLink
+			switch chain {
+			case AUDIT_CHAIN_AUDIT_LOG:
+				var logs []AuditLog
+				if err := tx.Where("chain_seq IS NULL").Order("id ASC").Limit(auditSealBatchSize).Find(&logs).Error; err != nil {
+					return err
+				}
+				for i := range logs {
+					records = append(records, &logs[i])
+				}
+			case AUDIT_CHAIN_LOGIN_AUDIT:
+				var logs []LoginAudit
+				if err := tx.Where("chain_seq IS NULL").Order("id ASC").Limit(auditSealBatchSize).Find(&logs).Error; err != nil {
+					return err
+				}
+				for i := range logs {
+					records = append(records, &logs[i])
+				}
+			}
+
+			for _, record := range records {
+				seq++
+				prevHash := hash
+				hash = record.link(seq, prevHash)
+				if err := tx.Model(record).UpdateColumns(record.chainColumns()).Error; err != nil {
+					return err
+				}
+				sealed++
+			}
+			if len(records) < auditSealBatchSize {
+				return nil
+			}
+		}
+	})
+	return sealed, err
+}
+
+// auditChainLink is an audit record that can be given its place in a chain
+type auditChainLink interface {
+	link(seq uint64, prevHash string) string
+	chainColumns() map[string]interface{}
+}
+
+func (a *AuditLog) link(seq uint64, prevHash string) string {
+	a.ChainSeq = &seq
+	a.PrevHash = prevHash
+	a.HashVersion = AUDIT_LOG_HASH_CURRENT
+	a.Hash = a.ComputeChainHash()
+	return a.Hash
+}
+
+func (a *AuditLog) chainColumns() map[string]interface{} {
+	return map[string]interface{}{
+		"chain_seq":    a.ChainSeq,
+		"prev_hash":    a.PrevHash,
+		"hash":         a.Hash,
+		"hash_version": a.HashVersion,
+	}
+}
+
+func (l *LoginAudit) link(seq uint64, prevHash string) string {
+	l.ChainSeq = &seq
+	l.PrevHash = prevHash
+	l.Hash = l.ComputeChainHash()
+	return l.Hash
+}
+
+func (l *LoginAudit) chainColumns() map[string]interface{} {
+	return map[string]interface{}{
+		"chain_seq": l.ChainSeq,
+		"prev_hash": l.PrevHash,
+		"hash":      l.Hash,
+	}
+}
+
+// auditChainHead returns the sequence number and hash of the last chained record of model's table,
+// or zero and an empty hash for an empty chain
+func auditChainHead(tx *gorm.DB, model interface{}) (uint64, string, error) {
+	var head struct {
+		ChainSeq uint64
+		Hash     string
+	}
+	result := tx.Model(model).Select("chain_seq, hash").
+		Where("chain_seq IS NOT NULL").
+		Order("chain_seq DESC").
+		Limit(1).
+		Scan(&head)
+	if result.Error != nil {
+		return 0, "", result.Error
+	}
+	return head.ChainSeq, head.Hash, nil
+}
+
+// AuditChainHead returns the last chained record of a chain, used when writing checkpoints
+func AuditChainHead(db *gorm.DB, chain string) (uint64, string, error) {
+	switch chain {
+	case AUDIT_CHAIN_AUDIT_LOG:
+		return auditChainHead(db, &AuditLog{})
+	case AUDIT_CHAIN_LOGIN_AUDIT:
+		return auditChainHead(db, &LoginAudit{})
+	default:
+		return 0, "", errors.New("unknown audit chain")
+	}
+}
+
+// IsValidAuditChain reports whether chain names a chained audit table
+func IsValidAuditChain(chain string) bool {
+	return chain == AUDIT_CHAIN_AUDIT_LOG || chain == AUDIT_CHAIN_LOGIN_AUDIT
+}
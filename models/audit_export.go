@@ -0,0 +1,128 @@
+package models
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// Audit event categories
+const (
+	AUDIT_EVENT_CATEGORY_AUDIT = "audit"
+	AUDIT_EVENT_CATEGORY_LOGIN = "login"
+)
+
+// Audit event outcomes
+const (
+	AUDIT_EVENT_OUTCOME_SUCCESS = "success"
+	AUDIT_EVENT_OUTCOME_FAILURE = "failure"
+)
+
+// AuditExportCursor tracks how far each chain has been delivered to an export sink. Records are
+// exported in chain order once sealed, so a sink resumes after the last sequence it acknowledged.
+type AuditExportCursor struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	Sink           string     `json:"sink" gorm:"size:50;not null;uniqueIndex:idx_audit_export_cursor"`
+	Chain          string     `json:"chain" gorm:"size:20;not null;uniqueIndex:idx_audit_export_cursor"`
+	LastSeq        uint64     `json:"last_seq" gorm:"not null;default:0"`
+	LastExportedAt *time.Time `json:"last_exported_at,omitempty"`
+	FailureCount   int        `json:"failure_count" gorm:"not null;default:0"` // Consecutive failed deliveries
+	LastError      string     `json:"last_error,omitempty" gorm:"size:500"`
+	LastErrorAt    *time.Time `json:"last_error_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// AuditEvent is an audit or login record as exported to a SIEM. Payloads are left out; the changed
+// field names are included so that events stay small.
+type AuditEvent struct {
+	Chain         string    `json:"chain"`
+	ChainSeq      uint64    `json:"chain_seq"`
+	RecordID      uint      `json:"record_id"`
+	Time          time.Time `json:"time"`
+	Category      string    `json:"category"` // "audit", "login"
+	Action        string    `json:"action"`
+	Outcome       string    `json:"outcome"` // "success", "failure"
+	UserID        *uint     `json:"user_id,omitempty"`
+	AdminID       *uint     `json:"admin_id,omitempty"`
+	EntityType    string    `json:"entity_type,omitempty"`
+	EntityID      uint      `json:"entity_id,omitempty"`
+	EntityKey     string    `json:"entity_key,omitempty"`
+	ChangedFields []string  `json:"changed_fields,omitempty"`
+	Identifier    string    `json:"identifier,omitempty"`
+	FailureReason string    `json:"failure_reason,omitempty"`
+	IPAddress     string    `json:"ip_address,omitempty"`
+	UserAgent     string    `json:"user_agent,omitempty"`
+	Endpoint      string    `json:"endpoint,omitempty"`
+	Method        string    `json:"method,omitempty"`
+	StatusCode    int       `json:"status_code,omitempty"`
+	PrevHash      string    `json:"prev_hash"`
+	Hash          string    `json:"hash"`
+}
+
+// AuditEvent returns the export form of a sealed audit log
+func (a *AuditLog) AuditEvent() AuditEvent {
+	event := AuditEvent{
+		Chain:      AUDIT_CHAIN_AUDIT_LOG,
+		RecordID:   a.ID,
+		Time:       a.CreatedAt,
+		Category:   AUDIT_EVENT_CATEGORY_AUDIT,
+		Action:     a.Action,
+		Outcome:    AUDIT_EVENT_OUTCOME_SUCCESS,
+		UserID:     a.UserID,
+		AdminID:    a.AdminID,
+		EntityType: a.EntityType,
+		EntityID:   a.EntityID,
+		EntityKey:  a.EntityKey,
+		IPAddress:  a.IPAddress,
+		UserAgent:  a.UserAgent,
+		Endpoint:   a.APIEndpoint,
+		Method:     a.RequestMethod,
+		StatusCode: a.StatusCode,
+		PrevHash:   a.PrevHash,
+		Hash:       a.Hash,
+	}
+	if a.ChainSeq != nil {
+		event.ChainSeq = *a.ChainSeq
+	}
+	if a.StatusCode >= 400 {
+		event.Outcome = AUDIT_EVENT_OUTCOME_FAILURE
+	}
+	if a.Changes != nil {
+		var changes map[string]json.RawMessage
+		if json.Unmarshal(*a.Changes, &changes) == nil {
+			for field := range changes {
+				event.ChangedFields = append(event.ChangedFields, field)
+			}
+			sort.Strings(event.ChangedFields)
+		}
+	}
+	return event
+}
+
+// AuditEvent returns the export form of a sealed login audit
+func (l *LoginAudit) AuditEvent() AuditEvent {
+	event := AuditEvent{
+		Chain:         AUDIT_CHAIN_LOGIN_AUDIT,
+		RecordID:      l.ID,
+		Time:          l.CreatedAt,
+		Category:      AUDIT_EVENT_CATEGORY_LOGIN,
+		Action:        l.LoginType,
+		Outcome:       AUDIT_EVENT_OUTCOME_SUCCESS,
+		UserID:        l.UserID,
+		AdminID:       l.AdminID,
+		Identifier:    l.Identifier,
+		FailureReason: l.FailureReason,
+		IPAddress:     l.IPAddress,
+		UserAgent:     l.UserAgent,
+		PrevHash:      l.PrevHash,
+		Hash:          l.Hash,
+	}
+	if l.ChainSeq != nil {
+		event.ChainSeq = *l.ChainSeq
+	}
+	if l.Status != LOGIN_STATUS_SUCCESS {
+		event.Outcome = AUDIT_EVENT_OUTCOME_FAILURE
+	}
+	return event
+}
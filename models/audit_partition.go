@@ -0,0 +1,232 @@
+package models
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditPartitionMonthsAhead is how many months of audit partitions are created in advance
+const AuditPartitionMonthsAhead = 3
+
+// auditPartitionedTables are partitioned by month on created_at. The table names are the chain names.
+var auditPartitionedTables = []string{AUDIT_CHAIN_AUDIT_LOG, AUDIT_CHAIN_LOGIN_AUDIT}
+
+// AuditPartition is one monthly partition of an audit table
+type AuditPartition struct {
+	Table string    `json:"table"`
+	Name  string    `json:"name"`
+	From  time.Time `json:"from"` // Inclusive
+	To    time.Time `json:"to"`   // Exclusive
+}
+
+// auditPartitionTimeFormat is used in partition names, e.g. audit_logs_p2025_01
+const auditPartitionTimeFormat = "2006_01"
+
+// AuditPartitionName returns the name of the partition holding the month containing t
+func AuditPartitionName(table string, t time.Time) string {
+	return table + "_p" + auditMonthStart(t).Format(auditPartitionTimeFormat)
+}
+
+func auditDefaultPartitionName(table string) string {
+	return table + "_default"
+}
+
+// auditMonthStart returns the first instant of the UTC month containing t
+func auditMonthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func quoteTimestamp(t time.Time) string {
+	return "'" + t.UTC().Format(time.RFC3339) + "'"
+}
+
+// isPartitionedTable reports whether table is a partitioned table in the current schema
+func isPartitionedTable(db *gorm.DB, table string) (bool, error) {
+	var kinds []string
+	err := db.Raw(`SELECT relkind::text FROM pg_class
+		WHERE relname = ? AND relnamespace = current_schema()::regnamespace`, table).Scan(&kinds).Error
+	return len(kinds) > 0 && kinds[0] == "p", err
+}
+
+// PartitionAuditTables converts the audit tables to tables partitioned by month on created_at, if
+// they are not already, and creates the partitions for the coming months. The primary key becomes
+// (id, created_at) and chain positions are unique on (chain_seq, created_at), because a partitioned
+// table's unique constraints must include the partition key.
+func PartitionAuditTables(db *gorm.DB) error {
+	for _, table := range auditPartitionedTables {
+		// Replaced by the unique index on (chain_seq, created_at)
+		if err := db.Exec(fmt.Sprintf("DROP INDEX IF EXISTS %s", quoteIdent("idx_"+table+"_chain_seq"))).Error; err != nil {
+			return err
+		}
+
+		partitioned, err := isPartitionedTable(db, table)
+		if err != nil {
+			return err
+		}
+		if !partitioned {
+			log.Printf("Converting %s to a partitioned table...", table)
+			if err := convertToPartitionedTable(db, table); err != nil {
+				return fmt.Errorf("failed to partition %s: %v", table, err)
+			}
+			log.Printf("✅ %s partitioned by month", table)
+		}
+	}
+	return EnsureAuditPartitions(db, time.Now())
+}
+
+// convertToPartitionedTable copies an audit table into a new partitioned table with one partition
+// per month of existing data, then swaps the tables; everything happens in one transaction
+func convertToPartitionedTable(db *gorm.DB, table string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		staging := table + "_partitioned"
+		statements := []string{
+			fmt.Sprintf("LOCK TABLE %s IN ACCESS EXCLUSIVE MODE", quoteIdent(table)),
+			fmt.Sprintf("UPDATE %s SET created_at = NOW() WHERE created_at IS NULL", quoteIdent(table)),
+			fmt.Sprintf("CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS) PARTITION BY RANGE (created_at)",
+				quoteIdent(staging), quoteIdent(table)),
+			fmt.Sprintf("CREATE TABLE %s PARTITION OF %s DEFAULT",
+				quoteIdent(auditDefaultPartitionName(table)), quoteIdent(staging)),
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+
+		var oldest *time.Time
+		if err := tx.Table(table).Select("MIN(created_at)").Scan(&oldest).Error; err != nil {
+			return err
+		}
+		from := auditMonthStart(time.Now())
+		if oldest != nil && oldest.Before(from) {
+			from = auditMonthStart(*oldest)
+		}
+		for month := from; !month.After(auditMonthStart(time.Now())); month = month.AddDate(0, 1, 0) {
+			if err := tx.Exec(fmt.Sprintf("CREATE TABLE %s PARTITION OF %s FOR VALUES FROM (%s) TO (%s)",
+				quoteIdent(AuditPartitionName(table, month)), quoteIdent(staging),
+				quoteTimestamp(month), quoteTimestamp(month.AddDate(0, 1, 0)))).Error; err != nil {
+				return err
+			}
+		}
+
+		var sequence string
+		if err := tx.Raw("SELECT COALESCE(pg_get_serial_sequence(?, 'id'), '')", table).Scan(&sequence).Error; err != nil {
+			return err
+		}
+
+		statements = []string{
+			fmt.Sprintf("INSERT INTO %s SELECT * FROM %s", quoteIdent(staging), quoteIdent(table)),
+		}
+		// The id sequence belongs to the old table's column and would be dropped with it
+		if sequence != "" {
+			statements = append(statements, fmt.Sprintf("ALTER SEQUENCE %s OWNED BY NONE", sequence))
+		}
+		statements = append(statements,
+			fmt.Sprintf("DROP TABLE %s", quoteIdent(table)),
+			fmt.Sprintf("ALTER TABLE %s RENAME TO %s", quoteIdent(staging), quoteIdent(table)),
+			fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s PRIMARY KEY (id, created_at)",
+				quoteIdent(table), quoteIdent(table+"_pkey")),
+		)
+		if sequence != "" {
+			statements = append(statements, fmt.Sprintf("ALTER SEQUENCE %s OWNED BY %s.id", sequence, quoteIdent(table)))
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+
+		// Recreate the secondary indexes of the models on the new table
+		if table == AUDIT_CHAIN_LOGIN_AUDIT {
+			return tx.AutoMigrate(&LoginAudit{})
+		}
+		return tx.AutoMigrate(&AuditLog{})
+	})
+}
+
+// EnsureAuditPartitions creates the partitions of the audit tables from the month containing now
+// through AuditPartitionMonthsAhead months later
+func EnsureAuditPartitions(db *gorm.DB, now time.Time) error {
+	for _, table := range auditPartitionedTables {
+		for i := 0; i <= AuditPartitionMonthsAhead; i++ {
+			if _, err := EnsureAuditPartition(db, table, auditMonthStart(now).AddDate(0, i, 0)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// EnsureAuditPartition creates the partition of table for the month containing month, moving any
+// rows of that month out of the default partition; returns whether the partition was created
+func EnsureAuditPartition(db *gorm.DB, table string, month time.Time) (bool, error) {
+	name := AuditPartitionName(table, month)
+	from := auditMonthStart(month)
+	to := from.AddDate(0, 1, 0)
+
+	var exists bool
+	if err := db.Raw("SELECT to_regclass(?) IS NOT NULL", name).Scan(&exists).Error; err != nil || exists {
+		return false, err
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		statements := []string{
+			fmt.Sprintf("CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS)", quoteIdent(name), quoteIdent(table)),
+			fmt.Sprintf("WITH moved AS (DELETE FROM %s WHERE created_at >= %s AND created_at < %s RETURNING *) INSERT INTO %s SELECT * FROM moved",
+				quoteIdent(auditDefaultPartitionName(table)), quoteTimestamp(from), quoteTimestamp(to), quoteIdent(name)),
+			fmt.Sprintf("ALTER TABLE %s ATTACH PARTITION %s FOR VALUES FROM (%s) TO (%s)",
+				quoteIdent(table), quoteIdent(name), quoteTimestamp(from), quoteTimestamp(to)),
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return err == nil, err
+}
+
+// ListAuditPartitions returns the monthly partitions of an audit table, oldest first. The default
+// partition, which only holds rows no monthly partition covers, is not listed.
+func ListAuditPartitions(db *gorm.DB, table string) ([]AuditPartition, error) {
+	var names []string
+	if err := db.Raw(`SELECT c.relname FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		JOIN pg_class p ON p.oid = i.inhparent
+		WHERE p.relname = ? AND p.relnamespace = current_schema()::regnamespace
+		ORDER BY c.relname`, table).Scan(&names).Error; err != nil {
+		return nil, err
+	}
+
+	var partitions []AuditPartition
+	for _, name := range names {
+		month, err := time.Parse(auditPartitionTimeFormat, strings.TrimPrefix(name, table+"_p"))
+		if err != nil || !strings.HasPrefix(name, table+"_p") {
+			continue
+		}
+		partitions = append(partitions, AuditPartition{Table: table, Name: name, From: month, To: month.AddDate(0, 1, 0)})
+	}
+	return partitions, nil
+}
+
+// DropAuditPartition detaches and drops a monthly partition of an audit table
+func DropAuditPartition(db *gorm.DB, partition AuditPartition) error {
+	if partition.Name != AuditPartitionName(partition.Table, partition.From) {
+		return fmt.Errorf("%s is not a partition of %s", partition.Name, partition.Table)
+	}
+	if err := db.Exec(fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s",
+		quoteIdent(partition.Table), quoteIdent(partition.Name))).Error; err != nil {
+		return err
+	}
+	return db.Exec(fmt.Sprintf("DROP TABLE %s", quoteIdent(partition.Name))).Error
+}
@@ -6,18 +6,20 @@ import (
 
 // BankAccount represents a bank account belonging to a user
 type BankAccount struct {
-	ID            uint      `json:"id" gorm:"primaryKey"`
-	UserID        uint      `json:"user_id" gorm:"not null;index"`
-	User          User      `json:"user" gorm:"foreignKey:UserID"`
-	AccountNumber string    `json:"account_number" gorm:"not null;uniqueIndex:idx_user_account;size:50"`
-	AccountName   string    `json:"account_name" gorm:"not null;size:100"` // Name as it appears on the account
-	BankName      string    `json:"bank_name" gorm:"size:100"`             // Bank institution name
-	BankCode      string    `json:"bank_code" gorm:"size:10"`              // Bank code (e.g., "014" for BCA)
-	AccountType   string    `json:"account_type" gorm:"size:20"`           // e.g., "saving", "checking", "current"
-	IsActive      bool      `json:"is_active" gorm:"default:true"`
-	IsPrimary     bool      `json:"is_primary" gorm:"default:false"` // Primary account for the user
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID                uint      `json:"id" gorm:"primaryKey"`
+	UserID            uint      `json:"user_id" gorm:"not null;index"`
+	User              User      `json:"user" gorm:"foreignKey:UserID"`
+	AccountNumber     string    `json:"account_number" gorm:"not null;uniqueIndex:idx_user_account;size:50"`
+	AccountName       string    `json:"account_name" gorm:"not null;size:100"` // Name as it appears on the account
+	BankName          string    `json:"bank_name" gorm:"size:100"`             // Bank institution name
+	BankCode          string    `json:"bank_code" gorm:"size:10"`              // Bank code (e.g., "014" for BCA)
+	AccountType       string    `json:"account_type" gorm:"size:20"`           // e.g., "saving", "checking", "current"
+	Currency          string    `json:"currency" gorm:"size:3;default:'IDR'"`  // ISO 4217 code, balances of non-IDR accounts live in CurrencyBalance
+	InterestProductID *uint     `json:"interest_product_id,omitempty"`         // Saving accounts only, default product when empty
+	IsActive          bool      `json:"is_active" gorm:"default:true"`
+	IsPrimary         bool      `json:"is_primary" gorm:"default:false"` // Primary account for the user
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
 }
 
 // BankAccountRequest for creating/updating bank account
@@ -27,6 +29,7 @@ type BankAccountRequest struct {
 	BankName      string `json:"bank_name" binding:"omitempty,max=100"`
 	BankCode      string `json:"bank_code" binding:"omitempty,max=10"`
 	AccountType   string `json:"account_type" binding:"omitempty,max=20"`
+	Currency      string `json:"currency" binding:"omitempty,len=3"` // Defaults to IDR, cannot be changed later
 	IsPrimary     bool   `json:"is_primary"`
 }
 
@@ -38,6 +41,7 @@ type BankAccountResponse struct {
 	BankName      string    `json:"bank_name"`
 	BankCode      string    `json:"bank_code"`
 	AccountType   string    `json:"account_type"`
+	Currency      string    `json:"currency"`
 	IsActive      bool      `json:"is_active"`
 	IsPrimary     bool      `json:"is_primary"`
 	CreatedAt     time.Time `json:"created_at"`
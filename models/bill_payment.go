@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+)
+
+// Bill payment status constants
+const (
+	BILL_PAYMENT_STATUS_PENDING = "pending" // customer debited, biller has not confirmed yet
+	BILL_PAYMENT_STATUS_SUSPECT = "suspect" // biller timed out, outcome unknown until an advice resolves it
+	BILL_PAYMENT_STATUS_SUCCESS = "success" // paid at the biller
+	BILL_PAYMENT_STATUS_FAILED  = "failed"  // rejected, funds reversed to the customer
+)
+
+// BillPayment represents a customer paying a bill through a biller
+type BillPayment struct {
+	ID                  uint       `json:"id" gorm:"primaryKey"`
+	Reference           string     `json:"reference" gorm:"uniqueIndex;size:50;not null"`
+	UserID              uint       `json:"user_id" gorm:"not null;index"`
+	BillerID            uint       `json:"biller_id" gorm:"not null;index"`
+	BillerCode          string     `json:"biller_code" gorm:"size:30;not null"`
+	BillerName          string     `json:"biller_name" gorm:"size:100"`
+	Category            string     `json:"category" gorm:"size:30"`
+	CustomerNumber      string     `json:"customer_number" gorm:"size:50;not null;index"`
+	CustomerName        string     `json:"customer_name" gorm:"size:100"`
+	Period              string     `json:"period,omitempty" gorm:"size:20"`
+	Amount              int64      `json:"amount" gorm:"not null"`
+	AdminFee            int64      `json:"admin_fee" gorm:"default:0"`
+	TotalAmount         int64      `json:"total_amount" gorm:"not null"`
+	Status              string     `json:"status" gorm:"size:20;default:'pending';index"` // "pending", "suspect", "success", "failed"
+	BillerAdapter       string     `json:"biller_adapter" gorm:"size:50"`
+	InquiryReference    string     `json:"inquiry_reference,omitempty" gorm:"size:50"`
+	BillerReference     string     `json:"biller_reference,omitempty" gorm:"size:100"`
+	Token               string     `json:"token,omitempty" gorm:"size:50"` // Prepaid token or voucher serial number
+	FailureReason       string     `json:"failure_reason,omitempty"`
+	AdviceCount         int        `json:"advice_count" gorm:"default:0"`
+	LastAdviceAt        *time.Time `json:"last_advice_at,omitempty"`
+	DebitTransactionID  *uint      `json:"debit_transaction_id,omitempty"`
+	RefundTransactionID *uint      `json:"refund_transaction_id,omitempty"`
+	CompletedAt         *time.Time `json:"completed_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+
+	// Relationship
+	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// BillInquiryRequest for looking up a bill before paying
+type BillInquiryRequest struct {
+	BillerCode     string `json:"biller_code" binding:"required,max=30"`
+	CustomerNumber string `json:"customer_number" binding:"required,min=4,max=50,numeric"`
+	Amount         int64  `json:"amount" binding:"omitempty,min=1"` // Required for prepaid products
+}
+
+// BillPaymentRequest for paying a bill
+type BillPaymentRequest struct {
+	BillerCode     string `json:"biller_code" binding:"required,max=30"`
+	CustomerNumber string `json:"customer_number" binding:"required,min=4,max=50,numeric"`
+	Amount         int64  `json:"amount" binding:"omitempty,min=1"` // Required for prepaid products
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Biller category constants
+const (
+	BILLER_CATEGORY_ELECTRICITY  = "electricity"
+	BILLER_CATEGORY_PHONE_CREDIT = "phone_credit"
+	BILLER_CATEGORY_WATER        = "water"
+	BILLER_CATEGORY_BPJS         = "bpjs"
+)
+
+// Biller product type constants
+const (
+	BILLER_PRODUCT_POSTPAID = "postpaid" // bill amount comes from the biller inquiry
+	BILLER_PRODUCT_PREPAID  = "prepaid"  // customer picks one of the denominations
+)
+
+// Biller is an entry of the bill payment catalog
+type Biller struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	Code          string         `json:"code" gorm:"uniqueIndex;size:30;not null"` // Product code sent to the biller adapter
+	Name          string         `json:"name" gorm:"size:100;not null"`
+	Category      string         `json:"category" gorm:"size:30;not null;index"`  // "electricity", "phone_credit", "water", "bpjs"
+	ProductType   string         `json:"product_type" gorm:"size:10;not null"`    // "postpaid", "prepaid"
+	Denominations string         `json:"denominations,omitempty" gorm:"size:255"` // Prepaid only, comma separated amounts
+	AdminFee      int64          `json:"admin_fee" gorm:"default:0"`              // Charged to the customer on top of the bill
+	IsActive      bool           `json:"is_active" gorm:"default:true"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// CreateBillerRequest for adding a biller to the catalog
+type CreateBillerRequest struct {
+	Code          string `json:"code" binding:"required,min=3,max=30"`
+	Name          string `json:"name" binding:"required,min=3,max=100"`
+	Category      string `json:"category" binding:"required,oneof=electricity phone_credit water bpjs"`
+	ProductType   string `json:"product_type" binding:"required,oneof=postpaid prepaid"`
+	Denominations string `json:"denominations" binding:"omitempty,max=255"`
+	AdminFee      int64  `json:"admin_fee" binding:"omitempty,min=0"`
+}
+
+// UpdateBillerRequest for changing a catalog entry
+type UpdateBillerRequest struct {
+	Name          string `json:"name" binding:"omitempty,min=3,max=100"`
+	Denominations string `json:"denominations" binding:"omitempty,max=255"`
+	AdminFee      *int64 `json:"admin_fee" binding:"omitempty,min=0"`
+	IsActive      *bool  `json:"is_active"`
+}
@@ -0,0 +1,178 @@
+package models
+
+import (
+	"time"
+)
+
+// Card status constants
+const (
+	CARD_STATUS_ACTIVE = "active"
+	CARD_STATUS_FROZEN = "frozen" // temporarily blocked by the cardholder
+	CARD_STATUS_CLOSED = "closed"
+)
+
+// Card authorization status constants
+const (
+	CARD_AUTH_STATUS_APPROVED = "approved" // hold placed, waiting for clearing
+	CARD_AUTH_STATUS_DECLINED = "declined"
+	CARD_AUTH_STATUS_CAPTURED = "captured" // cleared, hold converted to a purchase
+	CARD_AUTH_STATUS_REVERSED = "reversed" // reversed by the network, hold released
+	CARD_AUTH_STATUS_EXPIRED  = "expired"  // not cleared in time, hold released
+)
+
+// ISO 8583 message type indicators handled by the card network endpoint
+const (
+	CARD_MTI_AUTH_REQUEST      = "0100"
+	CARD_MTI_AUTH_RESPONSE     = "0110"
+	CARD_MTI_REVERSAL_REQUEST  = "0400"
+	CARD_MTI_REVERSAL_RESPONSE = "0410"
+)
+
+// ISO 8583 response codes (field 39)
+const (
+	CARD_RC_APPROVED            = "00"
+	CARD_RC_DO_NOT_HONOR        = "05"
+	CARD_RC_INVALID_TRANSACTION = "12"
+	CARD_RC_INVALID_AMOUNT      = "13"
+	CARD_RC_INVALID_CARD        = "14"
+	CARD_RC_ORIGINAL_NOT_FOUND  = "25"
+	CARD_RC_INSUFFICIENT_FUNDS  = "51"
+	CARD_RC_EXPIRED_CARD        = "54"
+	CARD_RC_NOT_PERMITTED       = "57"
+	CARD_RC_EXCEEDS_LIMIT       = "61"
+	CARD_RC_RESTRICTED_CARD     = "62"
+	CARD_RC_CVV_MISMATCH        = "82"
+	CARD_RC_SYSTEM_MALFUNCTION  = "96"
+)
+
+// Card is a virtual debit card drawing on the user's main balance. Only a token, a keyed hash
+// of the PAN and the masked PAN are stored; the full PAN and CVV are shown once at issuance.
+type Card struct {
+	ID                  uint       `json:"id" gorm:"primaryKey"`
+	UserID              uint       `json:"user_id" gorm:"not null;index"`
+	BankAccountID       uint       `json:"bank_account_id" gorm:"not null"`
+	Token               string     `json:"token" gorm:"uniqueIndex;size:40;not null"`
+	PANHash             string     `json:"-" gorm:"uniqueIndex;size:64;not null"`
+	MaskedPAN           string     `json:"masked_pan" gorm:"size:19;not null"`
+	CVVHash             string     `json:"-" gorm:"size:64;not null"`
+	CardholderName      string     `json:"cardholder_name" gorm:"size:26;not null"`
+	ExpiryMonth         int        `json:"expiry_month" gorm:"not null"`
+	ExpiryYear          int        `json:"expiry_year" gorm:"not null"`
+	PerTransactionLimit int64      `json:"per_transaction_limit"`
+	DailyLimit          int64      `json:"daily_limit"`
+	MonthlyLimit        int64      `json:"monthly_limit"`
+	Status              string     `json:"status" gorm:"size:20;default:'active';index"` // "active", "frozen", "closed"
+	FrozenAt            *time.Time `json:"frozen_at,omitempty"`
+	ClosedAt            *time.Time `json:"closed_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// IsExpired reports whether the card is past the last day of its expiry month
+func (c *Card) IsExpired(now time.Time) bool {
+	return now.Year() > c.ExpiryYear || (now.Year() == c.ExpiryYear && int(now.Month()) > c.ExpiryMonth)
+}
+
+// CardAuthorization is an authorization request from the card network and, when approved,
+// the hold it placed on the cardholder's balance
+type CardAuthorization struct {
+	ID                   uint       `json:"id" gorm:"primaryKey"`
+	CardID               *uint      `json:"card_id,omitempty" gorm:"index"` // Nil when the PAN is unknown
+	UserID               *uint      `json:"user_id,omitempty" gorm:"index"`
+	RRN                  string     `json:"rrn" gorm:"uniqueIndex;size:12;not null"` // Retrieval reference number (field 37)
+	STAN                 string     `json:"stan" gorm:"size:6"`                      // System trace audit number (field 11)
+	AuthCode             string     `json:"auth_code,omitempty" gorm:"size:6;index"` // Approval code (field 38)
+	ResponseCode         string     `json:"response_code" gorm:"size:2;not null"`    // Field 39
+	MaskedPAN            string     `json:"masked_pan" gorm:"size:19"`
+	ProcessingCode       string     `json:"processing_code" gorm:"size:6"` // Field 3
+	Amount               int64      `json:"amount" gorm:"not null"`        // Field 4
+	CapturedAmount       int64      `json:"captured_amount"`
+	Currency             string     `json:"currency" gorm:"size:3"`
+	MerchantCategoryCode string     `json:"merchant_category_code" gorm:"size:4"` // Field 18
+	TerminalID           string     `json:"terminal_id" gorm:"size:8"`            // Field 41
+	MerchantID           string     `json:"merchant_id" gorm:"size:15"`           // Field 42
+	MerchantName         string     `json:"merchant_name" gorm:"size:40"`         // Field 43
+	POSEntryMode         string     `json:"pos_entry_mode" gorm:"size:3"`         // Field 22
+	Status               string     `json:"status" gorm:"size:20;not null;index"` // "approved", "declined", "captured", "reversed", "expired"
+	DeclineReason        string     `json:"decline_reason,omitempty"`
+	HoldTxnID            *uint      `json:"hold_transaction_id,omitempty"`
+	ReleaseTxnID         *uint      `json:"release_transaction_id,omitempty"` // Credit returning an uncaptured hold
+	HoldExpiresAt        *time.Time `json:"hold_expires_at,omitempty" gorm:"index"`
+	CapturedAt           *time.Time `json:"captured_at,omitempty"`
+	ReleasedAt           *time.Time `json:"released_at,omitempty"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+}
+
+// IssueCardRequest for issuing a virtual card
+type IssueCardRequest struct {
+	BankAccountID uint `json:"bank_account_id" binding:"required"`
+}
+
+// IssuedCardResponse is returned once at issuance and is the only time the PAN and CVV are shown
+type IssuedCardResponse struct {
+	Card
+	PAN string `json:"pan"`
+	CVV string `json:"cvv"`
+}
+
+// CardLimitsRequest for changing a card's spending limits
+type CardLimitsRequest struct {
+	PerTransactionLimit int64 `json:"per_transaction_limit" binding:"required,min=1"`
+	DailyLimit          int64 `json:"daily_limit" binding:"required,gtefield=PerTransactionLimit"`
+	MonthlyLimit        int64 `json:"monthly_limit" binding:"required,gtefield=DailyLimit"`
+}
+
+// CardNetworkMessage is an ISO 8583-like authorization or reversal request, with JSON fields
+// named after the ISO data elements they carry
+type CardNetworkMessage struct {
+	MTI                  string `json:"mti" binding:"required,oneof=0100 0400"`
+	PAN                  string `json:"pan" binding:"required,min=13,max=19,numeric"`      // Field 2
+	ProcessingCode       string `json:"processing_code" binding:"required,len=6,numeric"`  // Field 3
+	Amount               int64  `json:"amount" binding:"required"`                         // Field 4, minor units
+	TransmissionDateTime string `json:"transmission_date_time" binding:"omitempty,len=10"` // Field 7, MMDDhhmmss
+	STAN                 string `json:"stan" binding:"required,len=6,numeric"`             // Field 11
+	Expiry               string `json:"expiry" binding:"required,len=4,numeric"`           // Field 14, YYMM
+	MerchantCategoryCode string `json:"mcc" binding:"omitempty,len=4,numeric"`             // Field 18
+	POSEntryMode         string `json:"pos_entry_mode" binding:"omitempty,max=3"`          // Field 22
+	RRN                  string `json:"rrn" binding:"required,len=12"`                     // Field 37
+	TerminalID           string `json:"terminal_id" binding:"omitempty,max=8"`             // Field 41
+	MerchantID           string `json:"merchant_id" binding:"omitempty,max=15"`            // Field 42
+	MerchantName         string `json:"merchant_name" binding:"omitempty,max=40"`          // Field 43
+	Currency             string `json:"currency" binding:"required,len=3"`                 // Field 49, ISO 4217 alpha
+	CVV                  string `json:"cvv" binding:"omitempty,len=3,numeric"`             // CVV2 for card-not-present
+}
+
+// CardNetworkResponse is the 0110/0410 answer to the network
+type CardNetworkResponse struct {
+	MTI          string `json:"mti"`
+	STAN         string `json:"stan"`
+	RRN          string `json:"rrn"`
+	AuthCode     string `json:"auth_code,omitempty"`
+	ResponseCode string `json:"response_code"`
+}
+
+// CardClearingRecord is one presentment in a clearing batch
+type CardClearingRecord struct {
+	RRN      string `json:"rrn" binding:"required,len=12"`
+	AuthCode string `json:"auth_code" binding:"required,len=6"`
+	Amount   int64  `json:"amount" binding:"required,min=1"` // Final amount, at most the authorized amount
+}
+
+// CardClearingRequest is a clearing batch from the card network
+type CardClearingRequest struct {
+	BatchReference string               `json:"batch_reference" binding:"required,max=50"`
+	Records        []CardClearingRecord `json:"records" binding:"required,min=1,dive"`
+}
+
+// CardClearingResult is the outcome of one clearing record
+type CardClearingResult struct {
+	RRN    string `json:"rrn"`
+	Status string `json:"status"` // "captured" or "rejected"
+	Reason string `json:"reason,omitempty"`
+}
+
+// RunCardHoldExpiryRequest for triggering release of stale holds
+type RunCardHoldExpiryRequest struct {
+	Date string `json:"date" binding:"omitempty,datetime=2006-01-02"` // Defaults to today
+}
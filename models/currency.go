@@ -0,0 +1,101 @@
+package models
+
+import (
+	"time"
+)
+
+// BASE_CURRENCY is the currency of User.Balance and of every amount that does not carry a currency
+const BASE_CURRENCY = "IDR"
+
+// FX rate status constants
+const (
+	FX_RATE_STATUS_PENDING    = "pending"    // proposed by a maker, waiting for a checker
+	FX_RATE_STATUS_ACTIVE     = "active"     // current rate for the currency
+	FX_RATE_STATUS_SUPERSEDED = "superseded" // replaced by a newer approved rate
+	FX_RATE_STATUS_REJECTED   = "rejected"   // rejected by a checker
+)
+
+// Currency defines a supported ISO 4217 currency and its minor-unit precision
+type Currency struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	Code       string    `json:"code" gorm:"uniqueIndex;size:3;not null"` // ISO 4217 alpha code, e.g. "USD"
+	Name       string    `json:"name" gorm:"size:50;not null"`
+	MinorUnits int       `json:"minor_units" gorm:"not null"` // Decimal places of the minor unit, amounts are stored in minor units
+	IsActive   bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// CurrencyBalance holds a user's balance in a currency other than the base currency
+type CurrencyBalance struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_user_currency"`
+	Currency  string    `json:"currency" gorm:"size:3;not null;uniqueIndex:idx_user_currency"`
+	Balance   int64     `json:"balance" gorm:"default:0"` // In minor units of the currency
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// FXRate is a rate against the base currency, maintained by admins under maker-checker
+type FXRate struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	Currency        string     `json:"currency" gorm:"size:3;not null;index"`
+	BuyRate         int64      `json:"buy_rate" gorm:"not null"`                      // Bank buys the currency, base units per 1 unit scaled by 1e6
+	SellRate        int64      `json:"sell_rate" gorm:"not null"`                     // Bank sells the currency, base units per 1 unit scaled by 1e6
+	Status          string     `json:"status" gorm:"size:20;default:'pending';index"` // "pending", "active", "superseded", "rejected"
+	MakerAdminID    uint       `json:"maker_admin_id" gorm:"not null"`
+	CheckerAdminID  *uint      `json:"checker_admin_id,omitempty"`
+	Comments        string     `json:"comments,omitempty"`
+	RejectionReason string     `json:"rejection_reason,omitempty"`
+	EffectiveAt     *time.Time `json:"effective_at,omitempty"`
+	ReviewedAt      *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// FXQuote is a conversion rate given to a customer, honoured by a transfer until it expires.
+// A quote can be used once.
+type FXQuote struct {
+	ID              uint       `json:"quote_id" gorm:"primaryKey"`
+	UserID          uint       `json:"user_id" gorm:"not null;index"`
+	FromCurrency    string     `json:"from_currency" gorm:"size:3;not null"`
+	ToCurrency      string     `json:"to_currency" gorm:"size:3;not null"`
+	Amount          int64      `json:"amount" gorm:"not null"`           // In minor units of FromCurrency
+	ConvertedAmount int64      `json:"converted_amount" gorm:"not null"` // In minor units of ToCurrency
+	FromRate        int64      `json:"-"`                                // Buy rate of FromCurrency scaled by 1e6 (0 for the base currency)
+	ToRate          int64      `json:"-"`                                // Sell rate of ToCurrency scaled by 1e6 (0 for the base currency)
+	ExchangeRate    string     `json:"exchange_rate" gorm:"size:30;not null"`
+	ExpiresAt       time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt          *time.Time `json:"used_at,omitempty"`
+	TransactionID   *uint      `json:"transaction_id,omitempty"` // Transfer that used the quote
+	CreatedAt       time.Time  `json:"quoted_at"`
+}
+
+// FXRateResponse formats the scaled rates as decimals
+type FXRateResponse struct {
+	FXRate
+	BuyRateDecimal  string `json:"buy_rate_decimal"`
+	SellRateDecimal string `json:"sell_rate_decimal"`
+}
+
+// CreateCurrencyRequest for adding a supported currency
+type CreateCurrencyRequest struct {
+	Code       string `json:"code" binding:"required,len=3,alpha"`
+	Name       string `json:"name" binding:"required,max=50"`
+	MinorUnits int    `json:"minor_units" binding:"min=0,max=4"`
+}
+
+// ProposeFXRateRequest for a maker proposing a new rate (decimal strings, e.g. "16250.50")
+type ProposeFXRateRequest struct {
+	Currency string `json:"currency" binding:"required,len=3"`
+	BuyRate  string `json:"buy_rate" binding:"required"`
+	SellRate string `json:"sell_rate" binding:"required"`
+	Comments string `json:"comments" binding:"max=255"`
+}
+
+// FXQuoteRequest for previewing a conversion
+type FXQuoteRequest struct {
+	FromCurrency string `json:"from_currency" binding:"required,len=3"`
+	ToCurrency   string `json:"to_currency" binding:"required,len=3"`
+	Amount       int64  `json:"amount" binding:"required,min=1"` // In minor units of FromCurrency
+}
@@ -97,6 +97,7 @@ type DeviceInfo struct {
 	DeviceType DeviceType `json:"device_type"`
 	DeviceID   string     `json:"device_id"`
 	DeviceName string     `json:"device_name"`
+	PublicKey  string     `json:"public_key,omitempty"` // Optional signing key registered when the device is bound
 }
 
 // MultiPlatformLoginResponse for successful authentication
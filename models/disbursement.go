@@ -0,0 +1,169 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Disbursement batch status constants
+const (
+	DISBURSEMENT_STATUS_INVALID               = "invalid"               // no valid rows, never submitted for approval
+	DISBURSEMENT_STATUS_PENDING               = "pending"               // waiting for checker approval
+	DISBURSEMENT_STATUS_REJECTED              = "rejected"              // rejected by checker
+	DISBURSEMENT_STATUS_EXPIRED               = "expired"               // not reviewed in time
+	DISBURSEMENT_STATUS_PROCESSING            = "processing"            // approved, being executed
+	DISBURSEMENT_STATUS_COMPLETED             = "completed"             // all valid rows paid out
+	DISBURSEMENT_STATUS_COMPLETED_WITH_ERRORS = "completed_with_errors" // some rows failed during execution
+)
+
+// Disbursement item status constants
+const (
+	DISBURSEMENT_ITEM_VALID   = "valid"   // passed validation, waiting for execution
+	DISBURSEMENT_ITEM_INVALID = "invalid" // failed validation, will not be executed
+	DISBURSEMENT_ITEM_SUCCESS = "success" // credited to the recipient
+	DISBURSEMENT_ITEM_FAILED  = "failed"  // execution failed
+	DISBURSEMENT_ITEM_SKIPPED = "skipped" // batch rejected or expired before execution
+)
+
+// Disbursement batch source formats
+const (
+	DISBURSEMENT_SOURCE_CSV     = "csv"      // uploaded CSV file
+	DISBURSEMENT_SOURCE_PAIN001 = "pain.001" // ISO 20022 customer credit transfer initiation
+)
+
+// DisbursementBatch represents an uploaded bulk disbursement file (checker-maker approved)
+type DisbursementBatch struct {
+	ID               uint           `json:"id" gorm:"primaryKey"`
+	BatchRef         string         `json:"batch_ref" gorm:"uniqueIndex;size:50;not null"`
+	FileName         string         `json:"file_name" gorm:"size:255"`
+	Description      string         `json:"description"`
+	SourceFormat     string         `json:"source_format" gorm:"size:20;default:'csv'"`       // "csv", "pain.001"
+	SourceMessageID  string         `json:"source_message_id,omitempty" gorm:"size:35;index"` // ISO 20022 MsgId of the imported pain.001
+	MakerAdminID     uint           `json:"maker_admin_id" gorm:"not null;index"`             // Admin who uploaded the file (maker)
+	CheckerAdminID   *uint          `json:"checker_admin_id,omitempty" gorm:"index"`          // Admin who approved/rejected (checker)
+	Status           string         `json:"status" gorm:"size:30;default:'pending';index"`
+	TotalRows        int            `json:"total_rows"`
+	ValidRows        int            `json:"valid_rows"`
+	InvalidRows      int            `json:"invalid_rows"`
+	TotalAmount      int64          `json:"total_amount"` // Sum of valid rows
+	SuccessCount     int            `json:"success_count"`
+	FailedCount      int            `json:"failed_count"`
+	SuccessAmount    int64          `json:"success_amount"`
+	ApprovalComments string         `json:"approval_comments"`
+	RejectionReason  string         `json:"rejection_reason"`
+	ExpiresAt        *time.Time     `json:"expires_at"`
+	ApprovedAt       *time.Time     `json:"approved_at"`
+	RejectedAt       *time.Time     `json:"rejected_at"`
+	StartedAt        *time.Time     `json:"started_at"`
+	CompletedAt      *time.Time     `json:"completed_at"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationships
+	MakerAdmin   Admin              `json:"maker_admin,omitempty" gorm:"foreignKey:MakerAdminID"`
+	CheckerAdmin *Admin             `json:"checker_admin,omitempty" gorm:"foreignKey:CheckerAdminID"`
+	Items        []DisbursementItem `json:"items,omitempty" gorm:"foreignKey:BatchID"`
+}
+
+// DisbursementItem represents a single row of a disbursement file
+type DisbursementItem struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	BatchID       uint       `json:"batch_id" gorm:"not null;index"`
+	RowNumber     int        `json:"row_number" gorm:"not null"`
+	EndToEndID    string     `json:"end_to_end_id,omitempty" gorm:"size:35"` // ISO 20022 EndToEndId (pain.001 imports)
+	AccountNumber string     `json:"account_number" gorm:"size:50"`
+	AccountName   string     `json:"account_name" gorm:"size:100"`
+	UserID        *uint      `json:"user_id,omitempty" gorm:"index"`
+	Amount        int64      `json:"amount"`
+	Description   string     `json:"description"`
+	Status        string     `json:"status" gorm:"size:20;index"` // "valid", "invalid", "success", "failed", "skipped"
+	FailureReason string     `json:"failure_reason,omitempty"`
+	TransactionID *uint      `json:"transaction_id,omitempty"`
+	ProcessedAt   *time.Time `json:"processed_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// DisbursementReviewRequest for approving or rejecting a batch
+type DisbursementReviewRequest struct {
+	Action          string `json:"action" binding:"required,oneof=approve reject"`
+	Comments        string `json:"comments"`
+	RejectionReason string `json:"rejection_reason"` // Required if action is reject
+}
+
+// DisbursementFundingRequest for recording funds received to pay out bulk disbursements
+type DisbursementFundingRequest struct {
+	Amount      int64  `json:"amount" binding:"required,min=1"`
+	Reference   string `json:"reference" binding:"required,max=50"` // Reference of the incoming funds, e.g. the corporate client's transfer
+	Description string `json:"description" binding:"max=255"`
+}
+
+// DisbursementBatchResponse is the summary view of a batch
+type DisbursementBatchResponse struct {
+	ID               uint       `json:"id"`
+	BatchRef         string     `json:"batch_ref"`
+	FileName         string     `json:"file_name"`
+	Description      string     `json:"description"`
+	SourceFormat     string     `json:"source_format"`
+	SourceMessageID  string     `json:"source_message_id,omitempty"`
+	MakerAdminID     uint       `json:"maker_admin_id"`
+	MakerAdminName   string     `json:"maker_admin_name"`
+	CheckerAdminID   *uint      `json:"checker_admin_id,omitempty"`
+	CheckerAdminName *string    `json:"checker_admin_name,omitempty"`
+	Status           string     `json:"status"`
+	TotalRows        int        `json:"total_rows"`
+	ValidRows        int        `json:"valid_rows"`
+	InvalidRows      int        `json:"invalid_rows"`
+	TotalAmount      int64      `json:"total_amount"`
+	SuccessCount     int        `json:"success_count"`
+	FailedCount      int        `json:"failed_count"`
+	SuccessAmount    int64      `json:"success_amount"`
+	ApprovalComments string     `json:"approval_comments,omitempty"`
+	RejectionReason  string     `json:"rejection_reason,omitempty"`
+	ExpiresAt        *time.Time `json:"expires_at"`
+	ApprovedAt       *time.Time `json:"approved_at,omitempty"`
+	RejectedAt       *time.Time `json:"rejected_at,omitempty"`
+	StartedAt        *time.Time `json:"started_at,omitempty"`
+	CompletedAt      *time.Time `json:"completed_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// ToResponse converts a batch to its summary response
+func (b *DisbursementBatch) ToResponse() DisbursementBatchResponse {
+	response := DisbursementBatchResponse{
+		ID:               b.ID,
+		BatchRef:         b.BatchRef,
+		FileName:         b.FileName,
+		Description:      b.Description,
+		SourceFormat:     b.SourceFormat,
+		SourceMessageID:  b.SourceMessageID,
+		MakerAdminID:     b.MakerAdminID,
+		MakerAdminName:   b.MakerAdmin.Name,
+		Status:           b.Status,
+		TotalRows:        b.TotalRows,
+		ValidRows:        b.ValidRows,
+		InvalidRows:      b.InvalidRows,
+		TotalAmount:      b.TotalAmount,
+		SuccessCount:     b.SuccessCount,
+		FailedCount:      b.FailedCount,
+		SuccessAmount:    b.SuccessAmount,
+		ApprovalComments: b.ApprovalComments,
+		RejectionReason:  b.RejectionReason,
+		ExpiresAt:        b.ExpiresAt,
+		ApprovedAt:       b.ApprovedAt,
+		RejectedAt:       b.RejectedAt,
+		StartedAt:        b.StartedAt,
+		CompletedAt:      b.CompletedAt,
+		CreatedAt:        b.CreatedAt,
+	}
+
+	if b.CheckerAdmin != nil {
+		checkerName := b.CheckerAdmin.Name
+		response.CheckerAdminID = b.CheckerAdminID
+		response.CheckerAdminName = &checkerName
+	}
+
+	return response
+}
@@ -0,0 +1,164 @@
+package models
+
+import (
+	"time"
+)
+
+// Dispute status constants
+const (
+	DISPUTE_STATUS_OPENED             = "opened"             // filed by the customer, not yet picked up
+	DISPUTE_STATUS_INVESTIGATING      = "investigating"      // assigned to an admin
+	DISPUTE_STATUS_PROVISIONAL_CREDIT = "provisional_credit" // customer credited while the investigation continues
+	DISPUTE_STATUS_RESOLVED           = "resolved"           // decided in the customer's favour
+	DISPUTE_STATUS_REJECTED           = "rejected"           // decided against the customer
+)
+
+// Dispute resolution constants
+const (
+	DISPUTE_RESOLUTION_REVERSE = "reverse" // the disputed transaction is reversed
+	DISPUTE_RESOLUTION_ADJUST  = "adjust"  // the customer is credited an adjustment by the bank
+	DISPUTE_RESOLUTION_REJECT  = "reject"  // no refund; any provisional credit is taken back
+)
+
+// disputeTransitions lists the statuses each dispute status may move to
+var disputeTransitions = map[string][]string{
+	DISPUTE_STATUS_OPENED:             {DISPUTE_STATUS_INVESTIGATING, DISPUTE_STATUS_REJECTED},
+	DISPUTE_STATUS_INVESTIGATING:      {DISPUTE_STATUS_PROVISIONAL_CREDIT, DISPUTE_STATUS_RESOLVED, DISPUTE_STATUS_REJECTED},
+	DISPUTE_STATUS_PROVISIONAL_CREDIT: {DISPUTE_STATUS_RESOLVED, DISPUTE_STATUS_REJECTED},
+}
+
+// Dispute is a customer's claim that a transaction went wrong
+type Dispute struct {
+	ID                      uint       `json:"id" gorm:"primaryKey"`
+	CaseNumber              string     `json:"case_number" gorm:"uniqueIndex;size:30;not null"`
+	UserID                  uint       `json:"user_id" gorm:"not null;index"`
+	TransactionID           uint       `json:"transaction_id" gorm:"not null;index"`
+	Reason                  string     `json:"reason" gorm:"size:30;not null"` // "unauthorized", "wrong_amount", "wrong_recipient", "not_received", "duplicate", "other"
+	Description             string     `json:"description" gorm:"type:text"`
+	DisputedAmount          int64      `json:"disputed_amount" gorm:"not null"`
+	Status                  string     `json:"status" gorm:"size:20;not null;index"`
+	AssignedAdminID         *uint      `json:"assigned_admin_id,omitempty" gorm:"index"`
+	AssignedAt              *time.Time `json:"assigned_at,omitempty"`
+	ResponseDueAt           time.Time  `json:"response_due_at"`                // SLA for picking the case up
+	ResolutionDueAt         time.Time  `json:"resolution_due_at" gorm:"index"` // SLA for a final decision
+	ProvisionalCreditAmount int64      `json:"provisional_credit_amount"`      // Outstanding provisional credit
+	ProvisionalCreditTxnID  *uint      `json:"provisional_credit_transaction_id,omitempty"`
+	Resolution              string     `json:"resolution,omitempty" gorm:"size:20"` // "reverse", "adjust", "reject"
+	ResolutionAmount        int64      `json:"resolution_amount"`                   // Final amount returned to the customer
+	ResolutionNotes         string     `json:"resolution_notes,omitempty" gorm:"type:text"`
+	ResolutionTxnID         *uint      `json:"resolution_transaction_id,omitempty"`
+	ResolvedByAdminID       *uint      `json:"resolved_by_admin_id,omitempty"`
+	ResolvedAt              *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt               time.Time  `json:"created_at"`
+	UpdatedAt               time.Time  `json:"updated_at"`
+
+	// Relationships
+	Transaction   Transaction            `json:"transaction,omitempty" gorm:"foreignKey:TransactionID"`
+	Attachments   []DisputeAttachment    `json:"attachments,omitempty" gorm:"foreignKey:DisputeID"`
+	Notes         []DisputeNote          `json:"notes,omitempty" gorm:"foreignKey:DisputeID"`
+	StatusHistory []DisputeStatusHistory `json:"status_history,omitempty" gorm:"foreignKey:DisputeID"`
+}
+
+// CanTransitionTo reports whether the dispute may move to status
+func (d *Dispute) CanTransitionTo(status string) bool {
+	for _, allowed := range disputeTransitions[d.Status] {
+		if allowed == status {
+			return true
+		}
+	}
+	return false
+}
+
+// IsClosed reports whether the dispute has a final decision
+func (d *Dispute) IsClosed() bool {
+	return d.Status == DISPUTE_STATUS_RESOLVED || d.Status == DISPUTE_STATUS_REJECTED
+}
+
+// SLABreached reports whether the response or resolution deadline has passed without action
+func (d *Dispute) SLABreached(now time.Time) bool {
+	if d.Status == DISPUTE_STATUS_OPENED && now.After(d.ResponseDueAt) {
+		return true
+	}
+	if d.IsClosed() {
+		return d.ResolvedAt != nil && d.ResolvedAt.After(d.ResolutionDueAt)
+	}
+	return now.After(d.ResolutionDueAt)
+}
+
+// DisputeAttachment is supporting evidence for a dispute, stored as a file URL
+type DisputeAttachment struct {
+	ID                uint      `json:"id" gorm:"primaryKey"`
+	DisputeID         uint      `json:"dispute_id" gorm:"not null;index"`
+	URL               string    `json:"url" gorm:"size:500;not null"`
+	FileName          string    `json:"file_name" gorm:"size:255"`
+	UploadedByUserID  *uint     `json:"uploaded_by_user_id,omitempty"`
+	UploadedByAdminID *uint     `json:"uploaded_by_admin_id,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// DisputeNote is a comment on a dispute. Internal notes are only visible to admins.
+type DisputeNote struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	DisputeID  uint      `json:"dispute_id" gorm:"not null;index"`
+	UserID     *uint     `json:"user_id,omitempty"`
+	AdminID    *uint     `json:"admin_id,omitempty"`
+	Note       string    `json:"note" gorm:"type:text;not null"`
+	IsInternal bool      `json:"is_internal" gorm:"default:false"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// DisputeStatusHistory records every status change of a dispute
+type DisputeStatusHistory struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	DisputeID  uint      `json:"dispute_id" gorm:"not null;index"`
+	FromStatus string    `json:"from_status" gorm:"size:20"`
+	ToStatus   string    `json:"to_status" gorm:"size:20;not null"`
+	AdminID    *uint     `json:"admin_id,omitempty"`
+	Note       string    `json:"note,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// DisputeAttachmentRequest references an uploaded evidence file
+type DisputeAttachmentRequest struct {
+	URL      string `json:"url" binding:"required,url,max=500"`
+	FileName string `json:"file_name" binding:"max=255"`
+}
+
+// CreateDisputeRequest for filing a dispute
+type CreateDisputeRequest struct {
+	TransactionID uint                       `json:"transaction_id" binding:"required"`
+	Reason        string                     `json:"reason" binding:"required,oneof=unauthorized wrong_amount wrong_recipient not_received duplicate other"`
+	Description   string                     `json:"description" binding:"required,min=10,max=2000"`
+	Amount        int64                      `json:"amount" binding:"omitempty,min=1"` // Defaults to the transaction amount
+	Attachments   []DisputeAttachmentRequest `json:"attachments" binding:"omitempty,max=5,dive"`
+}
+
+// AddDisputeAttachmentsRequest for adding evidence to an open dispute
+type AddDisputeAttachmentsRequest struct {
+	Attachments []DisputeAttachmentRequest `json:"attachments" binding:"required,min=1,max=5,dive"`
+}
+
+// DisputeNoteRequest for commenting on a dispute
+type DisputeNoteRequest struct {
+	Note       string `json:"note" binding:"required,min=1,max=2000"`
+	IsInternal bool   `json:"is_internal"` // Admin only
+}
+
+// AssignDisputeRequest for assigning a dispute to an admin
+type AssignDisputeRequest struct {
+	AdminID uint   `json:"admin_id" binding:"required"`
+	Note    string `json:"note" binding:"max=500"`
+}
+
+// ProvisionalCreditRequest for crediting the customer while a dispute is investigated
+type ProvisionalCreditRequest struct {
+	Amount int64  `json:"amount" binding:"omitempty,min=1"` // Defaults to the disputed amount
+	Note   string `json:"note" binding:"required,min=5,max=500"`
+}
+
+// ResolveDisputeRequest for the final decision on a dispute
+type ResolveDisputeRequest struct {
+	Action string `json:"action" binding:"required,oneof=reverse adjust reject"`
+	Amount int64  `json:"amount" binding:"required_if=Action adjust,omitempty,min=1"` // Adjustment amount
+	Notes  string `json:"notes" binding:"required,min=10,max=2000"`
+}
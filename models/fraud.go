@@ -0,0 +1,143 @@
+package models
+
+import (
+	"time"
+)
+
+// Fraud decision constants, ordered from least to most severe
+const (
+	FRAUD_DECISION_ALLOW     = "allow"     // transaction proceeds
+	FRAUD_DECISION_CHALLENGE = "challenge" // customer must confirm with their PIN and resubmit
+	FRAUD_DECISION_HOLD      = "hold"      // transaction waits for an admin review before it may be resubmitted
+	FRAUD_DECISION_BLOCK     = "block"     // transaction is refused
+)
+
+// Fraud rule type constants
+const (
+	FRAUD_RULE_VELOCITY          = "velocity"          // more than max_count transactions within window_minutes
+	FRAUD_RULE_AMOUNT_ANOMALY    = "amount_anomaly"    // amount above multiplier_percent of the user's average
+	FRAUD_RULE_NEW_DEVICE_AMOUNT = "new_device_amount" // large amount from a device first seen within new_device_hours
+	FRAUD_RULE_NEW_BENEFICIARY   = "new_beneficiary"   // first transfer to the beneficiary
+	FRAUD_RULE_NIGHT_TIME        = "night_time"        // activity between start_hour and end_hour local time
+)
+
+// Transaction types evaluated by the fraud rules
+const (
+	FRAUD_CHECK_TYPE_TRANSFER = "transfer"
+	FRAUD_CHECK_TYPE_WITHDRAW = "withdraw"
+)
+
+// Fraud decision review status constants
+const (
+	FRAUD_REVIEW_STATUS_NONE      = "none"      // allowed, nothing to review
+	FRAUD_REVIEW_STATUS_PENDING   = "pending"   // waiting for the customer (challenge) or an admin (hold, block)
+	FRAUD_REVIEW_STATUS_FAILED    = "failed"    // challenge answered with a wrong PIN too many times
+	FRAUD_REVIEW_STATUS_APPROVED  = "approved"  // cleared by an admin, may be resubmitted
+	FRAUD_REVIEW_STATUS_REJECTED  = "rejected"  // confirmed as fraud by an admin
+	FRAUD_REVIEW_STATUS_COMPLETED = "completed" // resubmitted and executed
+)
+
+// fraudDecisionSeverity ranks decisions so the most severe rule hit wins
+var fraudDecisionSeverity = map[string]int{
+	FRAUD_DECISION_ALLOW:     0,
+	FRAUD_DECISION_CHALLENGE: 1,
+	FRAUD_DECISION_HOLD:      2,
+	FRAUD_DECISION_BLOCK:     3,
+}
+
+// MoreSevereFraudDecision returns whichever of two decisions is more severe
+func MoreSevereFraudDecision(a, b string) string {
+	if fraudDecisionSeverity[b] > fraudDecisionSeverity[a] {
+		return b
+	}
+	return a
+}
+
+// FraudRuleParameters are the declarative settings of a fraud rule. Only the fields used by the
+// rule type are set.
+type FraudRuleParameters struct {
+	MinAmount         int64  `json:"min_amount,omitempty"`         // All types: smaller amounts never hit
+	MaxCount          int    `json:"max_count,omitempty"`          // velocity
+	WindowMinutes     int    `json:"window_minutes,omitempty"`     // velocity
+	MultiplierPercent int64  `json:"multiplier_percent,omitempty"` // amount_anomaly: 300 = three times the average
+	LookbackDays      int    `json:"lookback_days,omitempty"`      // amount_anomaly
+	MinHistory        int    `json:"min_history,omitempty"`        // amount_anomaly: transactions needed before the rule applies
+	NewDeviceHours    int    `json:"new_device_hours,omitempty"`   // new_device_amount
+	StartHour         int    `json:"start_hour,omitempty"`         // night_time, inclusive
+	EndHour           int    `json:"end_hour,omitempty"`           // night_time, exclusive; may wrap past midnight
+	Timezone          string `json:"timezone,omitempty"`           // night_time, defaults to Asia/Jakarta
+}
+
+// FraudRule is an admin-editable rule evaluated before transfers and withdrawals commit
+type FraudRule struct {
+	ID               uint                `json:"id" gorm:"primaryKey"`
+	Code             string              `json:"code" gorm:"uniqueIndex;size:50;not null"`
+	Name             string              `json:"name" gorm:"size:100;not null"`
+	Description      string              `json:"description" gorm:"type:text"`
+	RuleType         string              `json:"rule_type" gorm:"size:30;not null"` // "velocity", "amount_anomaly", "new_device_amount", "new_beneficiary", "night_time"
+	TransactionTypes string              `json:"transaction_types" gorm:"size:100"` // Comma separated "transfer", "withdraw"; empty for all
+	Parameters       FraudRuleParameters `json:"parameters" gorm:"serializer:json;type:text"`
+	Action           string              `json:"action" gorm:"size:20;not null"` // "allow", "challenge", "hold", "block"
+	IsActive         bool                `json:"is_active" gorm:"default:true;index"`
+	CreatedByAdminID *uint               `json:"created_by_admin_id,omitempty"`
+	UpdatedByAdminID *uint               `json:"updated_by_admin_id,omitempty"`
+	CreatedAt        time.Time           `json:"created_at"`
+	UpdatedAt        time.Time           `json:"updated_at"`
+}
+
+// FraudDecision is the stored outcome of evaluating the fraud rules for one transaction attempt
+type FraudDecision struct {
+	ID                 uint       `json:"id" gorm:"primaryKey"`
+	UserID             uint       `json:"user_id" gorm:"not null;index"`
+	TransactionType    string     `json:"transaction_type" gorm:"size:20;not null"` // "transfer", "withdraw"
+	Amount             int64      `json:"amount" gorm:"not null"`
+	Currency           string     `json:"currency" gorm:"size:3"`
+	BaseAmount         int64      `json:"base_amount"` // Amount in the base currency, used by the rules
+	BeneficiaryUserID  *uint      `json:"beneficiary_user_id,omitempty" gorm:"index"`
+	BeneficiaryAccount string     `json:"beneficiary_account,omitempty" gorm:"size:50"`
+	DeviceID           string     `json:"device_id,omitempty" gorm:"size:255"`
+	IPAddress          string     `json:"ip_address,omitempty" gorm:"size:45"`
+	Decision           string     `json:"decision" gorm:"size:20;not null;index"`
+	ReviewStatus       string     `json:"review_status" gorm:"size:20;not null;index"` // "none", "pending", "failed", "approved", "rejected", "completed"
+	ExpiresAt          *time.Time `json:"expires_at,omitempty"`                        // Deadline for answering a challenge or resubmitting after approval
+	ChallengeAttempts  int        `json:"challenge_attempts" gorm:"default:0"`         // Wrong PINs given for a challenge
+	TransactionID      *uint      `json:"transaction_id,omitempty" gorm:"index"`       // Transaction executed under this decision
+	ReviewedByAdminID  *uint      `json:"reviewed_by_admin_id,omitempty"`
+	ReviewNotes        string     `json:"review_notes,omitempty" gorm:"type:text"`
+	ReviewedAt         *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+
+	// Relationships
+	RuleHits []FraudRuleHit `json:"rule_hits,omitempty" gorm:"foreignKey:DecisionID"`
+}
+
+// FraudRuleHit records one rule that matched a transaction attempt
+type FraudRuleHit struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	DecisionID uint      `json:"decision_id" gorm:"not null;index"`
+	RuleID     uint      `json:"rule_id" gorm:"not null;index"`
+	RuleCode   string    `json:"rule_code" gorm:"size:50;not null"`
+	RuleType   string    `json:"rule_type" gorm:"size:30;not null"`
+	Action     string    `json:"action" gorm:"size:20;not null"`
+	Detail     string    `json:"detail"` // Why the rule matched, e.g. "6 transfers in 10 minutes"
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// FraudRuleRequest for creating or updating a fraud rule
+type FraudRuleRequest struct {
+	Code             string              `json:"code" binding:"required,min=3,max=50"`
+	Name             string              `json:"name" binding:"required,min=3,max=100"`
+	Description      string              `json:"description" binding:"max=1000"`
+	RuleType         string              `json:"rule_type" binding:"required,oneof=velocity amount_anomaly new_device_amount new_beneficiary night_time"`
+	TransactionTypes []string            `json:"transaction_types" binding:"omitempty,dive,oneof=transfer withdraw"`
+	Parameters       FraudRuleParameters `json:"parameters"`
+	Action           string              `json:"action" binding:"required,oneof=allow challenge hold block"`
+	IsActive         *bool               `json:"is_active"`
+}
+
+// ReviewFraudDecisionRequest for an admin decision on a held or blocked transaction
+type ReviewFraudDecisionRequest struct {
+	Action string `json:"action" binding:"required,oneof=approve reject"`
+	Notes  string `json:"notes" binding:"required,min=5,max=1000"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+)
+
+// Interbank transfer status constants
+const (
+	INTERBANK_STATUS_PENDING    = "pending"    // customer debited, not yet sent to the switch
+	INTERBANK_STATUS_PROCESSING = "processing" // accepted by the switch, waiting for callback
+	INTERBANK_STATUS_SUSPECT    = "suspect"    // outcome unknown (timeout), needs status check
+	INTERBANK_STATUS_SUCCESS    = "success"    // credited at the beneficiary bank
+	INTERBANK_STATUS_FAILED     = "failed"     // rejected, funds refunded to the customer
+)
+
+// InterbankTransfer represents an outbound transfer to an account at another bank
+type InterbankTransfer struct {
+	ID                       uint       `json:"id" gorm:"primaryKey"`
+	Reference                string     `json:"reference" gorm:"uniqueIndex;size:50;not null"`
+	UserID                   uint       `json:"user_id" gorm:"not null;index"`
+	SourceAccountNumber      string     `json:"source_account_number" gorm:"size:50"`
+	BeneficiaryBankCode      string     `json:"beneficiary_bank_code" gorm:"size:10;not null"`
+	BeneficiaryBankName      string     `json:"beneficiary_bank_name" gorm:"size:100"`
+	BeneficiaryAccountNumber string     `json:"beneficiary_account_number" gorm:"size:50;not null"`
+	BeneficiaryAccountName   string     `json:"beneficiary_account_name" gorm:"size:100"`
+	Amount                   int64      `json:"amount" gorm:"not null"`
+	Fee                      int64      `json:"fee" gorm:"default:0"`
+	Description              string     `json:"description"`
+	Status                   string     `json:"status" gorm:"size:20;default:'pending';index"` // "pending", "processing", "suspect", "success", "failed"
+	SwitchingAdapter         string     `json:"switching_adapter" gorm:"size:50"`
+	SwitchReference          string     `json:"switch_reference,omitempty" gorm:"size:100"`
+	FailureReason            string     `json:"failure_reason,omitempty"`
+	DebitTransactionID       *uint      `json:"debit_transaction_id,omitempty"`
+	FeeTransactionID         *uint      `json:"fee_transaction_id,omitempty"`
+	RefundTransactionID      *uint      `json:"refund_transaction_id,omitempty"`
+	RefundHeld               bool       `json:"refund_held,omitempty"` // Refund parked in UNCLAIMED_REFUNDS, the customer account was not active
+	SentAt                   *time.Time `json:"sent_at,omitempty"`
+	CompletedAt              *time.Time `json:"completed_at,omitempty"`
+	CreatedAt                time.Time  `json:"created_at"`
+	UpdatedAt                time.Time  `json:"updated_at"`
+
+	// Relationship
+	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// InterbankInquiryRequest for validating a beneficiary account at another bank
+type InterbankInquiryRequest struct {
+	BankCode      string `json:"bank_code" binding:"required,max=10"`
+	AccountNumber string `json:"account_number" binding:"required,min=5,max=34"`
+}
+
+// InterbankTransferRequest for sending money to another bank
+type InterbankTransferRequest struct {
+	BankCode      string `json:"bank_code" binding:"required,max=10"`
+	AccountNumber string `json:"account_number" binding:"required,min=5,max=34"`
+	Amount        int64  `json:"amount" binding:"required,min=1"`
+	Description   string `json:"description" binding:"max=140"`
+
+	FraudDecisionID *uint  `json:"fraud_decision_id"` // Resubmission of a challenged or reviewed transfer
+	PIN             string `json:"pin"`               // SHA256 of the PIN, answers a fraud challenge
+}
+
+// InterbankResolveRequest for an operator decision on a suspect transfer
+type InterbankResolveRequest struct {
+	Status string `json:"status" binding:"required,oneof=success failed"`
+	Reason string `json:"reason" binding:"required,max=255"`
+}
@@ -0,0 +1,128 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Day count convention constants
+const (
+	DAY_COUNT_ACT_365 = "ACT/365" // actual days over a 365-day year
+	DAY_COUNT_ACT_360 = "ACT/360" // actual days over a 360-day year
+	DAY_COUNT_ACT_ACT = "ACT/ACT" // actual days over the actual days of the year (365 or 366)
+)
+
+// Interest accruals are stored in micro units (1/1,000,000 of the base currency unit) so that
+// daily interest on small balances is not lost to rounding before capitalization.
+const INTEREST_MICRO_UNITS = 1000000
+
+// Interest capitalization status constants
+const (
+	INTEREST_CAPITALIZATION_COMPLETED = "completed"
+	INTEREST_CAPITALIZATION_FAILED    = "failed"
+)
+
+// InterestProduct defines how interest is calculated for savings accounts
+type InterestProduct struct {
+	ID                 uint           `json:"id" gorm:"primaryKey"`
+	Code               string         `json:"code" gorm:"uniqueIndex;size:30;not null"`
+	Name               string         `json:"name" gorm:"size:100;not null"`
+	DayCountBasis      string         `json:"day_count_basis" gorm:"size:10;not null"`   // "ACT/365", "ACT/360", "ACT/ACT"
+	TaxRateBasisPoints int            `json:"tax_rate_basis_points" gorm:"default:2000"` // Withholding tax on interest, 2000 = 20%
+	IsDefault          bool           `json:"is_default" gorm:"default:false"`           // Used by saving accounts without a product
+	IsActive           bool           `json:"is_active" gorm:"default:true"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationship
+	Tiers []InterestTier `json:"tiers" gorm:"foreignKey:ProductID"`
+}
+
+// InterestTier sets the annual rate for balances from MinBalance upwards.
+// The rate of the highest tier reached applies to the whole balance.
+type InterestTier struct {
+	ID              uint  `json:"id" gorm:"primaryKey"`
+	ProductID       uint  `json:"product_id" gorm:"not null;index"`
+	MinBalance      int64 `json:"min_balance" gorm:"not null"`
+	RateBasisPoints int   `json:"rate_basis_points" gorm:"not null"` // Annual rate, 250 = 2.50%
+}
+
+// RateFor returns the annual rate in basis points that applies to a balance
+func (p *InterestProduct) RateFor(balance int64) int {
+	rate := 0
+	var reached int64 = -1
+	for _, tier := range p.Tiers {
+		if balance >= tier.MinBalance && tier.MinBalance > reached {
+			rate = tier.RateBasisPoints
+			reached = tier.MinBalance
+		}
+	}
+	return rate
+}
+
+// InterestAccrual is the interest earned by a user for one day, written by the daily accrual job
+type InterestAccrual struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	UserID           uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_interest_accrual_user_date"`
+	AccrualDate      time.Time `json:"accrual_date" gorm:"type:date;not null;uniqueIndex:idx_interest_accrual_user_date;index"`
+	BankAccountID    uint      `json:"bank_account_id" gorm:"not null"`
+	ProductID        uint      `json:"product_id" gorm:"not null"`
+	Balance          int64     `json:"balance"` // End-of-day balance the interest was calculated on
+	RateBasisPoints  int       `json:"rate_basis_points"`
+	DayCountBasis    string    `json:"day_count_basis" gorm:"size:10"`
+	AmountMicros     int64     `json:"amount_micros"` // Interest in micro units (see INTEREST_MICRO_UNITS)
+	CapitalizationID *uint     `json:"capitalization_id,omitempty" gorm:"index"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// InterestCapitalization credits one month of accrued interest and withholds tax on it
+type InterestCapitalization struct {
+	ID                    uint       `json:"id" gorm:"primaryKey"`
+	UserID                uint       `json:"user_id" gorm:"not null;uniqueIndex:idx_interest_capitalization_user_period"`
+	Period                string     `json:"period" gorm:"size:7;not null;uniqueIndex:idx_interest_capitalization_user_period;index"` // "2006-01"
+	AccrualCount          int        `json:"accrual_count"`
+	GrossInterest         int64      `json:"gross_interest"`
+	TaxAmount             int64      `json:"tax_amount"`
+	NetInterest           int64      `json:"net_interest"`
+	Status                string     `json:"status" gorm:"size:20;index"` // "completed", "failed"
+	FailureReason         string     `json:"failure_reason,omitempty"`
+	InterestTransactionID *uint      `json:"interest_transaction_id,omitempty"`
+	TaxTransactionID      *uint      `json:"tax_transaction_id,omitempty"`
+	CapitalizedAt         *time.Time `json:"capitalized_at,omitempty"`
+	CreatedAt             time.Time  `json:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at"`
+}
+
+// InterestTierRequest describes one tier of a product
+type InterestTierRequest struct {
+	MinBalance      int64 `json:"min_balance" binding:"min=0"`
+	RateBasisPoints int   `json:"rate_basis_points" binding:"min=0,max=10000"`
+}
+
+// InterestProductRequest for creating or replacing an interest product
+type InterestProductRequest struct {
+	Code               string                `json:"code" binding:"required,min=3,max=30"`
+	Name               string                `json:"name" binding:"required,min=3,max=100"`
+	DayCountBasis      string                `json:"day_count_basis" binding:"required,oneof=ACT/365 ACT/360 ACT/ACT"`
+	TaxRateBasisPoints int                   `json:"tax_rate_basis_points" binding:"min=0,max=10000"`
+	IsDefault          bool                  `json:"is_default"`
+	IsActive           *bool                 `json:"is_active"`
+	Tiers              []InterestTierRequest `json:"tiers" binding:"required,min=1,dive"`
+}
+
+// AssignInterestProductRequest for linking a savings account to a product (null to use the default)
+type AssignInterestProductRequest struct {
+	ProductID *uint `json:"product_id"`
+}
+
+// RunInterestAccrualRequest for triggering the accrual of a date
+type RunInterestAccrualRequest struct {
+	AccrualDate string `json:"accrual_date" binding:"omitempty,datetime=2006-01-02"` // Defaults to yesterday
+}
+
+// RunInterestCapitalizationRequest for triggering the capitalization of a month
+type RunInterestCapitalizationRequest struct {
+	Period string `json:"period" binding:"omitempty,datetime=2006-01"` // Defaults to last month
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// ISO 20022 message direction constants
+const (
+	ISO20022_DIRECTION_INBOUND  = "inbound"  // received from a clearing partner or corporate client
+	ISO20022_DIRECTION_OUTBOUND = "outbound" // generated by this system
+)
+
+// ISO20022Message stores every ISO 20022 message imported or generated, keyed by its MsgId
+type ISO20022Message struct {
+	ID                uint      `json:"id" gorm:"primaryKey"`
+	MessageID         string    `json:"message_id" gorm:"size:35;not null;uniqueIndex:idx_iso20022_msg"` // GrpHdr/MsgId
+	MessageType       string    `json:"message_type" gorm:"size:20;not null;index"`                      // "pain.001.001.09", "pacs.008.001.08", "pacs.002.001.10"
+	Direction         string    `json:"direction" gorm:"size:10;not null;uniqueIndex:idx_iso20022_msg"`  // "inbound", "outbound"
+	NumberOfTxs       int       `json:"number_of_txs"`
+	ControlSum        int64     `json:"control_sum"`
+	OriginalMessageID string    `json:"original_message_id,omitempty" gorm:"size:35;index"` // For status reports
+	BatchID           *uint     `json:"batch_id,omitempty" gorm:"index"`                    // Disbursement batch created from a pain.001
+	AdminID           uint      `json:"admin_id" gorm:"not null"`
+	Payload           string    `json:"-" gorm:"type:text;not null"`
+	CreatedAt         time.Time `json:"created_at"`
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Loan status constants
+const (
+	LOAN_STATUS_SUBMITTED   = "submitted"   // applied by the customer, waiting for maker review
+	LOAN_STATUS_RECOMMENDED = "recommended" // recommended by the maker, waiting for checker approval
+	LOAN_STATUS_REJECTED    = "rejected"    // rejected by maker or checker
+	LOAN_STATUS_CANCELLED   = "cancelled"   // withdrawn by the customer before approval
+	LOAN_STATUS_ACTIVE      = "active"      // disbursed and being repaid
+	LOAN_STATUS_PAID_OFF    = "paid_off"    // every installment paid
+)
+
+// Loan installment status constants
+const (
+	LOAN_INSTALLMENT_PENDING = "pending" // not yet due or due and unpaid
+	LOAN_INSTALLMENT_PARTIAL = "partial" // partly collected
+	LOAN_INSTALLMENT_PAID    = "paid"
+)
+
+// LoanProduct defines the limits, pricing and late fees of an installment loan
+type LoanProduct struct {
+	ID                  uint           `json:"id" gorm:"primaryKey"`
+	Code                string         `json:"code" gorm:"uniqueIndex;size:30;not null"`
+	Name                string         `json:"name" gorm:"size:100;not null"`
+	MinAmount           int64          `json:"min_amount" gorm:"not null"`
+	MaxAmount           int64          `json:"max_amount" gorm:"not null"`
+	MinTenorMonths      int            `json:"min_tenor_months" gorm:"not null"`
+	MaxTenorMonths      int            `json:"max_tenor_months" gorm:"not null"`
+	RateBasisPoints     int            `json:"rate_basis_points" gorm:"not null"`       // Annual rate, 1800 = 18%
+	InterestMethod      string         `json:"interest_method" gorm:"size:10;not null"` // "flat", "annuity"
+	AdminFeeBasisPoints int            `json:"admin_fee_basis_points"`                  // Deducted from the disbursed amount
+	LateFeePerDay       int64          `json:"late_fee_per_day"`                        // Charged per overdue installment per day
+	LateFeeCap          int64          `json:"late_fee_cap"`                            // Maximum late fee per installment, 0 = no cap
+	IsActive            bool           `json:"is_active" gorm:"default:true"`
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Loan is an application and, once disbursed, the loan account. Pricing is copied from the
+// product at application time.
+type Loan struct {
+	ID                   uint       `json:"id" gorm:"primaryKey"`
+	LoanNumber           string     `json:"loan_number" gorm:"uniqueIndex;size:30;not null"`
+	UserID               uint       `json:"user_id" gorm:"not null;index"`
+	ProductID            uint       `json:"product_id" gorm:"not null;index"`
+	BankAccountID        uint       `json:"bank_account_id" gorm:"not null"` // Account the loan is disbursed to
+	Purpose              string     `json:"purpose" gorm:"size:255"`
+	Principal            int64      `json:"principal" gorm:"not null"`
+	TenorMonths          int        `json:"tenor_months" gorm:"not null"`
+	RateBasisPoints      int        `json:"rate_basis_points" gorm:"not null"`
+	InterestMethod       string     `json:"interest_method" gorm:"size:10;not null"`
+	AdminFee             int64      `json:"admin_fee"`
+	LateFeePerDay        int64      `json:"late_fee_per_day"`
+	LateFeeCap           int64      `json:"late_fee_cap"`
+	InstallmentAmount    int64      `json:"installment_amount"` // First installment, for display
+	Status               string     `json:"status" gorm:"size:20;not null;index"`
+	MakerAdminID         *uint      `json:"maker_admin_id,omitempty" gorm:"index"`
+	MakerComments        string     `json:"maker_comments,omitempty"`
+	CheckerAdminID       *uint      `json:"checker_admin_id,omitempty" gorm:"index"`
+	CheckerComments      string     `json:"checker_comments,omitempty"`
+	RejectionReason      string     `json:"rejection_reason,omitempty"`
+	DisbursedAmount      int64      `json:"disbursed_amount"` // Principal minus admin fee
+	DisbursementTxnID    *uint      `json:"disbursement_transaction_id,omitempty"`
+	OutstandingPrincipal int64      `json:"outstanding_principal"`
+	NextDueDate          *time.Time `json:"next_due_date,omitempty" gorm:"type:date"`
+	DaysPastDue          int        `json:"days_past_due" gorm:"default:0;index"` // Days since the oldest unpaid installment was due
+	RecommendedAt        *time.Time `json:"recommended_at,omitempty"`
+	DisbursedAt          *time.Time `json:"disbursed_at,omitempty"`
+	RejectedAt           *time.Time `json:"rejected_at,omitempty"`
+	PaidOffAt            *time.Time `json:"paid_off_at,omitempty"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+
+	// Relationships
+	Product      LoanProduct       `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+	Installments []LoanInstallment `json:"installments,omitempty" gorm:"foreignKey:LoanID"`
+}
+
+// LoanInstallment is one row of a loan's amortization schedule
+type LoanInstallment struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	LoanID          uint       `json:"loan_id" gorm:"not null;uniqueIndex:idx_loan_installment_sequence"`
+	Sequence        int        `json:"sequence" gorm:"not null;uniqueIndex:idx_loan_installment_sequence"`
+	DueDate         time.Time  `json:"due_date" gorm:"type:date;not null;index"`
+	PrincipalAmount int64      `json:"principal_amount"`
+	InterestAmount  int64      `json:"interest_amount"`
+	Amount          int64      `json:"amount"`   // Principal plus interest
+	LateFee         int64      `json:"late_fee"` // Accrued so far, capped at the loan's late fee cap
+	PrincipalPaid   int64      `json:"principal_paid"`
+	InterestPaid    int64      `json:"interest_paid"`
+	LateFeePaid     int64      `json:"late_fee_paid"`
+	Status          string     `json:"status" gorm:"size:20;not null;index"` // "pending", "partial", "paid"
+	PaidAt          *time.Time `json:"paid_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// AmountDue returns what is still owed on the installment including late fees
+func (i *LoanInstallment) AmountDue() int64 {
+	return (i.Amount - i.PrincipalPaid - i.InterestPaid) + (i.LateFee - i.LateFeePaid)
+}
+
+// LoanProductRequest for creating or updating a loan product
+type LoanProductRequest struct {
+	Code                string `json:"code" binding:"required,min=3,max=30"`
+	Name                string `json:"name" binding:"required,min=3,max=100"`
+	MinAmount           int64  `json:"min_amount" binding:"required,min=1"`
+	MaxAmount           int64  `json:"max_amount" binding:"required,gtefield=MinAmount"`
+	MinTenorMonths      int    `json:"min_tenor_months" binding:"required,min=1,max=60"`
+	MaxTenorMonths      int    `json:"max_tenor_months" binding:"required,gtefield=MinTenorMonths,max=60"`
+	RateBasisPoints     int    `json:"rate_basis_points" binding:"min=0,max=10000"`
+	InterestMethod      string `json:"interest_method" binding:"required,oneof=flat annuity"`
+	AdminFeeBasisPoints int    `json:"admin_fee_basis_points" binding:"min=0,max=2000"`
+	LateFeePerDay       int64  `json:"late_fee_per_day" binding:"min=0"`
+	LateFeeCap          int64  `json:"late_fee_cap" binding:"min=0"`
+	IsActive            *bool  `json:"is_active"`
+}
+
+// LoanSimulationRequest for previewing an installment schedule
+type LoanSimulationRequest struct {
+	ProductID   uint  `json:"product_id" binding:"required"`
+	Amount      int64 `json:"amount" binding:"required,min=1"`
+	TenorMonths int   `json:"tenor_months" binding:"required,min=1"`
+}
+
+// LoanApplicationRequest for applying for a loan
+type LoanApplicationRequest struct {
+	ProductID     uint   `json:"product_id" binding:"required"`
+	BankAccountID uint   `json:"bank_account_id" binding:"required"`
+	Amount        int64  `json:"amount" binding:"required,min=1"`
+	TenorMonths   int    `json:"tenor_months" binding:"required,min=1"`
+	Purpose       string `json:"purpose" binding:"required,min=3,max=255"`
+}
+
+// LoanReviewRequest for the maker recommendation and the checker approval
+type LoanReviewRequest struct {
+	Action          string `json:"action" binding:"required,oneof=approve reject"`
+	Comments        string `json:"comments,omitempty"`
+	RejectionReason string `json:"rejection_reason,omitempty"`
+}
+
+// RunLoanCollectionRequest for triggering installment auto-debit
+type RunLoanCollectionRequest struct {
+	Date string `json:"date" binding:"omitempty,datetime=2006-01-02"` // Defaults to today
+}
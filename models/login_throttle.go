@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+// Login throttle scope constants
+const (
+	LOGIN_THROTTLE_SCOPE_USER  = "user"  // keyed by phone number
+	LOGIN_THROTTLE_SCOPE_ADMIN = "admin" // keyed by email
+	LOGIN_THROTTLE_SCOPE_IP    = "ip"    // keyed by client IP, shared by user and admin logins
+)
+
+// Login audit status constants
+const (
+	LOGIN_STATUS_SUCCESS = "success"
+	LOGIN_STATUS_FAILED  = "failed"
+	LOGIN_STATUS_BLOCKED = "blocked" // rejected by throttling or lockout before the credentials were checked
+)
+
+// LoginThrottle counts recent failed logins for one identifier or IP address. Failures older than
+// the failure window no longer count; past the free attempts each failure blocks further attempts
+// for a delay that doubles every time.
+type LoginThrottle struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	Scope         string     `json:"scope" gorm:"size:10;not null;uniqueIndex:idx_login_throttle_key"` // "user", "admin", "ip"
+	Key           string     `json:"key" gorm:"size:255;not null;uniqueIndex:idx_login_throttle_key"`
+	FailureCount  int        `json:"failure_count" gorm:"not null;default:0"`
+	LastFailureAt *time.Time `json:"last_failure_at,omitempty"`
+	BlockedUntil  *time.Time `json:"blocked_until,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Merchant status constants
+const (
+	MERCHANT_STATUS_ACTIVE   = "active"
+	MERCHANT_STATUS_INACTIVE = "inactive"
+)
+
+// Merchant represents a QRIS merchant registered with this bank as acquirer
+type Merchant struct {
+	ID                      uint           `json:"id" gorm:"primaryKey"`
+	MerchantID              string         `json:"merchant_id" gorm:"uniqueIndex;size:20;not null"`  // National Merchant ID (NMID)
+	MerchantPAN             string         `json:"merchant_pan" gorm:"uniqueIndex;size:19;not null"` // QRIS merchant PAN (Luhn checked)
+	Name                    string         `json:"name" gorm:"size:25;not null"`                     // As printed on the QR (max 25)
+	City                    string         `json:"city" gorm:"size:15;not null"`                     // As printed on the QR (max 15)
+	PostalCode              string         `json:"postal_code" gorm:"size:10"`
+	MCC                     string         `json:"mcc" gorm:"size:4;not null"`      // ISO 18245 merchant category code
+	Criteria                string         `json:"criteria" gorm:"size:3;not null"` // QRIS criteria: "UMI", "UKE", "UME", "UBE"
+	UserID                  uint           `json:"user_id" gorm:"not null;index"`   // Merchant owner credited on payment
+	SettlementAccountNumber string         `json:"settlement_account_number" gorm:"size:50"`
+	MDRBasisPoints          int            `json:"mdr_basis_points" gorm:"default:0"`            // Merchant discount rate, e.g. 70 = 0.70%
+	MDRFixedFee             int64          `json:"mdr_fixed_fee" gorm:"default:0"`               // Fixed fee per payment on top of the rate
+	Status                  string         `json:"status" gorm:"size:20;default:'active';index"` // "active", "inactive"
+	CreatedAt               time.Time      `json:"created_at"`
+	UpdatedAt               time.Time      `json:"updated_at"`
+	DeletedAt               gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationship
+	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// CalculateMDR returns the merchant discount for a payment amount (never more than the amount)
+func (m *Merchant) CalculateMDR(amount int64) int64 {
+	mdr := amount*int64(m.MDRBasisPoints)/10000 + m.MDRFixedFee
+	if mdr > amount {
+		return amount
+	}
+	return mdr
+}
+
+// CreateMerchantRequest for registering a merchant
+type CreateMerchantRequest struct {
+	MerchantID              string `json:"merchant_id" binding:"omitempty,min=10,max=20"` // Generated if empty
+	Name                    string `json:"name" binding:"required,min=3,max=25"`
+	City                    string `json:"city" binding:"required,min=3,max=15"`
+	PostalCode              string `json:"postal_code" binding:"omitempty,max=10"`
+	MCC                     string `json:"mcc" binding:"required,len=4,numeric"`
+	Criteria                string `json:"criteria" binding:"required,oneof=UMI UKE UME UBE"`
+	UserID                  uint   `json:"user_id" binding:"required"`
+	SettlementAccountNumber string `json:"settlement_account_number" binding:"required"`
+	MDRBasisPoints          int    `json:"mdr_basis_points" binding:"omitempty,min=0,max=10000"`
+	MDRFixedFee             int64  `json:"mdr_fixed_fee" binding:"omitempty,min=0"`
+}
+
+// UpdateMerchantRequest for updating merchant details
+type UpdateMerchantRequest struct {
+	Name                    string `json:"name" binding:"omitempty,min=3,max=25"`
+	City                    string `json:"city" binding:"omitempty,min=3,max=15"`
+	PostalCode              string `json:"postal_code" binding:"omitempty,max=10"`
+	MCC                     string `json:"mcc" binding:"omitempty,len=4,numeric"`
+	Criteria                string `json:"criteria" binding:"omitempty,oneof=UMI UKE UME UBE"`
+	SettlementAccountNumber string `json:"settlement_account_number"`
+	MDRBasisPoints          *int   `json:"mdr_basis_points" binding:"omitempty,min=0,max=10000"`
+	MDRFixedFee             *int64 `json:"mdr_fixed_fee" binding:"omitempty,min=0"`
+	Status                  string `json:"status" binding:"omitempty,oneof=active inactive"`
+}
+
+// MerchantAPIKey is a server-to-server credential a merchant uses to accept payments.
+// Only a SHA-256 hash of the key is stored; the plaintext is shown once on creation.
+type MerchantAPIKey struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	MerchantID uint       `json:"merchant_id" gorm:"not null;index"`
+	Name       string     `json:"name" gorm:"size:100"`
+	KeyPrefix  string     `json:"key_prefix" gorm:"uniqueIndex;size:20;not null"` // Identifies the key in lists and lookups
+	KeyHash    string     `json:"-" gorm:"size:64;not null"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedBy  uint       `json:"created_by"` // Admin who issued the key
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreateMerchantAPIKeyRequest for issuing a merchant API key
+type CreateMerchantAPIKeyRequest struct {
+	Name string `json:"name" binding:"required,min=3,max=100"`
+}
+
+// MerchantPaymentRequest for a merchant requesting a dynamic QR through the API
+type MerchantPaymentRequest struct {
+	Amount        int64  `json:"amount" binding:"required,min=1"`
+	BillNumber    string `json:"bill_number" binding:"omitempty,max=25"`
+	TerminalLabel string `json:"terminal_label" binding:"omitempty,max=25"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// Merchant settlement status constants
+const (
+	MERCHANT_SETTLEMENT_COMPLETED = "completed" // net amount credited to the settlement account
+	MERCHANT_SETTLEMENT_FAILED    = "failed"    // could not be credited, retried on the next run
+)
+
+// MerchantSettlement nets one business day of a merchant's payments minus MDR into its settlement account
+type MerchantSettlement struct {
+	ID                      uint       `json:"id" gorm:"primaryKey"`
+	Reference               string     `json:"reference" gorm:"uniqueIndex;size:50;not null"`
+	MerchantID              uint       `json:"merchant_id" gorm:"not null;uniqueIndex:idx_merchant_business_date"`
+	BusinessDate            time.Time  `json:"business_date" gorm:"type:date;not null;uniqueIndex:idx_merchant_business_date"`
+	PaymentCount            int        `json:"payment_count"`
+	GrossAmount             int64      `json:"gross_amount"`
+	MDRAmount               int64      `json:"mdr_amount"`
+	NetAmount               int64      `json:"net_amount"`
+	SettlementAccountNumber string     `json:"settlement_account_number" gorm:"size:50"`
+	Status                  string     `json:"status" gorm:"size:20;index"` // "completed", "failed"
+	FailureReason           string     `json:"failure_reason,omitempty"`
+	TransactionID           *uint      `json:"transaction_id,omitempty"`
+	SettledAt               *time.Time `json:"settled_at,omitempty"`
+	CreatedAt               time.Time  `json:"created_at"`
+	UpdatedAt               time.Time  `json:"updated_at"`
+
+	// Relationship
+	Merchant Merchant `json:"merchant,omitempty" gorm:"foreignKey:MerchantID"`
+}
+
+// RunMerchantSettlementRequest for triggering settlement of a business date
+type RunMerchantSettlementRequest struct {
+	BusinessDate string `json:"business_date" binding:"omitempty,datetime=2006-01-02"` // Defaults to yesterday
+}
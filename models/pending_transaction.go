@@ -44,7 +44,7 @@ type PendingTransaction struct {
 // ApprovalThreshold represents the approval requirements for different transaction types
 type ApprovalThreshold struct {
 	ID                    uint           `json:"id" gorm:"primaryKey"`
-	TransactionType       string         `json:"transaction_type" gorm:"not null;uniqueIndex"` // "topup", "withdraw", "transfer", "balance_adjustment", "balance_set"
+	TransactionType       string         `json:"transaction_type" gorm:"not null;uniqueIndex"` // "topup", "withdraw", "transfer", "balance_adjustment", "balance_set", "bulk_disbursement"
 	AmountThreshold       int64          `json:"amount_threshold" gorm:"not null"`             // Amount above which approval is required
 	RequiresDualApproval  bool           `json:"requires_dual_approval" gorm:"default:false"`  // Whether it requires two approvers for very high amounts
 	DualApprovalThreshold int64          `json:"dual_approval_threshold"`                      // Amount above which dual approval is required
@@ -73,7 +73,7 @@ type ApprovalRequest struct {
 }
 
 type ApprovalThresholdRequest struct {
-	TransactionType       string `json:"transaction_type" binding:"required,oneof=topup withdraw transfer balance_adjustment balance_set"`
+	TransactionType       string `json:"transaction_type" binding:"required,oneof=topup withdraw transfer balance_adjustment balance_set bulk_disbursement"`
 	AmountThreshold       int64  `json:"amount_threshold" binding:"required,min=0"`
 	RequiresDualApproval  bool   `json:"requires_dual_approval"`
 	DualApprovalThreshold int64  `json:"dual_approval_threshold" binding:"min=0"`
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"time"
+)
+
+// Pocket status constants
+const (
+	POCKET_STATUS_ACTIVE = "active"
+	POCKET_STATUS_CLOSED = "closed"
+)
+
+// Auto-save rule constants
+const (
+	POCKET_AUTO_SAVE_NONE     = ""         // no automatic sweep
+	POCKET_AUTO_SAVE_FIXED    = "fixed"    // sweep AutoSaveAmount on every transfer out
+	POCKET_AUTO_SAVE_ROUND_UP = "round_up" // sweep the difference to the next multiple of RoundUpUnit
+)
+
+// Pocket movement type constants
+const (
+	POCKET_MOVEMENT_IN        = "move_in"
+	POCKET_MOVEMENT_OUT       = "move_out"
+	POCKET_MOVEMENT_AUTO_SAVE = "auto_save"
+)
+
+// Pocket is a savings goal held under the user's account. Money in a pocket is moved out of
+// User.Balance, so it is not available for transfers until it is moved back.
+type Pocket struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	UserID         uint       `json:"user_id" gorm:"not null;index"`
+	Name           string     `json:"name" gorm:"size:50;not null"`
+	TargetAmount   int64      `json:"target_amount"`                                // 0 when the pocket has no target
+	TargetDate     *time.Time `json:"target_date,omitempty" gorm:"type:date"`       // Optional goal date
+	Balance        int64      `json:"balance" gorm:"default:0"`                     // Money set aside in the pocket
+	AutoSaveType   string     `json:"auto_save_type" gorm:"size:20"`                // "", "fixed", "round_up"
+	AutoSaveAmount int64      `json:"auto_save_amount"`                             // Fixed amount swept per transfer out
+	RoundUpUnit    int64      `json:"round_up_unit"`                                // e.g. 1000 rounds 25,300 up to 26,000
+	Status         string     `json:"status" gorm:"size:20;default:'active';index"` // "active", "closed"
+	ClosedAt       *time.Time `json:"closed_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// AutoSaveAmountFor returns how much to sweep into the pocket for a transfer out of amount
+func (p *Pocket) AutoSaveAmountFor(amount int64) int64 {
+	switch p.AutoSaveType {
+	case POCKET_AUTO_SAVE_FIXED:
+		return p.AutoSaveAmount
+	case POCKET_AUTO_SAVE_ROUND_UP:
+		if p.RoundUpUnit <= 0 {
+			return 0
+		}
+		if remainder := amount % p.RoundUpUnit; remainder > 0 {
+			return p.RoundUpUnit - remainder
+		}
+	}
+	return 0
+}
+
+// TargetReached reports whether the pocket has reached its target amount
+func (p *Pocket) TargetReached() bool {
+	return p.TargetAmount > 0 && p.Balance >= p.TargetAmount
+}
+
+// PocketMovement records money moving between the main balance and a pocket
+type PocketMovement struct {
+	ID                  uint      `json:"id" gorm:"primaryKey"`
+	PocketID            uint      `json:"pocket_id" gorm:"not null;index"`
+	UserID              uint      `json:"user_id" gorm:"not null;index"`
+	Type                string    `json:"type" gorm:"size:20;not null"` // "move_in", "move_out", "auto_save"
+	Amount              int64     `json:"amount" gorm:"not null"`
+	BalanceBefore       int64     `json:"balance_before"` // Pocket balance before the movement
+	BalanceAfter        int64     `json:"balance_after"`
+	TransactionID       uint      `json:"transaction_id"`                               // Main balance transaction
+	SourceTransactionID *uint     `json:"source_transaction_id,omitempty" gorm:"index"` // Transfer out that triggered an auto-save
+	Description         string    `json:"description"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// PocketResponse adds goal progress to a pocket
+type PocketResponse struct {
+	Pocket
+	TargetReached   bool   `json:"target_reached"`
+	ProgressPercent *int64 `json:"progress_percent,omitempty"` // Only for pockets with a target
+	RemainingAmount *int64 `json:"remaining_amount,omitempty"`
+}
+
+// ToResponse converts a pocket to its API response
+func (p *Pocket) ToResponse() PocketResponse {
+	response := PocketResponse{Pocket: *p, TargetReached: p.TargetReached()}
+	if p.TargetAmount > 0 {
+		progress := min(p.Balance*100/p.TargetAmount, 100)
+		remaining := max(p.TargetAmount-p.Balance, 0)
+		response.ProgressPercent = &progress
+		response.RemainingAmount = &remaining
+	}
+	return response
+}
+
+// PocketRequest for creating or updating a pocket
+type PocketRequest struct {
+	Name           string `json:"name" binding:"required,min=1,max=50"`
+	TargetAmount   int64  `json:"target_amount" binding:"min=0"`
+	TargetDate     string `json:"target_date" binding:"omitempty,datetime=2006-01-02"`
+	AutoSaveType   string `json:"auto_save_type" binding:"omitempty,oneof=fixed round_up"`
+	AutoSaveAmount int64  `json:"auto_save_amount" binding:"min=0"`
+	RoundUpUnit    int64  `json:"round_up_unit" binding:"omitempty,oneof=1000 5000 10000 50000 100000"`
+}
+
+// PocketMoveRequest for moving money into or out of a pocket
+type PocketMoveRequest struct {
+	Amount int64 `json:"amount" binding:"required,min=1"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+)
+
+// QR payment type constants
+const (
+	QR_PAYMENT_TYPE_MERCHANT = "merchant" // QRIS merchant-presented QR
+	QR_PAYMENT_TYPE_PERSONAL = "personal" // personal receive-money QR of another customer
+)
+
+// QRPayment records a payment made by scanning a QR code
+type QRPayment struct {
+	ID                  uint      `json:"id" gorm:"primaryKey"`
+	Reference           string    `json:"reference" gorm:"uniqueIndex;size:50;not null"`
+	UserID              uint      `json:"user_id" gorm:"not null;index"` // Payer
+	PaymentType         string    `json:"payment_type" gorm:"size:20;not null"`
+	MerchantID          *uint     `json:"merchant_id,omitempty" gorm:"index"`
+	RecipientUserID     *uint     `json:"recipient_user_id,omitempty" gorm:"index"`
+	RecipientName       string    `json:"recipient_name" gorm:"size:100"`
+	RecipientAccount    string    `json:"recipient_account" gorm:"size:50"` // Merchant PAN or account number
+	Amount              int64     `json:"amount" gorm:"not null"`
+	TipAmount           int64     `json:"tip_amount" gorm:"default:0"` // Tip or convenience fee
+	TotalAmount         int64     `json:"total_amount" gorm:"not null"`
+	MDRAmount           int64     `json:"mdr_amount" gorm:"default:0"`          // Merchant discount deducted at settlement
+	SettlementID        *uint     `json:"settlement_id,omitempty" gorm:"index"` // Merchant settlement that paid this out
+	QRType              string    `json:"qr_type" gorm:"size:10"`               // "static", "dynamic"
+	BillNumber          string    `json:"bill_number,omitempty" gorm:"size:50"`
+	TerminalLabel       string    `json:"terminal_label,omitempty" gorm:"size:50"`
+	PayloadHash         string    `json:"-" gorm:"size:64;uniqueIndex:idx_qr_payments_payload_hash_completed,where:status = 'completed' AND payload_hash <> ''"` // Prevents paying the same dynamic QR twice
+	Status              string    `json:"status" gorm:"size:20;default:'completed'"`
+	DebitTransactionID  *uint     `json:"debit_transaction_id,omitempty"`
+	CreditTransactionID *uint     `json:"credit_transaction_id,omitempty"` // Personal QR only; merchant payments are credited at settlement
+	CreatedAt           time.Time `json:"created_at"`
+
+	// Relationships
+	Merchant *Merchant `json:"merchant,omitempty" gorm:"foreignKey:MerchantID"`
+}
+
+// QRParseRequest for decoding a scanned QR payload
+type QRParseRequest struct {
+	Payload string `json:"payload" binding:"required,min=20,max=512"`
+}
+
+// QRPaymentRequest for paying a scanned QR
+type QRPaymentRequest struct {
+	Payload string `json:"payload" binding:"required,min=20,max=512"`
+	Amount  int64  `json:"amount" binding:"omitempty,min=1"` // Required for static QR
+	Tip     int64  `json:"tip" binding:"omitempty,min=0"`    // Only used when the QR prompts for a tip
+
+	FraudDecisionID *uint  `json:"fraud_decision_id"` // Resubmission of a challenged or reviewed personal QR transfer
+	PIN             string `json:"pin"`               // SHA256 of the PIN, answers a fraud challenge
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+)
+
+// System account codes (internal settlement, suspense and income accounts)
+const (
+	SYSTEM_ACCOUNT_INTERBANK_SUSPENSE   = "INTERBANK_SUSPENSE"   // funds debited from customers, waiting for switch result
+	SYSTEM_ACCOUNT_INTERBANK_SETTLEMENT = "INTERBANK_SETTLEMENT" // funds owed to the switching network after success
+	SYSTEM_ACCOUNT_FEE_INCOME           = "FEE_INCOME"           // fee income collected from customers
+	SYSTEM_ACCOUNT_MERCHANT_PAYABLE     = "MERCHANT_PAYABLE"     // merchant payments received, waiting for daily settlement
+	SYSTEM_ACCOUNT_BILLER_SUSPENSE      = "BILLER_SUSPENSE"      // bill payments debited from customers, waiting for biller result
+	SYSTEM_ACCOUNT_BILLER_SETTLEMENT    = "BILLER_SETTLEMENT"    // funds owed to billers after successful payment
+	SYSTEM_ACCOUNT_VA_SUSPENSE          = "VA_SUSPENSE"          // inbound virtual account payments that could not be matched
+	SYSTEM_ACCOUNT_INTEREST_EXPENSE     = "INTEREST_EXPENSE"     // interest paid to customers
+	SYSTEM_ACCOUNT_TAX_PAYABLE          = "TAX_PAYABLE"          // withholding tax collected, owed to the tax office
+	SYSTEM_ACCOUNT_TIME_DEPOSIT         = "TIME_DEPOSIT"         // principal of outstanding time deposits
+	SYSTEM_ACCOUNT_LOAN_RECEIVABLE      = "LOAN_RECEIVABLE"      // principal of outstanding customer loans
+	SYSTEM_ACCOUNT_INTEREST_INCOME      = "INTEREST_INCOME"      // interest collected on customer loans
+	SYSTEM_ACCOUNT_CARD_HOLD            = "CARD_HOLD"            // card authorization holds waiting for clearing
+	SYSTEM_ACCOUNT_DISPUTE_SUSPENSE     = "DISPUTE_SUSPENSE"     // provisional credits advanced to customers on open disputes
+	SYSTEM_ACCOUNT_DISPUTE_EXPENSE      = "DISPUTE_EXPENSE"      // dispute adjustments borne by the bank
+	SYSTEM_ACCOUNT_CARD_SETTLEMENT      = "CARD_SETTLEMENT"      // cleared card purchases owed to the card network
+	SYSTEM_ACCOUNT_REVERSAL_RECEIVABLE  = "REVERSAL_RECEIVABLE"  // reversed transfer amounts the recipient could not cover
+	SYSTEM_ACCOUNT_UNCLAIMED_REFUNDS    = "UNCLAIMED_REFUNDS"    // refunds owed to customers whose account could not be credited
+	SYSTEM_ACCOUNT_DISBURSEMENT_FUNDING = "DISBURSEMENT_FUNDING" // funds received for bulk disbursements, paid out row by row
+)
+
+// SystemAccount represents an internal bank ledger account that is not owned by a user
+type SystemAccount struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Code        string    `json:"code" gorm:"uniqueIndex;size:50;not null"`
+	Name        string    `json:"name" gorm:"size:100;not null"`
+	AccountType string    `json:"account_type" gorm:"size:20"` // "suspense", "settlement", "income", "expense", "liability", "asset"
+	Balance     int64     `json:"balance" gorm:"default:0"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// SystemAccountEntry records a single movement on a system account
+type SystemAccountEntry struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	SystemAccountID uint      `json:"system_account_id" gorm:"not null;index"`
+	Amount          int64     `json:"amount" gorm:"not null"` // Positive for credit, negative for debit
+	BalanceBefore   int64     `json:"balance_before" gorm:"not null"`
+	BalanceAfter    int64     `json:"balance_after" gorm:"not null"`
+	Reference       string    `json:"reference" gorm:"size:50;index"` // Business reference (e.g. interbank transfer reference)
+	Description     string    `json:"description"`
+	TransactionID   *uint     `json:"transaction_id,omitempty" gorm:"index"` // Related customer transaction, if any
+	CreatedAt       time.Time `json:"created_at"`
+
+	// Relationship
+	SystemAccount SystemAccount `json:"-" gorm:"foreignKey:SystemAccountID"`
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Time deposit status constants
+const (
+	TIME_DEPOSIT_STATUS_ACTIVE      = "active"      // placed and waiting for maturity
+	TIME_DEPOSIT_STATUS_ROLLED_OVER = "rolled_over" // matured and renewed into a new deposit (RolledToID)
+	TIME_DEPOSIT_STATUS_PAID_OUT    = "paid_out"    // matured and paid to the savings balance
+	TIME_DEPOSIT_STATUS_BROKEN      = "broken"      // closed before maturity with a penalty
+)
+
+// Maturity instruction constants
+const (
+	MATURITY_ARO_PRINCIPAL          = "aro_principal"          // renew the principal, pay interest to savings
+	MATURITY_ARO_PRINCIPAL_INTEREST = "aro_principal_interest" // renew principal plus net interest
+	MATURITY_PAYOUT                 = "payout"                 // pay principal and interest to savings
+)
+
+// TimeDepositProduct defines the tenor, rate and break penalty of a deposito
+type TimeDepositProduct struct {
+	ID                 uint           `json:"id" gorm:"primaryKey"`
+	Code               string         `json:"code" gorm:"uniqueIndex;size:30;not null"`
+	Name               string         `json:"name" gorm:"size:100;not null"`
+	TenorMonths        int            `json:"tenor_months" gorm:"not null"`
+	RateBasisPoints    int            `json:"rate_basis_points" gorm:"not null"` // Annual rate, 450 = 4.50%
+	MinAmount          int64          `json:"min_amount" gorm:"not null"`
+	PenaltyBasisPoints int            `json:"penalty_basis_points" gorm:"default:0"`     // Charged on principal when broken early
+	TaxRateBasisPoints int            `json:"tax_rate_basis_points" gorm:"default:2000"` // Withholding tax on interest, 2000 = 20%
+	IsActive           bool           `json:"is_active" gorm:"default:true"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// TimeDeposit is one placement of a deposito. Terms are copied from the product at placement so
+// later product changes only affect new placements and rollovers.
+type TimeDeposit struct {
+	ID                  uint       `json:"id" gorm:"primaryKey"`
+	DepositNumber       string     `json:"deposit_number" gorm:"uniqueIndex;size:30;not null"`
+	UserID              uint       `json:"user_id" gorm:"not null;index"`
+	ProductID           uint       `json:"product_id" gorm:"not null;index"`
+	BankAccountID       uint       `json:"bank_account_id" gorm:"not null"` // Savings account funded from and paid to
+	Principal           int64      `json:"principal" gorm:"not null"`
+	TenorMonths         int        `json:"tenor_months" gorm:"not null"`
+	RateBasisPoints     int        `json:"rate_basis_points" gorm:"not null"`
+	PenaltyBasisPoints  int        `json:"penalty_basis_points"`
+	TaxRateBasisPoints  int        `json:"tax_rate_basis_points"`
+	MaturityInstruction string     `json:"maturity_instruction" gorm:"size:30;not null"` // "aro_principal", "aro_principal_interest", "payout"
+	PlacementDate       time.Time  `json:"placement_date" gorm:"type:date;not null"`
+	MaturityDate        time.Time  `json:"maturity_date" gorm:"type:date;not null;index"`
+	Status              string     `json:"status" gorm:"size:20;not null;index"` // "active", "rolled_over", "paid_out", "broken"
+	GrossInterest       int64      `json:"gross_interest"`                       // Filled when the deposit is closed
+	TaxAmount           int64      `json:"tax_amount"`
+	PenaltyAmount       int64      `json:"penalty_amount"`
+	PayoutAmount        int64      `json:"payout_amount"` // Amount credited to savings on close
+	RolledFromID        *uint      `json:"rolled_from_id,omitempty"`
+	RolledToID          *uint      `json:"rolled_to_id,omitempty"`
+	PlacementTxnID      *uint      `json:"placement_transaction_id,omitempty"`
+	ClosedAt            *time.Time `json:"closed_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+
+	// Relationship
+	Product TimeDepositProduct `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+}
+
+// TimeDepositProductRequest for creating or updating a deposito product
+type TimeDepositProductRequest struct {
+	Code               string `json:"code" binding:"required,min=3,max=30"`
+	Name               string `json:"name" binding:"required,min=3,max=100"`
+	TenorMonths        int    `json:"tenor_months" binding:"required,min=1,max=60"`
+	RateBasisPoints    int    `json:"rate_basis_points" binding:"min=0,max=10000"`
+	MinAmount          int64  `json:"min_amount" binding:"required,min=1"`
+	PenaltyBasisPoints int    `json:"penalty_basis_points" binding:"min=0,max=10000"`
+	TaxRateBasisPoints int    `json:"tax_rate_basis_points" binding:"min=0,max=10000"`
+	IsActive           *bool  `json:"is_active"`
+}
+
+// OpenTimeDepositRequest for placing a deposito from a savings account
+type OpenTimeDepositRequest struct {
+	ProductID           uint   `json:"product_id" binding:"required"`
+	BankAccountID       uint   `json:"bank_account_id" binding:"required"`
+	Amount              int64  `json:"amount" binding:"required,min=1"`
+	MaturityInstruction string `json:"maturity_instruction" binding:"required,oneof=aro_principal aro_principal_interest payout"`
+}
+
+// UpdateMaturityInstructionRequest for changing what happens at maturity
+type UpdateMaturityInstructionRequest struct {
+	MaturityInstruction string `json:"maturity_instruction" binding:"required,oneof=aro_principal aro_principal_interest payout"`
+}
+
+// RunTimeDepositMaturityRequest for triggering the maturity job
+type RunTimeDepositMaturityRequest struct {
+	Date string `json:"date" binding:"omitempty,datetime=2006-01-02"` // Defaults to today
+}
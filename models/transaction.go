@@ -6,28 +6,128 @@ import (
 	"gorm.io/gorm"
 )
 
+// Transaction status constants
+const (
+	TRANSACTION_STATUS_PENDING            = "pending"            // Menunggu hasil akhir (mis. hold kartu)
+	TRANSACTION_STATUS_COMPLETED          = "completed"          // Berhasil, belum ada reversal
+	TRANSACTION_STATUS_FAILED             = "failed"             // Gagal, status akhir
+	TRANSACTION_STATUS_PARTIALLY_REVERSED = "partially_reversed" // Sebagian amount sudah di-reverse
+	TRANSACTION_STATUS_REVERSED           = "reversed"           // Seluruh amount sudah di-reverse
+)
+
+// Transaction type constants
+const (
+	TRANSACTION_TYPE_TOPUP                        = "topup"                        // Top up saldo
+	TRANSACTION_TYPE_WITHDRAW                     = "withdraw"                     // Penarikan saldo
+	TRANSACTION_TYPE_TRANSFER_OUT                 = "transfer_out"                 // Transfer keluar ke sesama nasabah
+	TRANSACTION_TYPE_TRANSFER_IN                  = "transfer_in"                  // Transfer masuk dari sesama nasabah
+	TRANSACTION_TYPE_REVERSAL                     = "reversal"                     // Reversal transaksi lain
+	TRANSACTION_TYPE_ADJUSTMENT_CREDIT            = "adjustment_credit"            // Penyesuaian saldo oleh admin (kredit)
+	TRANSACTION_TYPE_ADJUSTMENT_DEBIT             = "adjustment_debit"             // Penyesuaian saldo oleh admin (debit)
+	TRANSACTION_TYPE_BALANCE_SET_CREDIT           = "balance_set_credit"           // Set saldo oleh admin (kredit)
+	TRANSACTION_TYPE_BALANCE_SET_DEBIT            = "balance_set_debit"            // Set saldo oleh admin (debit)
+	TRANSACTION_TYPE_DISBURSEMENT                 = "disbursement"                 // Kredit dari bulk disbursement
+	TRANSACTION_TYPE_INTERBANK_TRANSFER_OUT       = "interbank_transfer_out"       // Transfer keluar ke bank lain
+	TRANSACTION_TYPE_INTERBANK_REFUND             = "interbank_refund"             // Refund transfer antarbank yang gagal
+	TRANSACTION_TYPE_FEE                          = "fee"                          // Biaya transaksi
+	TRANSACTION_TYPE_QR_PAYMENT                   = "qr_payment"                   // Pembayaran QRIS ke merchant
+	TRANSACTION_TYPE_MERCHANT_SETTLEMENT          = "merchant_settlement"          // Settlement harian ke merchant
+	TRANSACTION_TYPE_BILL_PAYMENT                 = "bill_payment"                 // Pembayaran tagihan
+	TRANSACTION_TYPE_BILL_PAYMENT_REFUND          = "bill_payment_refund"          // Refund pembayaran tagihan yang gagal
+	TRANSACTION_TYPE_INTEREST                     = "interest"                     // Bunga tabungan atau deposito
+	TRANSACTION_TYPE_WITHHOLDING_TAX              = "withholding_tax"              // Pajak atas bunga
+	TRANSACTION_TYPE_TIME_DEPOSIT_PLACEMENT       = "time_deposit_placement"       // Penempatan deposito
+	TRANSACTION_TYPE_TIME_DEPOSIT_PAYOUT          = "time_deposit_payout"          // Pencairan pokok deposito saat jatuh tempo
+	TRANSACTION_TYPE_TIME_DEPOSIT_BREAK           = "time_deposit_break"           // Pencairan deposito sebelum jatuh tempo
+	TRANSACTION_TYPE_POCKET_MOVE_IN               = "pocket_move_in"               // Pemindahan saldo ke pocket
+	TRANSACTION_TYPE_POCKET_MOVE_OUT              = "pocket_move_out"              // Pemindahan saldo dari pocket
+	TRANSACTION_TYPE_LOAN_DISBURSEMENT            = "loan_disbursement"            // Pencairan pinjaman
+	TRANSACTION_TYPE_LOAN_REPAYMENT               = "loan_repayment"               // Pembayaran cicilan pinjaman
+	TRANSACTION_TYPE_LOAN_LATE_FEE                = "loan_late_fee"                // Denda keterlambatan cicilan
+	TRANSACTION_TYPE_CARD_AUTHORIZATION           = "card_authorization"           // Hold otorisasi kartu
+	TRANSACTION_TYPE_CARD_HOLD_RELEASE            = "card_hold_release"            // Pelepasan hold kartu
+	TRANSACTION_TYPE_DISPUTE_PROVISIONAL_CREDIT   = "dispute_provisional_credit"   // Kredit sementara selama dispute
+	TRANSACTION_TYPE_DISPUTE_PROVISIONAL_REVERSAL = "dispute_provisional_reversal" // Penarikan kredit sementara dispute
+	TRANSACTION_TYPE_DISPUTE_ADJUSTMENT           = "dispute_adjustment"           // Penyelesaian dispute untuk nasabah
+)
+
+// Counterparty policy constants, dipakai saat penerima transfer tidak punya saldo cukup untuk reversal
+const (
+	REVERSAL_POLICY_REJECT = "reject" // Reversal dibatalkan seluruhnya
+	REVERSAL_POLICY_ABSORB = "absorb" // Saldo penerima yang tersedia ditarik, kekurangannya dicatat sebagai piutang bank
+)
+
+// transactionTransitions lists the statuses each transaction status may move to. A reversed
+// transaction moves back towards completed when one of its reversals is itself reversed.
+var transactionTransitions = map[string][]string{
+	TRANSACTION_STATUS_PENDING:            {TRANSACTION_STATUS_COMPLETED, TRANSACTION_STATUS_FAILED},
+	TRANSACTION_STATUS_COMPLETED:          {TRANSACTION_STATUS_PARTIALLY_REVERSED, TRANSACTION_STATUS_REVERSED},
+	TRANSACTION_STATUS_PARTIALLY_REVERSED: {TRANSACTION_STATUS_PARTIALLY_REVERSED, TRANSACTION_STATUS_REVERSED, TRANSACTION_STATUS_COMPLETED},
+	TRANSACTION_STATUS_REVERSED:           {TRANSACTION_STATUS_PARTIALLY_REVERSED, TRANSACTION_STATUS_COMPLETED},
+}
+
 type Transaction struct {
-	ID             uint           `json:"id" gorm:"primaryKey"`
-	UserID         uint           `json:"user_id" gorm:"not null;index"`
-	Type           string         `json:"type" gorm:"not null"`              // "topup", "withdraw", "transfer_out", "transfer_in", "reversal"
-	Amount         int64          `json:"amount" gorm:"not null"`            // Amount dalam format int64
-	BalanceBefore  int64          `json:"balance_before" gorm:"not null"`    // Balance sebelum transaksi
-	BalanceAfter   int64          `json:"balance_after" gorm:"not null"`     // Balance setelah transaksi
-	Description    string         `json:"description"`                       // Deskripsi transaksi
-	Status         string         `json:"status" gorm:"default:'completed'"` // "completed", "failed", "pending"
-	OriginalTxnID  *uint          `json:"original_txn_id,omitempty"`         // ID transaksi asli (untuk reversal)
-	ReversedTxnID  *uint          `json:"reversed_txn_id,omitempty"`         // ID transaksi reversal (untuk transaksi yang di-reverse)
-	IsReversed     bool           `json:"is_reversed" gorm:"default:false"`  // Apakah transaksi sudah di-reverse
-	ReversalReason string         `json:"reversal_reason,omitempty"`         // Alasan reversal
-	ReversedAt     *time.Time     `json:"reversed_at,omitempty"`             // Waktu reversal
-	CreatedAt      time.Time      `json:"created_at"`
-	UpdatedAt      time.Time      `json:"updated_at"`
-	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
+	ID                uint           `json:"id" gorm:"primaryKey"`
+	UserID            uint           `json:"user_id" gorm:"not null;index"`
+	Type              string         `json:"type" gorm:"not null"`                       // TRANSACTION_TYPE_*, atau tipe pending transaction yang disetujui
+	Amount            int64          `json:"amount" gorm:"not null"`                     // Amount dalam format int64
+	Currency          string         `json:"currency" gorm:"size:3;default:'IDR'"`       // Mata uang amount dan balance (minor unit)
+	BalanceBefore     int64          `json:"balance_before" gorm:"not null"`             // Balance sebelum transaksi
+	BalanceAfter      int64          `json:"balance_after" gorm:"not null"`              // Balance setelah transaksi
+	Description       string         `json:"description"`                                // Deskripsi transaksi
+	Status            string         `json:"status" gorm:"default:'completed'"`          // "pending", "completed", "failed", "partially_reversed", "reversed"
+	OriginalTxnID     *uint          `json:"original_txn_id,omitempty"`                  // ID transaksi asli (untuk reversal)
+	ReversedTxnID     *uint          `json:"reversed_txn_id,omitempty"`                  // ID transaksi reversal terakhir (untuk transaksi yang di-reverse)
+	IsReversed        bool           `json:"is_reversed" gorm:"default:false"`           // Apakah transaksi sudah di-reverse seluruhnya
+	ReversedAmount    int64          `json:"reversed_amount" gorm:"default:0"`           // Total amount yang sudah di-reverse
+	CounterpartyTxnID *uint          `json:"counterparty_txn_id,omitempty" gorm:"index"` // Leg lawan (transfer_out <-> transfer_in, atau reversal keduanya)
+	ReversalReason    string         `json:"reversal_reason,omitempty"`                  // Alasan reversal
+	ReversedAt        *time.Time     `json:"reversed_at,omitempty"`                      // Waktu reversal
+	ExchangeRate      string         `json:"exchange_rate,omitempty" gorm:"size:32"`     // Kurs yang dipakai (mata uang tujuan per 1 mata uang asal)
+	CounterAmount     int64          `json:"counter_amount,omitempty"`                   // Amount di sisi lain konversi
+	CounterCurrency   string         `json:"counter_currency,omitempty" gorm:"size:3"`   // Mata uang di sisi lain konversi
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// Relationship
-	User        User         `json:"user,omitempty" gorm:"foreignKey:UserID"`
-	OriginalTxn *Transaction `json:"original_txn,omitempty" gorm:"foreignKey:OriginalTxnID"`
-	ReversedTxn *Transaction `json:"reversed_txn,omitempty" gorm:"foreignKey:ReversedTxnID"`
+	User        User          `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	OriginalTxn *Transaction  `json:"original_txn,omitempty" gorm:"foreignKey:OriginalTxnID"`
+	ReversedTxn *Transaction  `json:"reversed_txn,omitempty" gorm:"foreignKey:ReversedTxnID"`
+	Reversals   []Transaction `json:"reversals,omitempty" gorm:"foreignKey:OriginalTxnID"`
+}
+
+// CanTransitionTo reports whether the transaction may move to status
+func (t *Transaction) CanTransitionTo(status string) bool {
+	for _, allowed := range transactionTransitions[t.Status] {
+		if allowed == status {
+			return true
+		}
+	}
+	return false
+}
+
+// IsReversible reports whether part of the transaction amount can still be reversed
+func (t *Transaction) IsReversible() bool {
+	return (t.Status == TRANSACTION_STATUS_COMPLETED || t.Status == TRANSACTION_STATUS_PARTIALLY_REVERSED) &&
+		t.RemainingReversible() > 0
+}
+
+// RemainingReversible returns the amount not yet reversed
+func (t *Transaction) RemainingReversible() int64 {
+	return t.Amount - t.ReversedAmount
+}
+
+// StatusForReversedAmount returns the status a settled transaction has once reversedAmount of it is reversed
+func (t *Transaction) StatusForReversedAmount(reversedAmount int64) string {
+	switch {
+	case reversedAmount <= 0:
+		return TRANSACTION_STATUS_COMPLETED
+	case reversedAmount >= t.Amount:
+		return TRANSACTION_STATUS_REVERSED
+	default:
+		return TRANSACTION_STATUS_PARTIALLY_REVERSED
+	}
 }
 
 // Request structures for transaction operations
@@ -37,14 +137,20 @@ type TopupRequest struct {
 }
 
 type WithdrawRequest struct {
-	Amount      int64  `json:"amount" binding:"required,min=1"`
-	Description string `json:"description"`
+	Amount          int64  `json:"amount" binding:"required,min=1"`
+	Description     string `json:"description"`
+	FraudDecisionID *uint  `json:"fraud_decision_id"` // Resubmission of a challenged or reviewed withdrawal
+	PIN             string `json:"pin"`               // SHA256 of the PIN, answers a fraud challenge
 }
 
 type TransferRequest struct {
-	ToAccountNumber string `json:"to_account_number" binding:"required"`
-	Amount          int64  `json:"amount" binding:"required,min=1"`
-	Description     string `json:"description"`
+	FromAccountNumber string `json:"from_account_number"` // Optional, selects the source currency (default IDR)
+	ToAccountNumber   string `json:"to_account_number" binding:"required"`
+	Amount            int64  `json:"amount" binding:"required,min=1"`
+	Description       string `json:"description"`
+	FXQuoteID         *uint  `json:"fx_quote_id"`       // Required when the accounts use different currencies
+	FraudDecisionID   *uint  `json:"fraud_decision_id"` // Resubmission of a challenged or reviewed transfer
+	PIN               string `json:"pin"`               // SHA256 of the PIN, answers a fraud challenge
 }
 
 type BalanceAdjustmentRequest struct {
@@ -67,7 +173,9 @@ type BalanceSetRequest struct {
 }
 
 type ReversalRequest struct {
-	TransactionID  uint   `json:"transaction_id" binding:"required"`
-	ReversalReason string `json:"reversal_reason" binding:"required,min=10,max=500"`
-	AdminComments  string `json:"admin_comments,omitempty"`
+	TransactionID      uint   `json:"transaction_id" binding:"required"`
+	Amount             int64  `json:"amount" binding:"omitempty,min=1"` // Partial reversal, defaults to the amount not yet reversed
+	ReversalReason     string `json:"reversal_reason" binding:"required,min=10,max=500"`
+	CounterpartyPolicy string `json:"counterparty_policy" binding:"omitempty,oneof=reject absorb"` // Defaults to config reversal_counterparty_policy
+	AdminComments      string `json:"admin_comments,omitempty"`
 }
@@ -0,0 +1,108 @@
+package models
+
+import "testing"
+
+func TestTransactionCanTransitionTo(t *testing.T) {
+	statuses := []string{
+		TRANSACTION_STATUS_PENDING,
+		TRANSACTION_STATUS_COMPLETED,
+		TRANSACTION_STATUS_FAILED,
+		TRANSACTION_STATUS_PARTIALLY_REVERSED,
+		TRANSACTION_STATUS_REVERSED,
+	}
+	allowed := map[string]map[string]bool{
+		TRANSACTION_STATUS_PENDING: {
+			TRANSACTION_STATUS_COMPLETED: true,
+			TRANSACTION_STATUS_FAILED:    true,
+		},
+		TRANSACTION_STATUS_COMPLETED: {
+			TRANSACTION_STATUS_PARTIALLY_REVERSED: true,
+			TRANSACTION_STATUS_REVERSED:           true,
+		},
+		TRANSACTION_STATUS_FAILED: {},
+		TRANSACTION_STATUS_PARTIALLY_REVERSED: {
+			TRANSACTION_STATUS_PARTIALLY_REVERSED: true,
+			TRANSACTION_STATUS_REVERSED:           true,
+			TRANSACTION_STATUS_COMPLETED:          true,
+		},
+		TRANSACTION_STATUS_REVERSED: {
+			TRANSACTION_STATUS_PARTIALLY_REVERSED: true,
+			TRANSACTION_STATUS_COMPLETED:          true,
+		},
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			t.Run(from+"->"+to, func(t *testing.T) {
+				txn := &Transaction{Status: from}
+				if got, want := txn.CanTransitionTo(to), allowed[from][to]; got != want {
+					t.Errorf("CanTransitionTo(%s) from %s = %v, want %v", to, from, got, want)
+				}
+			})
+		}
+	}
+
+	t.Run("unknown status", func(t *testing.T) {
+		txn := &Transaction{Status: "unknown"}
+		if txn.CanTransitionTo(TRANSACTION_STATUS_COMPLETED) {
+			t.Errorf("transaction with an unknown status may move to completed")
+		}
+	})
+}
+
+func TestTransactionIsReversible(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         string
+		amount         int64
+		reversedAmount int64
+		want           bool
+	}{
+		{"completed", TRANSACTION_STATUS_COMPLETED, 100000, 0, true},
+		{"partially reversed", TRANSACTION_STATUS_PARTIALLY_REVERSED, 100000, 40000, true},
+		{"partially reversed status with nothing left", TRANSACTION_STATUS_PARTIALLY_REVERSED, 100000, 100000, false},
+		{"reversed", TRANSACTION_STATUS_REVERSED, 100000, 100000, false},
+		{"pending", TRANSACTION_STATUS_PENDING, 100000, 0, false},
+		{"failed", TRANSACTION_STATUS_FAILED, 100000, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txn := &Transaction{Status: tt.status, Amount: tt.amount, ReversedAmount: tt.reversedAmount}
+			if got := txn.IsReversible(); got != tt.want {
+				t.Errorf("IsReversible() = %v, want %v", got, tt.want)
+			}
+			if got := txn.RemainingReversible(); got != tt.amount-tt.reversedAmount {
+				t.Errorf("RemainingReversible() = %d, want %d", got, tt.amount-tt.reversedAmount)
+			}
+		})
+	}
+}
+
+func TestTransactionStatusForReversedAmount(t *testing.T) {
+	tests := []struct {
+		name           string
+		reversedAmount int64
+		want           string
+	}{
+		{"nothing reversed", 0, TRANSACTION_STATUS_COMPLETED},
+		{"reversal of reversal below zero", -1, TRANSACTION_STATUS_COMPLETED},
+		{"part reversed", 1, TRANSACTION_STATUS_PARTIALLY_REVERSED},
+		{"all but one reversed", 99999, TRANSACTION_STATUS_PARTIALLY_REVERSED},
+		{"fully reversed", 100000, TRANSACTION_STATUS_REVERSED},
+		{"over reversed", 100001, TRANSACTION_STATUS_REVERSED},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txn := &Transaction{Status: TRANSACTION_STATUS_COMPLETED, Amount: 100000}
+			got := txn.StatusForReversedAmount(tt.reversedAmount)
+			if got != tt.want {
+				t.Errorf("StatusForReversedAmount(%d) = %s, want %s", tt.reversedAmount, got, tt.want)
+			}
+			if !txn.CanTransitionTo(got) && got != TRANSACTION_STATUS_COMPLETED {
+				t.Errorf("completed transaction may not move to %s", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"time"
+)
+
+// Trusted device status constants
+const (
+	TRUSTED_DEVICE_STATUS_ACTIVE  = "active"  // bound, may log in and transact
+	TRUSTED_DEVICE_STATUS_UNBOUND = "unbound" // removed by the user or an admin, must be verified again
+)
+
+// TrustedDevice is a device bound to a user. Only bound devices may use the API; a device bound
+// after the first one is held to lower limits until its cooling-off period ends.
+type TrustedDevice struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	UserID          uint       `json:"user_id" gorm:"not null;uniqueIndex:idx_trusted_device_user_device"`
+	DeviceID        string     `json:"device_id" gorm:"size:255;not null;uniqueIndex:idx_trusted_device_user_device"`
+	DeviceType      DeviceType `json:"device_type" gorm:"size:50"`
+	DeviceName      string     `json:"device_name" gorm:"size:255"`
+	PublicKey       string     `json:"-" gorm:"type:text"`                     // PKIX public key (PEM or base64 DER) used to verify request signatures
+	KeyAlgorithm    string     `json:"key_algorithm,omitempty" gorm:"size:20"` // "ecdsa-p256", "ed25519"
+	KeyFingerprint  string     `json:"key_fingerprint,omitempty" gorm:"size:64"`
+	Status          string     `json:"status" gorm:"size:20;not null;index"`
+	IsPrimary       bool       `json:"is_primary" gorm:"default:false"` // First device bound to the account
+	BoundAt         time.Time  `json:"bound_at"`
+	CoolingOffUntil *time.Time `json:"cooling_off_until,omitempty"`
+	LastUsedAt      *time.Time `json:"last_used_at,omitempty"`
+	LastIPAddress   string     `json:"last_ip_address,omitempty" gorm:"size:45"`
+	UnboundAt       *time.Time `json:"unbound_at,omitempty"`
+	UnboundBy       string     `json:"unbound_by,omitempty" gorm:"size:20"` // "user", "admin"
+	UnboundReason   string     `json:"unbound_reason,omitempty" gorm:"size:255"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// InCoolingOff reports whether the device is still within its cooling-off period
+func (d *TrustedDevice) InCoolingOff(now time.Time) bool {
+	return d.CoolingOffUntil != nil && now.Before(*d.CoolingOffUntil)
+}
+
+// DeviceRequestNonce is a nonce a device signed a request with, kept until the request's timestamp
+// falls outside the allowed skew so a captured request cannot be replayed
+type DeviceRequestNonce struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	TrustedDeviceID uint      `json:"trusted_device_id" gorm:"not null;uniqueIndex:idx_device_request_nonce"`
+	Nonce           string    `json:"nonce" gorm:"size:64;not null;uniqueIndex:idx_device_request_nonce"`
+	ExpiresAt       time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// DeviceVerification is the extra step required before a new device is bound to an account that
+// already has a bound device
+type DeviceVerification struct {
+	ID                uint       `json:"id" gorm:"primaryKey"`
+	UserID            uint       `json:"user_id" gorm:"not null;index"`
+	VerificationToken string     `json:"-" gorm:"unique;not null;size:255"`
+	VerificationCode  string     `json:"-" gorm:"not null;size:10"` // Sent to the registered phone number
+	DeviceType        DeviceType `json:"device_type" gorm:"size:50"`
+	DeviceID          string     `json:"device_id" gorm:"size:255;not null"`
+	DeviceName        string     `json:"device_name" gorm:"size:255"`
+	PublicKey         string     `json:"-" gorm:"type:text"`
+	IPAddress         string     `json:"ip_address" gorm:"size:45"`
+	Attempts          int        `json:"attempts" gorm:"default:0"`
+	ExpiresAt         time.Time  `json:"expires_at" gorm:"not null"`
+	IsUsed            bool       `json:"is_used" gorm:"default:false"`
+	VerifiedAt        *time.Time `json:"verified_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// DeviceVerifyRequest for completing the verification of a new device
+type DeviceVerifyRequest struct {
+	VerificationToken string `json:"verification_token" binding:"required"`
+	OtpCode           string `json:"otp_code" binding:"required,len=6,numeric"`
+}
+
+// RegisterDeviceKeyRequest for registering or rotating the current device's signing key
+type RegisterDeviceKeyRequest struct {
+	PublicKey string `json:"public_key" binding:"required,max=4096"`
+}
+
+// UnbindDeviceRequest for a user removing one of their devices
+type UnbindDeviceRequest struct {
+	PIN    string `json:"pin" binding:"required,len=6,numeric"`
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+// AdminUnbindDeviceRequest for an admin removing a device, e.g. a reported lost phone
+type AdminUnbindDeviceRequest struct {
+	Reason string `json:"reason" binding:"required,min=5,max=255"`
+}
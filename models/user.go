@@ -31,6 +31,7 @@ type User struct {
 	Avatar         string          `json:"avatar" gorm:"size:500"`
 	BankAccounts   []BankAccount   `json:"bank_accounts,omitempty" gorm:"foreignKey:UserID"`
 	DeviceSessions []DeviceSession `json:"device_sessions,omitempty" gorm:"foreignKey:UserID"`
+	Loans          []Loan          `json:"loans,omitempty" gorm:"foreignKey:UserID"`
 	CreatedAt      time.Time       `json:"created_at"`
 	UpdatedAt      time.Time       `json:"updated_at"`
 	DeletedAt      gorm.DeletedAt  `json:"deleted_at,omitempty" gorm:"index"`
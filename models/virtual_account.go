@@ -0,0 +1,91 @@
+package models
+
+import (
+	"time"
+)
+
+// Virtual account type constants
+const (
+	VIRTUAL_ACCOUNT_TYPE_STATIC  = "static"  // permanent number per user, accepts any amount
+	VIRTUAL_ACCOUNT_TYPE_DYNAMIC = "dynamic" // single invoice with a fixed amount and expiry
+)
+
+// Virtual account status constants
+const (
+	VIRTUAL_ACCOUNT_STATUS_ACTIVE  = "active"
+	VIRTUAL_ACCOUNT_STATUS_PAID    = "paid"    // dynamic only
+	VIRTUAL_ACCOUNT_STATUS_EXPIRED = "expired" // dynamic only
+	VIRTUAL_ACCOUNT_STATUS_CLOSED  = "closed"  // cancelled by the user
+)
+
+// Virtual account payment status constants
+const (
+	VA_PAYMENT_STATUS_CREDITED = "credited" // matched and credited to the user
+	VA_PAYMENT_STATUS_SUSPENSE = "suspense" // unmatched, waiting for an admin decision
+	VA_PAYMENT_STATUS_ASSIGNED = "assigned" // credited to a user by an admin
+	VA_PAYMENT_STATUS_RETURNED = "returned" // returned to the sender by an admin
+)
+
+// VirtualAccount is a number other banks can transfer to in order to top up a user's balance
+type VirtualAccount struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	VANumber    string     `json:"va_number" gorm:"uniqueIndex;size:20;not null"`
+	UserID      uint       `json:"user_id" gorm:"not null;index"`
+	Type        string     `json:"type" gorm:"size:10;not null"` // "static", "dynamic"
+	Name        string     `json:"name" gorm:"size:100"`         // Shown to the payer at inquiry
+	Amount      int64      `json:"amount,omitempty"`             // Dynamic only: exact amount expected
+	Description string     `json:"description,omitempty" gorm:"size:255"`
+	Status      string     `json:"status" gorm:"size:20;default:'active';index"` // "active", "paid", "expired", "closed"
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`                         // Dynamic only
+	PaidAt      *time.Time `json:"paid_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	// Relationship
+	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// VirtualAccountPayment records every inbound credit notification, matched or not
+type VirtualAccountPayment struct {
+	ID                uint       `json:"id" gorm:"primaryKey"`
+	ExternalReference string     `json:"external_reference" gorm:"uniqueIndex;size:100;not null"` // Sender's reference, makes notifications idempotent
+	VANumber          string     `json:"va_number" gorm:"size:20;not null;index"`
+	Amount            int64      `json:"amount" gorm:"not null"`
+	PayerName         string     `json:"payer_name" gorm:"size:100"`
+	PayerBank         string     `json:"payer_bank" gorm:"size:50"`
+	PaidAt            *time.Time `json:"paid_at,omitempty"`
+	Status            string     `json:"status" gorm:"size:20;not null;index"` // "credited", "suspense", "assigned", "returned"
+	SuspenseReason    string     `json:"suspense_reason,omitempty"`
+	VirtualAccountID  *uint      `json:"virtual_account_id,omitempty" gorm:"index"`
+	UserID            *uint      `json:"user_id,omitempty" gorm:"index"`
+	TransactionID     *uint      `json:"transaction_id,omitempty"`
+	ResolvedBy        *uint      `json:"resolved_by,omitempty"` // Admin who assigned or returned a suspense payment
+	ResolutionNote    string     `json:"resolution_note,omitempty"`
+	ResolvedAt        *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// CreateDynamicVARequest for issuing a single-use invoice virtual account
+type CreateDynamicVARequest struct {
+	Amount           int64  `json:"amount" binding:"required,min=10000"`
+	Description      string `json:"description" binding:"max=255"`
+	ExpiresInMinutes int    `json:"expires_in_minutes" binding:"omitempty,min=5,max=10080"` // Default 24 hours
+}
+
+// VANotificationRequest is the inbound credit notification from the collecting bank
+type VANotificationRequest struct {
+	ExternalReference string    `json:"external_reference" binding:"required,max=100"`
+	VANumber          string    `json:"va_number" binding:"required,max=20"`
+	Amount            int64     `json:"amount" binding:"required,min=1"`
+	PayerName         string    `json:"payer_name" binding:"max=100"`
+	PayerBank         string    `json:"payer_bank" binding:"max=50"`
+	PaidAt            time.Time `json:"paid_at"`
+}
+
+// ResolveVAPaymentRequest for an admin deciding on a suspense payment
+type ResolveVAPaymentRequest struct {
+	Action string `json:"action" binding:"required,oneof=assign return"`
+	UserID uint   `json:"user_id" binding:"required_if=Action assign"`
+	Note   string `json:"note" binding:"required,max=255"`
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+)
+
+// Watchlist type constants
+const (
+	WATCHLIST_TYPE_SANCTIONS = "sanctions" // UN, DTTOT, OFAC and similar sanctions lists
+	WATCHLIST_TYPE_PEP       = "pep"       // Politically exposed persons
+)
+
+// Screening context constants
+const (
+	SCREENING_CONTEXT_REGISTRATION   = "registration"
+	SCREENING_CONTEXT_PROFILE_UPDATE = "profile_update"
+	SCREENING_CONTEXT_TRANSFER       = "transfer"
+	SCREENING_CONTEXT_ADMIN          = "admin" // Rescreening requested by an admin
+)
+
+// Screening subject constants
+const (
+	SCREENING_SUBJECT_CUSTOMER    = "customer"    // The customer's own name
+	SCREENING_SUBJECT_BENEFICIARY = "beneficiary" // A beneficiary name the customer tried to pay
+)
+
+// Screening match status constants
+const (
+	SCREENING_MATCH_STATUS_PENDING        = "pending"        // waiting for a compliance review
+	SCREENING_MATCH_STATUS_CONFIRMED      = "confirmed"      // true hit, the customer's account is frozen
+	SCREENING_MATCH_STATUS_FALSE_POSITIVE = "false_positive" // dismissed, the same name is not raised again
+)
+
+// WatchlistEntry is a listed person or entity imported from a sanctions or PEP list
+type WatchlistEntry struct {
+	ID                uint      `json:"id" gorm:"primaryKey"`
+	Source            string    `json:"source" gorm:"size:50;not null;uniqueIndex:idx_watchlist_source_ref"` // e.g. "UN", "DTTOT", "OFAC", "INTERNAL"
+	ExternalID        string    `json:"external_id" gorm:"size:100;not null;uniqueIndex:idx_watchlist_source_ref"`
+	ListType          string    `json:"list_type" gorm:"size:20;not null;index"` // "sanctions", "pep"
+	Name              string    `json:"name" gorm:"size:255;not null"`
+	NormalizedName    string    `json:"normalized_name" gorm:"size:255;not null;index"`
+	Aliases           []string  `json:"aliases" gorm:"serializer:json;type:text"`
+	NormalizedAliases []string  `json:"-" gorm:"serializer:json;type:text"`
+	DateOfBirth       string    `json:"date_of_birth,omitempty" gorm:"size:50"`
+	Nationality       string    `json:"nationality,omitempty" gorm:"size:255"`
+	Remarks           string    `json:"remarks,omitempty" gorm:"type:text"`
+	IsActive          bool      `json:"is_active" gorm:"default:true;index"`
+	ImportedByAdminID *uint     `json:"imported_by_admin_id,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// ScreeningMatch is a possible watchlist hit waiting for, or closed by, a compliance review.
+// A customer gets one match per entry and screened name, so repeated screenings of the same
+// name reuse the earlier review outcome.
+type ScreeningMatch struct {
+	ID                 uint       `json:"id" gorm:"primaryKey"`
+	UserID             uint       `json:"user_id" gorm:"not null;uniqueIndex:idx_screening_match_scope"` // Customer whose account a confirmed hit freezes
+	EntryID            uint       `json:"entry_id" gorm:"not null;uniqueIndex:idx_screening_match_scope"`
+	NormalizedName     string     `json:"normalized_name" gorm:"size:255;not null;uniqueIndex:idx_screening_match_scope"`
+	Subject            string     `json:"subject" gorm:"size:20;not null"` // "customer", "beneficiary"
+	Context            string     `json:"context" gorm:"size:30;not null"` // Where the first screening happened
+	ScreenedName       string     `json:"screened_name" gorm:"size:255;not null"`
+	BeneficiaryAccount string     `json:"beneficiary_account,omitempty" gorm:"size:50"`
+	MatchedName        string     `json:"matched_name" gorm:"size:255;not null"` // Listed name or alias that matched
+	Score              float64    `json:"score"`                                 // Name similarity, 0 to 1
+	Status             string     `json:"status" gorm:"size:20;not null;index"`
+	ReviewedByAdminID  *uint      `json:"reviewed_by_admin_id,omitempty"`
+	ReviewNotes        string     `json:"review_notes,omitempty" gorm:"type:text"`
+	ReviewedAt         *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+
+	// Relationships
+	User  User           `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Entry WatchlistEntry `json:"entry,omitempty" gorm:"foreignKey:EntryID"`
+}
+
+// ReviewScreeningMatchRequest for confirming or dismissing a screening match
+type ReviewScreeningMatchRequest struct {
+	Action string `json:"action" binding:"required,oneof=confirm dismiss"`
+	Notes  string `json:"notes" binding:"required,min=5,max=1000"`
+}
+
+// ScreenNameRequest for checking a name against the watchlist without recording a match
+type ScreenNameRequest struct {
+	Name string `json:"name" binding:"required,min=2,max=255"`
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"math"
+)
+
+// Loan interest methods
+const (
+	INTEREST_METHOD_FLAT    = "flat"    // interest on the original principal every month
+	INTEREST_METHOD_ANNUITY = "annuity" // equal installments, interest on the outstanding principal
+)
+
+// AmortizationRow is one monthly installment of a loan schedule
+type AmortizationRow struct {
+	Sequence             int   `json:"sequence"`
+	PrincipalAmount      int64 `json:"principal_amount"`
+	InterestAmount       int64 `json:"interest_amount"`
+	Amount               int64 `json:"amount"`
+	OutstandingPrincipal int64 `json:"outstanding_principal"` // Principal left after this installment
+}
+
+// BuildAmortizationSchedule splits a loan into monthly installments. Amounts are rounded to whole
+// units and the last installment absorbs the rounding difference of the principal.
+func BuildAmortizationSchedule(principal int64, annualRateBasisPoints, tenorMonths int, method string) []AmortizationRow {
+	if principal <= 0 || tenorMonths <= 0 {
+		return nil
+	}
+
+	monthlyRate := float64(annualRateBasisPoints) / 10000 / 12
+	rows := make([]AmortizationRow, 0, tenorMonths)
+	outstanding := principal
+
+	var installment int64
+	if method == INTEREST_METHOD_ANNUITY && monthlyRate > 0 {
+		factor := math.Pow(1+monthlyRate, float64(tenorMonths))
+		installment = int64(math.Ceil(float64(principal) * monthlyRate * factor / (factor - 1)))
+	}
+	flatInterest := int64(math.Round(float64(principal) * monthlyRate))
+	flatPrincipal := principal / int64(tenorMonths)
+
+	for sequence := 1; sequence <= tenorMonths; sequence++ {
+		var principalPart, interestPart int64
+		if method == INTEREST_METHOD_ANNUITY && monthlyRate > 0 {
+			interestPart = int64(math.Round(float64(outstanding) * monthlyRate))
+			principalPart = installment - interestPart
+		} else {
+			interestPart = flatInterest
+			principalPart = flatPrincipal
+		}
+		if sequence == tenorMonths || principalPart > outstanding {
+			principalPart = outstanding
+		}
+		outstanding -= principalPart
+
+		rows = append(rows, AmortizationRow{
+			Sequence:             sequence,
+			PrincipalAmount:      principalPart,
+			InterestAmount:       interestPart,
+			Amount:               principalPart + interestPart,
+			OutstandingPrincipal: outstanding,
+		})
+	}
+
+	return rows
+}
@@ -0,0 +1,92 @@
+package utils
+
+import "testing"
+
+func TestBuildAmortizationSchedule(t *testing.T) {
+	tests := []struct {
+		name        string
+		principal   int64
+		rateBP      int
+		tenor       int
+		method      string
+		wantRows    int
+		wantFirst   AmortizationRow
+		wantLast    AmortizationRow
+		equalAmount bool // every installment but the last has the same amount
+	}{
+		{
+			name: "flat", principal: 1200000, rateBP: 1200, tenor: 12, method: INTEREST_METHOD_FLAT,
+			wantRows:  12,
+			wantFirst: AmortizationRow{Sequence: 1, PrincipalAmount: 100000, InterestAmount: 12000, Amount: 112000, OutstandingPrincipal: 1100000},
+			wantLast:  AmortizationRow{Sequence: 12, PrincipalAmount: 100000, InterestAmount: 12000, Amount: 112000, OutstandingPrincipal: 0},
+		},
+		{
+			name: "flat with rounding left to the last installment", principal: 1000000, rateBP: 1200, tenor: 3, method: INTEREST_METHOD_FLAT,
+			wantRows:  3,
+			wantFirst: AmortizationRow{Sequence: 1, PrincipalAmount: 333333, InterestAmount: 10000, Amount: 343333, OutstandingPrincipal: 666667},
+			wantLast:  AmortizationRow{Sequence: 3, PrincipalAmount: 333334, InterestAmount: 10000, Amount: 343334, OutstandingPrincipal: 0},
+		},
+		{
+			name: "annuity", principal: 1200000, rateBP: 1200, tenor: 12, method: INTEREST_METHOD_ANNUITY,
+			wantRows:    12,
+			wantFirst:   AmortizationRow{Sequence: 1, PrincipalAmount: 94619, InterestAmount: 12000, Amount: 106619, OutstandingPrincipal: 1105381},
+			wantLast:    AmortizationRow{Sequence: 12, OutstandingPrincipal: 0},
+			equalAmount: true,
+		},
+		{
+			name: "annuity without interest is repaid in equal parts", principal: 600000, rateBP: 0, tenor: 6, method: INTEREST_METHOD_ANNUITY,
+			wantRows:    6,
+			wantFirst:   AmortizationRow{Sequence: 1, PrincipalAmount: 100000, InterestAmount: 0, Amount: 100000, OutstandingPrincipal: 500000},
+			wantLast:    AmortizationRow{Sequence: 6, PrincipalAmount: 100000, InterestAmount: 0, Amount: 100000, OutstandingPrincipal: 0},
+			equalAmount: true,
+		},
+		{
+			name: "single installment", principal: 500000, rateBP: 2400, tenor: 1, method: INTEREST_METHOD_ANNUITY,
+			wantRows:  1,
+			wantFirst: AmortizationRow{Sequence: 1, PrincipalAmount: 500000, InterestAmount: 10000, Amount: 510000, OutstandingPrincipal: 0},
+			wantLast:  AmortizationRow{Sequence: 1, PrincipalAmount: 500000, InterestAmount: 10000, Amount: 510000, OutstandingPrincipal: 0},
+		},
+		{name: "zero principal", principal: 0, rateBP: 1200, tenor: 12, method: INTEREST_METHOD_FLAT},
+		{name: "zero tenor", principal: 1000000, rateBP: 1200, tenor: 0, method: INTEREST_METHOD_ANNUITY},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := BuildAmortizationSchedule(tt.principal, tt.rateBP, tt.tenor, tt.method)
+			if len(rows) != tt.wantRows {
+				t.Fatalf("got %d rows, want %d", len(rows), tt.wantRows)
+			}
+			if tt.wantRows == 0 {
+				return
+			}
+
+			if rows[0] != tt.wantFirst {
+				t.Errorf("first row = %+v, want %+v", rows[0], tt.wantFirst)
+			}
+			last := rows[len(rows)-1]
+			if tt.wantLast.PrincipalAmount != 0 && last != tt.wantLast {
+				t.Errorf("last row = %+v, want %+v", last, tt.wantLast)
+			}
+			if last.Sequence != tt.wantLast.Sequence || last.OutstandingPrincipal != 0 {
+				t.Errorf("last row = %+v, want sequence %d with nothing outstanding", last, tt.wantLast.Sequence)
+			}
+
+			var repaid int64
+			for i, row := range rows {
+				repaid += row.PrincipalAmount
+				if row.Amount != row.PrincipalAmount+row.InterestAmount {
+					t.Errorf("row %d amount %d is not principal plus interest", row.Sequence, row.Amount)
+				}
+				if row.OutstandingPrincipal != tt.principal-repaid {
+					t.Errorf("row %d outstanding = %d, want %d", row.Sequence, row.OutstandingPrincipal, tt.principal-repaid)
+				}
+				if tt.equalAmount && i < len(rows)-1 && row.Amount != rows[0].Amount {
+					t.Errorf("row %d amount = %d, want %d", row.Sequence, row.Amount, rows[0].Amount)
+				}
+			}
+			if repaid != tt.principal {
+				t.Errorf("repaid principal = %d, want %d", repaid, tt.principal)
+			}
+		})
+	}
+}
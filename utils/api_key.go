@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// API key format: "mk_" + 48 hex characters. The first 11 characters are the lookup prefix.
+const (
+	apiKeyPrefix       = "mk_"
+	apiKeyLookupLength = 11
+)
+
+// GenerateAPIKey returns a new API key, its lookup prefix and the SHA-256 hash to store
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	secret := make([]byte, 24)
+	if _, err = rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+
+	key = apiKeyPrefix + hex.EncodeToString(secret)
+	return key, APIKeyPrefix(key), HashAPIKey(key), nil
+}
+
+// APIKeyPrefix returns the non-secret lookup prefix of an API key
+func APIKeyPrefix(key string) string {
+	if len(key) < apiKeyLookupLength {
+		return key
+	}
+	return key[:apiKeyLookupLength]
+}
+
+// HashAPIKey returns the hex SHA-256 of an API key
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+)
+
+// SignAuditCheckpoint signs an audit chain head with AUDIT_CHECKPOINT_SECRET. Keep the secret out of
+// the database so that someone able to rewrite audit rows cannot also forge checkpoints.
+func SignAuditCheckpoint(chain string, chainSeq uint64, hash string) string {
+	mac := hmac.New(sha256.New, []byte(getAuditCheckpointSecret()))
+	mac.Write([]byte(fmt.Sprintf("%s|%d|%s", chain, chainSeq, hash)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyAuditCheckpoint checks the signature of an audit chain checkpoint
+func VerifyAuditCheckpoint(chain string, chainSeq uint64, hash, signature string) bool {
+	return hmac.Equal([]byte(SignAuditCheckpoint(chain, chainSeq, hash)), []byte(signature))
+}
+
+func getAuditCheckpointSecret() string {
+	secret := os.Getenv("AUDIT_CHECKPOINT_SECRET")
+	if secret == "" {
+		secret = "mbankingcore-audit-checkpoint-secret"
+	}
+	return secret
+}
+
+// SignAuditArchive signs the manifest of an audit archive with the checkpoint secret, so that an
+// archive cannot be replaced or its recorded chain ranges altered without detection
+func SignAuditArchive(payload string) string {
+	mac := hmac.New(sha256.New, []byte(getAuditCheckpointSecret()))
+	mac.Write([]byte("archive|" + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyAuditArchive checks the signature of an audit archive manifest
+func VerifyAuditArchive(payload, signature string) bool {
+	return hmac.Equal([]byte(SignAuditArchive(payload)), []byte(signature))
+}
+
+// GetAuditArchiveDir returns the directory audit archives are written to, AUDIT_ARCHIVE_DIR
+func GetAuditArchiveDir() string {
+	dir := os.Getenv("AUDIT_ARCHIVE_DIR")
+	if dir == "" {
+		dir = "./archives/audit"
+	}
+	return dir
+}
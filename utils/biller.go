@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Biller payment status values returned by an adapter
+const (
+	BILLER_STATUS_SUCCESS = "success" // bill paid at the biller
+	BILLER_STATUS_PENDING = "pending" // accepted, final result must be queried with an advice
+	BILLER_STATUS_FAILED  = "failed"  // rejected by the biller
+	BILLER_STATUS_UNKNOWN = "unknown" // biller has no record of the payment
+)
+
+// ErrBillerUnavailable is returned when the biller cannot be reached (the request was never delivered)
+var ErrBillerUnavailable = errors.New("biller unavailable")
+
+// ErrBillerTimeout is returned when the biller did not answer in time (the outcome is unknown)
+var ErrBillerTimeout = errors.New("biller response timeout")
+
+// BillerInquiryRequest asks the biller for the outstanding bill of a customer number
+type BillerInquiryRequest struct {
+	BillerCode     string `json:"biller_code"`
+	CustomerNumber string `json:"customer_number"`
+	Amount         int64  `json:"amount,omitempty"` // Prepaid products: chosen denomination
+}
+
+// BillerInquiryResponse describes the bill to be paid
+type BillerInquiryResponse struct {
+	BillerCode       string `json:"biller_code"`
+	CustomerNumber   string `json:"customer_number"`
+	CustomerName     string `json:"customer_name"`
+	Period           string `json:"period,omitempty"`
+	Amount           int64  `json:"amount"`
+	InquiryReference string `json:"inquiry_reference"`
+}
+
+// BillerPaymentRequest pays a bill previously returned by an inquiry
+type BillerPaymentRequest struct {
+	Reference        string `json:"reference"`
+	BillerCode       string `json:"biller_code"`
+	CustomerNumber   string `json:"customer_number"`
+	Amount           int64  `json:"amount"`
+	InquiryReference string `json:"inquiry_reference"`
+}
+
+// BillerPaymentResponse is the biller's answer to a payment or advice
+type BillerPaymentResponse struct {
+	Reference       string `json:"reference"`
+	BillerReference string `json:"biller_reference,omitempty"`
+	Status          string `json:"status"`
+	Token           string `json:"token,omitempty"` // Prepaid token or voucher serial number
+	FailureReason   string `json:"failure_reason,omitempty"`
+}
+
+// BillerAdapter abstracts a biller host-to-host connection or biller aggregator
+type BillerAdapter interface {
+	// Name identifies the adapter in logs and payment records
+	Name() string
+	// Inquiry returns the bill for a customer number
+	Inquiry(req BillerInquiryRequest) (*BillerInquiryResponse, error)
+	// Payment pays a bill; a timeout leaves the outcome unknown
+	Payment(req BillerPaymentRequest) (*BillerPaymentResponse, error)
+	// Advice queries the biller for the final status of an earlier payment
+	Advice(reference string) (*BillerPaymentResponse, error)
+}
+
+// NewBillerAdapter returns the adapter configured by BILLER_ADAPTER (default: mock)
+func NewBillerAdapter() BillerAdapter {
+	adapter := os.Getenv("BILLER_ADAPTER")
+	if adapter != "" && adapter != "mock" {
+		log.Printf("Unknown BILLER_ADAPTER %q, falling back to mock", adapter)
+	}
+	return NewMockBillerAdapter()
+}
+
+// MockBillerAdapter is an offline biller for development and testing.
+//
+// Customer numbers drive the simulated outcome:
+//   - ending in "0000": inquiry fails (customer number not found)
+//   - ending in "9999": payment is rejected
+//   - ending in "8888": payment succeeds at the biller but the response times out (advice returns success)
+//   - ending in "5555": payment times out and never reaches the biller (advice returns unknown)
+//   - ending in "6666": payment is pending, advice returns success
+//   - ending in "7777": biller is unavailable
+//   - anything else: payment succeeds
+type MockBillerAdapter struct {
+	mu       sync.Mutex
+	payments map[string]*BillerPaymentResponse
+}
+
+// NewMockBillerAdapter creates an empty mock biller
+func NewMockBillerAdapter() *MockBillerAdapter {
+	return &MockBillerAdapter{payments: map[string]*BillerPaymentResponse{}}
+}
+
+func (m *MockBillerAdapter) Name() string {
+	return "mock"
+}
+
+func (m *MockBillerAdapter) Inquiry(req BillerInquiryRequest) (*BillerInquiryResponse, error) {
+	if strings.HasSuffix(req.CustomerNumber, "7777") {
+		return nil, ErrBillerUnavailable
+	}
+	if strings.HasSuffix(req.CustomerNumber, "0000") {
+		return nil, errors.New("customer number not found")
+	}
+
+	amount := req.Amount
+	period := ""
+	if amount == 0 {
+		// Postpaid: derive a stable bill amount from the customer number
+		var sum int64
+		for _, r := range req.CustomerNumber {
+			if r >= '0' && r <= '9' {
+				sum += int64(r - '0')
+			}
+		}
+		amount = (sum%40 + 5) * 10000
+		period = time.Now().AddDate(0, -1, 0).Format("200601")
+	}
+
+	return &BillerInquiryResponse{
+		BillerCode:       req.BillerCode,
+		CustomerNumber:   req.CustomerNumber,
+		CustomerName:     "MOCK CUSTOMER " + req.CustomerNumber[max(0, len(req.CustomerNumber)-4):],
+		Period:           period,
+		Amount:           amount,
+		InquiryReference: GenerateReference("INQ"),
+	}, nil
+}
+
+func (m *MockBillerAdapter) Payment(req BillerPaymentRequest) (*BillerPaymentResponse, error) {
+	switch {
+	case strings.HasSuffix(req.CustomerNumber, "7777"):
+		return nil, ErrBillerUnavailable
+	case strings.HasSuffix(req.CustomerNumber, "5555"):
+		return nil, ErrBillerTimeout
+	}
+
+	response := &BillerPaymentResponse{
+		Reference:       req.Reference,
+		BillerReference: GenerateReference("MBL"),
+		Status:          BILLER_STATUS_SUCCESS,
+		Token:           mockBillerToken(req.Reference),
+	}
+	if strings.HasSuffix(req.CustomerNumber, "9999") {
+		response.Status = BILLER_STATUS_FAILED
+		response.Token = ""
+		response.FailureReason = "bill already paid"
+	}
+
+	m.mu.Lock()
+	m.payments[req.Reference] = response
+	m.mu.Unlock()
+
+	copied := *response
+	switch {
+	case strings.HasSuffix(req.CustomerNumber, "8888"):
+		return nil, ErrBillerTimeout
+	case strings.HasSuffix(req.CustomerNumber, "6666"):
+		copied.Status = BILLER_STATUS_PENDING
+		copied.Token = ""
+	}
+	return &copied, nil
+}
+
+func (m *MockBillerAdapter) Advice(reference string) (*BillerPaymentResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	response, ok := m.payments[reference]
+	if !ok {
+		return &BillerPaymentResponse{Reference: reference, Status: BILLER_STATUS_UNKNOWN}, nil
+	}
+
+	copied := *response
+	return &copied, nil
+}
+
+// mockBillerToken derives a 20-digit prepaid token from the payment reference
+func mockBillerToken(reference string) string {
+	var hash uint64 = 14695981039346656037
+	for i := 0; i < len(reference); i++ {
+		hash ^= uint64(reference[i])
+		hash *= 1099511628211
+	}
+	return fmt.Sprintf("%020d", hash%10000000000000000000)
+}
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+)
+
+// CardBIN returns the 6-digit bank identification number for issued cards (CARD_BIN)
+func CardBIN() string {
+	bin := os.Getenv("CARD_BIN")
+	if bin == "" {
+		bin = "522184"
+	}
+	return bin
+}
+
+// BuildCardPAN appends the Luhn check digit to the BIN and account digits. Issued cards use
+// 9 account digits, giving a 16-digit PAN.
+func BuildCardPAN(accountDigits string) string {
+	pan := CardBIN() + accountDigits
+	return pan + string(LuhnCheckDigit(pan))
+}
+
+// MaskCardPAN keeps the BIN and last four digits, e.g. "522184******1234"
+func MaskCardPAN(pan string) string {
+	if len(pan) < 10 {
+		return pan
+	}
+	masked := []byte(pan)
+	for i := 6; i < len(pan)-4; i++ {
+		masked[i] = '*'
+	}
+	return string(masked)
+}
+
+// GenerateCardToken returns the opaque token that stands in for the PAN outside the card vault
+func GenerateCardToken() (string, error) {
+	secret := make([]byte, 16)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return "ctk_" + hex.EncodeToString(secret), nil
+}
+
+// HashCardPAN returns the keyed hash used to look a card up by PAN. The PAN itself is never stored.
+func HashCardPAN(pan string) string {
+	mac := hmac.New(sha256.New, []byte(getCardVaultSecret()))
+	mac.Write([]byte(pan))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// HashCardCVV returns the keyed hash of a CVV, bound to the card token and expiry
+func HashCardCVV(token, cvv string, expiryMonth, expiryYear int) string {
+	mac := hmac.New(sha256.New, []byte(getCardVaultSecret()))
+	mac.Write([]byte(fmt.Sprintf("%s|%s|%02d%02d", token, cvv, expiryYear%100, expiryMonth)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyCardCVV checks a CVV against its stored hash
+func VerifyCardCVV(hash, token, cvv string, expiryMonth, expiryYear int) bool {
+	return hmac.Equal([]byte(hash), []byte(HashCardCVV(token, cvv, expiryMonth, expiryYear)))
+}
+
+// SignCardNetworkMessage computes the hex HMAC-SHA256 signature of a card network message
+func SignCardNetworkMessage(payload []byte) string {
+	return SignSwitchingPayload(getCardNetworkSecret(), payload)
+}
+
+// VerifyCardNetworkMessage checks the X-Signature of an inbound card network message
+func VerifyCardNetworkMessage(payload []byte, signature string) bool {
+	return hmac.Equal([]byte(SignCardNetworkMessage(payload)), []byte(signature))
+}
+
+func getCardVaultSecret() string {
+	secret := os.Getenv("CARD_VAULT_SECRET")
+	if secret == "" {
+		secret = "mbankingcore-card-vault-secret"
+	}
+	return secret
+}
+
+func getCardNetworkSecret() string {
+	secret := os.Getenv("CARD_NETWORK_SECRET")
+	if secret == "" {
+		secret = "mbankingcore-card-network-secret"
+	}
+	return secret
+}
@@ -0,0 +1,50 @@
+package utils
+
+import "testing"
+
+func TestBuildCardPAN(t *testing.T) {
+	tests := []struct {
+		name          string
+		bin           string
+		accountDigits string
+		want          string
+	}{
+		{"default BIN", "", "000000001", "5221840000000019"},
+		{"configured BIN", "411111", "111111111", "4111111111111111"},
+		{"account digits in order", "", "123456789", "5221841234567898"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CARD_BIN", tt.bin)
+			got := BuildCardPAN(tt.accountDigits)
+			if got != tt.want {
+				t.Errorf("BuildCardPAN(%q) = %s, want %s", tt.accountDigits, got, tt.want)
+			}
+			if len(got) != 16 || !LuhnValid(got) {
+				t.Errorf("BuildCardPAN(%q) = %s is not a valid 16-digit PAN", tt.accountDigits, got)
+			}
+		})
+	}
+}
+
+func TestMaskCardPAN(t *testing.T) {
+	tests := []struct {
+		pan  string
+		want string
+	}{
+		{"5221840000000017", "522184******0017"},
+		{"4111111111111111", "411111******1111"},
+		{"5221841234", "5221841234"},
+		{"522184123", "522184123"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pan, func(t *testing.T) {
+			if got := MaskCardPAN(tt.pan); got != tt.want {
+				t.Errorf("MaskCardPAN(%q) = %s, want %s", tt.pan, got, tt.want)
+			}
+		})
+	}
+}
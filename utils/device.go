@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Device key algorithms accepted for request signing
+const (
+	DEVICE_KEY_ALGORITHM_ECDSA_P256 = "ecdsa-p256" // Android Keystore / iOS Secure Enclave default
+	DEVICE_KEY_ALGORITHM_ED25519    = "ed25519"
+)
+
+// ErrInvalidDeviceSignature is returned when a request signature does not verify
+var ErrInvalidDeviceSignature = errors.New("invalid device signature")
+
+// ParseDevicePublicKey parses a PKIX public key given as PEM or base64 DER and returns the key,
+// its algorithm and a SHA-256 fingerprint of the DER bytes
+func ParseDevicePublicKey(encoded string) (crypto.PublicKey, string, string, error) {
+	encoded = strings.TrimSpace(encoded)
+
+	var der []byte
+	if block, _ := pem.Decode([]byte(encoded)); block != nil {
+		der = block.Bytes
+	} else {
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, "", "", fmt.Errorf("public key must be PEM or base64 encoded DER")
+		}
+		der = decoded
+	}
+
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("invalid public key: %v", err)
+	}
+
+	sum := sha256.Sum256(der)
+	fingerprint := hex.EncodeToString(sum[:])
+
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return nil, "", "", fmt.Errorf("only P-256 ECDSA keys are supported")
+		}
+		return k, DEVICE_KEY_ALGORITHM_ECDSA_P256, fingerprint, nil
+	case ed25519.PublicKey:
+		return k, DEVICE_KEY_ALGORITHM_ED25519, fingerprint, nil
+	default:
+		return nil, "", "", fmt.Errorf("unsupported public key type, use ECDSA P-256 or Ed25519")
+	}
+}
+
+// DeviceSigningPayload builds the string a device signs for a request:
+// METHOD \n request URI \n timestamp \n nonce \n hex(SHA-256(body))
+func DeviceSigningPayload(method, requestURI, timestamp, nonce string, body []byte) []byte {
+	bodyHash := sha256.Sum256(body)
+	return []byte(strings.Join([]string{
+		strings.ToUpper(method),
+		requestURI,
+		timestamp,
+		nonce,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n"))
+}
+
+// VerifyDeviceSignature checks a base64 signature over payload. ECDSA signatures are ASN.1 DER
+// over the SHA-256 digest; Ed25519 signatures are over the payload itself.
+func VerifyDeviceSignature(encodedKey string, payload []byte, signature string) error {
+	key, _, _, err := ParseDevicePublicKey(encodedKey)
+	if err != nil {
+		return err
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(signature))
+	if err != nil {
+		return ErrInvalidDeviceSignature
+	}
+
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(payload)
+		if !ecdsa.VerifyASN1(k, digest[:], sig) {
+			return ErrInvalidDeviceSignature
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, payload, sig) {
+			return ErrInvalidDeviceSignature
+		}
+	}
+	return nil
+}
@@ -0,0 +1,469 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EMVCo merchant-presented QR (MPM) root tags
+const (
+	EMVQR_TAG_PAYLOAD_FORMAT      = "00"
+	EMVQR_TAG_POINT_OF_INITIATION = "01"
+	EMVQR_TAG_MCC                 = "52"
+	EMVQR_TAG_CURRENCY            = "53"
+	EMVQR_TAG_AMOUNT              = "54"
+	EMVQR_TAG_TIP_INDICATOR       = "55"
+	EMVQR_TAG_FEE_FIXED           = "56"
+	EMVQR_TAG_FEE_PERCENTAGE      = "57"
+	EMVQR_TAG_COUNTRY             = "58"
+	EMVQR_TAG_MERCHANT_NAME       = "59"
+	EMVQR_TAG_MERCHANT_CITY       = "60"
+	EMVQR_TAG_POSTAL_CODE         = "61"
+	EMVQR_TAG_ADDITIONAL_DATA     = "62"
+	EMVQR_TAG_CRC                 = "63"
+)
+
+// Point of initiation method values
+const (
+	EMVQR_STATIC  = "11" // same QR for every payment, customer enters the amount
+	EMVQR_DYNAMIC = "12" // QR for a single payment with the amount embedded
+)
+
+// Tip or convenience indicator values (tag 55)
+const (
+	EMVQR_TIP_PROMPT     = "01" // customer enters a tip
+	EMVQR_FEE_FIXED      = "02" // fixed convenience fee in tag 56
+	EMVQR_FEE_PERCENTAGE = "03" // percentage convenience fee in tag 57
+)
+
+// QRIS globally unique identifiers
+const (
+	QRIS_GUI          = "ID.CO.QRIS.WWW"         // national QRIS merchant information template (tag 51)
+	QRIS_ACQUIRER_GUI = "ID.CO.MBANKINGCORE.WWW" // this bank as acquirer of a merchant (tag 26)
+	QRIS_PERSONAL_GUI = "ID.CO.MBANKINGCORE.P2P" // this bank's personal receive-money QR (tag 26)
+	QRIS_CURRENCY_IDR = "360"                    // ISO 4217 numeric code for IDR
+	QRIS_COUNTRY_ID   = "ID"
+	QRIS_MCC_PERSONAL = "4829" // money transfer
+)
+
+// EMVQRMerchantAccount is a merchant account information template (tags 26-51)
+type EMVQRMerchantAccount struct {
+	Tag         string `json:"tag"`
+	GUI         string `json:"gui"`                    // sub-tag 00 (reverse domain of the acquirer/network)
+	MerchantPAN string `json:"merchant_pan,omitempty"` // sub-tag 01
+	MerchantID  string `json:"merchant_id,omitempty"`  // sub-tag 02 (NMID for QRIS)
+	Criteria    string `json:"criteria,omitempty"`     // sub-tag 03 (QRIS: UMI, UKE, UME, UBE)
+}
+
+// EMVQRAdditionalData is the additional data field template (tag 62)
+type EMVQRAdditionalData struct {
+	BillNumber     string `json:"bill_number,omitempty"`     // sub-tag 01
+	MobileNumber   string `json:"mobile_number,omitempty"`   // sub-tag 02
+	StoreLabel     string `json:"store_label,omitempty"`     // sub-tag 03
+	ReferenceLabel string `json:"reference_label,omitempty"` // sub-tag 05
+	TerminalLabel  string `json:"terminal_label,omitempty"`  // sub-tag 07
+	Purpose        string `json:"purpose,omitempty"`         // sub-tag 08
+}
+
+// EMVQR is a decoded merchant-presented QR payload
+type EMVQR struct {
+	PayloadFormat     string                 `json:"payload_format"`
+	PointOfInitiation string                 `json:"point_of_initiation"`
+	MerchantAccounts  []EMVQRMerchantAccount `json:"merchant_accounts"`
+	MCC               string                 `json:"mcc"`
+	Currency          string                 `json:"currency"`
+	Amount            int64                  `json:"amount,omitempty"`
+	TipIndicator      string                 `json:"tip_indicator,omitempty"`
+	FeeFixed          int64                  `json:"fee_fixed,omitempty"`
+	FeePercentage     float64                `json:"fee_percentage,omitempty"`
+	CountryCode       string                 `json:"country_code"`
+	MerchantName      string                 `json:"merchant_name"`
+	MerchantCity      string                 `json:"merchant_city"`
+	PostalCode        string                 `json:"postal_code,omitempty"`
+	AdditionalData    *EMVQRAdditionalData   `json:"additional_data,omitempty"`
+	CRC               string                 `json:"crc"`
+}
+
+// IsDynamic reports whether the QR is for a single payment
+func (q *EMVQR) IsDynamic() bool {
+	return q.PointOfInitiation == EMVQR_DYNAMIC
+}
+
+// FindMerchantAccount returns the merchant account template with the given GUI
+func (q *EMVQR) FindMerchantAccount(gui string) *EMVQRMerchantAccount {
+	for i := range q.MerchantAccounts {
+		if strings.EqualFold(q.MerchantAccounts[i].GUI, gui) {
+			return &q.MerchantAccounts[i]
+		}
+	}
+	return nil
+}
+
+// ConvenienceFee returns the fee the customer must add on top of amount
+func (q *EMVQR) ConvenienceFee(amount int64) int64 {
+	switch q.TipIndicator {
+	case EMVQR_FEE_FIXED:
+		return q.FeeFixed
+	case EMVQR_FEE_PERCENTAGE:
+		return int64(float64(amount) * q.FeePercentage / 100)
+	default:
+		return 0
+	}
+}
+
+// EMVQRField is a single TLV data object
+type EMVQRField struct {
+	Tag   string
+	Value string
+}
+
+// ParseEMVQRFields splits a TLV string into its data objects (two-digit tag, two-digit length)
+func ParseEMVQRFields(data string) ([]EMVQRField, error) {
+	fields := []EMVQRField{}
+	for pos := 0; pos < len(data); {
+		if pos+4 > len(data) {
+			return nil, fmt.Errorf("truncated data object at position %d", pos)
+		}
+		tag := data[pos : pos+2]
+		length, err := strconv.Atoi(data[pos+2 : pos+4])
+		if err != nil || !isDigits(tag) {
+			return nil, fmt.Errorf("invalid tag or length at position %d", pos)
+		}
+		pos += 4
+		if pos+length > len(data) {
+			return nil, fmt.Errorf("value of tag %s exceeds payload length", tag)
+		}
+		fields = append(fields, EMVQRField{Tag: tag, Value: data[pos : pos+length]})
+		pos += length
+	}
+	return fields, nil
+}
+
+// ParseEMVQR decodes and validates an EMVCo MPM payload, including its CRC
+func ParseEMVQR(payload string) (*EMVQR, error) {
+	payload = strings.TrimSpace(payload)
+	if len(payload) < 8 {
+		return nil, errors.New("QR payload is too short")
+	}
+
+	// CRC must be the last data object and covers everything up to and including "6304"
+	crcIndex := len(payload) - 8
+	if payload[crcIndex:crcIndex+4] != EMVQR_TAG_CRC+"04" {
+		return nil, errors.New("QR payload must end with a CRC (tag 63)")
+	}
+	expected := EMVQRChecksum(payload[:crcIndex+4])
+	if !strings.EqualFold(payload[crcIndex+4:], expected) {
+		return nil, errors.New("QR CRC checksum mismatch")
+	}
+
+	fields, err := ParseEMVQRFields(payload)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 || fields[0].Tag != EMVQR_TAG_PAYLOAD_FORMAT {
+		return nil, errors.New("QR payload must start with the payload format indicator (tag 00)")
+	}
+
+	qr := &EMVQR{}
+	seen := map[string]bool{}
+	for _, field := range fields {
+		if seen[field.Tag] {
+			return nil, fmt.Errorf("duplicate tag %s", field.Tag)
+		}
+		seen[field.Tag] = true
+
+		switch tag, _ := strconv.Atoi(field.Tag); {
+		case field.Tag == EMVQR_TAG_PAYLOAD_FORMAT:
+			qr.PayloadFormat = field.Value
+		case field.Tag == EMVQR_TAG_POINT_OF_INITIATION:
+			qr.PointOfInitiation = field.Value
+		case tag >= 2 && tag <= 51:
+			account, err := parseEMVQRMerchantAccount(field)
+			if err != nil {
+				return nil, err
+			}
+			qr.MerchantAccounts = append(qr.MerchantAccounts, *account)
+		case field.Tag == EMVQR_TAG_MCC:
+			qr.MCC = field.Value
+		case field.Tag == EMVQR_TAG_CURRENCY:
+			qr.Currency = field.Value
+		case field.Tag == EMVQR_TAG_AMOUNT:
+			if qr.Amount, err = parseEMVQRAmount(field.Value); err != nil {
+				return nil, fmt.Errorf("invalid transaction amount: %v", err)
+			}
+		case field.Tag == EMVQR_TAG_TIP_INDICATOR:
+			qr.TipIndicator = field.Value
+		case field.Tag == EMVQR_TAG_FEE_FIXED:
+			if qr.FeeFixed, err = parseEMVQRAmount(field.Value); err != nil {
+				return nil, fmt.Errorf("invalid convenience fee: %v", err)
+			}
+		case field.Tag == EMVQR_TAG_FEE_PERCENTAGE:
+			if qr.FeePercentage, err = strconv.ParseFloat(field.Value, 64); err != nil || qr.FeePercentage < 0 || qr.FeePercentage > 100 {
+				return nil, errors.New("invalid convenience fee percentage")
+			}
+		case field.Tag == EMVQR_TAG_COUNTRY:
+			qr.CountryCode = field.Value
+		case field.Tag == EMVQR_TAG_MERCHANT_NAME:
+			qr.MerchantName = field.Value
+		case field.Tag == EMVQR_TAG_MERCHANT_CITY:
+			qr.MerchantCity = field.Value
+		case field.Tag == EMVQR_TAG_POSTAL_CODE:
+			qr.PostalCode = field.Value
+		case field.Tag == EMVQR_TAG_ADDITIONAL_DATA:
+			if qr.AdditionalData, err = parseEMVQRAdditionalData(field.Value); err != nil {
+				return nil, err
+			}
+		case field.Tag == EMVQR_TAG_CRC:
+			qr.CRC = strings.ToUpper(field.Value)
+		}
+	}
+
+	if err := qr.validate(); err != nil {
+		return nil, err
+	}
+	return qr, nil
+}
+
+// validate checks the mandatory data objects of an MPM payload
+func (q *EMVQR) validate() error {
+	switch {
+	case q.PayloadFormat != "01":
+		return errors.New("unsupported payload format indicator")
+	case q.PointOfInitiation != "" && q.PointOfInitiation != EMVQR_STATIC && q.PointOfInitiation != EMVQR_DYNAMIC:
+		return errors.New("invalid point of initiation method")
+	case len(q.MerchantAccounts) == 0:
+		return errors.New("QR has no merchant account information")
+	case len(q.MCC) != 4 || !isDigits(q.MCC):
+		return errors.New("invalid merchant category code")
+	case len(q.Currency) != 3 || !isDigits(q.Currency):
+		return errors.New("invalid transaction currency")
+	case len(q.CountryCode) != 2:
+		return errors.New("invalid country code")
+	case q.MerchantName == "":
+		return errors.New("merchant name is required")
+	case q.MerchantCity == "":
+		return errors.New("merchant city is required")
+	case q.IsDynamic() && q.Amount <= 0:
+		return errors.New("dynamic QR must contain a transaction amount")
+	case q.TipIndicator == EMVQR_FEE_FIXED && q.FeeFixed <= 0:
+		return errors.New("fixed convenience fee is missing")
+	case q.TipIndicator == EMVQR_FEE_PERCENTAGE && q.FeePercentage <= 0:
+		return errors.New("percentage convenience fee is missing")
+	}
+	return nil
+}
+
+func parseEMVQRMerchantAccount(field EMVQRField) (*EMVQRMerchantAccount, error) {
+	account := &EMVQRMerchantAccount{Tag: field.Tag}
+
+	// Tags 02-25 are reserved for card schemes and hold a primitive value
+	if tag, _ := strconv.Atoi(field.Tag); tag < 26 {
+		account.MerchantPAN = field.Value
+		return account, nil
+	}
+
+	subFields, err := ParseEMVQRFields(field.Value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid merchant account template %s: %v", field.Tag, err)
+	}
+	for _, sub := range subFields {
+		switch sub.Tag {
+		case "00":
+			account.GUI = sub.Value
+		case "01":
+			account.MerchantPAN = sub.Value
+		case "02":
+			account.MerchantID = sub.Value
+		case "03":
+			account.Criteria = sub.Value
+		}
+	}
+	if account.GUI == "" {
+		return nil, fmt.Errorf("merchant account template %s has no globally unique identifier", field.Tag)
+	}
+	return account, nil
+}
+
+func parseEMVQRAdditionalData(value string) (*EMVQRAdditionalData, error) {
+	subFields, err := ParseEMVQRFields(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid additional data template: %v", err)
+	}
+
+	data := &EMVQRAdditionalData{}
+	for _, sub := range subFields {
+		switch sub.Tag {
+		case "01":
+			data.BillNumber = sub.Value
+		case "02":
+			data.MobileNumber = sub.Value
+		case "03":
+			data.StoreLabel = sub.Value
+		case "05":
+			data.ReferenceLabel = sub.Value
+		case "07":
+			data.TerminalLabel = sub.Value
+		case "08":
+			data.Purpose = sub.Value
+		}
+	}
+	return data, nil
+}
+
+// parseEMVQRAmount parses an amount in whole IDR; a zero fractional part is accepted
+func parseEMVQRAmount(value string) (int64, error) {
+	whole, fraction, _ := strings.Cut(value, ".")
+	if strings.Trim(fraction, "0") != "" {
+		return 0, errors.New("fractional amounts are not supported")
+	}
+	amount, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || amount < 0 {
+		return 0, errors.New("amount must be a positive number")
+	}
+	return amount, nil
+}
+
+// GenerateEMVQR encodes a QR into its MPM payload string with a trailing CRC
+func GenerateEMVQR(qr *EMVQR) (string, error) {
+	if qr.PayloadFormat == "" {
+		qr.PayloadFormat = "01"
+	}
+	if qr.PointOfInitiation == "" {
+		qr.PointOfInitiation = EMVQR_STATIC
+		if qr.Amount > 0 {
+			qr.PointOfInitiation = EMVQR_DYNAMIC
+		}
+	}
+
+	var b strings.Builder
+	write := func(tag, value string) error {
+		if value == "" {
+			return nil
+		}
+		if len(value) > 99 {
+			return fmt.Errorf("value of tag %s is longer than 99 characters", tag)
+		}
+		fmt.Fprintf(&b, "%s%02d%s", tag, len(value), value)
+		return nil
+	}
+
+	fields := []EMVQRField{
+		{EMVQR_TAG_PAYLOAD_FORMAT, qr.PayloadFormat},
+		{EMVQR_TAG_POINT_OF_INITIATION, qr.PointOfInitiation},
+	}
+
+	accounts := append([]EMVQRMerchantAccount{}, qr.MerchantAccounts...)
+	sort.SliceStable(accounts, func(i, j int) bool { return accounts[i].Tag < accounts[j].Tag })
+	for _, account := range accounts {
+		template, err := encodeEMVQRTemplate([]EMVQRField{
+			{"00", account.GUI},
+			{"01", account.MerchantPAN},
+			{"02", account.MerchantID},
+			{"03", account.Criteria},
+		})
+		if err != nil {
+			return "", err
+		}
+		fields = append(fields, EMVQRField{account.Tag, template})
+	}
+
+	fields = append(fields,
+		EMVQRField{EMVQR_TAG_MCC, qr.MCC},
+		EMVQRField{EMVQR_TAG_CURRENCY, qr.Currency},
+	)
+	if qr.Amount > 0 {
+		fields = append(fields, EMVQRField{EMVQR_TAG_AMOUNT, strconv.FormatInt(qr.Amount, 10)})
+	}
+	fields = append(fields, EMVQRField{EMVQR_TAG_TIP_INDICATOR, qr.TipIndicator})
+	if qr.TipIndicator == EMVQR_FEE_FIXED {
+		fields = append(fields, EMVQRField{EMVQR_TAG_FEE_FIXED, strconv.FormatInt(qr.FeeFixed, 10)})
+	}
+	if qr.TipIndicator == EMVQR_FEE_PERCENTAGE {
+		fields = append(fields, EMVQRField{EMVQR_TAG_FEE_PERCENTAGE, strconv.FormatFloat(qr.FeePercentage, 'f', -1, 64)})
+	}
+	fields = append(fields,
+		EMVQRField{EMVQR_TAG_COUNTRY, qr.CountryCode},
+		EMVQRField{EMVQR_TAG_MERCHANT_NAME, truncateEMVQR(qr.MerchantName, 25)},
+		EMVQRField{EMVQR_TAG_MERCHANT_CITY, truncateEMVQR(qr.MerchantCity, 15)},
+		EMVQRField{EMVQR_TAG_POSTAL_CODE, qr.PostalCode},
+	)
+
+	if data := qr.AdditionalData; data != nil {
+		template, err := encodeEMVQRTemplate([]EMVQRField{
+			{"01", data.BillNumber},
+			{"02", data.MobileNumber},
+			{"03", data.StoreLabel},
+			{"05", data.ReferenceLabel},
+			{"07", data.TerminalLabel},
+			{"08", data.Purpose},
+		})
+		if err != nil {
+			return "", err
+		}
+		fields = append(fields, EMVQRField{EMVQR_TAG_ADDITIONAL_DATA, template})
+	}
+
+	for _, field := range fields {
+		if err := write(field.Tag, field.Value); err != nil {
+			return "", err
+		}
+	}
+
+	b.WriteString(EMVQR_TAG_CRC + "04")
+	payload := b.String()
+	qr.CRC = EMVQRChecksum(payload)
+
+	if err := qr.validate(); err != nil {
+		return "", err
+	}
+	return payload + qr.CRC, nil
+}
+
+func encodeEMVQRTemplate(fields []EMVQRField) (string, error) {
+	var b strings.Builder
+	for _, field := range fields {
+		if field.Value == "" {
+			continue
+		}
+		if len(field.Value) > 99 {
+			return "", fmt.Errorf("value of sub-tag %s is longer than 99 characters", field.Tag)
+		}
+		fmt.Fprintf(&b, "%s%02d%s", field.Tag, len(field.Value), field.Value)
+	}
+	return b.String(), nil
+}
+
+// EMVQRChecksum computes the CRC-16/CCITT-FALSE (poly 0x1021, init 0xFFFF) as four uppercase hex digits
+func EMVQRChecksum(data string) string {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(data); i++ {
+		crc ^= uint16(data[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return fmt.Sprintf("%04X", crc)
+}
+
+func truncateEMVQR(value string, length int) string {
+	if len(value) > length {
+		return value[:length]
+	}
+	return value
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return value != ""
+}
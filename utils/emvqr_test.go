@@ -0,0 +1,228 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEMVQRChecksum(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"empty", "", "FFFF"},
+		{"check value", "123456789", "29B1"},
+		{"single byte", "A", "B915"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EMVQRChecksum(tt.data); got != tt.want {
+				t.Errorf("EMVQRChecksum(%q) = %s, want %s", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseEMVQRFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []EMVQRField
+		wantErr bool
+	}{
+		{"empty", "", []EMVQRField{}, false},
+		{"single field", "000201", []EMVQRField{{"00", "01"}}, false},
+		{"several fields", "00020101021253033605802ID", []EMVQRField{{"00", "01"}, {"01", "12"}, {"53", "360"}, {"58", "ID"}}, false},
+		{"empty value", "0000", []EMVQRField{{"00", ""}}, false},
+		{"truncated header", "000", nil, true},
+		{"non numeric length", "00AB01", nil, true},
+		{"non numeric tag", "A10201", nil, true},
+		{"value past end", "000501", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseEMVQRFields(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseEMVQRFields(%q) error = %v, wantErr %v", tt.data, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseEMVQRFields(%q) = %v, want %v", tt.data, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("field %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// testEMVQR returns a static merchant QR accepted by ParseEMVQR
+func testEMVQR() *EMVQR {
+	return &EMVQR{
+		MerchantAccounts: []EMVQRMerchantAccount{
+			{Tag: "26", GUI: QRIS_ACQUIRER_GUI, MerchantPAN: "9360088800000000017", MerchantID: "M0001", Criteria: "UMI"},
+			{Tag: "51", GUI: QRIS_GUI, MerchantID: "ID1020000000001"},
+		},
+		MCC:          "5812",
+		Currency:     QRIS_CURRENCY_IDR,
+		CountryCode:  QRIS_COUNTRY_ID,
+		MerchantName: "Warung Makan Sederhana",
+		MerchantCity: "Jakarta",
+		PostalCode:   "12190",
+	}
+}
+
+// withEMVQRChecksum replaces the CRC of a payload ending in "6304XXXX" with a valid one
+func withEMVQRChecksum(payload string) string {
+	body := payload[:len(payload)-4]
+	return body + EMVQRChecksum(body)
+}
+
+func TestParseEMVQR(t *testing.T) {
+	static, err := GenerateEMVQR(testEMVQR())
+	if err != nil {
+		t.Fatalf("GenerateEMVQR() error = %v", err)
+	}
+
+	dynamicQR := testEMVQR()
+	dynamicQR.Amount = 25000
+	dynamicQR.TipIndicator = EMVQR_FEE_FIXED
+	dynamicQR.FeeFixed = 1000
+	dynamicQR.AdditionalData = &EMVQRAdditionalData{BillNumber: "INV-001", TerminalLabel: "T01"}
+	dynamic, err := GenerateEMVQR(dynamicQR)
+	if err != nil {
+		t.Fatalf("GenerateEMVQR() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		payload string
+		check   func(t *testing.T, qr *EMVQR)
+		wantErr string
+	}{
+		{
+			name:    "static",
+			payload: static,
+			check: func(t *testing.T, qr *EMVQR) {
+				if qr.IsDynamic() || qr.Amount != 0 {
+					t.Errorf("static QR parsed as dynamic with amount %d", qr.Amount)
+				}
+				account := qr.FindMerchantAccount(QRIS_ACQUIRER_GUI)
+				if account == nil || account.MerchantID != "M0001" || account.Criteria != "UMI" {
+					t.Errorf("acquirer account = %+v", account)
+				}
+				if qr.MerchantName != "Warung Makan Sederhana" || qr.MerchantCity != "Jakarta" || qr.PostalCode != "12190" {
+					t.Errorf("merchant = %q, %q, %q", qr.MerchantName, qr.MerchantCity, qr.PostalCode)
+				}
+			},
+		},
+		{
+			name:    "lowercase CRC",
+			payload: static[:len(static)-4] + strings.ToLower(static[len(static)-4:]),
+		},
+		{
+			name:    "dynamic with fee and additional data",
+			payload: dynamic,
+			check: func(t *testing.T, qr *EMVQR) {
+				if !qr.IsDynamic() || qr.Amount != 25000 {
+					t.Errorf("dynamic QR amount = %d, dynamic = %v", qr.Amount, qr.IsDynamic())
+				}
+				if fee := qr.ConvenienceFee(qr.Amount); fee != 1000 {
+					t.Errorf("ConvenienceFee() = %d, want 1000", fee)
+				}
+				if qr.AdditionalData == nil || qr.AdditionalData.BillNumber != "INV-001" || qr.AdditionalData.TerminalLabel != "T01" {
+					t.Errorf("additional data = %+v", qr.AdditionalData)
+				}
+			},
+		},
+		{
+			name:    "too short",
+			payload: "6304",
+			wantErr: "too short",
+		},
+		{
+			name:    "CRC not last",
+			payload: static + "9900",
+			wantErr: "must end with a CRC",
+		},
+		{
+			name:    "CRC mismatch",
+			payload: static[:len(static)-4] + "0000",
+			wantErr: "checksum mismatch",
+		},
+		{
+			name:    "missing payload format",
+			payload: withEMVQRChecksum("010211" + static[12:]),
+			wantErr: "payload format indicator",
+		},
+		{
+			name:    "duplicate tag",
+			payload: withEMVQRChecksum("000201" + static[6:len(static)-8] + "5802ID6304XXXX"),
+			wantErr: "duplicate tag 58",
+		},
+		{
+			name:    "dynamic without amount",
+			payload: withEMVQRChecksum("000201010212" + static[12:]),
+			wantErr: "must contain a transaction amount",
+		},
+		{
+			name:    "fractional amount",
+			payload: withEMVQRChecksum(strings.Replace(dynamic, "540525000", "5408250.5000", 1)),
+			wantErr: "invalid transaction amount",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qr, err := ParseEMVQR(tt.payload)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseEMVQR() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseEMVQR() error = %v", err)
+			}
+			if tt.check != nil {
+				tt.check(t, qr)
+			}
+		})
+	}
+}
+
+func TestParseEMVQRAmount(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{"25000", 25000, false},
+		{"25000.00", 25000, false},
+		{"25000.", 25000, false},
+		{"0", 0, false},
+		{"25000.50", 0, true},
+		{"-1", 0, true},
+		{"abc", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseEMVQRAmount(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseEMVQRAmount(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseEMVQRAmount(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
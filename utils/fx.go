@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// FX rates are stored as integers scaled by FX_RATE_SCALE: base currency major units per 1 major unit
+// of the foreign currency, e.g. USD 16250.5 IDR is stored as 16250500000.
+const (
+	FX_RATE_DECIMALS = 6
+	FX_RATE_SCALE    = 1000000
+)
+
+// ParseFXRate parses a decimal rate such as "16250.50" into its scaled integer form
+func ParseFXRate(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	parts := strings.SplitN(value, ".", 2)
+	if parts[0] == "" || !isDigits(parts[0]) {
+		return 0, errors.New("rate must be a positive decimal number")
+	}
+
+	fraction := ""
+	if len(parts) == 2 {
+		fraction = parts[1]
+		if fraction == "" || !isDigits(fraction) {
+			return 0, errors.New("rate must be a positive decimal number")
+		}
+		if len(fraction) > FX_RATE_DECIMALS {
+			return 0, errors.New("rate has too many decimal places")
+		}
+	}
+	fraction += strings.Repeat("0", FX_RATE_DECIMALS-len(fraction))
+
+	scaled, ok := new(big.Int).SetString(parts[0]+fraction, 10)
+	if !ok || !scaled.IsInt64() || scaled.Sign() <= 0 {
+		return 0, errors.New("rate is out of range")
+	}
+	return scaled.Int64(), nil
+}
+
+// FormatFXRate formats a scaled rate as a decimal string
+func FormatFXRate(rate int64) string {
+	return new(big.Rat).SetFrac64(rate, FX_RATE_SCALE).FloatString(FX_RATE_DECIMALS)
+}
+
+// FXConversion converts an amount in minor units between currencies through the base currency.
+// A zero rate means that side is the base currency itself.
+type FXConversion struct {
+	FromMinorUnits int
+	ToMinorUnits   int
+	FromRate       int64 // Rate at which the bank buys the source currency (0 when it is the base currency)
+	ToRate         int64 // Rate at which the bank sells the target currency (0 when it is the base currency)
+}
+
+// Rate returns the effective rate as target major units per 1 source major unit
+func (f FXConversion) Rate() *big.Rat {
+	rate := big.NewRat(1, 1)
+	if f.FromRate != 0 {
+		rate.Mul(rate, big.NewRat(f.FromRate, FX_RATE_SCALE))
+	}
+	if f.ToRate != 0 {
+		rate.Quo(rate, big.NewRat(f.ToRate, FX_RATE_SCALE))
+	}
+	return rate
+}
+
+// RateString returns the effective rate formatted for transaction records. It keeps extra decimals
+// because rates from the base currency are small (e.g. IDR to USD 0.0000615385).
+func (f FXConversion) RateString() string {
+	return f.Rate().FloatString(10)
+}
+
+// Convert converts amount (source minor units) to target minor units, rounding down
+func (f FXConversion) Convert(amount int64) (int64, error) {
+	value := new(big.Rat).SetInt64(amount)
+	value.Quo(value, pow10Rat(f.FromMinorUnits))
+	value.Mul(value, f.Rate())
+	value.Mul(value, pow10Rat(f.ToMinorUnits))
+
+	converted := new(big.Int).Quo(value.Num(), value.Denom())
+	if !converted.IsInt64() {
+		return 0, errors.New("converted amount is out of range")
+	}
+	return converted.Int64(), nil
+}
+
+func pow10Rat(n int) *big.Rat {
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil))
+}
@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// goAML report codes and lookups used for suspicious transaction reports (LTKM) to PPATK
+const (
+	GOAML_REPORT_CODE_STR       = "LTKM" // Laporan Transaksi Keuangan Mencurigakan
+	GOAML_SUBMISSION_ELECTRONIC = "E"
+	GOAML_FUNDS_CASH            = "K" // Tunai
+	GOAML_FUNDS_TRANSFER        = "T" // Transfer
+	GOAML_FUNDS_OTHER           = "L" // Lainnya
+	GOAML_COUNTRY_INDONESIA     = "ID"
+)
+
+// GoAMLRentityID returns the reporting entity ID assigned by the regulator (env GOAML_RENTITY_ID)
+func GoAMLRentityID() string {
+	if id := os.Getenv("GOAML_RENTITY_ID"); id != "" {
+		return id
+	}
+	return "0"
+}
+
+// GoAMLReport is the root element of a goAML report
+type GoAMLReport struct {
+	XMLName           xml.Name           `xml:"report"`
+	RentityID         string             `xml:"rentity_id"`
+	SubmissionCode    string             `xml:"submission_code"`
+	ReportCode        string             `xml:"report_code"`
+	EntityReference   string             `xml:"entity_reference"`
+	SubmissionDate    string             `xml:"submission_date"`
+	CurrencyCodeLocal string             `xml:"currency_code_local"`
+	ReportingPerson   GoAMLPerson        `xml:"reporting_person"`
+	Reason            string             `xml:"reason"`
+	Action            string             `xml:"action"`
+	Transactions      []GoAMLTransaction `xml:"transaction"`
+	ReportIndicators  []string           `xml:"report_indicators>indicator"`
+}
+
+// GoAMLPerson is a natural person (t_person)
+type GoAMLPerson struct {
+	FirstName string `xml:"first_name"`
+	LastName  string `xml:"last_name"`
+	Email     string `xml:"email,omitempty"`
+}
+
+// GoAMLSignatory is the holder of an account
+type GoAMLSignatory struct {
+	IsPrimary bool        `xml:"is_primary"`
+	Person    GoAMLPerson `xml:"t_person"`
+}
+
+// GoAMLAccount is a bank account (t_account)
+type GoAMLAccount struct {
+	InstitutionName string          `xml:"institution_name"`
+	Account         string          `xml:"account"`
+	CurrencyCode    string          `xml:"currency_code"`
+	Signatory       *GoAMLSignatory `xml:"signatory,omitempty"`
+}
+
+// GoAMLFrom is the source side of a transaction
+type GoAMLFrom struct {
+	FundsCode string        `xml:"from_funds_code"`
+	Account   *GoAMLAccount `xml:"from_account,omitempty"`
+	Person    *GoAMLPerson  `xml:"from_person,omitempty"`
+	Country   string        `xml:"from_country"`
+}
+
+// GoAMLTo is the destination side of a transaction
+type GoAMLTo struct {
+	FundsCode string        `xml:"to_funds_code"`
+	Account   *GoAMLAccount `xml:"to_account,omitempty"`
+	Person    *GoAMLPerson  `xml:"to_person,omitempty"`
+	Country   string        `xml:"to_country"`
+}
+
+// GoAMLTransaction is one reported transaction. The reporting institution's customer appears on
+// the _my_client side.
+type GoAMLTransaction struct {
+	TransactionNumber      string     `xml:"transactionnumber"`
+	InternalRefNumber      string     `xml:"internal_ref_number"`
+	TransactionLocation    string     `xml:"transaction_location"`
+	TransactionDescription string     `xml:"transaction_description"`
+	DateTransaction        string     `xml:"date_transaction"`
+	TransmodeCode          string     `xml:"transmode_code"`
+	AmountLocal            string     `xml:"amount_local"`
+	FromMyClient           *GoAMLFrom `xml:"t_from_my_client,omitempty"`
+	From                   *GoAMLFrom `xml:"t_from,omitempty"`
+	ToMyClient             *GoAMLTo   `xml:"t_to_my_client,omitempty"`
+	To                     *GoAMLTo   `xml:"t_to,omitempty"`
+}
+
+// FormatGoAMLDateTime formats a time as goAML expects (local time, no zone)
+func FormatGoAMLDateTime(t time.Time) string {
+	return t.Format("2006-01-02T15:04:05")
+}
+
+// FormatGoAMLAmount formats an amount in whole rupiah with two decimals
+func FormatGoAMLAmount(amount int64) string {
+	return fmt.Sprintf("%d.00", amount)
+}
+
+// SplitGoAMLName splits a full name into the first and last name elements
+func SplitGoAMLName(fullName string) GoAMLPerson {
+	parts := strings.Fields(fullName)
+	switch len(parts) {
+	case 0:
+		return GoAMLPerson{}
+	case 1:
+		// Single names are common in Indonesia; goAML still requires a last name
+		return GoAMLPerson{FirstName: parts[0], LastName: parts[0]}
+	default:
+		return GoAMLPerson{FirstName: strings.Join(parts[:len(parts)-1], " "), LastName: parts[len(parts)-1]}
+	}
+}
+
+// EncodeGoAMLReport marshals a report with the XML declaration and indentation
+func EncodeGoAMLReport(report *GoAMLReport) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
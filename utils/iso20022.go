@@ -0,0 +1,539 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ISO 20022 message definitions supported by the encoder/decoder
+const (
+	ISO20022_PAIN001 = "pain.001.001.09" // Customer Credit Transfer Initiation
+	ISO20022_PACS008 = "pacs.008.001.08" // FI To FI Customer Credit Transfer
+	ISO20022_PACS002 = "pacs.002.001.10" // FI To FI Payment Status Report
+)
+
+// ISO 20022 transaction/group status codes (ExternalPaymentTransactionStatus1Code)
+const (
+	ISO20022_STATUS_ACCEPTED_SETTLED = "ACSC" // settlement completed
+	ISO20022_STATUS_ACCEPTED         = "ACSP" // accepted, settlement in process
+	ISO20022_STATUS_PENDING          = "PDNG" // pending
+	ISO20022_STATUS_PARTIAL          = "PART" // some transactions accepted, some rejected
+	ISO20022_STATUS_REJECTED         = "RJCT" // rejected
+)
+
+// ISO20022Namespace returns the XML namespace of a message definition
+func ISO20022Namespace(messageType string) string {
+	return "urn:iso:std:iso:20022:tech:xsd:" + messageType
+}
+
+var (
+	iso20022MaxText35 = regexp.MustCompile(`^.{1,35}$`)
+	iso20022Currency  = regexp.MustCompile(`^[A-Z]{3}$`)
+	iso20022BIC       = regexp.MustCompile(`^[A-Z0-9]{4}[A-Z]{2}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+	iso20022IBAN      = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[a-zA-Z0-9]{1,30}$`)
+	iso20022Amount    = regexp.MustCompile(`^[0-9]{1,18}(\.[0-9]{1,5})?$`)
+)
+
+// Common components
+
+type ISO20022Amount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type ISO20022Party struct {
+	Name string `xml:"Nm,omitempty"`
+}
+
+type ISO20022OtherAccountID struct {
+	ID string `xml:"Id"`
+}
+
+type ISO20022AccountID struct {
+	IBAN  string                  `xml:"IBAN,omitempty"`
+	Other *ISO20022OtherAccountID `xml:"Othr,omitempty"`
+}
+
+type ISO20022Account struct {
+	ID ISO20022AccountID `xml:"Id"`
+}
+
+// AccountNumber returns the IBAN or proprietary account number
+func (a *ISO20022Account) AccountNumber() string {
+	if a == nil {
+		return ""
+	}
+	if a.ID.IBAN != "" {
+		return a.ID.IBAN
+	}
+	if a.ID.Other != nil {
+		return a.ID.Other.ID
+	}
+	return ""
+}
+
+type ISO20022ClearingMember struct {
+	MemberID string `xml:"MmbId"`
+}
+
+type ISO20022FinancialInstitution struct {
+	BICFI            string                  `xml:"BICFI,omitempty"`
+	ClearingMemberID *ISO20022ClearingMember `xml:"ClrSysMmbId,omitempty"`
+}
+
+type ISO20022Agent struct {
+	FinInstnID ISO20022FinancialInstitution `xml:"FinInstnId"`
+}
+
+type ISO20022Remittance struct {
+	Unstructured string `xml:"Ustrd,omitempty"`
+}
+
+type ISO20022PaymentID struct {
+	InstructionID string `xml:"InstrId,omitempty"`
+	EndToEndID    string `xml:"EndToEndId"`
+	TransactionID string `xml:"TxId,omitempty"`
+}
+
+// pain.001.001.09 - Customer Credit Transfer Initiation
+
+type Pain001Document struct {
+	XMLName  xml.Name                `xml:"Document"`
+	Xmlns    string                  `xml:"xmlns,attr,omitempty"`
+	Initiate Pain001CstmrCdtTrfInitn `xml:"CstmrCdtTrfInitn"`
+}
+
+type Pain001CstmrCdtTrfInitn struct {
+	GroupHeader Pain001GroupHeader          `xml:"GrpHdr"`
+	PaymentInfo []Pain001PaymentInstruction `xml:"PmtInf"`
+}
+
+type Pain001GroupHeader struct {
+	MessageID        string        `xml:"MsgId"`
+	CreationDateTime string        `xml:"CreDtTm"`
+	NumberOfTxs      string        `xml:"NbOfTxs"`
+	ControlSum       string        `xml:"CtrlSum,omitempty"`
+	InitiatingParty  ISO20022Party `xml:"InitgPty"`
+}
+
+type Pain001PaymentInstruction struct {
+	PaymentInfoID   string                      `xml:"PmtInfId"`
+	PaymentMethod   string                      `xml:"PmtMtd"`
+	NumberOfTxs     string                      `xml:"NbOfTxs,omitempty"`
+	ControlSum      string                      `xml:"CtrlSum,omitempty"`
+	Debtor          ISO20022Party               `xml:"Dbtr"`
+	DebtorAccount   ISO20022Account             `xml:"DbtrAcct"`
+	DebtorAgent     ISO20022Agent               `xml:"DbtrAgt"`
+	CreditTransfers []Pain001CreditTransferInfo `xml:"CdtTrfTxInf"`
+}
+
+type Pain001CreditTransferInfo struct {
+	PaymentID ISO20022PaymentID `xml:"PmtId"`
+	Amount    struct {
+		InstructedAmount ISO20022Amount `xml:"InstdAmt"`
+	} `xml:"Amt"`
+	CreditorAgent   *ISO20022Agent      `xml:"CdtrAgt,omitempty"`
+	Creditor        ISO20022Party       `xml:"Cdtr"`
+	CreditorAccount ISO20022Account     `xml:"CdtrAcct"`
+	Remittance      *ISO20022Remittance `xml:"RmtInf,omitempty"`
+}
+
+// pacs.008.001.08 - FI To FI Customer Credit Transfer
+
+type Pacs008Document struct {
+	XMLName  xml.Name                 `xml:"Document"`
+	Xmlns    string                   `xml:"xmlns,attr,omitempty"`
+	Transfer Pacs008FIToFICstmrCdtTrf `xml:"FIToFICstmrCdtTrf"`
+}
+
+type Pacs008FIToFICstmrCdtTrf struct {
+	GroupHeader     Pacs008GroupHeader          `xml:"GrpHdr"`
+	CreditTransfers []Pacs008CreditTransferInfo `xml:"CdtTrfTxInf"`
+}
+
+type Pacs008GroupHeader struct {
+	MessageID                   string         `xml:"MsgId"`
+	CreationDateTime            string         `xml:"CreDtTm"`
+	NumberOfTxs                 string         `xml:"NbOfTxs"`
+	TotalInterbankSettlementAmt ISO20022Amount `xml:"TtlIntrBkSttlmAmt"`
+	InterbankSettlementDate     string         `xml:"IntrBkSttlmDt"`
+	SettlementInfo              struct {
+		SettlementMethod string `xml:"SttlmMtd"`
+	} `xml:"SttlmInf"`
+}
+
+type Pacs008CreditTransferInfo struct {
+	PaymentID              ISO20022PaymentID   `xml:"PmtId"`
+	InterbankSettlementAmt ISO20022Amount      `xml:"IntrBkSttlmAmt"`
+	ChargeBearer           string              `xml:"ChrgBr"`
+	Debtor                 ISO20022Party       `xml:"Dbtr"`
+	DebtorAccount          ISO20022Account     `xml:"DbtrAcct"`
+	DebtorAgent            ISO20022Agent       `xml:"DbtrAgt"`
+	CreditorAgent          ISO20022Agent       `xml:"CdtrAgt"`
+	Creditor               ISO20022Party       `xml:"Cdtr"`
+	CreditorAccount        ISO20022Account     `xml:"CdtrAcct"`
+	Remittance             *ISO20022Remittance `xml:"RmtInf,omitempty"`
+}
+
+// pacs.002.001.10 - FI To FI Payment Status Report
+
+type Pacs002Document struct {
+	XMLName xml.Name               `xml:"Document"`
+	Xmlns   string                 `xml:"xmlns,attr,omitempty"`
+	Report  Pacs002FIToFIPmtStsRpt `xml:"FIToFIPmtStsRpt"`
+}
+
+type Pacs002FIToFIPmtStsRpt struct {
+	GroupHeader         Pacs002GroupHeader         `xml:"GrpHdr"`
+	OriginalGroupInfo   Pacs002OriginalGroupInfo   `xml:"OrgnlGrpInfAndSts"`
+	TransactionStatuses []Pacs002TransactionStatus `xml:"TxInfAndSts,omitempty"`
+}
+
+type Pacs002GroupHeader struct {
+	MessageID        string `xml:"MsgId"`
+	CreationDateTime string `xml:"CreDtTm"`
+}
+
+type Pacs002OriginalGroupInfo struct {
+	OriginalMessageID     string `xml:"OrgnlMsgId"`
+	OriginalMessageNameID string `xml:"OrgnlMsgNmId"`
+	OriginalNumberOfTxs   string `xml:"OrgnlNbOfTxs,omitempty"`
+	GroupStatus           string `xml:"GrpSts,omitempty"`
+}
+
+type Pacs002TransactionStatus struct {
+	OriginalInstructionID string               `xml:"OrgnlInstrId,omitempty"`
+	OriginalEndToEndID    string               `xml:"OrgnlEndToEndId"`
+	OriginalTransactionID string               `xml:"OrgnlTxId,omitempty"`
+	TransactionStatus     string               `xml:"TxSts"`
+	StatusReason          *Pacs002StatusReason `xml:"StsRsnInf,omitempty"`
+	AcceptanceDateTime    string               `xml:"AccptncDtTm,omitempty"`
+}
+
+type Pacs002StatusReason struct {
+	Reason struct {
+		Code string `xml:"Cd"`
+	} `xml:"Rsn"`
+	AdditionalInfo string `xml:"AddtlInf,omitempty"`
+}
+
+// NewPacs002StatusReason builds a status reason with an ISO external reason code
+func NewPacs002StatusReason(code, additionalInfo string) *Pacs002StatusReason {
+	reason := &Pacs002StatusReason{AdditionalInfo: truncateISO20022(additionalInfo, 105)}
+	reason.Reason.Code = code
+	return reason
+}
+
+// Encoding and decoding
+
+// EncodeISO20022 marshals a document with the XML declaration and indentation
+func EncodeISO20022(document interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// DecodePain001 parses and validates a pain.001 document.
+// Validation errors are returned as a list so every problem can be reported at once.
+func DecodePain001(data []byte) (*Pain001Document, []string) {
+	var document Pain001Document
+	if err := xml.Unmarshal(data, &document); err != nil {
+		return nil, []string{"Malformed XML: " + err.Error()}
+	}
+
+	if errs := ValidatePain001(&document, document.XMLName.Space); len(errs) > 0 {
+		return nil, errs
+	}
+	return &document, nil
+}
+
+// DecodePacs008 parses and validates a pacs.008 document
+func DecodePacs008(data []byte) (*Pacs008Document, []string) {
+	var document Pacs008Document
+	if err := xml.Unmarshal(data, &document); err != nil {
+		return nil, []string{"Malformed XML: " + err.Error()}
+	}
+
+	if errs := ValidatePacs008(&document, document.XMLName.Space); len(errs) > 0 {
+		return nil, errs
+	}
+	return &document, nil
+}
+
+// Schema validation
+//
+// The checks below cover the constraints of the XSDs that matter for processing:
+// namespace, mandatory elements, Max35Text identifiers, ISODateTime/ISODate,
+// currency codes, decimal amounts, BIC/IBAN patterns and NbOfTxs/CtrlSum consistency.
+
+type iso20022Validator struct {
+	errors []string
+}
+
+func (v *iso20022Validator) addf(format string, args ...interface{}) {
+	v.errors = append(v.errors, fmt.Sprintf(format, args...))
+}
+
+func (v *iso20022Validator) required(path, value string) bool {
+	if strings.TrimSpace(value) == "" {
+		v.addf("%s is required", path)
+		return false
+	}
+	return true
+}
+
+func (v *iso20022Validator) max35(path, value string) {
+	if v.required(path, value) && !iso20022MaxText35.MatchString(value) {
+		v.addf("%s must be at most 35 characters", path)
+	}
+}
+
+func (v *iso20022Validator) dateTime(path, value string) {
+	if !v.required(path, value) {
+		return
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02T15:04:05.999999999"} {
+		if _, err := time.Parse(layout, value); err == nil {
+			return
+		}
+	}
+	v.addf("%s must be an ISODateTime", path)
+}
+
+func (v *iso20022Validator) date(path, value string) {
+	if !v.required(path, value) {
+		return
+	}
+	if _, err := time.Parse("2006-01-02", value); err != nil {
+		v.addf("%s must be an ISODate", path)
+	}
+}
+
+func (v *iso20022Validator) amount(path string, amount ISO20022Amount) {
+	if !iso20022Currency.MatchString(amount.Currency) {
+		v.addf("%s/@Ccy must be an ISO 4217 currency code", path)
+	}
+	value := strings.TrimSpace(amount.Value)
+	if !iso20022Amount.MatchString(value) {
+		v.addf("%s must be a decimal amount", path)
+		return
+	}
+	if parsed, _ := strconv.ParseFloat(value, 64); parsed <= 0 {
+		v.addf("%s must be greater than zero", path)
+	}
+}
+
+func (v *iso20022Validator) account(path string, account ISO20022Account) {
+	switch {
+	case account.ID.IBAN != "":
+		if !iso20022IBAN.MatchString(account.ID.IBAN) {
+			v.addf("%s/Id/IBAN is not a valid IBAN", path)
+		}
+	case account.ID.Other != nil:
+		if v.required(path+"/Id/Othr/Id", account.ID.Other.ID) && len(account.ID.Other.ID) > 34 {
+			v.addf("%s/Id/Othr/Id must be at most 34 characters", path)
+		}
+	default:
+		v.addf("%s/Id must contain IBAN or Othr", path)
+	}
+}
+
+func (v *iso20022Validator) agent(path string, agent ISO20022Agent) {
+	switch {
+	case agent.FinInstnID.BICFI != "":
+		if !iso20022BIC.MatchString(agent.FinInstnID.BICFI) {
+			v.addf("%s/FinInstnId/BICFI is not a valid BIC", path)
+		}
+	case agent.FinInstnID.ClearingMemberID != nil:
+		v.max35(path+"/FinInstnId/ClrSysMmbId/MmbId", agent.FinInstnID.ClearingMemberID.MemberID)
+	default:
+		v.addf("%s/FinInstnId must contain BICFI or ClrSysMmbId", path)
+	}
+}
+
+func (v *iso20022Validator) namespace(expected, actual string) {
+	if actual != ISO20022Namespace(expected) {
+		v.addf("Document namespace must be %s (got %q)", ISO20022Namespace(expected), actual)
+	}
+}
+
+func (v *iso20022Validator) count(path, declared string, actual int) {
+	if !v.required(path, declared) {
+		return
+	}
+	n, err := strconv.Atoi(declared)
+	if err != nil {
+		v.addf("%s must be numeric", path)
+		return
+	}
+	if n != actual {
+		v.addf("%s is %d but message contains %d transactions", path, n, actual)
+	}
+}
+
+func (v *iso20022Validator) controlSum(path, declared string, amounts []ISO20022Amount) {
+	if declared == "" {
+		return
+	}
+	expected, err := ParseISO20022Amount(declared)
+	if err != nil {
+		v.addf("%s must be a decimal amount", path)
+		return
+	}
+	var total int64
+	for _, amount := range amounts {
+		value, err := ParseISO20022Amount(amount.Value)
+		if err != nil {
+			return // reported on the individual amount
+		}
+		total += value
+	}
+	if total != expected {
+		v.addf("%s is %s but transactions sum to %d", path, declared, total)
+	}
+}
+
+// ValidatePain001 checks a decoded pain.001 against the schema constraints
+func ValidatePain001(document *Pain001Document, namespace string) []string {
+	v := &iso20022Validator{}
+	v.namespace(ISO20022_PAIN001, namespace)
+
+	header := document.Initiate.GroupHeader
+	v.max35("GrpHdr/MsgId", header.MessageID)
+	v.dateTime("GrpHdr/CreDtTm", header.CreationDateTime)
+
+	if len(document.Initiate.PaymentInfo) == 0 {
+		v.addf("PmtInf is required")
+	}
+
+	allAmounts := []ISO20022Amount{}
+	for i, payment := range document.Initiate.PaymentInfo {
+		path := fmt.Sprintf("PmtInf[%d]", i+1)
+		v.max35(path+"/PmtInfId", payment.PaymentInfoID)
+		if payment.PaymentMethod != "TRF" {
+			v.addf("%s/PmtMtd must be TRF", path)
+		}
+		v.account(path+"/DbtrAcct", payment.DebtorAccount)
+		v.agent(path+"/DbtrAgt", payment.DebtorAgent)
+
+		if len(payment.CreditTransfers) == 0 {
+			v.addf("%s/CdtTrfTxInf is required", path)
+		}
+
+		amounts := []ISO20022Amount{}
+		for j, transfer := range payment.CreditTransfers {
+			txPath := fmt.Sprintf("%s/CdtTrfTxInf[%d]", path, j+1)
+			v.max35(txPath+"/PmtId/EndToEndId", transfer.PaymentID.EndToEndID)
+			v.amount(txPath+"/Amt/InstdAmt", transfer.Amount.InstructedAmount)
+			v.account(txPath+"/CdtrAcct", transfer.CreditorAccount)
+			if transfer.CreditorAgent != nil {
+				v.agent(txPath+"/CdtrAgt", *transfer.CreditorAgent)
+			}
+			if transfer.Remittance != nil && len(transfer.Remittance.Unstructured) > 140 {
+				v.addf("%s/RmtInf/Ustrd must be at most 140 characters", txPath)
+			}
+			amounts = append(amounts, transfer.Amount.InstructedAmount)
+		}
+
+		if payment.NumberOfTxs != "" {
+			v.count(path+"/NbOfTxs", payment.NumberOfTxs, len(payment.CreditTransfers))
+		}
+		v.controlSum(path+"/CtrlSum", payment.ControlSum, amounts)
+		allAmounts = append(allAmounts, amounts...)
+	}
+
+	v.count("GrpHdr/NbOfTxs", header.NumberOfTxs, len(allAmounts))
+	v.controlSum("GrpHdr/CtrlSum", header.ControlSum, allAmounts)
+
+	return v.errors
+}
+
+// ValidatePacs008 checks a pacs.008 against the schema constraints
+func ValidatePacs008(document *Pacs008Document, namespace string) []string {
+	v := &iso20022Validator{}
+	v.namespace(ISO20022_PACS008, namespace)
+
+	header := document.Transfer.GroupHeader
+	v.max35("GrpHdr/MsgId", header.MessageID)
+	v.dateTime("GrpHdr/CreDtTm", header.CreationDateTime)
+	v.date("GrpHdr/IntrBkSttlmDt", header.InterbankSettlementDate)
+	switch header.SettlementInfo.SettlementMethod {
+	case "INDA", "INGA", "COVE", "CLRG":
+	default:
+		v.addf("GrpHdr/SttlmInf/SttlmMtd must be one of INDA, INGA, COVE, CLRG")
+	}
+
+	if len(document.Transfer.CreditTransfers) == 0 {
+		v.addf("CdtTrfTxInf is required")
+	}
+
+	amounts := []ISO20022Amount{}
+	for i, transfer := range document.Transfer.CreditTransfers {
+		path := fmt.Sprintf("CdtTrfTxInf[%d]", i+1)
+		v.max35(path+"/PmtId/EndToEndId", transfer.PaymentID.EndToEndID)
+		v.amount(path+"/IntrBkSttlmAmt", transfer.InterbankSettlementAmt)
+		v.account(path+"/DbtrAcct", transfer.DebtorAccount)
+		v.agent(path+"/DbtrAgt", transfer.DebtorAgent)
+		v.agent(path+"/CdtrAgt", transfer.CreditorAgent)
+		v.account(path+"/CdtrAcct", transfer.CreditorAccount)
+		switch transfer.ChargeBearer {
+		case "DEBT", "CRED", "SHAR", "SLEV":
+		default:
+			v.addf("%s/ChrgBr must be one of DEBT, CRED, SHAR, SLEV", path)
+		}
+		amounts = append(amounts, transfer.InterbankSettlementAmt)
+	}
+
+	v.count("GrpHdr/NbOfTxs", header.NumberOfTxs, len(amounts))
+	v.amount("GrpHdr/TtlIntrBkSttlmAmt", header.TotalInterbankSettlementAmt)
+	v.controlSum("GrpHdr/TtlIntrBkSttlmAmt", header.TotalInterbankSettlementAmt.Value, amounts)
+
+	return v.errors
+}
+
+// Amount helpers (IDR amounts are whole numbers in this system)
+
+// FormatISO20022Amount formats a whole-unit amount as an ISO 20022 decimal
+func FormatISO20022Amount(amount int64) string {
+	return strconv.FormatInt(amount, 10) + ".00"
+}
+
+// ParseISO20022Amount parses an ISO 20022 decimal into whole units.
+// Amounts with a non-zero fractional part are rejected.
+func ParseISO20022Amount(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	whole, fraction, _ := strings.Cut(value, ".")
+	if strings.Trim(fraction, "0") != "" {
+		return 0, fmt.Errorf("amount %s has a fractional part", value)
+	}
+	return strconv.ParseInt(whole, 10, 64)
+}
+
+// FormatISO20022DateTime formats a timestamp as ISODateTime
+func FormatISO20022DateTime(t time.Time) string {
+	return t.Format("2006-01-02T15:04:05.000Z07:00")
+}
+
+// NewISO20022MessageID generates a unique MsgId (Max35Text)
+func NewISO20022MessageID(prefix string) string {
+	return truncateISO20022(GenerateReference(prefix), 35)
+}
+
+func truncateISO20022(value string, length int) string {
+	if len(value) > length {
+		return value[:length]
+	}
+	return value
+}
@@ -0,0 +1,222 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+const testPain001 = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.09">
+  <CstmrCdtTrfInitn>
+    <GrpHdr>
+      <MsgId>MSG-0001</MsgId>
+      <CreDtTm>2024-05-01T09:30:00</CreDtTm>
+      <NbOfTxs>2</NbOfTxs>
+      <CtrlSum>350000.00</CtrlSum>
+      <InitgPty><Nm>PT Contoh</Nm></InitgPty>
+    </GrpHdr>
+    <PmtInf>
+      <PmtInfId>PMT-0001</PmtInfId>
+      <PmtMtd>TRF</PmtMtd>
+      <NbOfTxs>2</NbOfTxs>
+      <Dbtr><Nm>PT Contoh</Nm></Dbtr>
+      <DbtrAcct><Id><Othr><Id>1234567890123456</Id></Othr></Id></DbtrAcct>
+      <DbtrAgt><FinInstnId><BICFI>MBCOIDJA</BICFI></FinInstnId></DbtrAgt>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>E2E-0001</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="IDR">150000.00</InstdAmt></Amt>
+        <Cdtr><Nm>Budi</Nm></Cdtr>
+        <CdtrAcct><Id><Othr><Id>6543210987654321</Id></Othr></Id></CdtrAcct>
+      </CdtTrfTxInf>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>E2E-0002</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="IDR">200000</InstdAmt></Amt>
+        <CdtrAgt><FinInstnId><BICFI>BMRIIDJA</BICFI></FinInstnId></CdtrAgt>
+        <Cdtr><Nm>Siti</Nm></Cdtr>
+        <CdtrAcct><Id><IBAN>ID12BMRI0000123456</IBAN></Id></CdtrAcct>
+      </CdtTrfTxInf>
+    </PmtInf>
+  </CstmrCdtTrfInitn>
+</Document>`
+
+func TestDecodePain001(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string // replacement applied to testPain001
+		wantErrs []string
+	}{
+		{name: "valid"},
+		{name: "malformed XML", old: "</Document>", new: "", wantErrs: []string{"Malformed XML"}},
+		{name: "wrong namespace", old: "pain.001.001.09", new: "pain.001.001.03", wantErrs: []string{"Document namespace must be"}},
+		{name: "missing message id", old: "<MsgId>MSG-0001</MsgId>", new: "", wantErrs: []string{"GrpHdr/MsgId is required"}},
+		{name: "message id too long", old: "MSG-0001", new: strings.Repeat("M", 36), wantErrs: []string{"GrpHdr/MsgId must be at most 35 characters"}},
+		{name: "bad creation time", old: "2024-05-01T09:30:00", new: "01/05/2024", wantErrs: []string{"GrpHdr/CreDtTm must be an ISODateTime"}},
+		{name: "creation time with zone", old: "2024-05-01T09:30:00", new: "2024-05-01T09:30:00.123+07:00"},
+		{name: "payment method", old: "<PmtMtd>TRF</PmtMtd>", new: "<PmtMtd>CHK</PmtMtd>", wantErrs: []string{"PmtInf[1]/PmtMtd must be TRF"}},
+		{name: "bad BIC", old: "MBCOIDJA", new: "MBC", wantErrs: []string{"PmtInf[1]/DbtrAgt/FinInstnId/BICFI is not a valid BIC"}},
+		{name: "bad IBAN", old: "ID12BMRI0000123456", new: "12ID", wantErrs: []string{"PmtInf[1]/CdtTrfTxInf[2]/CdtrAcct/Id/IBAN is not a valid IBAN"}},
+		{name: "bad currency", old: `Ccy="IDR">200000`, new: `Ccy="idr">200000`, wantErrs: []string{"PmtInf[1]/CdtTrfTxInf[2]/Amt/InstdAmt/@Ccy must be an ISO 4217 currency code"}},
+		{
+			name: "zero amount", old: "150000.00</InstdAmt>", new: "0</InstdAmt>",
+			wantErrs: []string{"PmtInf[1]/CdtTrfTxInf[1]/Amt/InstdAmt must be greater than zero", "GrpHdr/CtrlSum is 350000.00 but transactions sum to 200000"},
+		},
+		{name: "non decimal amount", old: "150000.00</InstdAmt>", new: "1,500</InstdAmt>", wantErrs: []string{"PmtInf[1]/CdtTrfTxInf[1]/Amt/InstdAmt must be a decimal amount"}},
+		{
+			name: "transaction count mismatch", old: "<NbOfTxs>2</NbOfTxs>\n      <CtrlSum>", new: "<NbOfTxs>3</NbOfTxs>\n      <CtrlSum>",
+			wantErrs: []string{"GrpHdr/NbOfTxs is 3 but message contains 2 transactions"},
+		},
+		{name: "control sum mismatch", old: "<CtrlSum>350000.00</CtrlSum>", new: "<CtrlSum>350001.00</CtrlSum>", wantErrs: []string{"GrpHdr/CtrlSum is 350001.00 but transactions sum to 350000"}},
+		{name: "missing end to end id", old: "<EndToEndId>E2E-0001</EndToEndId>", new: "", wantErrs: []string{"PmtInf[1]/CdtTrfTxInf[1]/PmtId/EndToEndId is required"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := testPain001
+			if tt.old != "" {
+				if !strings.Contains(data, tt.old) {
+					t.Fatalf("test document does not contain %q", tt.old)
+				}
+				data = strings.Replace(data, tt.old, tt.new, 1)
+			}
+
+			document, errs := DecodePain001([]byte(data))
+			if len(tt.wantErrs) == 0 {
+				if len(errs) > 0 || document == nil {
+					t.Fatalf("DecodePain001() errors = %v", errs)
+				}
+				return
+			}
+			if document != nil {
+				t.Errorf("DecodePain001() returned a document for an invalid message")
+			}
+			if len(errs) != len(tt.wantErrs) {
+				t.Fatalf("DecodePain001() errors = %q, want %q", errs, tt.wantErrs)
+			}
+			for i, want := range tt.wantErrs {
+				if !strings.HasPrefix(errs[i], want) {
+					t.Errorf("error %d = %q, want %q", i, errs[i], want)
+				}
+			}
+		})
+	}
+}
+
+func testPacs008() *Pacs008Document {
+	document := &Pacs008Document{}
+	header := &document.Transfer.GroupHeader
+	header.MessageID = "MSG-0001"
+	header.CreationDateTime = "2024-05-01T09:30:00.000+07:00"
+	header.NumberOfTxs = "1"
+	header.TotalInterbankSettlementAmt = ISO20022Amount{Currency: "IDR", Value: "150000.00"}
+	header.InterbankSettlementDate = "2024-05-01"
+	header.SettlementInfo.SettlementMethod = "CLRG"
+
+	document.Transfer.CreditTransfers = []Pacs008CreditTransferInfo{{
+		PaymentID:              ISO20022PaymentID{EndToEndID: "E2E-0001"},
+		InterbankSettlementAmt: ISO20022Amount{Currency: "IDR", Value: "150000.00"},
+		ChargeBearer:           "SLEV",
+		DebtorAccount:          ISO20022Account{ID: ISO20022AccountID{Other: &ISO20022OtherAccountID{ID: "1234567890123456"}}},
+		DebtorAgent:            ISO20022Agent{FinInstnID: ISO20022FinancialInstitution{BICFI: "MBCOIDJA"}},
+		CreditorAgent:          ISO20022Agent{FinInstnID: ISO20022FinancialInstitution{ClearingMemberID: &ISO20022ClearingMember{MemberID: "008"}}},
+		CreditorAccount:        ISO20022Account{ID: ISO20022AccountID{IBAN: "ID12BMRI0000123456"}},
+	}}
+	return document
+}
+
+func TestValidatePacs008(t *testing.T) {
+	tests := []struct {
+		name     string
+		mutate   func(d *Pacs008Document)
+		wantErrs []string
+	}{
+		{name: "valid", mutate: func(d *Pacs008Document) {}},
+		{
+			name:     "settlement method",
+			mutate:   func(d *Pacs008Document) { d.Transfer.GroupHeader.SettlementInfo.SettlementMethod = "XXXX" },
+			wantErrs: []string{"GrpHdr/SttlmInf/SttlmMtd must be one of INDA, INGA, COVE, CLRG"},
+		},
+		{
+			name:     "settlement date",
+			mutate:   func(d *Pacs008Document) { d.Transfer.GroupHeader.InterbankSettlementDate = "2024-05-01T00:00:00" },
+			wantErrs: []string{"GrpHdr/IntrBkSttlmDt must be an ISODate"},
+		},
+		{
+			name:     "charge bearer",
+			mutate:   func(d *Pacs008Document) { d.Transfer.CreditTransfers[0].ChargeBearer = "" },
+			wantErrs: []string{"CdtTrfTxInf[1]/ChrgBr must be one of DEBT, CRED, SHAR, SLEV"},
+		},
+		{
+			name:     "agent without identification",
+			mutate:   func(d *Pacs008Document) { d.Transfer.CreditTransfers[0].CreditorAgent = ISO20022Agent{} },
+			wantErrs: []string{"CdtTrfTxInf[1]/CdtrAgt/FinInstnId must contain BICFI or ClrSysMmbId"},
+		},
+		{
+			name:     "account without identification",
+			mutate:   func(d *Pacs008Document) { d.Transfer.CreditTransfers[0].DebtorAccount = ISO20022Account{} },
+			wantErrs: []string{"CdtTrfTxInf[1]/DbtrAcct/Id must contain IBAN or Othr"},
+		},
+		{
+			name: "proprietary account too long",
+			mutate: func(d *Pacs008Document) {
+				d.Transfer.CreditTransfers[0].DebtorAccount.ID.Other.ID = strings.Repeat("1", 35)
+			},
+			wantErrs: []string{"CdtTrfTxInf[1]/DbtrAcct/Id/Othr/Id must be at most 34 characters"},
+		},
+		{
+			name:     "total does not match",
+			mutate:   func(d *Pacs008Document) { d.Transfer.GroupHeader.TotalInterbankSettlementAmt.Value = "100000.00" },
+			wantErrs: []string{"GrpHdr/TtlIntrBkSttlmAmt is 100000.00 but transactions sum to 150000"},
+		},
+		{
+			name:     "no transactions",
+			mutate:   func(d *Pacs008Document) { d.Transfer.CreditTransfers = nil },
+			wantErrs: []string{"CdtTrfTxInf is required", "GrpHdr/NbOfTxs is 1 but message contains 0 transactions", "GrpHdr/TtlIntrBkSttlmAmt is 150000.00 but transactions sum to 0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document := testPacs008()
+			tt.mutate(document)
+			errs := ValidatePacs008(document, ISO20022Namespace(ISO20022_PACS008))
+			if len(errs) != len(tt.wantErrs) {
+				t.Fatalf("ValidatePacs008() errors = %q, want %q", errs, tt.wantErrs)
+			}
+			for i, want := range tt.wantErrs {
+				if errs[i] != want {
+					t.Errorf("error %d = %q, want %q", i, errs[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseISO20022Amount(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{"150000.00", 150000, false},
+		{"150000", 150000, false},
+		{" 150000.0 ", 150000, false},
+		{"150000.50", 0, true},
+		{"abc", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseISO20022Amount(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseISO20022Amount(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseISO20022Amount(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+
+	if got := FormatISO20022Amount(150000); got != "150000.00" {
+		t.Errorf("FormatISO20022Amount(150000) = %s, want 150000.00", got)
+	}
+}
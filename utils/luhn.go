@@ -0,0 +1,27 @@
+package utils
+
+// LuhnCheckDigit returns the Luhn (mod 10) check digit for a string of digits
+func LuhnCheckDigit(digits string) byte {
+	sum := 0
+	double := true
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// LuhnValid reports whether a string of digits ends with a valid Luhn check digit
+func LuhnValid(number string) bool {
+	if len(number) < 2 || !isDigits(number) {
+		return false
+	}
+	return LuhnCheckDigit(number[:len(number)-1]) == number[len(number)-1]
+}
@@ -0,0 +1,49 @@
+package utils
+
+import "testing"
+
+func TestLuhnCheckDigit(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   byte
+	}{
+		{"7992739871", '3'},
+		{"411111111111111", '1'},
+		{"0", '0'},
+		{"", '0'},
+		{"1", '8'},
+		{"52218400000001", '9'},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.digits, func(t *testing.T) {
+			if got := LuhnCheckDigit(tt.digits); got != tt.want {
+				t.Errorf("LuhnCheckDigit(%q) = %c, want %c", tt.digits, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLuhnValid(t *testing.T) {
+	tests := []struct {
+		number string
+		want   bool
+	}{
+		{"79927398713", true},
+		{"4111111111111111", true},
+		{"79927398710", false},
+		{"4111111111111112", false},
+		{"0", false},
+		{"", false},
+		{"00", true},
+		{"4111-1111-1111-1111", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			if got := LuhnValid(tt.number); got != tt.want {
+				t.Errorf("LuhnValid(%q) = %v, want %v", tt.number, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,337 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Redaction rules applied to sensitive fields
+const (
+	redactNone    = iota
+	redactFull    // the whole value is replaced
+	redactPartial // all but the last four characters are masked
+)
+
+// RedactedValue stands in for fully redacted values
+const RedactedValue = "[REDACTED]"
+
+// redactedFields maps field and column names to how their values are redacted
+var redactedFields = map[string]int{
+	"pin":               redactFull,
+	"pin_atm":           redactFull,
+	"password":          redactFull,
+	"otp":               redactFull,
+	"otp_code":          redactFull,
+	"verification_code": redactFull,
+	"token":             redactFull,
+	"refresh_token":     redactFull,
+	"access_token":      redactFull,
+	"login_token":       redactFull,
+	"mother_name":       redactFull,
+	"cvv":               redactFull,
+	"api_key":           redactFull,
+	"secret":            redactFull,
+	"signature":         redactFull,
+	"account_number":    redactPartial,
+	"card_number":       redactPartial,
+	"pan":               redactPartial,
+}
+
+// Name suffixes redacted like the field they end in, e.g. new_pin, current_password,
+// source_account_number
+var redactedSuffixes = []struct {
+	suffix string
+	rule   int
+}{
+	{"_pin", redactFull},
+	{"_password", redactFull},
+	{"_token", redactFull},
+	{"_secret", redactFull},
+	{"_otp", redactFull},
+	{"_account_number", redactPartial},
+	{"_card_number", redactPartial},
+}
+
+// redactionRule returns how values of a field are redacted. Field names are matched case
+// insensitively and in snake or camel case.
+func redactionRule(field string) int {
+	name := normalizeFieldName(field)
+	if rule, ok := redactedFields[name]; ok {
+		return rule
+	}
+	for _, s := range redactedSuffixes {
+		if strings.HasSuffix(name, s.suffix) {
+			return s.rule
+		}
+	}
+	return redactNone
+}
+
+// normalizeFieldName converts pinAtm, PinAtm and PIN_ATM to pin_atm
+func normalizeFieldName(field string) string {
+	var b strings.Builder
+	for i, r := range field {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 && field[i-1] >= 'a' && field[i-1] <= 'z' {
+				b.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		} else if r == '-' {
+			r = '_'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// IsSensitiveField reports whether values of a field are redacted
+func IsSensitiveField(field string) bool {
+	return redactionRule(field) != redactNone
+}
+
+// MaskPartial masks all but the last four characters of a value, e.g. "******7890"
+func MaskPartial(value string) string {
+	if len(value) <= 4 {
+		return strings.Repeat("*", len(value))
+	}
+	return strings.Repeat("*", len(value)-4) + value[len(value)-4:]
+}
+
+// RedactValue returns the value to record for a field. Empty values are kept so that it stays
+// visible whether a field was set.
+func RedactValue(field string, value interface{}) interface{} {
+	return applyRedaction(redactionRule(field), value)
+}
+
+func applyRedaction(rule int, value interface{}) interface{} {
+	if rule == redactNone || value == nil {
+		return value
+	}
+
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return v
+		}
+		if rule == redactPartial {
+			return MaskPartial(v)
+		}
+		return RedactedValue
+	case *string:
+		if v == nil {
+			return value
+		}
+		return applyRedaction(rule, *v)
+	case []byte:
+		return applyRedaction(rule, string(v))
+	case map[string]interface{}:
+		// Nested values of a sensitive field, e.g. the old and new value of a change
+		redacted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			redacted[key] = applyRedaction(rule, item)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = applyRedaction(rule, item)
+		}
+		return redacted
+	case bool:
+		return v
+	default:
+		if rule == redactPartial {
+			return MaskPartial(fmt.Sprint(v))
+		}
+		return RedactedValue
+	}
+}
+
+// RedactFields returns a copy of a decoded JSON object with sensitive fields redacted, at any depth
+func RedactFields(fields map[string]interface{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		if rule := redactionRule(key); rule != redactNone {
+			redacted[key] = applyRedaction(rule, value)
+			continue
+		}
+		redacted[key] = redactNested(value)
+	}
+	return redacted
+}
+
+func redactNested(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return RedactFields(v)
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = redactNested(item)
+		}
+		return redacted
+	default:
+		return value
+	}
+}
+
+// RedactJSON redacts sensitive fields of a JSON document, such as an audit payload or a request
+// body. Input that is not valid JSON is redacted as text.
+func RedactJSON(data []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil || decoder.More() {
+		return []byte(RedactText(string(data)))
+	}
+
+	redacted, err := json.Marshal(redactNested(document))
+	if err != nil {
+		return data
+	}
+	return redacted
+}
+
+// textFieldPattern matches key/value pairs in free text: "pin_atm":"123456", pin_atm=123456,
+// otp_code: 123456 and token='abc'
+var textFieldPattern = regexp.MustCompile(`(["']?)([A-Za-z][A-Za-z0-9_\-]*)(["']?\s*[:=]\s*)("(?:[^"\\]|\\.)*"|'[^']*'|[^\s,;&"'}\]]+)`)
+
+// RedactText redacts the values of sensitive key/value pairs in a log line or other free text
+func RedactText(text string) string {
+	return textFieldPattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := textFieldPattern.FindStringSubmatch(match)
+		rule := redactionRule(parts[2])
+		if rule == redactNone {
+			return match
+		}
+
+		value, quote := parts[4], ""
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') {
+			quote, value = value[:1], value[1:len(value)-1]
+		}
+		if value == "" {
+			return match
+		}
+		return parts[1] + parts[2] + parts[3] + quote + applyRedaction(rule, value).(string) + quote
+	})
+}
+
+// SQL patterns that tie a bind variable to the column it is compared with or assigned to
+var (
+	sqlComparisonPattern = regexp.MustCompile(`(?i)"?([A-Za-z_][A-Za-z0-9_]*)"?\s*(?:=|<>|!=|>=|<=|>|<|\bLIKE\b|\bILIKE\b)\s*\$(\d+)`)
+	sqlInListPattern     = regexp.MustCompile(`(?i)"?([A-Za-z_][A-Za-z0-9_]*)"?\s+IN\s*\(([^)]*)\)`)
+	sqlInsertPattern     = regexp.MustCompile(`(?is)^\s*INSERT\s+INTO\s+\S+\s*\(([^)]*)\)\s*VALUES\s*(.*?)(?:\s+ON\s+CONFLICT|\s+RETURNING|$)`)
+	sqlTuplePattern      = regexp.MustCompile(`\(([^()]*)\)`)
+	sqlBindVarPattern    = regexp.MustCompile(`\$(\d+)`)
+	sqlLiteralPattern    = regexp.MustCompile(`"?([A-Za-z_][A-Za-z0-9_]*)"?(\s*=\s*)'((?:[^']|'')*)'`)
+)
+
+// RedactSQLVars redacts the bind variables of a statement that are compared with or written to
+// sensitive columns. Variables are matched to columns through their $n placeholders.
+func RedactSQLVars(sql string, vars []interface{}) []interface{} {
+	columns := map[int]string{}
+	for _, m := range sqlComparisonPattern.FindAllStringSubmatch(sql, -1) {
+		if n, err := strconv.Atoi(m[2]); err == nil {
+			columns[n] = m[1]
+		}
+	}
+	for _, m := range sqlInListPattern.FindAllStringSubmatch(sql, -1) {
+		for _, bind := range sqlBindVarPattern.FindAllStringSubmatch(m[2], -1) {
+			if n, err := strconv.Atoi(bind[1]); err == nil {
+				columns[n] = m[1]
+			}
+		}
+	}
+	if m := sqlInsertPattern.FindStringSubmatch(sql); m != nil {
+		names := strings.Split(m[1], ",")
+		for _, tuple := range sqlTuplePattern.FindAllStringSubmatch(m[2], -1) {
+			for i, value := range strings.Split(tuple[1], ",") {
+				bind := sqlBindVarPattern.FindStringSubmatch(value)
+				if bind == nil || i >= len(names) {
+					continue
+				}
+				if n, err := strconv.Atoi(bind[1]); err == nil {
+					columns[n] = strings.Trim(strings.TrimSpace(names[i]), `"`)
+				}
+			}
+		}
+	}
+
+	redacted := vars
+	copied := false
+	for n, column := range columns {
+		rule := redactionRule(column)
+		if rule == redactNone || n < 1 || n > len(vars) {
+			continue
+		}
+		if !copied {
+			redacted = append([]interface{}(nil), vars...)
+			copied = true
+		}
+		redacted[n-1] = applyRedaction(rule, vars[n-1])
+	}
+	return redacted
+}
+
+// RedactSQL redacts string literals assigned to or compared with sensitive columns in raw SQL
+func RedactSQL(sql string) string {
+	return sqlLiteralPattern.ReplaceAllStringFunc(sql, func(match string) string {
+		parts := sqlLiteralPattern.FindStringSubmatch(match)
+		rule := redactionRule(parts[1])
+		if rule == redactNone || parts[3] == "" {
+			return match
+		}
+		return strings.TrimSuffix(match, "'"+parts[3]+"'") + "'" + applyRedaction(rule, parts[3]).(string) + "'"
+	})
+}
+
+// redactingSQLLogger wraps a GORM logger so that logged SQL does not contain PINs, tokens and other
+// sensitive values. GORM passes the statement and its variables through ParamsFilter before they
+// are interpolated for logging.
+type redactingSQLLogger struct {
+	logger.Interface
+}
+
+// NewRedactingSQLLogger returns a GORM logger that redacts sensitive values from logged SQL
+func NewRedactingSQLLogger(l logger.Interface) logger.Interface {
+	return &redactingSQLLogger{Interface: l}
+}
+
+func (l *redactingSQLLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return &redactingSQLLogger{Interface: l.Interface.LogMode(level)}
+}
+
+// ParamsFilter implements gorm.ParamsFilter
+func (l *redactingSQLLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	if filter, ok := l.Interface.(gorm.ParamsFilter); ok {
+		sql, params = filter.ParamsFilter(ctx, sql, params...)
+	}
+	return RedactSQL(sql), RedactSQLVars(sql, params)
+}
+
+// redactingWriter redacts sensitive key/value pairs from everything written through it
+type redactingWriter struct {
+	out io.Writer
+}
+
+// NewRedactingWriter returns a writer for application logs that redacts sensitive values before
+// passing each write on to out
+func NewRedactingWriter(out io.Writer) io.Writer {
+	return &redactingWriter{out: out}
+}
+
+func (w *redactingWriter) Write(p []byte) (int, error) {
+	if _, err := w.out.Write([]byte(RedactText(string(p)))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,193 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRedactJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "flat object",
+			data: `{"phone":"08123456789","pin_atm":"123456","otp_code":"654321"}`,
+			want: `{"otp_code":"[REDACTED]","phone":"08123456789","pin_atm":"[REDACTED]"}`,
+		},
+		{
+			name: "partial masking of account numbers",
+			data: `{"account_number":"1234567890123456","amount":50000}`,
+			want: `{"account_number":"************3456","amount":50000}`,
+		},
+		{
+			name: "camel case and suffixes",
+			data: `{"newPin":"111111","currentPassword":"secret","sourceAccountNumber":"9876543210","refreshToken":"abc"}`,
+			want: `{"currentPassword":"[REDACTED]","newPin":"[REDACTED]","refreshToken":"[REDACTED]","sourceAccountNumber":"******3210"}`,
+		},
+		{
+			name: "nested objects and arrays",
+			data: `{"user":{"name":"Budi","pin":"123456"},"items":[{"token":"t1"},{"token":""}]}`,
+			want: `{"items":[{"token":"[REDACTED]"},{"token":""}],"user":{"name":"Budi","pin":"[REDACTED]"}}`,
+		},
+		{
+			name: "audit change of a sensitive field",
+			data: `{"pin_atm":{"old":"[HIDDEN]","new":"[HIDDEN]"},"account_number":{"old":"1111222233","new":"4444555566"}}`,
+			want: `{"account_number":{"new":"******5566","old":"******2233"},"pin_atm":{"new":"[REDACTED]","old":"[REDACTED]"}}`,
+		},
+		{
+			name: "numbers and nulls",
+			data: `{"pin":123456,"card_number":5221840000000019,"otp":null}`,
+			want: `{"card_number":"************0019","otp":null,"pin":"[REDACTED]"}`,
+		},
+		{
+			name: "invalid JSON is redacted as text",
+			data: `{"pin":"123456"`,
+			want: `{"pin":"[REDACTED]"`,
+		},
+		{
+			name: "no sensitive fields",
+			data: `[1,"two",{"three":3}]`,
+			want: `[1,"two",{"three":3}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(RedactJSON([]byte(tt.data))); got != tt.want {
+				t.Errorf("RedactJSON(%s) = %s, want %s", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"key=value", "login failed pin=123456 phone=0812", "login failed pin=[REDACTED] phone=0812"},
+		{"colon separated", "otp_code: 654321, attempt: 2", "otp_code: [REDACTED], attempt: 2"},
+		{"JSON fragment", `body {"pin_atm":"123456","name":"Budi"}`, `body {"pin_atm":"[REDACTED]","name":"Budi"}`},
+		{"single quoted", "token='abc def'", "token='[REDACTED]'"},
+		{"query string", "/verify?login_token=abc&device=x", "/verify?login_token=[REDACTED]&device=x"},
+		{"partial", "account_number=1234567890", "account_number=******7890"},
+		{"empty value kept", `"pin":""`, `"pin":""`},
+		{"nothing sensitive", "transfer 50000 to 1234567890", "transfer 50000 to 1234567890"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RedactText(tt.text); got != tt.want {
+				t.Errorf("RedactText(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactSQL(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{
+			name: "comparison",
+			sql:  `SELECT * FROM "otp_sessions" WHERE login_token = 'abc123' AND is_used = false`,
+			want: `SELECT * FROM "otp_sessions" WHERE login_token = '[REDACTED]' AND is_used = false`,
+		},
+		{
+			name: "assignment",
+			sql:  `UPDATE "users" SET "pin_atm"='$2a$10$hash',"name"='Budi' WHERE "id" = 1`,
+			want: `UPDATE "users" SET "pin_atm"='[REDACTED]',"name"='Budi' WHERE "id" = 1`,
+		},
+		{
+			name: "partial",
+			sql:  `SELECT * FROM bank_accounts WHERE account_number = '1234567890'`,
+			want: `SELECT * FROM bank_accounts WHERE account_number = '******7890'`,
+		},
+		{
+			name: "escaped quote",
+			sql:  `UPDATE users SET mother_name = 'O''Brien' WHERE id = 1`,
+			want: `UPDATE users SET mother_name = '[REDACTED]' WHERE id = 1`,
+		},
+		{
+			name: "device verification code",
+			sql:  `SELECT * FROM "device_verifications" WHERE "verification_code" = '482913' AND user_id = 1`,
+			want: `SELECT * FROM "device_verifications" WHERE "verification_code" = '[REDACTED]' AND user_id = 1`,
+		},
+		{
+			name: "nothing sensitive",
+			sql:  `SELECT * FROM users WHERE phone = '0812'`,
+			want: `SELECT * FROM users WHERE phone = '0812'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RedactSQL(tt.sql); got != tt.want {
+				t.Errorf("RedactSQL(%q) = %q, want %q", tt.sql, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactSQLVars(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		vars []interface{}
+		want []interface{}
+	}{
+		{
+			name: "comparison",
+			sql:  `SELECT * FROM "otp_sessions" WHERE login_token = $1 AND is_used = $2`,
+			vars: []interface{}{"abc123", false},
+			want: []interface{}{RedactedValue, false},
+		},
+		{
+			name: "in list",
+			sql:  `SELECT * FROM bank_accounts WHERE account_number IN ($1,$2) AND user_id = $3`,
+			vars: []interface{}{"1234567890", "0987654321", uint(5)},
+			want: []interface{}{"******7890", "******4321", uint(5)},
+		},
+		{
+			name: "insert",
+			sql:  `INSERT INTO "otp_sessions" ("login_token","phone","otp_code") VALUES ($1,$2,$3) RETURNING "id"`,
+			vars: []interface{}{"tok", "0812", "123456"},
+			want: []interface{}{RedactedValue, "0812", RedactedValue},
+		},
+		{
+			name: "device verification insert",
+			sql:  `INSERT INTO "device_verifications" ("user_id","verification_token","verification_code","device_id") VALUES ($1,$2,$3,$4) RETURNING "id"`,
+			vars: []interface{}{uint(1), "tok", "482913", "dev-1"},
+			want: []interface{}{uint(1), RedactedValue, RedactedValue, "dev-1"},
+		},
+		{
+			name: "update",
+			sql:  `UPDATE "users" SET "pin_atm"=$1,"updated_at"=$2 WHERE "id" = $3`,
+			vars: []interface{}{"$2a$10$hash", "2024-05-01", uint(1)},
+			want: []interface{}{RedactedValue, "2024-05-01", uint(1)},
+		},
+		{
+			name: "nothing sensitive",
+			sql:  `SELECT * FROM users WHERE phone = $1`,
+			vars: []interface{}{"0812"},
+			want: []interface{}{"0812"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := append([]interface{}(nil), tt.vars...)
+			if got := RedactSQLVars(tt.sql, tt.vars); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RedactSQLVars() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(tt.vars, original) {
+				t.Errorf("RedactSQLVars() modified its input: %v", tt.vars)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// GenerateReference generates a human-readable reference such as "DSB20250803142501A1B2C3"
+func GenerateReference(prefix string) string {
+	const charset = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+	suffix := make([]byte, 6)
+	for i := range suffix {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+		if err != nil {
+			// Fallback to timestamp-based suffix if crypto/rand fails
+			return fmt.Sprintf("%s%s%06d", prefix, time.Now().Format("20060102150405"), time.Now().UnixNano()%1000000)
+		}
+		suffix[i] = charset[n.Int64()]
+	}
+
+	return prefix + time.Now().Format("20060102150405") + string(suffix)
+}
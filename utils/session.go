@@ -173,6 +173,13 @@ func (sm *SessionManager) LogoutAllSessions(userID uint) error {
 		Update("is_active", false).Error
 }
 
+// LogoutDeviceSessions logs out all sessions of a user on one device
+func (sm *SessionManager) LogoutDeviceSessions(userID uint, deviceID string) error {
+	return sm.DB.Model(&models.DeviceSession{}).
+		Where("user_id = ? AND device_id = ?", userID, deviceID).
+		Update("is_active", false).Error
+}
+
 // LogoutAllOtherSessions logs out all other sessions except current
 func (sm *SessionManager) LogoutAllOtherSessions(userID uint, currentSessionID uint) error {
 	return sm.DB.Model(&models.DeviceSession{}).
@@ -0,0 +1,391 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"mbankingcore/models"
+)
+
+// SIEM message body formats
+const (
+	SIEM_FORMAT_JSON = "json"
+	SIEM_FORMAT_CEF  = "cef"
+)
+
+// Syslog framing of audit events
+const (
+	siemSyslogFacility  = 13 // log audit
+	siemSeverityNotice  = 5
+	siemSeverityWarning = 4
+	siemSDID            = "mbc@32473" // structured data ID carrying the chain position of each event
+	siemTimeFormat      = "2006-01-02T15:04:05.000000Z07:00"
+	siemCEFVendor       = "MBankingCore"
+	siemCEFProduct      = "mbankingcore"
+)
+
+// IsValidSIEMFormat reports whether format names a supported message body format
+func IsValidSIEMFormat(format string) bool {
+	return format == SIEM_FORMAT_JSON || format == SIEM_FORMAT_CEF
+}
+
+// FormatAuditEventJSON encodes an audit event as a single line of JSON
+func FormatAuditEventJSON(event models.AuditEvent) string {
+	data, _ := json.Marshal(event)
+	return string(data)
+}
+
+// FormatAuditEventCEF encodes an audit event in ArcSight Common Event Format
+func FormatAuditEventCEF(event models.AuditEvent, productVersion string) string {
+	signatureID := event.Category + ":" + strings.ToLower(event.Action)
+	name := event.Action
+	if event.Category == models.AUDIT_EVENT_CATEGORY_AUDIT && event.EntityType != "" {
+		signatureID += ":" + event.EntityType
+		name = event.Action + " " + event.EntityType
+	}
+
+	severity := 3
+	if event.Outcome == models.AUDIT_EVENT_OUTCOME_FAILURE {
+		severity = 6
+	}
+
+	ext := []string{
+		"rt=" + strconv.FormatInt(event.Time.UnixMilli(), 10),
+		"act=" + cefExtensionEscape(event.Action),
+		"outcome=" + event.Outcome,
+		"cat=" + event.Category,
+		"externalId=" + strconv.FormatUint(uint64(event.RecordID), 10),
+		"cs1Label=chain", "cs1=" + event.Chain,
+		"cn1Label=chainSeq", "cn1=" + strconv.FormatUint(event.ChainSeq, 10),
+		"cs2Label=hash", "cs2=" + event.Hash,
+		"cs3Label=prevHash", "cs3=" + event.PrevHash,
+	}
+	if event.AdminID != nil {
+		ext = append(ext, "suser=admin:"+strconv.FormatUint(uint64(*event.AdminID), 10))
+	} else if event.UserID != nil {
+		ext = append(ext, "suser=user:"+strconv.FormatUint(uint64(*event.UserID), 10))
+	}
+	if event.IPAddress != "" {
+		ext = append(ext, "src="+event.IPAddress)
+	}
+	if event.UserAgent != "" {
+		ext = append(ext, "requestClientApplication="+cefExtensionEscape(event.UserAgent))
+	}
+	if event.Endpoint != "" {
+		ext = append(ext, "request="+cefExtensionEscape(event.Endpoint), "requestMethod="+event.Method)
+	}
+	if event.StatusCode != 0 {
+		ext = append(ext, "cn2Label=statusCode", "cn2="+strconv.Itoa(event.StatusCode))
+	}
+	if event.EntityType != "" {
+		ext = append(ext, "cs4Label=entity", "cs4="+cefExtensionEscape(auditEventEntity(event)))
+	}
+	if len(event.ChangedFields) > 0 {
+		ext = append(ext, "cs5Label=changedFields", "cs5="+cefExtensionEscape(strings.Join(event.ChangedFields, ",")))
+	}
+	if event.Identifier != "" {
+		ext = append(ext, "duser="+cefExtensionEscape(event.Identifier))
+	}
+	if event.FailureReason != "" {
+		ext = append(ext, "reason="+cefExtensionEscape(event.FailureReason))
+	}
+
+	return fmt.Sprintf("CEF:0|%s|%s|%s|%s|%s|%d|%s",
+		cefHeaderEscape(siemCEFVendor), cefHeaderEscape(siemCEFProduct), cefHeaderEscape(productVersion),
+		cefHeaderEscape(signatureID), cefHeaderEscape(name), severity, strings.Join(ext, " "))
+}
+
+// auditEventEntity identifies the entity an audit event acts on, e.g. "user:12" or "config:otp_ttl"
+func auditEventEntity(event models.AuditEvent) string {
+	if event.EntityKey != "" {
+		return event.EntityType + ":" + event.EntityKey
+	}
+	return event.EntityType + ":" + strconv.FormatUint(uint64(event.EntityID), 10)
+}
+
+func cefHeaderEscape(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return strings.ReplaceAll(value, "|", `\|`)
+}
+
+func cefExtensionEscape(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "=", `\=`)
+	value = strings.ReplaceAll(value, "\r", `\r`)
+	return strings.ReplaceAll(value, "\n", `\n`)
+}
+
+// FormatAuditEventSyslog wraps an audit event in an RFC 5424 syslog message. The chain position and
+// hash are carried as structured data so that a receiver can check completeness without parsing
+// the body.
+func FormatAuditEventSyslog(event models.AuditEvent, format, hostname, appName, productVersion string) string {
+	severity := siemSeverityNotice
+	if event.Outcome == models.AUDIT_EVENT_OUTCOME_FAILURE {
+		severity = siemSeverityWarning
+	}
+
+	body := FormatAuditEventJSON(event)
+	if format == SIEM_FORMAT_CEF {
+		body = FormatAuditEventCEF(event, productVersion)
+	}
+
+	return fmt.Sprintf("<%d>1 %s %s %s - %s [%s chain=\"%s\" seq=\"%d\" id=\"%d\" hash=\"%s\"] %s",
+		siemSyslogFacility*8+severity, event.Time.UTC().Format(siemTimeFormat), syslogHeaderField(hostname, 255),
+		syslogHeaderField(appName, 48), strings.ToUpper(event.Category), siemSDID,
+		sdParamEscape(event.Chain), event.ChainSeq, event.RecordID, sdParamEscape(event.Hash), body)
+}
+
+// syslogHeaderField returns a header field limited to printable ASCII without spaces, or the nil
+// value "-"
+func syslogHeaderField(value string, maxLen int) string {
+	var b strings.Builder
+	for _, r := range value {
+		if r > 32 && r < 127 && b.Len() < maxLen {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() == 0 {
+		return "-"
+	}
+	return b.String()
+}
+
+func sdParamEscape(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return strings.ReplaceAll(value, "]", `\]`)
+}
+
+// SyslogMessage is a parsed RFC 5424 message
+type SyslogMessage struct {
+	Facility       int
+	Severity       int
+	Timestamp      time.Time
+	Hostname       string
+	AppName        string
+	ProcID         string
+	MsgID          string
+	StructuredData map[string]map[string]string
+	Message        string
+}
+
+// ParseSyslogMessage parses and validates an RFC 5424 message
+func ParseSyslogMessage(raw string) (*SyslogMessage, error) {
+	if !strings.HasPrefix(raw, "<") {
+		return nil, errors.New("missing PRI")
+	}
+	end := strings.Index(raw, ">")
+	if end < 2 || end > 4 {
+		return nil, errors.New("malformed PRI")
+	}
+	pri, err := strconv.Atoi(raw[1:end])
+	if err != nil || pri < 0 || pri > 191 {
+		return nil, errors.New("malformed PRI")
+	}
+	msg := &SyslogMessage{Facility: pri / 8, Severity: pri % 8}
+
+	fields := strings.SplitN(raw[end+1:], " ", 7)
+	if len(fields) < 7 {
+		return nil, errors.New("incomplete header")
+	}
+	if fields[0] != "1" {
+		return nil, fmt.Errorf("unsupported version %q", fields[0])
+	}
+	if fields[1] != "-" {
+		if msg.Timestamp, err = time.Parse(time.RFC3339Nano, fields[1]); err != nil {
+			return nil, fmt.Errorf("malformed timestamp %q", fields[1])
+		}
+	}
+	msg.Hostname, msg.AppName, msg.ProcID, msg.MsgID = fields[2], fields[3], fields[4], fields[5]
+	for _, field := range fields[2:6] {
+		if field == "" {
+			return nil, errors.New("empty header field")
+		}
+	}
+
+	rest := fields[6]
+	msg.StructuredData, rest, err = parseStructuredData(rest)
+	if err != nil {
+		return nil, err
+	}
+	if rest != "" {
+		if !strings.HasPrefix(rest, " ") {
+			return nil, errors.New("missing space before message")
+		}
+		msg.Message = strings.TrimPrefix(rest[1:], "\ufeff")
+	}
+	return msg, nil
+}
+
+// parseStructuredData parses the STRUCTURED-DATA part of a syslog message and returns the rest
+func parseStructuredData(s string) (map[string]map[string]string, string, error) {
+	data := map[string]map[string]string{}
+	if strings.HasPrefix(s, "-") {
+		return data, s[1:], nil
+	}
+
+	for strings.HasPrefix(s, "[") {
+		s = s[1:]
+		idEnd := strings.IndexAny(s, " ]")
+		if idEnd <= 0 {
+			return nil, "", errors.New("malformed structured data")
+		}
+		params := map[string]string{}
+		data[s[:idEnd]] = params
+		s = s[idEnd:]
+
+		for strings.HasPrefix(s, " ") {
+			s = s[1:]
+			eq := strings.Index(s, `="`)
+			if eq <= 0 {
+				return nil, "", errors.New("malformed structured data parameter")
+			}
+			name := s[:eq]
+			s = s[eq+2:]
+
+			var value strings.Builder
+			closed := false
+			for i := 0; i < len(s); i++ {
+				if s[i] == '\\' && i+1 < len(s) && strings.ContainsRune(`"\]`, rune(s[i+1])) {
+					value.WriteByte(s[i+1])
+					i++
+					continue
+				}
+				if s[i] == '"' {
+					s = s[i+1:]
+					closed = true
+					break
+				}
+				value.WriteByte(s[i])
+			}
+			if !closed {
+				return nil, "", errors.New("unterminated structured data parameter")
+			}
+			params[name] = value.String()
+		}
+		if !strings.HasPrefix(s, "]") {
+			return nil, "", errors.New("unterminated structured data element")
+		}
+		s = s[1:]
+	}
+	if len(data) == 0 {
+		return nil, "", errors.New("missing structured data")
+	}
+	return data, s, nil
+}
+
+// CEFRecord is a parsed Common Event Format message
+type CEFRecord struct {
+	Version        string
+	Vendor         string
+	Product        string
+	ProductVersion string
+	SignatureID    string
+	Name           string
+	Severity       int
+	Extension      map[string]string
+}
+
+// ParseCEF parses and validates a Common Event Format message
+func ParseCEF(raw string) (*CEFRecord, error) {
+	if !strings.HasPrefix(raw, "CEF:") {
+		return nil, errors.New("missing CEF prefix")
+	}
+
+	var header []string
+	var field strings.Builder
+	s := raw[len("CEF:"):]
+	i := 0
+	for ; i < len(s) && len(header) < 7; i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && (s[i+1] == '\\' || s[i+1] == '|'):
+			field.WriteByte(s[i+1])
+			i++
+		case s[i] == '|':
+			header = append(header, field.String())
+			field.Reset()
+		default:
+			field.WriteByte(s[i])
+		}
+	}
+	if len(header) < 7 {
+		return nil, errors.New("incomplete CEF header")
+	}
+
+	severity, err := strconv.Atoi(header[6])
+	if err != nil || severity < 0 || severity > 10 {
+		return nil, fmt.Errorf("invalid CEF severity %q", header[6])
+	}
+	record := &CEFRecord{
+		Version:        header[0],
+		Vendor:         header[1],
+		Product:        header[2],
+		ProductVersion: header[3],
+		SignatureID:    header[4],
+		Name:           header[5],
+		Severity:       severity,
+	}
+	if record.Version != "0" {
+		return nil, fmt.Errorf("unsupported CEF version %q", record.Version)
+	}
+	record.Extension, err = parseCEFExtension(s[i:])
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// parseCEFExtension parses space separated key=value pairs; values may contain unescaped spaces,
+// so a value runs until the next " key=" token
+func parseCEFExtension(s string) (map[string]string, error) {
+	ext := map[string]string{}
+	key := ""
+	var value strings.Builder
+	flush := func() {
+		if key != "" {
+			ext[key] = strings.TrimRight(value.String(), " ")
+		}
+		value.Reset()
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			switch s[i+1] {
+			case 'n':
+				value.WriteByte('\n')
+			case 'r':
+				value.WriteByte('\r')
+			default:
+				value.WriteByte(s[i+1])
+			}
+			i++
+			continue
+		}
+		if s[i] == '=' {
+			// The word before an unescaped "=" starts the next pair
+			current := value.String()
+			start := strings.LastIndex(current, " ") + 1
+			if key == "" && start == 0 {
+				key = current
+				value.Reset()
+				continue
+			}
+			if start == 0 {
+				return nil, errors.New("unescaped \"=\" in CEF extension value")
+			}
+			nextKey := current[start:]
+			value.Reset()
+			value.WriteString(current[:start-1])
+			flush()
+			key = nextKey
+			continue
+		}
+		value.WriteByte(s[i])
+	}
+	flush()
+	return ext, nil
+}
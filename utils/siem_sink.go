@@ -0,0 +1,327 @@
+package utils
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"mbankingcore/models"
+)
+
+// Syslog transports
+const (
+	SIEM_NETWORK_TCP = "tcp"
+	SIEM_NETWORK_UDP = "udp"
+	SIEM_NETWORK_TLS = "tls"
+)
+
+const (
+	siemDialTimeout     = 5 * time.Second
+	siemWriteTimeout    = 10 * time.Second
+	siemMaxUDPMessage   = 8192 // Larger datagrams are commonly dropped by receivers
+	defaultAuditFileMB  = 100
+	defaultAuditBackups = 10
+)
+
+// AuditSink delivers audit events to a SIEM or another external store. Send either delivers the
+// whole batch or returns an error, in which case the batch is sent again later; receivers must
+// tolerate the duplicates this can cause, using the chain and sequence of each event.
+type AuditSink interface {
+	// Name identifies the sink in export cursors and logs
+	Name() string
+	// Send delivers a batch of events in chain order
+	Send(events []models.AuditEvent) error
+	// Close releases the sink's connection or file
+	Close() error
+}
+
+// NewAuditSinks returns the sinks listed in AUDIT_SINKS (comma separated: syslog, file), or none
+// when export is not configured
+func NewAuditSinks(productVersion string) ([]AuditSink, error) {
+	var sinks []AuditSink
+	for _, name := range strings.Split(os.Getenv("AUDIT_SINKS"), ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+			continue
+		case "syslog":
+			sink, err := NewSyslogAuditSink(
+				os.Getenv("AUDIT_SYSLOG_NETWORK"),
+				os.Getenv("AUDIT_SYSLOG_ADDRESS"),
+				os.Getenv("AUDIT_SYSLOG_FORMAT"),
+				os.Getenv("AUDIT_SYSLOG_CA_FILE"),
+				productVersion,
+			)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, sink)
+		case "file":
+			maxSizeMB := getEnvInt("AUDIT_FILE_MAX_SIZE_MB", defaultAuditFileMB)
+			maxBackups := getEnvInt("AUDIT_FILE_MAX_BACKUPS", defaultAuditBackups)
+			sink, err := NewFileAuditSink(os.Getenv("AUDIT_FILE_PATH"), int64(maxSizeMB)<<20, maxBackups)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, sink)
+		default:
+			return nil, fmt.Errorf("unknown audit sink %q in AUDIT_SINKS", name)
+		}
+	}
+	return sinks, nil
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil && value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
+// SyslogAuditSink sends events as RFC 5424 messages over UDP, TCP or TLS. Messages on stream
+// transports use octet-counting framing (RFC 5425/6587), so message bodies may contain newlines.
+type SyslogAuditSink struct {
+	network        string
+	address        string
+	format         string
+	hostname       string
+	productVersion string
+	tlsConfig      *tls.Config
+	conn           net.Conn
+}
+
+// NewSyslogAuditSink configures a syslog sink; the connection is opened on the first send. caFile
+// optionally names a PEM bundle that the TLS receiver's certificate is verified against in place of
+// the system roots.
+func NewSyslogAuditSink(network, address, format, caFile, productVersion string) (*SyslogAuditSink, error) {
+	if network == "" {
+		network = SIEM_NETWORK_TCP
+	}
+	if format == "" {
+		format = SIEM_FORMAT_CEF
+	}
+	if network != SIEM_NETWORK_TCP && network != SIEM_NETWORK_UDP && network != SIEM_NETWORK_TLS {
+		return nil, fmt.Errorf("invalid syslog network %q, use tcp, udp or tls", network)
+	}
+	if !IsValidSIEMFormat(format) {
+		return nil, fmt.Errorf("invalid syslog format %q, use cef or json", format)
+	}
+	if address == "" {
+		return nil, errors.New("AUDIT_SYSLOG_ADDRESS is required for the syslog audit sink")
+	}
+
+	hostname, _ := os.Hostname()
+	sink := &SyslogAuditSink{
+		network:        network,
+		address:        address,
+		format:         format,
+		hostname:       hostname,
+		productVersion: productVersion,
+	}
+
+	if network == SIEM_NETWORK_TLS {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid syslog address %q: %v", address, err)
+		}
+		sink.tlsConfig = &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+		if caFile != "" {
+			pem, err := os.ReadFile(caFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read AUDIT_SYSLOG_CA_FILE: %v", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, errors.New("AUDIT_SYSLOG_CA_FILE contains no certificates")
+			}
+			sink.tlsConfig.RootCAs = pool
+		}
+	}
+	return sink, nil
+}
+
+// Name implements AuditSink
+func (s *SyslogAuditSink) Name() string {
+	return "syslog"
+}
+
+// Send implements AuditSink. The connection is dropped after a failed write and opened again on the
+// next attempt.
+func (s *SyslogAuditSink) Send(events []models.AuditEvent) error {
+	if err := s.connect(); err != nil {
+		return err
+	}
+
+	var writer *bufio.Writer
+	if s.network != SIEM_NETWORK_UDP {
+		writer = bufio.NewWriter(s.conn)
+	}
+	s.conn.SetWriteDeadline(time.Now().Add(siemWriteTimeout))
+
+	for _, event := range events {
+		message := FormatAuditEventSyslog(event, s.format, s.hostname, "mbankingcore", s.productVersion)
+
+		var err error
+		if writer == nil {
+			if len(message) > siemMaxUDPMessage {
+				log.Printf("Audit export: %s event %d exceeds %d bytes and may be dropped over UDP",
+					event.Chain, event.ChainSeq, siemMaxUDPMessage)
+			}
+			_, err = s.conn.Write([]byte(message))
+		} else {
+			_, err = fmt.Fprintf(writer, "%d %s", len(message), message)
+		}
+		if err != nil {
+			s.Close()
+			return err
+		}
+	}
+
+	if writer != nil {
+		if err := writer.Flush(); err != nil {
+			s.Close()
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SyslogAuditSink) connect() error {
+	if s.conn != nil {
+		return nil
+	}
+
+	var conn net.Conn
+	var err error
+	dialer := &net.Dialer{Timeout: siemDialTimeout}
+	if s.network == SIEM_NETWORK_TLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", s.address, s.tlsConfig)
+	} else {
+		conn, err = dialer.Dial(s.network, s.address)
+	}
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+	return nil
+}
+
+// Close implements AuditSink
+func (s *SyslogAuditSink) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// FileAuditSink appends events as JSON lines to a file, rotating it when it reaches maxSize. Rotated
+// files are renamed path.1, path.2, ... with path.1 the most recent; the oldest beyond maxBackups
+// are removed.
+type FileAuditSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// NewFileAuditSink opens or creates the JSON-lines file at path
+func NewFileAuditSink(path string, maxSize int64, maxBackups int) (*FileAuditSink, error) {
+	if path == "" {
+		return nil, errors.New("AUDIT_FILE_PATH is required for the file audit sink")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, err
+	}
+
+	sink := &FileAuditSink{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := sink.open(); err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+// Name implements AuditSink
+func (s *FileAuditSink) Name() string {
+	return "file"
+}
+
+// Send implements AuditSink. The file is synced before returning, so a batch is only acknowledged
+// once it is on disk.
+func (s *FileAuditSink) Send(events []models.AuditEvent) error {
+	if s.file == nil {
+		if err := s.open(); err != nil {
+			return err
+		}
+	}
+
+	for _, event := range events {
+		line := FormatAuditEventJSON(event) + "\n"
+		if s.maxSize > 0 && s.size > 0 && s.size+int64(len(line)) > s.maxSize {
+			if err := s.rotate(); err != nil {
+				return err
+			}
+		}
+		n, err := s.file.WriteString(line)
+		s.size += int64(n)
+		if err != nil {
+			return err
+		}
+	}
+	return s.file.Sync()
+}
+
+func (s *FileAuditSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	s.file, s.size = file, info.Size()
+	return nil
+}
+
+// rotate shifts the backups up by one, moves the current file to path.1 and starts a new file
+func (s *FileAuditSink) rotate() error {
+	if err := s.file.Sync(); err != nil {
+		return err
+	}
+	s.Close()
+
+	os.Remove(fmt.Sprintf("%s.%d", s.path, s.maxBackups))
+	for i := s.maxBackups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", s.path, i), fmt.Sprintf("%s.%d", s.path, i+1))
+	}
+	if s.maxBackups > 0 {
+		if err := os.Rename(s.path, s.path+".1"); err != nil {
+			return err
+		}
+	} else if err := os.Remove(s.path); err != nil {
+		return err
+	}
+	return s.open()
+}
+
+// Close implements AuditSink
+func (s *FileAuditSink) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
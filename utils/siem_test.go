@@ -0,0 +1,217 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"mbankingcore/models"
+)
+
+func testAuditEvent() models.AuditEvent {
+	adminID := uint(7)
+	return models.AuditEvent{
+		Chain:         models.AUDIT_CHAIN_AUDIT_LOG,
+		ChainSeq:      42,
+		RecordID:      1001,
+		Time:          time.Date(2024, 5, 1, 9, 30, 0, 123456000, time.FixedZone("WIB", 7*3600)),
+		Category:      models.AUDIT_EVENT_CATEGORY_AUDIT,
+		Action:        "UPDATE",
+		Outcome:       models.AUDIT_EVENT_OUTCOME_SUCCESS,
+		AdminID:       &adminID,
+		EntityType:    "config",
+		EntityKey:     "otp_ttl",
+		ChangedFields: []string{"value", "description"},
+		IPAddress:     "10.0.0.1",
+		UserAgent:     "curl/8.0 a=b",
+		Endpoint:      "/api/admin/config",
+		Method:        "PUT",
+		StatusCode:    200,
+		PrevHash:      "aaaa",
+		Hash:          "bbbb",
+	}
+}
+
+func TestFormatAuditEventCEF(t *testing.T) {
+	userID := uint(12)
+	tests := []struct {
+		name           string
+		mutate         func(e *models.AuditEvent)
+		productVersion string
+		wantSignature  string
+		wantName       string
+		wantSeverity   int
+		wantExt        map[string]string
+		wantNoExt      []string
+	}{
+		{
+			name:           "audit change",
+			mutate:         func(e *models.AuditEvent) {},
+			productVersion: "1.0",
+			wantSignature:  "audit:update:config",
+			wantName:       "UPDATE config",
+			wantSeverity:   3,
+			wantExt: map[string]string{
+				"rt": "1714530600123", "act": "UPDATE", "outcome": "success", "externalId": "1001",
+				"cs1": "audit_logs", "cn1": "42", "cs2": "bbbb", "cs3": "aaaa", "suser": "admin:7",
+				"src": "10.0.0.1", "requestClientApplication": "curl/8.0 a=b", "request": "/api/admin/config",
+				"requestMethod": "PUT", "cn2": "200", "cs4": "config:otp_ttl", "cs5": "value,description",
+			},
+			wantNoExt: []string{"duser", "reason"},
+		},
+		{
+			name: "failed login",
+			mutate: func(e *models.AuditEvent) {
+				e.Chain = models.AUDIT_CHAIN_LOGIN_AUDIT
+				e.Category = models.AUDIT_EVENT_CATEGORY_LOGIN
+				e.Action = "LOGIN"
+				e.Outcome = models.AUDIT_EVENT_OUTCOME_FAILURE
+				e.AdminID, e.UserID = nil, &userID
+				e.EntityType, e.EntityKey, e.ChangedFields = "", "", nil
+				e.Identifier = "0812****5678"
+				e.FailureReason = "invalid_pin\nretry"
+			},
+			productVersion: "1.0",
+			wantSignature:  "login:login",
+			wantName:       "LOGIN",
+			wantSeverity:   6,
+			wantExt:        map[string]string{"suser": "user:12", "duser": "0812****5678", "reason": "invalid_pin\nretry", "outcome": "failure"},
+			wantNoExt:      []string{"cs4", "cs5"},
+		},
+		{
+			name:           "header escaping",
+			mutate:         func(e *models.AuditEvent) { e.EntityType = `a|b\c` },
+			productVersion: "1.0|beta",
+			wantSignature:  `audit:update:a|b\c`,
+			wantName:       `UPDATE a|b\c`,
+			wantSeverity:   3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := testAuditEvent()
+			tt.mutate(&event)
+
+			record, err := ParseCEF(FormatAuditEventCEF(event, tt.productVersion))
+			if err != nil {
+				t.Fatalf("ParseCEF() error = %v", err)
+			}
+			if record.Vendor != siemCEFVendor || record.Product != siemCEFProduct || record.ProductVersion != tt.productVersion {
+				t.Errorf("header = %q|%q|%q", record.Vendor, record.Product, record.ProductVersion)
+			}
+			if record.SignatureID != tt.wantSignature || record.Name != tt.wantName || record.Severity != tt.wantSeverity {
+				t.Errorf("signature, name, severity = %q, %q, %d, want %q, %q, %d",
+					record.SignatureID, record.Name, record.Severity, tt.wantSignature, tt.wantName, tt.wantSeverity)
+			}
+			for key, want := range tt.wantExt {
+				if got := record.Extension[key]; got != want {
+					t.Errorf("extension %s = %q, want %q", key, got, want)
+				}
+			}
+			for _, key := range tt.wantNoExt {
+				if value, ok := record.Extension[key]; ok {
+					t.Errorf("unexpected extension %s = %q", key, value)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatAuditEventSyslog(t *testing.T) {
+	tests := []struct {
+		name         string
+		format       string
+		hostname     string
+		outcome      string
+		wantPRI      int
+		wantHostname string
+		wantBody     string
+	}{
+		{"CEF body", SIEM_FORMAT_CEF, "core-01", models.AUDIT_EVENT_OUTCOME_SUCCESS, 13*8 + 5, "core-01", "CEF:0|MBankingCore|"},
+		{"JSON body", SIEM_FORMAT_JSON, "core-01", models.AUDIT_EVENT_OUTCOME_SUCCESS, 13*8 + 5, "core-01", `{"chain":"audit_logs"`},
+		{"failure is a warning", SIEM_FORMAT_JSON, "core-01", models.AUDIT_EVENT_OUTCOME_FAILURE, 13*8 + 4, "core-01", "{"},
+		{"hostname with spaces", SIEM_FORMAT_JSON, "core 01\t", models.AUDIT_EVENT_OUTCOME_SUCCESS, 13*8 + 5, "core01", "{"},
+		{"empty hostname", SIEM_FORMAT_JSON, "", models.AUDIT_EVENT_OUTCOME_SUCCESS, 13*8 + 5, "-", "{"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := testAuditEvent()
+			event.Outcome = tt.outcome
+			event.Hash = `h"a]sh\`
+
+			raw := FormatAuditEventSyslog(event, tt.format, tt.hostname, "mbankingcore", "1.0")
+			msg, err := ParseSyslogMessage(raw)
+			if err != nil {
+				t.Fatalf("ParseSyslogMessage(%q) error = %v", raw, err)
+			}
+			if pri := msg.Facility*8 + msg.Severity; pri != tt.wantPRI {
+				t.Errorf("PRI = %d, want %d", pri, tt.wantPRI)
+			}
+			if !msg.Timestamp.Equal(event.Time) {
+				t.Errorf("timestamp = %v, want %v", msg.Timestamp, event.Time)
+			}
+			if msg.Hostname != tt.wantHostname || msg.AppName != "mbankingcore" || msg.ProcID != "-" || msg.MsgID != "AUDIT" {
+				t.Errorf("header = %q %q %q %q", msg.Hostname, msg.AppName, msg.ProcID, msg.MsgID)
+			}
+			sd := msg.StructuredData[siemSDID]
+			if sd["chain"] != "audit_logs" || sd["seq"] != "42" || sd["id"] != "1001" || sd["hash"] != event.Hash {
+				t.Errorf("structured data = %v", sd)
+			}
+			if !strings.HasPrefix(msg.Message, tt.wantBody) {
+				t.Errorf("message = %q, want prefix %q", msg.Message, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestParseSyslogMessageErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr string
+	}{
+		{"missing PRI", "1 - - - - - -", "missing PRI"},
+		{"PRI out of range", "<192>1 - h a - m -", "malformed PRI"},
+		{"incomplete header", "<13>1 - host", "incomplete header"},
+		{"version", "<13>2 - h a - m -", "unsupported version"},
+		{"timestamp", "<13>1 yesterday h a - m -", "malformed timestamp"},
+		{"empty header field", "<13>1 -  a - m -", "empty header field"},
+		{"unterminated parameter", `<13>1 - h a - m [id k="v]`, "unterminated structured data parameter"},
+		{"unterminated element", `<13>1 - h a - m [id k="v"`, "unterminated structured data element"},
+		{"no space before message", "<13>1 - h a - m -msg", "missing space before message"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSyslogMessage(tt.raw)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseSyslogMessage(%q) error = %v, want %q", tt.raw, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseCEFErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr string
+	}{
+		{"prefix", "LEEF:1.0|a|b|c|d|", "missing CEF prefix"},
+		{"incomplete header", "CEF:0|a|b|c|d|e|", "incomplete CEF header"},
+		{"severity", "CEF:0|a|b|c|d|e|11|", "invalid CEF severity"},
+		{"version", "CEF:1|a|b|c|d|e|3|", "unsupported CEF version"},
+		{"unescaped equals", "CEF:0|a|b|c|d|e|3|k=a=b", "unescaped"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCEF(tt.raw)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseCEF(%q) error = %v, want %q", tt.raw, err, tt.wantErr)
+			}
+		})
+	}
+}
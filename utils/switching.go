@@ -0,0 +1,239 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Switching transfer status values returned by an adapter
+const (
+	SWITCHING_STATUS_ACCEPTED = "accepted" // accepted by the switch, final result arrives via callback
+	SWITCHING_STATUS_SUCCESS  = "success"  // credited at the beneficiary bank
+	SWITCHING_STATUS_FAILED   = "failed"   // rejected by the switch or beneficiary bank
+	SWITCHING_STATUS_UNKNOWN  = "unknown"  // switch has no record of the transfer
+)
+
+// ErrSwitchingUnavailable is returned when the switch cannot be reached
+var ErrSwitchingUnavailable = errors.New("switching network unavailable")
+
+// SwitchingAccountInfo is the result of a beneficiary account inquiry
+type SwitchingAccountInfo struct {
+	BankCode      string `json:"bank_code"`
+	BankName      string `json:"bank_name"`
+	AccountNumber string `json:"account_number"`
+	AccountName   string `json:"account_name"`
+}
+
+// SwitchingTransferRequest is an outbound interbank credit transfer
+type SwitchingTransferRequest struct {
+	Reference                string `json:"reference"`
+	SourceAccountNumber      string `json:"source_account_number"`
+	SourceAccountName        string `json:"source_account_name"`
+	BeneficiaryBankCode      string `json:"beneficiary_bank_code"`
+	BeneficiaryAccountNumber string `json:"beneficiary_account_number"`
+	BeneficiaryAccountName   string `json:"beneficiary_account_name"`
+	Amount                   int64  `json:"amount"`
+	Description              string `json:"description"`
+}
+
+// SwitchingTransferResponse is the switch's synchronous answer to a transfer
+type SwitchingTransferResponse struct {
+	Reference       string `json:"reference"`
+	SwitchReference string `json:"switch_reference"`
+	Status          string `json:"status"`
+	FailureReason   string `json:"failure_reason,omitempty"`
+}
+
+// SwitchingCallback is the asynchronous final status notification from the switch
+type SwitchingCallback struct {
+	Reference       string `json:"reference" binding:"required"`
+	SwitchReference string `json:"switch_reference"`
+	Status          string `json:"status" binding:"required,oneof=success failed"`
+	FailureReason   string `json:"failure_reason,omitempty"`
+}
+
+// SwitchingAdapter abstracts the interbank switching network (e.g. ATM Bersama, Prima, BI-FAST)
+type SwitchingAdapter interface {
+	// Name identifies the adapter in logs and transfer records
+	Name() string
+	// InquireAccount validates a beneficiary account and returns the account holder name
+	InquireAccount(bankCode, accountNumber string) (*SwitchingAccountInfo, error)
+	// SendTransfer submits a credit transfer; the final result may arrive later via callback
+	SendTransfer(req SwitchingTransferRequest) (*SwitchingTransferResponse, error)
+	// CheckStatus queries the switch for the current status of a transfer
+	CheckStatus(reference string) (*SwitchingTransferResponse, error)
+	// VerifyCallback checks the signature of an inbound callback payload
+	VerifyCallback(payload []byte, signature string) bool
+	// SetCallbackHandler registers the function that receives asynchronous results
+	SetCallbackHandler(handler func(SwitchingCallback))
+}
+
+// NewSwitchingAdapter returns the adapter configured by SWITCHING_ADAPTER (default: simulator)
+func NewSwitchingAdapter() SwitchingAdapter {
+	adapter := os.Getenv("SWITCHING_ADAPTER")
+	if adapter != "" && adapter != "simulator" {
+		log.Printf("Unknown SWITCHING_ADAPTER %q, falling back to simulator", adapter)
+	}
+	return NewSimulatorSwitchingAdapter(getSwitchingCallbackSecret(), 3*time.Second)
+}
+
+// SignSwitchingPayload computes the hex HMAC-SHA256 signature used for switching callbacks
+func SignSwitchingPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func getSwitchingCallbackSecret() string {
+	secret := os.Getenv("SWITCHING_CALLBACK_SECRET")
+	if secret == "" {
+		secret = "mbankingcore-switching-simulator-secret"
+	}
+	return secret
+}
+
+// SimulatorSwitchingAdapter is an offline switch for development and testing.
+//
+// Beneficiary account numbers drive the simulated outcome:
+//   - ending in "0000": inquiry fails (account not found)
+//   - ending in "9999": transfer is accepted, then fails via callback
+//   - ending in "8888": transfer is accepted but no callback is ever sent (suspect transaction)
+//   - ending in "7777": switch is unavailable
+//   - anything else: transfer is accepted, then succeeds via callback
+type SimulatorSwitchingAdapter struct {
+	secret        string
+	callbackDelay time.Duration
+	banks         map[string]string
+
+	mu        sync.Mutex
+	transfers map[string]*SwitchingTransferResponse
+	handler   func(SwitchingCallback)
+}
+
+// NewSimulatorSwitchingAdapter creates a simulator that sends callbacks after callbackDelay
+func NewSimulatorSwitchingAdapter(secret string, callbackDelay time.Duration) *SimulatorSwitchingAdapter {
+	return &SimulatorSwitchingAdapter{
+		secret:        secret,
+		callbackDelay: callbackDelay,
+		banks: map[string]string{
+			"002": "Bank BRI",
+			"008": "Bank Mandiri",
+			"009": "Bank BNI",
+			"011": "Bank Danamon",
+			"014": "Bank BCA",
+			"022": "Bank CIMB Niaga",
+			"200": "Bank BTN",
+			"451": "Bank Syariah Indonesia",
+		},
+		transfers: map[string]*SwitchingTransferResponse{},
+	}
+}
+
+func (s *SimulatorSwitchingAdapter) Name() string {
+	return "simulator"
+}
+
+func (s *SimulatorSwitchingAdapter) InquireAccount(bankCode, accountNumber string) (*SwitchingAccountInfo, error) {
+	bankName, ok := s.banks[bankCode]
+	if !ok {
+		return nil, errors.New("unknown beneficiary bank code")
+	}
+	if strings.HasSuffix(accountNumber, "7777") {
+		return nil, ErrSwitchingUnavailable
+	}
+	if strings.HasSuffix(accountNumber, "0000") {
+		return nil, errors.New("beneficiary account not found")
+	}
+
+	return &SwitchingAccountInfo{
+		BankCode:      bankCode,
+		BankName:      bankName,
+		AccountNumber: accountNumber,
+		AccountName:   "SIMULATED ACCOUNT " + accountNumber[max(0, len(accountNumber)-4):],
+	}, nil
+}
+
+func (s *SimulatorSwitchingAdapter) SendTransfer(req SwitchingTransferRequest) (*SwitchingTransferResponse, error) {
+	if _, ok := s.banks[req.BeneficiaryBankCode]; !ok {
+		return &SwitchingTransferResponse{
+			Reference:     req.Reference,
+			Status:        SWITCHING_STATUS_FAILED,
+			FailureReason: "unknown beneficiary bank code",
+		}, nil
+	}
+	if strings.HasSuffix(req.BeneficiaryAccountNumber, "7777") {
+		return nil, ErrSwitchingUnavailable
+	}
+
+	response := &SwitchingTransferResponse{
+		Reference:       req.Reference,
+		SwitchReference: GenerateReference("SIM"),
+		Status:          SWITCHING_STATUS_ACCEPTED,
+	}
+
+	s.mu.Lock()
+	s.transfers[req.Reference] = response
+	accepted := *response
+	s.mu.Unlock()
+
+	if strings.HasSuffix(req.BeneficiaryAccountNumber, "8888") {
+		return &accepted, nil
+	}
+
+	callback := SwitchingCallback{
+		Reference:       req.Reference,
+		SwitchReference: response.SwitchReference,
+		Status:          SWITCHING_STATUS_SUCCESS,
+	}
+	if strings.HasSuffix(req.BeneficiaryAccountNumber, "9999") {
+		callback.Status = SWITCHING_STATUS_FAILED
+		callback.FailureReason = "beneficiary account closed"
+	}
+
+	go func() {
+		time.Sleep(s.callbackDelay)
+
+		s.mu.Lock()
+		response.Status = callback.Status
+		response.FailureReason = callback.FailureReason
+		handler := s.handler
+		s.mu.Unlock()
+
+		if handler != nil {
+			handler(callback)
+		}
+	}()
+
+	return &accepted, nil
+}
+
+func (s *SimulatorSwitchingAdapter) CheckStatus(reference string) (*SwitchingTransferResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	response, ok := s.transfers[reference]
+	if !ok {
+		return &SwitchingTransferResponse{Reference: reference, Status: SWITCHING_STATUS_UNKNOWN}, nil
+	}
+
+	copied := *response
+	return &copied, nil
+}
+
+func (s *SimulatorSwitchingAdapter) VerifyCallback(payload []byte, signature string) bool {
+	expected := SignSwitchingPayload(s.secret, payload)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+func (s *SimulatorSwitchingAdapter) SetCallbackHandler(handler func(SwitchingCallback)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handler = handler
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"fmt"
+	"os"
+)
+
+// Virtual account numbers: bank prefix + type digit + 11 digits (16 digits in total)
+const (
+	vaStaticDigit  = "0" // followed by the zero-padded user ID
+	vaDynamicDigit = "9" // followed by random digits
+)
+
+// VABankPrefix returns the 4-digit virtual account prefix assigned to this bank (VA_BANK_PREFIX)
+func VABankPrefix() string {
+	prefix := os.Getenv("VA_BANK_PREFIX")
+	if prefix == "" {
+		prefix = "8808"
+	}
+	return prefix
+}
+
+// StaticVANumber returns the permanent virtual account number of a user
+func StaticVANumber(userID uint) string {
+	return fmt.Sprintf("%s%s%011d", VABankPrefix(), vaStaticDigit, userID)
+}
+
+// DynamicVANumber builds an invoice virtual account number from 11 random digits
+func DynamicVANumber(randomDigits string) string {
+	return VABankPrefix() + vaDynamicDigit + randomDigits
+}
+
+// SignVANotification computes the hex HMAC-SHA256 signature of an inbound VA notification
+func SignVANotification(payload []byte) string {
+	return SignSwitchingPayload(getVACallbackSecret(), payload)
+}
+
+// VerifyVANotification checks the X-Signature of an inbound VA notification
+func VerifyVANotification(payload []byte, signature string) bool {
+	return hmac.Equal([]byte(SignVANotification(payload)), []byte(signature))
+}
+
+func getVACallbackSecret() string {
+	secret := os.Getenv("VA_CALLBACK_SECRET")
+	if secret == "" {
+		secret = "mbankingcore-virtual-account-secret"
+	}
+	return secret
+}
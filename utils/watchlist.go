@@ -0,0 +1,328 @@
+package utils
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// WatchlistRecord is one parsed entry of an imported sanctions or PEP list
+type WatchlistRecord struct {
+	ExternalID  string
+	Name        string
+	Aliases     []string
+	ListType    string // Empty when the file does not say; the import default applies
+	DateOfBirth string
+	Nationality string
+	Remarks     string
+}
+
+// transliterations maps accented Latin and Cyrillic letters to plain Latin
+var transliterations = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'æ': "ae", 'ç': "c", 'ć': "c", 'č': "c", 'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+	'ğ': "g", 'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'ı': "i",
+	'ł': "l", 'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o", 'œ': "oe",
+	'ř': "r", 'ś': "s", 'š': "s", 'ş': "s", 'ș': "s", 'ß': "ss", 'ť': "t", 'ţ': "t", 'ț': "t",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u",
+	'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z", 'þ': "th",
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z",
+	'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+	'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+}
+
+// spellingVariants folds the old Indonesian spelling (Soekarno, Djakarta, Tjipto) and common
+// romanisation variants onto one form, applied after transliteration
+var spellingVariants = strings.NewReplacer(
+	"oe", "u",
+	"dj", "j",
+	"tj", "c",
+	"nj", "ny",
+	"sj", "sy",
+	"ch", "kh",
+)
+
+// nameNoiseTokens are titles and connectors that do not identify a person
+var nameNoiseTokens = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "dr": true, "drs": true, "ir": true, "prof": true,
+	"h": true, "hj": true, "haji": true, "hajjah": true,
+	"bin": true, "binti": true, "bint": true, "ibn": true,
+}
+
+// NormalizeName lowercases, transliterates and strips punctuation, titles and connectors so
+// that names from different sources can be compared
+func NormalizeName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if t, ok := transliterations[r]; ok {
+			b.WriteString(t)
+			continue
+		}
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case unicode.IsLetter(r):
+			// Scripts without a transliteration are kept so they can still match exactly
+			b.WriteRune(r)
+		default:
+			b.WriteRune(' ')
+		}
+	}
+
+	tokens := []string{}
+	for _, token := range strings.Fields(spellingVariants.Replace(b.String())) {
+		if !nameNoiseTokens[token] {
+			tokens = append(tokens, token)
+		}
+	}
+	return strings.Join(tokens, " ")
+}
+
+// JaroWinkler returns the Jaro-Winkler similarity of two strings, from 0 (different) to 1 (equal)
+func JaroWinkler(a, b string) float64 {
+	s1, s2 := []rune(a), []rune(b)
+	if len(s1) == 0 && len(s2) == 0 {
+		return 1
+	}
+	if len(s1) == 0 || len(s2) == 0 {
+		return 0
+	}
+
+	matchDistance := max(len(s1), len(s2))/2 - 1
+	if matchDistance < 0 {
+		matchDistance = 0
+	}
+
+	matched1 := make([]bool, len(s1))
+	matched2 := make([]bool, len(s2))
+	matches := 0
+	for i := range s1 {
+		start := max(0, i-matchDistance)
+		end := min(len(s2), i+matchDistance+1)
+		for j := start; j < end; j++ {
+			if matched2[j] || s1[i] != s2[j] {
+				continue
+			}
+			matched1[i], matched2[j] = true, true
+			matches++
+			break
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	j := 0
+	for i := range s1 {
+		if !matched1[i] {
+			continue
+		}
+		for !matched2[j] {
+			j++
+		}
+		if s1[i] != s2[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(s1)) + m/float64(len(s2)) + (m-float64(transpositions)/2)/m) / 3
+
+	// Winkler boost for a common prefix of up to four characters
+	prefix := 0
+	for prefix < min(4, len(s1), len(s2)) && s1[prefix] == s2[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+// NameMatchScore compares two normalized names, tolerating reordered name parts and a missing
+// middle name. The result is between 0 and 1.
+func NameMatchScore(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+
+	score := JaroWinkler(a, b)
+
+	tokensA, tokensB := strings.Fields(a), strings.Fields(b)
+	score = max(score, JaroWinkler(sortedTokens(tokensA), sortedTokens(tokensB)))
+
+	// Token-wise comparison: every part of the shorter name must resemble some part of the
+	// longer one. Scaled down by the share of parts left unmatched to limit false positives.
+	if len(tokensA) >= 2 && len(tokensB) >= 2 {
+		shorter, longer := tokensA, tokensB
+		if len(shorter) > len(longer) {
+			shorter, longer = longer, shorter
+		}
+		total := 0.0
+		for _, token := range shorter {
+			best := 0.0
+			for _, other := range longer {
+				best = max(best, JaroWinkler(token, other))
+			}
+			total += best
+		}
+		coverage := float64(len(shorter)) / float64(len(longer))
+		score = max(score, total/float64(len(shorter))*(0.9+0.1*coverage))
+	}
+
+	return score
+}
+
+func sortedTokens(tokens []string) string {
+	sorted := append([]string{}, tokens...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, " ")
+}
+
+// ParseWatchlistCSV reads a watchlist CSV with a header row. Recognised columns are id, name,
+// aliases (separated by ';'), list_type, date_of_birth, nationality and remarks; only name is required.
+func ParseWatchlistCSV(r io.Reader) ([]WatchlistRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("Invalid CSV file: %v", err)
+	}
+	if len(rows) < 2 {
+		return nil, fmt.Errorf("CSV file contains no data rows")
+	}
+
+	columns := map[string]int{}
+	for i, header := range rows[0] {
+		columns[strings.ToLower(strings.TrimSpace(header))] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, fmt.Errorf("CSV header must contain a name column")
+	}
+	field := func(row []string, column string) string {
+		if i, ok := columns[column]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	records := []WatchlistRecord{}
+	for _, row := range rows[1:] {
+		name := field(row, "name")
+		if name == "" {
+			continue
+		}
+		record := WatchlistRecord{
+			ExternalID:  field(row, "id"),
+			Name:        name,
+			ListType:    strings.ToLower(field(row, "list_type")),
+			DateOfBirth: field(row, "date_of_birth"),
+			Nationality: field(row, "nationality"),
+			Remarks:     field(row, "remarks"),
+		}
+		for _, alias := range strings.Split(field(row, "aliases"), ";") {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				record.Aliases = append(record.Aliases, alias)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// unConsolidatedList is the subset of the UN Security Council consolidated list XML we import
+type unConsolidatedList struct {
+	XMLName     xml.Name         `xml:"CONSOLIDATED_LIST"`
+	Individuals []unListedParty  `xml:"INDIVIDUALS>INDIVIDUAL"`
+	Entities    []unListedEntity `xml:"ENTITIES>ENTITY"`
+}
+
+type unListedParty struct {
+	DataID      string   `xml:"DATAID"`
+	FirstName   string   `xml:"FIRST_NAME"`
+	SecondName  string   `xml:"SECOND_NAME"`
+	ThirdName   string   `xml:"THIRD_NAME"`
+	FourthName  string   `xml:"FOURTH_NAME"`
+	Comments    string   `xml:"COMMENTS1"`
+	Nationality []string `xml:"NATIONALITY>VALUE"`
+	Aliases     []string `xml:"INDIVIDUAL_ALIAS>ALIAS_NAME"`
+	BirthDates  []struct {
+		Date string `xml:"DATE"`
+		Year string `xml:"YEAR"`
+	} `xml:"INDIVIDUAL_DATE_OF_BIRTH"`
+}
+
+type unListedEntity struct {
+	DataID   string   `xml:"DATAID"`
+	Name     string   `xml:"FIRST_NAME"`
+	Comments string   `xml:"COMMENTS1"`
+	Aliases  []string `xml:"ENTITY_ALIAS>ALIAS_NAME"`
+}
+
+// ParseWatchlistXML reads the UN Security Council consolidated list format, which is also the
+// basis of the domestic terrorist list (DTTOT)
+func ParseWatchlistXML(r io.Reader) ([]WatchlistRecord, error) {
+	var list unConsolidatedList
+	if err := xml.NewDecoder(r).Decode(&list); err != nil {
+		return nil, fmt.Errorf("Invalid XML file: %v", err)
+	}
+
+	records := []WatchlistRecord{}
+	for _, party := range list.Individuals {
+		name := strings.Join(strings.Fields(strings.Join([]string{party.FirstName, party.SecondName, party.ThirdName, party.FourthName}, " ")), " ")
+		if name == "" {
+			continue
+		}
+		record := WatchlistRecord{
+			ExternalID:  party.DataID,
+			Name:        name,
+			Nationality: strings.Join(party.Nationality, ", "),
+			Remarks:     strings.TrimSpace(party.Comments),
+		}
+		for _, alias := range party.Aliases {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				record.Aliases = append(record.Aliases, alias)
+			}
+		}
+		if len(party.BirthDates) > 0 {
+			record.DateOfBirth = party.BirthDates[0].Date
+			if record.DateOfBirth == "" {
+				record.DateOfBirth = party.BirthDates[0].Year
+			}
+		}
+		records = append(records, record)
+	}
+	for _, entity := range list.Entities {
+		name := strings.TrimSpace(entity.Name)
+		if name == "" {
+			continue
+		}
+		record := WatchlistRecord{
+			ExternalID: entity.DataID,
+			Name:       name,
+			Remarks:    strings.TrimSpace(entity.Comments),
+		}
+		for _, alias := range entity.Aliases {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				record.Aliases = append(record.Aliases, alias)
+			}
+		}
+		records = append(records, record)
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("XML file contains no listed individuals or entities")
+	}
+	return records, nil
+}
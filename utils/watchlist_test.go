@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestJaroWinkler(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"martha", "marhta", 0.9611},
+		{"dwayne", "duane", 0.84},
+		{"dixon", "dicksonx", 0.8133},
+		{"jones", "johnson", 0.8323},
+		{"abc", "abc", 1},
+		{"abc", "xyz", 0},
+		{"", "", 1},
+		{"abc", "", 0},
+		{"a", "a", 1},
+		{"budi", "búdi", 0.85},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			got := JaroWinkler(tt.a, tt.b)
+			if math.Abs(got-tt.want) > 0.0001 {
+				t.Errorf("JaroWinkler(%q, %q) = %.4f, want %.4f", tt.a, tt.b, got, tt.want)
+			}
+			if reverse := JaroWinkler(tt.b, tt.a); math.Abs(reverse-got) > 1e-9 {
+				t.Errorf("JaroWinkler(%q, %q) = %.4f, not symmetric with %.4f", tt.b, tt.a, reverse, got)
+			}
+		})
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Budi Santoso", "budi santoso"},
+		{"  BUDI   SANTOSO ", "budi santoso"},
+		{"Dr. H. Ahmad bin Abdullah", "ahmad abdullah"},
+		{"Soekarno", "sukarno"},
+		{"Tjipto Mangoenkoesoemo", "cipto mangunkusumo"},
+		{"José Müller-Łukasz", "jose muller lukasz"},
+		{"Сергей Иванов", "sergey ivanov"},
+		{"O'Brien", "o brien"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeName(tt.name); got != tt.want {
+				t.Errorf("NormalizeName(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNameMatchScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		minScore float64
+		maxScore float64
+	}{
+		{"equal", "budi santoso", "budi santoso", 1, 1},
+		{"reordered", "santoso budi", "budi santoso", 0.99, 1},
+		{"missing middle name", "budi santoso", "budi hartono santoso", 0.9, 1},
+		{"typo", "budi santosa", "budi santoso", 0.9, 1},
+		{"different person", "budi santoso", "siti rahayu", 0, 0.6},
+		{"empty", "", "budi santoso", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NameMatchScore(tt.a, tt.b)
+			if got < tt.minScore || got > tt.maxScore {
+				t.Errorf("NameMatchScore(%q, %q) = %.4f, want between %.2f and %.2f", tt.a, tt.b, got, tt.minScore, tt.maxScore)
+			}
+		})
+	}
+}